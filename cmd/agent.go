@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/repplus/rep-cli/internal/triage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	agentSaved string
+	agentTop   int
+)
+
+const (
+	agentScriptLimit = 25 // First-party script URLs listed
+	agentFollowUps   = 5  // Interesting requests that get an action
+)
+
+// AgentBriefing is the consolidated output of 'rep agent'
+type AgentBriefing struct {
+	Target         string            `json:"target"`
+	Source         string            `json:"source"` // "live" or the saved session ID
+	Requests       int               `json:"requests"`
+	TargetRequests int               `json:"target_requests"` // On the target's registrable domain
+	Surface        AgentSurface      `json:"surface"`
+	Auth           []AgentCredential `json:"auth"`
+	JavaScript     AgentJS           `json:"javascript"`
+	Interesting    []AgentFinding    `json:"interesting"`
+	Actions        []AgentAction     `json:"actions"`
+}
+
+// AgentSurface is the domain breakdown from recon
+type AgentSurface struct {
+	FirstParty        []ReconDomainSummary `json:"first_party"`
+	ThirdPartyDomains int                  `json:"third_party_domains"`
+	Noise             []NoiseDomain        `json:"noise"`
+	SuggestedIgnore   string               `json:"suggested_ignore_command,omitempty"`
+}
+
+// AgentCredential is a credential the target's traffic carries. The value
+// is left out: 'rep auth --save' stores it without printing it.
+type AgentCredential struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Domain string `json:"domain"`
+}
+
+// AgentJS summarizes the captured scripts
+type AgentJS struct {
+	Summary    JSSummary `json:"summary"`
+	FirstParty []string  `json:"first_party"` // Script URLs, at most 25
+}
+
+// AgentFinding is one of the highest scoring target requests
+type AgentFinding struct {
+	ID      string   `json:"id"`
+	Method  string   `json:"method"`
+	URL     string   `json:"url"`
+	Status  int      `json:"status"`
+	Score   int      `json:"score"`
+	Signals []string `json:"signals"` // "name: detail"
+}
+
+// AgentAction is a suggested next step, most valuable first
+type AgentAction struct {
+	Priority int    `json:"priority"`
+	Reason   string `json:"reason"`
+	Command  string `json:"command"`
+}
+
+var agentCmd = &cobra.Command{
+	Use:   "agent <target-domain>",
+	Short: "One-shot JSON briefing: recon, auth, JS and top requests with next commands",
+	Long: `Run recon, credential extraction, JavaScript categorization and
+interesting-request scoring in one go and print a single JSON briefing, so
+an agent starts with one call instead of four:
+
+  surface      First-party domains, third-party count, noise to ignore
+  auth         Credentials sent to the target (names only, never values)
+  javascript   Script counts and first-party script URLs
+  interesting  The --top highest scoring target requests with their signals
+  actions      Prioritized next steps, each with the exact command to run
+
+Like 'rep recon', the target is marked primary. Requests count as the
+target's when they are on its registrable domain. The briefing is always
+JSON; --envelope wraps it (schema: rep schema agent).
+
+Examples:
+  rep agent example.com
+  rep agent example.com --top 20
+  rep agent example.com --saved latest
+  rep agent example.com | jq -r '.actions[].command'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target := strings.ToLower(strings.TrimSpace(args[0]))
+		if agentTop < 0 {
+			return usageError("--top must be 0 or more")
+		}
+
+		persistentStore, err := store.Get()
+		if err != nil {
+			return fmt.Errorf("failed to load store: %w", err)
+		}
+		tempStore, err := loadSourceStore(agentSaved)
+		if err != nil || tempStore == nil {
+			return err
+		}
+
+		persistentStore.SetPrimary(target)
+		if err := persistentStore.Save(); err != nil {
+			jsonWarn("could not save primary domain: %v", err)
+		}
+		tempStore.PrimaryDomains = persistentStore.PrimaryDomains
+
+		briefing := buildAgentBriefing(target, tempStore)
+		if agentSaved != "" {
+			briefing.Source = agentSaved
+		}
+		if briefing.TargetRequests == 0 {
+			if err := softFail(emptyError("No requests to %s captured", store.GetBaseDomain(target))); err != nil {
+				return err
+			}
+		}
+
+		next := make([]string, len(briefing.Actions))
+		for i, a := range briefing.Actions {
+			next[i] = a.Command
+		}
+		printJSON("agent", briefing, next...)
+		return nil
+	},
+}
+
+// buildAgentBriefing analyzes every request in s (ignored domains included)
+// for target
+func buildAgentBriefing(target string, s *store.Store) AgentBriefing {
+	all := s.Filter(store.FilterOptions{ExcludeIgnored: false})
+	recon := buildReconOutput(target, all, s)
+
+	briefing := AgentBriefing{
+		Target:   target,
+		Source:   "live",
+		Requests: len(all),
+		Surface: AgentSurface{
+			FirstParty:        recon.FirstParty.Domains,
+			ThirdPartyDomains: len(recon.ThirdParty.Domains),
+			Noise:             recon.NoiseDetected,
+			SuggestedIgnore:   recon.SuggestedIgnore,
+		},
+		Auth:        []AgentCredential{},
+		Interesting: []AgentFinding{},
+	}
+
+	targetBase := store.GetBaseDomain(target)
+	var requests []store.Request
+	for _, req := range all {
+		if req.Domain != "" && store.GetBaseDomain(req.Domain) == targetBase {
+			requests = append(requests, req)
+		}
+	}
+	briefing.TargetRequests = len(requests)
+
+	seen := make(map[string]bool)
+	for _, t := range extractAuthTokens(requests, "") {
+		key := t.Name + "\x00" + t.Domain
+		if !seen[key] {
+			seen[key] = true
+			briefing.Auth = append(briefing.Auth, AgentCredential{Name: t.Name, Source: t.Source, Domain: t.Domain})
+		}
+	}
+
+	js := categorizeJS(getJSRequests(s))
+	briefing.JavaScript = AgentJS{Summary: js.Summary, FirstParty: []string{}}
+	for _, f := range js.FirstPartyJS {
+		if len(briefing.JavaScript.FirstParty) == agentScriptLimit {
+			break
+		}
+		briefing.JavaScript.FirstParty = append(briefing.JavaScript.FirstParty, f.URL)
+	}
+
+	scores := triage.Rank(requests)
+	for _, score := range scores {
+		if len(briefing.Interesting) == agentTop || score.Score == 0 {
+			break
+		}
+		req := score.Request
+		finding := AgentFinding{
+			ID:      req.ID,
+			Method:  req.Method,
+			URL:     req.URL,
+			Status:  responseStatus(req),
+			Score:   score.Score,
+			Signals: make([]string, len(score.Signals)),
+		}
+		for i, signal := range score.Signals {
+			finding.Signals[i] = signal.Name + ": " + signal.Detail
+		}
+		briefing.Interesting = append(briefing.Interesting, finding)
+	}
+
+	briefing.Actions = agentActions(target, briefing, scores)
+	return briefing
+}
+
+// agentActions orders the next steps: clean up noise, store credentials,
+// follow up the top findings with the command their signals call for, then
+// broader sweeps
+func agentActions(target string, b AgentBriefing, scores []triage.Score) []AgentAction {
+	var actions []AgentAction
+	seen := make(map[string]bool)
+	add := func(command, reason string) {
+		if seen[command] {
+			return
+		}
+		seen[command] = true
+		actions = append(actions, AgentAction{Priority: len(actions) + 1, Reason: reason, Command: command})
+	}
+
+	if b.Surface.SuggestedIgnore != "" {
+		add(b.Surface.SuggestedIgnore, "Ignore analytics/CDN/tracking noise so filters skip it")
+	}
+	if len(b.Auth) > 0 {
+		domain := agentAuthDomain(b.Auth)
+		add("rep auth --save -d "+domain, fmt.Sprintf("Store %d credential(s) for replay without printing them", len(b.Auth)))
+		add(fmt.Sprintf(`eval "$(rep auth --vars -d %s --prefix TARGET)"`, domain), "Reference them as $TARGET_* in curl commands")
+	}
+
+	for i, finding := range b.Interesting {
+		if i == agentFollowUps {
+			break
+		}
+		command, reason := agentFollowUp(finding, target)
+		add(command, reason)
+	}
+
+	errorsSeen := false
+	for _, score := range scores {
+		if score.Request.Response != nil && score.Request.Response.Status >= 400 {
+			errorsSeen = true
+			break
+		}
+	}
+	// Domain filters are exact, so sweeps name every first-party host
+	hosts := []string{target}
+	if len(b.Surface.FirstParty) > 0 {
+		hosts = hosts[:0]
+		for _, d := range b.Surface.FirstParty {
+			hosts = append(hosts, d.Domain)
+		}
+	}
+	domainFilter := "-d " + strings.Join(hosts, ",") + " --primary=false"
+	if errorsSeen {
+		add("rep errors "+domainFilter+" -o json", "Fingerprint the stack behind error responses")
+	}
+	if b.JavaScript.Summary.FirstPartyCount > 0 {
+		add("rep js --endpoints --unrequested -o json", fmt.Sprintf("Mine %d first-party script(s) for endpoints not yet requested", b.JavaScript.Summary.FirstPartyCount))
+	}
+	add("rep list --api "+domainFilter+" -o json", "Browse the target's API calls")
+	return actions
+}
+
+// agentFollowUp picks the command for a finding from its strongest signals
+func agentFollowUp(f AgentFinding, target string) (string, string) {
+	has := func(name string) bool {
+		for _, s := range f.Signals {
+			if strings.HasPrefix(s, name+":") {
+				return true
+			}
+		}
+		return false
+	}
+	what := fmt.Sprintf("%s %s (score %d)", f.Method, truncateCell(f.URL, 80), f.Score)
+	switch {
+	case has("stack_trace") || has("verbose_error"):
+		return "rep body " + f.ID, "Read the error detail leaked by " + what
+	case has("access_denied"):
+		return "rep bypass " + f.ID + " --use-vars", "Try access-control bypasses on " + what
+	case has("admin_path"):
+		return "rep body " + f.ID, "Inspect the admin/internal endpoint " + what
+	case has("object_id") && has("auth"):
+		return "rep curl " + f.ID + " --use-vars", "Replay with another object ID (IDOR candidate) " + what
+	case has("mutation") && has("auth"):
+		return "rep csrf -d " + store.GetBaseDomain(target), "Check CSRF protection of state-changing requests like " + what
+	}
+	return "rep body " + f.ID, "Review " + what
+}
+
+// agentAuthDomain is the domain most credentials were sent to
+func agentAuthDomain(creds []AgentCredential) string {
+	counts := make(map[string]int)
+	for _, c := range creds {
+		counts[c.Domain]++
+	}
+	domains := make([]string, 0, len(counts))
+	for d := range counts {
+		domains = append(domains, d)
+	}
+	sort.Slice(domains, func(i, j int) bool {
+		if counts[domains[i]] != counts[domains[j]] {
+			return counts[domains[i]] > counts[domains[j]]
+		}
+		return domains[i] < domains[j]
+	})
+	return domains[0]
+}
+
+func init() {
+	rootCmd.AddCommand(agentCmd)
+	agentCmd.Flags().StringVar(&agentSaved, "saved", "", "Read from saved session (ID, prefix, or 'latest')")
+	agentCmd.Flags().IntVar(&agentTop, "top", 10, "Interesting requests to include")
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	archiveOlderThan string
+	archiveRestore   bool
+	archiveDryRun    bool
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive <session-id...> | --older-than <age>",
+	Short: "Move saved sessions into compressed archive files",
+	Long: `Move saved sessions out of store.json into individually zstd-compressed
+files under the archive directory (next to store.json). Keeps the store
+small and every command fast without losing history.
+
+Archived sessions stay listed in 'rep sessions' and are read transparently
+by --saved <id> (e.g. 'rep list --saved <id>'), decompressed on demand.
+'latest' only refers to sessions still in store.json.
+
+--restore moves archived sessions back into store.json.
+
+Examples:
+  rep archive 20240115-103000              Archive one session
+  rep archive --older-than 14d             Archive everything older than two weeks
+  rep archive --older-than 7d --dry-run    Show what would be archived
+  rep archive --restore 20240115           Bring a session back
+  rep list --saved 20240115 --errors       Query an archived session directly`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && archiveOlderThan == "" {
+			return fmt.Errorf("specify session IDs or --older-than")
+		}
+
+		s, err := store.Get()
+		if err != nil {
+			return fmt.Errorf("failed to load store: %w", err)
+		}
+
+		if archiveRestore {
+			return runArchiveRestore(s, args)
+		}
+
+		var ids []string
+		for _, id := range args {
+			session := resolveSession(s, id)
+			if session == nil {
+				if err := softFail(notFoundError("Session not found: %s", id)); err != nil {
+					return err
+				}
+				continue
+			}
+			ids = appendUnique(ids, session.ID)
+		}
+		if archiveOlderThan != "" {
+			maxAge, err := store.ParseAge(archiveOlderThan)
+			if err != nil {
+				return fmt.Errorf("invalid --older-than: %s (use 30d, 12h, 90m)", archiveOlderThan)
+			}
+			for _, sess := range s.ListSessions() {
+				if time.Since(time.UnixMilli(sess.Timestamp)) > maxAge {
+					ids = appendUnique(ids, sess.ID)
+				}
+			}
+		}
+
+		if len(ids) == 0 {
+			pterm.Info.Println("Nothing to archive")
+			return nil
+		}
+
+		if archiveDryRun {
+			if getOutputMode() == "json" {
+				out, _ := sonic.MarshalIndent(map[string]interface{}{"would_archive": ids, "dry_run": true}, "", "  ")
+				fmt.Println(string(out))
+				return nil
+			}
+			for _, id := range ids {
+				fmt.Println(id)
+			}
+			pterm.Info.Printf("Would archive %d sessions (dry run)\n", len(ids))
+			return nil
+		}
+
+		archived, err := archiveSessions(s, ids)
+		if err != nil {
+			return err
+		}
+
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{"archived": archived}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		for _, a := range archived {
+			pterm.Success.Printf("Archived %s (%d requests, %s)\n", a.ID, a.Requests, formatByteSize(a.Bytes))
+		}
+		if dir, err := store.GetArchivePath(); err == nil {
+			pterm.Info.Printf("Archive: %s\n", dir)
+		}
+		return nil
+	},
+}
+
+// archiveSessions archives sessions by exact ID and saves the store
+func archiveSessions(s *store.Store, ids []string) ([]store.ArchivedSession, error) {
+	var archived []store.ArchivedSession
+	for _, id := range ids {
+		entry, err := s.ArchiveSession(id)
+		if err != nil {
+			pterm.Warning.Printf("Could not archive %s: %v\n", id, err)
+			continue
+		}
+		archived = append(archived, *entry)
+	}
+	if len(archived) > 0 {
+		if err := s.Save(); err != nil {
+			return nil, fmt.Errorf("failed to save store: %w", err)
+		}
+	}
+	return archived, nil
+}
+
+func runArchiveRestore(s *store.Store, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("--restore needs archived session IDs")
+	}
+
+	var restored []string
+	for _, id := range args {
+		session, err := s.RestoreSession(id)
+		if err != nil {
+			pterm.Warning.Printf("%v\n", err)
+			continue
+		}
+		restored = append(restored, session.ID)
+	}
+	if len(restored) > 0 {
+		if err := s.Save(); err != nil {
+			return fmt.Errorf("failed to save store: %w", err)
+		}
+	}
+
+	if getOutputMode() == "json" {
+		out, _ := sonic.MarshalIndent(map[string]interface{}{"restored": restored}, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+	for _, id := range restored {
+		pterm.Success.Printf("Restored %s\n", id)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	archiveCmd.Flags().StringVar(&archiveOlderThan, "older-than", "", "Archive sessions saved more than this long ago (30d, 12h)")
+	archiveCmd.Flags().BoolVar(&archiveRestore, "restore", false, "Move archived sessions back into store.json")
+	archiveCmd.Flags().BoolVar(&archiveDryRun, "dry-run", false, "Show what would be archived")
+}
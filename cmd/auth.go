@@ -15,14 +15,17 @@ import (
 )
 
 var (
-	authExport bool
-	authSave   bool
-	authEnv    bool
-	authShell  bool
-	authVars   bool
-	authPrefix string
-	authDomain string
-	authSaved  string
+	authExport   bool
+	authSave     bool
+	authEnv      bool
+	authShell    bool
+	authVars     bool
+	authPrefix   string
+	authDomain   string
+	authSaved    string
+	authWhere    bool
+	authRotation bool
+	authReplay   bool
 )
 
 // AuthToken represents an extracted authentication token
@@ -54,6 +57,11 @@ Legacy workflow (shell vars):
   rep auth --export                      Output as shell exports (prints tokens)
   eval "$(rep auth --export)"            Set in current shell
 
+--export follows --shell-syntax (default powershell on Windows):
+  rep auth --export | Out-String | Invoke-Expression        PowerShell
+  rep auth --export --shell-syntax cmd > auth.cmd && call auth.cmd
+--save, --env, --shell and --vars write and print POSIX shell syntax.
+
 Examples:
   rep auth                               Show extracted auth tokens
   rep auth --save                        Save to env file
@@ -62,6 +70,24 @@ Examples:
   rep auth --shell -d api.target.com     Print "source <path>" for shell
   rep auth --vars -d api.target.com --prefix KIRO
   rep auth --export                      Output as shell exports
+  rep auth --where                       Where each token was sent (leaks, blast radius)
+  rep auth --where -d api.target.com -o json
+  rep auth --rotation                    How session cookies and tokens rotate
+  rep auth --rotation --replay -d api.target.com
+
+--where lists, for each token, every domain and endpoint that received it
+(in a header, the URL or the body), third-party domains first: a session
+cookie or bearer token sent to an analytics host is a leak, and the
+endpoints show what a stolen copy would reach.
+
+--rotation follows each session cookie (by name) and bearer token through
+the capture in order: every value it took, the response that issued it,
+how often it rotated and the lifetime Set-Cookie declared. It flags
+replaced values the server still answered normally, and fixation
+indicators: a value kept across a login, the server re-issuing a value
+the client sent, a session value in a URL. --replay re-sends the last GET
+that carried the current value with the one it replaced, to test whether
+the old value is still accepted (one request per rotated credential).
 
 Extracted headers:
   - Authorization (Bearer, Basic, etc.)
@@ -69,13 +95,18 @@ Extracted headers:
   - X-API-Key, X-Auth-Token, X-Access-Token
   - X-CSRF-Token, X-XSRF-Token`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// One env file per domain: groups don't map to a file
+		var err error
+		if authDomain, err = singleDomain(authDomain); err != nil {
+			return err
+		}
 		if (authEnv || authShell || authVars) && !authSave {
 			envPath, err := authEnvPath(authDomain)
 			if err != nil {
 				return fmt.Errorf("failed to resolve auth env path: %w", err)
 			}
 			if !fileExists(envPath) {
-				return fmt.Errorf("auth env not found: %s (run 'rep auth --save' first)", envPath)
+				return notFoundError("auth env not found: %s (run 'rep auth --save' first)", envPath)
 			}
 			if authVars {
 				return printAuthVars(envPath, authPrefix, authDomain)
@@ -100,29 +131,57 @@ Extracted headers:
 			}
 
 			if session == nil {
-				pterm.Warning.Printf("Session not found: %s\n", authSaved)
-				return nil
+				return notFoundError("session not found: %s", authSaved).withHint("Use 'rep sessions' to list available sessions")
 			}
 			requests = session.Requests
 		} else {
 			// Load from live.json
-			livePath, err := store.GetLiveFilePath()
+			export, err := loadLiveMerged()
 			if err != nil {
-				return fmt.Errorf("failed to get live path: %w", err)
+				return softFail(liveReadError(err))
 			}
-			export, err := loadLiveExport(livePath)
-			if err != nil {
-				pterm.Warning.Printf("Could not read live.json: %v\n", err)
+			requests = export.Requests
+		}
+
+		if authRotation {
+			if authSave || authExport || authWhere {
+				return usageError("--rotation can't be combined with --save, --export or --where")
+			}
+			tracks := analyzeRotation(requests, authDomain)
+			if len(tracks) == 0 {
+				return softFail(emptyError("No session cookies or bearer tokens found in captured requests"))
+			}
+			if authReplay {
+				replayRotation(tracks, requests)
+			}
+			if getOutputMode() == "json" {
+				printJSON("auth/rotation", tracks)
 				return nil
 			}
-			requests = export.Requests
+			printAuthRotation(tracks)
+			return nil
+		}
+		if authReplay {
+			return usageError("--replay needs --rotation")
 		}
 
 		// Extract auth tokens
 		tokens := extractAuthTokens(requests, authDomain)
 
 		if len(tokens) == 0 {
-			pterm.Info.Println("No auth tokens found in captured requests")
+			return softFail(emptyError("No auth tokens found in captured requests"))
+		}
+
+		if authWhere {
+			if authSave || authExport {
+				return usageError("--where can't be combined with --save or --export")
+			}
+			usages := tokenUsages(tokens, requests)
+			if getOutputMode() == "json" {
+				printJSON("auth/where", usages)
+				return nil
+			}
+			printAuthWhere(usages)
 			return nil
 		}
 
@@ -159,16 +218,21 @@ Extracted headers:
 			}
 			return nil
 		} else if authExport {
-			// Shell export format
+			// Shell export format (--shell-syntax picks the shell)
+			shell, err := currentShellDialect()
+			if err != nil {
+				return err
+			}
 			for _, t := range tokens {
-				// Escape single quotes in value
-				escaped := strings.ReplaceAll(t.Value, "'", "'\"'\"'")
-				fmt.Printf("export %s='%s'\n", t.Name, escaped)
+				fmt.Println(shell.SetVar(t.Name, t.Value))
 			}
-			fmt.Println("# Usage: eval \"$(rep auth --export)\"")
+			fmt.Printf("%s Usage: %s\n", shell.Comment, shell.LoadAuth)
 		} else if getOutputMode() == "json" {
-			out, _ := sonic.MarshalIndent(tokens, "", "  ")
-			fmt.Println(string(out))
+			var next []string
+			if len(tokens) > 0 {
+				next = append(next, "rep auth --save", "rep auth --vars")
+			}
+			printJSON("auth", tokens, next...)
 		} else {
 			// Human-readable format
 			pterm.DefaultSection.Println("Extracted Auth Tokens")
@@ -378,6 +442,56 @@ func saveAuthEnv(tokens []AuthToken, domain string) (string, error) {
 	return envPath, nil
 }
 
+// loadAuthEnv parses an env file written by saveAuthEnv ("export NAME='value'")
+// into a name -> value map.
+func loadAuthEnv(envPath string) (map[string]string, error) {
+	data, err := os.ReadFile(envPath)
+	if err != nil {
+		return nil, err
+	}
+	vars := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "export "))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		vars[strings.TrimSpace(name)] = shellUnquote(value)
+	}
+	return vars, nil
+}
+
+// shellUnquote reverses shellQuote ('...' segments joined with "'")
+func shellUnquote(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				b.WriteString(s[i+1:])
+				return b.String()
+			}
+			b.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+		case '"':
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				b.WriteString(s[i+1:])
+				return b.String()
+			}
+			b.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
 func shellQuote(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", "'\"'\"'") + "'"
 }
@@ -521,6 +635,9 @@ func init() {
 	authCmd.Flags().BoolVar(&authVars, "vars", false, "Print shell exports for prefixed auth variables")
 	authCmd.Flags().StringVar(&authPrefix, "prefix", "", "Prefix for --vars exports (default: domain-derived)")
 	authCmd.Flags().BoolVar(&authExport, "export", false, "Output as shell export statements (legacy)")
-	authCmd.Flags().StringVarP(&authDomain, "domain", "d", "", "Filter by domain")
+	authCmd.Flags().StringVarP(&authDomain, "domain", "d", "", "Filter by domain (a single domain: env files are per domain)")
 	authCmd.Flags().StringVar(&authSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	authCmd.Flags().BoolVar(&authWhere, "where", false, "Show every domain and endpoint each token was sent to")
+	authCmd.Flags().BoolVar(&authRotation, "rotation", false, "Track how session cookies and tokens change over the capture")
+	authCmd.Flags().BoolVar(&authReplay, "replay", false, "With --rotation, test whether replaced values are still accepted")
 }
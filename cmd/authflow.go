@@ -0,0 +1,537 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	authflowSaved  string
+	authflowDomain string
+	authflowCheck  bool
+)
+
+// sessionCookieName matches cookie names that carry a login session
+var sessionCookieName = regexp.MustCompile(`(?i)(sess|sid|auth|token|jwt|login|remember)`)
+
+// loginPath matches paths of login endpoints
+var loginPath = regexp.MustCompile(`(?i)/(login|log-in|signin|sign-in|sessions?|authenticate)\b`)
+
+// issuedTokenFields are the JSON response fields holding issued tokens,
+// lowercase without underscores, with the name they are reported under
+var issuedTokenFields = map[string]string{
+	"accesstoken":  "access_token",
+	"idtoken":      "id_token",
+	"refreshtoken": "refresh_token",
+	"token":        "token",
+	"authtoken":    "auth_token",
+	"sessiontoken": "session_token",
+	"jwt":          "jwt",
+}
+
+// AuthFlow is one login / OAuth / OIDC sequence
+type AuthFlow struct {
+	Type   string        `json:"type"`
+	Grant  string        `json:"grant,omitempty"`
+	Domain string        `json:"domain"`
+	OIDC   bool          `json:"oidc,omitempty"`
+	PKCE   bool          `json:"pkce,omitempty"`
+	Steps  []AuthStep    `json:"steps"`
+	Tokens []IssuedToken `json:"tokens"`
+}
+
+// AuthStep is one request of a flow with its role
+type AuthStep struct {
+	Role   string `json:"role"` // login, authorize, redirect, callback, token
+	ID     string `json:"id"`
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Status int    `json:"status,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// IssuedToken is a credential a flow step issued and where it was first used
+type IssuedToken struct {
+	Kind        string `json:"kind"` // access_token, id_token, refresh_token, code, cookie:<name>, ...
+	IssuedBy    string `json:"issued_by"`
+	FirstUsedBy string `json:"first_used_by,omitempty"`
+	FirstUsedAt string `json:"first_used_at,omitempty"` // Method and URL of the first use
+	UsedIn      string `json:"used_in,omitempty"`       // Where the value was sent: header name, cookie, url, body
+	value       string
+	skipID      string // The redirect target receiving it, which is delivery rather than use
+}
+
+var authflowCmd = &cobra.Command{
+	Use:   "authflow",
+	Short: "Reconstruct login, OAuth and OIDC flows from captured traffic",
+	Long: `Identify authentication sequences in the capture and lay out each one
+step by step: login form, authorize request, redirects, callback and token
+exchange, followed by where the issued credentials were first used.
+
+Detected flows:
+  OAuth 2.0 authorization code (with PKCE when code_challenge/code_verifier
+  is sent), implicit (tokens in the redirect), refresh_token,
+  client_credentials, password and device code grants; OIDC when scope
+  includes openid or an id_token is issued; and plain logins that return
+  a session cookie or a JSON token.
+
+For every issued credential (authorization code, access/id/refresh token,
+session cookie) the request that issued it and the first later request
+that sent it (Authorization header, cookie, URL or body) are shown.
+
+Checks (--check):
+  Instead of the flows, report OAuth/OIDC misconfigurations per domain with
+  the request IDs as evidence:
+    missing-state     Authorize request without (or with a guessable) state
+    state-reuse       The same state value in several authorize requests
+    redirect-uri      Plain http, bare origin or off-domain redirect_uri, or
+                      one client accepting several redirect_uri values
+    implicit-flow     response_type=token / id_token (tokens in the URL)
+    missing-pkce      Authorization code flow without code_challenge
+    plain-pkce        code_challenge_method=plain
+    token-in-url      Access tokens, session IDs or JWTs in query strings
+    token-in-referer  Codes or tokens leaking through Referer, high when
+                      sent to a third party
+
+With -d, only requests on that domain's registrable domain are considered
+(-d acme.com covers auth.acme.com and api.acme.com).
+
+Examples:
+  rep authflow                          Live capture
+  rep authflow -d acme.com              One target
+  rep authflow --saved latest -o json
+  rep authflow --check -d acme.com      Misconfiguration checks`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scope, err := baseDomainScope(authflowDomain)
+		if err != nil {
+			return err
+		}
+		requests, err := filterSource(authflowSaved, store.FilterOptions{})
+		if err != nil || requests == nil {
+			return err
+		}
+		if len(scope) > 0 {
+			kept := requests[:0]
+			for _, req := range requests {
+				if scope.covers(hostFromURL(req.URL)) {
+					kept = append(kept, req)
+				}
+			}
+			requests = kept
+		}
+		store.SortRequests(requests, "time", false)
+
+		if authflowCheck {
+			findings := checkOAuth(requests)
+			if getOutputMode() == "json" {
+				if findings == nil {
+					findings = []OAuthFinding{}
+				}
+				out, _ := sonic.MarshalIndent(map[string]interface{}{
+					"total":    len(findings),
+					"findings": findings,
+				}, "", "  ")
+				fmt.Println(string(out))
+				return nil
+			}
+			printOAuthFindings(findings)
+			return nil
+		}
+
+		flows := findAuthFlows(requests)
+
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"total": len(flows),
+				"flows": flows,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		printAuthFlows(flows)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(authflowCmd)
+	authflowCmd.Flags().StringVar(&authflowSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	authflowCmd.Flags().BoolVar(&authflowCheck, "check", false, "Report OAuth/OIDC misconfigurations instead of the flows")
+	authflowCmd.Flags().StringVarP(&authflowDomain, "domain", "d", "", "Only these registrable domains and their subdomains (comma-separated or @group)")
+}
+
+// findAuthFlows anchors a flow at every request that issues a credential
+// and is not already part of an earlier flow. Token endpoint calls are
+// anchored first so the login and authorize steps before them join their
+// flow instead of starting their own. requests are in capture order.
+func findAuthFlows(requests []store.Request) []AuthFlow {
+	var flows []AuthFlow
+	inFlow := make(map[int]bool)
+
+	var anchors, others []int
+	for i := range requests {
+		switch {
+		case !issuesCredentials(&requests[i]):
+		case requestField(&requests[i], "grant_type") != "":
+			anchors = append(anchors, i)
+		default:
+			others = append(others, i)
+		}
+	}
+
+	for _, i := range append(anchors, others...) {
+		if inFlow[i] {
+			continue
+		}
+		req := &requests[i]
+		var steps []int
+
+		grant := requestField(req, "grant_type")
+		if grant == "authorization_code" {
+			// Walk from the redirect that delivered the code: login,
+			// authorize and callback precede the token exchange
+			if code := requestField(req, "code"); code != "" {
+				if issuer := codeIssuer(requests, i, code); issuer >= 0 {
+					for _, hop := range buildRedirectChain(requests, issuer) {
+						if j := requestIndex(requests, hop.ID); j >= 0 && j < i {
+							steps = append(steps, j)
+						}
+					}
+				}
+			}
+			steps = append(steps, i)
+		} else {
+			for _, hop := range buildRedirectChain(requests, i) {
+				if j := requestIndex(requests, hop.ID); j >= 0 {
+					steps = append(steps, j)
+				}
+			}
+		}
+
+		flow := AuthFlow{Domain: hostFromURL(req.URL)}
+		for _, j := range steps {
+			inFlow[j] = true
+			step := &requests[j]
+			flow.Steps = append(flow.Steps, authStep(step))
+			flow.Tokens = append(flow.Tokens, issuedTokens(step)...)
+			if authorizeParam(step, "code_challenge") != "" || requestField(step, "code_verifier") != "" {
+				flow.PKCE = true
+			}
+			if strings.Contains(" "+authorizeParam(step, "scope")+" ", " openid ") {
+				flow.OIDC = true
+			}
+		}
+		for k := range flow.Tokens {
+			if flow.Tokens[k].Kind == "id_token" {
+				flow.OIDC = true
+			}
+			markFirstUse(requests, &flow.Tokens[k])
+		}
+		flow.Grant, flow.Type = classifyAuthFlow(requests, steps, flow)
+		flows = append(flows, flow)
+	}
+	sort.SliceStable(flows, func(a, b int) bool {
+		return requestIndex(requests, flows[a].Steps[0].ID) < requestIndex(requests, flows[b].Steps[0].ID)
+	})
+	return flows
+}
+
+// issuesCredentials reports whether a response hands out a token or a
+// session cookie. A redirect carrying only an authorization code does not
+// count: the token exchange redeeming it anchors that flow.
+func issuesCredentials(req *store.Request) bool {
+	for _, t := range issuedTokens(req) {
+		if t.Kind != "code" {
+			return true
+		}
+	}
+	return false
+}
+
+// issuedTokens lists the credentials a response issues
+func issuedTokens(req *store.Request) []IssuedToken {
+	if req.Response == nil {
+		return nil
+	}
+	var tokens []IssuedToken
+	add := func(kind, value, skipID string) {
+		if len(value) >= 6 {
+			tokens = append(tokens, IssuedToken{Kind: kind, IssuedBy: req.ID, value: value, skipID: skipID})
+		}
+	}
+
+	var fields map[string]interface{}
+	if sonic.UnmarshalString(store.ResponseBodyText(req), &fields) == nil {
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			kind, ok := issuedTokenFields[strings.ReplaceAll(strings.ToLower(name), "_", "")]
+			if value, isString := fields[name].(string); ok && isString {
+				add(kind, value, "")
+			}
+		}
+	}
+
+	if location := redirectLocation(req); location != "" {
+		if parsed, err := url.Parse(location); err == nil {
+			params := parsed.Query()
+			if fragment, err := url.ParseQuery(parsed.Fragment); err == nil {
+				for k, v := range fragment {
+					params[k] = v
+				}
+			}
+			for _, kind := range []string{"code", "access_token", "id_token"} {
+				add(kind, params.Get(kind), location)
+			}
+		}
+	}
+
+	for _, cookie := range setCookieNames(req.Response.Headers) {
+		if strings.HasSuffix(cookie, " (cleared)") || !sessionCookieName.MatchString(cookie) {
+			continue
+		}
+		for _, c := range setCookies(req.Response.Headers) {
+			if c[0] == cookie {
+				add("cookie:"+cookie, c[1], "")
+			}
+		}
+	}
+	return tokens
+}
+
+// setCookies returns the name/value pairs a response sets
+func setCookies(headers store.HeaderMap) [][2]string {
+	var pairs [][2]string
+	for _, v := range store.HeaderValues(headers, "set-cookie") {
+		pair := strings.SplitN(strings.SplitN(v, ";", 2)[0], "=", 2)
+		if len(pair) == 2 {
+			pairs = append(pairs, [2]string{strings.TrimSpace(pair[0]), strings.TrimSpace(pair[1])})
+		}
+	}
+	return pairs
+}
+
+// markFirstUse finds the first request after the issuer that sends the
+// token: a cookie in the Cookie header, anything else in a header, the URL
+// or the body. The redirect target receiving a code or token is skipped.
+func markFirstUse(requests []store.Request, t *IssuedToken) {
+	issuer := requestIndex(requests, t.IssuedBy)
+	for i := issuer + 1; i >= 1 && i < len(requests); i++ {
+		req := &requests[i]
+		if t.skipID != "" && req.URL == t.skipID {
+			continue
+		}
+		usedIn := ""
+		if name, ok := strings.CutPrefix(t.Kind, "cookie:"); ok {
+			if _, sent := sentCookies(req)[name]; sent && sentCookies(req)[name] == t.value {
+				usedIn = "cookie"
+			}
+		} else {
+			for name, values := range req.Headers {
+				for _, v := range values {
+					if strings.Contains(v, t.value) {
+						usedIn = name
+					}
+				}
+			}
+			switch {
+			case usedIn != "":
+			case strings.Contains(req.URL, t.value):
+				usedIn = "url"
+			case strings.Contains(req.Body, t.value):
+				usedIn = "body"
+			}
+		}
+		if usedIn != "" {
+			t.FirstUsedBy, t.FirstUsedAt, t.UsedIn = req.ID, req.Method+" "+req.URL, usedIn
+			return
+		}
+	}
+}
+
+// codeIssuer returns the index of the redirect that handed out an
+// authorization code, searching before the token request at limit
+func codeIssuer(requests []store.Request, limit int, code string) int {
+	for i := limit - 1; i >= 0; i-- {
+		if location := redirectLocation(&requests[i]); location != "" {
+			if parsed, err := url.Parse(location); err == nil && parsed.Query().Get("code") == code {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// authStep labels a request with its role in a flow
+func authStep(req *store.Request) AuthStep {
+	step := AuthStep{ID: req.ID, Method: req.Method, URL: req.URL, Status: responseStatus(req), Role: "redirect"}
+	parsed, _ := url.Parse(req.URL)
+	var query url.Values
+	if parsed != nil {
+		query = parsed.Query()
+	}
+	switch {
+	case requestField(req, "grant_type") != "":
+		step.Role = "token"
+		step.Detail = "grant_type=" + requestField(req, "grant_type")
+	case query.Get("response_type") != "":
+		step.Role = "authorize"
+		step.Detail = "response_type=" + query.Get("response_type")
+		if scope := query.Get("scope"); scope != "" {
+			step.Detail += " scope=" + scope
+		}
+	case query.Get("code") != "" || strings.Contains(req.URL, "#access_token=") || strings.Contains(req.URL, "#id_token="):
+		step.Role = "callback"
+	case requestField(req, "password") != "" || (req.Method == "POST" && parsed != nil && loginPath.MatchString(parsed.Path)):
+		step.Role = "login"
+	}
+	var issued []string
+	for _, t := range issuedTokens(req) {
+		issued = append(issued, t.Kind)
+	}
+	if len(issued) > 0 {
+		if step.Detail != "" {
+			step.Detail += "; "
+		}
+		step.Detail += "issues " + strings.Join(issued, ", ")
+	}
+	return step
+}
+
+// classifyAuthFlow names the grant and the flow type
+func classifyAuthFlow(requests []store.Request, steps []int, flow AuthFlow) (string, string) {
+	grant := ""
+	implicit := false
+	login := false
+	for _, j := range steps {
+		req := &requests[j]
+		if g := requestField(req, "grant_type"); g != "" {
+			grant = g
+		}
+		for _, t := range issuedTokens(req) {
+			if t.skipID != "" && t.Kind != "code" {
+				implicit = true
+			}
+		}
+		if authStep(req).Role == "login" {
+			login = true
+		}
+	}
+
+	prefix := "OAuth 2.0"
+	if flow.OIDC {
+		prefix = "OIDC"
+	}
+	switch {
+	case grant == "authorization_code":
+		name := prefix + " authorization code"
+		if flow.PKCE {
+			name += " + PKCE"
+		}
+		return grant, name
+	case grant == "urn:ietf:params:oauth:grant-type:device_code":
+		return grant, prefix + " device code"
+	case grant == "password":
+		return grant, prefix + " resource owner password"
+	case grant != "":
+		return grant, prefix + " " + strings.ReplaceAll(grant, "_", " ")
+	case implicit:
+		return "implicit", prefix + " implicit"
+	case login:
+		for _, t := range flow.Tokens {
+			if t.Kind != "code" && !strings.HasPrefix(t.Kind, "cookie:") {
+				return "", "Login (token)"
+			}
+		}
+		return "", "Login (session cookie)"
+	}
+	return "", "Session issued"
+}
+
+// requestField returns a field of a form or JSON request body
+func requestField(req *store.Request, name string) string {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return ""
+	}
+	if strings.HasPrefix(body, "{") {
+		var fields map[string]interface{}
+		if sonic.UnmarshalString(body, &fields) == nil {
+			if v, ok := fields[name].(string); ok {
+				return v
+			}
+		}
+		return ""
+	}
+	if values, err := url.ParseQuery(body); err == nil {
+		return values.Get(name)
+	}
+	return ""
+}
+
+// authorizeParam returns a query parameter of the request URL
+func authorizeParam(req *store.Request, name string) string {
+	if parsed, err := url.Parse(req.URL); err == nil {
+		return parsed.Query().Get(name)
+	}
+	return ""
+}
+
+// requestIndex returns the position of a request ID, or -1
+func requestIndex(requests []store.Request, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i := range requests {
+		if requests[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func printAuthFlows(flows []AuthFlow) {
+	if len(flows) == 0 {
+		pterm.Info.Println("No login, OAuth or OIDC flow found (no response issued a token or session cookie)")
+		return
+	}
+	pterm.DefaultSection.Printf("Auth flows (%d)\n", len(flows))
+	for n, flow := range flows {
+		fmt.Printf("%d. %s  %s\n", n+1, pterm.Bold.Sprint(flow.Type), pterm.FgGray.Sprint(flow.Domain))
+		for _, s := range flow.Steps {
+			status := "---"
+			if s.Status > 0 {
+				status = colorStatus(s.Status, fmt.Sprint(s.Status))
+			}
+			fmt.Printf("   %s %-12s %s %s %s\n", pterm.FgCyan.Sprintf("%-9s", s.Role), s.ID, status, s.Method,
+				output.SanitizeText(truncateCell(s.URL, 80)))
+			if s.Detail != "" {
+				fmt.Printf("   %-9s %s\n", "", pterm.FgGray.Sprint(output.SanitizeText(s.Detail)))
+			}
+		}
+		if len(flow.Tokens) > 0 {
+			fmt.Println("   Credentials:")
+			for _, t := range flow.Tokens {
+				use := pterm.FgGray.Sprint("never used afterwards")
+				if t.FirstUsedBy != "" {
+					use = fmt.Sprintf("first used by %s %s (%s)", t.FirstUsedBy, output.SanitizeText(truncateCell(t.FirstUsedAt, 70)), t.UsedIn)
+				}
+				fmt.Printf("     %-22s issued by %-12s %s\n", t.Kind, t.IssuedBy, use)
+			}
+		}
+		fmt.Println()
+	}
+}
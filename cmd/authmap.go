@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	authmapFilter requestFilterFlags
+	authmapSaved  string
+)
+
+// authSchemes are the credential kinds of the matrix, "none" first
+var authSchemes = []string{"none", "cookie", "bearer", "basic", "api-key"}
+
+// authSchemePriority orders the schemes an endpoint is labeled with when
+// several are sent together: the one least likely to be incidental wins
+var authSchemePriority = []string{"bearer", "api-key", "basic", "cookie"}
+
+// apiKeyHeaders and apiKeyParam identify API key credentials
+var apiKeyHeaders = []string{"x-api-key", "api-key", "apikey", "x-apikey", "x-auth-token", "x-access-token"}
+var apiKeyParam = regexp.MustCompile(`(?i)^(api[_-]?key|apikey|access[_-]?key|key|subscription-key)$`)
+
+// AuthMapEntry is one endpoint of the auth requirements map
+type AuthMapEntry struct {
+	Method   string            `json:"method"`
+	Domain   string            `json:"domain"`
+	Path     string            `json:"path"`
+	Auth     string            `json:"auth"`   // anonymous, cookie-auth, bearer-auth, basic-auth, api-key, denied, unknown
+	Matrix   map[string]string `json:"matrix"` // Scheme -> ok, denied, mixed (schemes never sent are absent)
+	Requests int               `json:"requests"`
+	Test     []string          `json:"test,omitempty"` // Access-control tests worth running
+	IDs      []string          `json:"ids"`
+	ok       map[string]bool   // Schemes sent on every successful request
+	okSeen   bool
+	sentBoth bool // A successful request sent more than one scheme
+}
+
+var authmapCmd = &cobra.Command{
+	Use:   "authmap -d <domain> [filter flags]",
+	Short: "Map which endpoints need which credentials",
+	Long: `Classify every endpoint (method, host and path template) by the
+credentials it needs, from the headers of the captured requests and the
+401/403 responses they got:
+
+  anonymous    Succeeded without credentials
+  cookie-auth  Succeeded only with cookies
+  bearer-auth  Succeeded with an Authorization: Bearer (or other token) header
+  basic-auth   Succeeded with Authorization: Basic
+  api-key      Succeeded with an API key header or key query parameter
+  denied       Only 401/403 responses were captured
+  unknown      Only other responses (404, 5xx, ...) or none
+
+The matrix shows, per credential kind sent, whether the requests carrying
+it succeeded (ok), were refused with 401/403 (denied) or both (mixed).
+When several kinds are sent together the endpoint is labeled with the
+strongest (bearer, api-key, basic, cookie).
+
+The Test column suggests where access-control testing is worthwhile:
+unauth (authenticated endpoint never tried without credentials), idor
+(object ID in the path), authz (403 despite credentials), split (several
+credentials sent together: try each alone) and write (state-changing
+endpoint open to anonymous users). Static resources are skipped.
+
+Examples:
+  rep authmap -d api.acme.test
+  rep authmap -d acme.com --api
+  rep authmap -d api.acme.test -o json
+  rep authmap --saved latest -d api.acme.test`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := authmapFilter.options()
+		if err != nil {
+			return err
+		}
+		requests, err := filterSource(authmapSaved, opts)
+		if err != nil || requests == nil {
+			return err
+		}
+		entries := buildAuthMap(requests)
+
+		if getOutputMode() == "json" {
+			counts := make(map[string]int)
+			for _, e := range entries {
+				counts[e.Auth]++
+			}
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"total":     len(entries),
+				"counts":    counts,
+				"endpoints": entries,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		printAuthMap(entries)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(authmapCmd)
+	authmapFilter.register(authmapCmd)
+	authmapCmd.Flags().StringVar(&authmapSaved, "saved", "", "Read from saved session (ID or 'latest')")
+}
+
+// requestAuthSchemes returns the credential kinds a request sends
+func requestAuthSchemes(req *store.Request) []string {
+	var schemes []string
+	for name := range sentCookies(req) {
+		if sessionCookieName.MatchString(name) {
+			schemes = append(schemes, "cookie")
+			break
+		}
+	}
+	if authz := store.HeaderFirst(req.Headers, "authorization"); authz != "" {
+		if strings.HasPrefix(strings.ToLower(authz), "basic ") {
+			schemes = append(schemes, "basic")
+		} else {
+			schemes = append(schemes, "bearer")
+		}
+	}
+	apiKey := false
+	for _, name := range apiKeyHeaders {
+		if store.HeaderFirst(req.Headers, name) != "" {
+			apiKey = true
+		}
+	}
+	if parsed, err := url.Parse(req.URL); err == nil {
+		for name := range parsed.Query() {
+			if apiKeyParam.MatchString(name) {
+				apiKey = true
+			}
+		}
+	}
+	if apiKey {
+		schemes = append(schemes, "api-key")
+	}
+	if len(schemes) == 0 {
+		schemes = []string{"none"}
+	}
+	return schemes
+}
+
+// buildAuthMap classifies the endpoints of requests by required credentials
+func buildAuthMap(requests []store.Request) []AuthMapEntry {
+	index := make(map[string]*AuthMapEntry)
+	var order []string
+	for i := range requests {
+		req := &requests[i]
+		if containsString(staticResourceTypes, req.ResourceType) {
+			continue
+		}
+		parsed, err := url.Parse(req.URL)
+		if err != nil || parsed.Host == "" {
+			continue
+		}
+		template := store.EndpointTemplate(parsed.Path)
+		key := req.Method + " " + parsed.Host + template
+		e := index[key]
+		if e == nil {
+			e = &AuthMapEntry{Method: req.Method, Domain: parsed.Host, Path: template, Matrix: make(map[string]string)}
+			index[key] = e
+			order = append(order, key)
+		}
+		e.Requests++
+		e.IDs = append(e.IDs, req.ID)
+
+		status := responseStatus(req)
+		outcome := ""
+		switch {
+		case status == 401 || status == 403:
+			outcome = "denied"
+		case status > 0 && status < 400:
+			outcome = "ok"
+		default:
+			continue
+		}
+		schemes := requestAuthSchemes(req)
+		for _, scheme := range schemes {
+			switch e.Matrix[scheme] {
+			case "":
+				e.Matrix[scheme] = outcome
+			case outcome:
+			default:
+				e.Matrix[scheme] = "mixed"
+			}
+		}
+		if outcome == "denied" {
+			if schemes[0] != "none" && status == 403 && !containsString(e.Test, "authz") {
+				e.Test = append(e.Test, "authz")
+			}
+			continue
+		}
+		if len(schemes) > 1 {
+			e.sentBoth = true
+		}
+		sent := make(map[string]bool)
+		for _, scheme := range schemes {
+			sent[scheme] = true
+		}
+		if !e.okSeen {
+			e.ok, e.okSeen = sent, true
+			continue
+		}
+		for scheme := range e.ok {
+			if !sent[scheme] {
+				delete(e.ok, scheme)
+			}
+		}
+	}
+
+	entries := make([]AuthMapEntry, 0, len(order))
+	for _, key := range order {
+		e := index[key]
+		e.Auth = authMapVerdict(e)
+		if e.Auth != "anonymous" && e.Auth != "unknown" && e.Auth != "denied" {
+			if _, tried := e.Matrix["none"]; !tried {
+				e.Test = append([]string{"unauth"}, e.Test...)
+			}
+			if strings.Contains(e.Path, "{id}") {
+				e.Test = append(e.Test, "idor")
+			}
+			if e.sentBoth {
+				e.Test = append(e.Test, "split")
+			}
+		}
+		if e.Auth == "anonymous" && isStateChanging(e.Method) {
+			e.Test = append(e.Test, "write")
+		}
+		entries = append(entries, *e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Domain != entries[j].Domain {
+			return entries[i].Domain < entries[j].Domain
+		}
+		if entries[i].Path != entries[j].Path {
+			return entries[i].Path < entries[j].Path
+		}
+		return entries[i].Method < entries[j].Method
+	})
+	return entries
+}
+
+// authMapVerdict labels an endpoint from its outcomes
+func authMapVerdict(e *AuthMapEntry) string {
+	if e.Matrix["none"] == "ok" || e.Matrix["none"] == "mixed" {
+		return "anonymous"
+	}
+	if !e.okSeen {
+		if len(e.Matrix) > 0 {
+			return "denied"
+		}
+		return "unknown"
+	}
+	for _, scheme := range authSchemePriority {
+		if e.ok[scheme] {
+			return authSchemeLabel(scheme)
+		}
+	}
+	for _, scheme := range authSchemePriority {
+		if e.Matrix[scheme] == "ok" || e.Matrix[scheme] == "mixed" {
+			return authSchemeLabel(scheme)
+		}
+	}
+	return "unknown"
+}
+
+func authSchemeLabel(scheme string) string {
+	if scheme == "api-key" {
+		return scheme
+	}
+	return scheme + "-auth"
+}
+
+func printAuthMap(entries []AuthMapEntry) {
+	if len(entries) == 0 {
+		pterm.Info.Println("No requests match the filter")
+		return
+	}
+	header := []string{"Endpoint", "Auth"}
+	header = append(header, authSchemes...)
+	header = append(header, "Test")
+	tableData := pterm.TableData{header}
+	counts := make(map[string]int)
+	for _, e := range entries {
+		counts[e.Auth]++
+		auth := e.Auth
+		switch e.Auth {
+		case "anonymous":
+			auth = pterm.FgYellow.Sprint(auth)
+		case "denied":
+			auth = pterm.FgRed.Sprint(auth)
+		case "unknown":
+			auth = pterm.FgGray.Sprint(auth)
+		default:
+			auth = pterm.FgGreen.Sprint(auth)
+		}
+		row := []string{truncateCell(output.SanitizeText(e.Method+" "+e.Domain+e.Path), 70), auth}
+		for _, scheme := range authSchemes {
+			cell := "-"
+			switch e.Matrix[scheme] {
+			case "ok":
+				cell = pterm.FgGreen.Sprint("ok")
+			case "denied":
+				cell = pterm.FgRed.Sprint("denied")
+			case "mixed":
+				cell = pterm.FgYellow.Sprint("mixed")
+			}
+			row = append(row, cell)
+		}
+		row = append(row, strings.Join(e.Test, ","))
+		tableData = append(tableData, row)
+	}
+	pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+
+	var parts []string
+	for _, label := range []string{"anonymous", "cookie-auth", "bearer-auth", "basic-auth", "api-key", "denied", "unknown"} {
+		if counts[label] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", label, counts[label]))
+		}
+	}
+	fmt.Printf("\nTotal: %d endpoints (%s)\n", len(entries), strings.Join(parts, ", "))
+}
@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/replay"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// CredentialRotation is how one session cookie or bearer token changed over
+// a capture
+type CredentialRotation struct {
+	Name               string            `json:"name"`   // cookie:<name> or authorization
+	Domain             string            `json:"domain"` // Registrable domain it was sent to
+	Lifetime           string            `json:"lifetime,omitempty"`
+	Rotations          int               `json:"rotations"`
+	AvgRotationSeconds float64           `json:"avg_rotation_seconds,omitempty"` // Mean time between new values
+	Values             []CredentialValue `json:"values"`
+	StaleUse           []string          `json:"stale_use"` // Requests answered below 400 with a replaced value
+	Fixation           []string          `json:"fixation"`
+	Replay             *RotationReplay   `json:"replay,omitempty"`
+}
+
+// CredentialValue is one value of a rotating credential
+type CredentialValue struct {
+	Preview     string  `json:"preview"`
+	IssuedBy    string  `json:"issued_by,omitempty"` // Response that set it
+	FirstSeen   string  `json:"first_seen"`
+	LastSeen    string  `json:"last_seen"`
+	Requests    int     `json:"requests"`     // Requests that sent it
+	SeenSeconds float64 `json:"seen_seconds"` // First to last sighting
+	value       string
+	first, last int // Indexes in the capture
+	sentBefore  bool
+}
+
+// RotationReplay is the --replay check of a replaced value
+type RotationReplay struct {
+	RequestID string `json:"request_id"` // Request re-sent with the old value
+	Status    int    `json:"status,omitempty"`
+	Captured  int    `json:"captured_status,omitempty"`
+	Accepted  bool   `json:"accepted"`
+	Error     string `json:"error,omitempty"`
+}
+
+// analyzeRotation follows every session cookie and bearer token through the
+// capture, in order. domain, when set, keeps its registrable domain only.
+func analyzeRotation(requests []store.Request, domain string) []*CredentialRotation {
+	base := ""
+	if domain != "" {
+		base = store.GetBaseDomain(strings.ToLower(domain))
+	}
+	tracks := make(map[string]*CredentialRotation)
+	var order []string
+	track := func(site, name string) *CredentialRotation {
+		key := site + " " + name
+		if tracks[key] == nil {
+			tracks[key] = &CredentialRotation{Name: name, Domain: site, StaleUse: []string{}, Fixation: []string{}}
+			order = append(order, key)
+		}
+		return tracks[key]
+	}
+
+	for i := range requests {
+		req := &requests[i]
+		site := store.GetBaseDomain(hostFromURL(req.URL))
+		if base != "" && site != base {
+			continue
+		}
+		for name, value := range sentCookies(req) {
+			if sessionCookieName.MatchString(name) && value != "" {
+				observeSent(track(site, "cookie:"+name), requests, i, value)
+			}
+		}
+		if auth := store.HeaderFirst(req.Headers, "authorization"); strings.HasPrefix(strings.ToLower(auth), "bearer ") {
+			observeSent(track(site, "authorization"), requests, i, strings.TrimSpace(auth[7:]))
+		}
+		if req.Response == nil {
+			continue
+		}
+		resp := http.Response{Header: http.Header{"Set-Cookie": store.HeaderValues(req.Response.Headers, "set-cookie")}}
+		for _, c := range resp.Cookies() {
+			if !sessionCookieName.MatchString(c.Name) || c.Value == "" || c.MaxAge < 0 {
+				continue
+			}
+			t := track(site, "cookie:"+c.Name)
+			t.Lifetime = cookieLifetime(c, req)
+			v := credentialValue(t, c.Value, i, req.ID)
+			if v.IssuedBy == "" {
+				v.IssuedBy = req.ID
+			}
+			if v.sentBefore {
+				t.Fixation = append(t.Fixation, fmt.Sprintf("%s set %s to a value the client had already sent", req.ID, c.Name))
+			}
+		}
+	}
+
+	var out []*CredentialRotation
+	for _, key := range order {
+		t := tracks[key]
+		t.Rotations = len(t.Values) - 1
+		for k := range t.Values {
+			v := &t.Values[k]
+			v.SeenSeconds = float64(requests[v.last].Timestamp-requests[v.first].Timestamp) / 1000
+		}
+		if t.Rotations > 0 {
+			span := requests[t.Values[len(t.Values)-1].first].Timestamp - requests[t.Values[0].first].Timestamp
+			t.AvgRotationSeconds = float64(span) / 1000 / float64(t.Rotations)
+		}
+		t.Fixation = append(t.Fixation, fixationIndicators(t, requests)...)
+		out = append(out, t)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Rotations != out[j].Rotations {
+			return out[i].Rotations > out[j].Rotations
+		}
+		return len(out[i].Fixation) > len(out[j].Fixation)
+	})
+	return out
+}
+
+// credentialValue returns t's entry for value, adding it when new
+func credentialValue(t *CredentialRotation, value string, i int, id string) *CredentialValue {
+	for k := range t.Values {
+		if t.Values[k].value == value {
+			return &t.Values[k]
+		}
+	}
+	t.Values = append(t.Values, CredentialValue{Preview: maskToken(value), FirstSeen: id, LastSeen: id, value: value, first: i, last: i})
+	return &t.Values[len(t.Values)-1]
+}
+
+// observeSent records that requests[i] sent value
+func observeSent(t *CredentialRotation, requests []store.Request, i int, value string) {
+	req := &requests[i]
+	v := credentialValue(t, value, i, req.ID)
+	v.Requests++
+	v.LastSeen, v.last, v.sentBefore = req.ID, i, true
+	// A replaced value still answered normally
+	if newest := &t.Values[len(t.Values)-1]; newest.value != value && newest.first < i {
+		if status := responseStatus(req); status > 0 && status < 400 {
+			t.StaleUse = append(t.StaleUse, req.ID)
+		}
+	}
+}
+
+// fixationIndicators flags a value that survives a login, and session
+// values carried in URLs
+func fixationIndicators(t *CredentialRotation, requests []store.Request) []string {
+	var found []string
+	name, isCookie := strings.CutPrefix(t.Name, "cookie:")
+	for _, v := range t.Values {
+		if isCookie {
+			for i := v.first; i < v.last; i++ {
+				req := &requests[i]
+				if authStep(req).Role != "login" || sentCookies(req)[name] != v.value {
+					continue
+				}
+				reissued := false
+				for _, c := range setCookies(responseHeaders(req)) {
+					if c[0] == name && c[1] != v.value {
+						reissued = true
+					}
+				}
+				if !reissued {
+					found = append(found, fmt.Sprintf("%s kept across login %s (sent before and after it)", v.Preview, req.ID))
+				}
+			}
+		}
+		if len(v.value) >= minAuthValueLength {
+			for i := range requests {
+				if strings.Contains(requests[i].URL, v.value) {
+					found = append(found, fmt.Sprintf("%s carried in a URL by %s", v.Preview, requests[i].ID))
+					break
+				}
+			}
+		}
+	}
+	return found
+}
+
+func responseHeaders(req *store.Request) store.HeaderMap {
+	if req.Response == nil {
+		return nil
+	}
+	return req.Response.Headers
+}
+
+// cookieLifetime is the lifetime a Set-Cookie declares: "session" without
+// Max-Age or Expires
+func cookieLifetime(c *http.Cookie, req *store.Request) string {
+	switch {
+	case c.MaxAge > 0:
+		return formatSeconds(float64(c.MaxAge))
+	case !c.Expires.IsZero():
+		issued := time.UnixMilli(req.Timestamp)
+		if date, err := http.ParseTime(store.HeaderFirst(req.Response.Headers, "date")); err == nil {
+			issued = date
+		}
+		if ttl := c.Expires.Sub(issued); ttl > 0 {
+			return formatSeconds(ttl.Seconds())
+		}
+		return "expired"
+	}
+	return "session"
+}
+
+func formatSeconds(s float64) string {
+	return (time.Duration(s) * time.Second).String()
+}
+
+// replayRotation re-sends the last GET that carried the current value with
+// the value it replaced, for each rotated credential
+func replayRotation(tracks []*CredentialRotation, requests []store.Request) {
+	for _, t := range tracks {
+		if t.Rotations == 0 {
+			continue
+		}
+		current, old := t.Values[len(t.Values)-1], t.Values[len(t.Values)-2]
+		var template *store.Request
+		for i := current.last; i >= current.first; i-- {
+			req := &requests[i]
+			if req.Method == "GET" && req.Response != nil && store.GetBaseDomain(hostFromURL(req.URL)) == t.Domain && credentialSent(req, t.Name) == current.value {
+				template = req
+				break
+			}
+		}
+		if template == nil {
+			continue
+		}
+
+		sendReq, _ := resolveSendRequest(template, false)
+		if name, ok := strings.CutPrefix(t.Name, "cookie:"); ok {
+			var pairs []string
+			for _, header := range store.HeaderValues(sendReq.Headers, "cookie") {
+				for _, pair := range strings.Split(header, ";") {
+					pair = strings.TrimSpace(pair)
+					if k, _, _ := strings.Cut(pair, "="); k == name {
+						pair = name + "=" + old.value
+					}
+					pairs = append(pairs, pair)
+				}
+			}
+			store.SetHeader(sendReq.Headers, "Cookie", strings.Join(pairs, "; "))
+		} else {
+			store.SetHeader(sendReq.Headers, "Authorization", "Bearer "+old.value)
+		}
+
+		r := &RotationReplay{RequestID: template.ID, Captured: template.Response.Status}
+		result, err := replay.Send(context.Background(), withMappedValues(sendReq), replay.Options{})
+		if err != nil {
+			r.Error = err.Error()
+		} else {
+			r.Status = result.Status
+			r.Accepted = result.Status < 400 && result.Status == r.Captured
+		}
+		t.Replay = r
+	}
+}
+
+// credentialSent is the value of a tracked credential a request sent
+func credentialSent(req *store.Request, name string) string {
+	if cookie, ok := strings.CutPrefix(name, "cookie:"); ok {
+		return sentCookies(req)[cookie]
+	}
+	auth := store.HeaderFirst(req.Headers, "authorization")
+	if strings.HasPrefix(strings.ToLower(auth), "bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+func printAuthRotation(tracks []*CredentialRotation) {
+	pterm.DefaultSection.Printf("Session credential rotation (%d credentials)\n", len(tracks))
+	for _, t := range tracks {
+		header := fmt.Sprintf("%s  %s", pterm.FgCyan.Sprint(output.SanitizeText(t.Name)), t.Domain)
+		if t.Lifetime != "" {
+			header += "  lifetime " + t.Lifetime
+		}
+		fmt.Printf("\n%s\n", header)
+		if t.Rotations == 0 {
+			fmt.Println("  never rotated")
+		} else {
+			fmt.Printf("  %d values, %d rotation(s), every ~%s\n", len(t.Values), t.Rotations, formatSeconds(t.AvgRotationSeconds))
+		}
+		for k, v := range t.Values {
+			issued := ""
+			if v.IssuedBy != "" {
+				issued = "issued by " + v.IssuedBy + ", "
+			}
+			fmt.Printf("   %d. %-14s %ssent %d time(s), %s … %s (%s)\n", k+1, output.SanitizeText(v.Preview), issued,
+				v.Requests, v.FirstSeen, v.LastSeen, formatSeconds(v.SeenSeconds))
+		}
+		if len(t.StaleUse) > 0 {
+			fmt.Printf("  %s replaced value answered normally in %s\n", pterm.FgYellow.Sprint("!"), strings.Join(t.StaleUse, ", "))
+		}
+		for _, f := range t.Fixation {
+			fmt.Printf("  %s fixation: %s\n", pterm.FgRed.Sprint("!"), f)
+		}
+		if r := t.Replay; r != nil {
+			switch {
+			case r.Error != "":
+				fmt.Printf("  replay of %s with the old value failed: %s\n", r.RequestID, r.Error)
+			case r.Accepted:
+				fmt.Printf("  %s old value still accepted: %s → %d (captured %d)\n", pterm.FgRed.Sprint("!"), r.RequestID, r.Status, r.Captured)
+			default:
+				fmt.Printf("  old value rejected: %s → %d (captured %d)\n", r.RequestID, r.Status, r.Captured)
+			}
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// authWhereEndpoints caps the endpoints listed per domain in human output
+const authWhereEndpoints = 5
+
+// TokenUsage is where one extracted credential was sent
+type TokenUsage struct {
+	Name       string        `json:"name"`
+	Preview    string        `json:"preview"` // Masked value
+	Source     string        `json:"source"`  // Header it was extracted from
+	Domain     string        `json:"domain"`  // Domain it was extracted from
+	Requests   int           `json:"requests"`
+	ThirdParty []string      `json:"third_party"` // Domains outside the token's base domain
+	Domains    []TokenDomain `json:"domains"`
+}
+
+// TokenDomain is a domain a credential was sent to
+type TokenDomain struct {
+	Domain     string          `json:"domain"`
+	ThirdParty bool            `json:"third_party"`
+	Requests   int             `json:"requests"`
+	Endpoints  []TokenEndpoint `json:"endpoints"`
+}
+
+// TokenEndpoint is an endpoint a credential was sent to, and how
+type TokenEndpoint struct {
+	Endpoint string   `json:"endpoint"` // METHOD /path/{id}
+	Count    int      `json:"count"`
+	Via      []string `json:"via"` // Header names, "query", "url" or "body"
+}
+
+// tokenUsages finds every request that carried each token, in a header, the
+// URL or the body
+func tokenUsages(tokens []AuthToken, requests []store.Request) []TokenUsage {
+	for i := range requests {
+		if m := requests[i].Method; m != "GET" && m != "HEAD" {
+		}
+	}
+
+	usages := make([]TokenUsage, 0, len(tokens))
+	for _, t := range tokens {
+		u := TokenUsage{Name: t.Name, Preview: maskToken(t.Value), Source: t.Source, Domain: t.Domain, ThirdParty: []string{}}
+		byDomain := make(map[string]*TokenDomain)
+		endpointIndex := make(map[string]int) // domain + endpoint -> index in its Endpoints
+		for i := range requests {
+			req := &requests[i]
+			via := tokenCarriers(req, t.Value)
+			if len(via) == 0 {
+				continue
+			}
+			if req.Domain == "" {
+				store.ComputeRequestFields(req)
+			}
+			u.Requests++
+			d := byDomain[req.Domain]
+			if d == nil {
+				d = &TokenDomain{Domain: req.Domain, ThirdParty: !store.IsFirstParty(req.Domain, t.Domain)}
+				byDomain[req.Domain] = d
+			}
+			d.Requests++
+			endpoint := req.Method + " " + store.EndpointTemplate(req.Path)
+			idx, ok := endpointIndex[req.Domain+" "+endpoint]
+			if !ok {
+				idx = len(d.Endpoints)
+				endpointIndex[req.Domain+" "+endpoint] = idx
+				d.Endpoints = append(d.Endpoints, TokenEndpoint{Endpoint: endpoint})
+			}
+			e := &d.Endpoints[idx]
+			e.Count++
+			for _, v := range via {
+				if !containsString(e.Via, v) {
+					e.Via = append(e.Via, v)
+				}
+			}
+		}
+
+		for _, d := range byDomain {
+			sort.SliceStable(d.Endpoints, func(i, j int) bool { return d.Endpoints[i].Count > d.Endpoints[j].Count })
+			u.Domains = append(u.Domains, *d)
+			if d.ThirdParty {
+				u.ThirdParty = append(u.ThirdParty, d.Domain)
+			}
+		}
+		// Third-party domains first, then by traffic
+		sort.Slice(u.Domains, func(i, j int) bool {
+			a, b := u.Domains[i], u.Domains[j]
+			if a.ThirdParty != b.ThirdParty {
+				return a.ThirdParty
+			}
+			if a.Requests != b.Requests {
+				return a.Requests > b.Requests
+			}
+			return a.Domain < b.Domain
+		})
+		sort.Strings(u.ThirdParty)
+		if u.Domains == nil {
+			u.Domains = []TokenDomain{}
+		}
+		usages = append(usages, u)
+	}
+
+	// Leaking tokens first, then the most widely sent
+	sort.SliceStable(usages, func(i, j int) bool {
+		if len(usages[i].ThirdParty) != len(usages[j].ThirdParty) {
+			return len(usages[i].ThirdParty) > len(usages[j].ThirdParty)
+		}
+		return usages[i].Requests > usages[j].Requests
+	})
+	return usages
+}
+
+// tokenCarriers lists where req carries value: header names, the query
+// string, elsewhere in the URL, or the body
+func tokenCarriers(req *store.Request, value string) []string {
+	if value == "" {
+		return nil
+	}
+	var via []string
+	for _, name := range sortedHeaderKeys(req.Headers) {
+		if strings.HasPrefix(name, ":") {
+			continue
+		}
+		for _, v := range req.Headers[name] {
+			if strings.Contains(v, value) {
+				via = append(via, strings.ToLower(name))
+				break
+			}
+		}
+	}
+	if strings.Contains(req.URL, value) || strings.Contains(req.URL, url.QueryEscape(value)) {
+		where := "url"
+		if parsed, err := url.Parse(req.URL); err == nil && (strings.Contains(parsed.RawQuery, value) || strings.Contains(parsed.RawQuery, url.QueryEscape(value))) {
+			where = "query"
+		}
+		via = append(via, where)
+	}
+	if req.Body != "" && strings.Contains(req.Body, value) {
+		via = append(via, "body")
+	}
+	return via
+}
+
+// maskToken shows the start and end of a credential
+func maskToken(value string) string {
+	if len(value) <= 12 {
+		return value[:len(value)/3] + "…"
+	}
+	return value[:6] + "…" + value[len(value)-4:]
+}
+
+func printAuthWhere(usages []TokenUsage) {
+	pterm.DefaultSection.Printf("Auth token usage (%d tokens)\n", len(usages))
+	leaking := 0
+	for _, u := range usages {
+		fmt.Printf("\n%s %s  from %s (%s), sent with %d request(s)\n", pterm.FgCyan.Sprint(u.Name),
+			output.SanitizeText(u.Preview), u.Domain, u.Source, u.Requests)
+		if len(u.ThirdParty) > 0 {
+			leaking++
+		}
+		for _, d := range u.Domains {
+			label := fmt.Sprintf("%-30s %d request(s)", d.Domain, d.Requests)
+			if d.ThirdParty {
+				label = pterm.FgRed.Sprint(label + "  third-party")
+			}
+			fmt.Printf("  %s\n", label)
+			for i, e := range d.Endpoints {
+				if i == authWhereEndpoints {
+					fmt.Printf("      ... %d more endpoints (-o json for all)\n", len(d.Endpoints)-i)
+					break
+				}
+				fmt.Printf("      %-40s ×%d  via %s\n", output.SanitizeText(truncateCell(e.Endpoint, 40)), e.Count, strings.Join(e.Via, ", "))
+			}
+		}
+	}
+	fmt.Println()
+	if leaking > 0 {
+		pterm.Warning.Printf("%d token(s) sent to third-party domains\n", leaking)
+	} else {
+		pterm.Info.Println("No token was sent outside its own base domain")
+	}
+}
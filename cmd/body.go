@@ -2,15 +2,23 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/bytedance/sonic"
 	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/htmltext"
+	"github.com/repplus/rep-cli/internal/jsonpath"
+	"github.com/repplus/rep-cli/internal/output"
 	"github.com/repplus/rep-cli/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var (
 	bodyRequest bool
+	bodyText    bool
+	bodyLinks   bool
+	bodyPath    string
+	bodyPaths   bool
 )
 
 var bodyCmd = &cobra.Command{
@@ -21,10 +29,29 @@ var bodyCmd = &cobra.Command{
 Use this when you need to analyze the full content after
 identifying interesting requests with 'rep list'.
 
+HTML triage:
+  --text    Readable text: scripts, styles and tags stripped, entities decoded
+  --links   URLs from href, src, (form)action, srcset and data-src/href/url
+            attributes, resolved against the page URL (forms show their method)
+
+JSON bodies:
+  --path    Print only the value(s) at a jq-like path:
+              .data.users[0].email   ["odd key"]   [-1] last element
+              .items[].id            [] or .* for every element or member
+              ..token                a key at any depth
+  --paths   List every leaf path with its type; array indices are folded
+            into [] so each path works with --path
+
 Examples:
   rep body req_42              Get response body
   rep body req_42 --request    Get request body instead
-  rep body req_42 -o json      Output as JSON`,
+  rep body req_42 -o json      Output as JSON
+  rep body req_42 --text       Page text without markup
+  rep body req_42 --links      Every URL the page references
+  rep body req_42 --links -o json | jq -r '.links[].url'
+  rep body req_42 --paths                  What's in a large JSON body
+  rep body req_42 --path '.data.users[0].email'
+  rep body req_42 --path '..csrfToken'`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		requestID := args[0]
@@ -32,14 +59,11 @@ Examples:
 		var req *store.Request
 
 		// Try live.json first (current session)
-		livePath, err := store.GetLiveFilePath()
-		if err == nil {
-			if export, err := loadLiveExport(livePath); err == nil {
-				for i := range export.Requests {
-					if export.Requests[i].ID == requestID {
-						req = &export.Requests[i]
-						break
-					}
+		if export, err := loadLiveMerged(); err == nil {
+			for i := range export.Requests {
+				if export.Requests[i].ID == requestID {
+					req = &export.Requests[i]
+					break
 				}
 			}
 		}
@@ -54,7 +78,23 @@ Examples:
 		}
 
 		if req == nil {
-			return fmt.Errorf("request not found: %s", requestID)
+			return notFoundError("request not found: %s", requestID)
+		}
+
+		views := 0
+		for _, set := range []bool{bodyText, bodyLinks, bodyPath != "", bodyPaths} {
+			if set {
+				views++
+			}
+		}
+		if views > 1 {
+			return usageError("use only one of --text, --links, --path and --paths")
+		}
+		if bodyPath != "" || bodyPaths {
+			return printJSONPaths(req)
+		}
+		if bodyText || bodyLinks {
+			return printHTMLTriage(req)
 		}
 
 		if getOutputMode() == "json" {
@@ -64,18 +104,23 @@ Examples:
 				"url":    req.URL,
 			}
 
+			var report *budgetReport
 			if bodyRequest {
-				output["body"] = req.Body
+				output["body"], report = fitBody(req.ID, req.Body, store.HeaderFirst(req.Headers, "content-type"))
 				output["type"] = "request"
 			} else {
 				if req.Response != nil {
 					output["status"] = req.Response.Status
-					output["body"] = req.Response.Body
+					output["body"], report = fitBody(req.ID, req.Response.Body, store.HeaderFirst(req.Response.Headers, "content-type"))
 					output["headers"] = req.Response.Headers
+					if n := len(req.Response.Events); n > 0 {
+						output["events"] = n
+					}
 				}
 				output["type"] = "response"
 			}
 
+			report.warn()
 			out, _ := sonic.MarshalIndent(output, "", "  ")
 			fmt.Println(string(out))
 		} else {
@@ -104,7 +149,9 @@ func printRequestBody(req *store.Request) {
 
 	fmt.Printf("Content-Type: %s\n", contentType)
 	fmt.Printf("Size: %d bytes\n\n", len(req.Body))
-	fmt.Println(req.Body)
+	body, report := fitBody(req.ID, req.Body, contentType)
+	fmt.Println(body)
+	report.print()
 }
 
 func printResponseBody(req *store.Request) {
@@ -119,6 +166,10 @@ func printResponseBody(req *store.Request) {
 	fmt.Printf("  Status: %d\n\n", req.Response.Status)
 
 	if req.Response.Body == "" {
+		if n := len(req.Response.Events); n > 0 {
+			pterm.Info.Printf("Streaming response: %d events captured (rep sse %s)\n", n, req.ID)
+			return
+		}
 		pterm.Info.Println("Empty response body")
 		return
 	}
@@ -128,10 +179,160 @@ func printResponseBody(req *store.Request) {
 
 	fmt.Printf("Content-Type: %s\n", contentType)
 	fmt.Printf("Size: %d bytes\n\n", len(req.Response.Body))
-	fmt.Println(req.Response.Body)
+	body, report := fitBody(req.ID, req.Response.Body, contentType)
+	fmt.Println(body)
+	report.print()
+}
+
+// printHTMLTriage prints the text (--text) or links (--links) of an HTML
+// body (the request body with --request)
+func printHTMLTriage(req *store.Request) error {
+	body, contentType := store.ResponseBodyText(req), ""
+	if req.Response != nil {
+		contentType = store.HeaderFirst(req.Response.Headers, "content-type")
+	}
+	if bodyRequest {
+		body, contentType = req.Body, store.HeaderFirst(req.Headers, "content-type")
+	}
+	if body == "" {
+		pterm.Info.Println("Empty body")
+		return nil
+	}
+	jsonMode := getOutputMode() == "json"
+	if !jsonMode && contentType != "" && !strings.Contains(strings.ToLower(contentType), "html") {
+		pterm.Warning.Printf("Body is %s, not HTML; extracting anyway\n", contentType)
+	}
+
+	if bodyText {
+		text := htmltext.Text(body)
+		if jsonMode {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"id":   req.ID,
+				"url":  req.URL,
+				"text": text,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		fmt.Println(output.SanitizeText(text))
+		return nil
+	}
+
+	// Relative URLs resolve against the page that served the body
+	links := htmltext.Links(body, req.URL)
+	if jsonMode {
+		if links == nil {
+			links = []htmltext.Link{}
+		}
+		out, _ := sonic.MarshalIndent(map[string]interface{}{
+			"id":    req.ID,
+			"url":   req.URL,
+			"links": links,
+		}, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+	if len(links) == 0 {
+		return softFail(emptyError("No links found"))
+	}
+	for _, link := range links {
+		label := link.Tag + " " + link.Attr
+		if link.Method != "" {
+			label += " " + link.Method
+		}
+		fmt.Printf("  %s %s\n", pterm.FgCyan.Sprintf("%-18s", label), output.SanitizeText(link.URL))
+	}
+	fmt.Println()
+	pterm.Info.Printf("%d links\n", len(links))
+	return nil
+}
+
+// printJSONPaths prints the value at --path, or the leaf paths (--paths), of
+// a JSON body (the request body with --request)
+func printJSONPaths(req *store.Request) error {
+	body, which := store.ResponseBodyText(req), "response"
+	if bodyRequest {
+		body, which = req.Body, "request"
+	}
+	if strings.TrimSpace(body) == "" {
+		return softFail(emptyError("Empty %s body", which))
+	}
+	jsonMode := getOutputMode() == "json"
+
+	if bodyPaths {
+		leaves, err := jsonpath.Leaves(body)
+		if err != nil {
+			return parseError("%s body of %s is not JSON: %v", which, req.ID, err)
+		}
+		if jsonMode {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"id":    req.ID,
+				"url":   req.URL,
+				"paths": leaves,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		width := 0
+		for _, leaf := range leaves {
+			if len(leaf.Path) > width && len(leaf.Path) <= 60 {
+				width = len(leaf.Path)
+			}
+		}
+		for _, leaf := range leaves {
+			count := ""
+			if leaf.Count > 1 {
+				count = fmt.Sprintf(" (%d)", leaf.Count)
+			}
+			fmt.Printf("  %-*s  %s%s\n", width, output.SanitizeText(leaf.Path),
+				pterm.FgGray.Sprint(strings.Join(leaf.Types, "|")), count)
+		}
+		fmt.Println()
+		pterm.Info.Printf("%d paths. Read one with: rep body %s --path '<path>'\n", len(leaves), req.ID)
+		return nil
+	}
+
+	path, err := jsonpath.Parse(bodyPath)
+	if err != nil {
+		return usageError("bad --path %q: %v", bodyPath, err)
+	}
+	doc, err := jsonpath.Decode(body)
+	if err != nil {
+		return parseError("%s body of %s is not JSON: %v", which, req.ID, err)
+	}
+	values := jsonpath.Get(doc, path)
+	if len(values) == 0 {
+		return notFoundError("no value at %s in %s", bodyPath, req.ID).
+			withHint(fmt.Sprintf("List the paths with: rep body %s --paths", req.ID))
+	}
+
+	if jsonMode {
+		out, _ := sonic.MarshalIndent(map[string]interface{}{
+			"id":     req.ID,
+			"url":    req.URL,
+			"path":   bodyPath,
+			"values": values,
+		}, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+	// Strings print raw, like jq -r; anything else as JSON
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			fmt.Println(output.SanitizeText(s))
+			continue
+		}
+		out, _ := sonic.MarshalIndent(v, "", "  ")
+		fmt.Println(string(out))
+	}
+	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(bodyCmd)
 	bodyCmd.Flags().BoolVarP(&bodyRequest, "request", "r", false, "Get request body instead of response")
+	bodyCmd.Flags().BoolVar(&bodyText, "text", false, "Strip HTML tags and scripts, print readable text")
+	bodyCmd.Flags().BoolVar(&bodyLinks, "links", false, "List href/src/form-action URLs in an HTML body")
+	bodyCmd.Flags().StringVar(&bodyPath, "path", "", "Print only the value(s) at a jq-like path in a JSON body (e.g. '.data.users[0].email')")
+	bodyCmd.Flags().BoolVar(&bodyPaths, "paths", false, "List the leaf paths of a JSON body with their types")
 }
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// maxTokens is the --max-tokens flag
+var maxTokens int
+
+// charsPerToken is the usual chars-per-token ratio of LLM tokenizers on
+// English, code and JSON; budgets are approximate by design
+const charsPerToken = 4
+
+// budgetBodySizes are the body limits tried, largest first, before bodies
+// are dropped
+var budgetBodySizes = []int{500, 200, 100, 50}
+
+// tokenBudget returns the --max-tokens budget, or $REP_MAX_TOKENS when the
+// flag isn't given; 0 means unlimited
+func tokenBudget() int {
+	if rootCmd.PersistentFlags().Changed("max-tokens") {
+		if maxTokens < 0 {
+			return 0
+		}
+		return maxTokens
+	}
+	if n, err := strconv.Atoi(os.Getenv("REP_MAX_TOKENS")); err == nil && n > 0 {
+		return n
+	}
+	return 0
+}
+
+// estimateTokens approximates the tokens text costs
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// jsonTokens approximates the tokens of v as JSON
+func jsonTokens(v interface{}) int {
+	out, _ := sonic.Marshal(v)
+	return estimateTokens(string(out))
+}
+
+// budgetReport records what trimming to the token budget left out and the
+// commands that fetch it
+type budgetReport struct {
+	budget  int
+	omitted []string
+	fetch   []string
+}
+
+func (r *budgetReport) add(omitted, fetch string) {
+	r.omitted = append(r.omitted, omitted)
+	if fetch != "" && !containsString(r.fetch, fetch) {
+		r.fetch = append(r.fetch, fetch)
+	}
+}
+
+func (r *budgetReport) trimmed() bool {
+	return r != nil && len(r.omitted) > 0
+}
+
+// print reports the trimming after human output
+func (r *budgetReport) print() {
+	if !r.trimmed() {
+		return
+	}
+	pterm.Info.Printf("[Trimmed to ~%d tokens (--max-tokens): %s]\n", r.budget, strings.Join(r.omitted, "; "))
+	for _, f := range r.fetch {
+		fmt.Printf("  %s\n", f)
+	}
+}
+
+// warn reports the trimming of JSON output: as an envelope warning, or on
+// stderr without --envelope so stdout stays valid JSON. Returns the fetch
+// commands as next steps.
+func (r *budgetReport) warn() []string {
+	if !r.trimmed() {
+		return nil
+	}
+	msg := fmt.Sprintf("trimmed to ~%d tokens (--max-tokens): %s", r.budget, strings.Join(r.omitted, "; "))
+	if envelopeEnabled() {
+		jsonWarn("%s", msg)
+	} else {
+		if len(r.fetch) > 0 {
+			msg += " (fetch with: " + strings.Join(r.fetch, ", ") + ")"
+		}
+		fmt.Fprintln(os.Stderr, "Note: "+msg)
+	}
+	return r.fetch
+}
+
+// requestView is how a list of requests is printed
+type requestView struct {
+	requests []store.Request
+	mode     store.OutputMode
+	cfg      store.TruncateConfig
+	line     bool // One line per request; only the count can shrink
+	offset   int  // --offset of the first request, for the next page hint
+}
+
+// tokens approximates the cost of printing the view
+func (v requestView) tokens() int {
+	if v.line {
+		n := 0
+		for i := range v.requests {
+			n += estimateTokens(output.FormatRequestCompact(&v.requests[i])) + 1
+		}
+		return n
+	}
+	return jsonTokens(output.FormatRequests(v.requests, v.mode, v.cfg))
+}
+
+// fitRequests trims v to the token budget: shorter bodies, then no bodies,
+// then no headers, then fewer requests (at least one)
+func fitRequests(v requestView) (requestView, *budgetReport) {
+	budget := tokenBudget()
+	if budget == 0 || v.tokens() <= budget {
+		return v, nil
+	}
+	report := &budgetReport{budget: budget}
+	total := len(v.requests)
+
+	if !v.line && v.mode != store.OutputMeta {
+		fitted := false
+		for _, size := range budgetBodySizes {
+			if v.mode == store.OutputCompact && size >= v.cfg.MaxBodySize {
+				continue
+			}
+			v.mode, v.cfg.MaxBodySize = store.OutputCompact, size
+			if v.tokens() <= budget {
+				fitted = true
+				break
+			}
+		}
+		report.add(fmt.Sprintf("bodies cut to %d chars", v.cfg.MaxBodySize), "rep body <id>")
+		if fitted {
+			return v, report
+		}
+		v.mode = store.OutputMeta
+		report.omitted[len(report.omitted)-1] = "bodies omitted"
+	}
+	if !v.line && v.tokens() > budget {
+		v.requests = withoutHeaders(v.requests)
+		report.add("headers omitted", "rep raw <id>")
+	}
+
+	if n := fitCount(len(v.requests), func(n int) int {
+		part := v
+		part.requests = v.requests[:n]
+		return part.tokens()
+	}, budget); n < total {
+		v.requests = v.requests[:n]
+		report.add(fmt.Sprintf("showing %d of %d requests", n, total), fmt.Sprintf("repeat with --offset %d for the next page", v.offset+n))
+	}
+	return v, report
+}
+
+// fitCount returns the largest n <= total whose cost fits budget (at
+// least 1 when total > 0); cost must grow with n
+func fitCount(total int, cost func(n int) int, budget int) int {
+	if total == 0 || cost(total) <= budget {
+		return total
+	}
+	lo, hi := 1, total
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if cost(mid) <= budget {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo
+}
+
+// withoutHeaders returns copies of requests without request or response
+// headers
+func withoutHeaders(requests []store.Request) []store.Request {
+	out := make([]store.Request, len(requests))
+	for i, req := range requests {
+		req.Headers = nil
+		if req.Response != nil {
+			resp := *req.Response
+			resp.Headers = nil
+			req.Response = &resp
+		}
+		out[i] = req
+	}
+	return out
+}
+
+// fitBody cuts a body to the token budget, keeping the --truncate mode
+func fitBody(id, body, contentType string) (string, *budgetReport) {
+	budget := tokenBudget()
+	if budget == 0 || estimateTokens(body) <= budget {
+		return body, nil
+	}
+	cfg := truncateConfig()
+	cfg.MaxBodySize = budget * charsPerToken
+	cfg.ShowFullSize = true
+	cut, _ := output.TruncateBody(body, contentType, cfg)
+	report := &budgetReport{budget: budget}
+	report.add(fmt.Sprintf("body cut to ~%d of %d tokens", budget, estimateTokens(body)),
+		fmt.Sprintf("rep body %s --max-tokens 0", id))
+	return cut, report
+}
+
+// fitSummary halves the longest lists of a summary until it fits the token
+// budget
+func fitSummary(summary Summary) (Summary, *budgetReport) {
+	budget := tokenBudget()
+	if budget == 0 || jsonTokens(summary) <= budget {
+		return summary, nil
+	}
+	report := &budgetReport{budget: budget}
+	totals := map[string]int{
+		"top_domains":    len(summary.TopDomains),
+		"page_breakdown": len(summary.PageBreakdown),
+	}
+	if summary.Timing != nil {
+		timing := *summary.Timing
+		summary.Timing = &timing
+		totals["slowest"] = len(timing.Slowest)
+	}
+
+	over := false
+trim:
+	for jsonTokens(summary) > budget {
+		switch {
+		case len(summary.PageBreakdown) > 1 && len(summary.PageBreakdown) >= len(summary.TopDomains):
+			summary.PageBreakdown = summary.PageBreakdown[:len(summary.PageBreakdown)/2]
+		case len(summary.TopDomains) > 1:
+			summary.TopDomains = summary.TopDomains[:len(summary.TopDomains)/2]
+		case summary.Timing != nil && len(summary.Timing.Slowest) > 0:
+			summary.Timing.Slowest = nil
+		default:
+			// The totals and breakdowns are always kept
+			over = true
+			break trim
+		}
+	}
+
+	if n := len(summary.TopDomains); n < totals["top_domains"] {
+		report.add(fmt.Sprintf("top_domains cut to %d of %d", n, totals["top_domains"]), "rep domains")
+	}
+	if n := len(summary.PageBreakdown); n < totals["page_breakdown"] {
+		report.add(fmt.Sprintf("page_breakdown cut to %d of %d", n, totals["page_breakdown"]), "rep summary --max-tokens 0")
+	}
+	if summary.Timing != nil && len(summary.Timing.Slowest) < totals["slowest"] {
+		report.add("slowest requests omitted", "rep list --sort duration --desc --limit 5")
+	}
+	if over {
+		report.add("still over budget", "")
+	}
+	return summary, report
+}
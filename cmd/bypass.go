@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/replay"
+	"github.com/repplus/rep-cli/internal/similarity"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bypassSaved      string
+	bypassUseVars    bool
+	bypassInsecure   bool
+	bypassTechniques string
+	bypassDryRun     bool
+	bypassSimilarity float64
+)
+
+var bypassTechniqueNames = []string{"path", "header", "method"}
+
+// bypassIPHeaders claim the request comes from the server itself
+var bypassIPHeaders = []string{
+	"X-Forwarded-For", "X-Real-IP", "X-Client-IP", "X-Originating-IP",
+	"X-Remote-IP", "X-Remote-Addr", "X-Custom-IP-Authorization", "True-Client-IP",
+}
+
+// bypassMethods are tried in place of the captured method
+var bypassMethods = []string{"GET", "POST", "HEAD", "PUT", "PATCH", "OPTIONS", "TRACE"}
+
+// BypassVariant is one bypass attempt and, once sent, its outcome
+type BypassVariant struct {
+	Technique  string                 `json:"technique"` // path, header, method
+	Label      string                 `json:"label"`
+	URL        string                 `json:"url"`
+	Status     int                    `json:"status,omitempty"`
+	Size       int64                  `json:"size,omitempty"`
+	Diff       string                 `json:"diff,omitempty"` // same, similar, status, body
+	Similarity *similarity.Comparison `json:"similarity,omitempty"`
+	Bypassed   bool                   `json:"bypassed,omitempty"` // 2xx where the baseline was denied
+	Error      string                 `json:"error,omitempty"`
+	request    *store.Request
+}
+
+var bypassCmd = &cobra.Command{
+	Use:   "bypass <request-id>",
+	Short: "Probe a 401/403 request with classic access-control bypasses",
+	Long: `Replay a forbidden request with a battery of classic bypass techniques
+and highlight every variant whose status or response length differs from
+the baseline.
+
+Techniques (--techniques):
+  path     Case changes, trailing slash, /., /%2e/, //, ;/, ..;/, %20, %09,
+           .json and other path normalization tricks
+  header   X-Original-URL / X-Rewrite-URL rewrites and client IP headers
+           (X-Forwarded-For, X-Real-IP, True-Client-IP, ...) set to 127.0.0.1
+  method   The same request with GET, POST, HEAD, PUT, PATCH, OPTIONS, TRACE
+
+The original request is sent first as the baseline. A variant returning
+2xx while the baseline was 401/403 is marked as a likely bypass (★);
+other status changes and bodies scoring under --similarity-threshold
+(status, length, word overlap and JSON structure, 0 to 1) are listed for
+review. A body above the threshold is only "similar": an error page with
+the requested path echoed in it does not count as a change.
+
+Examples:
+  rep bypass h_abc123                      All techniques
+  rep bypass h_abc123 --techniques path    Path normalization only
+  rep bypass h_abc123 --dry-run            List the variants without sending
+  rep bypass h_abc123 --similarity-threshold 0.99
+  rep bypass h_abc123 --use-vars -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		requestID := args[0]
+
+		techniques := make(map[string]bool)
+		for _, t := range parseCommaSeparated(strings.ToLower(bypassTechniques)) {
+			if !containsString(bypassTechniqueNames, t) {
+				return fmt.Errorf("invalid technique: %s (use %s)", t, strings.Join(bypassTechniqueNames, ", "))
+			}
+			techniques[t] = true
+		}
+		if bypassSimilarity < 0 || bypassSimilarity > 1 {
+			return usageError("--similarity-threshold must be between 0 and 1")
+		}
+
+		req, err := lookupRequest(requestID, bypassSaved)
+		if err != nil {
+			return err
+		}
+		if req == nil {
+			return notFoundError("request not found: %s", requestID).withHint("Use 'rep list' to see available request IDs")
+		}
+
+		sendReq, missing := resolveSendRequest(req, bypassUseVars)
+		if err := warnMissingVars(req, missing); err != nil {
+			return err
+		}
+
+		variants := bypassVariants(sendReq, techniques)
+		if bypassDryRun {
+			pterm.DefaultSection.Printf("Bypass variants for %s (%d)\n", req.ID, len(variants))
+			for _, v := range variants {
+				fmt.Printf("  %-7s %s\n", v.Technique, output.SanitizeText(v.Label))
+			}
+			return nil
+		}
+
+		opts := replay.Options{Insecure: bypassInsecure}
+		baseline, err := replay.Send(context.Background(), sendReq, opts)
+		if err != nil {
+			return fmt.Errorf("baseline request failed: %w", err)
+		}
+		denied := baseline.Status == 401 || baseline.Status == 403
+		if !denied && getOutputMode() != "json" {
+			pterm.Warning.Printf("Baseline returned %d, not 401/403: differences may not be bypasses\n", baseline.Status)
+		}
+		for i := range variants {
+			runBypassVariant(&variants[i], baseline, denied, opts)
+		}
+
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"id":       req.ID,
+				"baseline": map[string]interface{}{"status": baseline.Status, "size": baseline.Size},
+				"variants": variants,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		printBypass(req, baseline, variants)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bypassCmd)
+	bypassCmd.Flags().StringVar(&bypassSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	bypassCmd.Flags().BoolVar(&bypassUseVars, "use-vars", false, "Resolve auth from environment / ~/.rep/auth-<domain>.env")
+	bypassCmd.Flags().BoolVarP(&bypassInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+	bypassCmd.Flags().StringVar(&bypassTechniques, "techniques", "", "Comma-separated techniques: path, header, method (default all)")
+	bypassCmd.Flags().BoolVar(&bypassDryRun, "dry-run", false, "List the variants without sending them")
+	bypassCmd.Flags().Float64Var(&bypassSimilarity, "similarity-threshold", similarity.DefaultThreshold, "Similarity score (0-1) under which a response body counts as different")
+}
+
+// bypassVariants builds the attempts for the selected techniques (all when
+// none are selected)
+func bypassVariants(req *store.Request, techniques map[string]bool) []BypassVariant {
+	want := func(t string) bool { return len(techniques) == 0 || techniques[t] }
+	parsed, err := url.Parse(req.URL)
+	if err != nil || parsed.Host == "" {
+		return nil
+	}
+	origin := parsed.Scheme + "://" + parsed.Host
+	query := ""
+	if parsed.RawQuery != "" {
+		query = "?" + parsed.RawQuery
+	}
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	var variants []BypassVariant
+
+	if want("path") {
+		trimmed := strings.TrimSuffix(path, "/")
+		dir, last := "", strings.TrimPrefix(trimmed, "/")
+		if i := strings.LastIndex(trimmed, "/"); i >= 0 {
+			dir, last = trimmed[:i], trimmed[i+1:]
+		}
+		var paths []string
+		if last != "" {
+			paths = append(paths,
+				dir+"/"+strings.ToUpper(last),
+				dir+"/"+strings.ToUpper(last[:1])+last[1:],
+				trimmed+"/",
+				trimmed+"/.",
+				dir+"/./"+last,
+				dir+"/%2e/"+last,
+				dir+"//"+last,
+				trimmed+"%2f",
+				trimmed+";/",
+				trimmed+"..;/",
+				dir+"/;/"+last,
+				trimmed+"%20",
+				trimmed+"%09",
+				trimmed+".json",
+				trimmed+"/*",
+			)
+		}
+		seen := map[string]bool{path: true}
+		for _, p := range paths {
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			v := *req
+			v.URL = origin + p + query
+			variants = append(variants, BypassVariant{Technique: "path", Label: p, URL: v.URL, request: &v})
+		}
+	}
+
+	if want("header") {
+		withHeader := func(label, rawURL, name, value string) {
+			v := *req
+			v.URL = rawURL
+			v.Headers = store.CloneHeaders(req.Headers)
+			if v.Headers == nil {
+				v.Headers = store.HeaderMap{}
+			}
+			store.SetHeader(v.Headers, name, value)
+			variants = append(variants, BypassVariant{Technique: "header", Label: label, URL: v.URL, request: &v})
+		}
+		for _, name := range []string{"X-Original-URL", "X-Rewrite-URL"} {
+			withHeader(name+": "+path+" (request to /)", origin+"/"+query, name, path)
+		}
+		for _, name := range bypassIPHeaders {
+			withHeader(name+": 127.0.0.1", req.URL, name, "127.0.0.1")
+		}
+	}
+
+	if want("method") {
+		for _, method := range bypassMethods {
+			if strings.EqualFold(method, req.Method) {
+				continue
+			}
+			v := *req
+			v.Method = method
+			if method == "GET" || method == "HEAD" || method == "OPTIONS" || method == "TRACE" {
+				v.Body = ""
+			}
+			variants = append(variants, BypassVariant{Technique: "method", Label: method + " " + path, URL: v.URL, request: &v})
+		}
+	}
+	return variants
+}
+
+// runBypassVariant sends one variant and compares it with the baseline
+func runBypassVariant(v *BypassVariant, baseline *replay.Result, denied bool, opts replay.Options) {
+	result, err := replay.Send(context.Background(), v.request, opts)
+	if err != nil {
+		v.Error = err.Error()
+		return
+	}
+	v.Status = result.Status
+	v.Size = result.Size
+	comparison := similarity.Compare(baseline.Status, baseline.Body, result.Status, result.Body)
+	v.Similarity = &comparison
+	switch {
+	case comparison.StatusChanged:
+		v.Diff = "status"
+	case comparison.Different(bypassSimilarity):
+		v.Diff = "body"
+	case result.Body != baseline.Body:
+		v.Diff = "similar"
+	default:
+		v.Diff = "same"
+	}
+	v.Bypassed = denied && result.Status >= 200 && result.Status < 300
+}
+
+func printBypass(req *store.Request, baseline *replay.Result, variants []BypassVariant) {
+	pterm.DefaultSection.Printf("Bypass probe for %s\n", req.ID)
+	fmt.Printf("  %s %s\n\n", req.Method, output.SanitizeText(req.URL))
+	fmt.Printf("  %-7s %-50s %6s %9s  %s\n", "TECH", "VARIANT", "STATUS", "SIZE", "DIFF")
+	fmt.Printf("  %-7s %-50s %6d %9s\n", "base", "(original)", baseline.Status, output.FormatBodySize(int(baseline.Size)))
+	bypassed, changed := 0, 0
+	for _, v := range variants {
+		label := truncateCell(output.SanitizeText(v.Label), 50)
+		if v.Error != "" {
+			fmt.Printf("  %-7s %-50s %6s  %s\n", v.Technique, label, "ERR", v.Error)
+			continue
+		}
+		line := fmt.Sprintf("  %-7s %-50s %6d %9s  %s", v.Technique, label, v.Status, output.FormatBodySize(int(v.Size)), v.Diff)
+		switch {
+		case v.Bypassed:
+			bypassed++
+			line = pterm.Red(line + "  ★")
+		case v.Diff == "status" || v.Diff == "body":
+			changed++
+			line = pterm.Yellow(line)
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+	switch {
+	case bypassed > 0:
+		pterm.Warning.Printf("%d variant(s) got a 2xx where the baseline was denied\n", bypassed)
+	case changed > 0:
+		pterm.Info.Printf("%d variant(s) differ from the baseline; no 2xx bypass\n", changed)
+	default:
+		pterm.Info.Println("Every variant matched the baseline")
+	}
+}
@@ -0,0 +1,356 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/repplus/rep-cli/internal/waf"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheSaved  string
+	cacheDomain string
+	cacheIssues bool
+)
+
+// cacheStatusHeaders report whether a CDN or reverse proxy served a response
+// from its cache
+var cacheStatusHeaders = []string{
+	"X-Cache", "CF-Cache-Status", "X-Cache-Status", "X-Proxy-Cache", "X-Drupal-Cache",
+	"X-Varnish-Cache", "Cdn-Cache", "X-Cdn-Cache", "Akamai-Cache-Status", "X-Litespeed-Cache",
+}
+
+// cacheProbeHeaders are the usual unkeyed inputs worth trying on a cached
+// endpoint
+var cacheProbeHeaders = []string{"X-Forwarded-Host", "X-Forwarded-Scheme", "X-Forwarded-Port", "X-Original-URL", "X-Host"}
+
+// cacheIgnoredHeaders never count as unkeyed inputs: caches key on them,
+// or they are sent to every endpoint alike
+var cacheIgnoredHeaders = []string{"host", ":authority", ":path", ":method", ":scheme", "content-length", "accept-encoding", "connection"}
+
+// cacheMaxAge reads max-age and s-maxage directives
+var cacheMaxAge = regexp.MustCompile(`(?i)\b(s-maxage|max-age)\s*=\s*"?(\d+)`)
+
+// cacheIDs caps the request IDs kept per endpoint
+const cacheIDs = 5
+
+// CacheEndpoint is the caching behavior seen on one endpoint
+type CacheEndpoint struct {
+	Endpoint      string       `json:"endpoint"` // "METHOD host/path/{id}"
+	Requests      int          `json:"requests"`
+	CacheControl  []string     `json:"cache_control"` // Distinct Cache-Control values
+	MaxAge        int          `json:"max_age"`       // Largest s-maxage or max-age, seconds
+	Age           int          `json:"age"`           // Largest Age header
+	Vary          []string     `json:"vary"`
+	CacheStatus   []string     `json:"cache_status"` // X-Cache, CF-Cache-Status, ... values
+	CDN           []string     `json:"cdn"`
+	Shared        bool         `json:"shared"` // A shared cache may store it, or served it
+	Authenticated bool         `json:"authenticated"`
+	Issues        []CacheIssue `json:"issues"`
+	IDs           []string     `json:"ids"`
+}
+
+// CacheIssue is a caching behavior worth a cache deception or poisoning test
+type CacheIssue struct {
+	Kind   string `json:"kind"` // authenticated-cacheable, unkeyed-header
+	Header string `json:"header,omitempty"`
+	Detail string `json:"detail"`
+	ID     string `json:"id"` // Request showing it
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache [-d domain]",
+	Short: "Analyze caching headers per endpoint for cache poisoning and deception",
+	Long: `Read the caching headers of every response (Cache-Control, Age, Vary,
+X-Cache, CF-Cache-Status and other CDN cache statuses) and summarize them
+per endpoint (method, host and path template).
+
+An endpoint is shared-cacheable when a response was public, had a max-age
+or s-maxage without private or no-store, carried an Age, or was a cache
+HIT. Two kinds of issues are flagged:
+
+  authenticated-cacheable  A 2xx response to a request with credentials
+                           that a shared cache may store, and whose Vary
+                           doesn't cover the Cookie or Authorization sent:
+                           a web cache deception lead
+  unkeyed-header           A request header whose value the cached response
+                           reflects (body, Location, Access-Control-Allow-
+                           Origin) but Vary doesn't list: a web cache
+                           poisoning lead
+
+Follow up on shared-cacheable endpoints with the headers caches rarely key
+on (X-Forwarded-Host, X-Forwarded-Scheme, X-Original-URL) through
+'rep replay --suite host-header' or 'rep replay -H'.
+
+Examples:
+  rep cache
+  rep cache -d target.com --issues
+  rep cache --saved latest -o json | jq '.[] | select(.issues | length > 0)'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scope, err := baseDomainScope(cacheDomain)
+		if err != nil {
+			return err
+		}
+		requests, err := filterSource(cacheSaved, store.FilterOptions{})
+		if err != nil || requests == nil {
+			return err
+		}
+		endpoints := buildCacheReport(requests, scope)
+		if cacheIssues {
+			kept := endpoints[:0]
+			for _, e := range endpoints {
+				if len(e.Issues) > 0 {
+					kept = append(kept, e)
+				}
+			}
+			endpoints = kept
+		}
+
+		if getOutputMode() == "json" {
+			printJSON("cache", endpoints)
+			return nil
+		}
+		if len(endpoints) == 0 {
+			if cacheIssues {
+				pterm.Info.Println("No caching issues found")
+				return nil
+			}
+			return softFail(emptyError("No responses to analyze"))
+		}
+		printCache(endpoints)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.Flags().StringVar(&cacheSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	cacheCmd.Flags().StringVarP(&cacheDomain, "domain", "d", "", "Only requests on these registrable domains (comma-separated or @group)")
+	cacheCmd.Flags().BoolVar(&cacheIssues, "issues", false, "Only endpoints with an issue")
+}
+
+// buildCacheReport summarizes caching per endpoint: those with issues
+// first, then the shared-cacheable ones
+func buildCacheReport(requests []store.Request, scope domainScope) []CacheEndpoint {
+	index := make(map[string]*CacheEndpoint)
+	var order []string
+	for i := range requests {
+		req := &requests[i]
+		if req.Response == nil {
+			continue
+		}
+		if !scope.covers(hostFromURL(req.URL)) {
+			continue
+		}
+		if req.Domain == "" {
+			store.ComputeRequestFields(req)
+		}
+		key := uniqueKey(req, true)
+		e := index[key]
+		if e == nil {
+			e = &CacheEndpoint{Endpoint: key, CacheControl: []string{}, Vary: []string{}, CacheStatus: []string{}, CDN: []string{}, Issues: []CacheIssue{}}
+			index[key] = e
+			order = append(order, key)
+		}
+		e.Requests++
+		if len(e.IDs) < cacheIDs {
+			e.IDs = append(e.IDs, req.ID)
+		}
+		analyzeCacheResponse(e, req)
+	}
+
+	endpoints := make([]CacheEndpoint, 0, len(order))
+	for _, key := range order {
+		endpoints = append(endpoints, *index[key])
+	}
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		if (len(endpoints[i].Issues) > 0) != (len(endpoints[j].Issues) > 0) {
+			return len(endpoints[i].Issues) > 0
+		}
+		return endpoints[i].Shared && !endpoints[j].Shared
+	})
+	return endpoints
+}
+
+// analyzeCacheResponse adds one response's caching headers to its endpoint
+func analyzeCacheResponse(e *CacheEndpoint, req *store.Request) {
+	headers := req.Response.Headers
+	cc := strings.TrimSpace(store.HeaderFirst(headers, "cache-control"))
+	if cc != "" && !containsString(e.CacheControl, cc) {
+		e.CacheControl = append(e.CacheControl, cc)
+	}
+	directives := strings.ToLower(cc)
+	maxAge := 0
+	for _, m := range cacheMaxAge.FindAllStringSubmatch(cc, -1) {
+		if n, err := strconv.Atoi(m[2]); err == nil {
+			maxAge = max(maxAge, n)
+		}
+	}
+	e.MaxAge = max(e.MaxAge, maxAge)
+	age, _ := strconv.Atoi(strings.TrimSpace(store.HeaderFirst(headers, "age")))
+	e.Age = max(e.Age, age)
+
+	var vary []string
+	for _, v := range store.HeaderValues(headers, "vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				vary = append(vary, name)
+				if !containsString(e.Vary, name) {
+					e.Vary = append(e.Vary, name)
+				}
+			}
+		}
+	}
+
+	hit := false
+	for _, name := range cacheStatusHeaders {
+		value := strings.TrimSpace(store.HeaderFirst(headers, name))
+		if value == "" {
+			continue
+		}
+		label := name + ": " + value
+		if !containsString(e.CacheStatus, label) {
+			e.CacheStatus = append(e.CacheStatus, label)
+		}
+		if strings.Contains(strings.ToUpper(value), "HIT") {
+			hit = true
+		}
+	}
+	for _, m := range waf.Detect(req) {
+		if !containsString(e.CDN, m.Name) {
+			e.CDN = append(e.CDN, m.Name)
+		}
+	}
+
+	private := strings.Contains(directives, "private") || strings.Contains(directives, "no-store")
+	shared := hit || age > 0 || (!private && (strings.Contains(directives, "public") || maxAge > 0))
+	if !shared {
+		return
+	}
+	e.Shared = true
+	if containsString(vary, "*") {
+		return
+	}
+
+	status := req.Response.Status
+	schemes := requestAuthSchemes(req)
+	if schemes[0] != "none" {
+		e.Authenticated = true
+		keyed := (containsString(schemes, "cookie") && containsString(vary, "cookie")) ||
+			(!containsString(schemes, "cookie") && containsString(vary, "authorization"))
+		if status >= 200 && status < 300 && !keyed {
+			detail := fmt.Sprintf("%d response to a request with %s credentials", status, strings.Join(schemes, "+"))
+			if cc != "" {
+				detail += "; Cache-Control: " + cc
+			}
+			if hit {
+				detail += "; served from cache"
+			}
+			addCacheIssue(e, CacheIssue{Kind: "authenticated-cacheable", Detail: detail, ID: req.ID})
+		}
+	}
+
+	body := store.ResponseBodyText(req)
+	reflectedIn := func(value string) string {
+		for _, name := range []string{"Location", "Access-Control-Allow-Origin", "Link", "Content-Location"} {
+			if strings.Contains(store.HeaderFirst(headers, name), value) {
+				return name
+			}
+		}
+		if strings.Contains(body, value) {
+			return "body"
+		}
+		return ""
+	}
+	for name, values := range req.Headers {
+		lower := strings.ToLower(name)
+		if containsString(cacheIgnoredHeaders, lower) || containsString(vary, lower) || lower == "cookie" || lower == "authorization" {
+			continue
+		}
+		for _, value := range values {
+			if len(value) < 6 {
+				continue
+			}
+			if where := reflectedIn(value); where != "" {
+				addCacheIssue(e, CacheIssue{Kind: "unkeyed-header", Header: name,
+					Detail: fmt.Sprintf("%s value reflected in %s, not in Vary", name, where), ID: req.ID})
+				break
+			}
+		}
+	}
+}
+
+// addCacheIssue records an issue once per kind and header
+func addCacheIssue(e *CacheEndpoint, issue CacheIssue) {
+	for _, existing := range e.Issues {
+		if existing.Kind == issue.Kind && strings.EqualFold(existing.Header, issue.Header) {
+			return
+		}
+	}
+	e.Issues = append(e.Issues, issue)
+}
+
+func printCache(endpoints []CacheEndpoint) {
+	shared, issues := 0, 0
+	for _, e := range endpoints {
+		if e.Shared {
+			shared++
+		}
+		issues += len(e.Issues)
+	}
+	pterm.DefaultSection.Printf("Caching (%d endpoints, %d shared-cacheable, %d issues)\n", len(endpoints), shared, issues)
+	tableData := pterm.TableData{{"Endpoint", "Cache-Control", "Age", "Vary", "Cache status", "Auth"}}
+	for _, e := range endpoints {
+		endpoint := truncateCell(output.SanitizeText(e.Endpoint), 55)
+		if e.Shared {
+			endpoint = pterm.FgYellow.Sprint(endpoint)
+		}
+		age := "-"
+		if e.Age > 0 {
+			age = strconv.Itoa(e.Age)
+		}
+		auth := "-"
+		if e.Authenticated {
+			auth = "yes"
+		}
+		tableData = append(tableData, []string{
+			endpoint,
+			truncateCell(output.SanitizeText(strings.Join(e.CacheControl, " | ")), 35),
+			age,
+			truncateCell(output.SanitizeText(strings.Join(e.Vary, ",")), 25),
+			truncateCell(output.SanitizeText(strings.Join(append(e.CacheStatus, e.CDN...), ", ")), 30),
+			auth,
+		})
+	}
+	pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+
+	if issues == 0 {
+		if shared > 0 {
+			fmt.Println()
+			pterm.Info.Printf("No issues; probe shared-cacheable endpoints with unkeyed headers: %s\n", strings.Join(cacheProbeHeaders, ", "))
+		}
+		return
+	}
+	fmt.Println()
+	pterm.DefaultSection.Printf("Issues (%d)\n", issues)
+	for _, e := range endpoints {
+		for _, issue := range e.Issues {
+			kind := pterm.FgYellow.Sprintf("%-25s", issue.Kind)
+			if issue.Kind == "authenticated-cacheable" {
+				kind = pterm.FgRed.Sprintf("%-25s", issue.Kind)
+			}
+			fmt.Printf("  %s %s  %s\n", kind, output.SanitizeText(e.Endpoint), issue.ID)
+			fmt.Printf("  %-25s %s\n", "", pterm.FgGray.Sprint(output.SanitizeText(issue.Detail)))
+		}
+	}
+	fmt.Println()
+	pterm.Info.Println("Confirm with a second request without credentials (deception) or with a canary header value (poisoning) and a cache buster")
+}
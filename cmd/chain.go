@@ -12,7 +12,10 @@ import (
 )
 
 var (
-	chainSaved string
+	chainSaved    string
+	chainDot      bool
+	chainMermaid  bool
+	chainRedirect bool
 )
 
 var chainCmd = &cobra.Command{
@@ -29,12 +32,36 @@ Use --saved to analyze chains from archived sessions.
 Without arguments, shows all unique chains grouped by page.
 With a request ID, shows the chain for that specific request.
 
+Redirects (--redirects <id>):
+  Reconstructs the redirect chain through a request: each 3xx Location is
+  resolved and matched to the next captured request for that URL, both
+  before and after the given one. Every hop shows its status, token-like
+  URL parameters (code, state, access_token, ...), cookies set by the
+  response, cookies added/changed/removed compared with the previous hop,
+  and Authorization header changes.
+
+Graph output (--dot, --mermaid):
+  Prints the initiator/page graph as Graphviz or Mermaid source instead:
+  pages link to the requests they made, and a request initiated by another
+  captured request (a script fetching an API) links from it. Error
+  responses are outlined in red. With a request ID, only its chain.
+
 Examples:
   rep chain                     Show all request chains from live session
   rep chain h_abc123            Show chain for specific request
   rep chain --saved latest      Show chains from most recent saved session
-  rep chain -o json             JSON output for agents`,
+  rep chain -o json             JSON output for agents
+  rep chain --redirects h_abc123  Hops of the login/OAuth redirect chain
+  rep chain h_abc123 --mermaid  One request's chain as Mermaid
+  rep chain --mermaid --saved latest        Flow graph for docs and reports
+  rep chain --dot | dot -Tsvg > flow.svg    Render with Graphviz`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if chainDot && chainMermaid {
+			return fmt.Errorf("--dot and --mermaid can't be combined")
+		}
+		if chainRedirect && len(args) == 0 {
+			return fmt.Errorf("--redirects needs a request ID")
+		}
 		var tempStore *store.Store
 		var persistentStore *store.Store
 
@@ -55,27 +82,18 @@ Examples:
 			}
 
 			if session == nil {
-				pterm.Warning.Printf("Session not found: %s\n", chainSaved)
-				pterm.Info.Println("Use 'rep sessions' to list available sessions")
-				return nil
+				return notFoundError("session not found: %s", chainSaved).withHint("Use 'rep sessions' to list available sessions")
 			}
 
 			tempStore = store.NewTempStore(session.Requests)
 		} else {
 			// Default: Load from live.json
-			livePath, err := store.GetLiveFilePath()
+			export, err := loadLiveMerged()
 			if err != nil {
-				return fmt.Errorf("failed to get live path: %w", err)
-			}
-			export, err := loadLiveExport(livePath)
-			if err != nil {
-				pterm.Warning.Printf("Could not read live.json: %v\n", err)
-				pterm.Info.Println("Enable auto-export in rep+ extension first")
-				return nil
+				return softFail(liveReadError(err))
 			}
 			if len(export.Requests) == 0 {
-				pterm.Info.Println("No requests captured yet (live session empty)")
-				return nil
+				return softFail(emptyLiveError())
 			}
 
 			tempStore = store.NewTempStore(export.Requests)
@@ -86,10 +104,12 @@ Examples:
 		tempStore.IgnoredDomains = persistentStore.IgnoredDomains
 
 		if tempStore.Count() == 0 {
-			pterm.Info.Println("No requests found")
-			return nil
+			return softFail(emptyError("No requests found"))
 		}
 
+		if chainRedirect {
+			return showRedirectChain(tempStore, args[0])
+		}
 		if len(args) > 0 {
 			return showRequestChain(tempStore, args[0])
 		}
@@ -117,12 +137,16 @@ type RequestChain struct {
 func showRequestChain(s *store.Store, requestID string) error {
 	req := s.GetRequest(requestID)
 	if req == nil {
-		return fmt.Errorf("request not found: %s", requestID)
+		return notFoundError("request not found: %s", requestID)
 	}
 
 	// Build chain by following initiator
 	chain := buildChainForRequest(s, req)
 
+	if printChainGraph(chainGraph(chain)) {
+		return nil
+	}
+
 	if getOutputMode() == "json" {
 		out, _ := sonic.MarshalIndent(chain, "", "  ")
 		fmt.Println(string(out))
@@ -154,6 +178,9 @@ func showAllChains(s *store.Store) error {
 	// Group requests by PageURL
 	pageGroups := make(map[string][]store.Request)
 	requests := s.Filter(store.FilterOptions{ExcludeIgnored: true})
+	if printChainGraph(buildInitiatorGraph(requests)) {
+		return nil
+	}
 
 	for _, req := range requests {
 		pageURL := req.PageURL
@@ -251,6 +278,19 @@ func showAllChains(s *store.Store) error {
 	return nil
 }
 
+// printChainGraph prints the graph when --dot or --mermaid is set
+func printChainGraph(g *initiatorGraph) bool {
+	switch {
+	case chainDot:
+		fmt.Print(renderDot(g))
+	case chainMermaid:
+		fmt.Print(renderMermaid(g))
+	default:
+		return false
+	}
+	return true
+}
+
 func buildChainForRequest(s *store.Store, req *store.Request) RequestChain {
 	chain := RequestChain{
 		PageURL: req.PageURL,
@@ -342,4 +382,7 @@ func getDomainFromURL(u string) string {
 func init() {
 	rootCmd.AddCommand(chainCmd)
 	chainCmd.Flags().StringVar(&chainSaved, "saved", "", "Read from saved session (ID, prefix, or 'latest')")
+	chainCmd.Flags().BoolVar(&chainDot, "dot", false, "Print the initiator graph as Graphviz DOT")
+	chainCmd.Flags().BoolVar(&chainRedirect, "redirects", false, "Show the redirect chain through the request")
+	chainCmd.Flags().BoolVar(&chainMermaid, "mermaid", false, "Print the initiator graph as a Mermaid flowchart")
 }
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// graphNode is a page, a captured request, or an initiator URL that was
+// not captured
+type graphNode struct {
+	ID     string
+	Label  string
+	Kind   string // page, request, external
+	Status int
+}
+
+type graphEdge struct {
+	From, To string
+}
+
+// initiatorGraph is the page / initiator relationship graph of a capture
+type initiatorGraph struct {
+	Nodes []graphNode
+	Edges []graphEdge
+	index map[string]string // page URL, request ID or external URL -> node ID
+}
+
+func newInitiatorGraph() *initiatorGraph {
+	return &initiatorGraph{index: make(map[string]string)}
+}
+
+// node returns the node ID for key, adding the node on first use
+func (g *initiatorGraph) node(key, kind, label string, status int) string {
+	if id, ok := g.index[kind+"\x00"+key]; ok {
+		return id
+	}
+	id := fmt.Sprintf("n%d", len(g.Nodes)+1)
+	g.index[kind+"\x00"+key] = id
+	g.Nodes = append(g.Nodes, graphNode{ID: id, Label: label, Kind: kind, Status: status})
+	return id
+}
+
+func (g *initiatorGraph) requestNode(req *store.Request) string {
+	return g.node(req.ID, "request", chainNodeLabel(req.Method, req.URL, responseStatus(req)), responseStatus(req))
+}
+
+// buildInitiatorGraph links every request to the captured request its
+// initiator URL points at (a script that fetched it), else to its page,
+// else to the initiator URL itself
+func buildInitiatorGraph(requests []store.Request) *initiatorGraph {
+	sorted := make([]store.Request, len(requests))
+	copy(sorted, requests)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp < sorted[j].Timestamp })
+
+	byURL := make(map[string]*store.Request)
+	for i := range sorted {
+		if _, ok := byURL[sorted[i].URL]; !ok {
+			byURL[sorted[i].URL] = &sorted[i]
+		}
+	}
+
+	g := newInitiatorGraph()
+	for i := range sorted {
+		req := &sorted[i]
+		to := g.requestNode(req)
+		from := ""
+		if req.Initiator != "" && req.Initiator != req.URL && req.Initiator != req.PageURL {
+			if parent := byURL[req.Initiator]; parent != nil && parent.ID != req.ID {
+				from = g.requestNode(parent)
+			}
+		}
+		if from == "" && req.PageURL != "" {
+			from = g.node(req.PageURL, "page", req.PageURL, 0)
+		}
+		if from == "" && req.Initiator != "" && req.Initiator != req.URL {
+			from = g.node(req.Initiator, "external", req.Initiator, 0)
+		}
+		if from != "" {
+			g.Edges = append(g.Edges, graphEdge{From: from, To: to})
+		}
+	}
+	return g
+}
+
+// chainGraph turns one request's chain (root first) into a path graph
+func chainGraph(chain RequestChain) *initiatorGraph {
+	g := newInitiatorGraph()
+	prev := ""
+	if chain.PageURL != "" {
+		prev = g.node(chain.PageURL, "page", chain.PageURL, 0)
+	}
+	for _, link := range chain.Links {
+		var id string
+		if link.ID == "" {
+			if link.URL == chain.PageURL {
+				continue
+			}
+			id = g.node(link.URL, "external", link.URL, 0)
+		} else {
+			id = g.node(link.ID, "request", chainNodeLabel(link.Method, link.URL, link.Status), link.Status)
+		}
+		if prev != "" && prev != id {
+			g.Edges = append(g.Edges, graphEdge{From: prev, To: id})
+		}
+		prev = id
+	}
+	return g
+}
+
+// chainNodeLabel is "METHOD host/path [status]"
+func chainNodeLabel(method, rawURL string, status int) string {
+	target := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.Host != "" {
+		target = parsed.Host + parsed.EscapedPath()
+	}
+	label := method + " " + truncateCell(target, 60)
+	if status > 0 {
+		label += fmt.Sprintf(" [%d]", status)
+	}
+	return label
+}
+
+func responseStatus(req *store.Request) int {
+	if req.Response == nil {
+		return 0
+	}
+	return req.Response.Status
+}
+
+// renderDot writes the graph as Graphviz source: pages as boxes, captured
+// requests as ellipses (red outline for 4xx/5xx), uncaptured initiators
+// dashed
+func renderDot(g *initiatorGraph) string {
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(s) + `"`
+	}
+	var b strings.Builder
+	b.WriteString("digraph rep {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\", fontsize=10];\n")
+	for _, n := range g.Nodes {
+		attrs := []string{"label=" + quote(n.Label)}
+		switch n.Kind {
+		case "page":
+			attrs = append(attrs, "shape=box", "style=filled", "fillcolor=\"#dde8f6\"")
+		case "external":
+			attrs = append(attrs, "shape=box", "style=dashed")
+		default:
+			if n.Status >= 400 {
+				attrs = append(attrs, "color=red")
+			}
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", n.ID, strings.Join(attrs, ", "))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", e.From, e.To)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// renderMermaid writes the graph as a Mermaid flowchart
+func renderMermaid(g *initiatorGraph) string {
+	escape := strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	errors := []string{}
+	for _, n := range g.Nodes {
+		label := escape(n.Label)
+		switch n.Kind {
+		case "page":
+			fmt.Fprintf(&b, "  %s([\"%s\"])\n", n.ID, label)
+		case "external":
+			fmt.Fprintf(&b, "  %s[/\"%s\"/]\n", n.ID, label)
+		default:
+			fmt.Fprintf(&b, "  %s[\"%s\"]\n", n.ID, label)
+			if n.Status >= 400 {
+				errors = append(errors, n.ID)
+			}
+		}
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s --> %s\n", e.From, e.To)
+	}
+	if len(errors) > 0 {
+		b.WriteString("  classDef error stroke:#d33,stroke-width:2px\n")
+		fmt.Fprintf(&b, "  class %s error\n", strings.Join(errors, ","))
+	}
+	return b.String()
+}
@@ -18,11 +18,12 @@ var clearCmd = &cobra.Command{
 	Long: `Clear all captured data and reset the store.
 
 This clears:
-  - Live session (live.json)
+  - Live session (live.json and each browser's live-<id>.json)
   - All saved sessions in store.json
   - Ignore list (domains)
   - Muted paths list
   - Primary domains list
+  - Domain groups
 
 Examples:
   rep clear                Clear everything
@@ -38,11 +39,11 @@ Examples:
 		ignoredCount := len(s.GetIgnoredDomains())
 		mutedCount := len(s.GetMutedPaths())
 		primaryCount := len(s.GetPrimaryDomains())
+		groupCount := len(s.GetDomainGroupNames())
 
 		// Get live request count before clearing
 		liveCount := 0
-		livePath, _ := store.GetLiveFilePath()
-		if export, err := loadLiveExport(livePath); err == nil {
+		if export, err := loadLiveMerged(); err == nil {
 			liveCount = len(export.Requests)
 		}
 
@@ -54,11 +55,15 @@ Examples:
 			return fmt.Errorf("failed to save: %w", err)
 		}
 
-		// Clear live.json
-		clearedLivePath, err := clearLiveExportFile()
+		// Clear live.json (every live session in scope)
+		clearedLivePaths, err := clearLiveExportFiles()
 		if err != nil {
 			pterm.Warning.Printf("Could not clear live.json: %v\n", err)
 		}
+		clearedLivePath := ""
+		if len(clearedLivePaths) > 0 {
+			clearedLivePath = clearedLivePaths[0]
+		}
 
 		if getOutputMode() == "json" {
 			result := map[string]interface{}{
@@ -67,8 +72,12 @@ Examples:
 				"cleared_ignored":       ignoredCount,
 				"cleared_muted":         mutedCount,
 				"cleared_primary":       primaryCount,
+				"cleared_groups":        groupCount,
 				"live_path":             clearedLivePath,
 			}
+			if len(clearedLivePaths) > 1 {
+				result["live_paths"] = clearedLivePaths
+			}
 			out, _ := sonic.MarshalIndent(result, "", "  ")
 			fmt.Println(string(out))
 		} else {
@@ -88,6 +97,9 @@ Examples:
 			if primaryCount > 0 {
 				pterm.Info.Printf("Primary domains: %d\n", primaryCount)
 			}
+			if groupCount > 0 {
+				pterm.Info.Printf("Domain groups: %d\n", groupCount)
+			}
 		}
 
 		return nil
@@ -98,30 +110,33 @@ func init() {
 	rootCmd.AddCommand(clearCmd)
 }
 
-func clearLiveExportFile() (string, error) {
-	livePath, err := store.GetLiveFilePath()
+// clearLiveExportFiles empties every live file in scope and returns their paths
+func clearLiveExportFiles() ([]string, error) {
+	livePaths, err := store.GetLiveFilePaths()
 	if err != nil {
-		return "", err
-	}
-
-	if err := os.MkdirAll(filepath.Dir(livePath), 0755); err != nil {
-		return "", err
-	}
-
-	export := store.Export{
-		Version:    "1.0",
-		ExportedAt: time.Now().Format(time.RFC3339),
-		Requests:   []store.Request{},
+		return nil, err
 	}
-
-	data, err := sonic.MarshalIndent(export, "", "  ")
-	if err != nil {
-		return "", err
+	var cleared []string
+	for _, livePath := range livePaths {
+		if err := clearLiveExportFile(livePath); err != nil {
+			return cleared, err
+		}
+		cleared = append(cleared, livePath)
 	}
+	return cleared, nil
+}
 
-	if err := os.WriteFile(livePath, data, 0644); err != nil {
-		return "", err
+// clearLiveExportFile empties livePath under its lock, so a request being
+// appended or deleted at the same time doesn't bring old requests back
+func clearLiveExportFile(livePath string) error {
+	if err := os.MkdirAll(filepath.Dir(livePath), 0755); err != nil {
+		return err
 	}
-
-	return livePath, nil
+	return store.UpdateFile(livePath, 0644, func([]byte) ([]byte, error) {
+		return sonic.MarshalIndent(store.Export{
+			Version:    "1.0",
+			ExportedAt: time.Now().Format(time.RFC3339),
+			Requests:   []store.Request{},
+		}, "", "  ")
+	})
 }
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/similarity"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	clusterSaved    string
+	clusterDomain   string
+	clusterDistance int
+	clusterMin      int
+)
+
+// clusterIDs caps the request IDs listed per cluster
+const clusterIDs = 10
+
+// EndpointClusters is the responses of one endpoint grouped by likeness
+type EndpointClusters struct {
+	Endpoint  string            `json:"endpoint"` // "METHOD host/path/{id}"
+	Responses int               `json:"responses"`
+	Clusters  []ResponseCluster `json:"clusters"` // Largest first: the first is the usual behavior
+}
+
+// ResponseCluster is a set of responses with the same status and
+// near-identical bodies
+type ResponseCluster struct {
+	Status    int      `json:"status"`
+	Size      int      `json:"size"`
+	Identical bool     `json:"identical"` // Every body has the same SHA-256
+	Hash      string   `json:"hash"`      // SHA-256 prefix of the first body
+	Simhash   string   `json:"simhash"`
+	MinLength int      `json:"min_length"`
+	MaxLength int      `json:"max_length"`
+	Sample    string   `json:"sample"` // Path and query of the first request
+	IDs       []string `json:"ids"`    // Up to 10
+
+	simhash uint64
+	hashes  map[string]bool
+}
+
+var clusterCmd = &cobra.Command{
+	Use:   "cluster [endpoint|request-id]",
+	Short: "Group near-identical responses per endpoint",
+	Long: `Hash every response body (exact SHA-256 and a 64-bit simhash of its
+words) and group each endpoint's responses into clusters: same status, and
+identical bodies or bodies whose simhashes differ by at most --distance
+bits, so a changed timestamp or nonce doesn't split a cluster.
+
+The largest cluster of an endpoint is its usual behavior; smaller ones are
+the parameter variations that actually changed what the server did. After
+fuzzing an endpoint with 500 values, the clusters are the handful worth
+reading.
+
+The endpoint is a request ID (its method, host and path template), or
+[METHOD] [host]/path, as in 'rep schema-infer'. Without one, every endpoint
+with at least --min responses is reported, those with the most clusters
+first.
+
+Examples:
+  rep cluster
+  rep cluster demo_0013                       Every response of its endpoint
+  rep cluster 'GET /v1/search' --distance 0   Exact matches only
+  rep cluster -d api.target.com -o json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var matcher *endpointMatcher
+		if len(args) == 1 {
+			m, err := endpointArg(strings.TrimSpace(args[0]), clusterSaved)
+			if err != nil {
+				return err
+			}
+			matcher = &m
+		}
+		if clusterDistance < 0 || clusterDistance > 64 {
+			return usageError("--distance must be between 0 and 64")
+		}
+
+		scope, err := baseDomainScope(clusterDomain)
+		if err != nil {
+			return err
+		}
+		requests, err := filterSource(clusterSaved, store.FilterOptions{})
+		if err != nil || requests == nil {
+			return err
+		}
+		kept := requests[:0]
+		for _, req := range requests {
+			if req.Response == nil || (matcher != nil && !matcher.matches(&req)) {
+				continue
+			}
+			if !scope.covers(hostFromURL(req.URL)) {
+				continue
+			}
+			kept = append(kept, req)
+		}
+		minResponses := clusterMin
+		if matcher != nil {
+			minResponses = 1
+		}
+		endpoints := clusterResponses(kept, clusterDistance, minResponses)
+
+		if getOutputMode() == "json" {
+			printJSON("cluster", endpoints)
+			return nil
+		}
+		if len(endpoints) == 0 {
+			return softFail(emptyError("No endpoint has %d or more responses", minResponses))
+		}
+		printClusters(endpoints)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(clusterCmd)
+	clusterCmd.Flags().StringVar(&clusterSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	clusterCmd.Flags().StringVarP(&clusterDomain, "domain", "d", "", "Only requests on these registrable domains (comma-separated or @group)")
+	clusterCmd.Flags().IntVar(&clusterDistance, "distance", 3, "Most simhash bits two bodies of one cluster differ in (0 = exact)")
+	clusterCmd.Flags().IntVar(&clusterMin, "min", 2, "Only endpoints with at least this many responses")
+}
+
+// clusterResponses groups each endpoint's responses, endpoints with the
+// most clusters first
+func clusterResponses(requests []store.Request, distance, minResponses int) []EndpointClusters {
+	byKey := make(map[string]*EndpointClusters)
+	var order []string
+	for i := range requests {
+		req := &requests[i]
+		if req.Domain == "" {
+			store.ComputeRequestFields(req)
+		}
+		key := uniqueKey(req, true)
+		e := byKey[key]
+		if e == nil {
+			e = &EndpointClusters{Endpoint: key}
+			byKey[key] = e
+			order = append(order, key)
+		}
+		e.Responses++
+
+		body := store.ResponseBodyText(req)
+		sum := sha256.Sum256([]byte(body))
+		hash := hex.EncodeToString(sum[:8])
+		fingerprint := similarity.Simhash(body)
+
+		var c *ResponseCluster
+		for k := range e.Clusters {
+			candidate := &e.Clusters[k]
+			if candidate.Status != req.Response.Status {
+				continue
+			}
+			if candidate.hashes[hash] || (distance > 0 && similarity.Distance(candidate.simhash, fingerprint) <= distance) {
+				c = candidate
+				break
+			}
+		}
+		if c == nil {
+			e.Clusters = append(e.Clusters, ResponseCluster{
+				Status:    req.Response.Status,
+				Identical: true,
+				Hash:      hash,
+				Simhash:   fmt.Sprintf("%016x", fingerprint),
+				MinLength: len(body),
+				Sample:    req.Path,
+				simhash:   fingerprint,
+				hashes:    map[string]bool{},
+			})
+			c = &e.Clusters[len(e.Clusters)-1]
+		}
+		c.Size++
+		c.hashes[hash] = true
+		c.Identical = len(c.hashes) == 1
+		c.MinLength = min(c.MinLength, len(body))
+		c.MaxLength = max(c.MaxLength, len(body))
+		if len(c.IDs) < clusterIDs {
+			c.IDs = append(c.IDs, req.ID)
+		}
+	}
+
+	endpoints := []EndpointClusters{}
+	for _, key := range order {
+		e := byKey[key]
+		if e.Responses < minResponses {
+			continue
+		}
+		sort.SliceStable(e.Clusters, func(i, j int) bool { return e.Clusters[i].Size > e.Clusters[j].Size })
+		endpoints = append(endpoints, *e)
+	}
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		if len(endpoints[i].Clusters) != len(endpoints[j].Clusters) {
+			return len(endpoints[i].Clusters) > len(endpoints[j].Clusters)
+		}
+		return endpoints[i].Responses > endpoints[j].Responses
+	})
+	return endpoints
+}
+
+func printClusters(endpoints []EndpointClusters) {
+	pterm.DefaultSection.Printf("Response clusters (%d endpoints)\n", len(endpoints))
+	for _, e := range endpoints {
+		fmt.Printf("\n%s  %d response(s) → %d cluster(s)\n", pterm.FgCyan.Sprint(output.SanitizeText(e.Endpoint)), e.Responses, len(e.Clusters))
+		for k, c := range e.Clusters {
+			size := output.FormatBodySize(c.MinLength)
+			if c.MaxLength != c.MinLength {
+				size += "–" + output.FormatBodySize(c.MaxLength)
+			}
+			kind := "identical"
+			if !c.Identical {
+				kind = "similar"
+			}
+			ids := strings.Join(c.IDs, ", ")
+			if c.Size > len(c.IDs) {
+				ids += ", ..."
+			}
+			fmt.Printf("  #%d %s ×%-4d %-16s %-9s %s\n", k+1, colorStatus(c.Status, fmt.Sprint(c.Status)), c.Size, size, kind, ids)
+			if k > 0 {
+				fmt.Printf("       %s\n", pterm.FgGray.Sprint("e.g. "+output.SanitizeText(truncateCell(c.Sample, 80))))
+			}
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	codeLang    string
+	codeUseVars bool
+	codeSaved   string
+)
+
+// codeLanguages lists the supported --lang values in help order
+var codeLanguages = []string{"httpie", "python", "go", "js-fetch", "powershell"}
+
+var codeCmd = &cobra.Command{
+	Use:   "code <request-id>",
+	Short: "Generate replay code (httpie, python, go, fetch, powershell)",
+	Long: `Generate code that replays a captured request in another ecosystem.
+
+Works like 'rep curl' (same header skipping and --use-vars substitution)
+but emits a snippet for the chosen language instead of a curl command.
+
+Languages (--lang):
+  httpie       HTTPie command line (http ...)
+  python       Python requests script
+  go           Go net/http program
+  js-fetch     JavaScript fetch() (Node 18+ / browser console)
+  powershell   PowerShell Invoke-WebRequest
+
+With --use-vars, auth values are read from environment variables
+($BEARER_TOKEN, $SESSION_COOKIE, ...) in the target language's idiom. The
+js-fetch snippet reads them from process.env under Node, and from
+placeholders to fill in when pasted into a browser console.
+
+Examples:
+  rep code h_abc123 --lang python               Python requests snippet
+  rep code h_abc123 --lang go --use-vars        Go program reading auth from env
+  rep code h_abc123 --lang js-fetch             fetch() call
+  rep code h_abc123 --lang httpie --use-vars    HTTPie with $VARIABLES`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		requestID := args[0]
+
+		generator, ok := codeGenerators[strings.ToLower(codeLang)]
+		if !ok {
+			return fmt.Errorf("unsupported language: %s (use %s)", codeLang, strings.Join(codeLanguages, ", "))
+		}
+
+		req, err := lookupRequest(requestID, codeSaved)
+		if err != nil {
+			return err
+		}
+
+		if req == nil {
+			return notFoundError("request not found: %s", requestID).withHint("Use 'rep list' to see available request IDs")
+		}
+
+		shell, err := currentShellDialect()
+		if err != nil {
+			return err
+		}
+		fmt.Println(generator(req, codeUseVars, shell))
+
+		if codeUseVars {
+			comment, loadAuth := "#", `eval "$(rep auth --export)"`
+			switch strings.ToLower(codeLang) {
+			case "go", "js-fetch":
+				comment = "//"
+			case "httpie":
+				comment, loadAuth = shell.Comment, shell.LoadAuth
+			}
+			fmt.Println()
+			fmt.Printf("%s Run first: %s\n", comment, loadAuth)
+		}
+
+		return nil
+	},
+}
+
+// codeGenerators maps --lang values to snippet generators. The shell dialect
+// (--shell-syntax) only matters to command lines.
+var codeGenerators = map[string]func(req *store.Request, useVars bool, shell shellDialect) string{
+	"httpie":     generateHTTPie,
+	"python":     generatePython,
+	"go":         generateGo,
+	"js-fetch":   generateJSFetch,
+	"powershell": generatePowerShell,
+}
+
+// varRefPattern matches $VARIABLE references produced by replaceWithVars
+var varRefPattern = regexp.MustCompile(`\$([A-Z_][A-Z0-9_]*)`)
+
+// renderTemplated renders a header value as an expression in the target language.
+// Literal parts go through quote, $VARIABLE references through ref, joined by concat.
+func renderTemplated(h replayHeader, quote func(string) string, ref func(string) string, concat string) string {
+	if !h.Templated {
+		return quote(h.Value)
+	}
+	var parts []string
+	last := 0
+	for _, loc := range varRefPattern.FindAllStringSubmatchIndex(h.Value, -1) {
+		if loc[0] > last {
+			parts = append(parts, quote(h.Value[last:loc[0]]))
+		}
+		parts = append(parts, ref(h.Value[loc[2]:loc[3]]))
+		last = loc[1]
+	}
+	if last < len(h.Value) {
+		parts = append(parts, quote(h.Value[last:]))
+	}
+	return strings.Join(parts, concat)
+}
+
+// codeTarget returns the URL and body to replay as values to render with
+// renderTemplated: with useVars, credentials in them are $VARIABLE
+// references, as in 'rep curl --use-vars' (see replayTarget)
+func codeTarget(req *store.Request, useVars bool) (replayHeader, replayHeader) {
+	target, body, _ := replayTarget(req, useVars)
+	return replayHeader{Value: target, Templated: target != req.URL}, replayHeader{Value: body, Templated: body != req.Body}
+}
+
+// mergedReplayHeaders folds repeated header names into a single value so they
+// fit dictionary-style header APIs (cookies join with "; ", others with ", ").
+func mergedReplayHeaders(req *store.Request, useVars bool) []replayHeader {
+	var merged []replayHeader
+	index := make(map[string]int)
+	for _, h := range replayHeaders(req, useVars) {
+		key := strings.ToLower(h.Name)
+		if i, ok := index[key]; ok {
+			sep := ", "
+			if key == "cookie" {
+				sep = "; "
+			}
+			merged[i].Value += sep + h.Value
+			merged[i].Templated = merged[i].Templated || h.Templated
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, h)
+	}
+	return merged
+}
+
+// jsonString quotes s as a JSON string literal (valid in Python and JavaScript)
+func jsonString(s string) string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return strconv.Quote(s)
+	}
+	return string(data)
+}
+
+func generateHTTPie(req *store.Request, useVars bool, shell shellDialect) string {
+	quote := func(v replayHeader) string {
+		if v.Templated {
+			return shell.Template(v)
+		}
+		return shell.Quote(v.Value)
+	}
+	target, body := codeTarget(req, useVars)
+	first := "http"
+	if body.Value != "" {
+		first += " --raw " + quote(body)
+	}
+	lines := []string{fmt.Sprintf("%s %s %s", first, req.Method, quote(target))}
+
+	for _, h := range replayHeaders(req, useVars) {
+		h.Value = h.Name + ":" + h.Value
+		lines = append(lines, "  "+quote(h))
+	}
+
+	return strings.Join(lines, shell.Continue)
+}
+
+func generatePython(req *store.Request, useVars bool, _ shellDialect) string {
+	headers := mergedReplayHeaders(req, useVars)
+	target, body := codeTarget(req, useVars)
+	env := func(name string) string {
+		return fmt.Sprintf("os.environ[%q]", name)
+	}
+	usesEnv := target.Templated || body.Templated
+	for _, h := range headers {
+		usesEnv = usesEnv || h.Templated
+	}
+
+	var b strings.Builder
+	if usesEnv {
+		b.WriteString("import os\n")
+	}
+	b.WriteString("import requests\n\n")
+
+	b.WriteString("headers = {\n")
+	for _, h := range headers {
+		value := renderTemplated(h, jsonString, env, " + ")
+		fmt.Fprintf(&b, "    %s: %s,\n", jsonString(h.Name), value)
+	}
+	b.WriteString("}\n")
+
+	dataArg := ""
+	if body.Value != "" {
+		fmt.Fprintf(&b, "data = %s\n", renderTemplated(body, jsonString, env, " + "))
+		dataArg = ", data=data"
+	}
+
+	fmt.Fprintf(&b, "\nresp = requests.request(%s, %s, headers=headers%s)\n",
+		jsonString(req.Method), renderTemplated(target, jsonString, env, " + "), dataArg)
+	b.WriteString("print(resp.status_code)\n")
+	b.WriteString("print(resp.text)")
+	return b.String()
+}
+
+func generateGo(req *store.Request, useVars bool, _ shellDialect) string {
+	headers := replayHeaders(req, useVars)
+	target, body := codeTarget(req, useVars)
+	env := func(name string) string {
+		return fmt.Sprintf("os.Getenv(%q)", name)
+	}
+	usesEnv := target.Templated || body.Templated
+	for _, h := range headers {
+		usesEnv = usesEnv || h.Templated
+	}
+
+	imports := []string{"fmt", "io", "net/http"}
+	if usesEnv {
+		imports = append(imports, "os")
+	}
+	if body.Value != "" {
+		imports = append(imports, "strings")
+	}
+
+	var b strings.Builder
+	b.WriteString("package main\n\nimport (\n")
+	for _, imp := range imports {
+		fmt.Fprintf(&b, "\t%q\n", imp)
+	}
+	b.WriteString(")\n\nfunc main() {\n")
+
+	bodyArg := "nil"
+	if body.Value != "" {
+		fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n", renderTemplated(body, strconv.Quote, env, "+"))
+		bodyArg = "body"
+	}
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(%s, %s, %s)\n", strconv.Quote(req.Method), renderTemplated(target, strconv.Quote, env, "+"), bodyArg)
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+
+	for _, h := range headers {
+		value := renderTemplated(h, strconv.Quote, env, "+")
+		fmt.Fprintf(&b, "\treq.Header.Add(%s, %s)\n", strconv.Quote(h.Name), value)
+	}
+
+	b.WriteString("\n\tresp, err := http.DefaultClient.Do(req)\n")
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	b.WriteString("\tdefer resp.Body.Close()\n\n")
+	b.WriteString("\tdata, err := io.ReadAll(resp.Body)\n")
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	b.WriteString("\tfmt.Println(resp.Status)\n")
+	b.WriteString("\tfmt.Println(string(data))\n")
+	b.WriteString("}")
+	return b.String()
+}
+
+func generateJSFetch(req *store.Request, useVars bool, _ shellDialect) string {
+	var vars []string
+	env := func(name string) string {
+		if !containsString(vars, name) {
+			vars = append(vars, name)
+		}
+		return "env." + name
+	}
+	target, body := codeTarget(req, useVars)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "const resp = await fetch(%s, {\n", renderTemplated(target, jsonString, env, " + "))
+	fmt.Fprintf(&b, "  method: %s,\n", jsonString(req.Method))
+
+	headers := mergedReplayHeaders(req, useVars)
+	if len(headers) > 0 {
+		b.WriteString("  headers: {\n")
+		for _, h := range headers {
+			value := renderTemplated(h, jsonString, env, " + ")
+			fmt.Fprintf(&b, "    %s: %s,\n", jsonString(h.Name), value)
+		}
+		b.WriteString("  },\n")
+	}
+
+	if body.Value != "" {
+		fmt.Fprintf(&b, "  body: %s,\n", renderTemplated(body, jsonString, env, " + "))
+	}
+	b.WriteString("});\n")
+	b.WriteString("console.log(resp.status);\n")
+	b.WriteString("console.log(await resp.text());")
+	if len(vars) == 0 {
+		return b.String()
+	}
+
+	// process is undefined in a browser console: fall back to placeholders
+	// the user fills in there
+	var prelude strings.Builder
+	prelude.WriteString("// Node 18+ reads these from the environment; in a browser console, fill in the placeholders\n")
+	prelude.WriteString("const env = typeof process !== \"undefined\" ? process.env : {\n")
+	for _, name := range vars {
+		fmt.Fprintf(&prelude, "  %s: \"<%s>\",\n", name, name)
+	}
+	prelude.WriteString("};\n\n")
+	return prelude.String() + b.String()
+}
+
+func generatePowerShell(req *store.Request, useVars bool, _ shellDialect) string {
+	psQuote := func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	env := func(name string) string {
+		return "$env:" + name
+	}
+	target, body := codeTarget(req, useVars)
+
+	var b strings.Builder
+	contentType := ""
+	b.WriteString("$headers = @{\n")
+	for _, h := range mergedReplayHeaders(req, useVars) {
+		// Invoke-WebRequest rejects Content-Type in -Headers; pass it via -ContentType
+		if strings.EqualFold(h.Name, "content-type") {
+			contentType = h.Value
+			continue
+		}
+		value := renderTemplated(h, psQuote, env, " + ")
+		fmt.Fprintf(&b, "    %s = %s\n", psQuote(h.Name), value)
+	}
+	b.WriteString("}\n")
+
+	// A concatenation needs parentheses to be one command argument
+	uri := renderTemplated(target, psQuote, env, " + ")
+	if target.Templated {
+		uri = "(" + uri + ")"
+	}
+	args := []string{
+		"-Uri " + uri,
+		"-Method " + req.Method,
+		"-Headers $headers",
+	}
+	if contentType != "" {
+		args = append(args, "-ContentType "+psQuote(contentType))
+	}
+	if body.Value != "" {
+		fmt.Fprintf(&b, "$body = %s\n", renderTemplated(body, psQuote, env, " + "))
+		args = append(args, "-Body $body")
+	}
+	args = append(args, "-UseBasicParsing")
+
+	fmt.Fprintf(&b, "$resp = Invoke-WebRequest %s\n", strings.Join(args, " "))
+	b.WriteString("$resp.StatusCode\n")
+	b.WriteString("$resp.Content")
+	return b.String()
+}
+
+func init() {
+	rootCmd.AddCommand(codeCmd)
+	codeCmd.Flags().StringVar(&codeLang, "lang", "python", "Target language: "+strings.Join(codeLanguages, ", "))
+	codeCmd.Flags().BoolVar(&codeUseVars, "use-vars", false, "Read auth tokens from environment variables")
+	codeCmd.Flags().StringVar(&codeSaved, "saved", "", "Read from saved session (ID or 'latest')")
+}
@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// completionLimit caps the request IDs offered at once; the newest win
+const completionLimit = 200
+
+// Argument kinds completed from the store, keyed by the placeholder used
+// in a command's Use line
+var completionArgKinds = map[string]string{
+	"request-id":          "request",
+	"url|request-id":      "request",
+	"endpoint|request-id": "request",
+	"session-id":          "session",
+	"domain":              "domain",
+	"target-domain":       "domain",
+	"base-domain":         "domain",
+	"domain/path":         "domain",
+}
+
+// registerCompletions wires dynamic completion into every command: request
+// and session IDs for arguments (from their Use line), --saved, --session,
+// and domains and @groups for -d/--not-domain. Called once the command tree
+// is built.
+func registerCompletions(cmd *cobra.Command) {
+	if cmd.ValidArgsFunction == nil && cmd.ValidArgs == nil {
+		if kinds := completionArgs(cmd); len(kinds) > 0 {
+			cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+				i := len(args)
+				if i >= len(kinds) {
+					if !strings.HasSuffix(kinds[len(kinds)-1], "...") {
+						return nil, cobra.ShellCompDirectiveNoFileComp
+					}
+					i = len(kinds) - 1
+				}
+				return completeKind(cmd, strings.TrimSuffix(kinds[i], "..."), toComplete)
+			}
+		}
+	}
+	for name, complete := range map[string]func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective){
+		"saved":      completeSavedFlag,
+		"session":    completeLiveSessions,
+		"domain":     completeDomainFlag,
+		"not-domain": completeDomainFlag,
+	} {
+		if cmd.LocalFlags().Lookup(name) != nil {
+			_ = cmd.RegisterFlagCompletionFunc(name, complete)
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		registerCompletions(sub)
+	}
+}
+
+// completionArgs returns the completion kind of each positional argument in
+// the Use line ("" when not completed); a trailing "..." marks a variadic one
+func completionArgs(cmd *cobra.Command) []string {
+	fields := strings.Fields(cmd.Use)
+	var kinds []string
+	completed := false
+	for _, field := range fields[1:] {
+		if strings.HasPrefix(field, "-") || field == "|" {
+			break
+		}
+		name := strings.Trim(field, "<>[]")
+		variadic := strings.HasSuffix(name, "...")
+		name = strings.TrimSuffix(name, "...")
+		kind := completionArgKinds[name]
+		if name == "name" && cmd.Parent() != nil && cmd.Parent().Name() == "group" && cmd.Name() != "create" {
+			kind = "group"
+		}
+		if kind != "" {
+			completed = true
+		}
+		if variadic {
+			kind += "..."
+		}
+		kinds = append(kinds, kind)
+	}
+	if !completed {
+		return nil
+	}
+	return kinds
+}
+
+func completeKind(cmd *cobra.Command, kind, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch kind {
+	case "request":
+		saved := ""
+		if flag := cmd.Flags().Lookup("saved"); flag != nil {
+			saved = flag.Value.String()
+		}
+		return completeRequestIDs(saved, toComplete), cobra.ShellCompDirectiveNoFileComp
+	case "session":
+		return completeSessionIDs(toComplete), cobra.ShellCompDirectiveNoFileComp
+	case "domain":
+		return completeDomains(toComplete, false), cobra.ShellCompDirectiveNoFileComp
+	case "group":
+		return completeGroupNames(toComplete, ""), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRequestIDs offers the newest request IDs with method, status and
+// URL as the description
+func completeRequestIDs(saved, toComplete string) []string {
+	store.SelectLiveSession(liveSession)
+	var requests []store.Request
+	if saved != "" {
+		s, err := store.Get()
+		if err != nil {
+			return nil
+		}
+		if session := resolveSession(s, saved); session != nil {
+			requests = session.Requests
+		}
+	} else {
+		paths, err := store.GetLiveFilePaths()
+		if err != nil {
+			return nil
+		}
+		if requests, err = store.LoadLiveWithoutBodiesAll(paths); err != nil {
+			return nil
+		}
+	}
+
+	var out []string
+	for i := len(requests) - 1; i >= 0 && len(out) < completionLimit; i-- {
+		req := &requests[i]
+		if !strings.HasPrefix(req.ID, toComplete) {
+			continue
+		}
+		status := "-"
+		if req.Response != nil {
+			status = fmt.Sprint(req.Response.Status)
+		}
+		out = append(out, fmt.Sprintf("%s\t%s %s %s", req.ID, req.Method, status, truncateCell(req.URL, 60)))
+	}
+	return out
+}
+
+func completeSessionIDs(toComplete string) []string {
+	s, err := store.Get()
+	if err != nil {
+		return nil
+	}
+	var out []string
+	if strings.HasPrefix("latest", toComplete) {
+		out = append(out, "latest\tMost recent saved session")
+	}
+	for _, sess := range s.ListSessions() {
+		if strings.HasPrefix(sess.ID, toComplete) {
+			out = append(out, fmt.Sprintf("%s\t%d requests %s", sess.ID, len(sess.Requests), sess.Note))
+		}
+	}
+	for _, a := range s.ListArchivedSessions() {
+		if strings.HasPrefix(a.ID, toComplete) {
+			out = append(out, fmt.Sprintf("%s\tarchived, %d requests %s", a.ID, a.Requests, a.Note))
+		}
+	}
+	return out
+}
+
+func completeSavedFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeSessionIDs(toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeLiveSessions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	live, _ := store.ListLiveSessions()
+	var out []string
+	for _, l := range live {
+		for _, value := range []string{l.ID, l.Profile} {
+			if value != "" && strings.HasPrefix(value, toComplete) {
+				out = append(out, fmt.Sprintf("%s\t%d requests", value, liveSessionRequests(l)))
+			}
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeDomainFlag completes the last element of a comma-separated
+// domain list, keeping (and skipping) the elements before it
+func completeDomainFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix, toComplete = toComplete[:i+1], toComplete[i+1:]
+	}
+	listed := parseCommaSeparated(prefix)
+	var out []string
+	for _, candidate := range completeDomains(toComplete, true) {
+		value, _, _ := strings.Cut(candidate, "\t")
+		if !containsString(listed, value) {
+			out = append(out, prefix+candidate)
+		}
+	}
+	if prefix != "" {
+		return out, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeDomains offers captured and listed domains, busiest first, and
+// with groups the @group names
+func completeDomains(toComplete string, groups bool) []string {
+	store.SelectLiveSession(liveSession)
+	if groups && strings.HasPrefix(toComplete, "@") {
+		return completeGroupNames(strings.TrimPrefix(toComplete, "@"), "@")
+	}
+
+	counts := make(map[string]int)
+	if paths, err := store.GetLiveFilePaths(); err == nil {
+		if requests, err := store.LoadLiveWithoutBodiesAll(paths); err == nil {
+			for _, d := range store.NewTempStore(requests).GetDomains() {
+				counts[d.Domain] = d.RequestCount
+			}
+		}
+	}
+	if s, err := store.Get(); err == nil {
+		for _, list := range [][]string{s.GetPrimaryDomains(), s.GetIgnoredDomains()} {
+			for _, d := range list {
+				if _, ok := counts[d]; !ok {
+					counts[d] = 0
+				}
+			}
+		}
+	}
+
+	var domains []string
+	for d := range counts {
+		if strings.HasPrefix(d, toComplete) {
+			domains = append(domains, d)
+		}
+	}
+	sort.Slice(domains, func(i, j int) bool {
+		if counts[domains[i]] != counts[domains[j]] {
+			return counts[domains[i]] > counts[domains[j]]
+		}
+		return domains[i] < domains[j]
+	})
+	out := make([]string, len(domains))
+	for i, d := range domains {
+		out[i] = fmt.Sprintf("%s\t%d requests", d, counts[d])
+	}
+	if groups && toComplete == "" {
+		out = append(out, completeGroupNames("", "@")...)
+	}
+	return out
+}
+
+func completeGroupNames(toComplete, prefix string) []string {
+	s, err := store.Get()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for name, domains := range s.DomainGroups {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, fmt.Sprintf("%s%s\t%d domains", prefix, name, len(domains)))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/openapi"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	coverageSaved  string
+	coverageSpec   string
+	coverageDomain string
+)
+
+// CoverageReport compares a spec with the traffic captured for its API
+type CoverageReport struct {
+	Spec       string              `json:"spec"` // File read
+	Title      string              `json:"title,omitempty"`
+	Version    string              `json:"version,omitempty"`
+	Format     string              `json:"format"` // "openapi 3.0.3" or "swagger 2.0"
+	Domains    []string            `json:"domains"`
+	Documented int                 `json:"documented"` // Operations in the spec
+	Observed   int                 `json:"observed"`   // Of those, seen in traffic
+	Percent    float64             `json:"coverage_percent"`
+	Unobserved []CoverageOperation `json:"unobserved"` // Documented, never seen: probe directly
+	Shadow     []ShadowEndpoint    `json:"shadow"`     // Seen, not documented
+	Operations []CoverageOperation `json:"operations"`
+}
+
+// CoverageOperation is a documented operation and the traffic that hit it
+type CoverageOperation struct {
+	openapi.Operation
+	Requests int      `json:"requests"`
+	Statuses []int    `json:"statuses,omitempty"`
+	IDs      []string `json:"ids,omitempty"` // Up to 5
+}
+
+// ShadowEndpoint is an observed endpoint the spec doesn't document
+type ShadowEndpoint struct {
+	Endpoint string   `json:"endpoint"` // METHOD /path/{id}
+	Domain   string   `json:"domain"`
+	Requests int      `json:"requests"`
+	Statuses []int    `json:"statuses"`
+	Reason   string   `json:"reason"` // "undocumented path" or "undocumented method"
+	IDs      []string `json:"ids"`    // Up to 5
+}
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage --spec <openapi.yaml>",
+	Short: "Compare a published OpenAPI spec with captured traffic",
+	Long: `Compare a target's OpenAPI 3 or Swagger 2 spec (YAML or JSON) with the
+traffic captured for its API, in both directions:
+
+  unobserved  Documented operations never seen in traffic. The UI doesn't
+              call them, so they are the least tested: probe them directly.
+  shadow      Endpoints seen in traffic that the spec doesn't document, by
+              path or only by method. Shadow APIs tend to skip the review
+              the documented surface got.
+
+Requests are matched on the spec's path templates ({id} matches one
+segment), after removing the servers' base path (/v1). CORS preflights and
+static resources (scripts, images, ...) are never reported as shadow.
+
+The API's host comes from -d, or from the spec's servers (host in Swagger
+2) when -d is not given.
+
+Examples:
+  rep coverage --spec openapi.yaml -d api.target.com
+  rep coverage --spec swagger.json --saved latest
+  rep coverage --spec openapi.yaml -o json | jq '.unobserved[].path'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if coverageSpec == "" {
+			return usageError("--spec is required").withHint("Download the target's openapi.json or swagger.yaml and pass its path")
+		}
+		data, err := os.ReadFile(coverageSpec)
+		if os.IsNotExist(err) {
+			return notFoundError("spec not found: %s", coverageSpec)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read spec: %w", err)
+		}
+		spec, err := openapi.Parse(data)
+		if err != nil {
+			return parseError("failed to parse spec: %v", err)
+		}
+
+		domains := spec.Hosts
+		if coverageDomain != "" {
+			if domains, err = hostList(strings.ToLower(coverageDomain)); err != nil {
+				return err
+			}
+		}
+		if len(domains) == 0 {
+			return usageError("the spec names no server host; give the API's host with -d")
+		}
+
+		requests, err := filterSource(coverageSaved, store.FilterOptions{Domains: domains})
+		if err != nil || requests == nil {
+			return err
+		}
+		report := buildCoverage(spec, requests)
+		report.Spec, report.Domains = coverageSpec, domains
+
+		if getOutputMode() == "json" {
+			printJSON("coverage", report)
+			return nil
+		}
+		printCoverage(report)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(coverageCmd)
+	coverageCmd.Flags().StringVar(&coverageSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	coverageCmd.Flags().StringVar(&coverageSpec, "spec", "", "OpenAPI 3 or Swagger 2 spec file (YAML or JSON)")
+	coverageCmd.Flags().StringVarP(&coverageDomain, "domain", "d", "", "API hosts, comma-separated or @group (default: the spec's server hosts)")
+}
+
+// coverageIDs caps the example request IDs kept per endpoint
+const coverageIDs = 5
+
+func buildCoverage(spec *openapi.Spec, requests []store.Request) *CoverageReport {
+	report := &CoverageReport{
+		Title:      spec.Title,
+		Version:    spec.Version,
+		Format:     spec.Format,
+		Documented: len(spec.Operations),
+		Unobserved: []CoverageOperation{},
+		Shadow:     []ShadowEndpoint{},
+		Operations: make([]CoverageOperation, len(spec.Operations)),
+	}
+	index := make(map[*openapi.Operation]int, len(spec.Operations))
+	for i := range spec.Operations {
+		report.Operations[i] = CoverageOperation{Operation: spec.Operations[i]}
+		index[&spec.Operations[i]] = i
+	}
+
+	shadow := make(map[string]*ShadowEndpoint)
+	var order []string
+	for i := range requests {
+		req := &requests[i]
+		if req.Domain == "" {
+			store.ComputeRequestFields(req)
+		}
+		path := req.Path
+		if idx := strings.IndexAny(path, "?#"); idx >= 0 {
+			path = path[:idx]
+		}
+		status := responseStatus(req)
+		op, pathOnly := spec.Match(req.Method, path)
+		if op != nil {
+			c := &report.Operations[index[op]]
+			c.Requests++
+			addStatus(&c.Statuses, status)
+			if len(c.IDs) < coverageIDs {
+				c.IDs = append(c.IDs, req.ID)
+			}
+			continue
+		}
+		if req.Method == "OPTIONS" || containsString(staticResourceTypes, req.ResourceType) {
+			continue
+		}
+		endpoint := req.Method + " " + store.EndpointTemplate(path)
+		key := req.Domain + " " + endpoint
+		s := shadow[key]
+		if s == nil {
+			s = &ShadowEndpoint{Endpoint: endpoint, Domain: req.Domain, Reason: "undocumented path", Statuses: []int{}}
+			if pathOnly {
+				s.Reason = "undocumented method"
+			}
+			shadow[key] = s
+			order = append(order, key)
+		}
+		s.Requests++
+		addStatus(&s.Statuses, status)
+		if len(s.IDs) < coverageIDs {
+			s.IDs = append(s.IDs, req.ID)
+		}
+	}
+
+	for _, c := range report.Operations {
+		if c.Requests > 0 {
+			report.Observed++
+		} else {
+			report.Unobserved = append(report.Unobserved, c)
+		}
+	}
+	if report.Documented > 0 {
+		report.Percent = float64(report.Observed*1000/report.Documented) / 10
+	}
+	for _, key := range order {
+		report.Shadow = append(report.Shadow, *shadow[key])
+	}
+	// Answered shadow endpoints first: those exist
+	sort.SliceStable(report.Shadow, func(i, j int) bool {
+		return shadowAnswered(report.Shadow[i]) && !shadowAnswered(report.Shadow[j])
+	})
+	return report
+}
+
+// shadowAnswered reports whether an endpoint ever answered below 400
+func shadowAnswered(s ShadowEndpoint) bool {
+	for _, status := range s.Statuses {
+		if status > 0 && status < 400 {
+			return true
+		}
+	}
+	return false
+}
+
+// addStatus adds a response status to a sorted set
+func addStatus(statuses *[]int, status int) {
+	if status == 0 {
+		return
+	}
+	for _, s := range *statuses {
+		if s == status {
+			return
+		}
+	}
+	*statuses = append(*statuses, status)
+	sort.Ints(*statuses)
+}
+
+func printCoverage(r *CoverageReport) {
+	title := r.Title
+	if title == "" {
+		title = r.Spec
+	}
+	pterm.DefaultSection.Printf("API coverage: %s %s (%s)\n", output.SanitizeText(title), output.SanitizeText(r.Version), r.Format)
+	fmt.Printf("  %s: %d of %d documented operations observed (%.1f%%)\n", strings.Join(r.Domains, ", "), r.Observed, r.Documented, r.Percent)
+
+	fmt.Println()
+	pterm.DefaultSection.Printf("Documented, never observed (%d)\n", len(r.Unobserved))
+	for _, c := range r.Unobserved {
+		label := fmt.Sprintf("%-7s %s", c.Method, output.SanitizeText(c.Path))
+		if c.OperationID != "" {
+			label += pterm.FgGray.Sprint("  " + output.SanitizeText(c.OperationID))
+		}
+		if c.Deprecated {
+			label += pterm.FgYellow.Sprint("  deprecated")
+		}
+		fmt.Printf("  %s\n", label)
+	}
+	if len(r.Unobserved) == 0 {
+		fmt.Println("  none")
+	}
+
+	fmt.Println()
+	pterm.DefaultSection.Printf("Observed, not documented (%d)\n", len(r.Shadow))
+	for _, s := range r.Shadow {
+		statuses := make([]string, len(s.Statuses))
+		for i, status := range s.Statuses {
+			statuses[i] = colorStatus(status, strconv.Itoa(status))
+		}
+		fmt.Printf("  %-45s ×%-3d %-12s %s  %s\n", output.SanitizeText(truncateCell(s.Endpoint, 45)), s.Requests,
+			strings.Join(statuses, ","), pterm.FgGray.Sprint(s.Reason), s.IDs[0])
+	}
+	if len(r.Shadow) == 0 {
+		fmt.Println("  none")
+	}
+
+	if len(r.Unobserved) > 0 {
+		fmt.Println()
+		pterm.Info.Println("Unobserved operations: build a request from a captured sibling with 'rep replay <id> --edit'")
+	}
+}
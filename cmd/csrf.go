@@ -0,0 +1,557 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/replay"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	csrfSaved    string
+	csrfDomain   string
+	csrfConfirm  string
+	csrfUseVars  bool
+	csrfInsecure bool
+)
+
+// csrfTokenName matches header, parameter and cookie names of anti-CSRF tokens
+var csrfTokenName = regexp.MustCompile(`(?i)(csrf|xsrf|authenticity_token|requestverificationtoken|anti.?forgery|^_token$)`)
+
+// csrfForgedOrigin is the Origin a confirmation replay claims to come from
+const csrfForgedOrigin = "https://csrf-probe.invalid"
+
+// CSRFToken is one anti-CSRF token name and how the capture used it
+type CSRFToken struct {
+	Name         string   `json:"name"`
+	In           []string `json:"in"`     // header, body, query, cookie
+	Values       int      `json:"values"` // Distinct values seen
+	Rotation     string   `json:"rotation"`
+	IssuedBy     []string `json:"issued_by,omitempty"` // Responses that delivered a value
+	UsedBy       []string `json:"used_by"`
+	DoubleSubmit bool     `json:"double_submit,omitempty"` // Sent value equals a cookie value
+	values       map[string]bool
+}
+
+// CSRFEndpoint is a state-changing endpoint and its CSRF posture
+type CSRFEndpoint struct {
+	Method     string            `json:"method"`
+	Domain     string            `json:"domain"`
+	Path       string            `json:"path"`
+	Protection string            `json:"protection"`     // token, header-auth, none
+	Risk       string            `json:"risk,omitempty"` // high, medium, low (protection none only)
+	Tokens     []string          `json:"tokens,omitempty"`
+	Cookies    map[string]string `json:"cookies,omitempty"` // Cookie sent -> SameSite from its Set-Cookie ("" = not captured)
+	Reason     string            `json:"reason,omitempty"`
+	IDs        []string          `json:"ids"`
+}
+
+var csrfCmd = &cobra.Command{
+	Use:   "csrf",
+	Short: "Track CSRF tokens and find state-changing endpoints without them",
+	Long: `Track anti-CSRF tokens across the capture and classify every
+state-changing endpoint (POST, PUT, PATCH, DELETE sent with cookies).
+
+Tokens are found in request headers (X-CSRF-Token, X-XSRF-TOKEN, ...),
+form and JSON body fields and query parameters (csrf_token, _csrf,
+authenticity_token, __RequestVerificationToken, ...) and cookies. For each
+token name the command reports where it travels, which responses issued
+its values, whether it rotates per request or stays fixed for the session,
+and whether it is a double-submit cookie.
+
+Endpoints are classified as:
+  token        Sends a CSRF token
+  header-auth  Sends an Authorization header, which browsers never add to
+               cross-site requests (cookie-only replays may still work)
+  none         Relies on cookies alone. High risk when a cookie it sends
+               was set with SameSite=None, low when the body is JSON (a
+               cross-site JSON POST needs a CORS preflight), medium otherwise
+
+Confirming (--confirm <id>):
+  Replays the request as a cross-site forgery would send it: CSRF tokens,
+  Authorization and X-Requested-With removed, Origin set to a foreign site
+  and no Referer, cookies kept. The captured request is sent first as the
+  baseline. A forged request that gets the same 2xx is reported as
+  accepted.
+
+Examples:
+  rep csrf                            Tokens and endpoints
+  rep csrf -d acme.com -o json
+  rep csrf --confirm demo_0015        Replay without the token
+  rep csrf --confirm h_abc --use-vars`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if csrfConfirm != "" {
+			return confirmCSRF(csrfConfirm)
+		}
+
+		scope, err := baseDomainScope(csrfDomain)
+		if err != nil {
+			return err
+		}
+		requests, err := filterSource(csrfSaved, store.FilterOptions{})
+		if err != nil || requests == nil {
+			return err
+		}
+		if len(scope) > 0 {
+			kept := requests[:0]
+			for _, req := range requests {
+				if scope.covers(hostFromURL(req.URL)) {
+					kept = append(kept, req)
+				}
+			}
+			requests = kept
+		}
+		store.SortRequests(requests, "time", false)
+
+		tokens, endpoints := analyzeCSRF(requests)
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"tokens":    tokens,
+				"endpoints": endpoints,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		printCSRF(tokens, endpoints)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(csrfCmd)
+	csrfCmd.Flags().StringVar(&csrfSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	csrfCmd.Flags().StringVarP(&csrfDomain, "domain", "d", "", "Only requests on these registrable domains (comma-separated or @group)")
+	csrfCmd.Flags().StringVar(&csrfConfirm, "confirm", "", "Replay a request without its CSRF token to confirm")
+	csrfCmd.Flags().BoolVar(&csrfUseVars, "use-vars", false, "Resolve auth from environment / ~/.rep/auth-<domain>.env")
+	csrfCmd.Flags().BoolVarP(&csrfInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+}
+
+// isStateChanging reports whether a method changes server state
+func isStateChanging(method string) bool {
+	switch strings.ToUpper(method) {
+	case "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
+
+// csrfTokensSent returns the CSRF tokens a request sends as "in:name" keys
+// with their values
+func csrfTokensSent(req *store.Request) map[string]string {
+	sent := make(map[string]string)
+	for name, values := range req.Headers {
+		if csrfTokenName.MatchString(name) && len(values) > 0 && values[0] != "" {
+			sent["header:"+strings.ToLower(name)] = values[0]
+		}
+	}
+	for _, name := range bodyParamNames(req) {
+		if csrfTokenName.MatchString(name) {
+			if v := requestField(req, name); v != "" {
+				sent["body:"+name] = v
+			}
+		}
+	}
+	if parsed, err := url.Parse(req.URL); err == nil {
+		for name, values := range parsed.Query() {
+			if csrfTokenName.MatchString(name) && len(values) > 0 && values[0] != "" {
+				sent["query:"+name] = values[0]
+			}
+		}
+	}
+	return sent
+}
+
+// cookieSameSite maps every cookie name set in the capture to the SameSite
+// attribute of its last Set-Cookie ("unset" when absent)
+func cookieSameSite(requests []store.Request) map[string]string {
+	sameSite := make(map[string]string)
+	for i := range requests {
+		if requests[i].Response == nil {
+			continue
+		}
+		values := store.HeaderValues(requests[i].Response.Headers, "set-cookie")
+		resp := http.Response{Header: http.Header{"Set-Cookie": values}}
+		for _, c := range resp.Cookies() {
+			switch c.SameSite {
+			case http.SameSiteNoneMode:
+				sameSite[c.Name] = "None"
+			case http.SameSiteLaxMode:
+				sameSite[c.Name] = "Lax"
+			case http.SameSiteStrictMode:
+				sameSite[c.Name] = "Strict"
+			default:
+				sameSite[c.Name] = "unset"
+			}
+		}
+	}
+	return sameSite
+}
+
+// analyzeCSRF tracks CSRF tokens and classifies state-changing endpoints
+func analyzeCSRF(requests []store.Request) ([]CSRFToken, []CSRFEndpoint) {
+	sameSite := cookieSameSite(requests)
+	tokenIndex := make(map[string]*CSRFToken)
+	var tokenOrder []string
+	endpointIndex := make(map[string]*CSRFEndpoint)
+	var endpointOrder []string
+
+	for i := range requests {
+		req := &requests[i]
+		cookies := sentCookies(req)
+		sent := csrfTokensSent(req)
+		for name, value := range cookies {
+			if csrfTokenName.MatchString(name) {
+				sent["cookie:"+name] = value
+			}
+		}
+
+		var tokenNames []string
+		for key, value := range sent {
+			in, name, _ := strings.Cut(key, ":")
+			lower := strings.ToLower(name)
+			t := tokenIndex[lower]
+			if t == nil {
+				t = &CSRFToken{Name: name, values: make(map[string]bool)}
+				tokenIndex[lower] = t
+				tokenOrder = append(tokenOrder, lower)
+			}
+			if !containsString(t.In, in) {
+				t.In = append(t.In, in)
+			}
+			t.values[value] = true
+			if in != "cookie" {
+				if !containsString(t.UsedBy, req.ID) {
+					t.UsedBy = append(t.UsedBy, req.ID)
+				}
+				for cookieName, cookieValue := range cookies {
+					if cookieValue == value && csrfTokenName.MatchString(cookieName) {
+						t.DoubleSubmit = true
+					}
+				}
+				tokenNames = append(tokenNames, name)
+			}
+		}
+
+		if !isStateChanging(req.Method) || len(cookies) == 0 {
+			continue
+		}
+		parsed, err := url.Parse(req.URL)
+		if err != nil {
+			continue
+		}
+		key := req.Method + " " + parsed.Host + store.EndpointTemplate(parsed.Path)
+		e := endpointIndex[key]
+		if e == nil {
+			e = &CSRFEndpoint{Method: req.Method, Domain: parsed.Host, Path: store.EndpointTemplate(parsed.Path), Cookies: make(map[string]string)}
+			endpointIndex[key] = e
+			endpointOrder = append(endpointOrder, key)
+		}
+		e.IDs = append(e.IDs, req.ID)
+		for name := range cookies {
+			e.Cookies[name] = sameSite[name]
+		}
+		for _, name := range tokenNames {
+			if !containsString(e.Tokens, name) {
+				e.Tokens = append(e.Tokens, name)
+			}
+		}
+		switch {
+		case len(e.Tokens) > 0:
+			e.Protection = "token"
+		case store.HeaderFirst(req.Headers, "authorization") != "":
+			if e.Protection == "" {
+				e.Protection = "header-auth"
+			}
+		default:
+			e.Protection = "none"
+			if strings.Contains(strings.ToLower(store.HeaderFirst(req.Headers, "content-type")), "json") {
+				e.Reason = "JSON body"
+			}
+		}
+	}
+
+	var tokens []CSRFToken
+	for _, key := range tokenOrder {
+		t := tokenIndex[key]
+		t.Values = len(t.values)
+		switch {
+		case len(t.UsedBy) <= 1:
+			t.Rotation = "single use seen"
+		case t.Values >= len(t.UsedBy):
+			t.Rotation = "per request"
+		case t.Values == 1:
+			t.Rotation = "fixed"
+		default:
+			t.Rotation = "per session"
+		}
+		t.IssuedBy = csrfIssuers(requests, t.values)
+		sort.Strings(t.In)
+		tokens = append(tokens, *t)
+	}
+
+	endpoints := make([]CSRFEndpoint, 0, len(endpointOrder))
+	for _, key := range endpointOrder {
+		e := endpointIndex[key]
+		if e.Protection == "none" {
+			e.Risk, e.Reason = csrfRisk(e)
+		}
+		sort.Strings(e.Tokens)
+		endpoints = append(endpoints, *e)
+	}
+	rank := map[string]int{"none": 0, "header-auth": 1, "token": 2}
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		if rank[endpoints[i].Protection] != rank[endpoints[j].Protection] {
+			return rank[endpoints[i].Protection] < rank[endpoints[j].Protection]
+		}
+		return oauthSeverityRank[endpoints[i].Risk] < oauthSeverityRank[endpoints[j].Risk]
+	})
+	return tokens, endpoints
+}
+
+// csrfRisk rates an endpoint that relies on cookies alone
+func csrfRisk(e *CSRFEndpoint) (string, string) {
+	var none []string
+	for name, sameSite := range e.Cookies {
+		if sameSite == "None" {
+			none = append(none, name)
+		}
+	}
+	sort.Strings(none)
+	switch {
+	case len(none) > 0:
+		return "high", "cookies with SameSite=None: " + strings.Join(none, ", ")
+	case e.Reason == "JSON body":
+		return "low", "JSON body: cross-site requests need a CORS preflight unless text/plain is accepted"
+	}
+	return "medium", "no token; SameSite Lax/Strict or browser default protects only top-level navigation"
+}
+
+// csrfIssuers returns the requests whose response delivered one of the
+// token values: a Set-Cookie, a response header or the body (meta tag,
+// hidden input, JSON)
+func csrfIssuers(requests []store.Request, values map[string]bool) []string {
+	var ids []string
+	for i := range requests {
+		resp := requests[i].Response
+		if resp == nil {
+			continue
+		}
+		body := store.ResponseBodyText(&requests[i])
+		for value := range values {
+			if len(value) < 6 {
+				continue
+			}
+			found := strings.Contains(body, value)
+			for _, vs := range resp.Headers {
+				for _, v := range vs {
+					found = found || strings.Contains(v, value)
+				}
+			}
+			if found {
+				ids = append(ids, requests[i].ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// forgeCSRFRequest strips what a cross-site attacker could not send: CSRF
+// tokens, Authorization and X-Requested-With, the Referer; Origin becomes a
+// foreign site. Cookies stay. It returns what was removed.
+func forgeCSRFRequest(req *store.Request) (*store.Request, []string) {
+	forged := *req
+	forged.Headers = store.CloneHeaders(req.Headers)
+	if forged.Headers == nil {
+		forged.Headers = store.HeaderMap{}
+	}
+	var removed []string
+	for name := range req.Headers {
+		lower := strings.ToLower(name)
+		if csrfTokenName.MatchString(name) || lower == "authorization" || lower == "x-requested-with" || lower == "referer" {
+			store.DelHeader(forged.Headers, name)
+			removed = append(removed, "header "+name)
+		}
+	}
+	store.SetHeader(forged.Headers, "Origin", csrfForgedOrigin)
+
+	if parsed, err := url.Parse(req.URL); err == nil {
+		query := parsed.Query()
+		changed := false
+		for name := range query {
+			if csrfTokenName.MatchString(name) {
+				query.Del(name)
+				removed = append(removed, "query "+name)
+				changed = true
+			}
+		}
+		if changed {
+			parsed.RawQuery = query.Encode()
+			forged.URL = parsed.String()
+		}
+	}
+
+	body := strings.TrimSpace(req.Body)
+	switch {
+	case strings.HasPrefix(body, "{"):
+		var fields map[string]interface{}
+		if sonic.UnmarshalString(body, &fields) == nil {
+			changed := false
+			for name := range fields {
+				if csrfTokenName.MatchString(name) {
+					delete(fields, name)
+					removed = append(removed, "body "+name)
+					changed = true
+				}
+			}
+			if changed {
+				if out, err := sonic.MarshalString(fields); err == nil {
+					forged.Body = out
+				}
+			}
+		}
+	case strings.Contains(strings.ToLower(store.HeaderFirst(req.Headers, "content-type")), "form-urlencoded"):
+		if values, err := url.ParseQuery(body); err == nil {
+			changed := false
+			for name := range values {
+				if csrfTokenName.MatchString(name) {
+					values.Del(name)
+					removed = append(removed, "body "+name)
+					changed = true
+				}
+			}
+			if changed {
+				forged.Body = values.Encode()
+			}
+		}
+	}
+	sort.Strings(removed)
+	return &forged, removed
+}
+
+// confirmCSRF replays a request with and without its CSRF protections
+func confirmCSRF(requestID string) error {
+	req, err := lookupRequest(requestID, csrfSaved)
+	if err != nil {
+		return err
+	}
+	if req == nil {
+		return notFoundError("request not found: %s", requestID).withHint("Use 'rep csrf' to see state-changing endpoints")
+	}
+	if !isStateChanging(req.Method) {
+		pterm.Warning.Printf("%s is a %s request; CSRF matters for state-changing requests\n", req.ID, req.Method)
+	}
+
+	sendReq, missing := resolveSendRequest(req, csrfUseVars)
+	if err := warnMissingVars(req, missing); err != nil {
+		return err
+	}
+	forged, removed := forgeCSRFRequest(sendReq)
+
+	opts := replay.Options{Insecure: csrfInsecure}
+	baseline, err := replay.Send(context.Background(), sendReq, opts)
+	if err != nil {
+		return fmt.Errorf("baseline request failed: %w", err)
+	}
+	result, err := replay.Send(context.Background(), forged, opts)
+	if err != nil {
+		return fmt.Errorf("forged request failed: %w", err)
+	}
+	accepted := result.Status >= 200 && result.Status < 300 && result.Status == baseline.Status
+
+	if getOutputMode() == "json" {
+		out, _ := sonic.MarshalIndent(map[string]interface{}{
+			"id":       req.ID,
+			"removed":  removed,
+			"origin":   csrfForgedOrigin,
+			"baseline": map[string]interface{}{"status": baseline.Status, "size": baseline.Size},
+			"forged":   map[string]interface{}{"status": result.Status, "size": result.Size},
+			"accepted": accepted,
+		}, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	pterm.DefaultSection.Printf("CSRF confirmation for %s\n", req.ID)
+	fmt.Printf("  %s %s\n", req.Method, output.SanitizeText(req.URL))
+	if len(removed) == 0 {
+		fmt.Printf("  Removed: nothing (no token or header auth); Origin: %s\n\n", csrfForgedOrigin)
+	} else {
+		fmt.Printf("  Removed: %s; Origin: %s\n\n", strings.Join(removed, ", "), csrfForgedOrigin)
+	}
+	fmt.Printf("  %-9s %6d %9s\n", "baseline", baseline.Status, output.FormatBodySize(int(baseline.Size)))
+	fmt.Printf("  %-9s %6d %9s\n\n", "forged", result.Status, output.FormatBodySize(int(result.Size)))
+	switch {
+	case accepted:
+		pterm.Warning.Printf("The forged request was accepted (%d): CSRF protection is not enforced\n", result.Status)
+	case baseline.Status >= 300:
+		pterm.Info.Printf("Baseline returned %d; the captured request no longer succeeds, refresh the session\n", baseline.Status)
+	default:
+		pterm.Success.Printf("The forged request was rejected (%d)\n", result.Status)
+	}
+	return nil
+}
+
+func printCSRF(tokens []CSRFToken, endpoints []CSRFEndpoint) {
+	pterm.DefaultSection.Printf("CSRF tokens (%d)\n", len(tokens))
+	if len(tokens) == 0 {
+		pterm.Info.Println("No CSRF token seen in headers, bodies, query strings or cookies")
+	}
+	for _, t := range tokens {
+		line := fmt.Sprintf("  %-28s in %-12s %d value(s), %s", output.SanitizeText(t.Name), strings.Join(t.In, ","), t.Values, t.Rotation)
+		if t.DoubleSubmit {
+			line += ", double-submit cookie"
+		}
+		fmt.Println(line)
+		if len(t.IssuedBy) > 0 {
+			fmt.Printf("  %s\n", pterm.FgGray.Sprint("  issued by "+strings.Join(t.IssuedBy, ", ")))
+		}
+		if len(t.UsedBy) > 0 {
+			fmt.Printf("  %s\n", pterm.FgGray.Sprint("  sent by   "+strings.Join(t.UsedBy, ", ")))
+		}
+	}
+	fmt.Println()
+
+	pterm.DefaultSection.Printf("State-changing endpoints with cookies (%d)\n", len(endpoints))
+	if len(endpoints) == 0 {
+		pterm.Info.Println("No POST/PUT/PATCH/DELETE request sent cookies")
+		return
+	}
+	unprotected := 0
+	for _, e := range endpoints {
+		label := fmt.Sprintf("%-11s", e.Protection)
+		switch {
+		case e.Protection == "none" && e.Risk == "high":
+			label = pterm.FgRed.Sprint(label)
+		case e.Protection == "none":
+			label = pterm.FgYellow.Sprint(label)
+		default:
+			label = pterm.FgGreen.Sprint(label)
+		}
+		if e.Protection == "none" {
+			unprotected++
+		}
+		fmt.Printf("  %s %-6s %s %s\n", label, e.Method, output.SanitizeText(e.Domain+e.Path), pterm.FgGray.Sprint("["+strings.Join(e.IDs, ", ")+"]"))
+		switch e.Protection {
+		case "token":
+			fmt.Printf("  %s\n", pterm.FgGray.Sprint("            token: "+strings.Join(e.Tokens, ", ")))
+		case "none":
+			fmt.Printf("  %s\n", pterm.FgGray.Sprint("            "+e.Risk+": "+e.Reason))
+		}
+	}
+	fmt.Println()
+	if unprotected > 0 {
+		pterm.Info.Println("Confirm with: rep csrf --confirm <id>")
+	}
+}
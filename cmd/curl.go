@@ -1,26 +1,44 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/bytedance/sonic"
 	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/replay"
 	"github.com/repplus/rep-cli/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var (
-	curlUseVars bool
-	curlSaved   string
+	curlUseVars  bool
+	curlSaved    string
+	curlExec     bool
+	curlRecord   bool
+	curlInsecure bool
+	// Batch mode
+	curlFilterMode bool
+	curlFormat     string
+	curlFilter     requestFilterFlags
 )
 
 var curlCmd = &cobra.Command{
-	Use:   "curl <request-id>",
+	Use:   "curl <request-id> | --filter [filter flags]",
 	Short: "Generate curl command to replay request",
 	Long: `Generate a curl command to replay a captured request.
 
 Use --use-vars to replace auth tokens with shell variables,
-saving tokens when the AI needs to modify and replay requests.
+saving tokens when the AI needs to modify and replay requests. Auth
+headers are replaced, and so are the same token, session and CSRF values
+(or those saved by 'rep auth --save') in the query string and body; the
+injected variables are listed below the command.
 
 Examples:
   rep curl h_abc123                     Generate full curl command
@@ -35,85 +53,84 @@ Without --use-vars (wastes tokens):
   curl -H 'Cookie: session=eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...' ...
 
 With --use-vars (saves tokens):
-  curl -H 'Cookie: $SESSION_COOKIE' ...`,
-	Args: cobra.ExactArgs(1),
+  curl -H "Cookie: $SESSION_COOKIE" ...
+
+Quoting follows --shell-syntax (or $REP_SHELL; default powershell on Windows):
+  rep curl h_abc123 --shell-syntax powershell   curl.exe, '' quotes, backtick continuations
+  rep curl h_abc123 --shell-syntax cmd          "" quotes, ^ continuations, %VAR% references
+
+Execute directly (--exec):
+  rep curl h_abc123 --exec              Send the request, stream the response
+  rep curl h_abc123 --exec --use-vars   Load ~/.rep/auth-<domain>.env first
+  rep curl h_abc123 --exec --record     Also append the response to live.json
+                                        (original_id links it to h_abc123)
+
+Batch mode (--filter) takes the same filter flags as 'rep list' and
+emits commands for every matching request:
+  rep curl --filter -d api.target.com --api --use-vars > replay.sh
+  rep curl --filter -m POST --format makefile > Makefile
+  rep curl --filter -p "/api/" --format ffuf > urls.txt
+
+Batch formats (--format):
+  script     Shell script, one curl command per request (default; bash,
+             or .ps1/.cmd with --shell-syntax powershell/cmd)
+  makefile   Makefile with one target per request ID (make h_abc123)
+  ffuf       Unique URLs, one per line (ffuf -w urls.txt -u FUZZ)`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if curlFilterMode {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		requestID := args[0]
-
-		var req *store.Request
-
-		if curlSaved != "" {
-			// Load from saved session
-			s, err := store.Get()
-			if err != nil {
-				return fmt.Errorf("failed to load store: %w", err)
-			}
-
-			var session *store.Session
-			if curlSaved == "latest" || curlSaved == "last" {
-				session = s.GetLatestSession()
-			} else {
-				session = s.GetSession(curlSaved)
-			}
-
-			if session == nil {
-				pterm.Warning.Printf("Session not found: %s\n", curlSaved)
-				return nil
+		if curlFilterMode {
+			if curlExec {
+				return fmt.Errorf("--exec works with a single request ID, not --filter")
 			}
+			return runCurlBatch()
+		}
 
-			for i := range session.Requests {
-				if session.Requests[i].ID == requestID {
-					req = &session.Requests[i]
-					break
-				}
-			}
-		} else {
-			// Try live.json first
-			livePath, err := store.GetLiveFilePath()
-			if err == nil {
-				export, err := loadLiveExport(livePath)
-				if err == nil {
-					for i := range export.Requests {
-						if export.Requests[i].ID == requestID {
-							req = &export.Requests[i]
-							break
-						}
-					}
-				}
-			}
+		requestID := args[0]
 
-			// Fall back to saved sessions
-			if req == nil {
-				s, err := store.Get()
-				if err == nil {
-					req = s.GetRequestFromSessions(requestID)
-				}
-			}
+		req, err := lookupRequest(requestID, curlSaved)
+		if err != nil {
+			return err
 		}
 
 		if req == nil {
-			pterm.Warning.Printf("Request not found: %s\n", requestID)
-			pterm.Info.Println("Use 'rep list' to see available request IDs")
-			return nil
+			return notFoundError("request not found: %s", requestID).withHint("Use 'rep list' to see available request IDs")
+		}
+
+		shell, err := currentShellDialect()
+		if err != nil {
+			return err
 		}
 
 		// Generate curl command
-		curlCmd := generateCurl(req, curlUseVars)
+		curlCmd := generateCurl(req, curlUseVars, shell)
+
+		if curlExec {
+			return execCurlRequest(req, curlCmd)
+		}
+
 		fmt.Println(curlCmd)
 
 		if curlUseVars {
 			fmt.Println()
-			fmt.Println("# Run first: eval \"$(rep auth --export)\"")
+			if injected := injectedVars(req, true); len(injected) > 0 {
+				fmt.Printf("%s Injected: %s\n", shell.Comment, formatInjectedVars(injected))
+			}
+			fmt.Printf("%s Run first: %s\n", shell.Comment, shell.LoadAuth)
 		}
 
 		return nil
 	},
 }
 
-func generateCurl(req *store.Request, useVars bool) string {
+func generateCurl(req *store.Request, useVars bool, shell shellDialect) string {
 	var parts []string
 
-	parts = append(parts, "curl")
+	parts = append(parts, shell.Curl)
 
 	// Method
 	if req.Method != "GET" {
@@ -121,57 +138,85 @@ func generateCurl(req *store.Request, useVars bool) string {
 	}
 
 	// URL
-	parts = append(parts, fmt.Sprintf("'%s'", req.URL))
+	target, body, _ := replayTarget(req, useVars)
+	parts = append(parts, shell.Quote(target))
+	if target != req.URL {
+		parts[len(parts)-1] = shell.Template(replayHeader{Value: target, Templated: true})
+	}
 
 	// Headers
-	skipHeaders := map[string]bool{
-		"host":              true,
-		"content-length":    true,
-		"connection":        true,
-		"accept-encoding":   true,
-		"sec-fetch-site":    true,
-		"sec-fetch-mode":    true,
-		"sec-fetch-dest":    true,
-		"sec-ch-ua":         true,
-		"sec-ch-ua-mobile":  true,
-		"sec-ch-ua-platform": true,
-	}
-
-	for key, values := range req.Headers {
-		if skipHeaders[strings.ToLower(key)] {
+	for _, h := range replayHeaders(req, useVars) {
+		h.Value = h.Name + ": " + h.Value
+		if h.Templated {
+			parts = append(parts, "-H", shell.Template(h))
 			continue
 		}
-
-		for _, value := range values {
-			headerValue := value
-
-			if useVars {
-				// Replace auth values with variables
-				headerValue = replaceWithVars(key, value)
-			}
-
-			parts = append(parts, "-H", fmt.Sprintf("'%s: %s'", key, escapeQuote(headerValue)))
-		}
+		parts = append(parts, "-H", shell.Quote(h.Value))
 	}
 
 	// Body
-	if req.Body != "" {
-		body := req.Body
-		if useVars {
-			// Could potentially replace tokens in body too
-			body = req.Body
-		}
-		parts = append(parts, "-d", fmt.Sprintf("'%s'", escapeQuote(body)))
+	if body != req.Body {
+		parts = append(parts, "-d", shell.Template(replayHeader{Value: body, Templated: true}))
+	} else if body != "" {
+		parts = append(parts, "-d", shell.Quote(body))
 	}
 
 	// Format with line continuations for readability
 	if len(parts) > 4 {
-		return formatCurlMultiline(parts)
+		return formatCurlMultiline(parts, shell.Continue)
 	}
 
 	return strings.Join(parts, " ")
 }
 
+// replaySkipHeaders are browser/transport headers dropped when replaying a request
+var replaySkipHeaders = map[string]bool{
+	"host":               true,
+	"content-length":     true,
+	"connection":         true,
+	"accept-encoding":    true,
+	"sec-fetch-site":     true,
+	"sec-fetch-mode":     true,
+	"sec-fetch-dest":     true,
+	"sec-ch-ua":          true,
+	"sec-ch-ua-mobile":   true,
+	"sec-ch-ua-platform": true,
+}
+
+// replayHeader is a single header value prepared for replay
+type replayHeader struct {
+	Name      string
+	Value     string
+	Templated bool // Value contains $VARIABLE references from replaceWithVars
+}
+
+// replayHeaders returns the headers to send when replaying req, sorted by name.
+// With useVars, auth values are replaced by shell variable references.
+func replayHeaders(req *store.Request, useVars bool) []replayHeader {
+	keys := make([]string, 0, len(req.Headers))
+	for key := range req.Headers {
+		if replaySkipHeaders[strings.ToLower(key)] {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var headers []replayHeader
+	for _, key := range keys {
+		for _, value := range req.Headers[key] {
+			h := replayHeader{Name: key, Value: value}
+			if useVars {
+				// Replace auth values with variables
+				h.Value = replaceWithVars(key, value)
+				h.Templated = h.Value != value
+			}
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
+
 func replaceWithVars(headerName, value string) string {
 	lowerName := strings.ToLower(headerName)
 
@@ -211,11 +256,438 @@ func replaceWithVars(headerName, value string) string {
 	return value
 }
 
-func escapeQuote(s string) string {
-	return strings.ReplaceAll(s, "'", "'\"'\"'")
+// minAuthValueLength is the shortest credential replaced in query strings
+// and bodies; shorter values match unrelated text
+const minAuthValueLength = 8
+
+// authEnvName matches the variable names 'rep auth --save' writes
+var authEnvName = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// authValue is a credential a request carries and the variable standing in
+// for it
+type authValue struct {
+	Name  string
+	Value string
+}
+
+// requestAuthValues lists the credentials to replace in req's query string
+// and body: the tokens 'rep auth' extracts from its headers, then the values
+// saved in the domain auth env file (a CSRF token sent only in a form, say).
+// Longest first, so a value containing another is replaced whole.
+func requestAuthValues(req *store.Request) []authValue {
+	seen := make(map[string]bool)
+	var values []authValue
+	add := func(name, value string) {
+		if len(value) < minAuthValueLength || seen[value] || !authEnvName.MatchString(name) {
+			return
+		}
+		seen[value] = true
+		values = append(values, authValue{Name: name, Value: value})
+	}
+	for _, t := range extractAuthTokens([]store.Request{*req}, "") {
+		add(t.Name, t.Value)
+	}
+	fileVars := authFileVars(req.Domain)
+	names := make([]string, 0, len(fileVars))
+	for name := range fileVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(name, fileVars[name])
+	}
+	sort.SliceStable(values, func(i, j int) bool { return len(values[i].Value) > len(values[j].Value) })
+	return values
+}
+
+// replaceValuesWithVars replaces credential values in s with $VARIABLE
+// references and returns the variables used. Text that already looks like a
+// $VARIABLE is left alone, as its references couldn't be told apart.
+func replaceValuesWithVars(s string, values []authValue) (string, []string) {
+	if varRefPattern.MatchString(s) {
+		return s, nil
+	}
+	var used []string
+	for _, v := range values {
+		if strings.Contains(s, v.Value) {
+			s = strings.ReplaceAll(s, v.Value, "$"+v.Name)
+			used = append(used, v.Name)
+		}
+	}
+	return s, used
+}
+
+// varInjection is a $VARIABLE reference --use-vars put in a request
+type varInjection struct {
+	Var   string `json:"var"`
+	Where string `json:"where"` // "header <name>", "query" or "body"
+}
+
+// replayTarget returns the URL and body to send when replaying req. With
+// useVars, credentials in the query string and body become $VARIABLE
+// references, as replaceWithVars does for headers.
+func replayTarget(req *store.Request, useVars bool) (string, string, []varInjection) {
+	if !useVars {
+		return req.URL, req.Body, nil
+	}
+	values := requestAuthValues(req)
+	var injected []varInjection
+
+	target := req.URL
+	if base, query, ok := strings.Cut(req.URL, "?"); ok {
+		replaced, used := replaceValuesWithVars(query, values)
+		target = base + "?" + replaced
+		for _, name := range used {
+			injected = append(injected, varInjection{Var: name, Where: "query"})
+		}
+	}
+	body, used := replaceValuesWithVars(req.Body, values)
+	for _, name := range used {
+		injected = append(injected, varInjection{Var: name, Where: "body"})
+	}
+	return target, body, injected
+}
+
+// injectedVars lists the $VARIABLE references --use-vars puts in req's
+// headers, query string and body
+func injectedVars(req *store.Request, useVars bool) []varInjection {
+	injected := []varInjection{}
+	if !useVars {
+		return injected
+	}
+	for _, h := range replayHeaders(req, true) {
+		if !h.Templated {
+			continue
+		}
+		for _, m := range varRefPattern.FindAllStringSubmatch(h.Value, -1) {
+			injected = append(injected, varInjection{Var: m[1], Where: "header " + h.Name})
+		}
+	}
+	_, _, more := replayTarget(req, true)
+	return append(injected, more...)
+}
+
+// formatInjectedVars renders injections as "$NAME (where), ..."
+func formatInjectedVars(injected []varInjection) string {
+	parts := make([]string, len(injected))
+	for i, v := range injected {
+		parts[i] = "$" + v.Var + " (" + v.Where + ")"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// runCurlBatch prints curl commands for every request matching the filter flags
+func runCurlBatch() error {
+	opts, err := curlFilter.options()
+	if err != nil {
+		return err
+	}
+	requests, err := filterSource(curlSaved, opts)
+	if err != nil || requests == nil {
+		return err
+	}
+	if len(requests) == 0 {
+		return softFail(emptyError("No requests match the filter"))
+	}
+
+	switch strings.ToLower(curlFormat) {
+	case "script", "sh":
+		shell, err := currentShellDialect()
+		if err != nil {
+			return err
+		}
+		if shell.Name == "cmd" {
+			shell, _ = shellDialectFor("cmd", true)
+		}
+		fmt.Print(curlBatchScript(requests, curlUseVars, shell))
+	case "makefile", "make":
+		fmt.Print(curlBatchMakefile(requests, curlUseVars))
+	case "ffuf":
+		fmt.Print(curlBatchFFUF(requests))
+	default:
+		return fmt.Errorf("unsupported format: %s (use script, makefile, ffuf)", curlFormat)
+	}
+	return nil
+}
+
+// curlBatchScript writes a bash, PowerShell (.ps1) or batch (.cmd) script
+func curlBatchScript(requests []store.Request, useVars bool, shell shellDialect) string {
+	var b strings.Builder
+	switch shell.Name {
+	case "posix":
+		b.WriteString("#!/usr/bin/env bash\n")
+	case "cmd":
+		b.WriteString("@echo off\n")
+	}
+	fmt.Fprintf(&b, "%s Generated by rep curl --filter (%d requests)\n", shell.Comment, len(requests))
+	if useVars {
+		fmt.Fprintf(&b, "%s Run first: %s\n", shell.Comment, shell.LoadAuth)
+	}
+	for i := range requests {
+		req := &requests[i]
+		fmt.Fprintf(&b, "\n%s [%s] %s %s\n", shell.Comment, req.ID, req.Method, output.SanitizeText(req.URL))
+		b.WriteString(generateCurl(req, useVars, shell))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func curlBatchMakefile(requests []store.Request, useVars bool) string {
+	// make runs recipes with /bin/sh
+	posix, _ := shellDialectFor("posix", false)
+	targets := make([]string, 0, len(requests))
+	for _, req := range requests {
+		targets = append(targets, req.ID)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by rep curl --filter (%d requests)\n", len(requests))
+	if useVars {
+		b.WriteString("# Run first: eval \"$(rep auth --export)\" (make passes the environment through)\n")
+	}
+	fmt.Fprintf(&b, ".PHONY: all %s\n\n", strings.Join(targets, " "))
+	fmt.Fprintf(&b, "all: %s\n", strings.Join(targets, " "))
+	for i := range requests {
+		req := &requests[i]
+		// Single-line recipe; make treats $ specially so it must be doubled
+		command := strings.ReplaceAll(generateCurl(req, useVars, posix), " \\\n  ", " ")
+		command = strings.ReplaceAll(command, "$", "$$")
+		fmt.Fprintf(&b, "\n# %s %s\n", req.Method, output.SanitizeText(req.URL))
+		fmt.Fprintf(&b, "%s:\n\t%s\n", req.ID, command)
+	}
+	return b.String()
+}
+
+func curlBatchFFUF(requests []store.Request) string {
+	var b strings.Builder
+	seen := make(map[string]bool)
+	for _, req := range requests {
+		if seen[req.URL] {
+			continue
+		}
+		seen[req.URL] = true
+		b.WriteString(req.URL)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// execCurlRequest sends the request (with --use-vars substitution resolved from
+// the domain auth env) and streams the response to stdout.
+func execCurlRequest(req *store.Request, curlCmd string) error {
+	// The recorded copy keeps the placeholders; only the wire sees the mapping
+	sendReq, missing := resolveReplayRequest(req, curlUseVars)
+	if err := warnMissingVars(req, missing); err != nil {
+		return err
+	}
+
+	injected := injectedVars(req, curlUseVars)
+	jsonMode := getOutputMode() == "json"
+	opts := replay.Options{Insecure: curlInsecure}
+	if !jsonMode {
+		fmt.Fprintln(os.Stderr, "# "+strings.ReplaceAll(curlCmd, "\n", "\n# "))
+		if len(injected) > 0 {
+			fmt.Fprintln(os.Stderr, "# Injected: "+formatInjectedVars(injected))
+		}
+		opts.BodyWriter = os.Stdout
+		opts.OnHeaders = func(status int, proto string, headers store.HeaderMap) {
+			// Status and headers go to stderr so stdout stays pipeable (jq, grep)
+			fmt.Fprintf(os.Stderr, "%s %d %s\n", proto, status, http.StatusText(status))
+			for _, key := range sortedHeaderKeys(headers) {
+				for _, v := range headers[key] {
+					fmt.Fprintf(os.Stderr, "%s: %s\n", key, v)
+				}
+			}
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+
+	result, err := replay.Send(context.Background(), withMappedValues(sendReq), opts)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+
+	recordedID := ""
+	if curlRecord {
+		if recordedID, err = recordReplay(sendReq, req.ID, result); err != nil {
+			return err
+		}
+	}
+
+	if jsonMode {
+		payload := map[string]interface{}{
+			"id":          req.ID,
+			"status":      result.Status,
+			"proto":       result.Proto,
+			"headers":     result.Headers,
+			"body":        result.Body,
+			"size":        result.Size,
+			"duration_ms": result.DurationMs(),
+		}
+		if result.Truncated {
+			payload["truncated"] = true
+		}
+		if recordedID != "" {
+			payload["recorded_id"] = recordedID
+		}
+		if len(injected) > 0 {
+			payload["injected"] = injected
+		}
+		out, _ := sonic.MarshalIndent(payload, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Println()
+	if result.Truncated {
+		pterm.Warning.Printf("Body truncated in memory at %s\n", output.FormatBodySize(replay.MaxBodySize))
+	}
+	pterm.Info.Printf("%d in %dms (%s)\n", result.Status, result.DurationMs(), output.FormatBodySize(int(result.Size)))
+	if recordedID != "" {
+		pterm.Success.Printf("Recorded as %s (compare: rep body %s vs rep body %s)\n", recordedID, req.ID, recordedID)
+	}
+	return nil
+}
+
+// resolveReplayRequest builds the request to send. With useVars, auth values
+// in headers, the query string and the body are resolved from the
+// environment and the domain auth env file; variables that can't be resolved
+// keep the captured value and are returned as missing.
+func resolveReplayRequest(req *store.Request, useVars bool) (*store.Request, []string) {
+	vars := map[string]string{}
+	if useVars {
+		vars = replayVars(req.Domain)
+	}
+
+	missingSet := make(map[string]bool)
+	// resolve expands a templated value, or returns captured if a variable
+	// isn't set
+	resolve := func(value, captured string) string {
+		resolved := true
+		expanded := varRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+			name := ref[1:]
+			if v, ok := vars[name]; ok {
+				return v
+			}
+			missingSet[name] = true
+			resolved = false
+			return ref
+		})
+		if !resolved {
+			return captured
+		}
+		return expanded
+	}
+
+	headers := make(store.HeaderMap)
+	for _, h := range replayHeaders(req, useVars) {
+		value := h.Value
+		if h.Templated {
+			value = resolve(value, store.HeaderFirst(req.Headers, h.Name))
+		}
+		headers[h.Name] = append(headers[h.Name], value)
+	}
+
+	sendReq := *req
+	sendReq.Headers = headers
+	sendReq.Response = nil
+	if target, body, _ := replayTarget(req, useVars); target != req.URL || body != req.Body {
+		sendReq.URL = resolve(target, req.URL)
+		sendReq.Body = resolve(body, req.Body)
+	}
+
+	missing := make([]string, 0, len(missingSet))
+	for name := range missingSet {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	return &sendReq, missing
+}
+
+// resolveSendRequest is resolveReplayRequest for a request rep sends itself:
+// sanitizer placeholders are also replaced with the values of the --mapping
+// file, so real credentials only exist on the wire, never in output.
+func resolveSendRequest(req *store.Request, useVars bool) (*store.Request, []string) {
+	sendReq, missing := resolveReplayRequest(req, useVars)
+	return withMappedValues(sendReq), missing
+}
+
+// warnMissingVars reports the --use-vars variables that kept the captured
+// value; with --strict the request isn't sent
+func warnMissingVars(req *store.Request, missing []string) error {
+	for _, name := range missing {
+		e := warningError("$%s not set, using captured value (run 'rep auth --save -d %s')", name, req.Domain)
+		if err := softFail(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayVars returns variables from the process environment overlaid with the
+// domain auth env (falling back to the default auth.env), like sourcing it.
+func replayVars(domain string) map[string]string {
+	vars := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			vars[name] = value
+		}
+	}
+	for name, value := range authFileVars(domain) {
+		vars[name] = value
+	}
+	return vars
+}
+
+// authFileVars reads the domain auth env, falling back to the default
+// auth.env; nil when neither exists
+func authFileVars(domain string) map[string]string {
+	for _, d := range []string{domain, ""} {
+		envPath, err := authEnvPath(d)
+		if err != nil || !fileExists(envPath) {
+			continue
+		}
+		if fileVars, err := loadAuthEnv(envPath); err == nil {
+			return fileVars
+		}
+	}
+	return nil
+}
+
+// recordReplay appends a sent request and its response to the live capture
+// so 'rep body' and friends can read it; returns its ID
+func recordReplay(sent *store.Request, originalID string, result *replay.Result) (string, error) {
+	recorded := *sent
+	recorded.ID = replayRequestID(result.Timestamp)
+	recorded.OriginalID = originalID
+	recorded.Response = result.Response()
+	recorded.Timestamp = result.Timestamp
+	// Replace the original capture's timing with this round trip's
+	recorded.DurationMs = float64(result.Duration.Microseconds()) / 1000
+	recorded.Protocol = strings.ToLower(result.Proto)
+	recorded.RequestBytes, recorded.ResponseBytes, recorded.RemoteIP = 0, 0, ""
+	recorded.TLS = nil
+	if err := appendLiveRequest(recorded); err != nil {
+		return "", fmt.Errorf("failed to record response: %w", err)
+	}
+	return recorded.ID, nil
+}
+
+// replayRequestID returns an ID for a recorded replay response
+func replayRequestID(timestamp int64) string {
+	return fmt.Sprintf("r_%x", timestamp)
+}
+
+func sortedHeaderKeys(headers store.HeaderMap) []string {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
-func formatCurlMultiline(parts []string) string {
+func formatCurlMultiline(parts []string, continuation string) string {
 	var lines []string
 	lines = append(lines, parts[0]) // curl
 
@@ -230,10 +702,10 @@ func formatCurlMultiline(parts []string) string {
 		}
 	}
 
-	// Add backslashes
+	// Add line continuations (backslash, backtick or caret)
 	result := lines[0]
 	for i := 1; i < len(lines); i++ {
-		result += " \\\n" + lines[i]
+		result += continuation + lines[i]
 	}
 
 	return result
@@ -243,4 +715,10 @@ func init() {
 	rootCmd.AddCommand(curlCmd)
 	curlCmd.Flags().BoolVar(&curlUseVars, "use-vars", false, "Replace auth tokens with shell variables")
 	curlCmd.Flags().StringVar(&curlSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	curlCmd.Flags().BoolVar(&curlExec, "exec", false, "Execute the request and stream the response")
+	curlCmd.Flags().BoolVar(&curlRecord, "record", false, "With --exec: append the response to live.json as a new request")
+	curlCmd.Flags().BoolVarP(&curlInsecure, "insecure", "k", false, "With --exec: skip TLS certificate verification")
+	curlCmd.Flags().BoolVar(&curlFilterMode, "filter", false, "Batch mode: emit commands for all requests matching the filter flags")
+	curlCmd.Flags().StringVar(&curlFormat, "format", "script", "Batch output format: script, makefile, ffuf")
+	curlFilter.register(curlCmd)
 }
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteFilterMode bool
+	deleteFilter     requestFilterFlags
+	deleteSaved      string
+	deleteDryRun     bool
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete <request-id...> | --filter [filter flags]",
+	Short: "Remove individual requests from the live session or a saved session",
+	Long: `Remove requests from live.json or, with --saved, from a saved session.
+With several browsers capturing, IDs are looked up in every live session
+(or only the --session one).
+
+Prune irrelevant or sensitive entries before saving, sharing or archiving
+a capture. Requests are matched by exact ID.
+
+Filter mode (--filter) takes the same filter flags as 'rep list' and
+deletes every matching request. Run with --dry-run first to see what
+would go.
+
+Note: while auto-export is running, the rep+ extension rewrites live.json
+from its own buffer, which can bring deleted live requests back. Save the
+session first ('rep save') and delete from the saved copy for a lasting edit.
+
+Examples:
+  rep delete demo_0031                         Remove one live request
+  rep delete a1b2 c3d4 --saved latest          Remove from the latest saved session
+  rep delete --filter -d cdn.example.com --primary=false
+  rep delete --filter --resp-header set-cookie --dry-run
+  rep delete --filter --saved latest -m OPTIONS`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if deleteFilterMode {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids := make(map[string]bool)
+		for _, id := range args {
+			ids[id] = true
+		}
+
+		if deleteFilterMode {
+			opts, err := deleteFilter.options()
+			if err != nil {
+				return err
+			}
+			requests, err := filterSource(deleteSaved, opts)
+			if err != nil {
+				return err
+			}
+			for _, req := range requests {
+				ids[req.ID] = true
+			}
+			if len(ids) == 0 {
+				return softFail(emptyError("No requests match the filter"))
+			}
+		}
+
+		var (
+			source  string
+			removed []string
+			remain  int
+		)
+
+		if deleteSaved != "" {
+			s, err := store.Get()
+			if err != nil {
+				return fmt.Errorf("failed to load store: %w", err)
+			}
+			session := resolveSession(s, deleteSaved)
+			if session == nil {
+				return notFoundError("session not found: %s", deleteSaved).withHint("Use 'rep sessions' to list available sessions")
+			}
+			source = "session " + session.ID
+			removed = matchingIDs(session.Requests, ids)
+			remain = len(session.Requests) - len(removed)
+			if !deleteDryRun && len(removed) > 0 {
+				s.RemoveSessionRequests(session.ID, ids)
+				if err := s.Save(); err != nil {
+					return fmt.Errorf("failed to save store: %w", err)
+				}
+			}
+		} else {
+			livePaths, err := store.GetLiveFilePaths()
+			if err != nil {
+				return fmt.Errorf("failed to get live path: %w", err)
+			}
+			source = "live.json"
+			if len(livePaths) > 1 {
+				source = fmt.Sprintf("%d live sessions", len(livePaths))
+			}
+			for _, livePath := range livePaths {
+				if _, err := os.Stat(livePath); err != nil {
+					if len(livePaths) == 1 {
+						return softFail(liveReadError(err))
+					}
+					continue
+				}
+				local := liveLocalIDs(livePath, ids)
+				fileIDs := make(map[string]bool, len(local))
+				for id := range local {
+					fileIDs[id] = true
+				}
+				// Matching and removal happen under the file's lock
+				err := updateLiveExport(livePath, func(export *store.Export) (bool, error) {
+					matched := matchingIDs(export.Requests, fileIDs)
+					for _, id := range matched {
+						removed = append(removed, local[id])
+					}
+					remain += len(export.Requests) - len(matched)
+					if deleteDryRun || len(matched) == 0 {
+						return false, nil
+					}
+					export.Requests, _ = store.RemoveRequests(export.Requests, fileIDs)
+					return true, nil
+				})
+				if err != nil {
+					return fmt.Errorf("failed to update %s: %w", livePath, err)
+				}
+			}
+		}
+
+		var missing []string
+		found := make(map[string]bool, len(removed))
+		for _, id := range removed {
+			found[id] = true
+		}
+		for _, id := range args {
+			if !found[id] {
+				missing = append(missing, id)
+			}
+		}
+		// With --strict, missing IDs fail the command once the rest is done
+		var missingErr error
+		if strictMode && len(missing) > 0 {
+			missingErr = notFoundError("%d request(s) not found in %s", len(missing), source)
+		}
+
+		if getOutputMode() == "json" {
+			result := map[string]interface{}{
+				"source":    source,
+				"deleted":   removed,
+				"count":     len(removed),
+				"remaining": remain,
+				"not_found": missing,
+				"dry_run":   deleteDryRun,
+			}
+			out, _ := sonic.MarshalIndent(result, "", "  ")
+			fmt.Println(string(out))
+			return missingErr
+		}
+
+		for _, id := range missing {
+			pterm.Warning.Printf("Request not found in %s: %s\n", source, id)
+		}
+		if len(removed) == 0 {
+			pterm.Info.Printf("Nothing deleted from %s\n", source)
+			return missingErr
+		}
+		if deleteDryRun {
+			for _, id := range removed {
+				fmt.Println(id)
+			}
+			pterm.Info.Printf("Would delete %d requests from %s (%d remain)\n", len(removed), source, remain)
+			return missingErr
+		}
+		pterm.Success.Printf("Deleted %d requests from %s (%d remain)\n", len(removed), source, remain)
+		return missingErr
+	},
+}
+
+// matchingIDs returns the IDs of requests in ids, in capture order
+func matchingIDs(requests []store.Request, ids map[string]bool) []string {
+	var matched []string
+	for _, req := range requests {
+		if ids[req.ID] {
+			matched = append(matched, req.ID)
+		}
+	}
+	return matched
+}
+
+// liveLocalIDs maps the IDs a live file uses to the IDs shown for them.
+// Namespaced sessions' IDs carry a "<session>:" prefix
+// (store.MergeLiveRequests) that the file doesn't have.
+func liveLocalIDs(livePath string, ids map[string]bool) map[string]string {
+	local := make(map[string]string, len(ids))
+	source := store.LiveSourceName(livePath)
+	for id := range ids {
+		if source == "" {
+			local[id] = id
+		} else if rest, ok := strings.CutPrefix(id, source+":"); ok {
+			local[rest] = id
+		}
+	}
+	return local
+}
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+	deleteCmd.Flags().BoolVar(&deleteFilterMode, "filter", false, "Delete every request matching the filter flags")
+	deleteFilter.register(deleteCmd)
+	deleteCmd.Flags().StringVar(&deleteSaved, "saved", "", "Delete from saved session (ID, prefix, or 'latest')")
+	deleteCmd.Flags().BoolVar(&deleteDryRun, "dry-run", false, "Show what would be deleted without writing")
+}
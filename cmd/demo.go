@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/demo"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	demoGenerate bool
+	demoOut      string
+	demoSeed     int64
+	demoExtra    int
+	demoForce    bool
+	demoBaseTime string
+)
+
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Generate a synthetic dataset to try every command offline",
+	Long: `Generate a realistic synthetic capture so rep can be explored without
+the rep+ extension connected.
+
+The dataset covers a small web app on *.acme.test: HTML pages, JS bundles
+(with endpoints, a source map reference and an API key), a login + OAuth
+flow, REST and GraphQL calls with auth headers, mutations, 4xx/5xx errors
+(stack trace, SQL error, rate limit) and analytics noise.
+
+Without --generate, prints a walkthrough of commands to run on the dataset.
+
+--generate writes live.json and adds a saved "demo" session plus primary
+domains to store.json. An existing non-empty live.json is kept unless
+--force is given.
+
+--out <dir> writes <dir>/rep-cli/live.json and store.json instead of the
+real data directory. Point rep at it with XDG_DATA_HOME=<dir>. Useful as a
+fixture for scripts and integration tests. Timestamps start an hour ago,
+unless --seed or --base-time is given: then they start at --base-time
+(default 2025-01-06T09:00:00Z), and the same seed and base time always
+produce the same files, session ID included.
+
+Examples:
+  rep demo                              Show the walkthrough
+  rep demo --generate                   Generate into the data directory
+  rep demo --generate --force           Replace the current live session
+  rep demo --generate --out /tmp/fx     Standalone fixture directory
+  rep demo --generate --out /tmp/fx --seed 7   Reproducible fixture
+  rep demo --generate --extra 500       Add 500 random API calls`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !demoGenerate {
+			printDemoWalkthrough()
+			return nil
+		}
+
+		baseTime, err := demoBaseTimeFor(cmd)
+		if err != nil {
+			return err
+		}
+		export := demo.Generate(demo.Options{Seed: demoSeed, Extra: demoExtra, BaseTime: baseTime})
+
+		var livePath, storePath string
+		if demoOut != "" {
+			dir := filepath.Join(demoOut, "rep-cli")
+			livePath = filepath.Join(dir, store.LiveFileName)
+			storePath = filepath.Join(dir, store.StoreFileName)
+		} else {
+			if livePath, err = store.GetLiveFilePath(); err != nil {
+				return err
+			}
+		}
+
+		if !demoForce {
+			if existing, err := loadLiveExport(livePath); err == nil && len(existing.Requests) > 0 {
+				pterm.Warning.Printf("Live session already has %d requests: %s\n", len(existing.Requests), livePath)
+				pterm.Info.Println("Use --force to replace it, or --out <dir> to generate elsewhere")
+				return nil
+			}
+		}
+
+		if err := writeDemoFile(livePath, export); err != nil {
+			return fmt.Errorf("failed to write live.json: %w", err)
+		}
+
+		// Saved session + primary domains (noise stays un-ignored so 'rep summary'
+		// has something to suggest)
+		sessionID := store.GenerateSessionID("demo")
+		if !baseTime.IsZero() {
+			sessionID = baseTime.UTC().Format("20060102-150405") + "-demo"
+		}
+		if demoOut != "" {
+			s := store.NewStore()
+			session := s.AddSession(sessionID, "demo", export.Requests)
+			if !baseTime.IsZero() {
+				session.Timestamp = baseTime.UnixMilli()
+			}
+			s.SetPrimary(demo.PrimaryDomains...)
+			if err := writeDemoFile(storePath, s); err != nil {
+				return fmt.Errorf("failed to write store.json: %w", err)
+			}
+		} else {
+			s, err := store.Get()
+			if err != nil {
+				return fmt.Errorf("failed to load store: %w", err)
+			}
+			s.AddSession(sessionID, "demo", export.Requests)
+			s.SetPrimary(demo.PrimaryDomains...)
+			if err := s.Save(); err != nil {
+				return fmt.Errorf("failed to save store: %w", err)
+			}
+			storePath, _ = store.GetStoreFilePath()
+		}
+
+		if getOutputMode() == "json" {
+			result := map[string]interface{}{
+				"live_path":       livePath,
+				"store_path":      storePath,
+				"session_id":      sessionID,
+				"requests":        len(export.Requests),
+				"primary_domains": demo.PrimaryDomains,
+				"seed":            demoSeed,
+			}
+			out, _ := sonic.MarshalIndent(result, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		pterm.Success.Printf("Generated %d demo requests\n", len(export.Requests))
+		pterm.Info.Printf("Live:    %s\n", livePath)
+		pterm.Info.Printf("Store:   %s (session %s)\n", storePath, sessionID)
+		pterm.Info.Printf("Primary: %v\n", demo.PrimaryDomains)
+		if demoOut != "" {
+			pterm.Info.Println("\nTo use this dataset:")
+			fmt.Printf("  export XDG_DATA_HOME=%s\n", demoOut)
+		}
+		fmt.Println()
+		fmt.Println("Next: rep demo   (walkthrough)   or   rep summary")
+		return nil
+	},
+}
+
+// demoBaseTimeFor returns the dataset's first timestamp: --base-time, the
+// fixed epoch when --seed is given, or zero (an hour ago)
+func demoBaseTimeFor(cmd *cobra.Command) (time.Time, error) {
+	if demoBaseTime != "" {
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if t, err := time.Parse(layout, demoBaseTime); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, usageError("invalid --base-time: %s (use RFC 3339 or YYYY-MM-DD)", demoBaseTime)
+	}
+	if cmd.Flags().Changed("seed") {
+		return demo.FixedBaseTime, nil
+	}
+	return time.Time{}, nil
+}
+
+// writeDemoFile marshals v as indented JSON to path, creating parent dirs
+func writeDemoFile(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := sonic.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return store.WriteFileAtomic(path, data, 0644)
+}
+
+func printDemoWalkthrough() {
+	pterm.DefaultSection.Println("rep demo walkthrough")
+	fmt.Println(`Generate the dataset first (or use --out for a throwaway directory):
+  rep demo --generate
+
+1. Landscape and noise
+  rep summary                          Domains, counts, ignore suggestions
+  rep ignore www.google-analytics.com stats.g.doubleclick.net o123.ingest.sentry.io
+  rep mute api.acme.test/health        Drop the health check
+
+2. Browse target traffic
+  rep list                             Primary domains (*.acme.test)
+  rep list --interesting               Errors + mutations
+  rep list --errors -o meta            4xx/5xx, headers only
+  rep body demo_0001                   Full response body
+
+3. Auth and replay
+  rep auth -d api.acme.test            Extracted bearer token, cookie, CSRF
+  rep curl demo_0014 --use-vars        Curl with $VARIABLES instead of secrets
+  rep code demo_0014 --lang python     Same request as a Python script
+  rep curl --filter --mutations --format script
+
+4. Flows and JavaScript
+  rep chain demo_0007                  Login -> OAuth redirect -> callback
+  rep js                               JS bundles (endpoints, secrets)
+
+5. Sessions
+  rep sessions                         The saved "demo" session
+  rep list --saved latest
+
+Everything uses *.acme.test, which never resolves: replaying (--exec) will fail by design.`)
+}
+
+func init() {
+	rootCmd.AddCommand(demoCmd)
+	demoCmd.Flags().BoolVar(&demoGenerate, "generate", false, "Generate the synthetic dataset")
+	demoCmd.Flags().StringVar(&demoOut, "out", "", "Write to <dir>/rep-cli/ instead of the data directory")
+	demoCmd.Flags().Int64Var(&demoSeed, "seed", 1, "Random seed for reproducible output (also fixes the base time)")
+	demoCmd.Flags().StringVar(&demoBaseTime, "base-time", "", "First request timestamp, RFC 3339 or YYYY-MM-DD (default 2025-01-06T09:00:00Z with --seed, else an hour ago)")
+	demoCmd.Flags().IntVar(&demoExtra, "extra", 0, "Number of additional random API calls")
+	demoCmd.Flags().BoolVar(&demoForce, "force", false, "Overwrite a non-empty live session")
+}
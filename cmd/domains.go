@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"encoding/csv"
 	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/bytedance/sonic"
 	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
 	"github.com/repplus/rep-cli/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -29,7 +35,9 @@ Use --saved to view domains from archived sessions.
   rep domains --all        Show all domains including ignored
   rep domains --primary    Show only primary domains
   rep domains --ignored    Show only ignored domains
-  rep domains --saved latest   Show domains from most recent saved session`,
+  rep domains --saved latest   Show domains from most recent saved session
+  rep domains -o csv > domains.csv   Spreadsheet-friendly export
+  rep domains -o ndjson | jq -c 'select(.RequestCount > 10)'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var tempStore *store.Store
 
@@ -48,9 +56,7 @@ Use --saved to view domains from archived sessions.
 			}
 
 			if session == nil {
-				pterm.Warning.Printf("Session not found: %s\n", domainsSaved)
-				pterm.Info.Println("Use 'rep sessions' to list available sessions")
-				return nil
+				return notFoundError("session not found: %s", domainsSaved).withHint("Use 'rep sessions' to list available sessions")
 			}
 
 			tempStore = store.NewTempStore(session.Requests)
@@ -58,22 +64,20 @@ Use --saved to view domains from archived sessions.
 			tempStore.IgnoredDomains = s.IgnoredDomains
 		} else {
 			// Default: Load from live.json
-			livePath, err := store.GetLiveFilePath()
+			livePaths, err := store.GetLiveFilePaths()
 			if err != nil {
 				return fmt.Errorf("failed to get live path: %w", err)
 			}
-			export, err := loadLiveExport(livePath)
+			// Bodies are never needed here, so skip them while parsing
+			requests, err := store.LoadLiveWithoutBodiesAll(livePaths)
 			if err != nil {
-				pterm.Warning.Printf("Could not read live.json: %v\n", err)
-				pterm.Info.Println("Enable auto-export in rep+ extension first")
-				return nil
+				return softFail(liveReadError(err))
 			}
-			if len(export.Requests) == 0 {
-				pterm.Info.Println("No requests captured yet (live session empty)")
-				return nil
+			if len(requests) == 0 {
+				return softFail(emptyLiveError())
 			}
 
-			tempStore = store.NewTempStore(export.Requests)
+			tempStore = store.NewTempStore(requests)
 			// Load ignore/primary lists from store
 			s, err := store.Get()
 			if err == nil {
@@ -84,19 +88,7 @@ Use --saved to view domains from archived sessions.
 
 		domains := tempStore.GetDomains()
 
-		// Filter based on flags
-		var filtered []store.DomainInfo
-		for _, d := range domains {
-			if domainsAll {
-				filtered = append(filtered, d)
-			} else if domainsPrimary && d.IsPrimary {
-				filtered = append(filtered, d)
-			} else if domainsIgnored && d.IsIgnored {
-				filtered = append(filtered, d)
-			} else if !domainsPrimary && !domainsIgnored && !d.IsIgnored {
-				filtered = append(filtered, d)
-			}
-		}
+		filtered := filterDomains(domains, domainsAll, domainsPrimary, domainsIgnored)
 
 		// Apply limit
 		totalCount := len(filtered)
@@ -104,10 +96,34 @@ Use --saved to view domains from archived sessions.
 			filtered = filtered[:domainsLimit]
 		}
 
-		if getOutputMode() == "json" {
+		switch getOutputMode() {
+		case "json":
 			out, _ := sonic.MarshalIndent(filtered, "", "  ")
 			fmt.Println(string(out))
-		} else {
+		case "ndjson":
+			nw := output.NewNDJSONWriter(os.Stdout)
+			for _, d := range filtered {
+				if err := nw.Write(d); err != nil {
+					return err
+				}
+			}
+			return nw.Flush()
+		case "csv":
+			cw := csv.NewWriter(os.Stdout)
+			cw.Write([]string{"domain", "requests", "endpoints", "methods", "primary", "ignored"})
+			for _, d := range filtered {
+				cw.Write([]string{
+					d.Domain,
+					strconv.Itoa(d.RequestCount),
+					strconv.Itoa(len(d.Endpoints)),
+					formatMethodCounts(d.Methods, ";"),
+					strconv.FormatBool(d.IsPrimary),
+					strconv.FormatBool(d.IsIgnored),
+				})
+			}
+			cw.Flush()
+			return cw.Error()
+		default:
 			printDomains(filtered, totalCount, domainsLimit)
 		}
 
@@ -115,6 +131,24 @@ Use --saved to view domains from archived sessions.
 	},
 }
 
+// filterDomains keeps all domains, only primary or ignored ones, or by
+// default every domain that isn't ignored
+func filterDomains(domains []store.DomainInfo, all, primary, ignored bool) []store.DomainInfo {
+	var filtered []store.DomainInfo
+	for _, d := range domains {
+		if all {
+			filtered = append(filtered, d)
+		} else if primary && d.IsPrimary {
+			filtered = append(filtered, d)
+		} else if ignored && d.IsIgnored {
+			filtered = append(filtered, d)
+		} else if !primary && !ignored && !d.IsIgnored {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
 func printDomains(domains []store.DomainInfo, totalCount, limit int) {
 	if len(domains) == 0 {
 		pterm.Info.Println("No domains match the filter")
@@ -125,13 +159,7 @@ func printDomains(domains []store.DomainInfo, totalCount, limit int) {
 	tableData := pterm.TableData{{"Domain", "Requests", "Endpoints", "Methods", "Status"}}
 
 	for _, d := range domains {
-		methodStr := ""
-		for m, count := range d.Methods {
-			if methodStr != "" {
-				methodStr += ", "
-			}
-			methodStr += fmt.Sprintf("%s:%d", m, count)
-		}
+		methodStr := formatMethodCounts(d.Methods, ", ")
 
 		status := ""
 		if d.IsPrimary {
@@ -159,6 +187,20 @@ func printDomains(domains []store.DomainInfo, totalCount, limit int) {
 	}
 }
 
+// formatMethodCounts renders method counts as "GET:3<sep>POST:1", sorted by method
+func formatMethodCounts(methods map[string]int, sep string) string {
+	names := make([]string, 0, len(methods))
+	for m := range methods {
+		names = append(names, m)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, m := range names {
+		parts[i] = fmt.Sprintf("%s:%d", m, methods[m])
+	}
+	return strings.Join(parts, sep)
+}
+
 func init() {
 	rootCmd.AddCommand(domainsCmd)
 	domainsCmd.Flags().BoolVar(&domainsPrimary, "primary", false, "Show only primary domains")
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+// JSONSchemaVersion is bumped whenever a published output schema changes
+// incompatibly (a field removed, renamed or retyped). Added fields don't
+// bump it.
+const JSONSchemaVersion = "1"
+
+// envelopeOutput is the --envelope flag
+var envelopeOutput bool
+
+// jsonWarnings collects the warnings of the running command for the envelope
+var jsonWarnings []string
+
+// Envelope wraps the JSON output of a command with --envelope
+type Envelope struct {
+	SchemaVersion string      `json:"schema_version"`
+	Command       string      `json:"command"` // Schema name, see 'rep schema'
+	Data          interface{} `json:"data"`
+	Warnings      []string    `json:"warnings"`
+	NextSteps     []string    `json:"next_steps"`
+}
+
+// jsonSchemaEntry is one published output schema
+type jsonSchemaEntry struct {
+	Description string
+	Data        reflect.Type
+}
+
+// jsonSchemas are the outputs published by 'rep schema', by envelope command
+var jsonSchemas = map[string]jsonSchemaEntry{
+	"list":          {"rep list -o json: matching requests", reflect.TypeOf([]output.RequestOutput{})},
+	"list/unique":   {"rep list --unique -o json: one row per method+URL (or endpoint)", reflect.TypeOf([]listUniqueOutput{})},
+	"list/group":    {"rep list --group-by <key> -o json: requests grouped by key", reflect.TypeOf([]listGroupOutput{})},
+	"list/top":      {"rep list --top N -o json: requests ranked by interest", reflect.TypeOf([]listTopOutput{})},
+	"summary":       {"rep summary -o json: traffic overview", reflect.TypeOf(Summary{})},
+	"recon":         {"rep recon <target> -o json: first/third-party breakdown and noise", reflect.TypeOf(ReconOutput{})},
+	"js":            {"rep js -o json: captured scripts by origin", reflect.TypeOf(JSOutput{})},
+	"auth":          {"rep auth -o json: extracted credentials", reflect.TypeOf([]AuthToken{})},
+	"auth/rotation": {"rep auth --rotation -o json: how session credentials changed", reflect.TypeOf([]CredentialRotation{})},
+	"auth/where":    {"rep auth --where -o json: where each credential was sent", reflect.TypeOf([]TokenUsage{})},
+	"coverage":      {"rep coverage --spec <file> -o json: spec operations against captured traffic", reflect.TypeOf(CoverageReport{})},
+	"hidden":        {"rep hidden -d <domain> -o json: robots.txt and sitemap paths against the capture", reflect.TypeOf(HiddenReport{})},
+	"cache":         {"rep cache -o json: caching headers and cache poisoning / deception leads per endpoint", reflect.TypeOf([]CacheEndpoint{})},
+	"cluster":       {"rep cluster -o json: responses grouped into near-identical clusters per endpoint", reflect.TypeOf([]EndpointClusters{})},
+	"methods":       {"rep methods -o json: status and Allow per HTTP method, unexpected ones flagged", reflect.TypeOf(MethodsReport{})},
+	"tls":           {"rep tls -o json: HTTP versions, TLS sessions and certificates per host", reflect.TypeOf(TLSReport{})},
+	"versions":      {"rep versions -o json: endpoints compared across API versions", reflect.TypeOf(VersionSurface{})},
+	"thirdparty":    {"rep thirdparty -o json: third-party vendors ranked by script and data exposure", reflect.TypeOf(ThirdPartyReport{})},
+	"vhosts":        {"rep vhosts -o json: hostnames grouped by the IP serving them", reflect.TypeOf(VHostInventory{})},
+	"schema-infer":  {"rep schema-infer <endpoint> -o json: schemas inferred from observed bodies", reflect.TypeOf([]InferredEndpoint{})},
+	"agent":         {"rep agent <target>: consolidated briefing with next actions", reflect.TypeOf(AgentBriefing{})},
+}
+
+// envelopeEnabled reports whether JSON output is wrapped, by --envelope or
+// $REP_ENVELOPE
+func envelopeEnabled() bool {
+	if envelopeOutput {
+		return true
+	}
+	switch strings.ToLower(os.Getenv("REP_ENVELOPE")) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// jsonWarn records a warning for the envelope of the running command
+func jsonWarn(format string, args ...interface{}) {
+	jsonWarnings = append(jsonWarnings, fmt.Sprintf(format, args...))
+}
+
+// printJSON prints data as indented JSON, wrapped in an Envelope naming
+// command when enabled
+func printJSON(command string, data interface{}, nextSteps ...string) {
+	var v interface{} = data
+	if envelopeEnabled() {
+		warnings := jsonWarnings
+		if warnings == nil {
+			warnings = []string{}
+		}
+		if nextSteps == nil {
+			nextSteps = []string{}
+		}
+		v = Envelope{
+			SchemaVersion: JSONSchemaVersion,
+			Command:       command,
+			Data:          data,
+			Warnings:      warnings,
+			NextSteps:     nextSteps,
+		}
+	}
+	out, _ := sonic.MarshalIndent(v, "", "  ")
+	fmt.Println(string(out))
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [command]",
+	Short: "Print the JSON Schema of a command's JSON output",
+	Long: `Print the JSON Schema (draft 2020-12) of a command's -o json output,
+wrapped in the envelope that --envelope (or $REP_ENVELOPE=1) adds:
+
+  {
+    "schema_version": "1",     Bumped on incompatible changes only
+    "command": "list",         Name of the schema describing data
+    "data": ...,               The command's usual JSON output
+    "warnings": [],            Problems that didn't stop the command
+    "next_steps": []           Suggested follow-up commands
+  }
+
+Schemas are derived from the types the commands encode, so they match
+the output of this build. Without a command, lists the published schemas.
+
+Examples:
+  rep schema
+  rep schema list > list.schema.json
+  rep --envelope list --api -o json | jq '.data[].url'
+  REP_ENVELOPE=1 rep summary -o json`,
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var names []string
+		for name, entry := range jsonSchemas {
+			names = append(names, name+"\t"+entry.Description)
+		}
+		sort.Strings(names)
+		return names, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names := make([]string, 0, len(jsonSchemas))
+		for name := range jsonSchemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if len(args) == 0 {
+			if getOutputMode() == "json" {
+				list := make([]map[string]string, len(names))
+				for i, name := range names {
+					list[i] = map[string]string{"command": name, "description": jsonSchemas[name].Description}
+				}
+				out, _ := sonic.MarshalIndent(map[string]interface{}{
+					"schema_version": JSONSchemaVersion,
+					"schemas":        list,
+				}, "", "  ")
+				fmt.Println(string(out))
+				return nil
+			}
+			pterm.DefaultSection.Printf("Output schemas (version %s)\n", JSONSchemaVersion)
+			for _, name := range names {
+				fmt.Printf("  %-12s %s\n", name, jsonSchemas[name].Description)
+			}
+			fmt.Println("\nPrint one with: rep schema <command>")
+			return nil
+		}
+
+		name := strings.TrimSpace(args[0])
+		entry, ok := jsonSchemas[name]
+		if !ok {
+			return notFoundError("no schema for %q (available: %s)", name, strings.Join(names, ", "))
+		}
+		doc := envelopeSchema(name, entry)
+		out, _ := sonic.MarshalIndent(doc, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+// envelopeSchema is the published schema of one command's envelope
+func envelopeSchema(name string, entry jsonSchemaEntry) schema.Schema {
+	stringList := schema.Schema{"type": "array", "items": schema.Schema{"type": "string"}}
+	return schema.Schema{
+		"$schema":     schema.Draft,
+		"title":       "rep " + name,
+		"description": entry.Description,
+		"type":        "object",
+		"properties": schema.Schema{
+			"schema_version": schema.Schema{"const": JSONSchemaVersion},
+			"command":        schema.Schema{"const": name},
+			"data":           schema.For(entry.Data),
+			"warnings":       stringList,
+			"next_steps":     stringList,
+		},
+		"required": []string{"schema_version", "command", "data", "warnings", "next_steps"},
+	}
+}
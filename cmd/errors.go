@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/triage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	errorsFilter requestFilterFlags
+	errorsSaved  string
+	errorsAll    bool
+	errorsLimit  int
+)
+
+// ErrorFinding is an error response and what it reveals
+type ErrorFinding struct {
+	ID     string `json:"id"`
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+	triage.ErrorInfo
+}
+
+var errorsCmd = &cobra.Command{
+	Use:   "errors",
+	Short: "Find revealing error responses",
+	Long: `Read 4xx/5xx response bodies for what they give away: stack traces,
+SQL errors, framework error and debug pages, and server file paths. Each
+error is fingerprinted (Django, Flask, Rails, Spring, Laravel, PHP, ASP.NET,
+Node.js, Go, and PostgreSQL/MySQL/MSSQL/Oracle/SQLite) from its body and
+headers, and the most revealing responses are listed first.
+
+Errors with a body that reveals nothing beyond a message are hidden unless
+--all is given. Takes the same filter flags as 'rep list' (primary domains
+only by default; --status/--status-range narrow the 4xx/5xx default).
+
+Examples:
+  rep errors                        Revealing errors, most telling first
+  rep errors --primary=false        Across all captured domains
+  rep errors --all                  Include plain error messages
+  rep errors -d api.target.com -o json
+  rep errors --saved latest --limit 5`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := errorsFilter.options()
+		if err != nil {
+			return err
+		}
+		if opts.Status == 0 && opts.StatusRange == "" && len(opts.StatusRanges) == 0 {
+			opts.StatusRanges = []string{"4xx", "5xx"}
+		}
+		requests, err := filterSource(errorsSaved, opts)
+		if err != nil || requests == nil {
+			return err
+		}
+
+		findings := []ErrorFinding{}
+		techCounts := make(map[string]int)
+		for i := range requests {
+			req := &requests[i]
+			if req.Response == nil || req.Response.Status < 400 {
+				continue
+			}
+			info := triage.ClassifyError(req)
+			for _, t := range info.Technologies {
+				techCounts[t]++
+			}
+			if !errorsAll && info.Revealing <= 1 {
+				continue
+			}
+			findings = append(findings, ErrorFinding{
+				ID: req.ID, Method: req.Method, URL: req.URL, Status: req.Response.Status, ErrorInfo: info,
+			})
+		}
+		sort.SliceStable(findings, func(i, j int) bool { return findings[i].Revealing > findings[j].Revealing })
+		total := len(findings)
+		if errorsLimit > 0 && len(findings) > errorsLimit {
+			findings = findings[:errorsLimit]
+		}
+
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"technologies": techCounts,
+				"total":        total,
+				"errors":       findings,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(techCounts) > 0 {
+			names := make([]string, 0, len(techCounts))
+			for name := range techCounts {
+				names = append(names, name)
+			}
+			sort.Slice(names, func(i, j int) bool {
+				if techCounts[names[i]] != techCounts[names[j]] {
+					return techCounts[names[i]] > techCounts[names[j]]
+				}
+				return names[i] < names[j]
+			})
+			parts := make([]string, len(names))
+			for i, name := range names {
+				parts[i] = fmt.Sprintf("%s (%d)", name, techCounts[name])
+			}
+			pterm.Info.Printf("Technologies: %s\n", strings.Join(parts, ", "))
+		}
+		if len(findings) == 0 {
+			pterm.Success.Println("No revealing error responses (use --all for plain messages)")
+			return nil
+		}
+
+		for _, f := range findings {
+			tech := ""
+			if len(f.Technologies) > 0 {
+				tech = pterm.FgCyan.Sprintf("  [%s]", strings.Join(f.Technologies, ", "))
+			}
+			fmt.Printf("[%s] %s %s → %s%s\n", f.ID, f.Method, output.SanitizeText(f.URL), colorStatus(f.Status, fmt.Sprint(f.Status)), tech)
+			fmt.Printf("    %s\n", pterm.FgYellow.Sprint(strings.Join(f.Kinds, ", ")))
+			if f.Message != "" {
+				fmt.Printf("    %s\n", output.SanitizeText(f.Message))
+			}
+			if len(f.Paths) > 0 {
+				fmt.Printf("    %s\n", pterm.FgGray.Sprint("paths: "+output.SanitizeText(strings.Join(f.Paths, " "))))
+			}
+		}
+		fmt.Println()
+		if len(findings) < total {
+			fmt.Printf("[Showing %d of %d errors]\n", len(findings), total)
+		}
+		fmt.Printf("  Next: rep body %s\n", findings[0].ID)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(errorsCmd)
+	errorsFilter.register(errorsCmd)
+	errorsCmd.Flags().StringVar(&errorsSaved, "saved", "", "Read from saved session (ID, prefix, or 'latest')")
+	errorsCmd.Flags().BoolVar(&errorsAll, "all", false, "Include errors that reveal only a message")
+	errorsCmd.Flags().IntVarP(&errorsLimit, "limit", "l", 0, "Show at most N errors")
+}
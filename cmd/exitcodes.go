@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes. They are part of the scripting interface: don't renumber.
+const (
+	exitError    = 1 // Any other failure
+	exitUsage    = 2 // Bad flag or argument
+	exitNotFound = 3 // A named request, session, group or file doesn't exist
+	exitEmpty    = 4 // Nothing captured or nothing matched (--strict only)
+	exitParse    = 5 // A capture, import or config file couldn't be parsed
+	exitWarning  = 6 // Any other warning (--strict only)
+)
+
+// errorKinds names the exit codes in JSON errors
+var errorKinds = map[int]string{
+	exitError:    "error",
+	exitUsage:    "usage",
+	exitNotFound: "not_found",
+	exitEmpty:    "empty",
+	exitParse:    "parse_error",
+	exitWarning:  "warning",
+}
+
+// strictMode is the --strict flag
+var strictMode bool
+
+// cliError is an error with its exit code and an optional hint on what to
+// run next
+type cliError struct {
+	code int
+	msg  string
+	hint string
+}
+
+func (e *cliError) Error() string { return e.msg }
+
+func newCLIError(code int, format string, args ...interface{}) *cliError {
+	return &cliError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+func usageError(format string, args ...interface{}) *cliError {
+	return newCLIError(exitUsage, format, args...)
+}
+
+func notFoundError(format string, args ...interface{}) *cliError {
+	return newCLIError(exitNotFound, format, args...)
+}
+
+func emptyError(format string, args ...interface{}) *cliError {
+	return newCLIError(exitEmpty, format, args...)
+}
+
+func parseError(format string, args ...interface{}) *cliError {
+	return newCLIError(exitParse, format, args...)
+}
+
+func warningError(format string, args ...interface{}) *cliError {
+	return newCLIError(exitWarning, format, args...)
+}
+
+// withHint sets the hint printed below the message
+func (e *cliError) withHint(hint string) *cliError {
+	e.hint = hint
+	return e
+}
+
+// softFail reports a problem the command stops at without failing: an info
+// line for empty results, a warning otherwise, recorded for the envelope
+// too. With --strict it returns e instead, so the command fails with e's
+// exit code.
+func softFail(e *cliError) error {
+	if strictMode {
+		return e
+	}
+	jsonWarn("%s", e.msg)
+	if e.code == exitEmpty {
+		pterm.Info.Println(e.msg)
+	} else {
+		pterm.Warning.Println(e.msg)
+	}
+	if e.hint != "" {
+		pterm.Info.Println(e.hint)
+	}
+	return nil
+}
+
+// liveReadError classifies a failure to read live.json
+func liveReadError(err error) *cliError {
+	e := parseError("Could not read live.json: %v", err)
+	if errors.Is(err, os.ErrNotExist) {
+		e.code = exitNotFound
+	}
+	return e.withHint("Enable auto-export in rep+ extension first")
+}
+
+func emptyLiveError() *cliError {
+	return emptyError("No requests captured yet (live session empty)")
+}
+
+func noPrimaryError() *cliError {
+	return emptyError("No primary domains set. Use 'rep primary <domain>' to add.")
+}
+
+// exitCode maps an error returned by a command to the process exit code
+func exitCode(err error) int {
+	var e *cliError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	msg := err.Error()
+	if strings.HasPrefix(msg, "unknown command") || strings.HasPrefix(msg, "unknown flag") ||
+		strings.HasPrefix(msg, "unknown shorthand flag") {
+		return exitUsage
+	}
+	return exitError
+}
+
+// printError writes a failed command's error to stderr: as JSON when JSON
+// output was asked for, so agents can parse it, otherwise as text
+func printError(err error, code int) {
+	hint := ""
+	var e *cliError
+	if errors.As(err, &e) {
+		hint = e.hint
+	}
+	if getOutputMode() == "json" || getOutputMode() == "ndjson" || envelopeEnabled() {
+		detail := map[string]interface{}{
+			"kind":      errorKinds[code],
+			"exit_code": code,
+			"message":   err.Error(),
+		}
+		if hint != "" {
+			detail["hint"] = hint
+		}
+		out, _ := sonic.Marshal(map[string]interface{}{"error": detail})
+		fmt.Fprintln(os.Stderr, string(out))
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Error: "+err.Error())
+	if hint != "" {
+		fmt.Fprintln(os.Stderr, hint)
+	}
+	if code == exitUsage {
+		fmt.Fprintln(os.Stderr, "Run 'rep --help' or 'rep <command> --help' for usage.")
+	}
+}
+
+// classifyUsageErrors makes flag and argument errors of every command exit
+// with exitUsage
+func classifyUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return usageError("%v", err)
+	})
+	if args := cmd.Args; args != nil {
+		cmd.Args = func(c *cobra.Command, a []string) error {
+			if err := args(c, a); err != nil {
+				return usageError("%v", err)
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		classifyUsageErrors(sub)
+	}
+}
@@ -0,0 +1,571 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/sanitize"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat    string
+	exportOut       string
+	exportUseVars   bool
+	exportSaved     string
+	exportSanitized bool
+	exportMapping   string
+	exportFilter    requestFilterFlags
+
+	// exportSessionID is the ID of a session export, shared with its mapping
+	exportSessionID string
+)
+
+// exporter renders a set of requests in one export format
+type exporter func(requests []store.Request, useVars bool) (string, error)
+
+// exportFormats maps --format values to exporters
+var exportFormats = map[string]exporter{
+	"gotest":   exportGoTest,
+	"pytest":   exportPyTest,
+	"ffuf":     exportFFUF,
+	"nuclei":   exportNuclei,
+	"session":  exportSession,
+	"har":      exportHAR,
+	"zap-har":  exportZAPHAR,
+	"insomnia": exportInsomnia,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export [request-id]",
+	Short: "Export captured requests for other tools (test suites, fuzzers)",
+	Long: `Export captured requests in a format other tools can consume.
+
+With a request ID, exports that single request. Otherwise the same
+filter flags as 'rep list' select which requests to export.
+
+Formats (--format):
+  gotest   Go test file: one test per endpoint (method + path)
+  pytest   pytest module: one test per endpoint (method + path)
+  ffuf     Raw HTTP request file for ffuf -request (single request), with
+           FUZZ in a variable path segment, else the first query parameter,
+           else the first body field, else a new path segment
+  nuclei   Target list for nuclei -l: base URLs, then unique endpoint URLs
+  session  Session file of the selected requests, loadable with 'rep import'
+  har      HTTP Archive 1.2 with full bodies (binary ones base64); request
+           IDs are kept in _id, so 'rep import' restores them
+  zap-har  HAR for ZAP (Import > HAR File): HTTP/1.1 versions and text
+           bodies only, which ZAP's importer requires
+  insomnia Insomnia v4 collection: a folder per domain, one request per
+           endpoint with its body; credentials become {{ _.VAR }}
+           references, set in a sub-environment per domain
+
+Test exports use the latest captured request of each endpoint as the
+fixture and assert the captured status code plus the top-level JSON
+keys and value types of the captured response. Set REP_BASE_URL when
+running the tests to point them at another environment.
+
+Sanitized exports (--sanitized):
+  Credentials, cookies, tokens, passwords, emails and user identifiers are
+  replaced by stable placeholders ({{TOKEN_1}}, {{COOKIE_2}}, {{SECRET_1}},
+  {{EMAIL_1}}, {{USER_1}}) in every header, URL and body before exporting.
+  The same value gets the same placeholder everywhere, so a token issued by
+  one response is still recognizably the one later requests send, and
+  bodies keep their structure. Numeric IDs are kept. With --sanitized the
+  format defaults to session: a copy safe to hand to collaborators or LLMs.
+
+  --mapping-out <file> also writes the placeholder -> original table (mode
+  0600). Keep it local: with 'rep --mapping <file>' (or $REP_MAPPING),
+  replay, curl --send, bypass, race and the other commands that send
+  requests put the real values back just before sending, so an agent can
+  work on the sanitized session and still replay its requests.
+
+The ffuf export resolves --use-vars from the environment at export time
+and prints the FUZZ placement and a suggested ffuf command to stderr.
+
+Examples:
+  rep export --format gotest -d api.target.com --api --out api_test.go
+  rep export --format pytest --primary -p "/v1/" --out test_api.py
+  rep export --format pytest --use-vars        Read auth from env vars
+  rep export h_abc123 --format gotest          Single request
+  rep export h_abc123 --format ffuf --out req.txt
+  rep export --format nuclei -d api.target.com --out targets.txt
+  rep export --format zap-har -d api.target.com --out zap.har
+  rep export --format insomnia --primary --out insomnia.json
+  rep export --sanitized --saved latest --primary=false --out shared.json
+  rep export --sanitized --format pytest -d api.target.com
+  rep export --sanitized --saved latest --out shared.json --mapping-out ~/.rep/shared.map.json
+  rep --mapping ~/.rep/shared.map.json replay h_abc123`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exportSanitized && !cmd.Flags().Changed("format") {
+			exportFormat = "session"
+		}
+		if exportMapping != "" && !exportSanitized {
+			return fmt.Errorf("--mapping-out requires --sanitized")
+		}
+		exp, ok := exportFormats[strings.ToLower(exportFormat)]
+		if !ok {
+			return fmt.Errorf("unsupported format: %s (use %s)", exportFormat, strings.Join(exportFormatNames(), ", "))
+		}
+
+		var requests []store.Request
+		if len(args) == 1 {
+			req, err := lookupRequest(args[0], exportSaved)
+			if err != nil {
+				return err
+			}
+			if req == nil {
+				return notFoundError("request not found: %s", args[0]).withHint("Use 'rep list' to see available request IDs")
+			}
+			requests = []store.Request{*req}
+		} else {
+			opts, err := exportFilter.options()
+			if err != nil {
+				return err
+			}
+			requests, err = filterSource(exportSaved, opts)
+			if err != nil {
+				return err
+			}
+			if requests == nil {
+				return nil
+			}
+		}
+
+		if len(requests) == 0 {
+			return softFail(emptyError("No requests match the filter"))
+		}
+
+		exportSessionID = store.GenerateSessionID("export")
+		var sanitizer *sanitize.Sanitizer
+		if exportSanitized {
+			exportSessionID = store.GenerateSessionID("sanitized")
+			sanitizer, requests = sanitizeRequests(requests)
+		}
+
+		content, err := exp(requests, exportUseVars)
+		if err != nil {
+			return err
+		}
+
+		if err := writeExport(content, exportOut, len(requests)); err != nil {
+			return err
+		}
+		if strings.ToLower(exportFormat) == "ffuf" && getOutputMode() != "json" {
+			ffufHint(&requests[0], exportOut)
+		}
+		if exportMapping != "" {
+			sessionID := ""
+			if strings.ToLower(exportFormat) == "session" {
+				sessionID = exportSessionID
+			}
+			if err := sanitize.WriteMapping(exportMapping, sanitizer.Mapping(sessionID)); err != nil {
+				return fmt.Errorf("failed to write mapping: %w", err)
+			}
+		}
+		if exportSanitized && (exportOut != "" || exportMapping != "") && getOutputMode() != "json" {
+			printSanitizeCounts(sanitizer.Counts())
+			if exportMapping != "" {
+				pterm.Info.Printf("Mapping written to %s (keep it private; replay with: rep --mapping %s ...)\n", exportMapping, exportMapping)
+			}
+		}
+		return nil
+	},
+}
+
+// writeExport prints content to stdout, or writes it to path and reports the result.
+func writeExport(content, path string, requestCount int) error {
+	if path == "" {
+		fmt.Print(content)
+		return nil
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	if getOutputMode() == "json" {
+		out, _ := sonic.MarshalIndent(map[string]interface{}{
+			"format":   strings.ToLower(exportFormat),
+			"path":     path,
+			"requests": requestCount,
+		}, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		pterm.Success.Printf("Exported %d requests to %s\n", requestCount, path)
+	}
+	return nil
+}
+
+func exportFormatNames() []string {
+	names := make([]string, 0, len(exportFormats))
+	for name := range exportFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// requestEndpoint returns "METHOD /path" without the query string
+func requestEndpoint(req *store.Request) string {
+	pathOnly := req.Path
+	if idx := strings.Index(pathOnly, "?"); idx >= 0 {
+		pathOnly = pathOnly[:idx]
+	}
+	if pathOnly == "" {
+		pathOnly = "/"
+	}
+	return fmt.Sprintf("%s %s", req.Method, pathOnly)
+}
+
+// endpointFixture is the request chosen to represent an endpoint in a test export
+type endpointFixture struct {
+	Endpoint string
+	Domain   string
+	Request  store.Request
+	// JSONKeys maps top-level response keys to JSON types (nil if not a JSON object)
+	JSONKeys map[string]string
+}
+
+// buildEndpointFixtures groups requests by domain + endpoint and keeps the
+// latest capture of each as the test fixture.
+func buildEndpointFixtures(requests []store.Request) []endpointFixture {
+	byKey := make(map[string]*endpointFixture)
+	var order []string
+
+	for _, req := range requests {
+		store.ComputeRequestFields(&req)
+		endpoint := requestEndpoint(&req)
+		key := req.Domain + " " + endpoint
+		existing, ok := byKey[key]
+		if !ok {
+			order = append(order, key)
+		} else if existing.Request.Timestamp > req.Timestamp {
+			continue
+		}
+		byKey[key] = &endpointFixture{
+			Endpoint: endpoint,
+			Domain:   req.Domain,
+			Request:  req,
+		}
+	}
+
+	fixtures := make([]endpointFixture, 0, len(order))
+	for _, key := range order {
+		f := byKey[key]
+		if f.Request.Response != nil {
+			f.JSONKeys = jsonTopLevelTypes(f.Request.Response.Body)
+		}
+		fixtures = append(fixtures, *f)
+	}
+	return fixtures
+}
+
+// jsonTopLevelTypes returns key -> JSON type for a JSON object body
+func jsonTopLevelTypes(body string) map[string]string {
+	trimmed := strings.TrimSpace(body)
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil
+	}
+	var obj map[string]interface{}
+	if err := sonic.UnmarshalString(trimmed, &obj); err != nil {
+		return nil
+	}
+	types := make(map[string]string, len(obj))
+	for key, value := range obj {
+		types[key] = jsonTypeName(value)
+	}
+	return types
+}
+
+// jsonTypeName returns the JSON type name for a decoded value
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "number"
+	}
+}
+
+// testName builds a unique identifier like "PostV1Orders" from an endpoint
+func testName(endpoint string, used map[string]int) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range endpoint {
+		isAlnum := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !isAlnum {
+			upper = true
+			continue
+		}
+		if upper && r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		} else if !upper && r >= 'A' && r <= 'Z' {
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+		upper = false
+	}
+	name := b.String()
+	if name == "" {
+		name = "Request"
+	}
+	used[name]++
+	if used[name] > 1 {
+		name += strconv.Itoa(used[name])
+	}
+	return name
+}
+
+// snakeCase converts "PostV1Orders" to "post_v1_orders"
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func exportGoTest(requests []store.Request, useVars bool) (string, error) {
+	fixtures := buildEndpointFixtures(requests)
+
+	var b strings.Builder
+	b.WriteString("// Code generated by rep export --format gotest. Review before committing.\n\n")
+	b.WriteString("package apitest\n\n")
+	b.WriteString("import (\n\t\"encoding/json\"\n\t\"io\"\n\t\"net/http\"\n\t\"net/url\"\n\t\"os\"\n\t\"strings\"\n\t\"testing\"\n)\n\n")
+	b.WriteString(`// baseURL rewrites a captured URL to $REP_BASE_URL when set.
+func baseURL(t *testing.T, raw string) string {
+	t.Helper()
+	base := os.Getenv("REP_BASE_URL")
+	if base == "" {
+		return raw
+	}
+	target, err := url.Parse(base)
+	if err != nil {
+		t.Fatalf("invalid REP_BASE_URL: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("invalid URL %q: %v", raw, err)
+	}
+	u.Scheme = target.Scheme
+	u.Host = target.Host
+	return u.String()
+}
+
+// expectJSONKeys checks that the body is a JSON object with the given key types.
+func expectJSONKeys(t *testing.T, body []byte, want map[string]string) {
+	t.Helper()
+	var obj map[string]interface{}
+	if err := json.Unmarshal(body, &obj); err != nil {
+		t.Fatalf("response is not a JSON object: %v", err)
+	}
+	for key, typ := range want {
+		value, ok := obj[key]
+		if !ok {
+			t.Errorf("missing key %q", key)
+			continue
+		}
+		if got := jsonType(value); got != typ {
+			t.Errorf("key %q: type = %s, want %s", key, got, typ)
+		}
+	}
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "number"
+	}
+}
+
+// Keep imports used when no test sends a body or reads the environment.
+var (
+	_ = strings.NewReader
+	_ = os.Getenv
+)
+`)
+
+	used := make(map[string]int)
+	for _, f := range fixtures {
+		req := f.Request
+		name := testName(f.Domain+" "+f.Endpoint, used)
+
+		fmt.Fprintf(&b, "\n// Test%s replays %s %s (captured as %s).\n", name, req.Method, f.Domain+strings.TrimPrefix(f.Endpoint, req.Method+" "), req.ID)
+		fmt.Fprintf(&b, "func Test%s(t *testing.T) {\n", name)
+
+		bodyArg := "nil"
+		if req.Body != "" {
+			fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n", strconv.Quote(req.Body))
+			bodyArg = "body"
+		}
+		fmt.Fprintf(&b, "\treq, err := http.NewRequest(%s, baseURL(t, %s), %s)\n", strconv.Quote(req.Method), strconv.Quote(req.URL), bodyArg)
+		b.WriteString("\tif err != nil {\n\t\tt.Fatal(err)\n\t}\n")
+
+		for _, h := range replayHeaders(&req, useVars) {
+			value := renderTemplated(h, strconv.Quote, func(name string) string {
+				return fmt.Sprintf("os.Getenv(%q)", name)
+			}, "+")
+			fmt.Fprintf(&b, "\treq.Header.Add(%s, %s)\n", strconv.Quote(h.Name), value)
+		}
+
+		b.WriteString("\n\tresp, err := http.DefaultClient.Do(req)\n")
+		b.WriteString("\tif err != nil {\n\t\tt.Fatal(err)\n\t}\n")
+		b.WriteString("\tdefer resp.Body.Close()\n")
+
+		if req.Response != nil {
+			fmt.Fprintf(&b, "\n\tif resp.StatusCode != %d {\n", req.Response.Status)
+			fmt.Fprintf(&b, "\t\tt.Errorf(\"status = %%d, want %d\", resp.StatusCode)\n", req.Response.Status)
+			b.WriteString("\t}\n")
+		}
+
+		if len(f.JSONKeys) > 0 {
+			b.WriteString("\n\tdata, err := io.ReadAll(resp.Body)\n")
+			b.WriteString("\tif err != nil {\n\t\tt.Fatal(err)\n\t}\n")
+			b.WriteString("\texpectJSONKeys(t, data, map[string]string{\n")
+			for _, key := range sortedKeys(f.JSONKeys) {
+				fmt.Fprintf(&b, "\t\t%s: %s,\n", strconv.Quote(key), strconv.Quote(f.JSONKeys[key]))
+			}
+			b.WriteString("\t})\n")
+		} else {
+			b.WriteString("\t_, _ = io.Copy(io.Discard, resp.Body)\n")
+		}
+		b.WriteString("}\n")
+	}
+
+	return b.String(), nil
+}
+
+func exportPyTest(requests []store.Request, useVars bool) (string, error) {
+	fixtures := buildEndpointFixtures(requests)
+
+	var b strings.Builder
+	b.WriteString(`# Generated by rep export --format pytest. Review before committing.
+import os
+from urllib.parse import urlsplit, urlunsplit
+
+import requests
+
+BASE_URL = os.environ.get("REP_BASE_URL")
+
+
+def _url(raw):
+    """Rewrite a captured URL to $REP_BASE_URL when set."""
+    if not BASE_URL:
+        return raw
+    base = urlsplit(BASE_URL)
+    parts = urlsplit(raw)
+    return urlunsplit((base.scheme, base.netloc, parts.path, parts.query, parts.fragment))
+
+
+def _json_type(value):
+    if value is None:
+        return "null"
+    if isinstance(value, bool):
+        return "boolean"
+    if isinstance(value, str):
+        return "string"
+    if isinstance(value, list):
+        return "array"
+    if isinstance(value, dict):
+        return "object"
+    return "number"
+
+
+def _expect_json_keys(resp, expected):
+    data = resp.json()
+    assert isinstance(data, dict), "response is not a JSON object"
+    for key, typ in expected.items():
+        assert key in data, f"missing key {key!r}"
+        assert _json_type(data[key]) == typ, f"key {key!r}: type {_json_type(data[key])}, want {typ}"
+`)
+
+	used := make(map[string]int)
+	for _, f := range fixtures {
+		req := f.Request
+		name := snakeCase(testName(f.Domain+" "+f.Endpoint, used))
+
+		fmt.Fprintf(&b, "\n\ndef test_%s():\n", name)
+		fmt.Fprintf(&b, "    \"\"\"Replays %s (captured as %s).\"\"\"\n", f.Endpoint, req.ID)
+
+		b.WriteString("    headers = {\n")
+		for _, h := range mergedReplayHeaders(&req, useVars) {
+			value := renderTemplated(h, jsonString, func(name string) string {
+				return fmt.Sprintf("os.environ[%q]", name)
+			}, " + ")
+			fmt.Fprintf(&b, "        %s: %s,\n", jsonString(h.Name), value)
+		}
+		b.WriteString("    }\n")
+
+		dataArg := ""
+		if req.Body != "" {
+			dataArg = ", data=" + jsonString(req.Body)
+		}
+		fmt.Fprintf(&b, "    resp = requests.request(%s, _url(%s), headers=headers%s)\n",
+			jsonString(req.Method), jsonString(req.URL), dataArg)
+
+		if req.Response != nil {
+			fmt.Fprintf(&b, "    assert resp.status_code == %d\n", req.Response.Status)
+		}
+		if len(f.JSONKeys) > 0 {
+			b.WriteString("    _expect_json_keys(resp, {\n")
+			for _, key := range sortedKeys(f.JSONKeys) {
+				fmt.Fprintf(&b, "        %s: %s,\n", jsonString(key), jsonString(f.JSONKeys[key]))
+			}
+			b.WriteString("    })\n")
+		}
+	}
+
+	return b.String(), nil
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "gotest", "Export format: gotest, pytest, ffuf, nuclei, session")
+	exportCmd.Flags().StringVar(&exportOut, "out", "", "Write to file instead of stdout")
+	exportCmd.Flags().BoolVar(&exportUseVars, "use-vars", false, "Read auth tokens from environment variables")
+	exportCmd.Flags().StringVar(&exportSaved, "saved", "", "Read from saved session (ID, prefix, or 'latest')")
+	exportCmd.Flags().BoolVar(&exportSanitized, "sanitized", false, "Replace credentials and personal data with placeholders (default format: session)")
+	exportCmd.Flags().StringVar(&exportMapping, "mapping-out", "", "With --sanitized, write the placeholder -> original mapping to this file (0600)")
+	exportFilter.register(exportCmd)
+}
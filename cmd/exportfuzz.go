@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// fuzzMarker is the keyword ffuf replaces with wordlist entries
+const fuzzMarker = "FUZZ"
+
+// fuzzPlacement is where FUZZ goes in an exported request
+type fuzzPlacement struct {
+	URL    string
+	Body   string
+	Reason string
+}
+
+// exportFFUF renders one request as a raw HTTP request file for ffuf -request,
+// with FUZZ at the most promising position
+func exportFFUF(requests []store.Request, useVars bool) (string, error) {
+	if len(requests) != 1 {
+		return "", fmt.Errorf("ffuf exports a single request (got %d): pass a request ID", len(requests))
+	}
+	req := &requests[0]
+	sendReq, missing := resolveReplayRequest(req, useVars)
+	for _, name := range missing {
+		fmt.Fprintf(os.Stderr, "Warning: $%s not set, using captured value\n", name)
+	}
+
+	placement := ffufPlacement(sendReq)
+	return formatRawRequest(sendReq.Method, placement.URL, replayHeaders(sendReq, false), placement.Body)
+}
+
+// ffufPlacement puts FUZZ in a variable path segment, else the first query
+// parameter, else the first body field, else a new trailing path segment
+// (content discovery)
+func ffufPlacement(req *store.Request) fuzzPlacement {
+	p := fuzzPlacement{URL: req.URL, Body: req.Body}
+	parsed, err := url.Parse(req.URL)
+	if err != nil {
+		p.Reason = "unparseable URL"
+		return p
+	}
+
+	segments := strings.Split(parsed.Path, "/")
+	template := strings.Split(store.EndpointTemplate(parsed.Path), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if i < len(template) && template[i] == "{id}" {
+			p.Reason = fmt.Sprintf("variable path segment %q (IDOR / enumeration)", segments[i])
+			segments[i] = fuzzMarker
+			u := *parsed
+			u.Path, u.RawPath = strings.Join(segments, "/"), ""
+			p.URL = u.String()
+			return p
+		}
+	}
+
+	if parsed.RawQuery != "" {
+		q := parsed.Query()
+		keys := make([]string, 0, len(q))
+		for k := range q {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		q.Set(keys[0], fuzzMarker)
+		u := *parsed
+		u.RawQuery = q.Encode()
+		p.URL = u.String()
+		p.Reason = fmt.Sprintf("query parameter %q", keys[0])
+		return p
+	}
+
+	if body, field := fuzzBodyField(req); field != "" {
+		p.Body = body
+		p.Reason = fmt.Sprintf("body field %q", field)
+		return p
+	}
+
+	u := *parsed
+	u.Path, u.RawPath = strings.TrimSuffix(parsed.Path, "/")+"/"+fuzzMarker, ""
+	p.URL = u.String()
+	p.Reason = "new path segment (content discovery)"
+	return p
+}
+
+// fuzzBodyField sets the first (alphabetical) scalar field of a JSON or form
+// body to FUZZ
+func fuzzBodyField(req *store.Request) (string, string) {
+	if req.Body == "" {
+		return "", ""
+	}
+	contentType := strings.ToLower(store.HeaderFirst(req.Headers, "content-type"))
+	switch {
+	case strings.Contains(contentType, "json"):
+		var obj map[string]interface{}
+		if json.Unmarshal([]byte(req.Body), &obj) != nil {
+			return "", ""
+		}
+		keys := make([]string, 0, len(obj))
+		for k, v := range obj {
+			switch v.(type) {
+			case string, float64, bool:
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) == 0 {
+			return "", ""
+		}
+		sort.Strings(keys)
+		obj[keys[0]] = fuzzMarker
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return "", ""
+		}
+		return string(data), keys[0]
+	case strings.Contains(contentType, "x-www-form-urlencoded"):
+		values, err := url.ParseQuery(req.Body)
+		if err != nil || len(values) == 0 {
+			return "", ""
+		}
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values.Set(keys[0], fuzzMarker)
+		return values.Encode(), keys[0]
+	}
+	return "", ""
+}
+
+// ffufHint explains the FUZZ placement and the command to run, on stderr so
+// the exported request stays pipeable
+func ffufHint(req *store.Request, path string) {
+	placement := ffufPlacement(req)
+	file := path
+	if file == "" {
+		file = "req.txt"
+	}
+	proto := "https"
+	if strings.HasPrefix(req.URL, "http://") {
+		proto = "http"
+	}
+	command := fmt.Sprintf("ffuf -request %s -request-proto %s -w wordlist.txt -mc all", file, proto)
+	if req.Response != nil {
+		command += fmt.Sprintf(" -fs %d", store.ResponseSize(req))
+	}
+	fmt.Fprintf(os.Stderr, "FUZZ placed in %s\n", placement.Reason)
+	fmt.Fprintf(os.Stderr, "Run: %s\n", command)
+}
+
+// exportNuclei lists the base URLs and unique endpoint URLs of the requests,
+// one per line, for nuclei -l
+func exportNuclei(requests []store.Request, useVars bool) (string, error) {
+	var bases, endpoints []string
+	seenBase := make(map[string]bool)
+	seenEndpoint := make(map[string]bool)
+	for _, req := range requests {
+		parsed, err := url.Parse(req.URL)
+		if err != nil || parsed.Host == "" {
+			continue
+		}
+		base := parsed.Scheme + "://" + parsed.Host
+		if !seenBase[base] {
+			seenBase[base] = true
+			bases = append(bases, base)
+		}
+		u := *parsed
+		u.Fragment = ""
+		endpoint := u.String()
+		if endpoint != base && endpoint != base+"/" && !seenEndpoint[endpoint] {
+			seenEndpoint[endpoint] = true
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	sort.Strings(bases)
+	sort.Strings(endpoints)
+
+	var b strings.Builder
+	for _, line := range append(bases, endpoints...) {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	"github.com/repplus/rep-cli/internal/har"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+func exportHAR(requests []store.Request, useVars bool) (string, error) {
+	return renderHAR(requests, har.Options{})
+}
+
+// exportZAPHAR writes a HAR ZAP's Import > HAR File reads: HTTP/1.1
+// versions only and text bodies only
+func exportZAPHAR(requests []store.Request, useVars bool) (string, error) {
+	return renderHAR(requests, har.Options{ZAP: true})
+}
+
+func renderHAR(requests []store.Request, opts har.Options) (string, error) {
+	opts.Creator = har.Creator{Name: "rep", Version: Version}
+	data, err := sonic.MarshalIndent(har.Build(requests, opts), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode HAR: %w", err)
+	}
+	return string(data) + "\n", nil
+}
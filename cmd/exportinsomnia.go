@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// insomniaExport is an Insomnia v4 export: a flat resource list linked by
+// parentId
+type insomniaExport struct {
+	Type         string             `json:"_type"`
+	ExportFormat int                `json:"__export_format"`
+	ExportDate   string             `json:"__export_date"`
+	ExportSource string             `json:"__export_source"`
+	Resources    []insomniaResource `json:"resources"`
+}
+
+// insomniaResource is a workspace, environment, request_group or request
+type insomniaResource struct {
+	ID          string            `json:"_id"`
+	Type        string            `json:"_type"`
+	ParentID    *string           `json:"parentId"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Scope       string            `json:"scope,omitempty"`
+	Data        map[string]string `json:"data,omitempty"`
+	Method      string            `json:"method,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Body        *insomniaBody     `json:"body,omitempty"`
+	Headers     []insomniaPair    `json:"headers,omitempty"`
+	Created     int64             `json:"created,omitempty"`
+	Modified    int64             `json:"modified,omitempty"`
+}
+
+type insomniaBody struct {
+	MimeType string         `json:"mimeType"`
+	Text     string         `json:"text,omitempty"`
+	Params   []insomniaPair `json:"params,omitempty"`
+}
+
+type insomniaPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// exportInsomnia writes one request per endpoint, in a folder per domain.
+// Credentials always become {{ _.VAR }} references: the base environment
+// holds every variable and a sub-environment per domain holds that domain's
+// values, so useVars changes nothing.
+func exportInsomnia(requests []store.Request, useVars bool) (string, error) {
+	fixtures := buildEndpointFixtures(requests)
+	var domains []string
+	for _, f := range fixtures {
+		if !containsString(domains, f.Domain) {
+			domains = append(domains, f.Domain)
+		}
+	}
+	sort.Strings(domains)
+
+	workspaceID := "wrk_" + insomniaID("workspace", strings.Join(domains, ","))
+	baseEnvID := "env_" + insomniaID("base", workspaceID)
+	export := insomniaExport{
+		Type:         "export",
+		ExportFormat: 4,
+		ExportDate:   time.Now().UTC().Format(time.RFC3339),
+		ExportSource: "rep:" + Version,
+	}
+	// Filled in below; Data is shared with the copy in Resources
+	baseEnv := insomniaResource{ID: baseEnvID, Type: "environment", ParentID: &workspaceID, Name: "Base Environment", Data: map[string]string{}}
+	export.Resources = append(export.Resources,
+		insomniaResource{ID: workspaceID, Type: "workspace", Name: "rep: " + strings.Join(domains, ", "), Scope: "collection",
+			Description: fmt.Sprintf("%d endpoints exported by rep", len(fixtures))},
+		baseEnv)
+
+	domainVars := make(map[string]map[string]string)
+	var requestResources []insomniaResource
+	for _, f := range fixtures {
+		req := f.Request
+		folderID := "fld_" + insomniaID("domain", f.Domain)
+		if domainVars[f.Domain] == nil {
+			domainVars[f.Domain] = map[string]string{}
+		}
+		values := requestAuthValues(&req)
+		template := func(s string) string {
+			for _, v := range values {
+				if strings.Contains(s, v.Value) {
+					s = strings.ReplaceAll(s, v.Value, "{{ _."+v.Name+" }}")
+					domainVars[f.Domain][v.Name] = v.Value
+				}
+			}
+			return s
+		}
+
+		r := insomniaResource{
+			ID:       "req_" + insomniaID("request", f.Domain+" "+f.Endpoint),
+			Type:     "request",
+			ParentID: &folderID,
+			Name:     f.Endpoint,
+			Method:   req.Method,
+			URL:      template(req.URL),
+			Headers:  []insomniaPair{},
+			Created:  req.Timestamp,
+			Modified: req.Timestamp,
+		}
+		for _, h := range replayHeaders(&req, false) {
+			r.Headers = append(r.Headers, insomniaPair{Name: h.Name, Value: template(h.Value)})
+		}
+		if req.Body != "" {
+			r.Body = insomniaRequestBody(store.HeaderFirst(req.Headers, "content-type"), template(req.Body))
+		}
+		requestResources = append(requestResources, r)
+	}
+
+	for _, domain := range domains {
+		folderID := "fld_" + insomniaID("domain", domain)
+		export.Resources = append(export.Resources, insomniaResource{ID: folderID, Type: "request_group", ParentID: &workspaceID, Name: domain})
+		if len(domainVars[domain]) == 0 {
+			continue
+		}
+		for name, value := range domainVars[domain] {
+			if _, ok := baseEnv.Data[name]; !ok {
+				baseEnv.Data[name] = value
+			}
+		}
+		export.Resources = append(export.Resources, insomniaResource{ID: "env_" + insomniaID("env", domain), Type: "environment",
+			ParentID: &baseEnvID, Name: domain, Data: domainVars[domain]})
+	}
+	export.Resources = append(export.Resources, requestResources...)
+
+	data, err := sonic.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode Insomnia export: %w", err)
+	}
+	return string(data) + "\n", nil
+}
+
+// insomniaRequestBody keeps a body as raw text with its content type; form
+// bodies become Insomnia's form parameters so they stay editable
+func insomniaRequestBody(contentType, body string) *insomniaBody {
+	mimeType := strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	if !strings.EqualFold(mimeType, "application/x-www-form-urlencoded") {
+		// Insomnia rebuilds multipart bodies from params, so send those raw
+		if strings.HasPrefix(strings.ToLower(mimeType), "multipart/") {
+			mimeType = ""
+		}
+		return &insomniaBody{MimeType: mimeType, Text: body}
+	}
+	var params []insomniaPair
+	for _, pair := range strings.Split(body, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		if n, err := url.QueryUnescape(name); err == nil {
+			name = n
+		}
+		if v, err := url.QueryUnescape(value); err == nil {
+			value = v
+		}
+		params = append(params, insomniaPair{Name: name, Value: value})
+	}
+	return &insomniaBody{MimeType: "application/x-www-form-urlencoded", Params: params}
+}
+
+// insomniaID derives a stable resource ID, so re-imports update resources
+// instead of duplicating them
+func insomniaID(kind, key string) string {
+	sum := sha1.Sum([]byte(kind + "\x00" + key))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/sanitize"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// sanitizeRequests returns copies of requests with credentials and
+// personal data replaced, and the Sanitizer that replaced them
+func sanitizeRequests(requests []store.Request) (*sanitize.Sanitizer, []store.Request) {
+	s := sanitize.New()
+	for i := range requests {
+		s.Collect(&requests[i])
+	}
+	sanitized := make([]store.Request, len(requests))
+	for i := range requests {
+		sanitized[i] = s.Apply(&requests[i])
+	}
+	return s, sanitized
+}
+
+// exportSession writes the requests as a single-session file for 'rep import'
+func exportSession(requests []store.Request, useVars bool) (string, error) {
+	note := "export"
+	if exportSanitized {
+		note = "sanitized"
+	}
+	id := exportSessionID
+	if id == "" {
+		id = store.GenerateSessionID(note)
+	}
+	export := store.SessionExport{
+		Format:     store.SessionExportFormat,
+		Version:    "1.0",
+		ExportedAt: time.Now().Format(time.RFC3339),
+		Session: store.Session{
+			ID:        id,
+			Timestamp: time.Now().UnixMilli(),
+			Note:      note,
+			Requests:  requests,
+		},
+	}
+	data, err := sonic.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode session: %w", err)
+	}
+	return string(data) + "\n", nil
+}
+
+func printSanitizeCounts(counts map[string]int) {
+	var parts []string
+	for _, category := range []string{sanitize.Token, sanitize.Cookie, sanitize.Secret, sanitize.Email, sanitize.User} {
+		if n := counts[category]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, strings.ToLower(category)))
+		}
+	}
+	if len(parts) == 0 {
+		pterm.Info.Println("No credentials or personal data found to replace")
+		return
+	}
+	pterm.Info.Printf("Replaced %s value(s) with placeholders\n", strings.Join(parts, ", "))
+}
+
+var (
+	loadedMapping *sanitize.Mapping
+	mappingLoaded bool
+)
+
+// sendMapping loads the --mapping (or $REP_MAPPING) file once; nil when
+// none is configured or it can't be read
+func sendMapping() *sanitize.Mapping {
+	if mappingLoaded {
+		return loadedMapping
+	}
+	mappingLoaded = true
+	path := mappingFile
+	if path == "" {
+		path = os.Getenv("REP_MAPPING")
+	}
+	if path == "" {
+		pterm.Warning.Println("Request contains sanitizer placeholders: pass --mapping <file> (or set $REP_MAPPING) to send the original values")
+		return nil
+	}
+	m, err := sanitize.LoadMapping(path)
+	if err != nil {
+		pterm.Warning.Printf("Placeholders sent as-is: %v\n", err)
+		return nil
+	}
+	loadedMapping = m
+	return m
+}
+
+// withMappedValues returns req with sanitizer placeholders replaced by the
+// original values of the mapping file, or req itself when it has none
+func withMappedValues(req *store.Request) *store.Request {
+	found := sanitize.HasPlaceholders(req.URL) || sanitize.HasPlaceholders(req.Body)
+	for _, values := range req.Headers {
+		for _, value := range values {
+			found = found || sanitize.HasPlaceholders(value)
+		}
+	}
+	if !found {
+		return req
+	}
+	m := sendMapping()
+	if m == nil {
+		return req
+	}
+
+	var unknown []string
+	restore := func(text string, escape bool) string {
+		restored, missing := m.Restore(text, escape)
+		unknown = append(unknown, missing...)
+		return restored
+	}
+	out := *req
+	out.URL = restore(req.URL, true)
+	out.Headers = store.CloneHeaders(req.Headers)
+	for name, values := range out.Headers {
+		for i := range values {
+			values[i] = restore(values[i], false)
+		}
+		out.Headers[name] = values
+	}
+	form := strings.Contains(strings.ToLower(store.HeaderFirst(req.Headers, "content-type")), "application/x-www-form-urlencoded")
+	out.Body = restore(req.Body, form)
+	if len(unknown) > 0 {
+		pterm.Warning.Printf("Not in the mapping, sent as-is: %s\n", strings.Join(unknown, ", "))
+	}
+	return &out
+}
@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// requestFilterFlags holds the request filter flags shared by 'rep list' and
+// every command with a filter mode, so they all accept the same vocabulary.
+type requestFilterFlags struct {
+	domain         string
+	method         string
+	status         int
+	statusRange    string
+	pattern        string
+	primary        bool
+	includeIgnored bool
+	resourceType   string // Comma-separated resource types: script,xhr,fetch,document
+	api            bool   // Preset: API calls only (xmlhttprequest,fetch)
+	interesting    bool   // Preset: Error responses + state-changing methods
+	errors         bool   // Preset: Only error responses (4xx/5xx)
+	mutations      bool   // Preset: Only state-changing methods
+	since          string // Relative duration, Unix time or RFC3339
+	until          string
+
+	// Exclusions
+	notDomain      string
+	notMethod      string
+	notPattern     string
+	notType        string
+	notStatusRange string
+
+	minSize string // Bytes with optional k/m suffix
+	maxSize string
+
+	reqHeaders  []string // "name" or "name:value-regex"
+	respHeaders []string
+
+	bodyPattern     string // Regex matched against the request body
+	respBodyPattern string // Regex matched against the response body
+}
+
+// register adds the filter flags to cmd
+func (f *requestFilterFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.domain, "domain", "d", "", "Filter by domain, @group, or comma-separated list")
+	cmd.Flags().StringVarP(&f.method, "method", "m", "", "Filter by HTTP method (or comma-separated list)")
+	cmd.Flags().IntVar(&f.status, "status", 0, "Filter by exact status code")
+	cmd.Flags().StringVar(&f.statusRange, "status-range", "", "Filter by status range (2xx, 3xx, 4xx, 5xx)")
+	cmd.Flags().StringVarP(&f.pattern, "pattern", "p", "", "Filter by URL pattern (regex)")
+	cmd.Flags().BoolVar(&f.primary, "primary", true, "Only show requests to primary domains (default)")
+	cmd.Flags().BoolVar(&f.includeIgnored, "include-ignored", false, "Include requests to ignored domains")
+	cmd.Flags().StringVar(&f.resourceType, "type", "", "Filter by resource type (script,xmlhttprequest,fetch,document)")
+	cmd.Flags().BoolVar(&f.api, "api", false, "Preset: API calls only (xmlhttprequest, fetch)")
+	cmd.Flags().BoolVar(&f.interesting, "interesting", false, "Preset: Error responses (4xx/5xx) + state-changing methods")
+	cmd.Flags().BoolVar(&f.errors, "errors", false, "Preset: Only error responses (4xx/5xx)")
+	cmd.Flags().BoolVar(&f.mutations, "mutations", false, "Preset: Only state-changing methods (POST/PUT/DELETE/PATCH)")
+	cmd.Flags().StringVar(&f.notDomain, "not-domain", "", "Exclude domains (comma-separated, @group allowed)")
+	cmd.Flags().StringVar(&f.notMethod, "not-method", "", "Exclude HTTP methods (e.g. OPTIONS,HEAD)")
+	cmd.Flags().StringVar(&f.notPattern, "not-pattern", "", "Exclude URLs matching pattern (regex)")
+	cmd.Flags().StringVar(&f.notType, "not-type", "", "Exclude resource types (e.g. image,font,preflight)")
+	cmd.Flags().StringVar(&f.notStatusRange, "not-status-range", "", "Exclude status ranges (e.g. 3xx,404)")
+	cmd.Flags().StringVar(&f.minSize, "min-size", "", "Only responses with at least this many body bytes (e.g. 512, 10k, 2m)")
+	cmd.Flags().StringVar(&f.maxSize, "max-size", "", "Only responses with at most this many body bytes (0 = empty bodies)")
+	cmd.Flags().StringArrayVar(&f.reqHeaders, "req-header", nil, "Request header present, or \"name:value-regex\" (repeatable)")
+	cmd.Flags().StringArrayVar(&f.respHeaders, "resp-header", nil, "Response header present, or \"name:value-regex\" (repeatable)")
+	cmd.Flags().StringVar(&f.bodyPattern, "body-pattern", "", "Filter by request body content (regex)")
+	cmd.Flags().StringVar(&f.respBodyPattern, "resp-body-pattern", "", "Filter by response body content (regex)")
+	cmd.Flags().StringVar(&f.since, "since", "", "Only requests at or after this time (5m, 2h, 1d, Unix time, RFC3339)")
+	cmd.Flags().StringVar(&f.until, "until", "", "Only requests at or before this time (same formats as --since)")
+}
+
+// options builds FilterOptions from the flags, applying presets.
+// Fails on an unparseable --since/--until or an unknown @group.
+func (f *requestFilterFlags) options() (store.FilterOptions, error) {
+	resourceTypes := parseCommaSeparated(f.resourceType)
+	methods := parseCommaSeparated(f.method)
+	statusRanges := []string{}
+
+	if f.api {
+		// Preset: API calls only (xhr/fetch)
+		resourceTypes = []string{"xmlhttprequest", "fetch"}
+	}
+
+	if f.interesting {
+		// Preset: Error responses + state-changing methods
+		statusRanges = []string{"4xx", "5xx"}
+		if len(methods) == 0 {
+			methods = []string{"POST", "PUT", "DELETE", "PATCH"}
+		}
+	}
+
+	if f.errors {
+		// Preset: Only error responses
+		statusRanges = []string{"4xx", "5xx"}
+	}
+
+	if f.mutations {
+		// Preset: Only state-changing methods
+		if len(methods) == 0 {
+			methods = []string{"POST", "PUT", "DELETE", "PATCH"}
+		}
+	}
+
+	since, err := parseSince(f.since)
+	if err != nil {
+		return store.FilterOptions{}, usageError("invalid --since: %s (use 5m, 2h, 1d, Unix time or RFC3339)", f.since)
+	}
+	until, err := parseSince(f.until)
+	if err != nil {
+		return store.FilterOptions{}, usageError("invalid --until: %s (use 5m, 2h, 1d, Unix time or RFC3339)", f.until)
+	}
+
+	domain, domains, err := resolveDomainFilter(f.domain)
+	if err != nil {
+		return store.FilterOptions{}, err
+	}
+
+	notDomain, notDomains, err := resolveDomainFilter(f.notDomain)
+	if err != nil {
+		return store.FilterOptions{}, err
+	}
+	if notDomain != "" {
+		notDomains = []string{notDomain}
+	}
+
+	opts := store.FilterOptions{
+		Domain:         domain,
+		Domains:        domains,
+		Methods:        methods, // Covers both -m GET and -m GET,POST
+		Status:         f.status,
+		StatusRange:    f.statusRange,
+		StatusRanges:   statusRanges,
+		ResourceTypes:  resourceTypes,
+		Pattern:        f.pattern,
+		PrimaryOnly:    f.primary,
+		ExcludeIgnored: !f.includeIgnored,
+		Since:          since,
+		Until:          until,
+	}
+	opts.ExcludeDomains = notDomains
+	opts.ExcludeMethods = parseCommaSeparated(f.notMethod)
+	opts.ExcludePattern = f.notPattern
+	opts.ExcludeResourceTypes = parseCommaSeparated(f.notType)
+	opts.ExcludeStatusRanges = parseCommaSeparated(f.notStatusRange)
+
+	if f.minSize != "" {
+		if opts.MinSize, err = parseByteSize(f.minSize); err != nil {
+			return store.FilterOptions{}, usageError("invalid --min-size: %v", err)
+		}
+	}
+	if f.maxSize != "" {
+		if opts.MaxSize, err = parseByteSize(f.maxSize); err != nil {
+			return store.FilterOptions{}, usageError("invalid --max-size: %v", err)
+		}
+		opts.MaxSizeSet = true
+	}
+
+	for _, spec := range f.reqHeaders {
+		match, err := store.ParseHeaderMatch(spec)
+		if err != nil {
+			return store.FilterOptions{}, usageError("invalid --req-header: %v", err)
+		}
+		opts.RequestHeaders = append(opts.RequestHeaders, match)
+	}
+	for _, spec := range f.respHeaders {
+		match, err := store.ParseHeaderMatch(spec)
+		if err != nil {
+			return store.FilterOptions{}, usageError("invalid --resp-header: %v", err)
+		}
+		opts.ResponseHeaders = append(opts.ResponseHeaders, match)
+	}
+
+	opts.BodyPattern = f.bodyPattern
+	opts.ResponseBodyPattern = f.respBodyPattern
+	return opts, nil
+}
+
+// parseByteSize parses "512", "10k", "1.5m", "2kb", "1MB", "1g" into bytes
+func parseByteSize(value string) (int, error) {
+	text := strings.ToLower(strings.TrimSpace(value))
+	text = strings.TrimSuffix(text, "b")
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(text, "k"):
+		multiplier = 1024
+		text = strings.TrimSuffix(text, "k")
+	case strings.HasSuffix(text, "m"):
+		multiplier = 1024 * 1024
+		text = strings.TrimSuffix(text, "m")
+	case strings.HasSuffix(text, "g"):
+		multiplier = 1024 * 1024 * 1024
+		text = strings.TrimSuffix(text, "g")
+	}
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a size (use 512, 10k, 2m)", value)
+	}
+	return int(n * multiplier), nil
+}
+
+// loadSourceStore loads requests from a saved session (saved != "") or from
+// live.json into a temp store with the persistent ignore/primary/mute lists
+// applied. A missing session is an error; when live.json is unreadable or
+// empty it reports that (see softFail) and returns nil.
+func loadSourceStore(saved string) (*store.Store, error) {
+	var tempStore *store.Store
+
+	if saved != "" {
+		s, err := store.Get()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load store: %w", err)
+		}
+
+		session := resolveSession(s, saved)
+		if session == nil {
+			return nil, notFoundError("session not found: %s", saved).withHint("Use 'rep sessions' to list available sessions")
+		}
+		tempStore = store.NewTempStore(session.Requests)
+	} else {
+		export, err := loadLiveMerged()
+		if err != nil {
+			return nil, softFail(liveReadError(err))
+		}
+		if len(export.Requests) == 0 {
+			return nil, softFail(emptyLiveError())
+		}
+		tempStore = store.NewTempStore(export.Requests)
+	}
+
+	applyPersistentLists(tempStore)
+	return tempStore, nil
+}
+
+// applyPersistentLists loads ignore/primary/mute lists from the persistent store
+func applyPersistentLists(tempStore *store.Store) {
+	if s, err := store.Get(); err == nil {
+		tempStore.PrimaryDomains = s.PrimaryDomains
+		tempStore.IgnoredDomains = s.IgnoredDomains
+		tempStore.MutedPaths = s.MutedPaths
+	}
+}
+
+// streamLiveSource filters live.json (and each browser's live-<id>.json)
+// while decoding, keeping only matches in memory (see store.FilterStream).
+// Returns ok=false, after reporting why (see softFail), when there is
+// nothing to filter.
+func streamLiveSource(opts store.FilterOptions, sopts store.StreamOptions) (res store.StreamResult, ok bool, err error) {
+	livePaths, err := store.GetLiveFilePaths()
+	if err != nil {
+		return res, false, fmt.Errorf("failed to get live path: %w", err)
+	}
+	if len(livePaths) == 1 {
+		if _, err := os.Stat(livePaths[0]); err != nil {
+			return res, false, softFail(liveReadError(err))
+		}
+	}
+
+	lists := store.NewStore()
+	applyPersistentLists(lists)
+	if opts.PrimaryOnly && len(lists.GetPrimaryDomains()) == 0 {
+		return res, false, softFail(noPrimaryError())
+	}
+
+	res, err = lists.FilterStreamFiles(livePaths, opts, sopts)
+	if err != nil {
+		return res, false, softFail(parseError("Could not read live.json: %v", err))
+	}
+	if res.Scanned == 0 {
+		return res, false, softFail(emptyLiveError())
+	}
+	return res, true, nil
+}
+
+// filterSource loads the source and applies opts. It returns nil (after
+// reporting why) when there is nothing to filter. live.json is filtered
+// while streaming, so only matching requests are held in memory.
+func filterSource(saved string, opts store.FilterOptions) ([]store.Request, error) {
+	if saved == "" {
+		res, ok, err := streamLiveSource(opts, store.StreamOptions{})
+		if err != nil || !ok {
+			return nil, err
+		}
+		return res.Requests, nil
+	}
+
+	tempStore, err := loadSourceStore(saved)
+	if err != nil || tempStore == nil {
+		return nil, err
+	}
+	if opts.PrimaryOnly && len(tempStore.GetPrimaryDomains()) == 0 {
+		return nil, softFail(noPrimaryError())
+	}
+	return tempStore.Filter(opts), nil
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// followPollInterval is how often a live file is re-read when its host
+// doesn't serve a socket
+const followPollInterval = 2 * time.Second
+
+// followRequests prints the current matches (honouring --limit/--offset/
+// --sort), then every newly captured request that matches, until interrupted.
+// A request is printed again when its response arrives after it was printed
+// (an "update" event from the host). Hosts started with -socket push
+// requests; other live files are polled.
+func followRequests(opts store.FilterOptions) error {
+	livePaths, err := store.GetLiveFilePaths()
+	if err != nil {
+		return fmt.Errorf("failed to get live path: %w", err)
+	}
+
+	emit := newFollowPrinter()
+	// Response status seen per request ID (0 before the response), so a
+	// later copy carrying the response is matched and printed again
+	seen := make(map[string]int)
+	start := time.Now().UnixMilli()
+
+	requests, _, ok, err := listSource(opts)
+	if err != nil {
+		return err
+	}
+	if ok {
+		for i := range requests {
+			seen[requests[i].ID] = responseStatus(&requests[i])
+			emit(&requests[i])
+		}
+	}
+
+	// New requests are matched one at a time, without paging
+	newOpts := opts
+	newOpts.Limit, newOpts.Offset, newOpts.SortBy = 0, 0, ""
+	lists := store.NewStore()
+	applyPersistentLists(lists)
+
+	incoming := make(chan store.Request, 256)
+	pushed := 0
+	for _, livePath := range livePaths {
+		prefix := ""
+		if name := store.LiveSourceName(livePath); name != "" {
+			prefix = name + ":"
+		}
+		if conn := dialHostSocket(livePath, start); conn != nil {
+			pushed++
+			go func(livePath, prefix string) {
+				// Keep following the file if the host goes away
+				last := readHostSocket(conn, prefix, start, incoming)
+				pollLiveFile(livePath, last, incoming)
+			}(livePath, prefix)
+		} else {
+			go pollLiveFile(livePath, start, incoming)
+		}
+	}
+	if pushed < len(livePaths) && !followMachineOutput() {
+		pterm.Info.Printf("Polling live.json every %s (start the host with REP_HOST_SOCKET=1 for push updates)\n", followPollInterval)
+	}
+
+	for req := range incoming {
+		status := responseStatus(&req)
+		if last, ok := seen[req.ID]; ok && (status == 0 || status == last) {
+			continue
+		}
+		seen[req.ID] = status
+		tmp := store.NewTempStore([]store.Request{req})
+		tmp.PrimaryDomains = lists.PrimaryDomains
+		tmp.IgnoredDomains = lists.IgnoredDomains
+		tmp.MutedPaths = lists.MutedPaths
+		if matched := tmp.Filter(newOpts); len(matched) == 1 {
+			emit(&matched[0])
+		}
+	}
+	return nil
+}
+
+// newFollowPrinter returns a function printing one request in the list
+// output mode. JSON output becomes NDJSON, since it is open-ended.
+func newFollowPrinter() func(req *store.Request) {
+	mode := store.OutputCompact
+	switch getOutputMode() {
+	case "meta":
+		mode = store.OutputMeta
+	case "full":
+		mode = store.OutputFull
+	}
+
+	switch getOutputMode() {
+	case "json", "ndjson":
+		nw := output.NewNDJSONWriter(os.Stdout)
+		cfg := truncateConfig()
+		return func(req *store.Request) {
+			nw.Write(output.FormatRequest(req, store.OutputJSON, cfg))
+			nw.Flush()
+		}
+	case "csv":
+		cw := csv.NewWriter(os.Stdout)
+		cw.Write(output.RequestCSVHeader)
+		cw.Flush()
+		return func(req *store.Request) {
+			cw.Write(output.RequestCSVRow(req))
+			cw.Flush()
+		}
+	}
+
+	if listLine && !listDetail && mode == store.OutputCompact {
+		return func(req *store.Request) {
+			status := 0
+			if req.Response != nil {
+				status = req.Response.Status
+			}
+			fmt.Printf("[%s] %s %s → %d\n", req.ID, req.Method, output.SanitizeText(req.URL), status)
+		}
+	}
+	return func(req *store.Request) {
+		printRequest(req, mode)
+		fmt.Println()
+	}
+}
+
+// dialHostSocket subscribes to the socket of the host writing livePath, if
+// it serves one. Returns nil when there is no usable socket.
+func dialHostSocket(livePath string, since int64) net.Conn {
+	status, err := store.LoadHostStatus(livePath)
+	if err != nil || status.Socket == "" || !status.Running || !store.ProcessAlive(status.PID) {
+		return nil
+	}
+	conn, err := net.DialTimeout("unix", status.Socket, 2*time.Second)
+	if err != nil {
+		return nil
+	}
+	frame, _ := sonic.Marshal(store.SocketRequest{Action: "subscribe", Since: since})
+	if err := store.WriteFrame(conn, frame); err != nil {
+		conn.Close()
+		return nil
+	}
+	return conn
+}
+
+// followMachineOutput reports whether follow output is for programs
+// (JSON/NDJSON/CSV), where status messages would corrupt the stream
+func followMachineOutput() bool {
+	switch getOutputMode() {
+	case "json", "ndjson", "csv":
+		return true
+	}
+	return false
+}
+
+// readHostSocket forwards requests pushed by a host until it disconnects.
+// Returns the latest capture time seen (at least since).
+func readHostSocket(conn net.Conn, prefix string, since int64, out chan<- store.Request) int64 {
+	defer conn.Close()
+	for {
+		data, err := store.ReadFrame(conn, store.MaxSocketFrame)
+		if err != nil {
+			if !followMachineOutput() {
+				pterm.Warning.Println("Host socket closed, polling live.json instead")
+			}
+			return since
+		}
+		var event store.SocketEvent
+		if err := sonic.Unmarshal(data, &event); err != nil {
+			continue
+		}
+		requests := event.Requests
+		if event.Request != nil {
+			requests = append(requests, *event.Request)
+		}
+		for _, req := range requests {
+			if req.Timestamp > since {
+				since = req.Timestamp
+			}
+			req.ID = prefix + req.ID
+			out <- req
+		}
+	}
+}
+
+// pollLiveFile re-reads livePath periodically, forwarding requests captured
+// since the last one seen (the caller drops repeats by ID). IDs come with
+// their session prefix (store.FilterStreamFiles).
+func pollLiveFile(livePath string, since int64, out chan<- store.Request) {
+	for {
+		time.Sleep(followPollInterval)
+		res, err := store.NewStore().FilterStreamFiles([]string{livePath}, store.FilterOptions{Since: since}, store.StreamOptions{})
+		if err != nil {
+			continue
+		}
+		for _, req := range res.Requests {
+			if req.Timestamp > since {
+				since = req.Timestamp
+			}
+			out <- req
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/graphql"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/replay"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphqlSaved      string
+	graphqlIntrospect bool
+	graphqlEndpoint   string
+	graphqlInsecure   bool
+	// graphql curl
+	graphqlCurlVars    []string
+	graphqlCurlUseVars bool
+	graphqlCurlSaved   string
+)
+
+// GraphQLEndpoint is a URL that served GraphQL operations
+type GraphQLEndpoint struct {
+	URL        string             `json:"url"`
+	Operations []GraphQLOperation `json:"operations"`
+	Schema     *GraphQLIntrospect `json:"introspection,omitempty"`
+}
+
+// GraphQLOperation is one named operation seen at an endpoint
+type GraphQLOperation struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Count     int      `json:"count"`
+	Variables []string `json:"variables,omitempty"` // Variable names
+	IDs       []string `json:"ids"`                 // Request IDs (first 3)
+}
+
+// GraphQLIntrospect is the outcome of --introspect for one endpoint
+type GraphQLIntrospect struct {
+	Status int             `json:"status,omitempty"`
+	Schema *graphql.Schema `json:"schema,omitempty"`
+	File   string          `json:"file,omitempty"` // Saved raw introspection response
+	Errors []string        `json:"errors,omitempty"`
+}
+
+var graphqlCmd = &cobra.Command{
+	Use:   "graphql",
+	Short: "List GraphQL endpoints and operations",
+	Long: `Find GraphQL traffic (JSON envelopes, batches, application/graphql bodies
+and GET ?query=) and list the operations seen at each endpoint.
+
+--introspect sends the standard introspection query to each endpoint through
+the replay engine, reusing the headers (auth included) of a captured
+operation. The raw schema is saved under the rep data directory
+(graphql/<host>.json) and the query, mutation and subscription fields are
+printed. Many production servers disable introspection; the server's error
+is shown instead.
+
+Use 'rep graphql curl <operation>' to turn a captured operation into a curl
+command, optionally with new variable values.
+
+Examples:
+  rep graphql                                  Endpoints and operations
+  rep graphql --introspect                     Fetch and summarise each schema
+  rep graphql --introspect --endpoint https://api.target.com/graphql
+  rep graphql curl GetOrg --var id=8           Replay GetOrg for another org
+  rep graphql --saved latest -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpoints, err := findGraphQLEndpoints(graphqlSaved)
+		if err != nil || endpoints == nil {
+			return err
+		}
+		if graphqlEndpoint != "" {
+			var kept []*GraphQLEndpoint
+			for _, e := range endpoints {
+				if e.URL == graphqlEndpoint {
+					kept = append(kept, e)
+				}
+			}
+			if len(kept) == 0 {
+				// An endpoint rep never captured: introspect it bare
+				kept = []*GraphQLEndpoint{{URL: graphqlEndpoint, Operations: []GraphQLOperation{}}}
+			}
+			endpoints = kept
+		}
+
+		if graphqlIntrospect {
+			for _, e := range endpoints {
+				e.Schema = introspectGraphQL(e, graphqlSaved)
+			}
+		}
+
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"endpoints": endpoints,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		printGraphQLEndpoints(endpoints)
+		return nil
+	},
+}
+
+var graphqlCurlCmd = &cobra.Command{
+	Use:   "curl <operation>",
+	Short: "Generate a curl command for a captured GraphQL operation",
+	Long: `Generate a replayable curl command for the most recent capture of a
+GraphQL operation (matched by name, case-insensitive, or by request ID).
+
+--var overrides a variable. Variables captured as strings stay strings;
+other values are parsed as JSON when valid (numbers, booleans, objects)
+and used as a string otherwise.
+
+Examples:
+  rep graphql curl GetOrg                      As captured
+  rep graphql curl GetOrg --var id=8           Another org
+  rep graphql curl UpdateProfile --var 'name=<script>' --use-vars
+  rep graphql curl GetOrg --var 'filter={"role":"admin"}'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, op, err := findGraphQLOperation(args[0], graphqlCurlSaved)
+		if err != nil || req == nil {
+			return err
+		}
+		if op.Variables == nil {
+			op.Variables = make(map[string]interface{})
+		}
+		for _, spec := range graphqlCurlVars {
+			name, value, ok := strings.Cut(spec, "=")
+			if !ok || name == "" {
+				return fmt.Errorf("invalid --var %q (use name=value)", spec)
+			}
+			// A captured string stays a string ("7" -> "8", not 8)
+			var parsed interface{} = value
+			if _, isString := op.Variables[name].(string); !isString {
+				if err := sonic.UnmarshalString(value, &parsed); err != nil {
+					parsed = value
+				}
+			}
+			op.Variables[name] = parsed
+		}
+
+		replayReq := graphqlRequest(req, op)
+		shell, err := currentShellDialect()
+		if err != nil {
+			return err
+		}
+		fmt.Println(generateCurl(replayReq, graphqlCurlUseVars, shell))
+		if graphqlCurlUseVars {
+			fmt.Println()
+			fmt.Printf("%s Run first: %s\n", shell.Comment, shell.LoadAuth)
+		}
+		return nil
+	},
+}
+
+// findGraphQLEndpoints groups captured GraphQL operations by endpoint URL
+// (query string dropped). Returns nil when the source is missing.
+func findGraphQLEndpoints(saved string) ([]*GraphQLEndpoint, error) {
+	requests, err := filterSource(saved, store.FilterOptions{ExcludeIgnored: true})
+	if err != nil || requests == nil {
+		return nil, err
+	}
+
+	byURL := make(map[string]*GraphQLEndpoint)
+	ops := make(map[string]map[string]*GraphQLOperation)
+	for i := range requests {
+		req := &requests[i]
+		for _, op := range graphql.Parse(req.Method, req.URL, store.HeaderFirst(req.Headers, "content-type"), req.Body) {
+			endpointURL := stripQuery(req.URL)
+			e := byURL[endpointURL]
+			if e == nil {
+				e = &GraphQLEndpoint{URL: endpointURL}
+				byURL[endpointURL] = e
+				ops[endpointURL] = make(map[string]*GraphQLOperation)
+			}
+			name := op.Name
+			if name == "" {
+				name = "(anonymous)"
+			}
+			key := op.Type + " " + name
+			o := ops[endpointURL][key]
+			if o == nil {
+				o = &GraphQLOperation{Name: name, Type: op.Type}
+				ops[endpointURL][key] = o
+			}
+			o.Count++
+			if len(o.IDs) < 3 {
+				o.IDs = append(o.IDs, req.ID)
+			}
+			for v := range op.Variables {
+				if !containsString(o.Variables, v) {
+					o.Variables = append(o.Variables, v)
+				}
+			}
+		}
+	}
+
+	endpoints := make([]*GraphQLEndpoint, 0, len(byURL))
+	for u, e := range byURL {
+		for _, o := range ops[u] {
+			sort.Strings(o.Variables)
+			e.Operations = append(e.Operations, *o)
+		}
+		sort.Slice(e.Operations, func(i, j int) bool {
+			if e.Operations[i].Type != e.Operations[j].Type {
+				return e.Operations[i].Type < e.Operations[j].Type
+			}
+			return e.Operations[i].Name < e.Operations[j].Name
+		})
+		endpoints = append(endpoints, e)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].URL < endpoints[j].URL })
+	return endpoints, nil
+}
+
+// findGraphQLOperation returns the latest request carrying the named
+// operation (or the request with that ID) and the operation itself
+func findGraphQLOperation(nameOrID, saved string) (*store.Request, graphql.Operation, error) {
+	requests, err := filterSource(saved, store.FilterOptions{})
+	if err != nil || requests == nil {
+		return nil, graphql.Operation{}, err
+	}
+	for i := len(requests) - 1; i >= 0; i-- {
+		req := &requests[i]
+		for _, op := range graphql.Parse(req.Method, req.URL, store.HeaderFirst(req.Headers, "content-type"), req.Body) {
+			if req.ID == nameOrID || strings.EqualFold(op.Name, nameOrID) {
+				return req, op, nil
+			}
+		}
+	}
+	return nil, graphql.Operation{}, notFoundError("no captured GraphQL operation named %s", nameOrID).withHint("Use 'rep graphql' to list captured operations")
+}
+
+// graphqlRequest rebuilds req to carry op alone (one operation, no batch),
+// keeping the original transport: GET query parameters or a JSON body
+func graphqlRequest(req *store.Request, op graphql.Operation) *store.Request {
+	out := *req
+	out.Headers = make(store.HeaderMap, len(req.Headers))
+	for k, v := range req.Headers {
+		out.Headers[k] = v
+	}
+	out.Response = nil
+	if strings.EqualFold(req.Method, "GET") {
+		if parsed, err := url.Parse(req.URL); err == nil {
+			q := parsed.Query()
+			for k, v := range graphql.QueryParams(op) {
+				q[k] = v
+			}
+			parsed.RawQuery = q.Encode()
+			out.URL = parsed.String()
+		}
+		return &out
+	}
+	out.Body = graphql.Body(op)
+	store.SetHeader(out.Headers, "Content-Type", "application/json")
+	return &out
+}
+
+// introspectGraphQL sends the introspection query to an endpoint, saving
+// the raw response when it contains a schema
+func introspectGraphQL(e *GraphQLEndpoint, saved string) *GraphQLIntrospect {
+	base := &store.Request{Method: "POST", URL: e.URL, Headers: store.HeaderMap{}}
+	if len(e.Operations) > 0 {
+		if req, _, err := findGraphQLOperation(e.Operations[0].IDs[0], saved); err == nil && req != nil {
+			base = req
+		}
+	}
+	sendReq, _ := resolveSendRequest(base, false)
+	sendReq.Method = "POST"
+	sendReq.URL = e.URL
+	sendReq = graphqlRequest(sendReq, graphql.Operation{Name: "IntrospectionQuery", Type: "query", Query: graphql.IntrospectionQuery})
+
+	result, err := replay.Send(context.Background(), sendReq, replay.Options{Insecure: graphqlInsecure})
+	if err != nil {
+		return &GraphQLIntrospect{Errors: []string{err.Error()}}
+	}
+	res := &GraphQLIntrospect{Status: result.Status}
+	res.Schema, res.Errors = graphql.ParseIntrospection(result.Body)
+	if res.Schema == nil {
+		return res
+	}
+	file, err := saveGraphQLSchema(e.URL, result.Body)
+	if err != nil {
+		res.Errors = append(res.Errors, fmt.Sprintf("schema not saved: %v", err))
+	}
+	res.File = file
+	return res
+}
+
+// saveGraphQLSchema writes an introspection response to
+// <store>/graphql/<host>.json
+func saveGraphQLSchema(endpointURL, body string) (string, error) {
+	storePath, err := store.GetStorePath()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(storePath, "graphql")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	file := filepath.Join(dir, safePathSegment(hostFromURL(endpointURL))+".json")
+	return file, os.WriteFile(file, []byte(body), 0644)
+}
+
+func stripQuery(rawURL string) string {
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		return rawURL[:i]
+	}
+	return rawURL
+}
+
+func printGraphQLEndpoints(endpoints []*GraphQLEndpoint) {
+	if len(endpoints) == 0 {
+		pterm.Info.Println("No GraphQL operations found in captured traffic")
+		return
+	}
+	for _, e := range endpoints {
+		pterm.DefaultSection.Printf("%s (%d operations)\n", output.SanitizeText(e.URL), len(e.Operations))
+		for _, o := range e.Operations {
+			vars := ""
+			if len(o.Variables) > 0 {
+				vars = pterm.FgGray.Sprintf("($%s)", strings.Join(o.Variables, ", $"))
+			}
+			label := o.Type
+			if o.Type == "mutation" {
+				label = pterm.FgYellow.Sprint(label)
+			}
+			fmt.Printf("  %-12s %s%s  %s\n", label, o.Name, vars, pterm.FgGray.Sprintf("x%d  %s", o.Count, strings.Join(o.IDs, " ")))
+		}
+		if e.Schema != nil {
+			printGraphQLIntrospect(e.Schema)
+		}
+	}
+	fmt.Println()
+	if len(endpoints) > 0 && len(endpoints[0].Operations) > 0 {
+		fmt.Printf("  Next: rep graphql curl %s --var name=value\n", endpoints[0].Operations[0].Name)
+	}
+}
+
+func printGraphQLIntrospect(r *GraphQLIntrospect) {
+	fmt.Println()
+	if r.Schema == nil {
+		status := ""
+		if r.Status != 0 {
+			status = fmt.Sprintf(" (HTTP %d)", r.Status)
+		}
+		pterm.Warning.Printf("Introspection failed%s: %s\n", status, output.SanitizeText(strings.Join(r.Errors, "; ")))
+		return
+	}
+	pterm.Success.Printf("Introspection enabled: %d types\n", r.Schema.Types)
+	for _, root := range []struct {
+		label  string
+		fields []string
+	}{
+		{"queries", r.Schema.Queries},
+		{"mutations", r.Schema.Mutations},
+		{"subscriptions", r.Schema.Subscriptions},
+	} {
+		if len(root.fields) > 0 {
+			fmt.Printf("  %-14s %s\n", root.label, strings.Join(root.fields, ", "))
+		}
+	}
+	if r.File != "" {
+		fmt.Printf("  %s\n", pterm.FgGray.Sprintf("Schema saved to %s", r.File))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(graphqlCmd)
+	graphqlCmd.Flags().StringVar(&graphqlSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	graphqlCmd.Flags().BoolVar(&graphqlIntrospect, "introspect", false, "Send an introspection query to each endpoint")
+	graphqlCmd.Flags().StringVar(&graphqlEndpoint, "endpoint", "", "Only this endpoint URL (may be one not captured)")
+	graphqlCmd.Flags().BoolVarP(&graphqlInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+
+	graphqlCmd.AddCommand(graphqlCurlCmd)
+	graphqlCurlCmd.Flags().StringArrayVar(&graphqlCurlVars, "var", nil, "Override a variable: name=value (repeatable)")
+	graphqlCurlCmd.Flags().BoolVar(&graphqlCurlUseVars, "use-vars", false, "Replace auth tokens with shell variables")
+	graphqlCurlCmd.Flags().StringVar(&graphqlCurlSaved, "saved", "", "Read from saved session (ID or 'latest')")
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	grepSaved       string
+	grepAllSessions bool
+	grepIn          string
+)
+
+// grepMatch is one matching request in JSON output
+type grepMatch struct {
+	Session string   `json:"session"`
+	ID      string   `json:"id"`
+	Method  string   `json:"method"`
+	Status  int      `json:"status,omitempty"`
+	URL     string   `json:"url"`
+	Matched []string `json:"matched"`
+}
+
+var grepCmd = &cobra.Command{
+	Use:   "grep <text>",
+	Short: "Find requests by URL token, header name, domain or parameter name",
+	Long: `Find requests whose URL tokens, header names (request or response),
+domain or parameter names (query and body) contain the text,
+case-insensitively. Each match lists the terms that matched.
+
+With --all-sessions every saved and archived session is searched through
+the search index (search-index.json next to store.json). The index is
+updated incrementally whenever the store is saved (rep save, rep import,
+rep archive, ...), so the search reads one small file instead of every
+session. Without it, the live capture (or --saved session) is scanned.
+
+Kinds (--in): url, header, domain, param (default all)
+
+Examples:
+  rep grep graphql                          Live capture
+  rep grep x-api-key --in header --all-sessions
+  rep grep redirect_uri --in param --all-sessions
+  rep grep admin --saved latest -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pattern := strings.TrimSpace(args[0])
+		if pattern == "" {
+			return fmt.Errorf("search text is empty")
+		}
+		kinds := parseCommaSeparated(strings.ToLower(grepIn))
+		for _, kind := range kinds {
+			if !containsString(store.SearchTermKinds, kind) {
+				return fmt.Errorf("invalid kind: %s (use %s)", kind, strings.Join(store.SearchTermKinds, ", "))
+			}
+		}
+
+		ix, err := searchSource(grepSaved, grepAllSessions)
+		if err != nil || ix == nil {
+			return err
+		}
+		hits := ix.Search(pattern, kinds)
+
+		if getOutputMode() == "json" {
+			matches := make([]grepMatch, 0, len(hits))
+			for _, h := range hits {
+				matches = append(matches, grepMatch{
+					Session: h.Session, ID: h.Request.ID, Method: h.Request.Method,
+					Status: h.Request.Status, URL: h.Request.URL, Matched: h.Matched,
+				})
+			}
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"pattern":  pattern,
+				"sessions": len(ix.Sessions),
+				"total":    len(matches),
+				"matches":  matches,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(hits) == 0 {
+			return softFail(emptyError("No requests match %q in %d session(s)", pattern, len(ix.Sessions)))
+		}
+		session := ""
+		sessions := 0
+		for _, h := range hits {
+			if h.Session != session {
+				session = h.Session
+				sessions++
+				label := session
+				if entry := ix.Sessions[session]; entry != nil && entry.Archived {
+					label += " (archived)"
+				}
+				pterm.DefaultSection.Println(label)
+			}
+			status := "-"
+			if h.Request.Status > 0 {
+				status = colorStatus(h.Request.Status, fmt.Sprint(h.Request.Status))
+			}
+			fmt.Printf("  %-12s %-7s %3s  %s\n", h.Request.ID, h.Request.Method, status, output.SanitizeText(truncateCell(h.Request.URL, 100)))
+			fmt.Printf("  %s\n", pterm.FgGray.Sprint(strings.Repeat(" ", 13)+strings.Join(h.Matched, ", ")))
+		}
+		fmt.Println()
+		pterm.Info.Printf("%d request(s) in %d session(s) match %q\n", len(hits), sessions, pattern)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(grepCmd)
+	grepCmd.Flags().StringVar(&grepSaved, "saved", "", "Search a saved session (ID or 'latest')")
+	grepCmd.Flags().BoolVar(&grepAllSessions, "all-sessions", false, "Search every saved and archived session through the index")
+	grepCmd.Flags().StringVar(&grepIn, "in", "", "Comma-separated kinds: url, header, domain, param (default all)")
+}
+
+// searchSource returns the index to query: the cross-session index with
+// allSessions, otherwise one built on the fly from the live capture or the
+// saved session
+func searchSource(saved string, allSessions bool) (*store.SearchIndex, error) {
+	if allSessions {
+		if saved != "" {
+			return nil, fmt.Errorf("--all-sessions and --saved can't be combined")
+		}
+		ix, err := store.LoadCurrentSearchIndex()
+		if err != nil {
+			return nil, err
+		}
+		if len(ix.Sessions) == 0 {
+			pterm.Info.Println("No saved sessions. Use 'rep save' to save the live capture")
+			return nil, nil
+		}
+		return ix, nil
+	}
+
+	requests, err := filterSource(saved, store.FilterOptions{})
+	if err != nil || requests == nil {
+		return nil, err
+	}
+	label := "live"
+	if saved != "" {
+		label = saved
+	}
+	ix := store.NewSearchIndex()
+	ix.Sessions[label] = store.IndexSession(0, requests)
+	return ix, nil
+}
@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage named domain groups (use as -d @name)",
+	Long: `Define named groups of domains, stored in store.json.
+
+A group can be used anywhere a domain filter is accepted by prefixing
+its name with @. -d also accepts a comma-separated mix of domains and
+groups.
+
+Examples:
+  rep group create target-apis api.target.com gateway.target.io
+  rep group add target-apis graphql.target.com
+  rep group remove target-apis gateway.target.io
+  rep group delete target-apis
+  rep group                               List groups
+  rep list -d @target-apis                Filter by group
+  rep list -d @target-apis,cdn.target.com Group plus a domain
+  rep curl --filter -d @target-apis --mutations`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGroupList()
+	},
+}
+
+var groupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List domain groups",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGroupList()
+	},
+}
+
+var groupCreateCmd = &cobra.Command{
+	Use:   "create <name> <domain...>",
+	Short: "Create a group (replaces an existing group with the same name)",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGroupUpdate("create", args, func(s *store.Store, name string, domains []string) int {
+			s.SetDomainGroup(name, domains)
+			members, _ := s.GetDomainGroup(name)
+			return len(members)
+		})
+	},
+}
+
+var groupAddCmd = &cobra.Command{
+	Use:   "add <name> <domain...>",
+	Short: "Add domains to a group (creates it if needed)",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGroupUpdate("add", args, func(s *store.Store, name string, domains []string) int {
+			return s.AddToDomainGroup(name, domains...)
+		})
+	},
+}
+
+var groupRemoveCmd = &cobra.Command{
+	Use:   "remove <name> <domain...>",
+	Short: "Remove domains from a group",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGroupUpdate("remove", args, func(s *store.Store, name string, domains []string) int {
+			return s.RemoveFromDomainGroup(name, domains...)
+		})
+	},
+}
+
+var groupDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a group",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, err := store.NormalizeGroupName(args[0])
+		if err != nil {
+			return err
+		}
+		s, err := store.Get()
+		if err != nil {
+			return fmt.Errorf("failed to load store: %w", err)
+		}
+		if !s.DeleteDomainGroup(name) {
+			return notFoundError("group not found: @%s", name)
+		}
+		if err := s.Save(); err != nil {
+			return fmt.Errorf("failed to save: %w", err)
+		}
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"action": "delete",
+				"group":  name,
+			}, "", "  ")
+			fmt.Println(string(out))
+		} else {
+			pterm.Success.Printf("Deleted group @%s\n", name)
+		}
+		return nil
+	},
+}
+
+// runGroupUpdate applies a membership change and reports the result
+func runGroupUpdate(action string, args []string, apply func(s *store.Store, name string, domains []string) int) error {
+	name, err := store.NormalizeGroupName(args[0])
+	if err != nil {
+		return err
+	}
+	s, err := store.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load store: %w", err)
+	}
+	if _, exists := s.GetDomainGroup(name); !exists && action == "remove" {
+		return notFoundError("group not found: @%s", name)
+	}
+
+	count := apply(s, name, args[1:])
+	if err := s.Save(); err != nil {
+		return fmt.Errorf("failed to save: %w", err)
+	}
+	members, _ := s.GetDomainGroup(name)
+
+	if getOutputMode() == "json" {
+		out, _ := sonic.MarshalIndent(map[string]interface{}{
+			"action":  action,
+			"group":   name,
+			"changed": count,
+			"domains": members,
+		}, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	switch action {
+	case "create":
+		pterm.Success.Printf("Created group @%s (%d domains)\n", name, count)
+	case "add":
+		pterm.Success.Printf("Added %d domain(s) to @%s\n", count, name)
+	case "remove":
+		pterm.Success.Printf("Removed %d domain(s) from @%s\n", count, name)
+	}
+	for _, d := range members {
+		fmt.Printf("  %s\n", d)
+	}
+	return nil
+}
+
+func runGroupList() error {
+	s, err := store.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load store: %w", err)
+	}
+	names := s.GetDomainGroupNames()
+
+	if getOutputMode() == "json" {
+		groups := make(map[string][]string, len(names))
+		for _, name := range names {
+			groups[name], _ = s.GetDomainGroup(name)
+		}
+		out, _ := sonic.MarshalIndent(groups, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(names) == 0 {
+		pterm.Info.Println("No domain groups. Use 'rep group create <name> <domain...>' to add one.")
+		return nil
+	}
+	pterm.DefaultSection.Println("Domain Groups")
+	for _, name := range names {
+		members, _ := s.GetDomainGroup(name)
+		pterm.Success.Printf("@%s (%d)\n", name, len(members))
+		for _, d := range members {
+			fmt.Printf("    %s\n", d)
+		}
+	}
+	return nil
+}
+
+// resolveDomainFilter expands a -d value: a single domain is returned as-is,
+// "@group" and comma-separated lists expand to a domain list.
+func resolveDomainFilter(value string) (string, []string, error) {
+	parts := parseCommaSeparated(value)
+	if len(parts) == 0 {
+		return "", nil, nil
+	}
+	if len(parts) == 1 && parts[0][0] != '@' {
+		return parts[0], nil, nil
+	}
+
+	var domains []string
+	for _, part := range parts {
+		if part[0] != '@' {
+			domains = append(domains, part)
+			continue
+		}
+		name, err := store.NormalizeGroupName(part)
+		if err != nil {
+			return "", nil, err
+		}
+		s, err := store.Get()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to load store: %w", err)
+		}
+		members, ok := s.GetDomainGroup(name)
+		if !ok {
+			return "", nil, notFoundError("unknown domain group: @%s (see 'rep group list')", name)
+		}
+		if len(members) == 0 {
+			return "", nil, fmt.Errorf("domain group @%s is empty", name)
+		}
+		domains = append(domains, members...)
+	}
+	return "", domains, nil
+}
+
+// domainScope is the set of registrable domains a -d value covers; an empty
+// scope covers every domain
+type domainScope map[string]bool
+
+// baseDomainScope resolves a -d value (a domain, a comma-separated list or
+// @group) for commands scoped to registrable domains
+func baseDomainScope(value string) (domainScope, error) {
+	domain, domains, err := resolveDomainFilter(value)
+	if err != nil {
+		return nil, err
+	}
+	if domain != "" {
+		domains = []string{domain}
+	}
+	scope := make(domainScope, len(domains))
+	for _, d := range domains {
+		scope[store.GetBaseDomain(strings.ToLower(d))] = true
+	}
+	return scope, nil
+}
+
+// covers reports whether host's registrable domain is in the scope
+func (s domainScope) covers(host string) bool {
+	return len(s) == 0 || s[store.GetBaseDomain(strings.ToLower(host))]
+}
+
+// String lists the scope's domains, sorted
+func (s domainScope) String() string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// hostList resolves a -d value to the hosts it names, for commands that
+// match exact hosts (nil when value is empty)
+func hostList(value string) ([]string, error) {
+	domain, domains, err := resolveDomainFilter(value)
+	if err != nil || domain == "" {
+		return domains, err
+	}
+	return []string{domain}, nil
+}
+
+// singleDomain checks a -d value for commands that act on one domain:
+// domain groups and lists are rejected instead of being read as a hostname
+func singleDomain(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "@") || strings.Contains(value, ",") {
+		return "", usageError("-d takes a single domain for this command, not a group or list: %s", value)
+	}
+	return value, nil
+}
+
+func init() {
+	rootCmd.AddCommand(groupCmd)
+	groupCmd.AddCommand(groupListCmd, groupCreateCmd, groupAddCmd, groupRemoveCmd, groupDeleteCmd)
+}
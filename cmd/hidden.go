@@ -0,0 +1,414 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/replay"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hiddenSaved       string
+	hiddenDomain      string
+	hiddenScheme      string
+	hiddenMaxSitemaps int
+	hiddenAll         bool
+	hiddenInsecure    bool
+)
+
+// hiddenListed caps the unvisited paths printed per source
+const hiddenListed = 40
+
+// HiddenReport is what robots.txt and the sitemaps reference, against the
+// capture
+type HiddenReport struct {
+	Domain    string         `json:"domain"`
+	Fetched   []HiddenSource `json:"fetched"`
+	Total     int            `json:"total"`
+	Unvisited int            `json:"unvisited"`
+	Paths     []HiddenPath   `json:"paths"` // Unvisited first
+}
+
+// HiddenSource is a fetched robots.txt or sitemap
+type HiddenSource struct {
+	URL      string `json:"url"`
+	Status   int    `json:"status,omitempty"`
+	Entries  int    `json:"entries"`            // Rules or URLs read from it
+	Sitemaps int    `json:"sitemaps,omitempty"` // Sitemaps a sitemap index lists
+	Error    string `json:"error,omitempty"`
+}
+
+// HiddenPath is one robots.txt rule or sitemap URL
+type HiddenPath struct {
+	Path     string   `json:"path"`             // Rule pattern, or sitemap URL
+	Source   string   `json:"source"`           // disallow, allow or sitemap
+	Agents   []string `json:"agents,omitempty"` // User-agents the rule applies to
+	Visited  bool     `json:"visited"`
+	Requests int      `json:"requests"`
+	IDs      []string `json:"ids,omitempty"` // Up to 5
+
+	match func(req *store.Request) bool
+}
+
+var hiddenCmd = &cobra.Command{
+	Use:   "hidden -d <domain>",
+	Short: "List robots.txt and sitemap paths never visited in the capture",
+	Long: `Fetch the domain's robots.txt and sitemaps, and report the paths they
+reference that the capture never visited: cheap leads for content the UI
+doesn't link to.
+
+  disallow  robots.txt Disallow rules: what the site asks crawlers to
+            avoid, often admin panels, staging and internal APIs
+  allow     robots.txt Allow rules
+  sitemap   URLs listed in sitemap.xml, the Sitemap: lines of robots.txt
+            and the sitemap indexes they point to (up to --max-sitemaps)
+
+Robots rules match like crawlers match them: by path prefix, with * and $
+wildcards. A sitemap URL is visited when a captured request has its host and
+path. robots.txt and sitemaps are fetched live through the replay engine;
+nothing else is requested.
+
+Examples:
+  rep hidden -d target.com
+  rep hidden -d target.com --all -o json       Visited paths too
+  rep hidden -d staging.target.com --scheme http`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if hiddenDomain == "" {
+			return usageError("-d is required").withHint("rep hidden -d target.com")
+		}
+		if _, err := singleDomain(hiddenDomain); err != nil {
+			return err
+		}
+		host := hiddenDomainHost()
+
+		requests, err := filterSource(hiddenSaved, store.FilterOptions{})
+		if err != nil || requests == nil {
+			return err
+		}
+
+		report := &HiddenReport{Domain: host, Paths: []HiddenPath{}}
+		paths := fetchHiddenPaths(report, hiddenScheme+"://"+host)
+		for i := range requests {
+			req := &requests[i]
+			if req.Domain == "" {
+				store.ComputeRequestFields(req)
+			}
+			for k := range paths {
+				p := &paths[k]
+				if p.match(req) {
+					p.Visited = true
+					p.Requests++
+					if len(p.IDs) < 5 {
+						p.IDs = append(p.IDs, req.ID)
+					}
+				}
+			}
+		}
+
+		sourceOrder := map[string]int{"disallow": 0, "allow": 1, "sitemap": 2}
+		sort.SliceStable(paths, func(i, j int) bool {
+			if paths[i].Visited != paths[j].Visited {
+				return !paths[i].Visited
+			}
+			return sourceOrder[paths[i].Source] < sourceOrder[paths[j].Source]
+		})
+		report.Total = len(paths)
+		for _, p := range paths {
+			if !p.Visited {
+				report.Unvisited++
+			}
+			if !p.Visited || hiddenAll {
+				report.Paths = append(report.Paths, p)
+			}
+		}
+
+		if getOutputMode() == "json" {
+			printJSON("hidden", report)
+			return nil
+		}
+		printHidden(report)
+		if report.Total == 0 {
+			return softFail(emptyError("No robots.txt rules or sitemap URLs found on %s", host))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hiddenCmd)
+	hiddenCmd.Flags().StringVar(&hiddenSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	hiddenCmd.Flags().StringVarP(&hiddenDomain, "domain", "d", "", "Host to fetch robots.txt and sitemaps from")
+	hiddenCmd.Flags().StringVar(&hiddenScheme, "scheme", "https", "URL scheme of the host (http or https)")
+	hiddenCmd.Flags().IntVar(&hiddenMaxSitemaps, "max-sitemaps", 20, "Sitemaps fetched at most, following sitemap indexes")
+	hiddenCmd.Flags().BoolVar(&hiddenAll, "all", false, "List visited paths too")
+	hiddenCmd.Flags().BoolVarP(&hiddenInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+}
+
+// fetchHiddenPaths reads robots.txt and the sitemaps of base, recording each
+// fetch in report
+func fetchHiddenPaths(report *HiddenReport, base string) []HiddenPath {
+	var paths []HiddenPath
+	var sitemaps []string
+
+	robotsURL := base + "/robots.txt"
+	source := HiddenSource{URL: robotsURL}
+	body, status, err := fetchHiddenText(robotsURL)
+	source.Status = status
+	switch {
+	case err != nil:
+		source.Error = err.Error()
+	case status == 200:
+		var rules []HiddenPath
+		rules, sitemaps = parseRobots(body)
+		source.Entries = len(rules)
+		paths = append(paths, rules...)
+	}
+	report.Fetched = append(report.Fetched, source)
+
+	if !containsString(sitemaps, base+"/sitemap.xml") {
+		sitemaps = append(sitemaps, base+"/sitemap.xml")
+	}
+	seenURL := make(map[string]bool)
+	for i := 0; i < len(sitemaps) && i < hiddenMaxSitemaps; i++ {
+		source := HiddenSource{URL: sitemaps[i]}
+		body, status, err := fetchHiddenText(sitemaps[i])
+		source.Status = status
+		if err != nil {
+			source.Error = err.Error()
+		} else if status == 200 {
+			locs, nested := parseSitemap(body)
+			source.Sitemaps = len(nested)
+			for _, loc := range nested {
+				if !containsString(sitemaps, loc) {
+					sitemaps = append(sitemaps, loc)
+				}
+			}
+			for _, loc := range locs {
+				if seenURL[loc] {
+					continue
+				}
+				seenURL[loc] = true
+				if p, ok := sitemapPath(loc); ok {
+					paths = append(paths, p)
+					source.Entries++
+				}
+			}
+		}
+		report.Fetched = append(report.Fetched, source)
+	}
+	if len(sitemaps) > hiddenMaxSitemaps {
+		jsonWarn("%d more sitemaps not fetched (raise --max-sitemaps)", len(sitemaps)-hiddenMaxSitemaps)
+	}
+	return paths
+}
+
+// fetchHiddenText GETs a URL, gunzipping .gz sitemaps
+func fetchHiddenText(target string) (string, int, error) {
+	req := &store.Request{Method: "GET", URL: target, Headers: store.HeaderMap{"Accept": {"*/*"}}}
+	res, err := replay.Send(context.Background(), req, replay.Options{FollowRedirects: true, Insecure: hiddenInsecure})
+	if err != nil {
+		return "", 0, err
+	}
+	body := res.Body
+	if strings.HasPrefix(body, "\x1f\x8b") {
+		if zr, err := gzip.NewReader(strings.NewReader(body)); err == nil {
+			if data, err := io.ReadAll(io.LimitReader(zr, replay.MaxBodySize)); err == nil {
+				body = string(data)
+			}
+		}
+	}
+	return body, res.Status, nil
+}
+
+// parseRobots reads the Allow and Disallow rules of robots.txt, with the
+// user-agents of their groups, and its Sitemap lines
+func parseRobots(body string) ([]HiddenPath, []string) {
+	var rules []HiddenPath
+	var sitemaps []string
+	index := make(map[string]int)
+	var agents []string
+	inRules := false // A rule ends the group's user-agent lines
+	for _, line := range strings.Split(body, "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if inRules {
+				agents, inRules = nil, false
+			}
+			agents = append(agents, value)
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue
+			}
+			k := key + " " + value
+			i, seen := index[k]
+			if !seen {
+				i = len(rules)
+				index[k] = i
+				rules = append(rules, HiddenPath{Path: value, Source: key, match: robotsMatcher(value)})
+			}
+			for _, a := range agents {
+				if !containsString(rules[i].Agents, a) {
+					rules[i].Agents = append(rules[i].Agents, a)
+				}
+			}
+		case "sitemap":
+			if value != "" && !containsString(sitemaps, value) {
+				sitemaps = append(sitemaps, value)
+			}
+		}
+	}
+	return rules, sitemaps
+}
+
+// robotsMatcher matches a request's path and query against a robots.txt
+// rule: a prefix, where * matches anything and a final $ anchors the end
+func robotsMatcher(rule string) func(req *store.Request) bool {
+	anchored := strings.HasSuffix(rule, "$")
+	rule = strings.TrimSuffix(rule, "$")
+	parts := strings.Split(rule, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	pattern := "^" + strings.Join(parts, ".*")
+	if anchored {
+		pattern += "$"
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return func(*store.Request) bool { return false }
+	}
+	host := strings.Split(hiddenDomainHost(), ":")[0]
+	return func(req *store.Request) bool {
+		return strings.EqualFold(hostFromURL(req.URL), host) && re.MatchString(req.Path)
+	}
+}
+
+// hiddenDomainHost is the host (and port) -d names, also given as a URL
+func hiddenDomainHost() string {
+	host := strings.ToLower(strings.TrimSuffix(hiddenDomain, "/"))
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return host
+}
+
+// sitemapDoc is a sitemap (urlset) or sitemap index
+type sitemapDoc struct {
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// parseSitemap returns the page URLs of a sitemap and the sitemaps an
+// index lists. Plain-text sitemaps list one URL per line.
+func parseSitemap(body string) (urls, sitemaps []string) {
+	var doc sitemapDoc
+	if err := xml.NewDecoder(strings.NewReader(body)).Decode(&doc); err == nil {
+		for _, u := range doc.URLs {
+			if loc := strings.TrimSpace(u.Loc); loc != "" {
+				urls = append(urls, loc)
+			}
+		}
+		for _, s := range doc.Sitemaps {
+			if loc := strings.TrimSpace(s.Loc); loc != "" {
+				sitemaps = append(sitemaps, loc)
+			}
+		}
+		return urls, sitemaps
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://") {
+			urls = append(urls, line)
+		}
+	}
+	return urls, nil
+}
+
+// sitemapPath is the entry for a sitemap URL, visited by any request to
+// the same host and path
+func sitemapPath(loc string) (HiddenPath, bool) {
+	u, err := url.Parse(loc)
+	if err != nil || u.Host == "" {
+		return HiddenPath{}, false
+	}
+	host, path := strings.ToLower(u.Hostname()), strings.TrimSuffix(u.EscapedPath(), "/")
+	return HiddenPath{Path: loc, Source: "sitemap", match: func(req *store.Request) bool {
+		if !strings.EqualFold(hostFromURL(req.URL), host) {
+			return false
+		}
+		p := req.Path
+		if i := strings.IndexAny(p, "?#"); i >= 0 {
+			p = p[:i]
+		}
+		return strings.TrimSuffix(p, "/") == path
+	}}, true
+}
+
+func printHidden(r *HiddenReport) {
+	pterm.DefaultSection.Printf("robots.txt and sitemap paths: %s\n", r.Domain)
+	for _, f := range r.Fetched {
+		switch {
+		case f.Error != "":
+			fmt.Printf("  %s  %s\n", output.SanitizeText(f.URL), pterm.FgRed.Sprint(f.Error))
+		case f.Status != 200:
+			fmt.Printf("  %s  %s\n", output.SanitizeText(f.URL), colorStatus(f.Status, fmt.Sprint(f.Status)))
+		case f.Sitemaps > 0:
+			fmt.Printf("  %s  index of %d sitemaps\n", output.SanitizeText(f.URL), f.Sitemaps)
+		default:
+			fmt.Printf("  %s  %d entries\n", output.SanitizeText(f.URL), f.Entries)
+		}
+	}
+	fmt.Printf("\n  %d of %d referenced paths never visited\n", r.Unvisited, r.Total)
+
+	for _, source := range []string{"disallow", "allow", "sitemap"} {
+		var listed []HiddenPath
+		for _, p := range r.Paths {
+			if p.Source == source {
+				listed = append(listed, p)
+			}
+		}
+		if len(listed) == 0 {
+			continue
+		}
+		fmt.Println()
+		pterm.DefaultSection.Printf("%s (%d)\n", source, len(listed))
+		for i, p := range listed {
+			if i == hiddenListed {
+				fmt.Printf("  ... %d more (-o json for all)\n", len(listed)-i)
+				break
+			}
+			line := output.SanitizeText(p.Path)
+			if p.Visited {
+				line = fmt.Sprintf("%s  %s", line, pterm.FgGray.Sprintf("visited ×%d (%s)", p.Requests, p.IDs[0]))
+			}
+			if len(p.Agents) > 0 && !(len(p.Agents) == 1 && p.Agents[0] == "*") {
+				line += pterm.FgGray.Sprint("  for " + output.SanitizeText(strings.Join(p.Agents, ", ")))
+			}
+			fmt.Printf("  %s\n", line)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Chunked transfer protocol.
+//
+// Chrome limits messages from the host to the extension to 1MB, and very
+// large messages from the extension are slow or dropped. Either side can
+// instead send a message as a serialized JSON string split into pieces:
+//
+//	{"action":"begin","transfer_id":"t1","total":3}
+//	{"action":"chunk","transfer_id":"t1","seq":0,"data":"{\"action\":\"sync\",..."}
+//	{"action":"chunk","transfer_id":"t1","seq":1,"data":"..."}
+//	{"action":"chunk","transfer_id":"t1","seq":2,"data":"...]}"}
+//	{"action":"end","transfer_id":"t1"}
+//
+// The host acks every step ({"success":true,"action":"chunk","seq":1,...}),
+// so the sender can resend a missing sequence number. Chunks may arrive in
+// any order and duplicates overwrite. On "end" the pieces are joined in seq
+// order and the result is handled like a normal message; the ack for "end"
+// is that message's response plus the transfer ID.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxOutboundMessage = 1000 * 1000        // Chrome's 1MB host-to-extension limit, with headroom
+	MaxTransferBytes   = 1024 * 1024 * 1024 // Refuse transfers beyond 1GB
+	MaxTransferChunks  = 64 * 1024          // Refuse a begin announcing more chunks (16KB each for 1GB)
+	TransferTimeout    = 5 * time.Minute    // Incomplete transfers are dropped after this
+
+	// chunkEnvelope is room left in an outbound chunk frame for everything
+	// but the escaped data string
+	chunkEnvelope = 256
+)
+
+// transfer is an in-progress chunked message
+type transfer struct {
+	chunks   []string
+	have     []bool
+	received int
+	bytes    int
+	started  time.Time
+}
+
+var (
+	transfers   = map[string]*transfer{}
+	outboundSeq int
+)
+
+// beginTransfer starts reassembly of a chunked message (caller holds mu)
+func beginTransfer(msg *Message) map[string]interface{} {
+	if msg.TransferID == "" || msg.Total <= 0 {
+		return transferError(msg, "begin needs transfer_id and total > 0")
+	}
+	if msg.Total > MaxTransferChunks {
+		return transferError(msg, fmt.Sprintf("total %d exceeds %d chunks", msg.Total, MaxTransferChunks))
+	}
+	expireTransfers()
+	transfers[msg.TransferID] = &transfer{
+		chunks:  make([]string, msg.Total),
+		have:    make([]bool, msg.Total),
+		started: time.Now(),
+	}
+	return map[string]interface{}{
+		"success":     true,
+		"action":      "begin",
+		"transfer_id": msg.TransferID,
+		"total":       msg.Total,
+	}
+}
+
+// receiveChunk stores one piece (caller holds mu)
+func receiveChunk(msg *Message) map[string]interface{} {
+	t := transfers[msg.TransferID]
+	if t == nil {
+		return transferError(msg, "unknown transfer (send begin first)")
+	}
+	if msg.Seq < 0 || msg.Seq >= len(t.chunks) {
+		return transferError(msg, fmt.Sprintf("seq %d out of range 0-%d", msg.Seq, len(t.chunks)-1))
+	}
+	if t.bytes+len(msg.Data) > MaxTransferBytes {
+		delete(transfers, msg.TransferID)
+		return transferError(msg, "transfer too large")
+	}
+
+	if !t.have[msg.Seq] {
+		t.have[msg.Seq] = true
+		t.received++
+	} else {
+		t.bytes -= len(t.chunks[msg.Seq])
+	}
+	t.chunks[msg.Seq] = msg.Data
+	t.bytes += len(msg.Data)
+
+	return map[string]interface{}{
+		"success":     true,
+		"action":      "chunk",
+		"transfer_id": msg.TransferID,
+		"seq":         msg.Seq,
+		"received":    t.received,
+		"total":       len(t.chunks),
+	}
+}
+
+// endTransfer reassembles and handles the message (caller holds mu)
+func endTransfer(msg *Message) map[string]interface{} {
+	t := transfers[msg.TransferID]
+	if t == nil {
+		return transferError(msg, "unknown transfer (send begin first)")
+	}
+	if t.received < len(t.chunks) {
+		var missing []int
+		for i, ok := range t.have {
+			if !ok {
+				missing = append(missing, i)
+			}
+		}
+		resp := transferError(msg, fmt.Sprintf("missing %d of %d chunks", len(missing), len(t.chunks)))
+		resp["missing"] = missing
+		return resp
+	}
+	delete(transfers, msg.TransferID)
+
+	var inner Message
+	if err := json.Unmarshal([]byte(strings.Join(t.chunks, "")), &inner); err != nil {
+		return transferError(msg, "invalid reassembled message: "+err.Error())
+	}
+	switch inner.Action {
+	case "begin", "chunk", "end":
+		return transferError(msg, "nested transfers are not supported")
+	}
+
+	resp := handleMessageLocked(&inner)
+	resp["transfer_id"] = msg.TransferID
+	return resp
+}
+
+// expireTransfers drops transfers that never finished (caller holds mu)
+func expireTransfers() {
+	for id, t := range transfers {
+		if time.Since(t.started) > TransferTimeout {
+			delete(transfers, id)
+		}
+	}
+}
+
+func transferError(msg *Message, text string) map[string]interface{} {
+	return map[string]interface{}{
+		"success":     false,
+		"action":      msg.Action,
+		"transfer_id": msg.TransferID,
+		"seq":         msg.Seq,
+		"error":       text,
+	}
+}
+
+// writeChunked sends an oversized outbound message as begin/chunk/end frames
+func writeChunked(content []byte) error {
+	outboundSeq++
+	id := fmt.Sprintf("host-%d-%d", os.Getpid(), outboundSeq)
+
+	pieces := chunkPieces(string(content), MaxOutboundMessage-chunkEnvelope)
+
+	frames := []interface{}{map[string]interface{}{"action": "begin", "transfer_id": id, "total": len(pieces)}}
+	for i, p := range pieces {
+		frames = append(frames, map[string]interface{}{"action": "chunk", "transfer_id": id, "seq": i, "data": p})
+	}
+	frames = append(frames, map[string]interface{}{"action": "end", "transfer_id": id})
+
+	for _, f := range frames {
+		data, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+		if len(data) > MaxOutboundMessage {
+			return fmt.Errorf("chunk frame of %d bytes exceeds %d", len(data), MaxOutboundMessage)
+		}
+		if err := writeFrame(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkPieces splits text into pieces whose JSON string encoding (without the
+// quotes) fits budget bytes. Sizes are counted after escaping: json.Marshal
+// turns < > & and control characters into 6-byte \u escapes, so a piece of
+// HTML can grow well past its raw length.
+func chunkPieces(text string, budget int) []string {
+	var pieces []string
+	start, size := 0, 0
+	for i, r := range text {
+		n := jsonEscapedLen(r)
+		if size+n > budget && i > start {
+			pieces = append(pieces, text[start:i])
+			start, size = i, 0
+		}
+		size += n
+	}
+	if start < len(text) {
+		pieces = append(pieces, text[start:])
+	}
+	return pieces
+}
+
+// jsonEscapedLen is the length of r inside a string encoded by json.Marshal
+func jsonEscapedLen(r rune) int {
+	switch {
+	case r == '"' || r == '\\' || r == '\n' || r == '\r' || r == '\t':
+		return 2
+	case r < 0x20 || r == '<' || r == '>' || r == '&' || r == '\u2028' || r == '\u2029' || r == utf8.RuneError:
+		return 6
+	}
+	return utf8.RuneLen(r)
+}
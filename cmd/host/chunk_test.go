@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestChunkFramesFit checks that chunk frames stay under Chrome's limit when
+// the payload is mostly characters json.Marshal escapes
+func TestChunkFramesFit(t *testing.T) {
+	body := strings.Repeat("<a href=\"x&y\">\x01é</a>\n", 200000)
+	content, err := json.Marshal(map[string]interface{}{"action": "sync", "body": body})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pieces := chunkPieces(string(content), MaxOutboundMessage-chunkEnvelope)
+	if len(pieces) < 2 {
+		t.Fatalf("got %d pieces, want several", len(pieces))
+	}
+	for i, p := range pieces {
+		frame, err := json.Marshal(map[string]interface{}{"action": "chunk", "transfer_id": "host-99999-99999", "seq": i, "data": p})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(frame) > MaxOutboundMessage {
+			t.Errorf("chunk %d frame is %d bytes, over %d", i, len(frame), MaxOutboundMessage)
+		}
+	}
+	if strings.Join(pieces, "") != string(content) {
+		t.Error("pieces don't join back to the message")
+	}
+}
+
+func TestBeginRejectsHugeTotal(t *testing.T) {
+	resp := beginTransfer(&Message{Action: "begin", TransferID: "t1", Total: MaxTransferChunks + 1})
+	if resp["success"] != false {
+		t.Errorf("begin with total %d accepted", MaxTransferChunks+1)
+	}
+	if _, ok := transfers["t1"]; ok {
+		t.Error("transfer was allocated")
+	}
+}
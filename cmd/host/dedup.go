@@ -0,0 +1,56 @@
+package main
+
+import "github.com/repplus/rep-cli/internal/store"
+
+// The extension re-sends requests after a reconnect ("sync" with its whole
+// list, or repeated "add"s). Requests are matched against live.json by
+// store.DedupIndex keys so re-sent ones update their earlier copy in place.
+
+var (
+	dedup             = store.NewDedupIndex()
+	duplicateRequests int64 // Re-sent requests merged into an existing entry (guarded by mu)
+)
+
+// indexRequest carries the fields the dedup keys are built from
+func indexRequest(r *Request) *store.Request {
+	return &store.Request{
+		ID:         r.ID,
+		OriginalID: r.OriginalID,
+		Method:     r.Method,
+		URL:        r.URL,
+		Body:       r.Body,
+		Timestamp:  r.Timestamp,
+	}
+}
+
+// rebuildDedupIndex re-indexes liveData.Requests after entries were removed
+// or reordered (caller holds mu)
+func rebuildDedupIndex() {
+	dedup = store.NewDedupIndex()
+	for i := range liveData.Requests {
+		dedup.Add(indexRequest(&liveData.Requests[i]), i)
+	}
+}
+
+// findDuplicate returns the position of an earlier copy of r (caller holds mu)
+func findDuplicate(r *Request) (int, bool) {
+	return dedup.Find(indexRequest(r))
+}
+
+// mergeRequest appends r, or replaces its earlier copy and reports true.
+// A re-sent copy without a response keeps the one already captured.
+// (caller holds mu)
+func mergeRequest(r Request) bool {
+	if i, ok := findDuplicate(&r); ok {
+		if r.Response == nil {
+			r.Response = liveData.Requests[i].Response
+		}
+		liveData.Requests[i] = r
+		dedup.Add(indexRequest(&r), i)
+		duplicateRequests++
+		return true
+	}
+	liveData.Requests = append(liveData.Requests, r)
+	dedup.Add(indexRequest(&r), len(liveData.Requests)-1)
+	return false
+}
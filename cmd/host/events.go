@@ -0,0 +1,61 @@
+// Streaming response capture.
+//
+// Server-Sent Events and chunked long-poll responses keep arriving after the
+// request was added, so the extension appends their chunks as they come:
+//
+//	{"action":"events","request_id":"h_abc","events":[{"timestamp":1700000000000,"data":"data: {...}\n\n"}]}
+//
+// Events are stored in arrival order on the request's response ("events"),
+// which 'rep sse' reads. Each request keeps at most MaxStreamEvents; older
+// events are dropped first.
+
+package main
+
+// MaxStreamEvents caps the chunks kept per streaming response
+const MaxStreamEvents = 5000
+
+// appendEvents adds streamed chunks to a captured request (caller holds mu)
+func appendEvents(msg *Message) map[string]interface{} {
+	if msg.RequestID == "" {
+		return map[string]interface{}{
+			"success": false,
+			"action":  "events",
+			"error":   "events needs request_id",
+		}
+	}
+
+	// Streams are usually recent, so search from the end
+	for i := len(liveData.Requests) - 1; i >= 0; i-- {
+		req := &liveData.Requests[i]
+		if req.ID != msg.RequestID {
+			continue
+		}
+		if req.Response == nil {
+			req.Response = &Response{}
+		}
+		events := append(req.Response.Events, msg.Events...)
+		dropped := 0
+		if over := len(events) - MaxStreamEvents; over > 0 {
+			events = events[over:]
+			dropped = over
+		}
+		req.Response.Events = events
+		saveLiveDataUnlocked()
+		response := map[string]interface{}{
+			"success": true,
+			"action":  "events",
+			"events":  len(events),
+		}
+		if dropped > 0 {
+			response["dropped"] = dropped
+		}
+		return response
+	}
+
+	// The request may have been filtered, rotated out or cleared
+	return map[string]interface{}{
+		"success": false,
+		"action":  "events",
+		"error":   "request not found: " + msg.RequestID,
+	}
+}
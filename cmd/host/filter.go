@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// Host-side filtering drops noise (analytics beacons, health checks) before
+// it reaches live.json. Rules come from two places:
+//   - store.json's ignore and mute lists, when enabled with -filter,
+//     REP_HOST_FILTER=1 or {"action":"configure","filter_store":true}.
+//     The lists are reloaded when store.json changes, so 'rep ignore' and
+//     'rep mute' apply without restarting the host.
+//   - "drop_domains" / "drop_paths" sent in a "configure" message, which
+//     replace the previous message-supplied rules.
+//
+// Dropped requests are gone for good (unlike CLI filters), so this is opt-in.
+
+// storeFilterInterval limits how often store.json's mtime is checked
+const storeFilterInterval = time.Second
+
+// Filter state (guarded by mu)
+var (
+	filterStore        bool
+	storeFilter        store.HostFilter
+	storeFilterMod     time.Time
+	storeFilterChecked time.Time
+	messageFilter      store.HostFilter
+	filteredRequests   int64
+)
+
+// refreshStoreFilter reloads the ignore/mute lists if store.json changed
+// (caller holds mu)
+func refreshStoreFilter(force bool) {
+	if !filterStore {
+		return
+	}
+	now := time.Now()
+	if !force && now.Sub(storeFilterChecked) < storeFilterInterval {
+		return
+	}
+	storeFilterChecked = now
+
+	path, err := store.GetStoreFilePath()
+	if err != nil {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		storeFilter = store.HostFilter{}
+		storeFilterMod = time.Time{}
+		return
+	}
+	if !force && info.ModTime().Equal(storeFilterMod) {
+		return
+	}
+	f, err := store.LoadHostFilter()
+	if err != nil {
+		lastError = err.Error()
+		os.Stderr.WriteString("Warning: could not load filter lists: " + err.Error() + "\n")
+		return
+	}
+	storeFilter = f
+	storeFilterMod = info.ModTime()
+}
+
+// dropRequest reports whether req is filtered out, counting it if so
+// (caller holds mu)
+func dropRequest(req *Request) bool {
+	refreshStoreFilter(false)
+	if storeFilter.Drops(req.URL) || messageFilter.Drops(req.URL) {
+		filteredRequests++
+		return true
+	}
+	return false
+}
+
+// filterRequests removes filtered requests in place (caller holds mu)
+func filterRequests(requests []Request) []Request {
+	kept := requests[:0]
+	for i := range requests {
+		if !dropRequest(&requests[i]) {
+			kept = append(kept, requests[i])
+		}
+	}
+	return kept
+}
+
+// filterRules is the number of active rules (caller holds mu)
+func filterRules() int {
+	return storeFilter.Rules() + messageFilter.Rules()
+}
+
+// configureFilter applies the filter fields of a "configure" message
+// (caller holds mu). Returns an error message, or "".
+func configureFilter(msg *Message) string {
+	if msg.DropDomains != nil || msg.DropPaths != nil {
+		f, err := store.ParseHostFilter(msg.DropDomains, msg.DropPaths)
+		if err != nil {
+			return err.Error()
+		}
+		messageFilter = f
+	}
+	if msg.FilterStore != nil {
+		filterStore = *msg.FilterStore
+		if filterStore {
+			refreshStoreFilter(true)
+		} else {
+			storeFilter = store.HostFilter{}
+		}
+	}
+	return ""
+}
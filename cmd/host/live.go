@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// Each host writes its own live-<id>.json (listed in live-index.json) so
+// several browsers or Chrome profiles can capture at the same time without
+// overwriting each other. The CLI merges all of them, or reads one with
+// --session. Setting REPLIVE_PATH keeps the old single-file behaviour.
+
+var (
+	profile     string             // Label from -profile, REP_PROFILE or a "configure" message
+	liveSession *store.LiveSession // nil when writing a fixed REPLIVE_PATH file
+)
+
+// registerLiveSession claims a namespaced live file and points dataPath at
+// it. Falls back to live.json if the index can't be written.
+func registerLiveSession() {
+	entry, err := store.RegisterLiveSession(profile, generateSessionID(), os.Getpid())
+	if err != nil {
+		os.Stderr.WriteString("Warning: could not register live session, using live.json: " + err.Error() + "\n")
+		return
+	}
+	path, err := store.LiveSessionPath(entry)
+	if err != nil {
+		return
+	}
+	liveSession = &entry
+	dataPath = path
+}
+
+// setProfile labels this host's live session so 'rep --session <profile>'
+// finds it (caller holds mu)
+func setProfile(name string) {
+	profile = name
+	if liveSession == nil {
+		return
+	}
+	liveSession.Profile = name
+	id := liveSession.ID
+	if err := store.UpdateLiveSession(id, func(s *store.LiveSession) { s.Profile = name }); err != nil {
+		lastError = err.Error()
+	}
+}
+
+// markLiveSessionStopped keeps the session listed (and resumable by the next
+// host with the same profile) after a disconnect with keep-on-disconnect
+func markLiveSessionStopped() {
+	store.UpdateLiveSession(liveSession.ID, func(s *store.LiveSession) { s.Running = false })
+}
+
+// unregisterLiveSession removes this host's live file, status sidecar and
+// index entry after a disconnect
+func unregisterLiveSession() {
+	if unlock, err := store.LockFile(dataPath, true); err == nil {
+		os.Remove(dataPath)
+		unlock()
+	}
+	os.Remove(dataPath + ".lock")
+	statusPath := store.GetHostStatusPath(dataPath)
+	os.Remove(statusPath)
+	os.Remove(statusPath + ".lock")
+	if err := store.RemoveLiveSession(liveSession.ID); err != nil {
+		os.Stderr.WriteString("Warning: could not update live index: " + err.Error() + "\n")
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 	"time"
 
@@ -20,11 +19,13 @@ import (
 
 const (
 	LiveFileName    = "live.json"
-	MaxLiveRequests = 10000 // Prevent unbounded memory growth
+	MaxLiveRequests = 10000 // Default request cap (prevents unbounded memory growth)
 )
 
 var (
 	keepOnDisconnect bool // If true, don't clear live.json when extension disconnects
+	autoSave         store.AutoSaveConfig
+	maxLiveRequests  = MaxLiveRequests // Changeable via the "config" action
 )
 
 // Message from extension
@@ -32,7 +33,29 @@ type Message struct {
 	Type     string    `json:"type"`
 	Requests []Request `json:"requests,omitempty"`
 	Request  *Request  `json:"request,omitempty"`
-	Action   string    `json:"action,omitempty"` // "add", "clear", "sync"
+	Action   string    `json:"action,omitempty"` // "add", "clear", "sync", "ping", "stats", "config"/"configure", "begin", "chunk", "end", "events"
+
+	// "config" action fields (nil = unchanged)
+	MaxRequests      *int  `json:"max_requests,omitempty"`
+	KeepOnDisconnect *bool `json:"keep_on_disconnect,omitempty"`
+
+	// Host-side filter fields (see filter.go)
+	FilterStore *bool    `json:"filter_store,omitempty"`
+	DropDomains []string `json:"drop_domains,omitempty"`
+	DropPaths   []string `json:"drop_paths,omitempty"`
+
+	// Browser profile label for this host's live session (see live.go)
+	Profile *string `json:"profile,omitempty"`
+
+	// "events" action fields (see events.go)
+	RequestID string              `json:"request_id,omitempty"`
+	Events    []store.StreamEvent `json:"events,omitempty"`
+
+	// Chunked transfer fields (see chunk.go)
+	TransferID string `json:"transfer_id,omitempty"`
+	Seq        int    `json:"seq,omitempty"`
+	Total      int    `json:"total,omitempty"` // Chunk count (begin)
+	Data       string `json:"data,omitempty"`  // Slice of the serialized message (chunk)
 }
 
 // Request matches extension export format
@@ -49,12 +72,22 @@ type Request struct {
 	Response         *Response       `json:"response,omitempty"`
 	ResponseEncoding string          `json:"response_encoding,omitempty"`
 	Timestamp        int64           `json:"timestamp"`
+
+	// Timing and transport details, when the extension provides them
+	DurationMs    float64        `json:"duration_ms,omitempty"`    // Request start to response end
+	RequestBytes  int64          `json:"request_bytes,omitempty"`  // Bytes sent, headers included
+	ResponseBytes int64          `json:"response_bytes,omitempty"` // Bytes received on the wire (compressed)
+	RemoteIP      string         `json:"remote_ip,omitempty"`
+	Protocol      string         `json:"protocol,omitempty"` // e.g. http/1.1, h2, h3
+	TLS           *store.TLSInfo `json:"tls,omitempty"`
 }
 
 type Response struct {
 	Status  int             `json:"status"`
 	Headers store.HeaderMap `json:"headers,omitempty"`
 	Body    string          `json:"body,omitempty"`
+
+	Events []store.StreamEvent `json:"events,omitempty"` // Streaming chunks (see events.go)
 }
 
 // LiveData is the file format
@@ -74,32 +107,72 @@ var (
 func main() {
 	// Parse flags (for manual testing)
 	flag.BoolVar(&keepOnDisconnect, "keep", false, "Keep live.json data when extension disconnects")
+	flag.IntVar(&autoSave.MaxRequests, "autosave-requests", 0, "Snapshot live.json into a saved session at N requests")
+	flag.DurationVar(&autoSave.MaxAge, "autosave-age", 0, "Snapshot live.json once its oldest request is this old")
+	flag.BoolVar(&filterStore, "filter", false, "Drop requests matching the ignore/mute lists before writing")
+	flag.StringVar(&profile, "profile", "", "Browser profile label for this host's live session")
+	flag.BoolVar(&serveSocket, "socket", false, "Serve captured requests on a local socket for 'rep list --follow'")
 	flag.Parse()
 
 	// Environment variable override (useful since native messaging can't pass args)
 	if os.Getenv("REP_KEEP_ON_DISCONNECT") == "1" {
 		keepOnDisconnect = true
 	}
+	if os.Getenv("REP_HOST_FILTER") == "1" {
+		filterStore = true
+	}
+	if env := os.Getenv("REP_PROFILE"); env != "" {
+		profile = env
+	}
+	if os.Getenv("REP_HOST_SOCKET") == "1" {
+		serveSocket = true
+	}
+	if envCfg, err := store.AutoSaveConfigFromEnv(); err != nil {
+		os.Stderr.WriteString("Warning: " + err.Error() + "\n")
+	} else {
+		if envCfg.MaxRequests > 0 {
+			autoSave.MaxRequests = envCfg.MaxRequests
+		}
+		if envCfg.MaxAge > 0 {
+			autoSave.MaxAge = envCfg.MaxAge
+		}
+	}
+	// Snapshot before the hard cap would start dropping requests
+	if autoSave.MaxRequests > maxLiveRequests {
+		autoSave.MaxRequests = maxLiveRequests
+	}
 
-	// Setup data path
+	// Setup data path (live-<id>.json unless REPLIVE_PATH pins one file)
 	dataPath = getDataPath()
 	ensureDir(filepath.Dir(dataPath))
+	if os.Getenv("REPLIVE_PATH") == "" {
+		registerLiveSession()
+	}
 
 	// Load existing data
 	liveData = loadLiveData()
+	rebuildDedupIndex()
+	refreshStoreFilter(true)
+	if serveSocket {
+		startSocket()
+		defer stopSocket()
+	}
 
 	// Generate session ID only if starting fresh (preserve on reconnect)
 	if liveData.SessionID == "" || len(liveData.Requests) == 0 {
 		liveData.SessionID = generateSessionID()
 	}
+	writeStatus(true)
+	stopStatusWriter := startStatusWriter()
 
 	// Process messages from Chrome
 	for {
 		msg, err := readMessage()
 		if err != nil {
 			if err == io.EOF {
-				// Extension disconnected - clear live.json unless --keep
-				if !keepOnDisconnect {
+				// Extension disconnected - clear live data unless --keep
+				// (a namespaced live file is removed after the loop)
+				if !keepOnDisconnect && liveSession == nil {
 					clearLiveData()
 				}
 				break
@@ -110,6 +183,16 @@ func main() {
 		response := handleMessage(msg)
 		writeMessage(response)
 	}
+	stopStatusWriter()
+
+	if liveSession != nil && !keepOnDisconnect {
+		unregisterLiveSession()
+		return
+	}
+	if liveSession != nil {
+		markLiveSessionStopped()
+	}
+	writeStatus(false)
 }
 
 func generateSessionID() string {
@@ -123,6 +206,7 @@ func clearLiveData() {
 	liveData.Requests = []Request{}
 	liveData.ExportedAt = time.Now().Format(time.RFC3339)
 	liveData.SessionID = ""
+	rebuildDedupIndex()
 
 	content, err := json.MarshalIndent(liveData, "", "  ")
 	if err != nil {
@@ -131,39 +215,22 @@ func clearLiveData() {
 		return
 	}
 
-	if err := os.WriteFile(dataPath, content, 0644); err != nil {
+	if err := store.WriteFileAtomic(dataPath, content, 0644); err != nil {
 		os.Stderr.WriteString("Error writing live.json: " + err.Error() + "\n")
 	}
 }
 
 func getDataPath() string {
 	if override := os.Getenv("REPLIVE_PATH"); override != "" {
-		path, err := expandHomePath(override)
+		path, err := store.ExpandHomePath(override)
 		if err == nil {
 			return path
 		}
 	}
-	// Check XDG_DATA_HOME
-	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
-		return filepath.Join(xdg, "rep-cli", LiveFileName)
-	}
-	// Default ~/.local/share/rep-cli/
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".local", "share", "rep-cli", LiveFileName)
-}
-
-func expandHomePath(path string) (string, error) {
-	if path == "~" || strings.HasPrefix(path, "~/") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", err
-		}
-		if path == "~" {
-			return home, nil
-		}
-		return filepath.Join(home, path[2:]), nil
-	}
-	return path, nil
+	// Same directory as the CLI: XDG_DATA_HOME, %LOCALAPPDATA% on Windows,
+	// else ~/.local/share/rep-cli/
+	storePath, _ := store.GetStorePath()
+	return filepath.Join(storePath, LiveFileName)
 }
 
 func ensureDir(dir string) {
@@ -176,7 +243,7 @@ func loadLiveData() *LiveData {
 		Requests: []Request{},
 	}
 
-	content, err := os.ReadFile(dataPath)
+	content, err := store.ReadFileLocked(dataPath)
 	if err != nil {
 		return data
 	}
@@ -207,48 +274,132 @@ func saveLiveDataUnlocked() error {
 		return err
 	}
 
-	return os.WriteFile(dataPath, content, 0644)
+	// Temp file + rename under the shared lock, so CLI readers never see a
+	// half-written live.json
+	if err := store.WriteFileAtomic(dataPath, content, 0644); err != nil {
+		lastError = err.Error()
+		return err
+	}
+	bytesWritten += int64(len(content))
+	return nil
 }
 
 func handleMessage(msg *Message) map[string]interface{} {
 	// Acquire mutex for all data modifications to prevent race conditions
 	mu.Lock()
 	defer mu.Unlock()
+	messagesHandled++
+	statusDirty = true
+	return handleMessageLocked(msg)
+}
 
+// handleMessageLocked dispatches a message (caller holds mu)
+func handleMessageLocked(msg *Message) map[string]interface{} {
 	switch msg.Action {
+	case "begin":
+		return beginTransfer(msg)
+	case "chunk":
+		return receiveChunk(msg)
+	case "end":
+		return endTransfer(msg)
+	case "stats":
+		return handleStats()
+	case "events":
+		return appendEvents(msg)
+	case "config", "configure":
+		if msg.Profile != nil {
+			setProfile(*msg.Profile)
+		}
+		return handleConfig(msg)
 	case "add":
 		if msg.Request != nil {
+			if dropRequest(msg.Request) {
+				return map[string]interface{}{
+					"success":  true,
+					"action":   "add",
+					"count":    len(liveData.Requests),
+					"filtered": true,
+				}
+			}
+			// A request re-sent after a reconnect updates its earlier copy
+			if i, ok := findDuplicate(msg.Request); ok {
+				mergeRequest(*msg.Request)
+				saveLiveDataUnlocked()
+				merged := liveData.Requests[i]
+				broadcast(socketEvent{Type: "update", Request: &merged})
+				return map[string]interface{}{
+					"success":   true,
+					"action":    "add",
+					"count":     len(liveData.Requests),
+					"duplicate": true,
+				}
+			}
+			var autoSavedID string
+			if len(liveData.Requests) > 0 && autoSave.Due(len(liveData.Requests), liveData.Requests[0].Timestamp, time.Now()) {
+				autoSavedID = snapshotRequests(liveData.Requests)
+				if autoSavedID != "" {
+					autoSaves++
+					liveData.Requests = []Request{}
+					liveData.SessionID = generateSessionID()
+					rebuildDedupIndex()
+					broadcast(socketEvent{Type: "clear"})
+				}
+			}
 			// Rotate old requests if we hit the limit (prevent memory leak)
-			if len(liveData.Requests) >= MaxLiveRequests {
+			if len(liveData.Requests) >= maxLiveRequests {
 				// Remove oldest 10% to make room
-				removeCount := MaxLiveRequests / 10
+				removeCount := maxLiveRequests / 10
+				if removeCount < 1 {
+					removeCount = 1
+				}
 				liveData.Requests = liveData.Requests[removeCount:]
+				recordDrop(removeCount)
+				rebuildDedupIndex()
 			}
-			liveData.Requests = append(liveData.Requests, *msg.Request)
+			mergeRequest(*msg.Request)
 			saveLiveDataUnlocked() // Already holding lock
-			return map[string]interface{}{
+			broadcast(socketEvent{Type: "add", Request: msg.Request})
+			response := map[string]interface{}{
 				"success": true,
 				"action":  "add",
 				"count":   len(liveData.Requests),
 			}
+			if autoSavedID != "" {
+				response["autosaved"] = autoSavedID
+			}
+			return response
 		}
 	case "sync":
 		if msg.Requests != nil {
-			// Truncate if incoming sync exceeds limit
-			if len(msg.Requests) > MaxLiveRequests {
-				msg.Requests = msg.Requests[len(msg.Requests)-MaxLiveRequests:]
+			// Merge rather than replace: requests already in live.json (sent
+			// before a reconnect) are updated in place, new ones appended
+			added, duplicates := 0, 0
+			for _, r := range filterRequests(msg.Requests) {
+				if mergeRequest(r) {
+					duplicates++
+				} else {
+					added++
+				}
+			}
+			// Trim the oldest if the merged list exceeds the limit
+			if over := len(liveData.Requests) - maxLiveRequests; over > 0 {
+				trimOldest(over)
 			}
-			liveData.Requests = msg.Requests
 			saveLiveDataUnlocked()
+			broadcast(socketEvent{Type: "sync", Requests: liveData.Requests})
 			return map[string]interface{}{
-				"success": true,
-				"action":  "sync",
-				"count":   len(liveData.Requests),
+				"success":    true,
+				"action":     "sync",
+				"count":      len(liveData.Requests),
+				"added":      added,
+				"duplicates": duplicates,
 			}
 		}
 	case "clear":
 		liveData.Requests = []Request{}
+		rebuildDedupIndex()
 		saveLiveDataUnlocked()
+		broadcast(socketEvent{Type: "clear"})
 		return map[string]interface{}{
 			"success": true,
 			"action":  "clear",
@@ -268,6 +419,39 @@ func handleMessage(msg *Message) map[string]interface{} {
 	}
 }
 
+// trimOldest removes the n oldest requests; with auto-save on, they are
+// kept as a saved session instead of dropped (caller holds mu)
+func trimOldest(n int) {
+	if autoSave.Enabled() && snapshotRequests(liveData.Requests[:n]) != "" {
+		autoSaves++
+	} else {
+		recordDrop(n)
+	}
+	liveData.Requests = liveData.Requests[n:]
+	rebuildDedupIndex()
+}
+
+// snapshotRequests saves requests into store.json as an auto-save session.
+// Returns the session ID, or "" on failure (logged to stderr).
+func snapshotRequests(requests []Request) string {
+	content, err := json.Marshal(requests)
+	if err != nil {
+		os.Stderr.WriteString("Error encoding auto-save: " + err.Error() + "\n")
+		return ""
+	}
+	var converted []store.Request
+	if err := json.Unmarshal(content, &converted); err != nil {
+		os.Stderr.WriteString("Error encoding auto-save: " + err.Error() + "\n")
+		return ""
+	}
+	session, err := store.SnapshotSession(store.AutoSaveNote, converted)
+	if err != nil {
+		os.Stderr.WriteString("Error writing auto-save session: " + err.Error() + "\n")
+		return ""
+	}
+	return session.ID
+}
+
 // Native messaging protocol: 4-byte length prefix (little-endian) + JSON
 func readMessage() (*Message, error) {
 	// Read length (4 bytes, little-endian)
@@ -295,7 +479,14 @@ func writeMessage(msg interface{}) error {
 	if err != nil {
 		return err
 	}
+	if len(content) > MaxOutboundMessage {
+		return writeChunked(content)
+	}
+	return writeFrame(content)
+}
 
+// writeFrame writes one length-prefixed native messaging frame
+func writeFrame(content []byte) error {
 	// Write length
 	length := uint32(len(content))
 	if err := binary.Write(os.Stdout, binary.LittleEndian, length); err != nil {
@@ -303,6 +494,6 @@ func writeMessage(msg interface{}) error {
 	}
 
 	// Write message
-	_, err = os.Stdout.Write(content)
+	_, err := os.Stdout.Write(content)
 	return err
 }
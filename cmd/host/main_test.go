@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// TestAddKeepsTLS checks that the tls object sent by the extension survives
+// into live.json
+func TestAddKeepsTLS(t *testing.T) {
+	dataPath = filepath.Join(t.TempDir(), LiveFileName)
+	liveData = &LiveData{Version: "1.0", Requests: []Request{}}
+	rebuildDedupIndex()
+
+	raw := `{"action":"add","request":{"id":"r1","method":"GET","url":"https://example.com/",
+		"timestamp":1700000000000,"protocol":"h2",
+		"tls":{"version":"TLS 1.3","cipher":"TLS_AES_128_GCM_SHA256","alpn":"h2",
+			"subject":"example.com","issuer":"Test CA","sans":["example.com","www.example.com"],
+			"valid_from":1700000000,"valid_to":1800000000}}}`
+	var msg Message
+	if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if resp := handleMessage(&msg); resp["success"] != true {
+		t.Fatalf("add failed: %v", resp)
+	}
+
+	content, err := os.ReadFile(dataPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var export store.Export
+	if err := json.Unmarshal(content, &export); err != nil {
+		t.Fatal(err)
+	}
+	if len(export.Requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(export.Requests))
+	}
+	got := export.Requests[0].TLS
+	if got == nil {
+		t.Fatal("tls dropped from live.json")
+	}
+	if got.Version != "TLS 1.3" || got.ALPN != "h2" || got.Issuer != "Test CA" || len(got.SANs) != 2 || got.ValidTo != 1800000000 {
+		t.Errorf("tls = %+v", *got)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"time"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// Optional push transport: with -socket (or REP_HOST_SOCKET=1) the host also
+// serves captured requests on a local socket next to its live file, so
+// 'rep list --follow' gets new requests as they arrive instead of polling.
+// Protocol: see internal/store/hostsocket.go.
+
+const (
+	subscriberBuffer   = 1024            // Queued frames before a slow follower is dropped
+	socketReadDeadline = 5 * time.Second // Time allowed for the client's request frame
+)
+
+// subscriber is a connected follower; ch is closed when it is dropped
+type subscriber struct {
+	ch chan []byte
+}
+
+var (
+	serveSocket bool
+	socketPath  string
+	listener    net.Listener
+	subscribers = make(map[*subscriber]bool) // guarded by mu
+)
+
+// socketEvent mirrors store.SocketEvent with the host's Request type
+type socketEvent struct {
+	Type     string    `json:"type"`
+	Request  *Request  `json:"request,omitempty"`
+	Requests []Request `json:"requests,omitempty"`
+}
+
+// startSocket listens on the socket next to dataPath. Failures are logged
+// and leave the host working without push updates.
+func startSocket() {
+	path := store.GetHostSocketPath(dataPath)
+	os.Remove(path) // Stale socket from a host that didn't exit cleanly
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		os.Stderr.WriteString("Warning: could not serve socket: " + err.Error() + "\n")
+		return
+	}
+	os.Chmod(path, 0600)
+	listener = l
+	socketPath = path
+	go acceptLoop(l)
+}
+
+// stopSocket closes the listener and every follower
+func stopSocket() {
+	if listener == nil {
+		return
+	}
+	listener.Close()
+	os.Remove(socketPath)
+	mu.Lock()
+	for sub := range subscribers {
+		delete(subscribers, sub)
+		close(sub.ch)
+	}
+	socketPath = ""
+	mu.Unlock()
+}
+
+func acceptLoop(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return // Listener closed
+		}
+		go serveConn(conn)
+	}
+}
+
+// serveConn reads the client's request, queues the snapshot and, for
+// "subscribe", keeps forwarding events until the connection fails
+func serveConn(conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(socketReadDeadline))
+	data, err := store.ReadFrame(conn, 1<<16)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	var req store.SocketRequest
+	if err := json.Unmarshal(data, &req); err != nil || (req.Action != "subscribe" && req.Action != "snapshot") {
+		frame, _ := json.Marshal(map[string]string{"type": "error", "error": "expected subscribe or snapshot"})
+		store.WriteFrame(conn, frame)
+		conn.Close()
+		return
+	}
+
+	// Snapshot and registration happen under mu, so no event can slip in
+	// between them
+	mu.Lock()
+	var snapshot []Request
+	for _, r := range liveData.Requests {
+		if r.Timestamp > req.Since {
+			snapshot = append(snapshot, r)
+		}
+	}
+	frame, err := json.Marshal(socketEvent{Type: "snapshot", Requests: snapshot})
+	if err != nil {
+		mu.Unlock()
+		conn.Close()
+		return
+	}
+	if req.Action == "snapshot" {
+		mu.Unlock()
+		store.WriteFrame(conn, frame)
+		conn.Close()
+		return
+	}
+	sub := &subscriber{ch: make(chan []byte, subscriberBuffer)}
+	sub.ch <- frame
+	subscribers[sub] = true
+	mu.Unlock()
+
+	for frame := range sub.ch {
+		if err := store.WriteFrame(conn, frame); err != nil {
+			mu.Lock()
+			if subscribers[sub] {
+				delete(subscribers, sub)
+				close(sub.ch)
+			}
+			mu.Unlock()
+			break
+		}
+	}
+	conn.Close()
+}
+
+// broadcast pushes an event to every follower (caller holds mu). Followers
+// whose queue is full are dropped rather than slowing down capture.
+func broadcast(event socketEvent) {
+	if len(subscribers) == 0 {
+		return
+	}
+	frame, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	for sub := range subscribers {
+		select {
+		case sub.ch <- frame:
+		default:
+			delete(subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// Counters for the "stats" action and the status sidecar (guarded by mu)
+var (
+	startedAt       = time.Now()
+	messagesHandled int64
+	bytesWritten    int64
+	rotations       int64
+	droppedRequests int64
+	autoSaves       int64
+	lastError       string
+	statusDirty     bool // Counters changed since the sidecar was written
+)
+
+// statusInterval is how often the status sidecar is refreshed while messages
+// arrive, rather than after every one
+const statusInterval = 2 * time.Second
+
+// currentStatus snapshots the host state (caller holds mu)
+func currentStatus(running bool) store.HostStatus {
+	count := 0
+	if liveData != nil {
+		count = len(liveData.Requests)
+	}
+	return store.HostStatus{
+		Running:          running,
+		PID:              os.Getpid(),
+		StartedAt:        startedAt.UnixMilli(),
+		UpdatedAt:        time.Now().UnixMilli(),
+		LivePath:         dataPath,
+		LiveRequests:     count,
+		MaxRequests:      maxLiveRequests,
+		KeepOnDisconnect: keepOnDisconnect,
+		Messages:         messagesHandled,
+		BytesWritten:     bytesWritten,
+		Rotations:        rotations,
+		Dropped:          droppedRequests,
+		AutoSaves:        autoSaves,
+		Duplicates:       duplicateRequests,
+		Filtered:         filteredRequests,
+		FilterStore:      filterStore,
+		FilterRules:      filterRules(),
+		LastError:        lastError,
+		Socket:           socketPath,
+	}
+}
+
+// writeStatus refreshes the sidecar file read by 'rep host-status'
+func writeStatus(running bool) {
+	mu.Lock()
+	status := currentStatus(running)
+	mu.Unlock()
+
+	content, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := store.WriteFileAtomic(store.GetHostStatusPath(dataPath), content, 0644); err != nil {
+		os.Stderr.WriteString("Error writing host status: " + err.Error() + "\n")
+	}
+}
+
+// startStatusWriter rewrites the status sidecar every statusInterval when
+// messages were handled since the last write. The returned function stops it
+// and waits for a write in progress.
+func startStatusWriter() func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(statusInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				mu.Lock()
+				dirty := statusDirty
+				statusDirty = false
+				mu.Unlock()
+				if dirty {
+					writeStatus(true)
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// recordDrop counts requests discarded by the request cap (caller holds mu)
+func recordDrop(n int) {
+	if n <= 0 {
+		return
+	}
+	rotations++
+	droppedRequests += int64(n)
+}
+
+// handleStats answers the "stats" action (caller holds mu)
+func handleStats() map[string]interface{} {
+	status := currentStatus(true)
+	return map[string]interface{}{
+		"success":            true,
+		"action":             "stats",
+		"uptime_seconds":     int64(time.Since(startedAt).Seconds()),
+		"messages":           status.Messages,
+		"bytes_written":      status.BytesWritten,
+		"rotations":          status.Rotations,
+		"dropped":            status.Dropped,
+		"autosaves":          status.AutoSaves,
+		"duplicates":         status.Duplicates,
+		"filtered":           status.Filtered,
+		"filter_rules":       status.FilterRules,
+		"count":              status.LiveRequests,
+		"max_requests":       status.MaxRequests,
+		"keep_on_disconnect": status.KeepOnDisconnect,
+		"path":               dataPath,
+	}
+}
+
+// handleConfig applies runtime settings from the "config" (or "configure")
+// action (caller holds mu). Unset fields are left alone.
+func handleConfig(msg *Message) map[string]interface{} {
+	if errMsg := configureFilter(msg); errMsg != "" {
+		return map[string]interface{}{
+			"success": false,
+			"action":  "config",
+			"error":   errMsg,
+		}
+	}
+	if msg.MaxRequests != nil {
+		if *msg.MaxRequests < 1 {
+			return map[string]interface{}{
+				"success": false,
+				"action":  "config",
+				"error":   "max_requests must be at least 1",
+			}
+		}
+		maxLiveRequests = *msg.MaxRequests
+		if autoSave.MaxRequests > maxLiveRequests {
+			autoSave.MaxRequests = maxLiveRequests
+		}
+		// Apply the new cap right away, auto-saving the overflow like sync
+		if over := len(liveData.Requests) - maxLiveRequests; over > 0 {
+			trimOldest(over)
+			saveLiveDataUnlocked()
+		}
+	}
+	if msg.KeepOnDisconnect != nil {
+		keepOnDisconnect = *msg.KeepOnDisconnect
+	}
+	return map[string]interface{}{
+		"success":            true,
+		"action":             "config",
+		"max_requests":       maxLiveRequests,
+		"keep_on_disconnect": keepOnDisconnect,
+		"filter_store":       filterStore,
+		"filter_rules":       filterRules(),
+		"profile":            profile,
+		"path":               dataPath,
+	}
+}
@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hostInstallName        string
+	hostInstallExtensionID []string
+	hostInstallBrowsers    []string
+	hostInstallHostPath    string
+	hostInstallUninstall   bool
+	hostInstallDryRun      bool
+)
+
+// nativeHostManifest is the Chrome native messaging host manifest
+type nativeHostManifest struct {
+	Name           string   `json:"name"`
+	Description    string   `json:"description"`
+	Path           string   `json:"path"`
+	Type           string   `json:"type"`
+	AllowedOrigins []string `json:"allowed_origins"`
+}
+
+// nativeHostTarget is where one browser looks for the manifest
+type nativeHostTarget struct {
+	Browser     string `json:"browser"`
+	Manifest    string `json:"manifest"`
+	RegistryKey string `json:"registry_key,omitempty"` // Windows only
+}
+
+var hostInstallCmd = &cobra.Command{
+	Use:   "host-install --name <host-name> --extension-id <id>",
+	Short: "Register rep-host as the extension's native messaging host",
+	Long: `Register rep-host with the browser so the rep+ extension can start it.
+
+Writes the native messaging manifest (name, path to rep-host, allowed
+extension origins) where each browser looks for it:
+
+  Linux     ~/.config/<browser>/NativeMessagingHosts/<name>.json
+  macOS     ~/Library/Application Support/<browser>/NativeMessagingHosts/<name>.json
+  Windows   %LOCALAPPDATA%\rep-cli\<name>.json, registered under
+            HKCU\Software\<vendor>\NativeMessagingHosts\<name>
+
+--name must be the host name the extension connects to, and --extension-id
+the extension's ID (chrome://extensions with developer mode on). rep-host
+defaults to the binary next to rep (rep-host.exe on Windows).
+
+Browsers (--browser, repeatable): chrome, chromium, brave, edge, arc.
+On Windows, Brave and Arc read Chrome's registry key.
+
+Examples:
+  rep host-install --name <host-name> --extension-id <id>
+  rep host-install --name <host-name> --extension-id <id> --browser brave --browser edge
+  rep host-install --name <host-name> --extension-id <id> --dry-run
+  rep host-install --name <host-name> --uninstall`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if hostInstallName == "" {
+			return fmt.Errorf("--name is required (the native host name the extension connects to)")
+		}
+		if !hostInstallUninstall && len(hostInstallExtensionID) == 0 {
+			return fmt.Errorf("--extension-id is required")
+		}
+
+		hostPath, err := resolveHostPath(hostInstallHostPath)
+		if err != nil {
+			return err
+		}
+
+		var targets []nativeHostTarget
+		for _, browser := range hostInstallBrowsers {
+			target, err := nativeHostTargetFor(strings.ToLower(browser), hostInstallName)
+			if err != nil {
+				return err
+			}
+			targets = append(targets, target)
+		}
+
+		manifest := nativeHostManifest{
+			Name:        hostInstallName,
+			Description: "rep+ native messaging host",
+			Path:        hostPath,
+			Type:        "stdio",
+		}
+		for _, id := range hostInstallExtensionID {
+			manifest.AllowedOrigins = append(manifest.AllowedOrigins, "chrome-extension://"+strings.Trim(id, "/")+"/")
+		}
+		data, err := sonic.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if !hostInstallDryRun {
+			for _, target := range targets {
+				if hostInstallUninstall {
+					err = uninstallNativeHost(target)
+				} else {
+					err = installNativeHost(target, data)
+				}
+				if err != nil {
+					return fmt.Errorf("%s: %w", target.Browser, err)
+				}
+			}
+		}
+
+		if getOutputMode() == "json" {
+			result := map[string]interface{}{
+				"name":      hostInstallName,
+				"targets":   targets,
+				"uninstall": hostInstallUninstall,
+				"dry_run":   hostInstallDryRun,
+			}
+			if !hostInstallUninstall {
+				result["manifest"] = manifest
+			}
+			out, _ := sonic.MarshalIndent(result, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if hostInstallDryRun && !hostInstallUninstall {
+			fmt.Println(string(data))
+			fmt.Println()
+		}
+		for _, target := range targets {
+			action := "Installed"
+			switch {
+			case hostInstallDryRun && hostInstallUninstall:
+				action = "Would remove"
+			case hostInstallDryRun:
+				action = "Would install"
+			case hostInstallUninstall:
+				action = "Removed"
+			}
+			pterm.Success.Printf("%s %s manifest: %s\n", action, target.Browser, target.Manifest)
+			if target.RegistryKey != "" {
+				fmt.Printf("  Registry: %s\n", target.RegistryKey)
+			}
+		}
+		if !hostInstallUninstall {
+			if _, err := os.Stat(hostPath); err != nil {
+				pterm.Warning.Printf("rep-host not found at %s (build it, or pass --host-path)\n", hostPath)
+			}
+			pterm.Info.Println("Restart the browser, then open the rep+ DevTools panel to start the host")
+		}
+		return nil
+	},
+}
+
+// resolveHostPath returns the absolute rep-host path, defaulting to the
+// binary next to the running rep
+func resolveHostPath(path string) (string, error) {
+	if path == "" {
+		self, err := os.Executable()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate rep: %w (pass --host-path)", err)
+		}
+		if resolved, err := filepath.EvalSymlinks(self); err == nil {
+			self = resolved
+		}
+		name := "rep-host"
+		if runtime.GOOS == "windows" {
+			name += ".exe"
+		}
+		path = filepath.Join(filepath.Dir(self), name)
+	}
+	path, err := store.ExpandHomePath(path)
+	if err != nil {
+		return "", err
+	}
+	// Chrome requires an absolute path on Linux and macOS
+	return filepath.Abs(path)
+}
+
+// nativeHostTargetFor returns the manifest location (and registry key on
+// Windows) a browser reads for the host name
+func nativeHostTargetFor(browser, name string) (nativeHostTarget, error) {
+	target := nativeHostTarget{Browser: browser}
+	file := name + ".json"
+
+	switch runtime.GOOS {
+	case "windows":
+		vendors := map[string]string{
+			"chrome":   `Google\Chrome`,
+			"chromium": `Chromium`,
+			"brave":    `Google\Chrome`,
+			"arc":      `Google\Chrome`,
+			"edge":     `Microsoft\Edge`,
+		}
+		vendor, ok := vendors[browser]
+		if !ok {
+			return target, unsupportedBrowser(browser)
+		}
+		// Chrome reads the manifest path from the registry; keep the file
+		// with the rest of rep's data
+		storePath, err := store.GetStorePath()
+		if err != nil {
+			return target, err
+		}
+		target.Manifest = filepath.Join(storePath, file)
+		target.RegistryKey = `HKCU\Software\` + vendor + `\NativeMessagingHosts\` + name
+	case "darwin":
+		dirs := map[string]string{
+			"chrome":   "Google/Chrome",
+			"chromium": "Chromium",
+			"brave":    "BraveSoftware/Brave-Browser",
+			"arc":      "Arc/User Data",
+			"edge":     "Microsoft Edge",
+		}
+		dir, ok := dirs[browser]
+		if !ok {
+			return target, unsupportedBrowser(browser)
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return target, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		target.Manifest = filepath.Join(home, "Library", "Application Support", dir, "NativeMessagingHosts", file)
+	default:
+		dirs := map[string]string{
+			"chrome":   "google-chrome",
+			"chromium": "chromium",
+			"brave":    "BraveSoftware/Brave-Browser",
+			"edge":     "microsoft-edge",
+		}
+		dir, ok := dirs[browser]
+		if !ok {
+			return target, unsupportedBrowser(browser)
+		}
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return target, err
+		}
+		target.Manifest = filepath.Join(configDir, dir, "NativeMessagingHosts", file)
+	}
+	return target, nil
+}
+
+func unsupportedBrowser(browser string) error {
+	return fmt.Errorf("unsupported browser on %s: %s (use chrome, chromium, brave, edge, arc)", runtime.GOOS, browser)
+}
+
+// installNativeHost writes the manifest and, on Windows, points the
+// browser's registry key at it
+func installNativeHost(target nativeHostTarget, manifest []byte) error {
+	if err := os.MkdirAll(filepath.Dir(target.Manifest), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target.Manifest, append(manifest, '\n'), 0644); err != nil {
+		return err
+	}
+	if target.RegistryKey == "" {
+		return nil
+	}
+	// Arguments go straight to reg.exe, so paths with spaces need no quoting
+	out, err := exec.Command("reg", "add", target.RegistryKey, "/ve", "/t", "REG_SZ", "/d", target.Manifest, "/f").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("reg add failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// uninstallNativeHost removes the registry key (Windows) and the manifest
+func uninstallNativeHost(target nativeHostTarget) error {
+	if target.RegistryKey != "" {
+		if out, err := exec.Command("reg", "delete", target.RegistryKey, "/f").CombinedOutput(); err != nil {
+			pterm.Warning.Printf("reg delete %s: %s\n", target.RegistryKey, strings.TrimSpace(string(out)))
+		}
+	}
+	if err := os.Remove(target.Manifest); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(hostInstallCmd)
+	hostInstallCmd.Flags().StringVar(&hostInstallName, "name", "", "Native messaging host name the extension connects to")
+	hostInstallCmd.Flags().StringArrayVar(&hostInstallExtensionID, "extension-id", nil, "Extension ID allowed to start the host (repeatable)")
+	hostInstallCmd.Flags().StringArrayVar(&hostInstallBrowsers, "browser", []string{"chrome"}, "Browser to register with: chrome, chromium, brave, edge, arc (repeatable)")
+	hostInstallCmd.Flags().StringVar(&hostInstallHostPath, "host-path", "", "Path to rep-host (default: next to rep)")
+	hostInstallCmd.Flags().BoolVar(&hostInstallUninstall, "uninstall", false, "Remove the manifest (and registry key on Windows)")
+	hostInstallCmd.Flags().BoolVar(&hostInstallDryRun, "dry-run", false, "Print the manifest and locations without writing anything")
+}
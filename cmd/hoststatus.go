@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var hostStatusCmd = &cobra.Command{
+	Use:     "host-status",
+	Aliases: []string{"status"},
+	Short:   "Show the native messaging host's state and counters",
+	Long: `Show the state of the native messaging host (rep-host) from the status
+file it keeps next to live.json (host-status.json).
+
+Reports whether the host is connected, its uptime, messages processed,
+bytes written to live.json, rotations (times the request cap dropped old
+requests), dropped requests, auto-saves, duplicates (requests the
+extension re-sent after a reconnect, merged into their earlier copy) and
+requests discarded by host-side filtering, plus the current settings.
+
+The extension can change settings at runtime with the host's "config"
+action (max_requests, keep_on_disconnect, filter_store, drop_domains,
+drop_paths) and read the same counters with the "stats" action.
+
+Host-side filtering (REP_HOST_FILTER=1) drops requests matching the
+'rep ignore' and 'rep mute' lists before they are written to live.json.
+
+With several browsers/profiles capturing, each host has its own status;
+the most recently active one is shown unless --session picks another.
+
+Examples:
+  rep host-status
+  rep status                       Same command
+  rep host-status --session work
+  rep host-status -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		livePaths, err := store.GetLiveFilePaths()
+		if err != nil {
+			return fmt.Errorf("failed to get live path: %w", err)
+		}
+
+		status, hosts, err := loadLatestHostStatus(livePaths)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return softFail(notFoundError("No host status found - the native host has not run yet (or is an older version)").
+					withHint("Open the rep+ extension DevTools panel to start it"))
+			}
+			return softFail(parseError("Could not read host status: %v", err))
+		}
+
+		running := status.Running && store.ProcessAlive(status.PID)
+
+		if getOutputMode() == "json" {
+			result := map[string]interface{}{
+				"running":            running,
+				"pid":                status.PID,
+				"started_at":         time.UnixMilli(status.StartedAt).Format(time.RFC3339),
+				"updated_at":         time.UnixMilli(status.UpdatedAt).Format(time.RFC3339),
+				"live_path":          status.LivePath,
+				"live_requests":      status.LiveRequests,
+				"max_requests":       status.MaxRequests,
+				"keep_on_disconnect": status.KeepOnDisconnect,
+				"messages":           status.Messages,
+				"bytes_written":      status.BytesWritten,
+				"rotations":          status.Rotations,
+				"dropped":            status.Dropped,
+				"autosaves":          status.AutoSaves,
+				"duplicates":         status.Duplicates,
+				"filtered":           status.Filtered,
+				"filter_store":       status.FilterStore,
+				"filter_rules":       status.FilterRules,
+			}
+			if running {
+				result["uptime_seconds"] = int64(time.Since(time.UnixMilli(status.StartedAt)).Seconds())
+			}
+			if status.LastError != "" {
+				result["last_error"] = status.LastError
+			}
+			if status.Socket != "" {
+				result["socket"] = status.Socket
+			}
+			out, _ := sonic.MarshalIndent(result, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		state := pterm.FgRed.Sprint("stopped")
+		uptime := "-"
+		if running {
+			state = pterm.FgGreen.Sprint("running")
+			uptime = time.Since(time.UnixMilli(status.StartedAt)).Round(time.Second).String()
+		} else if status.Running {
+			// Sidecar says running but the process is gone (killed, crashed)
+			state = pterm.FgYellow.Sprint("not responding (stale status)")
+		}
+
+		tableData := pterm.TableData{
+			{"State", state},
+			{"PID", fmt.Sprintf("%d", status.PID)},
+			{"Uptime", uptime},
+			{"Last update", time.UnixMilli(status.UpdatedAt).Format("2006-01-02 15:04:05")},
+			{"Live requests", fmt.Sprintf("%d / %d", status.LiveRequests, status.MaxRequests)},
+			{"Keep on disconnect", fmt.Sprintf("%v", status.KeepOnDisconnect)},
+			{"Messages", fmt.Sprintf("%d", status.Messages)},
+			{"Bytes written", formatByteSize(status.BytesWritten)},
+			{"Rotations", fmt.Sprintf("%d", status.Rotations)},
+			{"Dropped requests", fmt.Sprintf("%d", status.Dropped)},
+			{"Auto-saves", fmt.Sprintf("%d", status.AutoSaves)},
+			{"Duplicates merged", fmt.Sprintf("%d", status.Duplicates)},
+			{"Host filter", hostFilterLabel(status)},
+			{"Filtered requests", fmt.Sprintf("%d", status.Filtered)},
+		}
+		if status.Socket != "" {
+			tableData = append(tableData, []string{"Push socket", status.Socket})
+		}
+		if status.LastError != "" {
+			tableData = append(tableData, []string{"Last error", status.LastError})
+		}
+		pterm.DefaultTable.WithData(tableData).Render()
+
+		if hosts > 1 {
+			fmt.Println()
+			pterm.Info.Printf("%d hosts report status - showing the most recent; use --session to pick one\n", hosts)
+		}
+		if status.Dropped > 0 && status.AutoSaves == 0 {
+			fmt.Println()
+			pterm.Info.Println("Requests were dropped at the cap - set REP_AUTOSAVE_REQUESTS to snapshot them instead")
+		}
+		return nil
+	},
+}
+
+// loadLatestHostStatus returns the most recently updated status among the
+// live files' sidecars, and how many sidecars were found
+func loadLatestHostStatus(livePaths []string) (*store.HostStatus, int, error) {
+	var latest *store.HostStatus
+	var firstErr error
+	found := 0
+	for _, livePath := range livePaths {
+		status, err := store.LoadHostStatus(livePath)
+		if err != nil {
+			if firstErr == nil || os.IsNotExist(firstErr) {
+				firstErr = err
+			}
+			continue
+		}
+		found++
+		if latest == nil || status.UpdatedAt > latest.UpdatedAt {
+			latest = status
+		}
+	}
+	if latest == nil {
+		return nil, 0, firstErr
+	}
+	return latest, found, nil
+}
+
+// hostFilterLabel describes the host-side filter settings
+func hostFilterLabel(status *store.HostStatus) string {
+	if status.FilterRules == 0 && !status.FilterStore {
+		return "off"
+	}
+	label := fmt.Sprintf("%d rules", status.FilterRules)
+	if status.FilterStore {
+		label += " (ignore/mute lists)"
+	}
+	return label
+}
+
+func init() {
+	rootCmd.AddCommand(hostStatusCmd)
+}
@@ -23,6 +23,9 @@ var ignoreCmd = &cobra.Command{
 Ignored domains are excluded from 'rep list' and 'rep summary' by default.
 This helps focus on target domains for bug bounty hunting.
 
+With REP_HOST_FILTER=1 set for the native host, ignored domains are dropped
+before they are written to live.json (permanently - not just hidden).
+
 Examples:
   rep ignore google-analytics.com facebook.net     Add domains to ignore
   rep ignore --remove api.example.com              Remove from ignore list
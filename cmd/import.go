@@ -3,46 +3,117 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/bytedance/sonic"
 	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/importer"
 	"github.com/repplus/rep-cli/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var (
-	importNote string
+	importNote   string
+	importFormat string
+	importCurl   string
 )
 
 var importCmd = &cobra.Command{
-	Use:   "import <file>",
+	Use:   "import <file> | --curl <command>",
 	Short: "Import traffic from rep+ extension export as a saved session",
 	Long: `Import HTTP traffic from rep+ Chrome extension JSON export.
 
 Imports the file as a saved session that can be viewed with 'rep list --saved'.
 
+Also accepts a single-session file from 'rep sessions export'. Its ID, note
+and save time are kept; a new ID is generated if the ID already exists.
+
+Traffic from other tools is converted (--format, detected by default):
+  mitmproxy   Flow file from mitmdump -w or mitmweb (File > Save); HTTP
+              flows, with WebSocket messages as response events ('rep sse')
+  har         HTTP Archive from ZAP (Export > HAR), Burp or browser devtools;
+              IDs come from ZAP's message IDs (zap_0042) when present
+  zap         ZAP message export (History > Export Messages to File)
+  curl        curl commands, one per line or with \ continuations, as
+              "Copy as cURL" and API docs write them; no responses
+
+--curl imports a single command given on the command line. Imported curl
+commands work like captures: replay them, or diff them against the
+capture once replayed.
+
+Compressed bodies (gzip, deflate, zstd) are decoded, as the extension
+captures them.
+
 Example:
   rep import ./rep_export_2024-01-15.json
-  rep import ./traffic.json --note "auth flow"`,
-	Args: cobra.ExactArgs(1),
+  rep import ./traffic.json --note "auth flow"
+  rep import ./session.json                Session shared by a teammate
+  rep import ./phone.flows --note "ios app"  mitmproxy capture
+  rep import ./zap-history.har --note "zap spider"
+  rep import ./messages.txt --format zap
+  rep import --curl 'curl -X POST https://api.target.com/v1/orders -H "Authorization: Bearer ..." -d "{}"'
+  rep import ./snippets.sh --format curl`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		filePath := args[0]
-
-		// Read file
-		data, err := os.ReadFile(filePath)
-		if err != nil {
-			return fmt.Errorf("failed to read file: %w", err)
+		var filePath string
+		var data []byte
+		var err error
+		switch {
+		case importCurl != "" && len(args) == 1:
+			return usageError("give a file or --curl, not both")
+		case importCurl != "":
+			filePath, data = "--curl", []byte(importCurl)
+			if cmd.Flags().Changed("format") && !strings.EqualFold(importFormat, "curl") {
+				return usageError("--curl imports curl syntax; drop --format %s", importFormat)
+			}
+			importFormat = "curl"
+		case len(args) == 0:
+			return usageError("missing file to import").withHint("rep import <file>, or rep import --curl 'curl ...'")
+		default:
+			filePath = args[0]
+			// Read file
+			data, err = os.ReadFile(filePath)
+			if os.IsNotExist(err) {
+				return notFoundError("file not found: %s", filePath)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read file: %w", err)
+			}
 		}
 
-		// Parse export
+		// Parse export (extension export, single-session file or another
+		// tool's format)
 		var export store.Export
-		if err := sonic.Unmarshal(data, &export); err != nil {
-			return fmt.Errorf("failed to parse JSON: %w", err)
+		var single store.SessionExport
+		format := strings.ToLower(importFormat)
+		if format == "auto" {
+			format = "rep"
+			if f, ok := importer.Detect(data); ok {
+				format = f.Name
+			}
+		}
+		if format == "rep" {
+			if err := sonic.Unmarshal(data, &export); err != nil {
+				return parseError("failed to parse JSON: %v", err).withHint("Use --format to pick a format: " + strings.Join(importer.Names(), ", "))
+			}
+			if len(export.Requests) == 0 {
+				if err := sonic.Unmarshal(data, &single); err == nil && single.Format == store.SessionExportFormat {
+					export.Version = single.Version
+					export.Requests = single.Session.Requests
+				}
+			}
+		} else {
+			f, ok := importer.Lookup(format)
+			if !ok {
+				return usageError("unsupported format: %s (use auto, rep, %s)", importFormat, strings.Join(importer.Names(), ", "))
+			}
+			if export.Requests, err = f.Parse(data); err != nil {
+				return parseError("failed to parse %s file: %v", f.Name, err)
+			}
 		}
 
 		if len(export.Requests) == 0 {
-			pterm.Warning.Println("No requests found in export file")
-			return nil
+			return softFail(emptyError("No requests found in export file"))
 		}
 
 		// Load store
@@ -52,8 +123,21 @@ Example:
 		}
 
 		// Generate session ID and save as session
-		sessionID := store.GenerateSessionID(importNote)
-		session := s.AddSession(sessionID, importNote, export.Requests)
+		note := importNote
+		sessionID := store.GenerateSessionID(note)
+		if single.Format == store.SessionExportFormat {
+			if note == "" {
+				note = single.Session.Note
+			}
+			sessionID = store.GenerateSessionID(note)
+			if single.Session.ID != "" && !hasSessionID(s, single.Session.ID) {
+				sessionID = single.Session.ID
+			}
+		}
+		session := s.AddSession(sessionID, note, export.Requests)
+		if single.Session.Timestamp > 0 {
+			session.Timestamp = single.Session.Timestamp
+		}
 
 		// Save
 		if err := s.Save(); err != nil {
@@ -71,12 +155,13 @@ Example:
 				"requests":       len(export.Requests),
 				"domains":        len(domains),
 				"source":         filePath,
+				"format":         format,
 				"export_version": export.Version,
 			}
 			out, _ := sonic.MarshalIndent(result, "", "  ")
 			fmt.Println(string(out))
 		} else {
-			pterm.Success.Printf("Imported %d requests as session: %s\n", len(export.Requests), session.ID)
+			pterm.Success.Printf("Imported %d requests (%s) as session: %s\n", len(export.Requests), format, session.ID)
 			pterm.Info.Printf("Unique domains: %d\n", len(domains))
 
 			if len(domains) > 0 {
@@ -103,7 +188,19 @@ Example:
 	},
 }
 
+// hasSessionID reports whether a session with exactly this ID exists
+func hasSessionID(s *store.Store, id string) bool {
+	for _, sess := range s.ListSessions() {
+		if sess.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
 	rootCmd.AddCommand(importCmd)
 	importCmd.Flags().StringVar(&importNote, "note", "", "Add a note to the imported session")
+	importCmd.Flags().StringVar(&importFormat, "format", "auto", "File format: auto, rep, "+strings.Join(importer.Names(), ", "))
+	importCmd.Flags().StringVar(&importCurl, "curl", "", "Import a curl command instead of a file")
 }
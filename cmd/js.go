@@ -6,7 +6,6 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/bytedance/sonic"
 	"github.com/pterm/pterm"
 	"github.com/repplus/rep-cli/internal/noise"
 	"github.com/repplus/rep-cli/internal/store"
@@ -69,6 +68,25 @@ Designed for AI agents to download JS files for:
   - Source map analysis
   - Dependency vulnerability scanning
 
+--download fetches the scripts concurrently into --out (default ./js),
+keeping the host/path layout, and writes manifest.json mapping each URL to
+its file and sha256. Re-running revalidates with ETag/Last-Modified and
+leaves unchanged files alone, so it is cheap to repeat.
+
+--sourcemaps looks for each script's source map (SourceMap header,
+//# sourceMappingURL comment, inline data: map, or a <script>.map sibling),
+fetches it and writes the original sources from sourcesContent under --out
+(default ./sourcemaps/<host>/...). Recovered sources are often the quickest
+route to readable app code and unlisted endpoints.
+
+--endpoints scans captured script bodies with LinkFinder-style patterns and
+fetch/axios/XHR/jQuery call heuristics for API paths. Paths no captured
+request matched are starred: routes the UI never exercised.
+
+--libs identifies libraries and versions (jQuery, Lodash, React, Angular,
+Vue, webpack runtime, ...) from script URLs and license banners, and lists
+known CVEs for each version from a small built-in advisory set.
+
 Includes both first-party and third-party/CDN scripts.
 Categorizes scripts as:
   - First-party: Same base domain as page that loaded it
@@ -80,6 +98,14 @@ Examples:
   rep js --urls                Just URLs, one per line (for curl)
   rep js --graph               Show page -> JS dependency graph
   rep js --curl                Generate curl commands for download
+  rep js --download            Fetch all scripts into ./js/<host>/<path>
+  rep js --download --out src --concurrency 16
+  rep js --sourcemaps          Recover original sources into ./sourcemaps
+  rep js --endpoints           API paths referenced in scripts (★ = never requested)
+  rep js --endpoints --unrequested --urls   Untested paths, one per line
+  rep js --libs                Library versions with known CVEs
+  rep js diff                  Scripts added/removed/changed since the last save
+  rep js view app.9c1d.js --grep fetch   Beautified script, grep within it
   rep js --saved latest        Analyze saved session
   rep js -o json               Full structured output for agents`,
 	RunE: runJS,
@@ -106,27 +132,18 @@ func runJS(cmd *cobra.Command, args []string) error {
 		}
 
 		if session == nil {
-			pterm.Warning.Printf("Session not found: %s\n", jsSaved)
-			pterm.Info.Println("Use 'rep sessions' to list available sessions")
-			return nil
+			return notFoundError("session not found: %s", jsSaved).withHint("Use 'rep sessions' to list available sessions")
 		}
 
 		tempStore = store.NewTempStore(session.Requests)
 	} else {
 		// Default: Load from live.json
-		livePath, err := store.GetLiveFilePath()
+		export, err := loadLiveMerged()
 		if err != nil {
-			return fmt.Errorf("failed to get live path: %w", err)
-		}
-		export, err := loadLiveExport(livePath)
-		if err != nil {
-			pterm.Warning.Printf("Could not read live.json: %v\n", err)
-			pterm.Info.Println("Enable auto-export in rep+ extension first")
-			return nil
+			return softFail(liveReadError(err))
 		}
 		if len(export.Requests) == 0 {
-			pterm.Info.Println("No requests captured yet (live session empty)")
-			return nil
+			return softFail(emptyLiveError())
 		}
 
 		tempStore = store.NewTempStore(export.Requests)
@@ -140,20 +157,39 @@ func runJS(cmd *cobra.Command, args []string) error {
 	jsRequests := getJSRequests(tempStore)
 
 	if len(jsRequests) == 0 {
-		pterm.Info.Println("No JavaScript files found in captured traffic")
-		return nil
+		return softFail(emptyError("No JavaScript files found in captured traffic"))
 	}
 
 	// Categorize scripts
 	output := categorizeJS(jsRequests)
 
 	// Handle different output modes
+	if jsEndpoints {
+		return findJSEndpoints(jsRequests)
+	}
+
+	if jsLibs {
+		return fingerprintJSLibs(jsRequests)
+	}
+
 	if jsURLs {
 		// Plain URLs, one per line
 		printJSURLs(output)
 		return nil
 	}
 
+	if jsDownload {
+		return downloadJS(output, jsOut)
+	}
+
+	if jsSourcemaps {
+		dir := jsOut
+		if !cmd.Flags().Changed("out") {
+			dir = "sourcemaps"
+		}
+		return unpackSourceMaps(jsRequests, dir)
+	}
+
 	if jsCurl {
 		// Generate curl commands
 		printCurlCommands(output)
@@ -169,8 +205,7 @@ func runJS(cmd *cobra.Command, args []string) error {
 		if jsCurl {
 			output.CurlCommands = generateCurlCommands(output)
 		}
-		out, _ := sonic.MarshalIndent(output, "", "  ")
-		fmt.Println(string(out))
+		printJSON("js", output)
 		return nil
 	}
 
@@ -371,18 +406,22 @@ func printCurlCommands(output JSOutput) {
 // generateCurlCommands creates curl commands for downloading JS files
 func generateCurlCommands(output JSOutput) []string {
 	var commands []string
+	shell, err := currentShellDialect()
+	if err != nil {
+		shell, _ = shellDialectFor("posix", false)
+	}
 
 	// First-party scripts (most relevant)
 	for _, js := range output.FirstPartyJS {
-		commands = append(commands, fmt.Sprintf("curl -sLO '%s'", js.URL))
+		commands = append(commands, shell.Curl+" -sLO "+shell.Quote(js.URL))
 	}
 	// Third-party scripts
 	for _, js := range output.ThirdPartyJS {
-		commands = append(commands, fmt.Sprintf("curl -sLO '%s'", js.URL))
+		commands = append(commands, shell.Curl+" -sLO "+shell.Quote(js.URL))
 	}
 	// CDN scripts (often useful for version fingerprinting)
 	for _, js := range output.CDNScripts {
-		commands = append(commands, fmt.Sprintf("curl -sLO '%s'", js.URL))
+		commands = append(commands, shell.Curl+" -sLO "+shell.Quote(js.URL))
 	}
 
 	return commands
@@ -430,7 +469,7 @@ func printJSSummary(output JSOutput) {
 	fmt.Println()
 	pterm.DefaultSection.Println("Next Steps")
 	fmt.Println("  rep js --urls > urls.txt                      # Export URLs")
-	fmt.Println("  rep js --urls | xargs -I{} curl -sLO {}       # Download all")
+	fmt.Println("  rep js --download                             # Download all into ./js")
 	fmt.Println("  rep js --graph                                # Show page dependencies")
 	fmt.Println("  rep js -o json                                # Full JSON output")
 }
@@ -440,5 +479,13 @@ func init() {
 	jsCmd.Flags().BoolVar(&jsURLs, "urls", false, "Just print URLs, one per line (for curl/wget)")
 	jsCmd.Flags().BoolVar(&jsGraph, "graph", false, "Show page -> JS dependency graph")
 	jsCmd.Flags().BoolVar(&jsCurl, "curl", false, "Generate curl commands for downloading")
+	jsCmd.Flags().BoolVar(&jsDownload, "download", false, "Download scripts into --out with a manifest")
+	jsCmd.Flags().BoolVar(&jsEndpoints, "endpoints", false, "Extract candidate API endpoints from script bodies")
+	jsCmd.Flags().BoolVar(&jsUnrequested, "unrequested", false, "With --endpoints: only endpoints never requested")
+	jsCmd.Flags().BoolVar(&jsLibs, "libs", false, "Fingerprint libraries and versions, with known advisories")
+	jsCmd.Flags().BoolVar(&jsSourcemaps, "sourcemaps", false, "Fetch source maps and unpack original sources into --out")
+	jsCmd.Flags().StringVar(&jsOut, "out", "js", "Output directory for --download (--sourcemaps: ./sourcemaps)")
+	jsCmd.Flags().IntVar(&jsConcurrency, "concurrency", 8, "Parallel fetches for --download/--sourcemaps")
+	jsCmd.Flags().BoolVar(&jsInsecure, "insecure", false, "Skip TLS certificate verification when fetching")
 	jsCmd.Flags().StringVar(&jsSaved, "saved", "", "Read from saved session (ID, prefix, or 'latest')")
 }
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	jsDiffSaved []string
+	jsDiffAll   bool
+)
+
+// bundleHashPattern matches content hashes in bundle file names
+// (app.9c1d2e.js, main-4F2A91BC.js, chunk.abc123def.min.js)
+var bundleHashPattern = regexp.MustCompile(`[.-][0-9a-fA-F]{4,}(?:[.-][0-9a-fA-F]{4,})*((?:\.[a-z]+)*)$`)
+
+// jsSnapshot is one script in a capture
+type jsSnapshot struct {
+	URL  string
+	Hash string // sha256 of the captured body ("" when not captured)
+	Size int
+}
+
+// JSDiffEntry is one script in the diff
+type JSDiffEntry struct {
+	Change  string `json:"change"` // added, removed, changed, renamed, unchanged
+	URL     string `json:"url"`
+	OldURL  string `json:"old_url,omitempty"` // Renamed bundles: the previous URL
+	OldHash string `json:"old_hash,omitempty"`
+	NewHash string `json:"new_hash,omitempty"`
+	OldSize int    `json:"old_size,omitempty"`
+	NewSize int    `json:"new_size,omitempty"`
+}
+
+var jsDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare scripts between two captures",
+	Long: `Compare the JavaScript of two captures: which script URLs were added or
+removed and which bundles changed content (sha256 of the captured body).
+
+Bundles whose file name only differs by a content hash (app.9c1d.js ->
+app.4f2e.js) are paired as "renamed", so a deploy shows up as changed
+bundles rather than a wall of additions and removals.
+
+Sources:
+  (none)                    Latest saved session -> live
+  --saved A                 Session A -> live
+  --saved A --saved B       Session A -> session B
+
+Examples:
+  rep js diff                                   What changed since the last save
+  rep js diff --saved 20240101-120000 --saved latest
+  rep js diff --all                             Include unchanged scripts
+  rep js diff -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var oldSource, newSource string
+		switch len(jsDiffSaved) {
+		case 0:
+			oldSource = "latest"
+		case 1:
+			oldSource = jsDiffSaved[0]
+		case 2:
+			oldSource, newSource = jsDiffSaved[0], jsDiffSaved[1]
+		default:
+			return fmt.Errorf("--saved takes at most two sessions")
+		}
+
+		oldScripts, oldLabel, err := jsSnapshots(oldSource)
+		if err != nil || oldScripts == nil {
+			return err
+		}
+		newScripts, newLabel, err := jsSnapshots(newSource)
+		if err != nil || newScripts == nil {
+			return err
+		}
+
+		entries := diffJSSnapshots(oldScripts, newScripts)
+		counts := make(map[string]int)
+		for _, e := range entries {
+			counts[e.Change]++
+		}
+		if !jsDiffAll {
+			kept := entries[:0]
+			for _, e := range entries {
+				if e.Change != "unchanged" {
+					kept = append(kept, e)
+				}
+			}
+			entries = kept
+		}
+
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"old":       oldLabel,
+				"new":       newLabel,
+				"added":     counts["added"],
+				"removed":   counts["removed"],
+				"changed":   counts["changed"] + counts["renamed"],
+				"unchanged": counts["unchanged"],
+				"scripts":   entries,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		pterm.DefaultSection.Printf("JS diff: %s -> %s\n", oldLabel, newLabel)
+		if len(entries) == 0 {
+			pterm.Success.Printf("No script changes (%d unchanged)\n", counts["unchanged"])
+			return nil
+		}
+		for _, e := range entries {
+			switch e.Change {
+			case "added":
+				fmt.Printf("  %s %s\n", pterm.FgGreen.Sprint("+"), output.SanitizeText(e.URL))
+			case "removed":
+				fmt.Printf("  %s %s\n", pterm.FgRed.Sprint("-"), output.SanitizeText(e.URL))
+			case "renamed":
+				fmt.Printf("  %s %s  %s\n", pterm.FgYellow.Sprint("~"), output.SanitizeText(e.URL),
+					pterm.FgGray.Sprintf("(was %s, %s)", path.Base(e.OldURL), sizeChange(e.OldSize, e.NewSize)))
+			case "changed":
+				fmt.Printf("  %s %s  %s\n", pterm.FgYellow.Sprint("~"), output.SanitizeText(e.URL),
+					pterm.FgGray.Sprintf("(content changed, %s)", sizeChange(e.OldSize, e.NewSize)))
+			default:
+				fmt.Printf("  %s %s\n", pterm.FgGray.Sprint("="), pterm.FgGray.Sprint(output.SanitizeText(e.URL)))
+			}
+		}
+		fmt.Println()
+		pterm.Info.Printf("%d added, %d removed, %d changed, %d unchanged\n",
+			counts["added"], counts["removed"], counts["changed"]+counts["renamed"], counts["unchanged"])
+		if counts["added"]+counts["changed"]+counts["renamed"] > 0 {
+			fmt.Println("  Next: rep js --endpoints to look for new routes in the new bundles")
+		}
+		return nil
+	},
+}
+
+// jsSnapshots loads the scripts of a source ("" = live) keyed by URL.
+// Returns nil when the source is missing (a warning was printed).
+func jsSnapshots(saved string) (map[string]jsSnapshot, string, error) {
+	label := "live"
+	if saved != "" {
+		s, err := store.Get()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to load store: %w", err)
+		}
+		session := resolveSession(s, saved)
+		if session == nil {
+			return nil, "", notFoundError("session not found: %s", saved).withHint("Use 'rep sessions' to list available sessions")
+		}
+		label = session.ID
+	}
+	tempStore, err := loadSourceStore(saved)
+	if err != nil || tempStore == nil {
+		return nil, "", err
+	}
+
+	scripts := make(map[string]jsSnapshot)
+	for _, req := range getJSRequests(tempStore) {
+		if _, ok := scripts[req.URL]; ok {
+			continue
+		}
+		snap := jsSnapshot{URL: req.URL}
+		if req.Response != nil && req.Response.Body != "" {
+			body := store.ResponseBodyText(&req)
+			sum := sha256.Sum256([]byte(body))
+			snap.Hash, snap.Size = hex.EncodeToString(sum[:]), len(body)
+		}
+		scripts[req.URL] = snap
+	}
+	return scripts, label, nil
+}
+
+// diffJSSnapshots pairs scripts by URL, then pairs the leftovers by bundle
+// name with the content hash stripped
+func diffJSSnapshots(oldScripts, newScripts map[string]jsSnapshot) []JSDiffEntry {
+	var entries []JSDiffEntry
+	var added, removed []jsSnapshot
+
+	for u, n := range newScripts {
+		o, ok := oldScripts[u]
+		if !ok {
+			added = append(added, n)
+			continue
+		}
+		change := "unchanged"
+		// Scripts captured without a body can only be compared by URL
+		if o.Hash != "" && n.Hash != "" && o.Hash != n.Hash {
+			change = "changed"
+		}
+		entries = append(entries, JSDiffEntry{Change: change, URL: u, OldHash: o.Hash, NewHash: n.Hash, OldSize: o.Size, NewSize: n.Size})
+	}
+	for u, o := range oldScripts {
+		if _, ok := newScripts[u]; !ok {
+			removed = append(removed, o)
+		}
+	}
+
+	sort.Slice(added, func(i, j int) bool { return added[i].URL < added[j].URL })
+	sort.Slice(removed, func(i, j int) bool { return removed[i].URL < removed[j].URL })
+	byStem := make(map[string][]int)
+	for i, o := range removed {
+		byStem[bundleStem(o.URL)] = append(byStem[bundleStem(o.URL)], i)
+	}
+	paired := make(map[int]bool)
+	for _, n := range added {
+		stem := bundleStem(n.URL)
+		candidates := byStem[stem]
+		if stem == "" || len(candidates) == 0 {
+			entries = append(entries, JSDiffEntry{Change: "added", URL: n.URL, NewHash: n.Hash, NewSize: n.Size})
+			continue
+		}
+		i := candidates[0]
+		byStem[stem] = candidates[1:]
+		paired[i] = true
+		o := removed[i]
+		change := "renamed"
+		if o.Hash != "" && o.Hash == n.Hash {
+			change = "unchanged" // Same content under a new URL
+		}
+		entries = append(entries, JSDiffEntry{Change: change, URL: n.URL, OldURL: o.URL, OldHash: o.Hash, NewHash: n.Hash, OldSize: o.Size, NewSize: n.Size})
+	}
+	for i, o := range removed {
+		if !paired[i] {
+			entries = append(entries, JSDiffEntry{Change: "removed", URL: o.URL, OldHash: o.Hash, OldSize: o.Size})
+		}
+	}
+
+	order := map[string]int{"added": 0, "renamed": 1, "changed": 2, "removed": 3, "unchanged": 4}
+	sort.Slice(entries, func(i, j int) bool {
+		if order[entries[i].Change] != order[entries[j].Change] {
+			return order[entries[i].Change] < order[entries[j].Change]
+		}
+		return entries[i].URL < entries[j].URL
+	})
+	return entries
+}
+
+// bundleStem is host + directory + file name without its content hash, or ""
+// when the name carries no hash
+func bundleStem(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	dir, file := path.Split(parsed.Path)
+	loc := bundleHashPattern.FindStringSubmatchIndex(file)
+	// Hashes have digits; "app.face.js" is a name
+	if loc == nil || !strings.ContainsAny(file[loc[0]:loc[2]], "0123456789") {
+		return ""
+	}
+	ext := file[loc[2]:loc[3]]
+	return strings.ToLower(parsed.Host) + dir + file[:loc[0]] + ext
+}
+
+func sizeChange(oldSize, newSize int) string {
+	if oldSize == 0 || newSize == 0 {
+		return "size unknown"
+	}
+	return fmt.Sprintf("%s -> %s", output.FormatBodySize(oldSize), output.FormatBodySize(newSize))
+}
+
+func init() {
+	jsCmd.AddCommand(jsDiffCmd)
+	jsDiffCmd.Flags().StringArrayVar(&jsDiffSaved, "saved", nil, "Session to compare (repeat for two; 'latest' allowed)")
+	jsDiffCmd.Flags().BoolVar(&jsDiffAll, "all", false, "Also list unchanged scripts")
+}
@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/replay"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+var (
+	jsDownload    bool
+	jsOut         string
+	jsConcurrency int
+	jsInsecure    bool
+)
+
+// jsManifestName is written at the root of the download directory
+const jsManifestName = "manifest.json"
+
+// JSManifest records what 'rep js --download' fetched, so later runs can
+// skip unchanged files
+type JSManifest struct {
+	GeneratedAt string            `json:"generated_at"`
+	Files       []JSManifestEntry `json:"files"`
+}
+
+// JSManifestEntry maps a script URL to its file
+type JSManifestEntry struct {
+	URL          string `json:"url"`
+	File         string `json:"file"` // Relative to the manifest, slash-separated
+	SHA256       string `json:"sha256"`
+	Size         int64  `json:"size"`
+	Category     string `json:"category,omitempty"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	FetchedAt    string `json:"fetched_at"`
+}
+
+// jsDownloadResult is the outcome for one script
+type jsDownloadResult struct {
+	URL    string `json:"url"`
+	File   string `json:"file,omitempty"`
+	Result string `json:"result"` // downloaded, updated, unchanged, failed
+	Error  string `json:"error,omitempty"`
+
+	entry *JSManifestEntry
+}
+
+// downloadJS fetches every script in output into dir, concurrently, and
+// writes the manifest
+func downloadJS(output JSOutput, dir string) error {
+	var files []JSFile
+	files = append(files, output.FirstPartyJS...)
+	files = append(files, output.ThirdPartyJS...)
+	files = append(files, output.CDNScripts...)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	previous := make(map[string]JSManifestEntry)
+	if data, err := os.ReadFile(filepath.Join(dir, jsManifestName)); err == nil {
+		var manifest JSManifest
+		if err := sonic.Unmarshal(data, &manifest); err != nil {
+			pterm.Warning.Printf("Ignoring unreadable %s: %v\n", jsManifestName, err)
+		}
+		for _, entry := range manifest.Files {
+			previous[entry.URL] = entry
+		}
+	}
+
+	workers := jsConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]jsDownloadResult, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				var prev *JSManifestEntry
+				if entry, ok := previous[files[i].URL]; ok {
+					prev = &entry
+				}
+				results[i] = downloadScript(files[i], dir, prev)
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Entries for scripts not seen this time are kept
+	manifest := JSManifest{GeneratedAt: time.Now().Format(time.RFC3339), Files: []JSManifestEntry{}}
+	written := make(map[string]bool)
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[r.Result]++
+		if r.entry != nil {
+			manifest.Files = append(manifest.Files, *r.entry)
+			written[r.URL] = true
+		}
+	}
+	for scriptURL, entry := range previous {
+		if !written[scriptURL] {
+			manifest.Files = append(manifest.Files, entry)
+		}
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].URL < manifest.Files[j].URL
+	})
+	data, _ := sonic.MarshalIndent(manifest, "", "  ")
+	if err := os.WriteFile(filepath.Join(dir, jsManifestName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	if getOutputMode() == "json" {
+		out, _ := sonic.MarshalIndent(map[string]interface{}{
+			"dir":        dir,
+			"manifest":   filepath.Join(dir, jsManifestName),
+			"downloaded": counts["downloaded"],
+			"updated":    counts["updated"],
+			"unchanged":  counts["unchanged"],
+			"failed":     counts["failed"],
+			"files":      results,
+		}, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	for _, r := range results {
+		switch r.Result {
+		case "failed":
+			fmt.Printf("  %s %s  %s\n", pterm.FgRed.Sprint("✗"), r.URL, pterm.FgGray.Sprint(r.Error))
+		case "unchanged":
+			fmt.Printf("  %s %s\n", pterm.FgGray.Sprint("="), pterm.FgGray.Sprint(r.File))
+		default:
+			fmt.Printf("  %s %s\n", pterm.FgGreen.Sprint("↓"), r.File)
+		}
+	}
+	fmt.Println()
+	prefix := pterm.Success
+	if counts["failed"] > 0 {
+		prefix = pterm.Warning
+	}
+	prefix.Printf("%d downloaded, %d updated, %d unchanged, %d failed -> %s\n",
+		counts["downloaded"], counts["updated"], counts["unchanged"], counts["failed"], filepath.Join(dir, jsManifestName))
+	return nil
+}
+
+// downloadScript fetches one script, revalidating against its previous
+// manifest entry, and writes it when it changed
+func downloadScript(js JSFile, dir string, prev *JSManifestEntry) jsDownloadResult {
+	result := jsDownloadResult{URL: js.URL}
+	rel, err := jsFilePath(js.URL)
+	if err != nil {
+		result.Result, result.Error = "failed", err.Error()
+		return result
+	}
+	result.File = rel
+	target := filepath.Join(dir, filepath.FromSlash(rel))
+
+	req := &store.Request{Method: "GET", URL: js.URL, Headers: store.HeaderMap{"Accept": {"*/*"}}}
+	_, statErr := os.Stat(target)
+	if prev != nil && statErr == nil {
+		if prev.ETag != "" {
+			store.SetHeader(req.Headers, "If-None-Match", prev.ETag)
+		}
+		if prev.LastModified != "" {
+			store.SetHeader(req.Headers, "If-Modified-Since", prev.LastModified)
+		}
+	}
+
+	res, err := replay.Send(context.Background(), req, replay.Options{FollowRedirects: true, Insecure: jsInsecure})
+	if err != nil {
+		result.Result, result.Error = "failed", err.Error()
+		return keepPrevious(result, prev)
+	}
+	if res.Status == 304 && prev != nil {
+		result.Result, result.entry = "unchanged", prev
+		return result
+	}
+	if res.Status != 200 {
+		result.Result, result.Error = "failed", fmt.Sprintf("HTTP %d", res.Status)
+		return keepPrevious(result, prev)
+	}
+	if res.Truncated {
+		result.Result, result.Error = "failed", fmt.Sprintf("larger than %d MB", replay.MaxBodySize/(1024*1024))
+		return keepPrevious(result, prev)
+	}
+
+	sum := sha256.Sum256([]byte(res.Body))
+	entry := &JSManifestEntry{
+		URL:          js.URL,
+		File:         rel,
+		SHA256:       hex.EncodeToString(sum[:]),
+		Size:         int64(len(res.Body)),
+		Category:     js.Category,
+		ETag:         store.HeaderFirst(res.Headers, "etag"),
+		LastModified: store.HeaderFirst(res.Headers, "last-modified"),
+		FetchedAt:    time.Now().Format(time.RFC3339),
+	}
+	result.entry = entry
+
+	if prev != nil && statErr == nil && prev.SHA256 == entry.SHA256 {
+		result.Result = "unchanged"
+		return result
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		result.Result, result.Error, result.entry = "failed", err.Error(), nil
+		return keepPrevious(result, prev)
+	}
+	if err := os.WriteFile(target, []byte(res.Body), 0644); err != nil {
+		result.Result, result.Error, result.entry = "failed", err.Error(), nil
+		return keepPrevious(result, prev)
+	}
+	result.Result = "downloaded"
+	if prev != nil {
+		result.Result = "updated"
+	}
+	return result
+}
+
+// keepPrevious leaves a failed script's old manifest entry in place
+func keepPrevious(result jsDownloadResult, prev *JSManifestEntry) jsDownloadResult {
+	result.entry = prev
+	return result
+}
+
+// jsFilePath maps a script URL to <host>/<path> under the download
+// directory. A query string gets a short hash suffix so versions of the same
+// path don't collide; directory URLs become index.js.
+func jsFilePath(raw string) (string, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("invalid URL")
+	}
+
+	segments := []string{safePathSegment(parsed.Host)}
+	p := parsed.Path
+	if p == "" || strings.HasSuffix(p, "/") {
+		p += "index.js"
+	}
+	for _, segment := range strings.Split(strings.Trim(p, "/"), "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+		segments = append(segments, safePathSegment(segment))
+	}
+
+	if parsed.RawQuery != "" {
+		sum := sha256.Sum256([]byte(parsed.RawQuery))
+		last := segments[len(segments)-1]
+		ext := path.Ext(last)
+		segments[len(segments)-1] = strings.TrimSuffix(last, ext) + "." + hex.EncodeToString(sum[:4]) + ext
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// safePathSegment replaces characters that are invalid in file names on
+// some platforms
+func safePathSegment(s string) string {
+	if decoded, err := url.PathUnescape(s); err == nil {
+		s = decoded
+	}
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "." || s == ".." {
+		return "_"
+	}
+	if len(s) > 200 {
+		s = s[:200]
+	}
+	return s
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/extract"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+var (
+	jsEndpoints   bool
+	jsUnrequested bool
+)
+
+// JSEndpoint is a candidate endpoint found in captured scripts
+type JSEndpoint struct {
+	Path     string   `json:"path"`
+	Method   string   `json:"method,omitempty"`
+	Via      []string `json:"via"`
+	Observed bool     `json:"observed"` // A captured request matched it
+	Scripts  []string `json:"scripts"`  // Script URLs it appears in (first 3)
+	Count    int      `json:"count"`    // Scripts it appears in
+}
+
+// findJSEndpoints extracts endpoints from the bodies of the scripts and
+// marks those seen in traffic
+func findJSEndpoints(scripts []store.Request) error {
+	byKey := make(map[string]*JSEndpoint)
+	scanned := 0
+	seen := make(map[string]bool)
+	for i := range scripts {
+		req := &scripts[i]
+		if seen[req.URL] {
+			continue
+		}
+		seen[req.URL] = true
+		if req.Response == nil || req.Response.Body == "" {
+			continue
+		}
+		scanned++
+		for _, ep := range extract.Endpoints(store.ResponseBodyText(req)) {
+			key := ep.Method + " " + ep.Path
+			found := byKey[key]
+			if found == nil {
+				found = &JSEndpoint{Path: ep.Path, Method: ep.Method}
+				byKey[key] = found
+			}
+			for _, via := range ep.Via {
+				if !containsString(found.Via, via) {
+					found.Via = append(found.Via, via)
+				}
+			}
+			found.Count++
+			if len(found.Scripts) < 3 {
+				found.Scripts = append(found.Scripts, req.URL)
+			}
+		}
+	}
+
+	keys, err := capturedURLKeys(jsSaved)
+	if err != nil {
+		return err
+	}
+	captured := make(map[string]bool, len(keys))
+	hosts := make(map[string]bool)
+	for key := range keys {
+		if idx := strings.Index(key, "/"); idx >= 0 {
+			captured[store.EndpointTemplate(key[idx:])] = true
+			hosts[key[:idx]] = true
+		}
+	}
+
+	endpoints := make([]JSEndpoint, 0, len(byKey))
+	unrequested := 0
+	for _, ep := range byKey {
+		ep.Observed = endpointObserved(ep.Path, captured, hosts)
+		if !ep.Observed {
+			unrequested++
+		} else if jsUnrequested {
+			continue
+		}
+		endpoints = append(endpoints, *ep)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Path != endpoints[j].Path {
+			return endpoints[i].Path < endpoints[j].Path
+		}
+		return endpoints[i].Method < endpoints[j].Method
+	})
+
+	if jsURLs {
+		printed := make(map[string]bool)
+		for _, ep := range endpoints {
+			if !printed[ep.Path] {
+				printed[ep.Path] = true
+				fmt.Println(ep.Path)
+			}
+		}
+		return nil
+	}
+
+	if getOutputMode() == "json" {
+		out, _ := sonic.MarshalIndent(map[string]interface{}{
+			"scripts":     scanned,
+			"total":       len(byKey),
+			"unrequested": unrequested,
+			"endpoints":   endpoints,
+		}, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(endpoints) == 0 {
+		return softFail(emptyError("No endpoints found in %d script bodies", scanned))
+	}
+	pterm.DefaultSection.Printf("Endpoints in JavaScript (%d, %d never requested)\n", len(byKey), unrequested)
+	for _, ep := range endpoints {
+		mark := pterm.FgGray.Sprint("✓")
+		if !ep.Observed {
+			mark = pterm.FgYellow.Sprint("★")
+		}
+		method := ep.Method
+		if method == "" {
+			method = "-"
+		}
+		script := ep.Scripts[0]
+		if parsed, err := url.Parse(script); err == nil {
+			script = path.Base(parsed.Path)
+		}
+		if ep.Count > 1 {
+			script += fmt.Sprintf(" +%d", ep.Count-1)
+		}
+		fmt.Printf("  %s %-7s %s  %s\n", mark, method, output.SanitizeText(ep.Path),
+			pterm.FgGray.Sprintf("(%s; %s)", strings.Join(ep.Via, ","), output.SanitizeText(script)))
+	}
+	fmt.Println()
+	pterm.Info.Printf("Scanned %d scripts. ★ = never requested in captured traffic (--unrequested to list only those)\n", scanned)
+	return nil
+}
+
+// endpointObserved reports whether a captured request matches the path,
+// treating {param} and ID-like segments as wildcards. Relative paths match
+// any captured path they end; a bare origin matches when its host was
+// requested.
+func endpointObserved(raw string, captured, hosts map[string]bool) bool {
+	p := raw
+	if strings.Contains(p, "://") || strings.HasPrefix(p, "//") {
+		if !strings.Contains(p, "://") {
+			p = "https:" + p
+		}
+		parsed, err := url.Parse(p)
+		if err != nil {
+			return false
+		}
+		if parsed.Path == "" || parsed.Path == "/" {
+			return hosts[strings.ToLower(parsed.Hostname())]
+		}
+		p = parsed.Path
+	}
+	if idx := strings.IndexAny(p, "?#"); idx >= 0 {
+		p = p[:idx]
+	}
+	relative := !strings.HasPrefix(p, "/")
+	// "/v1/users/" + id: any captured path below it
+	prefix := strings.HasSuffix(p, "/") && len(p) > 1
+	for strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") {
+		p = p[strings.Index(p, "/")+1:]
+	}
+	p = strings.ReplaceAll(store.EndpointTemplate("/"+strings.TrimPrefix(p, "/")), "{param}", "{id}")
+	if p == "/" {
+		return false
+	}
+
+	if captured[p] {
+		return true
+	}
+	dir := strings.TrimSuffix(p, "/") + "/"
+	for key := range captured {
+		if prefix && (strings.HasPrefix(key, dir) || (relative && strings.Contains(key, dir))) {
+			return true
+		}
+		if relative && strings.HasSuffix(key, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/jslibs"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+var jsLibs bool
+
+// JSLibrary is a library version found in captured scripts
+type JSLibrary struct {
+	Library    string            `json:"library"`
+	Version    string            `json:"version,omitempty"`
+	Via        string            `json:"via"`     // url, content
+	Scripts    []string          `json:"scripts"` // Script URLs it was found in (first 3)
+	Count      int               `json:"count"`
+	Advisories []jslibs.Advisory `json:"advisories,omitempty"`
+}
+
+// fingerprintJSLibs identifies libraries in the scripts and their known
+// advisories
+func fingerprintJSLibs(scripts []store.Request) error {
+	byKey := make(map[string]*JSLibrary)
+	seen := make(map[string]bool)
+	for i := range scripts {
+		req := &scripts[i]
+		if seen[req.URL] {
+			continue
+		}
+		seen[req.URL] = true
+		body := ""
+		if req.Response != nil {
+			body = store.ResponseBodyText(req)
+		}
+		for _, d := range jslibs.Detect(req.URL, body) {
+			key := d.Library + "@" + d.Version
+			lib := byKey[key]
+			if lib == nil {
+				lib = &JSLibrary{Library: d.Library, Version: d.Version, Via: d.Via}
+				byKey[key] = lib
+			}
+			lib.Count++
+			if len(lib.Scripts) < 3 {
+				lib.Scripts = append(lib.Scripts, req.URL)
+			}
+		}
+	}
+
+	libs := make([]JSLibrary, 0, len(byKey))
+	vulnerable := 0
+	for _, lib := range byKey {
+		// A versionless sighting adds nothing when a version is known
+		if lib.Version == "" && hasVersionedLib(byKey, lib.Library) {
+			continue
+		}
+		lib.Advisories = jslibs.Advisories(lib.Library, lib.Version)
+		if len(lib.Advisories) > 0 {
+			vulnerable++
+		}
+		libs = append(libs, *lib)
+	}
+	sort.Slice(libs, func(i, j int) bool {
+		if libs[i].Library != libs[j].Library {
+			return libs[i].Library < libs[j].Library
+		}
+		return jslibs.CompareVersions(libs[i].Version, libs[j].Version) < 0
+	})
+
+	if getOutputMode() == "json" {
+		out, _ := sonic.MarshalIndent(map[string]interface{}{
+			"libraries":  libs,
+			"vulnerable": vulnerable,
+		}, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(libs) == 0 {
+		return softFail(emptyError("No known libraries identified"))
+	}
+	pterm.DefaultSection.Printf("JavaScript Libraries (%d, %d with known advisories)\n", len(libs), vulnerable)
+	for _, lib := range libs {
+		version := lib.Version
+		if version == "" {
+			version = pterm.FgGray.Sprint("?")
+		}
+		where := pterm.FgGray.Sprintf("(%s, %d script(s))", lib.Via, lib.Count)
+		fmt.Printf("  %-12s %-10s %s\n", lib.Library, version, where)
+		for _, a := range lib.Advisories {
+			fixed := ""
+			if a.Fixed != "" {
+				fixed = pterm.FgGray.Sprintf(" fixed in %s", a.Fixed)
+			}
+			fmt.Printf("      %s %s %s%s\n", severityLabel(a.Severity), a.ID, a.Summary, fixed)
+		}
+		if len(lib.Advisories) > 0 {
+			fmt.Printf("      %s\n", pterm.FgGray.Sprint(output.SanitizeText(strings.Join(lib.Scripts, " "))))
+		}
+	}
+	fmt.Println()
+	pterm.Info.Println("Advisory data is a curated subset; a clean result is not proof of safety")
+	return nil
+}
+
+func hasVersionedLib(libs map[string]*JSLibrary, library string) bool {
+	for _, lib := range libs {
+		if lib.Library == library && lib.Version != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// severityLabel colors an advisory severity
+func severityLabel(severity string) string {
+	label := fmt.Sprintf("[%s]", strings.ToUpper(severity))
+	switch severity {
+	case "critical", "high":
+		return pterm.FgRed.Sprint(label)
+	case "medium":
+		return pterm.FgYellow.Sprint(label)
+	default:
+		return pterm.FgGray.Sprint(label)
+	}
+}
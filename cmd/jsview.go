@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/jsbeautify"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	jsViewSaved   string
+	jsViewGrep    string
+	jsViewContext int
+	jsViewLines   string
+	jsViewRaw     bool
+	jsViewNoPager bool
+)
+
+// JSViewMatch is one --grep hit in the beautified script
+type JSViewMatch struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+var jsViewCmd = &cobra.Command{
+	Use:   "view <url|request-id>",
+	Short: "Pretty-print a captured script",
+	Long: `Show a captured JavaScript response re-indented for reading, straight from
+the capture: no download step.
+
+The argument is a request ID or a script URL. A partial URL works when it
+matches one script (e.g. "app.9c1d.js").
+
+The beautifier is token-based, not a parser: strings, regexes and comments
+are kept verbatim and lines break at braces, statements and object members.
+Good enough to read and grep a bundle, not to re-run it.
+
+Output longer than the terminal goes through $PAGER (default "less -R");
+piped output is never paged.
+
+Examples:
+  rep js view req_42                       Beautified script in a pager
+  rep js view app.9c1d.js                  Same, by (partial) URL
+  rep js view req_42 --grep 'api/v[0-9]'   Matching lines with 2 lines of context
+  rep js view req_42 --grep token -C 5
+  rep js view req_42 --lines 120:180       A line range
+  rep js view req_42 --raw --no-pager      The body as captured
+  rep js view req_42 -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := findJSViewRequest(args[0], jsViewSaved)
+		if err != nil || req == nil {
+			return err
+		}
+		body := ""
+		if req.Response != nil {
+			body = store.ResponseBodyText(req)
+		}
+		if body == "" {
+			return softFail(emptyError("No response body captured for %s", req.URL))
+		}
+
+		text := body
+		if !jsViewRaw {
+			text = jsbeautify.Beautify(body)
+		}
+		lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+
+		first, last := 1, len(lines)
+		if jsViewLines != "" {
+			first, last, err = parseLineRange(jsViewLines, len(lines))
+			if err != nil {
+				return err
+			}
+		}
+
+		if jsViewGrep != "" {
+			pattern, err := regexp.Compile(jsViewGrep)
+			if err != nil {
+				return fmt.Errorf("invalid --grep pattern: %w", err)
+			}
+			return printJSViewGrep(req, lines, first, last, pattern)
+		}
+
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"id":      req.ID,
+				"url":     req.URL,
+				"lines":   len(lines),
+				"from":    first,
+				"to":      last,
+				"content": strings.Join(lines[first-1:last], "\n"),
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		var b strings.Builder
+		width := len(strconv.Itoa(last))
+		for i := first; i <= last; i++ {
+			fmt.Fprintf(&b, "%s  %s\n", pterm.FgGray.Sprintf("%*d", width, i), output.SanitizeText(lines[i-1]))
+		}
+		header := fmt.Sprintf("%s  %s\n\n", req.ID, output.SanitizeText(req.URL))
+		pageOutput(header+b.String(), last-first+1)
+		return nil
+	},
+}
+
+// findJSViewRequest resolves a request ID or (partial) script URL
+func findJSViewRequest(arg, saved string) (*store.Request, error) {
+	if !strings.Contains(arg, "/") {
+		req, err := lookupRequest(arg, saved)
+		if err != nil || req != nil {
+			return req, err
+		}
+	}
+
+	tempStore, err := loadSourceStore(saved)
+	if err != nil || tempStore == nil {
+		return nil, err
+	}
+	scripts := getJSRequests(tempStore)
+	var matches []*store.Request
+	seen := make(map[string]bool)
+	for i := range scripts {
+		if scripts[i].URL == arg {
+			return &scripts[i], nil
+		}
+		if strings.Contains(scripts[i].URL, arg) && !seen[scripts[i].URL] {
+			seen[scripts[i].URL] = true
+			matches = append(matches, &scripts[i])
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, notFoundError("no request or captured script matches %s", arg)
+	case 1:
+		return matches[0], nil
+	}
+	pterm.Warning.Printf("%d scripts match %s; be more specific:\n", len(matches), arg)
+	for _, m := range matches {
+		fmt.Printf("  %s  %s\n", m.ID, output.SanitizeText(m.URL))
+	}
+	return nil, nil
+}
+
+// parseLineRange parses "a:b", "a:" or ":b" (1-based, inclusive) and clamps
+// it to the script
+func parseLineRange(spec string, total int) (int, int, error) {
+	from, to, ok := strings.Cut(spec, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("--lines must be START:END (e.g. 100:200)")
+	}
+	first, last := 1, total
+	var err error
+	if from != "" {
+		if first, err = strconv.Atoi(from); err != nil || first < 1 {
+			return 0, 0, fmt.Errorf("invalid --lines start: %s", from)
+		}
+	}
+	if to != "" {
+		if last, err = strconv.Atoi(to); err != nil || last < 1 {
+			return 0, 0, fmt.Errorf("invalid --lines end: %s", to)
+		}
+	}
+	if last > total {
+		last = total
+	}
+	if first > last {
+		return 0, 0, fmt.Errorf("--lines %s is outside the script (%d lines)", spec, total)
+	}
+	return first, last, nil
+}
+
+// printJSViewGrep prints the lines matching pattern with -C lines of
+// context, grep-style ("--" between groups)
+func printJSViewGrep(req *store.Request, lines []string, first, last int, pattern *regexp.Regexp) error {
+	var matches []JSViewMatch
+	for i := first; i <= last; i++ {
+		if pattern.MatchString(lines[i-1]) {
+			matches = append(matches, JSViewMatch{Line: i, Text: lines[i-1]})
+		}
+	}
+
+	if getOutputMode() == "json" {
+		if matches == nil {
+			matches = []JSViewMatch{}
+		}
+		out, _ := sonic.MarshalIndent(map[string]interface{}{
+			"id":      req.ID,
+			"url":     req.URL,
+			"lines":   len(lines),
+			"pattern": pattern.String(),
+			"matches": matches,
+		}, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(matches) == 0 {
+		return softFail(emptyError("No lines match %s", pattern))
+	}
+
+	var b strings.Builder
+	width := len(strconv.Itoa(last))
+	printed := 0 // Last line number printed
+	count := 0
+	for _, m := range matches {
+		start, end := m.Line-jsViewContext, m.Line+jsViewContext
+		if start < first {
+			start = first
+		}
+		if end > last {
+			end = last
+		}
+		if start <= printed {
+			start = printed + 1
+		} else if printed > 0 {
+			b.WriteString(pterm.FgGray.Sprint("--") + "\n")
+		}
+		for i := start; i <= end; i++ {
+			text := output.SanitizeText(lines[i-1])
+			number := pterm.FgGray.Sprintf("%*d", width, i)
+			if pattern.MatchString(lines[i-1]) {
+				text = pattern.ReplaceAllStringFunc(text, func(s string) string { return pterm.FgRed.Sprint(s) })
+				number = pterm.FgGreen.Sprintf("%*d", width, i)
+			}
+			fmt.Fprintf(&b, "%s  %s\n", number, text)
+			count++
+		}
+		printed = end
+	}
+	header := fmt.Sprintf("%s  %s  (%d matching lines)\n\n", req.ID, output.SanitizeText(req.URL), len(matches))
+	pageOutput(header+b.String(), count)
+	return nil
+}
+
+// pageOutput writes text through $PAGER when stdout is a terminal and the
+// text is taller than it; otherwise it prints directly
+func pageOutput(text string, lines int) {
+	if jsViewNoPager || lines < pterm.GetTerminalHeight()-2 || !stdoutIsTerminal() {
+		fmt.Print(text)
+		return
+	}
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less -R"
+	}
+	fields := strings.Fields(pager)
+	if len(fields) == 0 {
+		fmt.Print(text)
+		return
+	}
+	c := exec.Command(fields[0], fields[1:]...)
+	c.Stdin = strings.NewReader(text)
+	c.Stdout, c.Stderr = os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		// Pager missing or killed: fall back to plain output
+		if _, ok := err.(*exec.ExitError); !ok {
+			fmt.Print(text)
+		}
+	}
+}
+
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func init() {
+	jsCmd.AddCommand(jsViewCmd)
+	jsViewCmd.Flags().StringVar(&jsViewSaved, "saved", "", "Read from a saved session (ID or 'latest')")
+	jsViewCmd.Flags().StringVar(&jsViewGrep, "grep", "", "Show only lines matching this regex (with context)")
+	jsViewCmd.Flags().IntVarP(&jsViewContext, "context", "C", 2, "Lines of context around --grep matches")
+	jsViewCmd.Flags().StringVar(&jsViewLines, "lines", "", "Line range START:END of the beautified script")
+	jsViewCmd.Flags().BoolVar(&jsViewRaw, "raw", false, "Show the body as captured, without beautifying")
+	jsViewCmd.Flags().BoolVar(&jsViewNoPager, "no-pager", false, "Never page the output")
+}
@@ -1,35 +1,34 @@
 package cmd
 
 import (
+	"encoding/csv"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 	"strings"
 
-	"github.com/bytedance/sonic"
 	"github.com/pterm/pterm"
 	"github.com/repplus/rep-cli/internal/output"
 	"github.com/repplus/rep-cli/internal/store"
+	"github.com/repplus/rep-cli/internal/triage"
 	"github.com/spf13/cobra"
 )
 
 var (
-	listDomain         string
-	listMethod         string
-	listStatus         int
-	listStatusRange    string
-	listPattern        string
-	listLimit          int
-	listOffset         int
-	listPrimary        bool
-	listIncludeIgnored bool
-	listLine           bool
-	listDetail         bool
-	// New flags for agent-optimized filtering
-	listType        string // Comma-separated resource types: script,xhr,fetch,document
-	listAPI         bool   // Preset: API calls only (xmlhttprequest,fetch)
-	listInteresting bool   // Preset: Error responses + state-changing methods
-	listErrors      bool   // Preset: Only error responses (4xx/5xx)
-	listMutations   bool   // Preset: Only state-changing methods
-	listSaved       string // Session ID to read from saved sessions
+	listFilter requestFilterFlags
+	listLimit  int
+	listOffset int
+	listSort   string
+	listDesc   bool
+	listGroup  string
+	listUnique bool
+	listUniqEP bool // --unique-endpoint
+	listLine   bool
+	listDetail bool
+	listSaved  string // Session ID to read from saved sessions
+	listFollow bool
+	listTop    int
 )
 
 var listCmd = &cobra.Command{
@@ -52,11 +51,35 @@ Presets (agent-optimized shortcuts):
   --mutations    Only state-changing methods (POST/PUT/DELETE/PATCH)
   --interesting  Errors + mutations combined
 
+Exclusions (comma-separated, applied after the filters above):
+  --not-domain        Domains or @groups to drop
+  --not-method        e.g. OPTIONS,HEAD
+  --not-type          Resource types, e.g. image,font,preflight
+  --not-status-range  e.g. 3xx,404
+  --not-pattern       URL regex to drop
+
+Ranking (--top N):
+  Scores every match with weighted signals (credentials sent, admin/internal
+  path keywords, numeric ids, 5xx and 401/403, verbose error bodies, stack
+  traces, large responses, one-off endpoints) and shows the N highest.
+  JSON output lists each signal with its weight and an explanation.
+
+Deduplication:
+  --unique           One row per method+URL with a count and the latest ID
+  --unique-endpoint  One row per method+endpoint template (/users/{id})
+
 Data sources:
   (default)              Show live.json (real-time, same as extension)
   --saved <id>           Show saved session by ID/prefix
   --saved latest         Show most recent saved session
 
+Following (--follow):
+  Prints the current matches, then each new matching request as it is
+  captured (Ctrl-C to stop). With the native host started with
+  REP_HOST_SOCKET=1 new requests are pushed over a local socket, and a
+  request is printed again when its response arrives later; otherwise
+  live.json is re-read every 2s. JSON output becomes NDJSON.
+
 Examples:
   rep list                          List requests to primary domains
   rep list --primary=false          List ALL requests (bypass primary filter)
@@ -64,149 +87,98 @@ Examples:
   rep list --saved 20231227         List session starting with 20231227
   rep list --api                    Only API calls (xhr/fetch)
   rep list --interesting            Errors + state-changing methods
+  rep list --top 20                 The 20 most interesting requests, scored
+  rep list --top 10 --api -o json   Scores with per-signal explanations
   rep list --type script            Only JavaScript files
   rep list --detail                 Multi-line request output
   rep list -d api.example.com       Filter by domain
   rep list -m POST                  Filter by method
   rep list --status 200             Filter by exact status
   rep list --status-range 4xx       Filter by status range
+  rep list --since 5m               Requests from the last 5 minutes
+  rep list --not-method OPTIONS --not-type image,font   Skip preflights and assets
+  rep list --sort size --desc -l 5  Five largest responses
+  rep list --min-size 100k          Large responses (possible data dumps)
+  rep list --req-header authorization           Only authenticated requests
+  rep list --resp-header "x-cache:HIT"          Cached responses
+  rep list --resp-body-pattern is_admin         Responses mentioning is_admin
+  rep list --body-pattern '"role":\s*"'         Request bodies setting a role
+  rep list -p /v1/orders --max-size 0   Empty responses for an endpoint
+  rep list --sort time --desc       Latest requests first
+  rep list --sort duration --desc -l 10  Ten slowest requests (needs timing from the extension)
+  rep list --group-by endpoint      One heading per endpoint with counts
+  rep list --unique                 Collapse repeated polling calls
+  rep list --unique-endpoint --api  One row per API endpoint template
+  rep list -o ndjson | jq -c '.response.status'   One JSON request per line
+  rep list -o csv > requests.csv    Metadata as CSV (no bodies)
+  rep list --since 2024-01-15T10:00:00Z --until 2024-01-15T11:00:00Z
   rep list -p "api/v1"              Filter by URL pattern (regex)
   rep list --limit 10               Limit results
   rep list -o full                  Show full response bodies
   rep list --line | rg "Login"      Grep-friendly one-line output with IDs
+  rep list --follow --api           Tail new API calls as they are captured
+  rep list --follow -o ndjson | jq -c .url   Stream new requests into jq
   rep body <id>                     Fetch full response body by ID`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Apply presets before building filter
-		resourceTypes := parseCommaSeparated(listType)
-		methods := parseCommaSeparated(listMethod)
-		statusRanges := []string{}
-
-		if listAPI {
-			// Preset: API calls only (xhr/fetch)
-			resourceTypes = []string{"xmlhttprequest", "fetch"}
+		// Build filter options (presets applied)
+		opts, err := listFilter.options()
+		if err != nil {
+			return err
 		}
-
-		if listInteresting {
-			// Preset: Error responses + state-changing methods
-			statusRanges = []string{"4xx", "5xx"}
-			if len(methods) == 0 {
-				methods = []string{"POST", "PUT", "DELETE", "PATCH"}
-			}
+		opts.Limit = listLimit
+		opts.Offset = listOffset
+		opts.SortBy = strings.ToLower(listSort)
+		opts.SortDesc = listDesc
+		if err := store.ValidateSortField(opts.SortBy); err != nil {
+			return err
 		}
-
-		if listErrors {
-			// Preset: Only error responses
-			statusRanges = []string{"4xx", "5xx"}
+		groupBy := strings.ToLower(listGroup)
+		if groupBy != "" && listGroupKeys[groupBy] == nil {
+			return fmt.Errorf("invalid group-by field: %s (use domain, endpoint, status, page)", listGroup)
 		}
-
-		if listMutations {
-			// Preset: Only state-changing methods
-			if len(methods) == 0 {
-				methods = []string{"POST", "PUT", "DELETE", "PATCH"}
-			}
+		unique := listUnique || listUniqEP
+		if unique && groupBy != "" {
+			return fmt.Errorf("--unique cannot be combined with --group-by")
 		}
-
-		// Build filter options
-		opts := store.FilterOptions{
-			Domain:         listDomain,
-			Method:         strings.ToUpper(listMethod),
-			Methods:        methods,
-			Status:         listStatus,
-			StatusRange:    listStatusRange,
-			StatusRanges:   statusRanges,
-			ResourceTypes:  resourceTypes,
-			Pattern:        listPattern,
-			Limit:          listLimit,
-			Offset:         listOffset,
-			PrimaryOnly:    listPrimary,
-			ExcludeIgnored: !listIncludeIgnored,
-		}
-
-		var requests []store.Request
-		var totalCount int
-
-		if listSaved != "" {
-			// Load from saved session in store.json
-			s, err := store.Get()
-			if err != nil {
-				return fmt.Errorf("failed to load store: %w", err)
+		if listTop > 0 {
+			if listFollow || unique || groupBy != "" {
+				return fmt.Errorf("--top can't be combined with --follow, --unique or --group-by")
 			}
-
-			var session *store.Session
-			if listSaved == "latest" || listSaved == "last" {
-				session = s.GetLatestSession()
-			} else {
-				session = s.GetSession(listSaved)
+			opts.Limit, opts.Offset, opts.SortBy = 0, 0, ""
+			requests, _, ok, err := listSource(opts)
+			if err != nil || !ok {
+				return err
 			}
-
-			if session == nil {
-				pterm.Warning.Printf("Session not found: %s\n", listSaved)
-				pterm.Info.Println("Use 'rep sessions' to list available sessions")
-				return nil
+			return listTopRequests(requests, listTop)
+		}
+		if listFollow {
+			if listSaved != "" || unique || groupBy != "" {
+				return fmt.Errorf("--follow reads live data and can't be combined with --saved, --unique or --group-by")
 			}
+			return followRequests(opts)
+		}
 
-			// Create temp store for filtering
-			tempStore := store.NewTempStore(session.Requests)
-			tempStore.PrimaryDomains = s.PrimaryDomains
-			tempStore.IgnoredDomains = s.IgnoredDomains
-			tempStore.MutedPaths = s.MutedPaths
-
-			if listPrimary && len(s.GetPrimaryDomains()) == 0 {
-				pterm.Info.Println("No primary domains set. Use 'rep primary <domain>' to add.")
-				return nil
-			}
+		// Live NDJSON and CSV rows are written as they are matched
+		if format := getOutputMode(); (format == "ndjson" || format == "csv") && listSaved == "" && !unique && groupBy == "" {
+			return streamLiveList(opts, format)
+		}
 
-			// Get total count first (without limit)
-			if opts.Limit > 0 {
-				unlimitedOpts := opts
-				unlimitedOpts.Limit = 0
-				unlimitedOpts.Offset = 0
-				totalCount = len(tempStore.Filter(unlimitedOpts))
-			}
-			requests = tempStore.Filter(opts)
-		} else {
-			// Default: Load from live.json (real-time, same as extension)
-			livePath, err := store.GetLiveFilePath()
-			if err != nil {
-				return fmt.Errorf("failed to get live path: %w", err)
-			}
-			export, err := loadLiveExport(livePath)
-			if err != nil {
-				pterm.Warning.Printf("Could not read live.json: %v\n", err)
-				pterm.Info.Println("Enable auto-export in rep+ extension first")
-				return nil
-			}
-			if len(export.Requests) == 0 {
-				pterm.Info.Println("No requests captured yet (live session empty)")
-				return nil
-			}
-			// Filter live requests using store's filter logic
-			tempStore := store.NewTempStore(export.Requests)
-			// Load ignore/primary/mute lists from persistent store
-			s, err := store.Get()
-			if err == nil {
-				tempStore.PrimaryDomains = s.PrimaryDomains
-				tempStore.IgnoredDomains = s.IgnoredDomains
-				tempStore.MutedPaths = s.MutedPaths
-			}
-			if listPrimary && len(tempStore.GetPrimaryDomains()) == 0 {
-				pterm.Info.Println("No primary domains set. Use 'rep primary <domain>' to add.")
-				return nil
-			}
+		// --unique pages over rows, so it needs every match
+		sourceOpts := opts
+		if unique {
+			sourceOpts.Limit, sourceOpts.Offset = 0, 0
+		}
+		requests, totalCount, ok, err := listSource(sourceOpts)
+		if err != nil || !ok {
+			return err
+		}
 
-			// Get total count first (without limit)
-			if opts.Limit > 0 {
-				unlimitedOpts := opts
-				unlimitedOpts.Limit = 0
-				unlimitedOpts.Offset = 0
-				totalCount = len(tempStore.Filter(unlimitedOpts))
-			}
-			requests = tempStore.Filter(opts)
+		if unique {
+			return listUniqueRequests(requests, opts.Limit, opts.Offset)
 		}
 
 		if len(requests) == 0 {
-			pterm.Info.Println("No requests match the filter")
-			return nil
+			return softFail(emptyError("No requests match the filter"))
 		}
 
 		// Determine output mode
@@ -220,24 +192,409 @@ Examples:
 			mode = store.OutputJSON
 		}
 
-		if mode == store.OutputJSON || getOutputMode() == "json" {
-			formatted := output.FormatRequests(requests, mode)
-			out, _ := sonic.MarshalIndent(formatted, "", "  ")
-			fmt.Println(string(out))
+		if format := getOutputMode(); format == "ndjson" || format == "csv" {
+			var key func(req *store.Request) string
+			if groupBy != "" {
+				key = listGroupKeys[groupBy]
+			}
+			return streamRequests(os.Stdout, requests, format, key)
+		}
+
+		if groupBy != "" {
+			if opts.Limit > 0 && totalCount > len(requests) {
+				jsonWarn("showing %d of %d requests (--limit)", len(requests), totalCount)
+			}
+			printRequestGroups(groupRequests(requests, listGroupKeys[groupBy]), mode, listLine && !listDetail)
+			if opts.Limit > 0 && totalCount > len(requests) && mode != store.OutputJSON {
+				fmt.Printf("[Showing %d of %d requests]\n", len(requests), totalCount)
+			}
 			return nil
 		}
 
 		useLine := listLine && !listDetail && mode == store.OutputCompact
+		view, report := fitRequests(requestView{
+			requests: requests,
+			mode:     mode,
+			cfg:      truncateConfig(),
+			line:     useLine && getOutputMode() != "json",
+			offset:   opts.Offset,
+		})
+		if report.trimmed() {
+			requests, mode = view.requests, view.mode
+			maxBodySize = view.cfg.MaxBodySize
+		}
+
+		if mode == store.OutputJSON || getOutputMode() == "json" {
+			formatted := output.FormatRequests(requests, mode, view.cfg)
+			next := report.warn()
+			if opts.Limit > 0 && totalCount > len(requests) {
+				jsonWarn("showing %d of %d requests (--limit)", len(requests), totalCount)
+				next = append(next, fmt.Sprintf("repeat with --offset %d for the next page", opts.Offset+len(requests)))
+			}
+			if len(requests) > 0 {
+				next = append(next, "rep body "+requests[0].ID, "rep curl "+requests[0].ID)
+			}
+			printJSON("list", formatted, next...)
+			return nil
+		}
+
 		if useLine {
 			printRequestsLine(requests, totalCount, opts.Limit)
 		} else {
 			printRequests(requests, mode, totalCount, opts.Limit)
 		}
+		report.print()
 
 		return nil
 	},
 }
 
+// listSource returns the requests matching opts and, when opts.Limit is set,
+// the total match count. live.json is filtered while streaming; in meta
+// output bodies are not even kept in memory.
+func listSource(opts store.FilterOptions) (requests []store.Request, totalCount int, ok bool, err error) {
+	if listSaved == "" {
+		sopts := store.StreamOptions{
+			SkipBodies: getOutputMode() == "meta" && !opts.NeedsBodies(),
+			CountAll:   opts.Limit > 0,
+		}
+		res, ok, err := streamLiveSource(opts, sopts)
+		if err != nil || !ok {
+			return nil, 0, ok, err
+		}
+		return res.Requests, res.Matched, true, nil
+	}
+
+	tempStore, err := loadSourceStore(listSaved)
+	if err != nil || tempStore == nil {
+		return nil, 0, false, err
+	}
+
+	if opts.PrimaryOnly && len(tempStore.GetPrimaryDomains()) == 0 {
+		pterm.Info.Println("No primary domains set. Use 'rep primary <domain>' to add.")
+		return nil, 0, false, nil
+	}
+
+	// Get total count first (without limit)
+	if opts.Limit > 0 {
+		unlimitedOpts := opts
+		unlimitedOpts.Limit = 0
+		unlimitedOpts.Offset = 0
+		unlimitedOpts.SortBy = ""
+		totalCount = len(tempStore.Filter(unlimitedOpts))
+	}
+	return tempStore.Filter(opts), totalCount, true, nil
+}
+
+// uniqueRequest is one --unique row: the latest request for a key
+type uniqueRequest struct {
+	Key     string
+	Count   int
+	FirstID string
+	Latest  store.Request
+}
+
+// uniqueKey returns the --unique key for req
+func uniqueKey(req *store.Request, endpoint bool) string {
+	if endpoint {
+		return req.Method + " " + req.Domain + store.EndpointTemplate(req.Path)
+	}
+	return req.Method + " " + req.URL
+}
+
+// uniqueRequests collapses requests by key, ordered by first appearance
+func uniqueRequests(requests []store.Request, endpoint bool) []uniqueRequest {
+	var rows []uniqueRequest
+	index := make(map[string]int)
+	for _, req := range requests {
+		k := uniqueKey(&req, endpoint)
+		i, ok := index[k]
+		if !ok {
+			i = len(rows)
+			index[k] = i
+			rows = append(rows, uniqueRequest{Key: k, FirstID: req.ID, Latest: req})
+		}
+		rows[i].Count++
+		if req.Timestamp >= rows[i].Latest.Timestamp {
+			rows[i].Latest = req
+		}
+	}
+	return rows
+}
+
+// listUniqueRequests prints one row per unique key. Offset/limit apply to
+// rows, not requests.
+// listUniqueOutput is one row of rep list --unique -o json
+type listUniqueOutput struct {
+	Key      string               `json:"key"`
+	Count    int                  `json:"count"`
+	FirstID  string               `json:"first_id"`
+	LatestID string               `json:"latest_id"`
+	Latest   output.RequestOutput `json:"latest"`
+}
+
+func listUniqueRequests(requests []store.Request, limit, offset int) error {
+	rows := uniqueRequests(requests, listUniqEP)
+	total := len(rows)
+	if offset > 0 {
+		if offset >= len(rows) {
+			rows = nil
+		} else {
+			rows = rows[offset:]
+		}
+	}
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+	if len(rows) == 0 {
+		return softFail(emptyError("No requests match the filter"))
+	}
+
+	switch getOutputMode() {
+	case "json", "ndjson":
+		cfg := truncateConfig()
+		result := make([]listUniqueOutput, len(rows))
+		for i, row := range rows {
+			result[i] = listUniqueOutput{
+				Key:      row.Key,
+				Count:    row.Count,
+				FirstID:  row.FirstID,
+				LatestID: row.Latest.ID,
+				Latest:   output.FormatRequest(&row.Latest, store.OutputJSON, cfg),
+			}
+		}
+		if getOutputMode() == "json" {
+			if total > offset+len(rows) {
+				jsonWarn("showing %d of %d unique requests (--limit)", len(rows), total)
+			}
+			printJSON("list/unique", result)
+			return nil
+		}
+		nw := output.NewNDJSONWriter(os.Stdout)
+		for _, r := range result {
+			if err := nw.Write(r); err != nil {
+				return err
+			}
+		}
+		return nw.Flush()
+	case "csv":
+		cw := csv.NewWriter(os.Stdout)
+		if err := cw.Write(append([]string{"key", "count"}, output.RequestCSVHeader...)); err != nil {
+			return err
+		}
+		for i := range rows {
+			row := append([]string{rows[i].Key, strconv.Itoa(rows[i].Count)}, output.RequestCSVRow(&rows[i].Latest)...)
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	for _, row := range rows {
+		status := 0
+		if row.Latest.Response != nil {
+			status = row.Latest.Response.Status
+		}
+		target := row.Latest.URL
+		if listUniqEP {
+			_, target, _ = strings.Cut(row.Key, " ")
+		}
+		fmt.Printf("[%s] %s %s → %d  (x%d)\n", row.Latest.ID, row.Latest.Method, output.SanitizeText(target), status, row.Count)
+	}
+	if len(rows) < total {
+		fmt.Printf("[Showing %d of %d unique, %d requests]\n", len(rows), total, len(requests))
+	} else {
+		fmt.Printf("%d unique of %d requests\n", total, len(requests))
+	}
+	return nil
+}
+
+// requestGroup is one --group-by bucket
+type requestGroup struct {
+	Key      string
+	Requests []store.Request
+}
+
+// listGroupKeys maps --group-by fields to key functions
+var listGroupKeys = map[string]func(req *store.Request) string{
+	"domain": func(req *store.Request) string { return req.Domain },
+	"endpoint": func(req *store.Request) string {
+		method, path, _ := strings.Cut(requestEndpoint(req), " ")
+		return method + " " + req.Domain + path
+	},
+	"status": func(req *store.Request) string {
+		if req.Response == nil {
+			return "no response"
+		}
+		return strconv.Itoa(req.Response.Status)
+	},
+	"page": func(req *store.Request) string {
+		if req.PageURL == "" {
+			return "(no page)"
+		}
+		return req.PageURL
+	},
+}
+
+// groupRequests buckets requests by key, ordered by first appearance
+// (so --sort still decides the group order)
+func groupRequests(requests []store.Request, key func(req *store.Request) string) []requestGroup {
+	var groups []requestGroup
+	index := make(map[string]int)
+	for _, req := range requests {
+		k := key(&req)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, requestGroup{Key: k})
+		}
+		groups[i].Requests = append(groups[i].Requests, req)
+	}
+	return groups
+}
+
+// listGroupOutput is one row of rep list --group-by -o json
+type listGroupOutput struct {
+	Group    string                 `json:"group"`
+	Count    int                    `json:"count"`
+	Requests []output.RequestOutput `json:"requests"`
+}
+
+func printRequestGroups(groups []requestGroup, mode store.OutputMode, line bool) {
+	if mode == store.OutputJSON {
+		result := make([]listGroupOutput, len(groups))
+		for i, g := range groups {
+			result[i] = listGroupOutput{
+				Group:    g.Key,
+				Count:    len(g.Requests),
+				Requests: output.FormatRequests(g.Requests, mode, truncateConfig()),
+			}
+		}
+		printJSON("list/group", result)
+		return
+	}
+
+	for _, g := range groups {
+		if line && mode == store.OutputCompact {
+			fmt.Printf("%s (%d)\n", pterm.Bold.Sprint(output.SanitizeText(g.Key)), len(g.Requests))
+			for _, req := range g.Requests {
+				fmt.Printf("  %s\n", output.SanitizeText(output.FormatRequestCompact(&req)))
+			}
+			continue
+		}
+		pterm.DefaultSection.Printf("%s (%d)\n", output.SanitizeText(g.Key), len(g.Requests))
+		for _, req := range g.Requests {
+			printRequest(&req, mode)
+			fmt.Println()
+		}
+	}
+	fmt.Printf("%d groups\n", len(groups))
+}
+
+// streamLiveList writes the live matches as NDJSON or CSV while live.json is
+// decoded, so output starts at once and memory doesn't grow with the capture
+func streamLiveList(opts store.FilterOptions, format string) error {
+	rw := newRequestStreamWriter(os.Stdout, format, nil)
+	res, ok, err := streamLiveSource(opts, store.StreamOptions{Emit: rw.write})
+	if err != nil || !ok {
+		return err
+	}
+	if res.Emitted == 0 {
+		return softFail(emptyError("No requests match the filter"))
+	}
+	return rw.flush()
+}
+
+// streamRequests writes requests as NDJSON (full bodies, one request per line)
+// or CSV (metadata only). With a group key, NDJSON emits one group per line
+// and CSV gains a leading "group" column.
+func streamRequests(w io.Writer, requests []store.Request, format string, groupKey func(req *store.Request) string) error {
+	if format == "ndjson" && groupKey != nil {
+		nw := output.NewNDJSONWriter(w)
+		cfg := truncateConfig()
+		for _, g := range groupRequests(requests, groupKey) {
+			line := map[string]interface{}{
+				"group":    g.Key,
+				"count":    len(g.Requests),
+				"requests": output.FormatRequests(g.Requests, store.OutputJSON, cfg),
+			}
+			if err := nw.Write(line); err != nil {
+				return err
+			}
+		}
+		return nw.Flush()
+	}
+
+	rw := newRequestStreamWriter(w, format, groupKey)
+	for i := range requests {
+		if err := rw.write(&requests[i]); err != nil {
+			return err
+		}
+	}
+	return rw.flush()
+}
+
+// requestStreamWriter writes requests one at a time as NDJSON lines or CSV
+// rows (see streamRequests)
+type requestStreamWriter struct {
+	csv      *csv.Writer
+	ndjson   *output.NDJSONWriter
+	cfg      store.TruncateConfig
+	groupKey func(req *store.Request) string
+	header   bool // CSV header written
+}
+
+func newRequestStreamWriter(w io.Writer, format string, groupKey func(req *store.Request) string) *requestStreamWriter {
+	rw := &requestStreamWriter{groupKey: groupKey}
+	if format == "csv" {
+		rw.csv = csv.NewWriter(w)
+	} else {
+		rw.ndjson = output.NewNDJSONWriter(w)
+		rw.cfg = truncateConfig()
+	}
+	return rw
+}
+
+func (rw *requestStreamWriter) write(req *store.Request) error {
+	if rw.ndjson != nil {
+		return rw.ndjson.Write(output.FormatRequest(req, store.OutputJSON, rw.cfg))
+	}
+	if err := rw.writeHeader(); err != nil {
+		return err
+	}
+	row := output.RequestCSVRow(req)
+	if rw.groupKey != nil {
+		row = append([]string{rw.groupKey(req)}, row...)
+	}
+	return rw.csv.Write(row)
+}
+
+func (rw *requestStreamWriter) writeHeader() error {
+	if rw.header {
+		return nil
+	}
+	rw.header = true
+	header := output.RequestCSVHeader
+	if rw.groupKey != nil {
+		header = append([]string{"group"}, header...)
+	}
+	return rw.csv.Write(header)
+}
+
+func (rw *requestStreamWriter) flush() error {
+	if rw.ndjson != nil {
+		return rw.ndjson.Flush()
+	}
+	if err := rw.writeHeader(); err != nil {
+		return err
+	}
+	rw.csv.Flush()
+	return rw.csv.Error()
+}
+
 func printRequests(requests []store.Request, mode store.OutputMode, totalCount int, limit int) {
 	for _, req := range requests {
 		printRequest(&req, mode)
@@ -267,6 +624,67 @@ func printRequestsLine(requests []store.Request, totalCount int, limit int) {
 	}
 }
 
+// listTopRequests prints the n highest-scoring requests with the signals
+// behind each score
+// listTopOutput is one row of rep list --top -o json
+type listTopOutput struct {
+	Rank    int                  `json:"rank"`
+	Score   int                  `json:"score"`
+	Signals []triage.Signal      `json:"signals"`
+	Request output.RequestOutput `json:"request"`
+}
+
+func listTopRequests(requests []store.Request, n int) error {
+	// Meta output reads live.json without bodies; ranking needs them
+	for i := range requests {
+		if err := store.LoadBodies(&requests[i]); err != nil {
+			return err
+		}
+	}
+	scores := triage.Rank(requests)
+	if len(scores) > n {
+		scores = scores[:n]
+	}
+	if len(scores) == 0 {
+		return softFail(emptyError("No requests match the filter"))
+	}
+
+	if getOutputMode() == "json" {
+		cfg := truncateConfig()
+		result := make([]listTopOutput, len(scores))
+		for i, s := range scores {
+			result[i] = listTopOutput{
+				Rank:    i + 1,
+				Score:   s.Score,
+				Signals: s.Signals,
+				Request: output.FormatRequest(s.Request, store.OutputJSON, cfg),
+			}
+		}
+		var next []string
+		if len(scores) > 0 {
+			next = append(next, "rep body "+scores[0].Request.ID)
+		}
+		printJSON("list/top", result, next...)
+		return nil
+	}
+
+	for _, s := range scores {
+		req := s.Request
+		status := 0
+		if req.Response != nil {
+			status = req.Response.Status
+		}
+		names := make([]string, len(s.Signals))
+		for i, signal := range s.Signals {
+			names[i] = signal.Name
+		}
+		fmt.Printf("%s [%s] %s %s → %d  %s\n", pterm.FgYellow.Sprintf("%3d", s.Score), req.ID, req.Method,
+			output.SanitizeText(req.URL), status, pterm.FgGray.Sprint(strings.Join(names, ", ")))
+	}
+	fmt.Printf("[Top %d of %d requests by score; -o json explains each signal]\n", len(scores), len(requests))
+	return nil
+}
+
 func printRequest(req *store.Request, mode store.OutputMode) {
 	// Status with color
 	status := 0
@@ -291,6 +709,10 @@ func printRequest(req *store.Request, mode store.OutputMode) {
 			req.URL,
 			pterm.NewStyle(statusColor).Sprintf("%d", status)))
 
+	if timing := output.TimingText(req); timing != "" {
+		fmt.Printf("  Timing: %s\n", timing)
+	}
+
 	// Request headers (always show key ones)
 	if len(req.Headers) > 0 {
 		fmt.Println("  Request Headers:")
@@ -346,6 +768,10 @@ func printRequest(req *store.Request, mode store.OutputMode) {
 			}
 		}
 
+		if n := len(req.Response.Events); n > 0 {
+			fmt.Printf("  Stream: %d events (rep sse %s)\n", n, req.ID)
+		}
+
 		if req.Response.Body != "" {
 			fmt.Println("  Response Body:")
 			// Get content type
@@ -355,7 +781,7 @@ func printRequest(req *store.Request, mode store.OutputMode) {
 			if mode == store.OutputFull {
 				body = req.Response.Body
 			} else {
-				body, _ = output.TruncateBody(req.Response.Body, contentType, store.DefaultTruncateConfig())
+				body, _ = output.TruncateBody(req.Response.Body, contentType, truncateConfig())
 			}
 
 			body = output.SanitizeText(body)
@@ -384,23 +810,18 @@ func parseCommaSeparated(input string) []string {
 
 func init() {
 	rootCmd.AddCommand(listCmd)
-	listCmd.Flags().StringVarP(&listDomain, "domain", "d", "", "Filter by domain")
-	listCmd.Flags().StringVarP(&listMethod, "method", "m", "", "Filter by HTTP method (or comma-separated list)")
-	listCmd.Flags().IntVar(&listStatus, "status", 0, "Filter by exact status code")
-	listCmd.Flags().StringVar(&listStatusRange, "status-range", "", "Filter by status range (2xx, 3xx, 4xx, 5xx)")
-	listCmd.Flags().StringVarP(&listPattern, "pattern", "p", "", "Filter by URL pattern (regex)")
+	listFilter.register(listCmd)
 	listCmd.Flags().IntVarP(&listLimit, "limit", "l", 0, "Limit number of results")
 	listCmd.Flags().IntVar(&listOffset, "offset", 0, "Skip first N results")
-	listCmd.Flags().BoolVar(&listPrimary, "primary", true, "Only show requests to primary domains (default)")
-	listCmd.Flags().BoolVar(&listIncludeIgnored, "include-ignored", false, "Include requests to ignored domains")
+	listCmd.Flags().StringVar(&listSort, "sort", "", "Sort by: time, size, status, url, domain, duration (default: capture order)")
+	listCmd.Flags().BoolVar(&listDesc, "desc", false, "Reverse the sort order (largest/latest first)")
+	listCmd.Flags().StringVar(&listGroup, "group-by", "", "Group output by: domain, endpoint, status, page")
+	listCmd.Flags().BoolVar(&listUnique, "unique", false, "Collapse requests sharing method+URL (count + latest ID)")
+	listCmd.Flags().BoolVar(&listUniqEP, "unique-endpoint", false, "Collapse requests sharing method+endpoint template (/users/{id})")
 	listCmd.Flags().BoolVar(&listLine, "line", true, "One-line output with request ID (default)")
 	listCmd.Flags().BoolVar(&listDetail, "detail", false, "Show multi-line request details")
-	// New agent-optimized flags
-	listCmd.Flags().StringVar(&listType, "type", "", "Filter by resource type (script,xmlhttprequest,fetch,document)")
-	listCmd.Flags().BoolVar(&listAPI, "api", false, "Preset: API calls only (xmlhttprequest, fetch)")
-	listCmd.Flags().BoolVar(&listInteresting, "interesting", false, "Preset: Error responses (4xx/5xx) + state-changing methods")
-	listCmd.Flags().BoolVar(&listErrors, "errors", false, "Preset: Only error responses (4xx/5xx)")
-	listCmd.Flags().BoolVar(&listMutations, "mutations", false, "Preset: Only state-changing methods (POST/PUT/DELETE/PATCH)")
 	// Data source
 	listCmd.Flags().StringVar(&listSaved, "saved", "", "Read from saved session (ID, prefix, or 'latest')")
+	listCmd.Flags().IntVar(&listTop, "top", 0, "Show the N highest-scoring requests (risk signals explained in JSON)")
+	listCmd.Flags().BoolVar(&listFollow, "follow", false, "Keep running and print new matching requests as they are captured")
 }
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -11,16 +12,104 @@ import (
 	"github.com/repplus/rep-cli/internal/store"
 )
 
+// liveReadAttempts bounds retries when live.json fails to parse (e.g. an
+// older host still writing it in place)
+const liveReadAttempts = 3
+
 func loadLiveExport(livePath string) (store.Export, error) {
 	var export store.Export
-	data, err := os.ReadFile(livePath)
+	var err error
+	for attempt := 0; attempt < liveReadAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		var data []byte
+		data, err = store.ReadFileLocked(livePath)
+		if err != nil {
+			return export, err
+		}
+		export = store.Export{}
+		if err = sonic.Unmarshal(data, &export); err == nil {
+			return export, nil
+		}
+	}
+	return export, err
+}
+
+// loadLiveMerged reads every live file in scope (live.json plus each
+// browser's live-<id>.json, or just the --session one) as a single export.
+// Fails only when none of them can be read.
+func loadLiveMerged() (store.Export, error) {
+	paths, err := store.GetLiveFilePaths()
+	if err != nil {
+		return store.Export{}, err
+	}
+	if len(paths) == 1 && store.LiveSourceName(paths[0]) == "" {
+		return loadLiveExport(paths[0])
+	}
+
+	var merged store.Export
+	var sources []string
+	var sets [][]store.Request
+	var firstErr error
+	for _, path := range paths {
+		export, err := loadLiveExport(path)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if export.ExportedAt > merged.ExportedAt {
+			merged.Version, merged.ExportedAt = export.Version, export.ExportedAt
+		}
+		sources = append(sources, store.LiveSourceName(path))
+		sets = append(sets, export.Requests)
+	}
+	if len(sets) == 0 {
+		return merged, firstErr
+	}
+	merged.Requests = store.MergeLiveRequests(sources, sets)
+	return merged, nil
+}
+
+// appendLiveRequest appends a request to live.json (creating it if needed)
+func appendLiveRequest(req store.Request) error {
+	livePath, err := store.GetLiveFilePath()
 	if err != nil {
-		return export, err
+		return err
 	}
-	if err := sonic.Unmarshal(data, &export); err != nil {
-		return export, err
+	return updateLiveExport(livePath, func(export *store.Export) (bool, error) {
+		export.Requests = append(export.Requests, req)
+		return true, nil
+	})
+}
+
+// updateLiveExport applies fn to the export in livePath under the file's
+// exclusive lock, held from the read to the rename, so a request appended or
+// deleted meanwhile by another command isn't lost. A missing file reads as
+// empty. fn returns false to leave the file as it is.
+func updateLiveExport(livePath string, fn func(export *store.Export) (bool, error)) error {
+	if err := os.MkdirAll(filepath.Dir(livePath), 0755); err != nil {
+		return err
 	}
-	return export, nil
+	return store.UpdateFile(livePath, 0644, func(data []byte) ([]byte, error) {
+		var export store.Export
+		if data != nil {
+			if err := sonic.Unmarshal(data, &export); err != nil {
+				return nil, fmt.Errorf("could not parse %s: %w", filepath.Base(livePath), err)
+			}
+		}
+		changed, err := fn(&export)
+		if err != nil || !changed {
+			return nil, err
+		}
+		if export.Version == "" {
+			export.Version = "1.0"
+		}
+		export.ExportedAt = time.Now().Format(time.RFC3339)
+		return sonic.MarshalIndent(export, "", "  ")
+	})
 }
 
 func maxRequestTimestamp(requests []store.Request) int64 {
@@ -33,11 +122,17 @@ func maxRequestTimestamp(requests []store.Request) int64 {
 	return max
 }
 
+// parseSince parses a time bound into Unix millis. Accepts a relative
+// duration back from now (30s, 5m, 2h, 1d), Unix seconds or millis, or
+// RFC3339. Empty means no bound (0).
 func parseSince(value string) (int64, error) {
 	text := strings.TrimSpace(value)
 	if text == "" {
 		return 0, nil
 	}
+	if d, ok := parseRelativeDuration(text); ok {
+		return time.Now().Add(-d).UnixMilli(), nil
+	}
 	if isDigits(text) {
 		val, err := strconv.ParseInt(text, 10, 64)
 		if err != nil {
@@ -58,6 +153,25 @@ func parseSince(value string) (int64, error) {
 	return t.UnixMilli(), nil
 }
 
+// parseRelativeDuration parses Go durations plus a "d" (days) suffix
+func parseRelativeDuration(text string) (time.Duration, bool) {
+	if days := strings.TrimSuffix(text, "d"); days != text && isDigits(days) {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, false
+		}
+		return time.Duration(n) * 24 * time.Hour, true
+	}
+	if isDigits(text) {
+		return 0, false // Bare numbers are timestamps
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil || d < 0 {
+		return 0, false
+	}
+	return d, true
+}
+
 func isDigits(text string) bool {
 	for _, r := range text {
 		if r < '0' || r > '9' {
@@ -66,3 +180,50 @@ func isDigits(text string) bool {
 	}
 	return text != ""
 }
+
+// resolveSession returns the saved session for an ID, prefix, or "latest"/"last".
+func resolveSession(s *store.Store, id string) *store.Session {
+	if id == "latest" || id == "last" {
+		return s.GetLatestSession()
+	}
+	return s.GetSession(id)
+}
+
+// lookupRequest finds a request by ID. With saved set, only that session is
+// searched; otherwise live.json is tried first, then all saved sessions.
+// Returns (nil, nil) when the request does not exist.
+func lookupRequest(requestID, saved string) (*store.Request, error) {
+	if saved != "" {
+		s, err := store.Get()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load store: %w", err)
+		}
+		session := resolveSession(s, saved)
+		if session == nil {
+			return nil, notFoundError("session not found: %s", saved)
+		}
+		for i := range session.Requests {
+			if session.Requests[i].ID == requestID {
+				store.ComputeRequestFields(&session.Requests[i])
+				return &session.Requests[i], nil
+			}
+		}
+		return nil, nil
+	}
+
+	// Try live.json first
+	if export, err := loadLiveMerged(); err == nil {
+		for i := range export.Requests {
+			if export.Requests[i].ID == requestID {
+				store.ComputeRequestFields(&export.Requests[i])
+				return &export.Requests[i], nil
+			}
+		}
+	}
+
+	// Fall back to saved sessions
+	if s, err := store.Get(); err == nil {
+		return s.GetRequestFromSessions(requestID), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,370 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/macro"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/replay"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	macroSaved     string
+	macroOut       string
+	macroName      string
+	macroVars      []string
+	macroInsecure  bool
+	macroDryRun    bool
+	macroRecord    bool
+	macroKeepGoing bool
+)
+
+// MacroStepResult is the outcome of one step of 'rep macro run'
+type MacroStepResult struct {
+	Step       int               `json:"step"`
+	Name       string            `json:"name"`
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Status     int               `json:"status,omitempty"`
+	DurationMs int64             `json:"duration_ms"`
+	Extracted  map[string]string `json:"extracted,omitempty"`
+	RecordedID string            `json:"recorded_id,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+var macroCmd = &cobra.Command{
+	Use:   "macro",
+	Short: "Record and run multi-step request chains (login → CSRF → action)",
+	Long: `Macros replay a chain of requests where values from one response feed
+the next request: fetch the login page, pull its CSRF token, log in, keep
+the session cookie, then send the action.
+
+A macro is a YAML file:
+
+  name: login
+  vars:
+    user: alice                      Initial values (override with --var)
+  steps:
+    - name: login page
+      request:
+        method: GET
+        url: https://app.example.com/login
+      extract:
+        - var: csrf                  First regex group (or whole match)
+          regex: name="csrf" value="([^"]+)"
+        - var: session
+          header: Set-Cookie         Match header values instead of the body
+          regex: ^session=([^;]*)
+    - name: login
+      request:
+        method: POST
+        url: https://app.example.com/login
+        headers:
+          Content-Type: application/x-www-form-urlencoded
+          Cookie: session={{session}}
+        body: user={{user}}&csrf={{csrf}}
+      extract:
+        - var: token
+          json: .access_token        A path as in 'rep body --path'
+      expect:
+        status: 200                  Stop the run on any other status
+
+{{name}} is a variable, or an environment variable of that name. 'rep macro
+record' writes the file from captured requests; 'rep macro run' sends it.
+
+Examples:
+  rep macro record req_1 req_2 req_3 --out login.yaml
+  rep macro run login.yaml
+  rep macro run login.yaml --var user=bob --record`,
+}
+
+var macroRecordCmd = &cobra.Command{
+	Use:   "record <request-id...>",
+	Short: "Write a macro from captured requests, tracing values between them",
+	Long: `Build a macro from captured requests, in the order given. Every value a
+request sends (cookies, bearer tokens, other headers, query and body
+parameters of at least 8 characters) is looked up in the responses before
+it: a Set-Cookie, a JSON field, another response header or the page body.
+When found, the earlier step gets an extractor for it and the later
+requests reference it as {{variable}}; each extractor is checked to yield
+the captured value.
+
+Values that no earlier response produced (a cookie from before the chain)
+stay literal, so the file can hold credentials: keep it private. Every step
+expects the captured status.
+
+Examples:
+  rep macro record req_1 req_2 req_3                    Print the YAML
+  rep macro record req_1 req_2 req_3 --out login.yaml
+  rep macro record r1 r2 --saved latest --name checkout --out checkout.yaml`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		requests := make([]store.Request, 0, len(args))
+		for _, id := range args {
+			req, err := lookupRequest(id, macroSaved)
+			if err != nil {
+				return err
+			}
+			if req == nil {
+				return notFoundError("request not found: %s", id).withHint("Use 'rep list' to see available request IDs")
+			}
+			requests = append(requests, *req)
+		}
+
+		name := macroName
+		if name == "" && macroOut != "" {
+			name = strings.TrimSuffix(baseName(macroOut), ".yaml")
+		}
+		m := macro.Record(name, requests)
+		data, err := macro.Marshal(m)
+		if err != nil {
+			return err
+		}
+
+		if macroOut == "" && getOutputMode() != "json" {
+			fmt.Print(string(data))
+			return nil
+		}
+		if macroOut != "" {
+			if err := os.WriteFile(macroOut, data, 0600); err != nil {
+				return fmt.Errorf("failed to write %s: %w", macroOut, err)
+			}
+		}
+
+		type tracedVar struct {
+			Var  string `json:"var"`
+			Step int    `json:"step"`
+			From string `json:"from"`
+		}
+		var traced []tracedVar
+		for i, step := range m.Steps {
+			for _, ex := range step.Extract {
+				traced = append(traced, tracedVar{Var: ex.Var, Step: i + 1, From: macroExtractorSource(ex)})
+			}
+		}
+
+		if getOutputMode() == "json" {
+			payload := map[string]interface{}{"steps": len(m.Steps), "variables": traced}
+			if macroOut != "" {
+				payload["path"] = macroOut
+			} else {
+				payload["yaml"] = string(data)
+			}
+			out, _ := sonic.MarshalIndent(payload, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		pterm.Success.Printf("Wrote %s (%d steps, %d traced variables)\n", macroOut, len(m.Steps), len(traced))
+		for _, t := range traced {
+			fmt.Printf("  %-24s step %d, %s\n", t.Var, t.Step, t.From)
+		}
+		fmt.Printf("\nRun it with: rep macro run %s\n", macroOut)
+		return nil
+	},
+}
+
+var macroRunCmd = &cobra.Command{
+	Use:   "run <file.yaml>",
+	Short: "Send a macro's requests in order, passing extracted values along",
+	Long: `Send each step of a macro in order. Before a step is sent, {{name}}
+references in its URL, headers and body are replaced; after, its extractors
+define variables for the steps that follow. A run stops at the first step
+that fails: a request error, an unexpected status (expect.status), an
+unresolved variable, or a required extractor that found nothing
+(--keep-going continues instead).
+
+Responses are not printed; --record appends every exchange to the live
+capture so 'rep body <recorded-id>' shows it.
+
+Examples:
+  rep macro run login.yaml
+  rep macro run login.yaml --var user=bob --var password=hunter2
+  rep macro run login.yaml --dry-run              Show the requests only
+  rep macro run login.yaml --record -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m, err := macro.Load(args[0])
+		if err != nil {
+			if os.IsNotExist(err) {
+				return notFoundError("macro not found: %s", args[0])
+			}
+			return parseError("%v", err)
+		}
+		vars := make(map[string]string)
+		for k, v := range m.Vars {
+			vars[k] = v
+		}
+		for _, kv := range macroVars {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok || k == "" {
+				return usageError("bad --var %q (use name=value)", kv)
+			}
+			vars[k] = v
+		}
+
+		results, failed := runMacro(m, vars)
+
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"macro": m.Name,
+				"ok":    failed == 0,
+				"steps": results,
+			}, "", "  ")
+			fmt.Println(string(out))
+		} else {
+			printMacroRun(m, results, failed)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d macro step(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+// runMacro sends the steps of m in order; returns the results and the
+// number of failed steps
+func runMacro(m *macro.Macro, vars map[string]string) ([]MacroStepResult, int) {
+	opts := replay.Options{Insecure: macroInsecure}
+	var results []MacroStepResult
+	failed := 0
+	for i, step := range m.Steps {
+		req, missing := step.Request.Build(vars)
+		res := MacroStepResult{Step: i + 1, Name: m.StepName(i), Method: req.Method, URL: req.URL}
+		fail := func(format string, args ...interface{}) {
+			res.Error = fmt.Sprintf(format, args...)
+			failed++
+		}
+
+		switch {
+		case len(missing) > 0:
+			fail("unresolved {{%s}}", strings.Join(missing, "}}, {{"))
+		case macroDryRun:
+			// Later steps show what they would receive
+			for _, ex := range step.Extract {
+				vars[ex.Var] = "{{" + ex.Var + "}}"
+			}
+		default:
+			result, err := replay.Send(context.Background(), withMappedValues(req), opts)
+			if err != nil {
+				fail("request failed: %v", err)
+				break
+			}
+			res.Status, res.DurationMs = result.Status, result.DurationMs()
+			if macroRecord {
+				if res.RecordedID, err = recordReplay(req, step.From, result); err != nil {
+					fail("%v", err)
+					break
+				}
+			}
+			if step.Expect != nil && step.Expect.Status != 0 && result.Status != step.Expect.Status {
+				fail("expected status %d, got %d", step.Expect.Status, result.Status)
+				break
+			}
+			for _, ex := range step.Extract {
+				value, ok := ex.Run(result.Headers, result.Body)
+				if !ok {
+					if !ex.Optional {
+						fail("nothing extracted for %s (%s)", ex.Var, macroExtractorSource(ex))
+						break
+					}
+					continue
+				}
+				vars[ex.Var] = value
+				if res.Extracted == nil {
+					res.Extracted = map[string]string{}
+				}
+				res.Extracted[ex.Var] = value
+			}
+		}
+		results = append(results, res)
+		if res.Error != "" && !macroKeepGoing {
+			break
+		}
+	}
+	return results, failed
+}
+
+// macroExtractorSource describes where an extractor reads its value
+func macroExtractorSource(ex macro.Extractor) string {
+	switch {
+	case ex.JSON != "":
+		return "json " + ex.JSON
+	case ex.Header != "" && ex.Regex != "":
+		return "header " + ex.Header + " ~ " + ex.Regex
+	case ex.Header != "":
+		return "header " + ex.Header
+	}
+	return "body ~ " + ex.Regex
+}
+
+func printMacroRun(m *macro.Macro, results []MacroStepResult, failed int) {
+	title := m.Name
+	if title == "" {
+		title = "macro"
+	}
+	pterm.DefaultSection.Printf("%s (%d steps)\n", title, len(m.Steps))
+	for _, r := range results {
+		status := "-"
+		if r.Status != 0 {
+			status = colorStatus(r.Status, fmt.Sprint(r.Status))
+		}
+		fmt.Printf("  %d. %-7s %s → %s", r.Step, r.Method, output.SanitizeText(truncateCell(r.URL, 80)), status)
+		if r.DurationMs > 0 {
+			fmt.Printf(" (%dms)", r.DurationMs)
+		}
+		if r.RecordedID != "" {
+			fmt.Printf("  [%s]", r.RecordedID)
+		}
+		fmt.Println()
+
+		names := make([]string, 0, len(r.Extracted))
+		for name := range r.Extracted {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("       %s = %s\n", name, output.SanitizeText(truncateCell(r.Extracted[name], 40)))
+		}
+		if r.Error != "" {
+			fmt.Printf("       %s\n", pterm.Red(r.Error))
+		}
+	}
+	fmt.Println()
+	switch {
+	case failed > 0:
+		pterm.Error.Printf("%d step(s) failed\n", failed)
+	case macroDryRun:
+		pterm.Info.Println("Dry run: nothing sent")
+	default:
+		pterm.Success.Printf("All %d steps passed\n", len(results))
+	}
+}
+
+// baseName is the last element of a slash or backslash separated path
+func baseName(path string) string {
+	if i := strings.LastIndexAny(path, `/\`); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+func init() {
+	rootCmd.AddCommand(macroCmd)
+	macroCmd.AddCommand(macroRecordCmd, macroRunCmd)
+	macroRecordCmd.Flags().StringVar(&macroSaved, "saved", "", "Read the requests from a saved session (ID or 'latest')")
+	macroRecordCmd.Flags().StringVar(&macroOut, "out", "", "Write the macro to this file instead of stdout")
+	macroRecordCmd.Flags().StringVar(&macroName, "name", "", "Macro name (default: the --out file name)")
+	macroRunCmd.Flags().StringArrayVar(&macroVars, "var", nil, "Set a variable, name=value (overrides the file's vars); repeatable")
+	macroRunCmd.Flags().BoolVarP(&macroInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+	macroRunCmd.Flags().BoolVar(&macroDryRun, "dry-run", false, "Resolve and list the requests without sending them")
+	macroRunCmd.Flags().BoolVar(&macroRecord, "record", false, "Append every exchange to the live capture (see 'rep body')")
+	macroRunCmd.Flags().BoolVar(&macroKeepGoing, "keep-going", false, "Continue after a failed step")
+}
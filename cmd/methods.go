@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/replay"
+	"github.com/repplus/rep-cli/internal/similarity"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	methodsSaved    string
+	methodsList     string
+	methodsUseVars  bool
+	methodsInsecure bool
+	methodsDryRun   bool
+)
+
+// methodsDefault are the methods probed unless --methods says otherwise
+var methodsDefault = []string{"OPTIONS", "PUT", "DELETE", "PATCH", "TRACE"}
+
+// MethodsReport is the outcome of replaying a request with other methods
+type MethodsReport struct {
+	ID         string         `json:"id"` // Request replayed
+	URL        string         `json:"url"`
+	Method     string         `json:"method"` // Captured method, sent as the baseline
+	Status     int            `json:"status"`
+	Allow      []string       `json:"allow"`    // Advertised by Allow or Access-Control-Allow-Methods
+	Observed   []string       `json:"observed"` // Methods captured on the same endpoint
+	Results    []MethodResult `json:"results"`
+	Unexpected []string       `json:"unexpected"` // Methods permitted without being advertised or observed
+}
+
+// MethodResult is the response to one method
+type MethodResult struct {
+	Method     string  `json:"method"`
+	Status     int     `json:"status,omitempty"`
+	Size       int64   `json:"size,omitempty"`
+	Allow      string  `json:"allow,omitempty"` // Allow header of this response
+	Similarity float64 `json:"similarity"`      // Body likeness to the baseline
+	Verdict    string  `json:"verdict"`         // permitted, ignored, rejected, denied, not-found, error, other
+	Unexpected string  `json:"unexpected,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+var methodsCmd = &cobra.Command{
+	Use:   "methods <request-id|endpoint>",
+	Short: "Replay a request with other HTTP methods and report unexpected ones",
+	Long: `Replay a captured request with OPTIONS, PUT, DELETE, PATCH and TRACE
+(or --methods), record the Allow header and the status of each method, and
+report the methods the server permits unexpectedly.
+
+The endpoint is a request ID, or [METHOD] [host]/path as in 'rep
+schema-infer', in which case its latest captured request is replayed. The
+captured method is sent first as the baseline. PUT and PATCH carry the
+captured body; the other methods are sent without one.
+
+  permitted  2xx or 3xx with a response unlike the baseline's
+  ignored    2xx or 3xx with the baseline's response: the server most
+             likely routed the method to the original handler
+  rejected   405 or 501
+  denied     401 or 403
+  not-found  404
+
+A permitted method is unexpected when neither Allow (nor Access-Control-
+Allow-Methods) advertises it nor the capture shows it on the endpoint, and
+TRACE is unexpected whenever it is permitted (cross-site tracing).
+
+PUT, DELETE and PATCH may change server state: use --dry-run to see the
+plan, and --methods to choose what is sent.
+
+Examples:
+  rep methods h_abc123
+  rep methods 'GET api.target.com/v1/users/{id}' --use-vars
+  rep methods h_abc123 --methods OPTIONS,TRACE,PROPFIND
+  rep methods h_abc123 --dry-run
+  rep methods h_abc123 -o json | jq '.unexpected'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		methods := methodsDefault
+		if methodsList != "" {
+			methods = nil
+			for _, m := range parseCommaSeparated(strings.ToUpper(methodsList)) {
+				if !containsString(methods, m) {
+					methods = append(methods, m)
+				}
+			}
+		}
+
+		requests, err := filterSource(methodsSaved, store.FilterOptions{})
+		if err != nil || requests == nil {
+			return err
+		}
+		req, observed, err := methodsTarget(strings.TrimSpace(args[0]), requests)
+		if err != nil {
+			return err
+		}
+
+		sendReq, missing := resolveSendRequest(req, methodsUseVars)
+		if err := warnMissingVars(req, missing); err != nil {
+			return err
+		}
+		var probes []string
+		for _, m := range methods {
+			if !strings.EqualFold(m, req.Method) {
+				probes = append(probes, m)
+			}
+		}
+		if methodsDryRun {
+			pterm.DefaultSection.Printf("Method probe for %s\n", req.ID)
+			fmt.Printf("  %s %s (baseline)\n", req.Method, output.SanitizeText(req.URL))
+			for _, m := range probes {
+				fmt.Printf("  %s\n", m)
+			}
+			return nil
+		}
+
+		opts := replay.Options{Insecure: methodsInsecure}
+		baseline, err := replay.Send(context.Background(), sendReq, opts)
+		if err != nil {
+			return fmt.Errorf("baseline request failed: %w", err)
+		}
+		report := &MethodsReport{
+			ID: req.ID, URL: req.URL, Method: req.Method, Status: baseline.Status,
+			Allow: []string{}, Observed: observed, Results: []MethodResult{}, Unexpected: []string{},
+		}
+		addMethodsAllow(report, baseline.Headers)
+		for _, m := range probes {
+			report.Results = append(report.Results, runMethodProbe(sendReq, m, baseline, opts, report))
+		}
+		for i := range report.Results {
+			r := &report.Results[i]
+			if r.Verdict != "permitted" && r.Verdict != "ignored" {
+				continue
+			}
+			switch {
+			case r.Method == "TRACE":
+				r.Unexpected = "TRACE enabled"
+			case r.Method == "OPTIONS" || r.Verdict == "ignored":
+			case !containsString(report.Allow, r.Method) && !containsString(report.Observed, r.Method):
+				r.Unexpected = "not advertised in Allow nor seen in the capture"
+			}
+			if r.Unexpected != "" {
+				report.Unexpected = append(report.Unexpected, r.Method)
+			}
+		}
+
+		if getOutputMode() == "json" {
+			printJSON("methods", report)
+			return nil
+		}
+		printMethods(report)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(methodsCmd)
+	methodsCmd.Flags().StringVar(&methodsSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	methodsCmd.Flags().StringVar(&methodsList, "methods", "", "Comma-separated methods to try (default OPTIONS,PUT,DELETE,PATCH,TRACE)")
+	methodsCmd.Flags().BoolVar(&methodsUseVars, "use-vars", false, "Resolve auth from environment / ~/.rep/auth-<domain>.env")
+	methodsCmd.Flags().BoolVarP(&methodsInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+	methodsCmd.Flags().BoolVar(&methodsDryRun, "dry-run", false, "List the methods without sending them")
+}
+
+// methodsTarget resolves the request to replay and the methods captured on
+// its endpoint
+func methodsTarget(arg string, requests []store.Request) (*store.Request, []string, error) {
+	matcher, err := endpointArg(arg, methodsSaved)
+	if err != nil {
+		return nil, nil, err
+	}
+	var target *store.Request
+	byID := !strings.ContainsAny(arg, "/ ")
+	if byID {
+		if target, err = lookupRequest(arg, methodsSaved); err != nil {
+			return nil, nil, err
+		}
+	}
+	observed := []string{}
+	endpoint := matcher
+	endpoint.method = ""
+	for i := range requests {
+		req := &requests[i]
+		if req.Domain == "" {
+			store.ComputeRequestFields(req)
+		}
+		if !endpoint.matches(req) {
+			continue
+		}
+		if !containsString(observed, strings.ToUpper(req.Method)) {
+			observed = append(observed, strings.ToUpper(req.Method))
+		}
+		if !byID && matcher.matches(req) {
+			target = req // The latest match
+		}
+	}
+	if target == nil {
+		return nil, nil, notFoundError("no captured request matches %s", arg).
+			withHint("Give a request ID or an endpoint like 'GET api.target.com/v1/users/{id}'")
+	}
+	sort.Strings(observed)
+	return target, observed, nil
+}
+
+// runMethodProbe sends req with another method and classifies the response
+func runMethodProbe(req *store.Request, method string, baseline *replay.Result, opts replay.Options, report *MethodsReport) MethodResult {
+	v := *req
+	v.Method = method
+	if method != "PUT" && method != "PATCH" && method != "POST" {
+		v.Body = ""
+	}
+	r := MethodResult{Method: method}
+	result, err := replay.Send(context.Background(), &v, opts)
+	if err != nil {
+		r.Error, r.Verdict = err.Error(), "error"
+		return r
+	}
+	r.Status, r.Size = result.Status, result.Size
+	r.Allow = store.HeaderFirst(result.Headers, "allow")
+	addMethodsAllow(report, result.Headers)
+	r.Similarity = similarity.Compare(baseline.Status, baseline.Body, result.Status, result.Body).Score
+
+	switch {
+	case result.Status == 405 || result.Status == 501:
+		r.Verdict = "rejected"
+	case result.Status == 401 || result.Status == 403:
+		r.Verdict = "denied"
+	case result.Status == 404:
+		r.Verdict = "not-found"
+	case result.Status >= 200 && result.Status < 400:
+		r.Verdict = "permitted"
+		if method != "OPTIONS" && result.Status == baseline.Status && r.Similarity >= similarity.DefaultThreshold {
+			r.Verdict = "ignored"
+		}
+	default:
+		r.Verdict = "other"
+	}
+	return r
+}
+
+// addMethodsAllow collects the methods a response advertises
+func addMethodsAllow(report *MethodsReport, headers store.HeaderMap) {
+	for _, name := range []string{"allow", "access-control-allow-methods"} {
+		for _, m := range parseCommaSeparated(strings.ToUpper(store.HeaderFirst(headers, name))) {
+			if m != "*" && !containsString(report.Allow, m) {
+				report.Allow = append(report.Allow, m)
+			}
+		}
+	}
+	sort.Strings(report.Allow)
+}
+
+func printMethods(r *MethodsReport) {
+	pterm.DefaultSection.Printf("HTTP methods for %s\n", r.ID)
+	fmt.Printf("  %s\n", output.SanitizeText(r.URL))
+	allow := "-"
+	if len(r.Allow) > 0 {
+		allow = strings.Join(r.Allow, ", ")
+	}
+	fmt.Printf("  Allow: %s   Captured: %s\n\n", output.SanitizeText(allow), strings.Join(r.Observed, ", "))
+	fmt.Printf("  %-8s %6s %9s %5s  %-10s %s\n", "METHOD", "STATUS", "SIZE", "SIM", "VERDICT", "ALLOW")
+	fmt.Printf("  %-8s %6d %9s %5s  %-10s\n", r.Method, r.Status, "", "", "baseline")
+	for _, m := range r.Results {
+		if m.Error != "" {
+			fmt.Printf("  %-8s %6s  %s\n", m.Method, "ERR", m.Error)
+			continue
+		}
+		line := fmt.Sprintf("  %-8s %6d %9s %5.2f  %-10s %s", m.Method, m.Status, output.FormatBodySize(int(m.Size)),
+			m.Similarity, m.Verdict, output.SanitizeText(truncateCell(m.Allow, 40)))
+		switch {
+		case m.Unexpected != "":
+			line = pterm.Red(line + "  ★ " + m.Unexpected)
+		case m.Verdict == "permitted":
+			line = pterm.Yellow(line)
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+	if len(r.Unexpected) > 0 {
+		pterm.Warning.Printf("Unexpectedly permitted: %s\n", strings.Join(r.Unexpected, ", "))
+	} else {
+		pterm.Info.Println("No unexpectedly permitted methods")
+	}
+}
@@ -30,6 +30,9 @@ Pattern formats:
   domain/^regex$       Mute paths matching regex
   */path               Mute path on ALL domains
 
+With REP_HOST_FILTER=1 set for the native host, muted paths are dropped
+before they are written to live.json (permanently - not just hidden).
+
 Examples:
   rep mute example.com/log                     Mute /log endpoint
   rep mute example.com/api/v1/telemetry        Mute specific API path
@@ -0,0 +1,363 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/replay"
+	"github.com/repplus/rep-cli/internal/similarity"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	negotiateSaved      string
+	negotiateExec       bool
+	negotiateUseVars    bool
+	negotiateInsecure   bool
+	negotiateKinds      string
+	negotiateSimilarity float64
+)
+
+// negotiateAcceptTypes are alternate serializations worth asking for
+var negotiateAcceptTypes = []string{
+	"application/json",
+	"application/xml",
+	"text/xml",
+	"text/csv",
+	"text/html",
+	"text/plain",
+	"application/x-yaml",
+	"application/javascript",
+	"*/*",
+}
+
+// negotiateLocales mix common locales with an RTL, a wildcard and a bogus tag
+var negotiateLocales = []string{"en-US", "fr-FR", "de-DE", "ja-JP", "ar-SA", "zz-ZZ", "*"}
+
+// negotiateFormats are tried as ?format= values and as path extensions
+var negotiateFormats = []string{"json", "xml", "csv"}
+
+var negotiateKindNames = []string{"accept", "language", "format"}
+
+// NegotiationInfo describes the captured content negotiation
+type NegotiationInfo struct {
+	Accept          string `json:"accept,omitempty"`
+	AcceptLanguage  string `json:"accept_language,omitempty"`
+	ContentType     string `json:"content_type,omitempty"`
+	ContentLanguage string `json:"content_language,omitempty"`
+	Vary            string `json:"vary,omitempty"`
+}
+
+// NegotiateVariant is one probe and, after --exec, its outcome
+type NegotiateVariant struct {
+	Kind        string                 `json:"kind"`  // accept, language, format
+	Label       string                 `json:"label"` // What changed
+	URL         string                 `json:"url"`
+	Status      int                    `json:"status,omitempty"`
+	ContentType string                 `json:"content_type,omitempty"`
+	Size        int64                  `json:"size,omitempty"`
+	Diff        string                 `json:"diff,omitempty"` // same, similar, body, content-language, content-type, status
+	Similarity  *similarity.Comparison `json:"similarity,omitempty"`
+	Interesting bool                   `json:"interesting,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	request     *store.Request
+}
+
+var negotiateCmd = &cobra.Command{
+	Use:   "negotiate <request-id>",
+	Short: "Probe content negotiation (Accept, Accept-Language, format variants)",
+	Long: `Inspect a request's content negotiation and generate replay variants
+with different Accept types, locales and format selectors.
+
+Variant kinds (--kinds):
+  accept     Accept: application/xml, text/csv, text/html, ...
+  language   Accept-Language: fr-FR, ja-JP, ar-SA, zz-ZZ, *
+  format     ?format=xml|csv|json and /path.xml|.csv|.json
+
+Without --exec, prints the captured negotiation headers and the variant
+plan. With --exec, the original request is re-sent as a baseline, every
+variant is sent, and responses are diffed against the baseline. Variants
+returning 2xx with a different Content-Type are marked interesting:
+alternate serializations (XML, CSV, debug views) often skip the output
+filtering applied to the JSON path.
+
+A body scoring under --similarity-threshold against the baseline (status,
+length, word overlap and JSON structure, 0 to 1) is "body"; a changed body
+above it is "similar".
+
+Examples:
+  rep negotiate h_abc123                     Show negotiation + variant plan
+  rep negotiate h_abc123 --exec              Send variants and diff
+  rep negotiate h_abc123 --exec --kinds accept,format
+  rep negotiate h_abc123 --exec -o json      Structured results for agents`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		requestID := args[0]
+
+		kinds := make(map[string]bool)
+		for _, k := range parseCommaSeparated(strings.ToLower(negotiateKinds)) {
+			if !containsString(negotiateKindNames, k) {
+				return fmt.Errorf("invalid kind: %s (use %s)", k, strings.Join(negotiateKindNames, ", "))
+			}
+			kinds[k] = true
+		}
+		if negotiateSimilarity < 0 || negotiateSimilarity > 1 {
+			return usageError("--similarity-threshold must be between 0 and 1")
+		}
+
+		req, err := lookupRequest(requestID, negotiateSaved)
+		if err != nil {
+			return err
+		}
+		if req == nil {
+			return notFoundError("request not found: %s", requestID).withHint("Use 'rep list' to see available request IDs")
+		}
+
+		sendReq, missing := resolveSendRequest(req, negotiateUseVars)
+		if err := warnMissingVars(req, missing); err != nil {
+			return err
+		}
+
+		info := negotiationInfo(req)
+		variants := negotiationVariants(sendReq, kinds)
+
+		var baseline *replay.Result
+		if negotiateExec {
+			opts := replay.Options{Insecure: negotiateInsecure}
+			baseline, err = replay.Send(context.Background(), sendReq, opts)
+			if err != nil {
+				return fmt.Errorf("baseline request failed: %w", err)
+			}
+			for i := range variants {
+				runNegotiateVariant(&variants[i], baseline, opts)
+			}
+		}
+
+		if getOutputMode() == "json" {
+			payload := map[string]interface{}{
+				"id":          req.ID,
+				"negotiation": info,
+				"variants":    variants,
+			}
+			if baseline != nil {
+				payload["baseline"] = map[string]interface{}{
+					"status":       baseline.Status,
+					"content_type": store.HeaderFirst(baseline.Headers, "content-type"),
+					"size":         baseline.Size,
+				}
+			}
+			out, _ := sonic.MarshalIndent(payload, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		printNegotiation(req, info, variants, baseline)
+		return nil
+	},
+}
+
+func negotiationInfo(req *store.Request) NegotiationInfo {
+	info := NegotiationInfo{
+		Accept:         store.HeaderFirst(req.Headers, "accept"),
+		AcceptLanguage: store.HeaderFirst(req.Headers, "accept-language"),
+	}
+	if req.Response != nil {
+		info.ContentType = store.HeaderFirst(req.Response.Headers, "content-type")
+		info.ContentLanguage = store.HeaderFirst(req.Response.Headers, "content-language")
+		info.Vary = strings.Join(store.HeaderValues(req.Response.Headers, "vary"), ", ")
+	}
+	return info
+}
+
+// negotiationVariants builds the probe requests for the selected kinds
+// (all kinds when none are selected)
+func negotiationVariants(req *store.Request, kinds map[string]bool) []NegotiateVariant {
+	want := func(kind string) bool { return len(kinds) == 0 || kinds[kind] }
+	var variants []NegotiateVariant
+
+	withHeader := func(name, value string) *store.Request {
+		v := *req
+		v.Headers = store.CloneHeaders(req.Headers)
+		if v.Headers == nil {
+			v.Headers = store.HeaderMap{}
+		}
+		store.SetHeader(v.Headers, name, value)
+		return &v
+	}
+
+	if want("accept") {
+		current := strings.ToLower(store.HeaderFirst(req.Headers, "accept"))
+		for _, accept := range negotiateAcceptTypes {
+			if current == accept {
+				continue
+			}
+			v := withHeader("Accept", accept)
+			variants = append(variants, NegotiateVariant{Kind: "accept", Label: "Accept: " + accept, URL: v.URL, request: v})
+		}
+	}
+
+	if want("language") {
+		current := strings.ToLower(store.HeaderFirst(req.Headers, "accept-language"))
+		for _, locale := range negotiateLocales {
+			if strings.HasPrefix(current, strings.ToLower(locale)) {
+				continue
+			}
+			v := withHeader("Accept-Language", locale)
+			variants = append(variants, NegotiateVariant{Kind: "language", Label: "Accept-Language: " + locale, URL: v.URL, request: v})
+		}
+	}
+
+	if want("format") {
+		parsed, err := url.Parse(req.URL)
+		if err == nil {
+			for _, format := range negotiateFormats {
+				q := parsed.Query()
+				q.Set("format", format)
+				u := *parsed
+				u.RawQuery = q.Encode()
+				v := *req
+				v.URL = u.String()
+				variants = append(variants, NegotiateVariant{Kind: "format", Label: "?format=" + format, URL: v.URL, request: &v})
+			}
+			if path.Ext(parsed.Path) == "" && parsed.Path != "" && parsed.Path != "/" {
+				for _, format := range negotiateFormats {
+					u := *parsed
+					u.Path = strings.TrimSuffix(parsed.Path, "/") + "." + format
+					u.RawPath = ""
+					v := *req
+					v.URL = u.String()
+					variants = append(variants, NegotiateVariant{Kind: "format", Label: "." + format + " extension", URL: v.URL, request: &v})
+				}
+			}
+		}
+	}
+
+	return variants
+}
+
+// runNegotiateVariant sends one variant and diffs it against the baseline
+func runNegotiateVariant(v *NegotiateVariant, baseline *replay.Result, opts replay.Options) {
+	result, err := replay.Send(context.Background(), v.request, opts)
+	if err != nil {
+		v.Error = err.Error()
+		return
+	}
+	v.Status = result.Status
+	v.ContentType = store.HeaderFirst(result.Headers, "content-type")
+	v.Size = result.Size
+
+	comparison := similarity.Compare(baseline.Status, baseline.Body, result.Status, result.Body)
+	v.Similarity = &comparison
+	baseType := mediaType(store.HeaderFirst(baseline.Headers, "content-type"))
+	switch {
+	case result.Status != baseline.Status:
+		v.Diff = "status"
+	case mediaType(v.ContentType) != baseType:
+		v.Diff = "content-type"
+	case store.HeaderFirst(result.Headers, "content-language") != store.HeaderFirst(baseline.Headers, "content-language"):
+		v.Diff = "content-language"
+	case comparison.Different(negotiateSimilarity):
+		v.Diff = "body"
+	case result.Body != baseline.Body:
+		v.Diff = "similar"
+	default:
+		v.Diff = "same"
+	}
+	v.Interesting = result.Status >= 200 && result.Status < 300 &&
+		(v.Diff == "content-type" || v.Diff == "content-language")
+}
+
+// mediaType strips parameters (charset etc.) from a Content-Type
+func mediaType(contentType string) string {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+func printNegotiation(req *store.Request, info NegotiationInfo, variants []NegotiateVariant, baseline *replay.Result) {
+	pterm.DefaultSection.Printf("Content Negotiation for %s\n", req.ID)
+	fmt.Printf("  %s %s\n", req.Method, output.SanitizeText(req.URL))
+	printNegotiationField("Accept", info.Accept)
+	printNegotiationField("Accept-Language", info.AcceptLanguage)
+	printNegotiationField("Content-Type", info.ContentType)
+	printNegotiationField("Content-Language", info.ContentLanguage)
+	printNegotiationField("Vary", info.Vary)
+
+	if baseline == nil {
+		pterm.DefaultSection.Printf("Variants (%d)\n", len(variants))
+		for _, v := range variants {
+			fmt.Printf("  %-9s %s\n", v.Kind, v.Label)
+		}
+		fmt.Println()
+		fmt.Printf("Run: rep negotiate %s --exec   (sends %d requests + baseline)\n", req.ID, len(variants))
+		return
+	}
+
+	pterm.DefaultSection.Println("Results")
+	fmt.Printf("  %-9s %-38s %6s  %-32s %9s  %s\n", "KIND", "VARIANT", "STATUS", "CONTENT-TYPE", "SIZE", "DIFF")
+	fmt.Printf("  %-9s %-38s %6d  %-32s %9s  %s\n", "baseline", "(original)", baseline.Status,
+		truncateCell(store.HeaderFirst(baseline.Headers, "content-type"), 32), output.FormatBodySize(int(baseline.Size)), "")
+	interesting := 0
+	for _, v := range variants {
+		if v.Error != "" {
+			fmt.Printf("  %-9s %-38s %6s  %s\n", v.Kind, truncateCell(v.Label, 38), "ERR", v.Error)
+			continue
+		}
+		line := fmt.Sprintf("  %-9s %-38s %6d  %-32s %9s  %s", v.Kind, truncateCell(v.Label, 38), v.Status,
+			truncateCell(v.ContentType, 32), output.FormatBodySize(int(v.Size)), v.Diff)
+		if v.Interesting {
+			interesting++
+			line = pterm.Yellow(line + "  ★")
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+	if interesting > 0 {
+		pterm.Warning.Printf("%d variant(s) returned an alternate representation\n", interesting)
+	} else {
+		pterm.Info.Println("No alternate representations found")
+	}
+}
+
+func printNegotiationField(name, value string) {
+	if value == "" {
+		value = "-"
+	}
+	fmt.Printf("  %-17s %s\n", name+":", value)
+}
+
+// truncateCell shortens s to width for table output
+func truncateCell(s string, width int) string {
+	if len(s) <= width {
+		return s
+	}
+	return s[:width-1] + "…"
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(negotiateCmd)
+	negotiateCmd.Flags().StringVar(&negotiateSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	negotiateCmd.Flags().BoolVar(&negotiateExec, "exec", false, "Send the variants and diff responses against a baseline")
+	negotiateCmd.Flags().BoolVar(&negotiateUseVars, "use-vars", false, "Resolve auth from environment / ~/.rep/auth-<domain>.env")
+	negotiateCmd.Flags().BoolVarP(&negotiateInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+	negotiateCmd.Flags().StringVar(&negotiateKinds, "kinds", "", "Comma-separated variant kinds: accept, language, format (default all)")
+	negotiateCmd.Flags().Float64Var(&negotiateSimilarity, "similarity-threshold", similarity.DefaultThreshold, "Similarity score (0-1) under which a response body counts as different")
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// urlTokenParam matches query parameter names whose values are credentials
+// that should never travel in a URL
+var urlTokenParam = regexp.MustCompile(`(?i)^(access_token|id_token|refresh_token|token|auth_token|jwt|session|sessionid|session_id|api_key|apikey)$`)
+
+// jwtPattern matches a JSON Web Token anywhere in a string
+var jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]*`)
+
+// OAuthFinding is one misconfiguration observed in captured OAuth/OIDC traffic
+type OAuthFinding struct {
+	Check    string   `json:"check"`    // missing-state, state-reuse, redirect-uri, implicit-flow, missing-pkce, plain-pkce, token-in-url, token-in-referer
+	Severity string   `json:"severity"` // high, medium, low
+	Domain   string   `json:"domain"`
+	Detail   string   `json:"detail"`
+	Evidence []string `json:"evidence"` // Request IDs
+}
+
+var oauthSeverityRank = map[string]int{"high": 0, "medium": 1, "low": 2}
+
+// checkOAuth runs the OAuth/OIDC checks over requests in capture order
+func checkOAuth(requests []store.Request) []OAuthFinding {
+	var findings []OAuthFinding
+	add := func(check, severity, domain, detail string, ids ...string) {
+		findings = append(findings, OAuthFinding{Check: check, Severity: severity, Domain: domain, Detail: detail, Evidence: ids})
+	}
+
+	states := make(map[string][]string)       // state -> authorize request IDs
+	redirectURIs := make(map[string][]string) // client_id -> redirect_uri values
+	redirectIDs := make(map[string][]string)  // client_id -> request IDs
+	for i := range requests {
+		req := &requests[i]
+		parsed, err := url.Parse(req.URL)
+		if err != nil {
+			continue
+		}
+		query := parsed.Query()
+		host := parsed.Hostname()
+
+		if responseType := query.Get("response_type"); responseType != "" {
+			types := strings.Fields(responseType)
+			state := query.Get("state")
+			switch {
+			case state == "":
+				add("missing-state", "high", host, "authorize request without a state parameter (login CSRF)", req.ID)
+			case len(state) < 8:
+				add("missing-state", "medium", host, fmt.Sprintf("short, guessable state %q", state), req.ID)
+			default:
+				states[state] = append(states[state], req.ID)
+			}
+			if containsString(types, "token") || (containsString(types, "id_token") && !containsString(types, "code")) {
+				add("implicit-flow", "medium", host, fmt.Sprintf("implicit flow (response_type=%s): tokens are returned in the URL fragment", responseType), req.ID)
+			}
+			if containsString(types, "code") {
+				switch method := query.Get("code_challenge_method"); {
+				case query.Get("code_challenge") == "":
+					add("missing-pkce", "medium", host, "authorization code flow without PKCE (code_challenge)", req.ID)
+				case method == "" || strings.EqualFold(method, "plain"):
+					add("plain-pkce", "low", host, "PKCE with the plain method: the verifier equals the challenge", req.ID)
+				}
+			}
+			if redirectURI := query.Get("redirect_uri"); redirectURI != "" {
+				clientID := host + " " + query.Get("client_id")
+				if !containsString(redirectURIs[clientID], redirectURI) {
+					redirectURIs[clientID] = append(redirectURIs[clientID], redirectURI)
+				}
+				redirectIDs[clientID] = append(redirectIDs[clientID], req.ID)
+				checkRedirectURI(req, host, redirectURI, add)
+			}
+		}
+
+		for name, values := range query {
+			for _, v := range values {
+				if (urlTokenParam.MatchString(name) && len(v) >= 12) || jwtPattern.MatchString(v) {
+					add("token-in-url", "medium", host, fmt.Sprintf("credential in the query string (%s): logged by servers and proxies, kept in history", name), req.ID)
+				}
+			}
+		}
+
+		if referer := store.HeaderFirst(req.Headers, "referer"); referer != "" {
+			if leaked := refererTokenParams(referer); len(leaked) > 0 {
+				severity, where := "medium", "same-site request"
+				if store.GetBaseDomain(hostFromURL(referer)) != store.GetBaseDomain(host) {
+					severity, where = "high", "third party "+host
+				}
+				add("token-in-referer", severity, hostFromURL(referer),
+					fmt.Sprintf("Referer carries %s to %s", strings.Join(leaked, ", "), where), req.ID)
+			}
+		}
+	}
+
+	stateKeys := make([]string, 0, len(states))
+	for state := range states {
+		stateKeys = append(stateKeys, state)
+	}
+	sort.Strings(stateKeys)
+	for _, state := range stateKeys {
+		if ids := states[state]; len(ids) > 1 {
+			domain := hostFromURL(requestURLByID(requests, ids[0]))
+			add("state-reuse", "high", domain, fmt.Sprintf("state %q used by %d authorize requests: not bound to a single login", state, len(ids)), ids...)
+		}
+	}
+
+	clients := make([]string, 0, len(redirectURIs))
+	for client := range redirectURIs {
+		clients = append(clients, client)
+	}
+	sort.Strings(clients)
+	for _, client := range clients {
+		if uris := redirectURIs[client]; len(uris) > 1 {
+			host, clientID, _ := strings.Cut(client, " ")
+			add("redirect-uri", "medium", host, fmt.Sprintf("client %q accepted %d different redirect_uri values (%s): matching may be loose, try other paths and hosts",
+				clientID, len(uris), strings.Join(uris, ", ")), redirectIDs[client]...)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Domain != findings[j].Domain {
+			return findings[i].Domain < findings[j].Domain
+		}
+		return oauthSeverityRank[findings[i].Severity] < oauthSeverityRank[findings[j].Severity]
+	})
+	return findings
+}
+
+// checkRedirectURI flags redirect_uri values that are weak on their own:
+// plain http, a bare origin, or a host outside the authorization server's
+// and the app page's registrable domains
+func checkRedirectURI(req *store.Request, host, redirectURI string, add func(check, severity, domain, detail string, ids ...string)) {
+	target, err := url.Parse(redirectURI)
+	if err != nil || target.Host == "" {
+		add("redirect-uri", "low", host, fmt.Sprintf("redirect_uri %q is not an absolute URL", redirectURI), req.ID)
+		return
+	}
+	if target.Scheme == "http" && target.Hostname() != "localhost" && target.Hostname() != "127.0.0.1" {
+		add("redirect-uri", "medium", host, fmt.Sprintf("redirect_uri %s uses plain http: the code travels unencrypted", redirectURI), req.ID)
+	}
+	if target.Path == "" || target.Path == "/" {
+		add("redirect-uri", "low", host, fmt.Sprintf("redirect_uri %s is a bare origin: any page on it can receive the code (open redirects, XSS)", redirectURI), req.ID)
+	}
+	targetBase := store.GetBaseDomain(target.Hostname())
+	pageBase := store.GetBaseDomain(hostFromURL(req.PageURL))
+	if targetBase != store.GetBaseDomain(host) && targetBase != pageBase {
+		add("redirect-uri", "medium", host, fmt.Sprintf("redirect_uri points to %s, outside the app's domain", target.Hostname()), req.ID)
+	}
+}
+
+// refererTokenParams returns the credential parameters present in a
+// Referer URL (query or fragment)
+func refererTokenParams(referer string) []string {
+	parsed, err := url.Parse(referer)
+	if err != nil {
+		return nil
+	}
+	var leaked []string
+	for _, raw := range []string{parsed.RawQuery, parsed.Fragment} {
+		values, err := url.ParseQuery(raw)
+		if err != nil {
+			continue
+		}
+		for name, v := range values {
+			if (name == "code" || urlTokenParam.MatchString(name)) && len(v) > 0 && len(v[0]) >= 6 && !containsString(leaked, name) {
+				leaked = append(leaked, name)
+			}
+		}
+	}
+	sort.Strings(leaked)
+	return leaked
+}
+
+// requestURLByID returns the URL of a request ID
+func requestURLByID(requests []store.Request, id string) string {
+	if i := requestIndex(requests, id); i >= 0 {
+		return requests[i].URL
+	}
+	return ""
+}
+
+func printOAuthFindings(findings []OAuthFinding) {
+	if len(findings) == 0 {
+		pterm.Success.Println("No OAuth/OIDC misconfiguration observed in the captured flows")
+		return
+	}
+	pterm.DefaultSection.Printf("OAuth/OIDC findings (%d)\n", len(findings))
+	domain := ""
+	for _, f := range findings {
+		if f.Domain != domain {
+			domain = f.Domain
+			fmt.Printf("%s\n", pterm.Bold.Sprint(output.SanitizeText(domain)))
+		}
+		severity := pterm.FgGray.Sprintf("%-6s", f.Severity)
+		switch f.Severity {
+		case "high":
+			severity = pterm.FgRed.Sprintf("%-6s", f.Severity)
+		case "medium":
+			severity = pterm.FgYellow.Sprintf("%-6s", f.Severity)
+		}
+		fmt.Printf("  %s %-16s %s\n", severity, f.Check, output.SanitizeText(f.Detail))
+		fmt.Printf("  %s\n", pterm.FgGray.Sprint(strings.Repeat(" ", 24)+"evidence: "+strings.Join(f.Evidence, ", ")))
+	}
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	overridesFilter requestFilterFlags
+	overridesSaved  string
+)
+
+// methodOverrideHeaders are request headers frameworks honor to rewrite the verb
+var methodOverrideHeaders = []string{
+	"X-HTTP-Method-Override",
+	"X-HTTP-Method",
+	"X-Method-Override",
+}
+
+// methodOverrideParams are query/form/JSON fields used for verb tunneling
+var methodOverrideParams = map[string]bool{
+	"_method":                true,
+	"__method":               true,
+	"_httpmethod":            true,
+	"httpmethod":             true,
+	"x-http-method-override": true,
+}
+
+// OverrideFinding is one request that tunnels an HTTP verb
+type OverrideFinding struct {
+	ID         string `json:"id"`
+	Method     string `json:"method"`     // Verb on the wire
+	Overridden string `json:"overridden"` // Verb requested through the override
+	Via        string `json:"via"`        // header, query, form, json
+	Field      string `json:"field"`      // Header or parameter name
+	URL        string `json:"url"`
+	Status     int    `json:"status"`
+	Accepted   bool   `json:"accepted"` // 2xx/3xx response
+}
+
+// OverrideEndpoint summarizes override usage per endpoint
+type OverrideEndpoint struct {
+	Endpoint   string   `json:"endpoint"` // domain + path
+	Verbs      []string `json:"verbs"`    // Overridden verbs seen
+	Accepted   []string `json:"accepted"` // Overridden verbs that got 2xx/3xx
+	Requests   int      `json:"requests"`
+	RequestIDs []string `json:"request_ids"`
+}
+
+// OverridesOutput is the structured output for agent consumption
+type OverridesOutput struct {
+	Scanned   int                `json:"scanned"`
+	Findings  []OverrideFinding  `json:"findings"`
+	Endpoints []OverrideEndpoint `json:"endpoints"`
+}
+
+var overridesCmd = &cobra.Command{
+	Use:   "overrides",
+	Short: "Detect HTTP method override / verb tunneling",
+	Long: `Flag requests that tunnel an HTTP verb through another one.
+
+Detects:
+  Headers   X-HTTP-Method-Override, X-HTTP-Method, X-Method-Override
+  Query     ?_method=DELETE (also __method, _HttpMethod, httpMethod)
+  Form      _method=PUT in urlencoded bodies
+  JSON      {"_method": "PATCH"} in JSON bodies
+
+Endpoints where an overridden verb got a 2xx/3xx response are listed
+first: the server honored the override, so access controls keyed on the
+wire verb (WAF rules, CSRF checks, route guards) may be bypassable.
+
+Accepts the same filter flags as 'rep list'.
+
+Examples:
+  rep overrides                     Scan primary-domain traffic
+  rep overrides --primary=false     Scan everything
+  rep overrides --saved latest      Scan a saved session
+  rep overrides -o json             Structured output for agents`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := overridesFilter.options()
+		if err != nil {
+			return err
+		}
+		requests, err := filterSource(overridesSaved, opts)
+		if err != nil || requests == nil {
+			return err
+		}
+
+		result := OverridesOutput{Scanned: len(requests), Findings: []OverrideFinding{}}
+		for i := range requests {
+			result.Findings = append(result.Findings, detectMethodOverrides(&requests[i])...)
+		}
+		result.Endpoints = summarizeOverrides(requests, result.Findings)
+
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(result, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(result.Findings) == 0 {
+			return softFail(emptyError("No method override usage in %d requests", result.Scanned))
+		}
+
+		pterm.DefaultSection.Println("Method Override Endpoints")
+		for _, ep := range result.Endpoints {
+			if len(ep.Accepted) > 0 {
+				pterm.Warning.Printf("%s accepted %s (%d requests)\n", ep.Endpoint, strings.Join(ep.Accepted, ","), ep.Requests)
+			} else {
+				pterm.Info.Printf("%s tried %s, rejected (%d requests)\n", ep.Endpoint, strings.Join(ep.Verbs, ","), ep.Requests)
+			}
+		}
+
+		pterm.DefaultSection.Println("Requests")
+		for _, f := range result.Findings {
+			fmt.Printf("[%s] %s→%s via %s %s %s → %d\n", f.ID, f.Method, f.Overridden, f.Via, f.Field, f.URL, f.Status)
+		}
+		fmt.Println()
+		fmt.Println("Next: rep curl <id> to replay, then try other verbs on the same endpoint")
+		return nil
+	},
+}
+
+// detectMethodOverrides returns every verb override carried by req
+func detectMethodOverrides(req *store.Request) []OverrideFinding {
+	var findings []OverrideFinding
+	add := func(via, field, verb string) {
+		verb = strings.ToUpper(strings.TrimSpace(verb))
+		if verb == "" {
+			return
+		}
+		status := 0
+		if req.Response != nil {
+			status = req.Response.Status
+		}
+		findings = append(findings, OverrideFinding{
+			ID:         req.ID,
+			Method:     req.Method,
+			Overridden: verb,
+			Via:        via,
+			Field:      field,
+			URL:        req.URL,
+			Status:     status,
+			Accepted:   status >= 200 && status < 400,
+		})
+	}
+
+	for _, name := range methodOverrideHeaders {
+		if v := store.HeaderFirst(req.Headers, name); v != "" {
+			add("header", name, v)
+		}
+	}
+
+	if parsed, err := url.Parse(req.URL); err == nil {
+		for key, values := range parsed.Query() {
+			if methodOverrideParams[strings.ToLower(key)] && len(values) > 0 {
+				add("query", key, values[0])
+			}
+		}
+	}
+
+	if req.Body != "" {
+		contentType := strings.ToLower(store.HeaderFirst(req.Headers, "content-type"))
+		switch {
+		case strings.Contains(contentType, "json"):
+			var body map[string]interface{}
+			if sonic.UnmarshalString(req.Body, &body) == nil {
+				for key, v := range body {
+					if s, ok := v.(string); ok && methodOverrideParams[strings.ToLower(key)] {
+						add("json", key, s)
+					}
+				}
+			}
+		case strings.Contains(contentType, "x-www-form-urlencoded"), contentType == "" && strings.Contains(req.Body, "="):
+			if form, err := url.ParseQuery(req.Body); err == nil {
+				for key, values := range form {
+					if methodOverrideParams[strings.ToLower(key)] && len(values) > 0 {
+						add("form", key, values[0])
+					}
+				}
+			}
+		}
+	}
+
+	return findings
+}
+
+// summarizeOverrides groups findings by endpoint, accepted overrides first
+func summarizeOverrides(requests []store.Request, findings []OverrideFinding) []OverrideEndpoint {
+	endpointOf := make(map[string]string, len(requests))
+	for i := range requests {
+		_, path, _ := strings.Cut(requestEndpoint(&requests[i]), " ")
+		endpointOf[requests[i].ID] = requests[i].Domain + path
+	}
+
+	byEndpoint := make(map[string]*OverrideEndpoint)
+	var order []string
+	for _, f := range findings {
+		key := endpointOf[f.ID]
+		ep, ok := byEndpoint[key]
+		if !ok {
+			ep = &OverrideEndpoint{Endpoint: key, Verbs: []string{}, Accepted: []string{}}
+			byEndpoint[key] = ep
+			order = append(order, key)
+		}
+		ep.Verbs = appendUnique(ep.Verbs, f.Overridden)
+		if f.Accepted {
+			ep.Accepted = appendUnique(ep.Accepted, f.Overridden)
+		}
+		if len(ep.RequestIDs) == 0 || ep.RequestIDs[len(ep.RequestIDs)-1] != f.ID {
+			ep.RequestIDs = append(ep.RequestIDs, f.ID)
+			ep.Requests++
+		}
+	}
+
+	result := make([]OverrideEndpoint, 0, len(order))
+	for _, key := range order {
+		result = append(result, *byEndpoint[key])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return len(result[i].Accepted) > 0 && len(result[j].Accepted) == 0
+	})
+	return result
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+func init() {
+	rootCmd.AddCommand(overridesCmd)
+	overridesFilter.register(overridesCmd)
+	overridesCmd.Flags().StringVar(&overridesSaved, "saved", "", "Read from saved session (ID or 'latest')")
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pageFilter requestFilterFlags
+	pageSaved  string
+	pageList   bool
+	pageDetail bool
+)
+
+// pageDomainGroup is the requests a page made to one domain, by type
+type pageDomainGroup struct {
+	Domain   string          `json:"domain"`
+	Count    int             `json:"count"`
+	Types    []pageTypeGroup `json:"types"`
+	requests []store.Request
+}
+
+// pageTypeGroup is the requests of one resource type
+type pageTypeGroup struct {
+	Type     string                 `json:"type"`
+	Count    int                    `json:"count"`
+	Requests []output.RequestOutput `json:"requests,omitempty"`
+	requests []store.Request
+}
+
+var pageCmd = &cobra.Command{
+	Use:   "page [page-url] [filter flags]",
+	Short: "List captured pages or drill into the requests one page made",
+	Long: `Page-centric view of the capture. Requests are attributed to the page
+(tab URL) that initiated them.
+
+Without an argument (or with --list), lists the captured pages with their
+request counts and the domains they talked to. With a page URL, shows every
+request that page initiated, grouped by domain (the page's own domain
+first) and then by resource type (document, script, fetch, ...).
+
+The page can be given exactly or as any unique part of its URL. The usual
+filter flags and output modes of 'rep list' apply: -d, --api, --type,
+--since, ..., and -o meta/full/json/ndjson/csv. As in 'rep list', only
+primary domains are shown by default; --primary=false includes the
+third-party requests the page made.
+
+Examples:
+  rep page                                  List pages
+  rep page https://app.acme.test/dashboard  Requests made by that page
+  rep page dashboard --primary=false        Include third parties
+  rep page dashboard --api -o json          API calls of the page, grouped
+  rep page checkout --detail -o meta        Headers of every request`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := pageFilter.options()
+		if err != nil {
+			return err
+		}
+		requests, err := filterSource(pageSaved, opts)
+		if err != nil || requests == nil {
+			return err
+		}
+		pages := store.NewTempStore(requests).GetPageFlows()
+
+		if pageList || len(args) == 0 {
+			printPageList(pages)
+			return nil
+		}
+
+		pageURL, candidates := resolvePage(pages, args[0])
+		if pageURL == "" {
+			if len(candidates) == 0 {
+				return notFoundError("no captured page matches %q", args[0]).withHint("Use 'rep page --list' to see captured pages")
+			}
+			pterm.Warning.Printf("%q matches %d pages; be more specific:\n", args[0], len(candidates))
+			for _, p := range candidates {
+				fmt.Printf("  %s\n", output.SanitizeText(p))
+			}
+			return nil
+		}
+
+		var pageRequests []store.Request
+		for _, req := range requests {
+			if req.PageURL == pageURL {
+				pageRequests = append(pageRequests, req)
+			}
+		}
+		groups := groupPageRequests(pageURL, pageRequests)
+
+		switch format := getOutputMode(); format {
+		case "ndjson", "csv":
+			return streamRequests(os.Stdout, pageRequests, format, func(req *store.Request) string {
+				return req.Domain + " " + pageRequestType(req)
+			})
+		case "json":
+			cfg := truncateConfig()
+			for i := range groups {
+				for j := range groups[i].Types {
+					t := &groups[i].Types[j]
+					t.Requests = output.FormatRequests(t.requests, store.OutputJSON, cfg)
+				}
+			}
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"page_url": pageURL,
+				"total":    len(pageRequests),
+				"domains":  groups,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		mode := store.OutputCompact
+		switch getOutputMode() {
+		case "meta":
+			mode = store.OutputMeta
+		case "full":
+			mode = store.OutputFull
+		}
+		printPageRequests(pageURL, groups, len(pageRequests), mode, pageDetail || mode != store.OutputCompact)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pageCmd)
+	pageFilter.register(pageCmd)
+	pageCmd.Flags().StringVar(&pageSaved, "saved", "", "Read from saved session (ID, prefix, or 'latest')")
+	pageCmd.Flags().BoolVar(&pageList, "list", false, "List captured pages")
+	pageCmd.Flags().BoolVar(&pageDetail, "detail", false, "Show multi-line request details")
+}
+
+// resolvePage finds the page for an exact URL or a unique case-insensitive
+// part of one. When the match is ambiguous it returns the candidates.
+func resolvePage(pages []store.PageFlowInfo, query string) (string, []string) {
+	var candidates []string
+	needle := strings.ToLower(query)
+	for _, p := range pages {
+		if p.PageURL == query {
+			return p.PageURL, nil
+		}
+		if strings.Contains(strings.ToLower(p.PageURL), needle) {
+			candidates = append(candidates, p.PageURL)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	sort.Strings(candidates)
+	return "", candidates
+}
+
+// pageRequestType is the resource type a request is grouped under
+func pageRequestType(req *store.Request) string {
+	if req.ResourceType == "" {
+		return "other"
+	}
+	return req.ResourceType
+}
+
+// groupPageRequests groups a page's requests by domain (the page's own
+// domain first, then by request count) and by resource type
+func groupPageRequests(pageURL string, requests []store.Request) []pageDomainGroup {
+	var groups []pageDomainGroup
+	index := make(map[string]int)
+	for _, req := range requests {
+		i, ok := index[req.Domain]
+		if !ok {
+			i = len(groups)
+			index[req.Domain] = i
+			groups = append(groups, pageDomainGroup{Domain: req.Domain})
+		}
+		groups[i].Count++
+		groups[i].requests = append(groups[i].requests, req)
+	}
+
+	pageHost := hostFromURL(pageURL)
+	sort.SliceStable(groups, func(i, j int) bool {
+		iOwn, jOwn := hostFromURL("https://"+groups[i].Domain) == pageHost, hostFromURL("https://"+groups[j].Domain) == pageHost
+		if iOwn != jOwn {
+			return iOwn
+		}
+		return groups[i].Count > groups[j].Count
+	})
+
+	for i := range groups {
+		typeIndex := make(map[string]int)
+		for _, req := range groups[i].requests {
+			t := pageRequestType(&req)
+			j, ok := typeIndex[t]
+			if !ok {
+				j = len(groups[i].Types)
+				typeIndex[t] = j
+				groups[i].Types = append(groups[i].Types, pageTypeGroup{Type: t})
+			}
+			groups[i].Types[j].Count++
+			groups[i].Types[j].requests = append(groups[i].Types[j].requests, req)
+		}
+		sort.SliceStable(groups[i].Types, func(a, b int) bool {
+			return groups[i].Types[a].Count > groups[i].Types[b].Count
+		})
+	}
+	return groups
+}
+
+func printPageList(pages []store.PageFlowInfo) {
+	if getOutputMode() == "json" {
+		out, _ := sonic.MarshalIndent(map[string]interface{}{
+			"total": len(pages),
+			"pages": pages,
+		}, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+	if len(pages) == 0 {
+		pterm.Info.Println("No requests with a page URL match the filter")
+		return
+	}
+	pterm.DefaultSection.Printf("Pages (%d)\n", len(pages))
+	for _, p := range pages {
+		domains := strings.Join(p.RequestedDomains, ", ")
+		fmt.Printf("  %5d  %s\n", p.RequestCount, output.SanitizeText(p.PageURL))
+		fmt.Printf("         %s\n", pterm.FgGray.Sprint(truncateCell(output.SanitizeText(domains), 100)))
+	}
+	fmt.Println()
+	pterm.Info.Println("Drill into a page: rep page <page-url or part of it>")
+}
+
+func printPageRequests(pageURL string, groups []pageDomainGroup, total int, mode store.OutputMode, detail bool) {
+	pterm.DefaultSection.Printf("%s (%d requests, %d domains)\n", output.SanitizeText(pageURL), total, len(groups))
+	for _, g := range groups {
+		fmt.Printf("%s (%d)\n", pterm.Bold.Sprint(output.SanitizeText(g.Domain)), g.Count)
+		for _, t := range g.Types {
+			fmt.Printf("  %s\n", pterm.FgCyan.Sprintf("%s (%d)", t.Type, t.Count))
+			for i := range t.requests {
+				if detail {
+					printRequest(&t.requests[i], mode)
+					fmt.Println()
+					continue
+				}
+				fmt.Printf("    %s\n", output.SanitizeText(output.FormatRequestCompact(&t.requests[i])))
+			}
+		}
+		fmt.Println()
+	}
+	fmt.Println("Use 'rep body <id>' to get full response body for a specific request")
+}
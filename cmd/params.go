@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	paramsSaved       string
+	paramsAllSessions bool
+	paramsMatch       string
+	paramsPlain       bool
+)
+
+var paramsCmd = &cobra.Command{
+	Use:   "params",
+	Short: "List parameter names with how often and where they were sent",
+	Long: `List every query and body parameter name (JSON top-level fields and
+form fields) with the number of requests and endpoints sending it. Rare
+parameters on many endpoints (debug, redirect_uri, callback) are the ones
+worth fuzzing; --plain prints a wordlist for ffuf or Arjun.
+
+With --all-sessions every saved and archived session is covered through the
+search index (see 'rep grep'), without reading the sessions themselves.
+
+Examples:
+  rep params                                Live capture
+  rep params --all-sessions                 Every session
+  rep params --all-sessions --plain > params.txt
+  rep params --match id --saved latest -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ix, err := searchSource(paramsSaved, paramsAllSessions)
+		if err != nil || ix == nil {
+			return err
+		}
+
+		usage := ix.TermUsage(store.TermParam)
+		if paramsMatch != "" {
+			needle := strings.ToLower(paramsMatch)
+			kept := usage[:0]
+			for _, u := range usage {
+				if strings.Contains(strings.ToLower(u.Term), needle) {
+					kept = append(kept, u)
+				}
+			}
+			usage = kept
+		}
+
+		if paramsPlain {
+			for _, u := range usage {
+				fmt.Println(u.Term)
+			}
+			return nil
+		}
+
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"sessions": len(ix.Sessions),
+				"total":    len(usage),
+				"params":   usage,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(usage) == 0 {
+			return softFail(emptyError("No parameters found in %d session(s)", len(ix.Sessions)))
+		}
+		pterm.DefaultSection.Printf("Parameters (%d)\n", len(usage))
+		fmt.Printf("  %-32s %8s %9s %8s\n", "NAME", "REQUESTS", "ENDPOINTS", "SESSIONS")
+		for _, u := range usage {
+			fmt.Printf("  %-32s %8d %9d %8d\n", truncateCell(u.Term, 32), u.Requests, u.Endpoints, len(u.Sessions))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(paramsCmd)
+	paramsCmd.Flags().StringVar(&paramsSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	paramsCmd.Flags().BoolVar(&paramsAllSessions, "all-sessions", false, "Cover every saved and archived session through the index")
+	paramsCmd.Flags().StringVar(&paramsMatch, "match", "", "Only names containing this text")
+	paramsCmd.Flags().BoolVar(&paramsPlain, "plain", false, "One name per line (wordlist)")
+}
@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/pii"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	piiFilter  requestFilterFlags
+	piiSaved   string
+	piiAccount string
+	piiKinds   string
+	piiOthers  bool
+)
+
+// selfPath matches endpoints that describe the logged-in account
+var selfPath = regexp.MustCompile(`(?i)/(me|self|whoami|profile|account|userinfo|current_?user|session)(/|$|\?)`)
+
+// staticResourceTypes are resource types whose bodies are code or media, not data
+var staticResourceTypes = []string{"script", "stylesheet", "image", "font", "media"}
+
+// PIIValue is one piece of personal data an endpoint returned
+type PIIValue struct {
+	Kind  string   `json:"kind"`
+	Value string   `json:"value"`
+	Owner string   `json:"owner"` // account, other, unknown
+	IDs   []string `json:"ids"`
+}
+
+// PIIEndpoint is an endpoint whose responses carry personal data
+type PIIEndpoint struct {
+	Method   string         `json:"method"`
+	Domain   string         `json:"domain"`
+	Path     string         `json:"path"`
+	Exposure string         `json:"exposure"` // others, account, unknown
+	Kinds    map[string]int `json:"kinds"`    // Kind -> distinct values
+	Values   []PIIValue     `json:"values"`
+	IDs      []string       `json:"ids"`
+}
+
+// PIIDomain totals the personal data found on one domain
+type PIIDomain struct {
+	Domain    string         `json:"domain"`
+	Endpoints int            `json:"endpoints"`
+	Others    int            `json:"others"` // Endpoints returning other users' data
+	Kinds     map[string]int `json:"kinds"`
+}
+
+var piiCmd = &cobra.Command{
+	Use:   "pii [filter flags]",
+	Short: "Find personal data in responses and endpoints leaking other users' data",
+	Long: `Scan response bodies for personal data: email addresses, phone
+numbers, national ID numbers (US SSN, UK NINO, Brazilian CPF, Indian
+Aadhaar) and geographic coordinates. Findings are aggregated by endpoint
+(method, host and path template) and by domain.
+
+Each value is attributed to the authenticated account or to someone else.
+The account's own data is what the client sent itself (request bodies and
+URLs, e.g. the login email or a profile update) plus what /me, /profile,
+/account, /userinfo and similar endpoints returned, and anything given with
+--account. A value is "other" when the account's own values of that kind
+are known and it is not one of them, else "unknown". Endpoints returning
+other users' data are listed first (★): the classic disclosure bug.
+
+Scripts, stylesheets and media are skipped. Takes the same filter flags as
+'rep list' (primary domains only by default).
+
+Examples:
+  rep pii                               All endpoints with personal data
+  rep pii --others                      Only endpoints leaking other users' data
+  rep pii --account alice@acme.test     Declare the account's identity
+  rep pii --kinds email,phone -o json
+  rep pii -d api.acme.test --saved latest`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		kinds := make(map[string]bool)
+		for _, k := range parseCommaSeparated(strings.ToLower(piiKinds)) {
+			if !containsString(pii.Kinds, k) {
+				return fmt.Errorf("invalid kind: %s (use %s)", k, strings.Join(pii.Kinds, ", "))
+			}
+			kinds[k] = true
+		}
+
+		opts, err := piiFilter.options()
+		if err != nil {
+			return err
+		}
+		requests, err := filterSource(piiSaved, opts)
+		if err != nil || requests == nil {
+			return err
+		}
+		store.SortRequests(requests, "time", false)
+
+		account := accountIdentity(requests, parseCommaSeparated(piiAccount))
+		endpoints := scanPII(requests, account, kinds)
+		if piiOthers {
+			kept := endpoints[:0]
+			for _, e := range endpoints {
+				if e.Exposure == "others" {
+					kept = append(kept, e)
+				}
+			}
+			endpoints = kept
+		}
+		domains := piiDomains(endpoints)
+
+		if getOutputMode() == "json" {
+			accountValues := make([]pii.Match, 0, len(account))
+			for key := range account {
+				kind, value, _ := strings.Cut(key, "\x00")
+				accountValues = append(accountValues, pii.Match{Kind: kind, Value: value})
+			}
+			sort.Slice(accountValues, func(i, j int) bool {
+				return accountValues[i].Kind+accountValues[i].Value < accountValues[j].Kind+accountValues[j].Value
+			})
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"account":   accountValues,
+				"domains":   domains,
+				"endpoints": endpoints,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		printPII(account, domains, endpoints)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(piiCmd)
+	piiFilter.register(piiCmd)
+	piiCmd.Flags().StringVar(&piiSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	piiCmd.Flags().StringVar(&piiAccount, "account", "", "Comma-separated values belonging to the authenticated account (email, phone, ...)")
+	piiCmd.Flags().StringVar(&piiKinds, "kinds", "", "Comma-separated kinds: "+strings.Join(pii.Kinds, ", ")+" (default all)")
+	piiCmd.Flags().BoolVar(&piiOthers, "others", false, "Only endpoints returning other users' data")
+}
+
+// accountIdentity collects the authenticated account's own values, keyed
+// "kind\x00value": what the client sent and what self endpoints returned
+func accountIdentity(requests []store.Request, declared []string) map[string]bool {
+	account := make(map[string]bool)
+	add := func(text string) {
+		for _, m := range pii.Scan(text) {
+			account[m.Kind+"\x00"+m.Value] = true
+		}
+	}
+	for _, value := range declared {
+		add(value)
+	}
+	for i := range requests {
+		req := &requests[i]
+		add(unescapeForScan(req.URL))
+		add(unescapeForScan(req.Body))
+		if selfPath.MatchString(req.URL) && req.Response != nil && req.Response.Status < 300 {
+			add(store.ResponseBodyText(req))
+		}
+	}
+	return account
+}
+
+// unescapeForScan decodes percent-encoding so form bodies and query
+// strings scan like plain text
+func unescapeForScan(s string) string {
+	if decoded, err := url.QueryUnescape(s); err == nil {
+		return decoded
+	}
+	return s
+}
+
+// scanPII aggregates the personal data in response bodies by endpoint
+func scanPII(requests []store.Request, account map[string]bool, kinds map[string]bool) []PIIEndpoint {
+	accountKinds := make(map[string]bool)
+	for key := range account {
+		kind, _, _ := strings.Cut(key, "\x00")
+		accountKinds[kind] = true
+	}
+
+	index := make(map[string]*PIIEndpoint)
+	var order []string
+	valueIndex := make(map[string]int) // endpoint key + kind + value -> index in Values
+	for i := range requests {
+		req := &requests[i]
+		if req.Response == nil || containsString(staticResourceTypes, req.ResourceType) {
+			continue
+		}
+		matches := pii.Scan(store.ResponseBodyText(req))
+		if len(matches) == 0 {
+			continue
+		}
+		parsed, err := url.Parse(req.URL)
+		if err != nil {
+			continue
+		}
+		template := store.EndpointTemplate(parsed.Path)
+		key := req.Method + " " + parsed.Host + template
+		e := index[key]
+		for _, m := range matches {
+			if len(kinds) > 0 && !kinds[m.Kind] {
+				continue
+			}
+			if e == nil {
+				e = &PIIEndpoint{Method: req.Method, Domain: parsed.Host, Path: template, Kinds: make(map[string]int)}
+				index[key] = e
+				order = append(order, key)
+			}
+			if !containsString(e.IDs, req.ID) {
+				e.IDs = append(e.IDs, req.ID)
+			}
+			vkey := key + "\x00" + m.Kind + "\x00" + m.Value
+			if j, ok := valueIndex[vkey]; ok {
+				if !containsString(e.Values[j].IDs, req.ID) {
+					e.Values[j].IDs = append(e.Values[j].IDs, req.ID)
+				}
+				continue
+			}
+			owner := "unknown"
+			switch {
+			case account[m.Kind+"\x00"+m.Value]:
+				owner = "account"
+			case accountKinds[m.Kind]:
+				owner = "other"
+			}
+			valueIndex[vkey] = len(e.Values)
+			e.Values = append(e.Values, PIIValue{Kind: m.Kind, Value: m.Value, Owner: owner, IDs: []string{req.ID}})
+			e.Kinds[m.Kind]++
+		}
+	}
+
+	endpoints := make([]PIIEndpoint, 0, len(order))
+	for _, key := range order {
+		e := index[key]
+		e.Exposure = "unknown"
+		owners := make(map[string]bool)
+		for _, v := range e.Values {
+			owners[v.Owner] = true
+		}
+		switch {
+		case owners["other"]:
+			e.Exposure = "others"
+		case owners["account"] && !owners["unknown"]:
+			e.Exposure = "account"
+		}
+		endpoints = append(endpoints, *e)
+	}
+	rank := map[string]int{"others": 0, "unknown": 1, "account": 2}
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		if rank[endpoints[i].Exposure] != rank[endpoints[j].Exposure] {
+			return rank[endpoints[i].Exposure] < rank[endpoints[j].Exposure]
+		}
+		return len(endpoints[i].Values) > len(endpoints[j].Values)
+	})
+	return endpoints
+}
+
+// piiDomains totals endpoints by domain, most exposed first
+func piiDomains(endpoints []PIIEndpoint) []PIIDomain {
+	index := make(map[string]*PIIDomain)
+	var order []string
+	for _, e := range endpoints {
+		d := index[e.Domain]
+		if d == nil {
+			d = &PIIDomain{Domain: e.Domain, Kinds: make(map[string]int)}
+			index[e.Domain] = d
+			order = append(order, e.Domain)
+		}
+		d.Endpoints++
+		if e.Exposure == "others" {
+			d.Others++
+		}
+		for kind, n := range e.Kinds {
+			d.Kinds[kind] += n
+		}
+	}
+	domains := make([]PIIDomain, 0, len(order))
+	for _, domain := range order {
+		domains = append(domains, *index[domain])
+	}
+	sort.SliceStable(domains, func(i, j int) bool {
+		if domains[i].Others != domains[j].Others {
+			return domains[i].Others > domains[j].Others
+		}
+		return domains[i].Endpoints > domains[j].Endpoints
+	})
+	return domains
+}
+
+// formatPIIKinds renders kind counts in pii.Kinds order: "email 3, phone 1"
+func formatPIIKinds(kinds map[string]int) string {
+	var parts []string
+	for _, kind := range pii.Kinds {
+		if n := kinds[kind]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", kind, n))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printPII(account map[string]bool, domains []PIIDomain, endpoints []PIIEndpoint) {
+	if len(endpoints) == 0 {
+		pterm.Info.Println("No personal data found in the matching responses")
+		return
+	}
+	if len(account) > 0 {
+		var values []string
+		for key := range account {
+			_, value, _ := strings.Cut(key, "\x00")
+			values = append(values, value)
+		}
+		sort.Strings(values)
+		pterm.Info.Printf("Account: %s\n", output.SanitizeText(truncateCell(strings.Join(values, ", "), 120)))
+	} else {
+		pterm.Info.Println("Account identity unknown: pass --account to tell own data from other users'")
+	}
+
+	pterm.DefaultSection.Printf("Personal data in responses (%d endpoints)\n", len(endpoints))
+	for _, d := range domains {
+		fmt.Printf("%s  %s\n", pterm.Bold.Sprint(output.SanitizeText(d.Domain)), pterm.FgGray.Sprint(formatPIIKinds(d.Kinds)))
+		for _, e := range endpoints {
+			if e.Domain != d.Domain {
+				continue
+			}
+			marker, exposure := " ", pterm.FgGray.Sprintf("%-8s", e.Exposure)
+			switch e.Exposure {
+			case "others":
+				marker, exposure = pterm.FgRed.Sprint("★"), pterm.FgRed.Sprintf("%-8s", e.Exposure)
+			case "account":
+				exposure = pterm.FgGreen.Sprintf("%-8s", e.Exposure)
+			}
+			fmt.Printf("  %s %s %-6s %s  %s %s\n", marker, exposure, e.Method, output.SanitizeText(e.Path),
+				formatPIIKinds(e.Kinds), pterm.FgGray.Sprint("["+strings.Join(e.IDs, ", ")+"]"))
+			var others []string
+			for _, v := range e.Values {
+				if v.Owner == "other" {
+					others = append(others, v.Value)
+				}
+			}
+			if len(others) > 0 {
+				sample := others
+				if len(sample) > 5 {
+					sample = append(sample[:5:5], fmt.Sprintf("+%d more", len(others)-5))
+				}
+				fmt.Printf("             %s\n", pterm.FgGray.Sprint("other: "+output.SanitizeText(strings.Join(sample, ", "))))
+			}
+		}
+		fmt.Println()
+	}
+	pterm.Info.Println("Use 'rep body <id>' to see a response, -o json for every value")
+}
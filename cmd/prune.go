@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneOlderThan string
+	pruneKeepLast  int
+	pruneMaxSize   string
+	pruneDryRun    bool
+	pruneArchive   bool
+)
+
+// pruneCandidate is a saved session with its on-disk size and removal reason
+type pruneCandidate struct {
+	Session store.Session
+	Size    int
+	Reason  string
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old saved sessions according to retention rules",
+	Long: `Delete saved sessions from store.json according to retention rules.
+
+Rules (a session is removed if ANY rule selects it):
+  --older-than <age>   Saved more than <age> ago (30d, 12h, 90m)
+  --keep-last <n>      Everything except the n most recent sessions
+  --max-size <size>    Oldest sessions until the rest fit in <size> (500MB, 1g)
+
+Sessions are ordered by save time. Use --dry-run to see what would be
+removed and how much disk would be reclaimed. With --archive, selected
+sessions are moved to compressed archive files ('rep archive') instead of
+being deleted.
+
+Examples:
+  rep prune --older-than 30d --dry-run
+  rep prune --keep-last 20
+  rep prune --max-size 500MB
+  rep prune --older-than 30d --archive     Keep history, shrink store.json
+  rep prune --older-than 30d --keep-last 20 --max-size 500MB -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if pruneOlderThan == "" && pruneKeepLast <= 0 && pruneMaxSize == "" {
+			return fmt.Errorf("specify at least one rule: --older-than, --keep-last or --max-size")
+		}
+
+		var maxAge time.Duration
+		if pruneOlderThan != "" {
+			d, err := store.ParseAge(pruneOlderThan)
+			if err != nil {
+				return fmt.Errorf("invalid --older-than: %s (use 30d, 12h, 90m)", pruneOlderThan)
+			}
+			maxAge = d
+		}
+		maxSize := 0
+		if pruneMaxSize != "" {
+			n, err := parseByteSize(pruneMaxSize)
+			if err != nil {
+				return fmt.Errorf("invalid --max-size: %w", err)
+			}
+			maxSize = n
+		}
+
+		s, err := store.Get()
+		if err != nil {
+			return fmt.Errorf("failed to load store: %w", err)
+		}
+
+		candidates := selectPruneSessions(s.ListSessions(), maxAge, pruneKeepLast, maxSize, time.Now())
+
+		var ids []string
+		reclaimed := 0
+		for _, c := range candidates {
+			ids = append(ids, c.Session.ID)
+			reclaimed += c.Size
+		}
+
+		if !pruneDryRun && len(ids) > 0 {
+			if pruneArchive {
+				if _, err := archiveSessions(s, ids); err != nil {
+					return err
+				}
+			} else {
+				s.DeleteSessions(ids...)
+				if err := s.Save(); err != nil {
+					return fmt.Errorf("failed to save store: %w", err)
+				}
+			}
+		}
+
+		storeSize := int64(0)
+		if path, err := store.GetStoreFilePath(); err == nil {
+			if info, err := os.Stat(path); err == nil {
+				storeSize = info.Size()
+			}
+		}
+
+		if getOutputMode() == "json" {
+			removed := make([]map[string]interface{}, len(candidates))
+			for i, c := range candidates {
+				removed[i] = map[string]interface{}{
+					"id":       c.Session.ID,
+					"note":     c.Session.Note,
+					"requests": len(c.Session.Requests),
+					"bytes":    c.Size,
+					"time":     time.UnixMilli(c.Session.Timestamp).Format(time.RFC3339),
+					"reason":   c.Reason,
+				}
+			}
+			result := map[string]interface{}{
+				"removed":         removed,
+				"count":           len(candidates),
+				"reclaimed_bytes": reclaimed,
+				"remaining":       s.SessionCount(),
+				"store_bytes":     storeSize,
+				"dry_run":         pruneDryRun,
+				"archived":        pruneArchive,
+			}
+			out, _ := sonic.MarshalIndent(result, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(candidates) == 0 {
+			pterm.Info.Println("Nothing to prune")
+			return nil
+		}
+
+		tableData := pterm.TableData{{"ID", "Requests", "Size", "Saved At", "Reason"}}
+		for _, c := range candidates {
+			tableData = append(tableData, []string{
+				c.Session.ID,
+				fmt.Sprintf("%d", len(c.Session.Requests)),
+				formatByteSize(int64(c.Size)),
+				time.UnixMilli(c.Session.Timestamp).Format("2006-01-02 15:04:05"),
+				c.Reason,
+			})
+		}
+		pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+		fmt.Println()
+
+		if pruneArchive {
+			verb := "Archived"
+			if pruneDryRun {
+				verb = "Would archive"
+			}
+			pterm.Info.Printf("%s %d sessions (~%s uncompressed)\n", verb, len(candidates), formatByteSize(int64(reclaimed)))
+			return nil
+		}
+		if pruneDryRun {
+			pterm.Info.Printf("Would remove %d sessions, reclaiming ~%s (dry run)\n", len(candidates), formatByteSize(int64(reclaimed)))
+			return nil
+		}
+		pterm.Success.Printf("Removed %d sessions, reclaimed ~%s (store now %s)\n", len(candidates), formatByteSize(int64(reclaimed)), formatByteSize(storeSize))
+		return nil
+	},
+}
+
+// selectPruneSessions applies the retention rules and returns the sessions to
+// remove, oldest first
+func selectPruneSessions(sessions []store.Session, maxAge time.Duration, keepLast int, maxSize int, now time.Time) []pruneCandidate {
+	// Newest first by save time
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Timestamp > sessions[j].Timestamp
+	})
+
+	reasons := make([]string, len(sessions))
+	sizes := make([]int, len(sessions))
+	for i, sess := range sessions {
+		if data, err := sonic.Marshal(sess); err == nil {
+			sizes[i] = len(data)
+		}
+		if maxAge > 0 && now.Sub(time.UnixMilli(sess.Timestamp)) > maxAge {
+			reasons[i] = "older than " + pruneOlderThan
+		} else if keepLast > 0 && i >= keepLast {
+			reasons[i] = fmt.Sprintf("beyond last %d", keepLast)
+		}
+	}
+
+	if maxSize > 0 {
+		// Keep newest sessions (not already removed) while they fit
+		total := 0
+		for i := range sessions {
+			if reasons[i] != "" {
+				continue
+			}
+			if total+sizes[i] > maxSize {
+				reasons[i] = "over " + pruneMaxSize
+				continue
+			}
+			total += sizes[i]
+		}
+	}
+
+	var result []pruneCandidate
+	for i := len(sessions) - 1; i >= 0; i-- {
+		if reasons[i] != "" {
+			result = append(result, pruneCandidate{Session: sessions[i], Size: sizes[i], Reason: reasons[i]})
+		}
+	}
+	return result
+}
+
+// formatByteSize renders a byte count as B/KB/MB/GB
+func formatByteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().StringVar(&pruneOlderThan, "older-than", "", "Remove sessions saved more than this long ago (30d, 12h)")
+	pruneCmd.Flags().IntVar(&pruneKeepLast, "keep-last", 0, "Remove all but the N most recent sessions")
+	pruneCmd.Flags().StringVar(&pruneMaxSize, "max-size", "", "Remove oldest sessions until the rest fit in this size (500MB, 1g)")
+	pruneCmd.Flags().BoolVar(&pruneArchive, "archive", false, "Archive selected sessions instead of deleting them")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Show what would be removed without deleting")
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http/httptrace"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/replay"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	raceSaved    string
+	raceUseVars  bool
+	raceInsecure bool
+	raceCount    int
+	raceParallel int
+)
+
+// maxRaceCount bounds how many copies one run may send
+const maxRaceCount = 1000
+
+// RaceResponse is the outcome of one copy of the raced request
+type RaceResponse struct {
+	Index     int     `json:"index"`
+	Batch     int     `json:"batch"`
+	Status    int     `json:"status,omitempty"`
+	Size      int64   `json:"size,omitempty"`
+	BodyHash  string  `json:"body_hash,omitempty"` // First 12 hex chars of the body's sha256
+	LatencyMs int64   `json:"latency_ms"`          // From the request written to the response read
+	OffsetMs  float64 `json:"offset_ms"`           // When the request was written, relative to the batch's first
+	Error     string  `json:"error,omitempty"`
+}
+
+// RaceOutcome groups responses with the same status and body
+type RaceOutcome struct {
+	Status   int    `json:"status"`
+	BodyHash string `json:"body_hash"`
+	Count    int    `json:"count"`
+	First    int    `json:"first"` // Index of the first response with this outcome
+}
+
+var raceCmd = &cobra.Command{
+	Use:   "race <request-id>",
+	Short: "Fire copies of a request simultaneously to test race conditions",
+	Long: `Send N copies of a captured request at the same moment through the replay
+engine and report how each one fared: status, body hash, size and latency.
+
+Copies are sent in batches of --parallel. Before a batch starts, every
+copy gets its own HTTP/1.1 connection, already through DNS, TCP and TLS;
+then each copy waits in its own goroutine on a shared barrier and all are
+released together, so releasing a copy only writes its request. Responses
+are grouped by status and body hash: a limit-once action (redeeming a
+coupon, a transfer, an invite) that returns more than one success is a
+race condition.
+
+The offset column shows how long after the first copy of its batch each
+copy finished writing its request; a large spread means the race window
+was probably missed, so retry with a smaller --parallel. Through a proxy
+(HTTPS_PROXY, HTTP_PROXY) connections can't be opened ahead, so copies
+connect after the release and spread wider.
+
+Examples:
+  rep race h_abc123                       20 copies at once
+  rep race h_abc123 -n 50 --parallel 25   Two batches of 25
+  rep race h_abc123 -n 10 --use-vars      Resolve auth from the env file
+  rep race h_abc123 -o json               Per-response results for agents`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		requestID := args[0]
+		if raceCount < 1 || raceCount > maxRaceCount {
+			return fmt.Errorf("-n must be between 1 and %d", maxRaceCount)
+		}
+		parallel := raceParallel
+		if parallel <= 0 || parallel > raceCount {
+			parallel = raceCount
+		}
+
+		req, err := lookupRequest(requestID, raceSaved)
+		if err != nil {
+			return err
+		}
+		if req == nil {
+			return notFoundError("request not found: %s", requestID).withHint("Use 'rep list' to see available request IDs")
+		}
+
+		sendReq, missing := resolveSendRequest(req, raceUseVars)
+		if err := warnMissingVars(req, missing); err != nil {
+			return err
+		}
+
+		opts := replay.Options{Insecure: raceInsecure}
+		responses := make([]RaceResponse, 0, raceCount)
+		for batch, sent := 0, 0; sent < raceCount; batch++ {
+			size := parallel
+			if raceCount-sent < size {
+				size = raceCount - sent
+			}
+			responses = append(responses, runRaceBatch(sendReq, opts, batch, sent, size)...)
+			sent += size
+		}
+		outcomes := raceOutcomes(responses)
+
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"id":        req.ID,
+				"count":     raceCount,
+				"parallel":  parallel,
+				"spread_ms": raceSpread(responses),
+				"outcomes":  outcomes,
+				"responses": responses,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		printRace(req, parallel, responses, outcomes)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(raceCmd)
+	raceCmd.Flags().StringVar(&raceSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	raceCmd.Flags().BoolVar(&raceUseVars, "use-vars", false, "Resolve auth from environment / ~/.rep/auth-<domain>.env")
+	raceCmd.Flags().BoolVarP(&raceInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+	raceCmd.Flags().IntVarP(&raceCount, "count", "n", 20, "Number of copies to send")
+	raceCmd.Flags().IntVar(&raceParallel, "parallel", 0, "Copies released together per batch (default: all)")
+}
+
+// runRaceBatch opens a connection for each of size copies of req, then
+// releases them from a shared barrier
+func runRaceBatch(req *store.Request, opts replay.Options, batch, first, size int) []RaceResponse {
+	opts.Transport = replay.NewTransport(opts)
+	defer opts.Transport.CloseIdleConnections()
+	if err := replay.Preconnect(context.Background(), opts.Transport, req.URL, size); err != nil && getOutputMode() != "json" {
+		pterm.Warning.Printf("Batch %d: connections not opened ahead (%v); copies connect after the release\n", batch+1, err)
+	}
+
+	results := make([]RaceResponse, size)
+	wrote := make([]time.Time, size)
+	release := make(chan struct{})
+	var ready, done sync.WaitGroup
+	ready.Add(size)
+	done.Add(size)
+
+	for i := 0; i < size; i++ {
+		go func(i int) {
+			defer done.Done()
+			copyReq := *req
+			copyOpts := opts
+			copyOpts.Trace = &httptrace.ClientTrace{
+				WroteRequest: func(httptrace.WroteRequestInfo) { wrote[i] = time.Now() },
+			}
+			ready.Done()
+			<-release
+			res := RaceResponse{Index: first + i + 1, Batch: batch + 1}
+			result, err := replay.Send(context.Background(), &copyReq, copyOpts)
+			if wrote[i].IsZero() {
+				wrote[i] = time.Now() // Failed before the request was written
+			}
+			res.LatencyMs = time.Since(wrote[i]).Milliseconds()
+			if err != nil {
+				res.Error = err.Error()
+			} else {
+				sum := sha256.Sum256([]byte(result.Body))
+				res.Status = result.Status
+				res.Size = result.Size
+				res.BodyHash = hex.EncodeToString(sum[:])[:12]
+			}
+			results[i] = res
+		}(i)
+	}
+	ready.Wait()
+	close(release)
+	done.Wait()
+
+	earliest := wrote[0]
+	for _, t := range wrote {
+		if t.Before(earliest) {
+			earliest = t
+		}
+	}
+	for i := range results {
+		results[i].OffsetMs = float64(wrote[i].Sub(earliest).Microseconds()) / 1000
+	}
+	return results
+}
+
+// raceOutcomes groups responses by status and body hash, most common first
+func raceOutcomes(responses []RaceResponse) []RaceOutcome {
+	index := make(map[string]int)
+	var outcomes []RaceOutcome
+	for _, r := range responses {
+		if r.Error != "" {
+			continue
+		}
+		key := fmt.Sprintf("%d|%s", r.Status, r.BodyHash)
+		if i, ok := index[key]; ok {
+			outcomes[i].Count++
+			continue
+		}
+		index[key] = len(outcomes)
+		outcomes = append(outcomes, RaceOutcome{Status: r.Status, BodyHash: r.BodyHash, Count: 1, First: r.Index})
+	}
+	sort.SliceStable(outcomes, func(i, j int) bool { return outcomes[i].Count > outcomes[j].Count })
+	return outcomes
+}
+
+// raceSpread is the widest send spread of any batch, in milliseconds
+func raceSpread(responses []RaceResponse) float64 {
+	spread := 0.0
+	for _, r := range responses {
+		if r.OffsetMs > spread {
+			spread = r.OffsetMs
+		}
+	}
+	return spread
+}
+
+func printRace(req *store.Request, parallel int, responses []RaceResponse, outcomes []RaceOutcome) {
+	pterm.DefaultSection.Printf("Race on %s\n", req.ID)
+	fmt.Printf("  %s %s\n", req.Method, output.SanitizeText(req.URL))
+	fmt.Printf("  %d copies, %d per batch, send spread %.1fms\n\n", len(responses), parallel, raceSpread(responses))
+
+	fmt.Printf("  %4s %5s %6s %9s  %-12s %8s %9s\n", "#", "BATCH", "STATUS", "SIZE", "BODY", "LATENCY", "OFFSET")
+	failed := 0
+	for _, r := range responses {
+		if r.Error != "" {
+			failed++
+			fmt.Printf("  %4d %5d %6s  %s\n", r.Index, r.Batch, "ERR", r.Error)
+			continue
+		}
+		fmt.Printf("  %4d %5d %6s %9s  %-12s %6dms %7.1fms\n", r.Index, r.Batch,
+			colorStatus(r.Status, fmt.Sprintf("%6d", r.Status)), output.FormatBodySize(int(r.Size)), r.BodyHash, r.LatencyMs, r.OffsetMs)
+	}
+
+	pterm.DefaultSection.Println("Outcomes")
+	successes := 0
+	for _, o := range outcomes {
+		fmt.Printf("  %s  body %s  ×%d  (first: #%d)\n", colorStatus(o.Status, fmt.Sprint(o.Status)), o.BodyHash, o.Count, o.First)
+		if o.Status >= 200 && o.Status < 300 {
+			successes += o.Count
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("  %s\n", pterm.FgRed.Sprintf("%d request(s) failed", failed))
+	}
+	fmt.Println()
+	switch {
+	case len(outcomes) > 1 && successes > 1:
+		pterm.Warning.Printf("%d copies succeeded with %d distinct outcomes: check whether the action applied more than once\n", successes, len(outcomes))
+	case len(outcomes) > 1:
+		pterm.Info.Printf("%d distinct outcomes across copies\n", len(outcomes))
+	default:
+		pterm.Info.Println("Every copy got the same response")
+	}
+}
@@ -0,0 +1,413 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/repplus/rep-cli/internal/waf"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ratelimitFilter requestFilterFlags
+	ratelimitSaved  string
+)
+
+// DomainThrottling is how one domain limits and blocks requests
+type DomainThrottling struct {
+	Domain          string         `json:"domain"`
+	Requests        int            `json:"requests"`
+	Throttled       int            `json:"throttled"`             // 429 responses
+	Forbidden       int            `json:"forbidden"`             // 403 responses
+	RetryAfter      []int          `json:"retry_after,omitempty"` // Distinct Retry-After values in seconds
+	LimitHeaders    []string       `json:"limit_headers,omitempty"`
+	Limit           string         `json:"limit,omitempty"`
+	LowestRemaining *int           `json:"lowest_remaining,omitempty"`
+	Reset           string         `json:"reset,omitempty"`
+	WindowSeconds   int            `json:"window_seconds,omitempty"` // From RateLimit-Policy
+	PeakPerMinute   int            `json:"peak_per_minute"`
+	FirstThrottle   *ThrottleEvent `json:"first_throttle,omitempty"`
+	WAFs            []WAFSighting  `json:"wafs,omitempty"`
+	DelayMs         int            `json:"suggested_delay_ms,omitempty"`
+	Advice          []string       `json:"advice,omitempty"`
+}
+
+// ThrottleEvent is the first 429 and the traffic that triggered it
+type ThrottleEvent struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	AfterRequests int    `json:"after_requests"` // Requests to the domain in the minute before
+}
+
+// WAFSighting summarizes one WAF in front of a domain
+type WAFSighting struct {
+	Name       string   `json:"name"`
+	Responses  int      `json:"responses"`
+	Blocked    int      `json:"blocked"`
+	Evidence   string   `json:"evidence"`
+	BlockedIDs []string `json:"blocked_ids,omitempty"`
+}
+
+var (
+	rateLimitHeaderPattern = regexp.MustCompile(`(?i)^(?:x-)?rate-?limit(?:-|$)`)
+	policyWindowPattern    = regexp.MustCompile(`(?i)\bw=(\d+)`)
+)
+
+// maxBlockedIDs caps the request IDs listed per WAF
+const maxBlockedIDs = 5
+
+var ratelimitCmd = &cobra.Command{
+	Use:   "ratelimit",
+	Short: "Summarize rate limiting and WAF blocking per domain",
+	Long: `Summarize how each domain throttles and blocks traffic, to tune replay and
+fuzzing before a target starts dropping requests.
+
+For each domain rep looks at:
+  - 429 and 403 responses, and the Retry-After they ask for
+  - X-RateLimit-*, X-Rate-Limit-* and RateLimit-* headers (limit, lowest
+    remaining, reset, policy window)
+  - The peak request rate captured, and how many requests in the preceding
+    minute triggered the first 429
+  - WAF and bot manager fingerprints (Cloudflare, Akamai, Imperva, AWS WAF,
+    Sucuri, F5, ModSecurity, DataDome, PerimeterX, Azure Front Door), and
+    which responses were block pages or challenges
+
+A suggested delay between requests is derived from the limit policy or the
+observed trigger rate. Domains with no throttling signals are only counted.
+
+Takes the same filter flags as 'rep list' (primary domains only by default).
+
+Examples:
+  rep ratelimit                     Throttling on primary domains
+  rep ratelimit -d api.target.com   One domain
+  rep ratelimit --primary=false     Across all captured domains
+  rep ratelimit --saved latest -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := ratelimitFilter.options()
+		if err != nil {
+			return err
+		}
+		requests, err := filterSource(ratelimitSaved, opts)
+		if err != nil || requests == nil {
+			return err
+		}
+
+		domains, quiet := analyzeThrottling(requests)
+
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"domains":     domains,
+				"unthrottled": quiet,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		printThrottling(domains, quiet)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ratelimitCmd)
+	ratelimitFilter.register(ratelimitCmd)
+	ratelimitCmd.Flags().StringVar(&ratelimitSaved, "saved", "", "Read from saved session (ID, prefix, or 'latest')")
+}
+
+// analyzeThrottling groups requests by domain and returns the domains with
+// throttling signals, plus the names of those without
+func analyzeThrottling(requests []store.Request) ([]*DomainThrottling, []string) {
+	byDomain := make(map[string][]*store.Request)
+	var order []string
+	for i := range requests {
+		host := hostFromURL(requests[i].URL)
+		if host == "" {
+			continue
+		}
+		if _, ok := byDomain[host]; !ok {
+			order = append(order, host)
+		}
+		byDomain[host] = append(byDomain[host], &requests[i])
+	}
+
+	domains := []*DomainThrottling{}
+	quiet := []string{}
+	for _, host := range order {
+		d := analyzeDomainThrottling(host, byDomain[host])
+		if d.Throttled == 0 && d.Forbidden == 0 && len(d.LimitHeaders) == 0 && len(d.WAFs) == 0 {
+			quiet = append(quiet, host)
+			continue
+		}
+		domains = append(domains, d)
+	}
+	sort.SliceStable(domains, func(i, j int) bool {
+		if domains[i].Throttled != domains[j].Throttled {
+			return domains[i].Throttled > domains[j].Throttled
+		}
+		return blockedCount(domains[i]) > blockedCount(domains[j])
+	})
+	sort.Strings(quiet)
+	return domains, quiet
+}
+
+func analyzeDomainThrottling(host string, reqs []*store.Request) *DomainThrottling {
+	sort.SliceStable(reqs, func(i, j int) bool { return reqs[i].Timestamp < reqs[j].Timestamp })
+	d := &DomainThrottling{Domain: host, Requests: len(reqs)}
+	wafs := make(map[string]*WAFSighting)
+	var wafOrder []string
+	retryAfter := make(map[int]bool)
+	headerNames := make(map[string]bool)
+
+	for i, req := range reqs {
+		if req.Response == nil {
+			continue
+		}
+		resp := req.Response
+		switch resp.Status {
+		case 429:
+			d.Throttled++
+			if d.FirstThrottle == nil {
+				d.FirstThrottle = &ThrottleEvent{ID: req.ID, URL: req.URL, AfterRequests: requestsInWindow(reqs[:i], req.Timestamp, time.Minute)}
+			}
+		case 403:
+			d.Forbidden++
+		}
+		if seconds, ok := parseRetryAfter(req); ok {
+			retryAfter[seconds] = true
+		}
+
+		for name, values := range resp.Headers {
+			if !rateLimitHeaderPattern.MatchString(name) || len(values) == 0 {
+				continue
+			}
+			lower := strings.ToLower(name)
+			headerNames[lower] = true
+			value := strings.TrimSpace(values[0])
+			switch {
+			case strings.HasSuffix(lower, "remaining"):
+				if n, err := strconv.Atoi(value); err == nil && (d.LowestRemaining == nil || n < *d.LowestRemaining) {
+					d.LowestRemaining = &n
+				}
+			case strings.HasSuffix(lower, "reset"):
+				d.Reset = value
+			case strings.HasSuffix(lower, "policy"):
+				if m := policyWindowPattern.FindStringSubmatch(value); m != nil {
+					d.WindowSeconds, _ = strconv.Atoi(m[1])
+				}
+				if d.Limit == "" {
+					d.Limit, _, _ = strings.Cut(value, ";")
+				}
+			case strings.HasSuffix(lower, "limit"):
+				d.Limit = value
+			}
+		}
+
+		if resp.Status >= 400 || resp.Status == 202 {
+		}
+		for _, m := range waf.Detect(req) {
+			s, ok := wafs[m.Name]
+			if !ok {
+				s = &WAFSighting{Name: m.Name, Evidence: m.Evidence}
+				wafs[m.Name] = s
+				wafOrder = append(wafOrder, m.Name)
+			}
+			s.Responses++
+			if m.Blocked {
+				if s.Blocked == 0 {
+					s.Evidence = m.Evidence // A block marker says more than a header
+				}
+				s.Blocked++
+				if len(s.BlockedIDs) < maxBlockedIDs {
+					s.BlockedIDs = append(s.BlockedIDs, req.ID)
+				}
+			}
+		}
+	}
+
+	for seconds := range retryAfter {
+		d.RetryAfter = append(d.RetryAfter, seconds)
+	}
+	sort.Ints(d.RetryAfter)
+	for name := range headerNames {
+		d.LimitHeaders = append(d.LimitHeaders, name)
+	}
+	sort.Strings(d.LimitHeaders)
+	for _, name := range wafOrder {
+		d.WAFs = append(d.WAFs, *wafs[name])
+	}
+	for end := range reqs {
+		if n := requestsInWindow(reqs[:end], reqs[end].Timestamp, time.Minute) + 1; n > d.PeakPerMinute {
+			d.PeakPerMinute = n
+		}
+	}
+	d.DelayMs, d.Advice = throttlingAdvice(d)
+	return d
+}
+
+// requestsInWindow counts the (timestamp-sorted) requests in the window
+// before at
+func requestsInWindow(before []*store.Request, at int64, window time.Duration) int {
+	n := 0
+	for i := len(before) - 1; i >= 0; i-- {
+		if at-before[i].Timestamp > window.Milliseconds() {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// parseRetryAfter reads Retry-After as seconds, or as an HTTP date relative
+// to the response Date (or capture time)
+func parseRetryAfter(req *store.Request) (int, bool) {
+	value := strings.TrimSpace(store.HeaderFirst(req.Response.Headers, "retry-after"))
+	if value == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+		return n, true
+	}
+	until, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	now := time.UnixMilli(req.Timestamp)
+	if date, err := http.ParseTime(store.HeaderFirst(req.Response.Headers, "date")); err == nil {
+		now = date
+	}
+	seconds := int(until.Sub(now).Seconds())
+	if seconds < 0 {
+		seconds = 0
+	}
+	return seconds, true
+}
+
+// throttlingAdvice derives a delay between requests and tuning hints
+func throttlingAdvice(d *DomainThrottling) (int, []string) {
+	var advice []string
+	delay := 0
+	limit, limitErr := strconv.Atoi(strings.TrimSpace(d.Limit))
+
+	switch {
+	case limitErr == nil && limit > 0 && d.WindowSeconds > 0:
+		delay = d.WindowSeconds * 1000 / limit
+		advice = append(advice, fmt.Sprintf("Policy allows %d requests per %ds: keep to one every %s", limit, d.WindowSeconds, formatDelay(delay)))
+	case d.FirstThrottle != nil && d.FirstThrottle.AfterRequests > 1:
+		safe := d.FirstThrottle.AfterRequests - 1
+		delay = 60000 / safe
+		advice = append(advice, fmt.Sprintf("First 429 came after %d requests in a minute: stay under %d/min (one every %s)", d.FirstThrottle.AfterRequests, safe, formatDelay(delay)))
+	case d.Throttled > 0:
+		delay = 1000
+		advice = append(advice, "Throttled without a clear trigger rate: start at one request per second")
+	}
+	if len(d.RetryAfter) > 0 {
+		longest := d.RetryAfter[len(d.RetryAfter)-1]
+		advice = append(advice, fmt.Sprintf("Wait Retry-After (up to %ds) after a 429 before resuming", longest))
+	}
+	if d.LowestRemaining != nil && *d.LowestRemaining == 0 && d.Throttled == 0 {
+		advice = append(advice, "Quota reached zero during capture: the next burst will be throttled")
+	}
+	for _, w := range d.WAFs {
+		if w.Blocked > 0 {
+			advice = append(advice, fmt.Sprintf("%s blocked %d response(s): replay with browser headers and cookies, avoid bursts and obvious payloads", w.Name, w.Blocked))
+			if delay < 1000 {
+				delay = 1000
+			}
+		}
+	}
+	return delay, advice
+}
+
+func formatDelay(ms int) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%.1fs", float64(ms)/1000)
+	}
+	return fmt.Sprintf("%dms", ms)
+}
+
+func blockedCount(d *DomainThrottling) int {
+	n := 0
+	for _, w := range d.WAFs {
+		n += w.Blocked
+	}
+	return n
+}
+
+func printThrottling(domains []*DomainThrottling, quiet []string) {
+	if len(domains) == 0 {
+		pterm.Success.Printf("No rate limiting or WAF signals across %d domain(s)\n", len(quiet))
+		return
+	}
+	for _, d := range domains {
+		fmt.Printf("%s  %s\n", pterm.Bold.Sprint(d.Domain), pterm.FgGray.Sprintf("%d requests, peak %d/min", d.Requests, d.PeakPerMinute))
+		var counts []string
+		if d.Throttled > 0 {
+			counts = append(counts, colorStatus(429, fmt.Sprintf("429×%d", d.Throttled)))
+		}
+		if d.Forbidden > 0 {
+			counts = append(counts, colorStatus(403, fmt.Sprintf("403×%d", d.Forbidden)))
+		}
+		if len(counts) > 0 {
+			fmt.Printf("  Responses: %s\n", strings.Join(counts, "  "))
+		}
+		if d.FirstThrottle != nil {
+			fmt.Printf("  First 429: [%s] after %d requests in the preceding minute\n", d.FirstThrottle.ID, d.FirstThrottle.AfterRequests)
+		}
+		if len(d.LimitHeaders) > 0 || len(d.RetryAfter) > 0 {
+			var parts []string
+			if d.Limit != "" {
+				parts = append(parts, "limit "+d.Limit)
+			}
+			if d.WindowSeconds > 0 {
+				parts = append(parts, fmt.Sprintf("window %ds", d.WindowSeconds))
+			}
+			if d.LowestRemaining != nil {
+				parts = append(parts, fmt.Sprintf("lowest remaining %d", *d.LowestRemaining))
+			}
+			if d.Reset != "" {
+				parts = append(parts, "reset "+d.Reset)
+			}
+			if len(d.RetryAfter) > 0 {
+				values := make([]string, len(d.RetryAfter))
+				for i, s := range d.RetryAfter {
+					values[i] = fmt.Sprintf("%ds", s)
+				}
+				parts = append(parts, "Retry-After "+strings.Join(values, "/"))
+			}
+			fmt.Printf("  Limits: %s\n", strings.Join(parts, ", "))
+			if len(d.LimitHeaders) > 0 {
+				fmt.Printf("  %s\n", pterm.FgGray.Sprint("headers: "+strings.Join(d.LimitHeaders, ", ")))
+			}
+		}
+		for _, w := range d.WAFs {
+			state := pterm.FgGray.Sprint("present")
+			if w.Blocked > 0 {
+				state = pterm.FgRed.Sprintf("blocked %d", w.Blocked)
+			}
+			fmt.Printf("  WAF: %s (%s, %d responses) %s\n", pterm.FgCyan.Sprint(w.Name), state, w.Responses, pterm.FgGray.Sprint(output.SanitizeText(w.Evidence)))
+			if len(w.BlockedIDs) > 0 {
+				fmt.Printf("       blocked: %s\n", strings.Join(w.BlockedIDs, " "))
+			}
+		}
+		for _, a := range d.Advice {
+			fmt.Printf("  → %s\n", a)
+		}
+		if d.DelayMs > 0 {
+			seconds := strconv.FormatFloat(float64(d.DelayMs)/1000, 'f', -1, 64)
+			fmt.Printf("  Tuning: ≥%s between requests (ffuf -p %s, sqlmap --delay=%s)\n", formatDelay(d.DelayMs), seconds, seconds)
+		}
+		fmt.Println()
+	}
+	if len(quiet) > 0 {
+		fmt.Printf("[%d other domain(s) showed no throttling]\n", len(quiet))
+	}
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rawSaved    string
+	rawResponse bool
+	rawOut      string
+	rawUseVars  bool
+)
+
+// rawFramingHeaders are recomputed for the exported message: the captured
+// values describe the browser's wire encoding, not the decoded body rep holds
+var rawFramingHeaders = map[string]bool{
+	"host":              true,
+	"content-length":    true,
+	"content-encoding":  true,
+	"transfer-encoding": true,
+}
+
+var rawCmd = &cobra.Command{
+	Use:   "raw <request-id>",
+	Short: "Print a request as raw HTTP/1.1 (Burp Repeater, sqlmap -r)",
+	Long: `Print a captured request in HTTP/1.1 wire format: request line, Host,
+headers and body, with CRLF line endings. Paste it into Burp Repeater or
+Turbo Intruder, or save it for sqlmap -r and ffuf -request.
+
+All captured headers are kept (sorted by name); HTTP/2 pseudo-headers are
+dropped and Content-Length is recomputed from the body. With --response,
+the captured response follows the request, separated by a blank line; its
+body is the decoded one, so Content-Encoding and Transfer-Encoding are
+dropped as well.
+
+Examples:
+  rep raw h_abc123                          Print the raw request
+  rep raw h_abc123 --out req.txt            Save for sqlmap -r req.txt
+  rep raw h_abc123 --response               Request and response
+  rep raw h_abc123 --use-vars --out req.txt Resolve auth from the env file`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := lookupRequest(args[0], rawSaved)
+		if err != nil {
+			return err
+		}
+		if req == nil {
+			return notFoundError("request not found: %s", args[0]).withHint("Use 'rep list' to see available request IDs")
+		}
+
+		sendReq, missing := resolveReplayRequest(req, rawUseVars)
+		if err := warnMissingVars(req, missing); err != nil {
+			return err
+		}
+		request, err := formatRawRequest(sendReq.Method, sendReq.URL, rawHeaders(sendReq.Headers), sendReq.Body)
+		if err != nil {
+			return err
+		}
+		response := ""
+		if rawResponse {
+			if req.Response == nil {
+				pterm.Warning.Printf("%s has no captured response\n", req.ID)
+			} else {
+				response = formatRawResponse(req)
+			}
+		}
+
+		if getOutputMode() == "json" {
+			payload := map[string]interface{}{"id": req.ID, "request": request}
+			if response != "" {
+				payload["response"] = response
+			}
+			if rawOut != "" {
+				payload["path"] = rawOut
+				if err := os.WriteFile(rawOut, []byte(request+rawSeparator(response)), 0644); err != nil {
+					return fmt.Errorf("failed to write %s: %w", rawOut, err)
+				}
+			}
+			out, _ := sonic.MarshalIndent(payload, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		content := request + rawSeparator(response)
+		if rawOut == "" {
+			fmt.Print(content)
+			return nil
+		}
+		if err := os.WriteFile(rawOut, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", rawOut, err)
+		}
+		pterm.Success.Printf("Wrote %s (%d bytes)\n", rawOut, len(content))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rawCmd)
+	rawCmd.Flags().StringVar(&rawSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	rawCmd.Flags().BoolVar(&rawResponse, "response", false, "Append the captured response")
+	rawCmd.Flags().StringVar(&rawOut, "out", "", "Write to file instead of stdout")
+	rawCmd.Flags().BoolVar(&rawUseVars, "use-vars", false, "Resolve auth from environment / ~/.rep/auth-<domain>.env")
+}
+
+// rawSeparator puts a blank line between the request and a response
+func rawSeparator(response string) string {
+	if response == "" {
+		return ""
+	}
+	return "\r\n\r\n" + response
+}
+
+// rawHeaders returns every header except pseudo-headers and the framing
+// headers formatRawRequest writes itself, sorted by name
+func rawHeaders(headers store.HeaderMap) []replayHeader {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		if strings.HasPrefix(key, ":") || rawFramingHeaders[strings.ToLower(key)] {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var out []replayHeader
+	for _, key := range keys {
+		for _, value := range headers[key] {
+			out = append(out, replayHeader{Name: key, Value: value})
+		}
+	}
+	return out
+}
+
+// formatRawRequest renders an HTTP/1.1 request with CRLF line endings: the
+// request line, Host, headers, Content-Length when there is a body, and the
+// body
+func formatRawRequest(method, rawURL string, headers []replayHeader, body string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("invalid URL: %s", rawURL)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s HTTP/1.1\r\n", method, parsed.RequestURI())
+	fmt.Fprintf(&b, "Host: %s\r\n", parsed.Host)
+	for _, h := range headers {
+		if strings.HasPrefix(h.Name, ":") || rawFramingHeaders[strings.ToLower(h.Name)] {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\r\n", h.Name, h.Value)
+	}
+	if body != "" {
+		fmt.Fprintf(&b, "Content-Length: %d\r\n", len(body))
+	}
+	b.WriteString("\r\n")
+	b.WriteString(body)
+	return b.String(), nil
+}
+
+// formatRawResponse renders the captured response as HTTP/1.1
+func formatRawResponse(req *store.Request) string {
+	body := store.ResponseBodyText(req)
+	var b strings.Builder
+	fmt.Fprintf(&b, "HTTP/1.1 %d %s\r\n", req.Response.Status, http.StatusText(req.Response.Status))
+	for _, h := range rawHeaders(req.Response.Headers) {
+		fmt.Fprintf(&b, "%s: %s\r\n", h.Name, h.Value)
+	}
+	b.WriteString("Content-Length: " + strconv.Itoa(len(body)) + "\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(body)
+	return b.String()
+}
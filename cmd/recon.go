@@ -6,7 +6,6 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/bytedance/sonic"
 	"github.com/pterm/pterm"
 	"github.com/repplus/rep-cli/internal/noise"
 	"github.com/repplus/rep-cli/internal/store"
@@ -81,11 +80,27 @@ Analyzes captured traffic for a target domain:
 Output is optimized for AI agents to understand the attack surface
 with minimal round-trips.
 
+--baseline <session> compares the traffic against an earlier saved session
+and reports what changed on the target's first-party hosts: new and removed
+endpoints (METHOD host/path, ids collapsed to {id}), parameters that
+existing endpoints gained, new and removed subdomains, and endpoints whose
+auth requirement changed (public, auth, denied from the captured statuses
+and credentials). Save after each recon run to keep a baseline.
+
+--report md|html renders the recon (domains, endpoints, auth surfaces,
+JavaScript, noise, cross-domain flows) as a document with tables and a
+request index; request IDs in the tables link to it. Credential values are
+never included. Written to --out, or stdout without it.
+
 Examples:
   rep recon example.com               Interactive recon overview
   rep recon example.com -o json       Full structured output for agents
   rep recon example.com --flows       Include cross-domain flow analysis
-  rep recon example.com --saved latest  Analyze saved session`,
+  rep recon example.com --saved latest  Analyze saved session
+  rep recon example.com --baseline 20240101-120000   What's new since then
+  rep recon example.com --baseline latest -o json    Change report for agents
+  rep recon example.com --report md --out report.md
+  rep recon example.com --report html --out report.html --saved latest`,
 	Args: cobra.ExactArgs(1),
 	RunE: runRecon,
 }
@@ -113,30 +128,26 @@ func runRecon(cmd *cobra.Command, args []string) error {
 		}
 
 		if session == nil {
-			pterm.Warning.Printf("Session not found: %s\n", reconSaved)
-			pterm.Info.Println("Use 'rep sessions' to list available sessions")
-			return nil
+			return notFoundError("session not found: %s", reconSaved).withHint("Use 'rep sessions' to list available sessions")
 		}
 
 		tempStore = store.NewTempStore(session.Requests)
 	} else {
 		// Default: Load from live.json
-		livePath, err := store.GetLiveFilePath()
+		livePaths, err := store.GetLiveFilePaths()
 		if err != nil {
 			return fmt.Errorf("failed to get live path: %w", err)
 		}
-		export, err := loadLiveExport(livePath)
+		// Bodies are never needed here, so skip them while parsing
+		requests, err := store.LoadLiveWithoutBodiesAll(livePaths)
 		if err != nil {
-			pterm.Warning.Printf("Could not read live.json: %v\n", err)
-			pterm.Info.Println("Enable auto-export in rep+ extension first")
-			return nil
+			return softFail(liveReadError(err))
 		}
-		if len(export.Requests) == 0 {
-			pterm.Info.Println("No requests captured yet (live session empty)")
-			return nil
+		if len(requests) == 0 {
+			return softFail(emptyLiveError())
 		}
 
-		tempStore = store.NewTempStore(export.Requests)
+		tempStore = store.NewTempStore(requests)
 	}
 
 	// Apply ignore/primary lists
@@ -154,17 +165,24 @@ func runRecon(cmd *cobra.Command, args []string) error {
 		ExcludeIgnored: false,
 	})
 
+	if reconBaseline != "" {
+		return runReconBaseline(targetDomain, allRequests, persistentStore)
+	}
+
 	// Build recon output
 	output := buildReconOutput(targetDomain, allRequests, tempStore)
 
 	// Add cross-domain flows if requested
-	if reconFlows {
+	if reconFlows || reconReport != "" {
 		output.CrossDomainFlows = buildCrossDomainFlows(allRequests, targetDomain)
 	}
 
+	if reconReport != "" {
+		return writeReconReport(targetDomain, output, allRequests)
+	}
+
 	if getOutputMode() == "json" {
-		out, _ := sonic.MarshalIndent(output, "", "  ")
-		fmt.Println(string(out))
+		printJSON("recon", output, output.NextSteps...)
 		return nil
 	}
 
@@ -461,4 +479,7 @@ func init() {
 	rootCmd.AddCommand(reconCmd)
 	reconCmd.Flags().BoolVar(&reconFlows, "flows", false, "Include cross-domain flow analysis")
 	reconCmd.Flags().StringVar(&reconSaved, "saved", "", "Read from saved session (ID, prefix, or 'latest')")
+	reconCmd.Flags().StringVar(&reconBaseline, "baseline", "", "Compare against a saved session (ID, prefix, or 'latest')")
+	reconCmd.Flags().StringVar(&reconReport, "report", "", "Render a report: md or html")
+	reconCmd.Flags().StringVar(&reconReportOut, "out", "", "With --report: output file (default stdout)")
 }
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/noise"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+var reconBaseline string
+
+// credentialHeaders carry credentials; a request with any of them is
+// authenticated for the auth-requirement comparison
+var credentialHeaders = []string{"authorization", "cookie", "x-api-key", "x-auth-token", "x-access-token"}
+
+// ReconChanges is the "what's new" report of recon --baseline
+type ReconChanges struct {
+	Target            string            `json:"target"`
+	Baseline          string            `json:"baseline"`
+	NewEndpoints      []string          `json:"new_endpoints"`
+	RemovedEndpoints  []string          `json:"removed_endpoints"`
+	NewParameters     []ReconParamDelta `json:"new_parameters"`
+	NewSubdomains     []string          `json:"new_subdomains"`
+	RemovedSubdomains []string          `json:"removed_subdomains"`
+	AuthChanges       []ReconAuthChange `json:"auth_changes"`
+	NextSteps         []string          `json:"next_steps"`
+}
+
+// ReconParamDelta lists parameters an existing endpoint gained
+type ReconParamDelta struct {
+	Endpoint string   `json:"endpoint"`
+	Params   []string `json:"params"`
+}
+
+// ReconAuthChange is an endpoint whose observed auth requirement changed
+type ReconAuthChange struct {
+	Endpoint string `json:"endpoint"`
+	Before   string `json:"before"` // public, auth, denied
+	After    string `json:"after"`
+}
+
+// reconEndpoint accumulates one "METHOD host/template" endpoint
+type reconEndpoint struct {
+	params map[string]bool
+	// Outcomes by credential use: 2xx with/without credentials, 401/403
+	okWithAuth, okWithoutAuth, denied bool
+}
+
+// reconSnapshot is the first-party attack surface of one capture
+type reconSnapshot struct {
+	endpoints map[string]*reconEndpoint
+	hosts     map[string]bool
+}
+
+// runReconBaseline compares requests (current traffic) against a saved
+// baseline session, limited to the target's first-party, non-noise hosts
+func runReconBaseline(target string, requests []store.Request, persistentStore *store.Store) error {
+	session := resolveSession(persistentStore, reconBaseline)
+	if session == nil {
+		return notFoundError("baseline session not found: %s", reconBaseline).withHint("Use 'rep sessions' to list available sessions")
+	}
+	baselineRequests := store.NewTempStore(session.Requests).Filter(store.FilterOptions{})
+
+	before := buildReconSnapshot(target, baselineRequests)
+	after := buildReconSnapshot(target, requests)
+	changes := diffReconSnapshots(before, after)
+	changes.Target, changes.Baseline = target, session.ID
+	changes.NextSteps = reconChangeSteps(changes)
+
+	if getOutputMode() == "json" {
+		out, _ := sonic.MarshalIndent(changes, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+	printReconChanges(changes)
+	return nil
+}
+
+func buildReconSnapshot(target string, requests []store.Request) reconSnapshot {
+	snap := reconSnapshot{endpoints: make(map[string]*reconEndpoint), hosts: make(map[string]bool)}
+	targetBase := store.GetBaseDomain(target)
+	for i := range requests {
+		req := &requests[i]
+		host := hostFromURL(req.URL)
+		if host == "" || store.GetBaseDomain(host) != targetBase || noise.DetectNoiseType(host) != "" {
+			continue
+		}
+		snap.hosts[host] = true
+
+		parsed, err := url.Parse(req.URL)
+		if err != nil {
+			continue
+		}
+		key := fmt.Sprintf("%s %s%s", req.Method, host, store.EndpointTemplate(parsed.Path))
+		e := snap.endpoints[key]
+		if e == nil {
+			e = &reconEndpoint{params: make(map[string]bool)}
+			snap.endpoints[key] = e
+		}
+		for name := range parsed.Query() {
+			e.params[name] = true
+		}
+		// Live requests are read without bodies; if the file changed since,
+		// only the URL's parameters are known
+		if err := store.LoadBodies(req); err == nil {
+			for _, name := range bodyParamNames(req) {
+				e.params[name] = true
+			}
+		}
+
+		if req.Response == nil {
+			continue
+		}
+		switch status := req.Response.Status; {
+		case status == 401 || status == 403:
+			e.denied = true
+		case status >= 200 && status < 300:
+			if sendsCredentials(req) {
+				e.okWithAuth = true
+			} else {
+				e.okWithoutAuth = true
+			}
+		}
+	}
+	return snap
+}
+
+// bodyParamNames returns the top-level keys of a JSON or form request body
+func bodyParamNames(req *store.Request) []string {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil
+	}
+	var names []string
+	if strings.HasPrefix(body, "{") {
+		var fields map[string]interface{}
+		if sonic.UnmarshalString(body, &fields) == nil {
+			for name := range fields {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	if strings.Contains(strings.ToLower(store.HeaderFirst(req.Headers, "content-type")), "form-urlencoded") {
+		if values, err := url.ParseQuery(body); err == nil {
+			for name := range values {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func sendsCredentials(req *store.Request) bool {
+	for _, name := range credentialHeaders {
+		if store.HeaderFirst(req.Headers, name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// authRequirement classifies what an endpoint needed: "public" (succeeded
+// without credentials), "auth" (succeeded only with them), "denied" (only
+// 401/403 seen), or "" when no outcome was captured
+func (e *reconEndpoint) authRequirement() string {
+	switch {
+	case e.okWithoutAuth:
+		return "public"
+	case e.okWithAuth:
+		return "auth"
+	case e.denied:
+		return "denied"
+	}
+	return ""
+}
+
+func diffReconSnapshots(before, after reconSnapshot) ReconChanges {
+	changes := ReconChanges{
+		NewEndpoints:      []string{},
+		RemovedEndpoints:  []string{},
+		NewParameters:     []ReconParamDelta{},
+		NewSubdomains:     []string{},
+		RemovedSubdomains: []string{},
+		AuthChanges:       []ReconAuthChange{},
+	}
+	for key, e := range after.endpoints {
+		old, ok := before.endpoints[key]
+		if !ok {
+			changes.NewEndpoints = append(changes.NewEndpoints, key)
+			continue
+		}
+		var added []string
+		for name := range e.params {
+			if !old.params[name] {
+				added = append(added, name)
+			}
+		}
+		if len(added) > 0 {
+			sort.Strings(added)
+			changes.NewParameters = append(changes.NewParameters, ReconParamDelta{Endpoint: key, Params: added})
+		}
+		was, now := old.authRequirement(), e.authRequirement()
+		if was != "" && now != "" && was != now {
+			changes.AuthChanges = append(changes.AuthChanges, ReconAuthChange{Endpoint: key, Before: was, After: now})
+		}
+	}
+	for key := range before.endpoints {
+		if _, ok := after.endpoints[key]; !ok {
+			changes.RemovedEndpoints = append(changes.RemovedEndpoints, key)
+		}
+	}
+	for host := range after.hosts {
+		if !before.hosts[host] {
+			changes.NewSubdomains = append(changes.NewSubdomains, host)
+		}
+	}
+	for host := range before.hosts {
+		if !after.hosts[host] {
+			changes.RemovedSubdomains = append(changes.RemovedSubdomains, host)
+		}
+	}
+
+	sort.Strings(changes.NewEndpoints)
+	sort.Strings(changes.RemovedEndpoints)
+	sort.Strings(changes.NewSubdomains)
+	sort.Strings(changes.RemovedSubdomains)
+	sort.Slice(changes.NewParameters, func(i, j int) bool {
+		return changes.NewParameters[i].Endpoint < changes.NewParameters[j].Endpoint
+	})
+	sort.Slice(changes.AuthChanges, func(i, j int) bool {
+		return changes.AuthChanges[i].Endpoint < changes.AuthChanges[j].Endpoint
+	})
+	return changes
+}
+
+// reconChangeSteps suggests follow-ups for the changes, most actionable first
+func reconChangeSteps(c ReconChanges) []string {
+	var steps []string
+	for _, a := range c.AuthChanges {
+		if a.After == "public" {
+			method, rest, _ := strings.Cut(a.Endpoint, " ")
+			steps = append(steps, fmt.Sprintf("rep list -m %s -p '%s' -o json   # now public (was %s)", method, reconPathPattern(rest), a.Before))
+		}
+	}
+	for _, host := range c.NewSubdomains {
+		steps = append(steps, fmt.Sprintf("rep list -d %s -o json", host))
+	}
+	if len(c.NewEndpoints) > 0 || len(c.NewParameters) > 0 {
+		steps = append(steps, "rep list --api --primary -o json")
+	}
+	if len(c.RemovedEndpoints) > 0 {
+		steps = append(steps, fmt.Sprintf("rep curl --filter --saved %s -p '%s'   # are removed endpoints still served?", c.Baseline, reconPathPattern(strings.SplitN(c.RemovedEndpoints[0], " ", 2)[1])))
+	}
+	if steps == nil {
+		steps = []string{}
+	}
+	return steps
+}
+
+// reconPathPattern turns "host/v1/users/{id}" into a path regex for -p
+func reconPathPattern(hostPath string) string {
+	path := hostPath
+	if i := strings.Index(hostPath, "/"); i >= 0 {
+		path = hostPath[i:]
+	}
+	return strings.ReplaceAll(regexp.QuoteMeta(path), `\{id\}`, "[^/]+")
+}
+
+func printReconChanges(c ReconChanges) {
+	pterm.DefaultSection.Printf("What's new on %s since %s\n", c.Target, c.Baseline)
+	total := len(c.NewEndpoints) + len(c.RemovedEndpoints) + len(c.NewParameters) +
+		len(c.NewSubdomains) + len(c.RemovedSubdomains) + len(c.AuthChanges)
+	if total == 0 {
+		pterm.Success.Println("No changes against the baseline")
+		return
+	}
+
+	printList := func(title string, items []string, marker string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Printf("\n%s (%d)\n", title, len(items))
+		for _, item := range items {
+			fmt.Printf("  %s %s\n", marker, output.SanitizeText(item))
+		}
+	}
+	printList("New subdomains", c.NewSubdomains, pterm.FgGreen.Sprint("+"))
+	printList("New endpoints", c.NewEndpoints, pterm.FgGreen.Sprint("+"))
+	if len(c.AuthChanges) > 0 {
+		fmt.Printf("\nAuth requirement changes (%d)\n", len(c.AuthChanges))
+		for _, a := range c.AuthChanges {
+			change := fmt.Sprintf("%s -> %s", a.Before, a.After)
+			if a.After == "public" {
+				change = pterm.FgRed.Sprint(change)
+			}
+			fmt.Printf("  %s %s  %s\n", pterm.FgYellow.Sprint("~"), output.SanitizeText(a.Endpoint), change)
+		}
+	}
+	if len(c.NewParameters) > 0 {
+		fmt.Printf("\nNew parameters (%d endpoints)\n", len(c.NewParameters))
+		for _, p := range c.NewParameters {
+			fmt.Printf("  %s %s  %s\n", pterm.FgGreen.Sprint("+"), output.SanitizeText(p.Endpoint), pterm.FgCyan.Sprint(strings.Join(p.Params, ", ")))
+		}
+	}
+	printList("Removed endpoints", c.RemovedEndpoints, pterm.FgRed.Sprint("-"))
+	printList("Removed subdomains", c.RemovedSubdomains, pterm.FgRed.Sprint("-"))
+
+	if len(c.NextSteps) > 0 {
+		fmt.Println()
+		pterm.DefaultSection.Println("Next Steps")
+		for i, step := range c.NextSteps {
+			fmt.Printf("  %d. %s\n", i+1, step)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/noise"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+var (
+	reconReport    string // md, html
+	reconReportOut string
+)
+
+// reportSection is one titled part of a recon report: optional prose and
+// an optional table. Renderers turn it into Markdown or HTML.
+type reportSection struct {
+	Title string
+	Text  []string // Paragraphs
+	Table *reportTable
+}
+
+// reportTable is a table whose idColumn (if >= 0) holds space-separated
+// request IDs, rendered as links to the request index
+type reportTable struct {
+	Header   []string
+	Rows     [][]string
+	idColumn int
+	anchors  bool // The first column holds request IDs the links point at
+}
+
+// writeReconReport renders the recon output and the first-party requests
+// behind it as a Markdown or HTML report
+func writeReconReport(target string, recon ReconOutput, requests []store.Request) error {
+	format := strings.ToLower(reconReport)
+	if format == "markdown" {
+		format = "md"
+	}
+	if format != "md" && format != "html" {
+		return fmt.Errorf("--report must be md or html")
+	}
+
+	sections := buildReconReport(target, recon, requests)
+	title := "Recon report: " + target
+	var rendered string
+	if format == "md" {
+		rendered = renderReportMarkdown(title, sections)
+	} else {
+		rendered = renderReportHTML(title, sections)
+	}
+
+	if reconReportOut == "" || reconReportOut == "-" {
+		fmt.Print(rendered)
+		return nil
+	}
+	if err := os.WriteFile(reconReportOut, []byte(rendered), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	pterm.Success.Printf("Report written to %s (%d sections)\n", reconReportOut, len(sections))
+	return nil
+}
+
+func buildReconReport(target string, recon ReconOutput, requests []store.Request) []reportSection {
+	targetBase := store.GetBaseDomain(target)
+	var firstParty []store.Request
+	for _, req := range requests {
+		host := hostFromURL(req.URL)
+		if store.GetBaseDomain(host) == targetBase && noise.DetectNoiseType(host) == "" {
+			firstParty = append(firstParty, req)
+		}
+	}
+
+	sections := []reportSection{{
+		Title: "Overview",
+		Text: []string{fmt.Sprintf("Generated %s from %d captured requests: %d first-party domains (%d requests), %d third-party domains (%d requests), %d noise domains.",
+			time.Now().Format("2006-01-02 15:04"), recon.TotalRequests,
+			len(recon.FirstParty.Domains), recon.FirstParty.Requests,
+			len(recon.ThirdParty.Domains), recon.ThirdParty.Requests,
+			len(recon.NoiseDetected))},
+	}}
+
+	sections = append(sections, reportDomainSection("First-party domains", recon.FirstParty.Domains))
+	sections = append(sections, reportDomainSection("Third-party domains", recon.ThirdParty.Domains))
+
+	endpoints := &reportTable{Header: []string{"Method", "Host", "Path", "Status", "Requests"}, idColumn: 4}
+	for _, e := range reportEndpoints(firstParty) {
+		endpoints.Rows = append(endpoints.Rows, []string{e.method, e.host, e.path, strings.Join(e.statuses, ", "), strings.Join(e.ids, " ")})
+	}
+	sections = append(sections, reportSection{
+		Title: "Endpoints",
+		Text:  []string{"First-party endpoints with variable path segments collapsed to {id} (first 5 request IDs each)."},
+		Table: endpoints,
+	})
+
+	auth := &reportTable{Header: []string{"Domain", "Credentials", "Requests"}, idColumn: -1}
+	for _, a := range reportAuthSurfaces(firstParty) {
+		auth.Rows = append(auth.Rows, []string{a.domain, strings.Join(a.mechanisms, ", "), fmt.Sprintf("%d", a.requests)})
+	}
+	sections = append(sections, reportSection{
+		Title: "Auth surfaces",
+		Text:  []string{"Credential types sent per domain. Values are not included; use 'rep auth --save' to extract them."},
+		Table: auth,
+	})
+
+	js := categorizeJS(jsFromRequests(requests))
+	scripts := &reportTable{Header: []string{"Category", "Script"}, idColumn: -1}
+	for _, group := range []struct {
+		label string
+		files []JSFile
+	}{{"first-party", js.FirstPartyJS}, {"third-party", js.ThirdPartyJS}, {"cdn", js.CDNScripts}} {
+		for _, f := range group.files {
+			scripts.Rows = append(scripts.Rows, []string{group.label, f.URL})
+		}
+	}
+	sections = append(sections, reportSection{
+		Title: "JavaScript",
+		Text: []string{fmt.Sprintf("%d scripts: %d first-party, %d third-party, %d CDN.",
+			js.Summary.TotalScripts, js.Summary.FirstPartyCount, js.Summary.ThirdPartyCount, js.Summary.CDNCount)},
+		Table: scripts,
+	})
+
+	noiseTable := &reportTable{Header: []string{"Domain", "Type", "Requests"}, idColumn: -1}
+	for _, n := range recon.NoiseDetected {
+		noiseTable.Rows = append(noiseTable.Rows, []string{n.Domain, n.Type, fmt.Sprintf("%d", n.Requests)})
+	}
+	noiseSection := reportSection{Title: "Noise", Table: noiseTable}
+	if recon.SuggestedIgnore != "" {
+		noiseSection.Text = []string{"Suggested: " + recon.SuggestedIgnore}
+	}
+	sections = append(sections, noiseSection)
+
+	flows := &reportTable{Header: []string{"Page", "Requests", "Domains requested"}, idColumn: -1}
+	for _, f := range recon.CrossDomainFlows {
+		flows.Rows = append(flows.Rows, []string{f.PageURL, fmt.Sprintf("%d", f.RequestCount), strings.Join(f.RequestedDomains, ", ")})
+	}
+	sections = append(sections, reportSection{Title: "Cross-domain flows", Table: flows})
+
+	index := &reportTable{Header: []string{"ID", "Method", "URL", "Status"}, idColumn: -1, anchors: true}
+	for _, req := range firstParty {
+		status := "-"
+		if req.Response != nil {
+			status = fmt.Sprintf("%d", req.Response.Status)
+		}
+		index.Rows = append(index.Rows, []string{req.ID, req.Method, req.URL, status})
+	}
+	sections = append(sections, reportSection{
+		Title: "Request index",
+		Text:  []string{"Inspect any request with 'rep body <id>' or replay it with 'rep curl <id>'."},
+		Table: index,
+	})
+	return sections
+}
+
+func reportDomainSection(title string, domains []ReconDomainSummary) reportSection {
+	table := &reportTable{Header: []string{"Domain", "Requests", "Endpoints", "Methods"}, idColumn: -1}
+	for _, d := range domains {
+		table.Rows = append(table.Rows, []string{d.Domain, fmt.Sprintf("%d", d.Requests), fmt.Sprintf("%d", d.Endpoints), strings.Join(d.Methods, ", ")})
+	}
+	return reportSection{Title: title, Table: table}
+}
+
+type reportEndpoint struct {
+	method, host, path string
+	statuses, ids      []string
+}
+
+func reportEndpoints(requests []store.Request) []*reportEndpoint {
+	byKey := make(map[string]*reportEndpoint)
+	for _, req := range requests {
+		parsed, err := url.Parse(req.URL)
+		if err != nil {
+			continue
+		}
+		path := store.EndpointTemplate(parsed.Path)
+		key := req.Method + " " + parsed.Host + path
+		e := byKey[key]
+		if e == nil {
+			e = &reportEndpoint{method: req.Method, host: parsed.Host, path: path}
+			byKey[key] = e
+		}
+		if req.Response != nil {
+			status := fmt.Sprintf("%d", req.Response.Status)
+			if !containsString(e.statuses, status) {
+				e.statuses = append(e.statuses, status)
+			}
+		}
+		if len(e.ids) < 5 {
+			e.ids = append(e.ids, req.ID)
+		}
+	}
+	endpoints := make([]*reportEndpoint, 0, len(byKey))
+	for _, e := range byKey {
+		sort.Strings(e.statuses)
+		endpoints = append(endpoints, e)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].host != endpoints[j].host {
+			return endpoints[i].host < endpoints[j].host
+		}
+		if endpoints[i].path != endpoints[j].path {
+			return endpoints[i].path < endpoints[j].path
+		}
+		return endpoints[i].method < endpoints[j].method
+	})
+	return endpoints
+}
+
+type reportAuthSurface struct {
+	domain     string
+	mechanisms []string
+	requests   int
+}
+
+// reportAuthSurfaces lists which credential types each domain receives
+func reportAuthSurfaces(requests []store.Request) []reportAuthSurface {
+	byDomain := make(map[string]*reportAuthSurface)
+	for i := range requests {
+		req := &requests[i]
+		var mechanisms []string
+		if value := store.HeaderFirst(req.Headers, "authorization"); value != "" {
+			scheme, _, _ := strings.Cut(value, " ")
+			mechanisms = append(mechanisms, "Authorization: "+scheme)
+		}
+		for _, name := range credentialHeaders[1:] {
+			if store.HeaderFirst(req.Headers, name) != "" {
+				mechanisms = append(mechanisms, http.CanonicalHeaderKey(name))
+			}
+		}
+		if len(mechanisms) == 0 {
+			continue
+		}
+		host := hostFromURL(req.URL)
+		a := byDomain[host]
+		if a == nil {
+			a = &reportAuthSurface{domain: host}
+			byDomain[host] = a
+		}
+		a.requests++
+		for _, m := range mechanisms {
+			if !containsString(a.mechanisms, m) {
+				a.mechanisms = append(a.mechanisms, m)
+			}
+		}
+	}
+	surfaces := make([]reportAuthSurface, 0, len(byDomain))
+	for _, a := range byDomain {
+		sort.Strings(a.mechanisms)
+		surfaces = append(surfaces, *a)
+	}
+	sort.Slice(surfaces, func(i, j int) bool { return surfaces[i].domain < surfaces[j].domain })
+	return surfaces
+}
+
+// jsFromRequests returns the JavaScript requests among requests
+func jsFromRequests(requests []store.Request) []store.Request {
+	var scripts []store.Request
+	for i := range requests {
+		if isJavaScript(&requests[i]) {
+			scripts = append(scripts, requests[i])
+		}
+	}
+	return scripts
+}
+
+func renderReportMarkdown(title string, sections []reportSection) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", title)
+	for _, s := range sections {
+		fmt.Fprintf(&b, "\n## %s\n\n", s.Title)
+		for _, p := range s.Text {
+			fmt.Fprintf(&b, "%s\n\n", p)
+		}
+		if s.Table == nil || len(s.Table.Rows) == 0 {
+			if s.Table != nil {
+				b.WriteString("_None._\n")
+			}
+			continue
+		}
+		b.WriteString("| " + strings.Join(s.Table.Header, " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat(" --- |", len(s.Table.Header)) + "\n")
+		for _, row := range s.Table.Rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				switch {
+				case i == 0 && s.Table.anchors:
+					cells[i] = fmt.Sprintf(`<a id="%s"></a>%s`, html.EscapeString(cell), markdownCell(cell))
+				case i == s.Table.idColumn:
+					var links []string
+					for _, id := range strings.Fields(cell) {
+						links = append(links, fmt.Sprintf("[%s](#%s)", markdownCell(id), id))
+					}
+					cells[i] = strings.Join(links, " ")
+				default:
+					cells[i] = markdownCell(cell)
+				}
+			}
+			b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+	}
+	return b.String()
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(text string) string {
+	text = strings.NewReplacer("|", `\|`, "\n", " ", "\r", "").Replace(text)
+	return strings.ReplaceAll(text, "`", "\\`")
+}
+
+func renderReportHTML(title string, sections []reportSection) string {
+	var b strings.Builder
+	esc := html.EscapeString
+	fmt.Fprintf(&b, `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", sans-serif; margin: 2em auto; max-width: 70em; padding: 0 1em; color: #222; }
+table { border-collapse: collapse; width: 100%%; margin: 0.5em 0 1.5em; font-size: 0.9em; }
+th, td { border: 1px solid #ddd; padding: 0.3em 0.6em; text-align: left; vertical-align: top; word-break: break-all; }
+th { background: #f4f4f4; }
+tr:target { background: #fff3c4; }
+code, td:first-child a { font-family: ui-monospace, monospace; }
+.none { color: #888; }
+</style>
+</head>
+<body>
+<h1>%s</h1>
+`, esc(title), esc(title))
+	for _, s := range sections {
+		fmt.Fprintf(&b, "<h2>%s</h2>\n", esc(s.Title))
+		for _, p := range s.Text {
+			fmt.Fprintf(&b, "<p>%s</p>\n", esc(p))
+		}
+		if s.Table == nil {
+			continue
+		}
+		if len(s.Table.Rows) == 0 {
+			b.WriteString("<p class=\"none\">None.</p>\n")
+			continue
+		}
+		b.WriteString("<table>\n<tr>")
+		for _, h := range s.Table.Header {
+			fmt.Fprintf(&b, "<th>%s</th>", esc(h))
+		}
+		b.WriteString("</tr>\n")
+		for _, row := range s.Table.Rows {
+			if s.Table.anchors {
+				fmt.Fprintf(&b, "<tr id=\"%s\">", esc(row[0]))
+			} else {
+				b.WriteString("<tr>")
+			}
+			for i, cell := range row {
+				if i == s.Table.idColumn {
+					var links []string
+					for _, id := range strings.Fields(cell) {
+						links = append(links, fmt.Sprintf(`<a href="#%s">%s</a>`, esc(id), esc(id)))
+					}
+					fmt.Fprintf(&b, "<td>%s</td>", strings.Join(links, " "))
+					continue
+				}
+				fmt.Fprintf(&b, "<td>%s</td>", esc(cell))
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("</table>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
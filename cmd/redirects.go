@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// redirectTokenParam matches query/fragment parameter names that carry
+// credentials or flow state along a redirect chain (OAuth code, state,
+// tokens, SAML assertions, session IDs)
+var redirectTokenParam = regexp.MustCompile(`(?i)(token|code|state|nonce|session|sid|jwt|ticket|assertion|saml|auth|key)`)
+
+// RedirectHop is one request of a redirect chain
+type RedirectHop struct {
+	ID             string          `json:"id,omitempty"` // Empty when the Location target was not captured
+	Method         string          `json:"method,omitempty"`
+	URL            string          `json:"url"`
+	Status         int             `json:"status,omitempty"`
+	Location       string          `json:"location,omitempty"` // Resolved against the hop's URL
+	Tokens         []RedirectToken `json:"tokens,omitempty"`
+	CookiesSet     []string        `json:"cookies_set,omitempty"` // Set-Cookie names ("name (cleared)" for deletions)
+	CookiesAdded   []string        `json:"cookies_added,omitempty"`
+	CookiesChanged []string        `json:"cookies_changed,omitempty"`
+	CookiesRemoved []string        `json:"cookies_removed,omitempty"`
+	AuthChanged    bool            `json:"auth_changed,omitempty"` // Authorization differs from the previous hop
+}
+
+// RedirectToken is a token-like parameter in a hop's URL
+type RedirectToken struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	In    string `json:"in"` // query, fragment
+}
+
+// showRedirectChain reconstructs and prints the redirect chain through a request
+func showRedirectChain(s *store.Store, requestID string) error {
+	requests := s.Filter(store.FilterOptions{})
+	start := -1
+	for i := range requests {
+		if requests[i].ID == requestID {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return notFoundError("request not found: %s", requestID)
+	}
+
+	hops := buildRedirectChain(requests, start)
+
+	if getOutputMode() == "json" {
+		out, _ := sonic.MarshalIndent(map[string]interface{}{
+			"id":   requestID,
+			"hops": hops,
+		}, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+	printRedirectChain(requestID, hops)
+	return nil
+}
+
+// buildRedirectChain walks back from requests[start] to the first request
+// of its chain, then forward following each 3xx Location to the next
+// request for that URL
+func buildRedirectChain(requests []store.Request, start int) []RedirectHop {
+	used := map[int]bool{start: true}
+	chain := []int{start}
+
+	// Backward: the latest earlier 3xx whose Location is this URL
+	for {
+		cur := &requests[chain[0]]
+		prev := -1
+		for i := range requests {
+			r := &requests[i]
+			if used[i] || r.Timestamp > cur.Timestamp || redirectLocation(r) != cur.URL {
+				continue
+			}
+			if prev < 0 || r.Timestamp >= requests[prev].Timestamp {
+				prev = i
+			}
+		}
+		if prev < 0 {
+			break
+		}
+		used[prev] = true
+		chain = append([]int{prev}, chain...)
+	}
+
+	// Forward: the earliest later request for the Location URL
+	var dangling string
+	for {
+		cur := &requests[chain[len(chain)-1]]
+		location := redirectLocation(cur)
+		if location == "" {
+			break
+		}
+		next := -1
+		for i := range requests {
+			r := &requests[i]
+			if used[i] || r.URL != location || r.Timestamp < cur.Timestamp {
+				continue
+			}
+			if next < 0 || r.Timestamp < requests[next].Timestamp {
+				next = i
+			}
+		}
+		if next < 0 {
+			dangling = location
+			break
+		}
+		used[next] = true
+		chain = append(chain, next)
+	}
+
+	hops := make([]RedirectHop, 0, len(chain)+1)
+	var prevReq *store.Request
+	for _, i := range chain {
+		req := &requests[i]
+		hop := RedirectHop{ID: req.ID, Method: req.Method, URL: req.URL, Location: redirectLocation(req)}
+		if req.Response != nil {
+			hop.Status = req.Response.Status
+			hop.CookiesSet = setCookieNames(req.Response.Headers)
+		}
+		hop.Tokens = redirectTokens(req.URL)
+		if prevReq != nil {
+			hop.CookiesAdded, hop.CookiesChanged, hop.CookiesRemoved = diffSentCookies(prevReq, req)
+			hop.AuthChanged = store.HeaderFirst(prevReq.Headers, "authorization") != store.HeaderFirst(req.Headers, "authorization")
+		}
+		hops = append(hops, hop)
+		prevReq = req
+	}
+	if dangling != "" {
+		hops = append(hops, RedirectHop{URL: dangling, Tokens: redirectTokens(dangling)})
+	}
+	return hops
+}
+
+// redirectLocation returns the absolute Location target of a 3xx response
+func redirectLocation(req *store.Request) string {
+	if req.Response == nil || req.Response.Status < 300 || req.Response.Status >= 400 {
+		return ""
+	}
+	location := store.HeaderFirst(req.Response.Headers, "location")
+	if location == "" {
+		return ""
+	}
+	base, err := url.Parse(req.URL)
+	if err != nil {
+		return location
+	}
+	target, err := base.Parse(location)
+	if err != nil {
+		return location
+	}
+	return target.String()
+}
+
+// redirectTokens lists token-like query and fragment parameters of a URL
+func redirectTokens(rawURL string) []RedirectToken {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	var tokens []RedirectToken
+	collect := func(raw, in string) {
+		values, err := url.ParseQuery(raw)
+		if err != nil {
+			return
+		}
+		names := make([]string, 0, len(values))
+		for name := range values {
+			if redirectTokenParam.MatchString(name) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			tokens = append(tokens, RedirectToken{Name: name, Value: values.Get(name), In: in})
+		}
+	}
+	collect(parsed.RawQuery, "query")
+	if strings.Contains(parsed.Fragment, "=") {
+		collect(parsed.Fragment, "fragment")
+	}
+	return tokens
+}
+
+// setCookieNames returns the cookie names a response sets, marking the ones
+// it deletes
+func setCookieNames(headers store.HeaderMap) []string {
+	values := store.HeaderValues(headers, "set-cookie")
+	if len(values) == 0 {
+		return nil
+	}
+	resp := http.Response{Header: http.Header{"Set-Cookie": values}}
+	var names []string
+	for _, c := range resp.Cookies() {
+		name := c.Name
+		if c.MaxAge < 0 || c.Value == "" {
+			name += " (cleared)"
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// sentCookies returns the cookies a request sent, by name
+func sentCookies(req *store.Request) map[string]string {
+	values := store.HeaderValues(req.Headers, "cookie")
+	cookies := make(map[string]string)
+	r := http.Request{Header: http.Header{"Cookie": values}}
+	for _, c := range r.Cookies() {
+		cookies[c.Name] = c.Value
+	}
+	return cookies
+}
+
+// diffSentCookies compares the cookies two consecutive hops sent
+func diffSentCookies(prev, cur *store.Request) (added, changed, removed []string) {
+	before, after := sentCookies(prev), sentCookies(cur)
+	for name, value := range after {
+		old, ok := before[name]
+		switch {
+		case !ok:
+			added = append(added, name)
+		case old != value:
+			changed = append(changed, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(changed)
+	sort.Strings(removed)
+	return added, changed, removed
+}
+
+func printRedirectChain(requestID string, hops []RedirectHop) {
+	if len(hops) == 1 && hops[0].Location == "" {
+		pterm.Info.Printf("%s is not part of a redirect chain\n", requestID)
+		return
+	}
+	pterm.DefaultSection.Printf("Redirect chain through %s (%d hops)\n", requestID, len(hops))
+	gray := pterm.FgGray.Sprint
+	for i, hop := range hops {
+		marker := " "
+		if hop.ID == requestID {
+			marker = pterm.FgCyan.Sprint("▶")
+		}
+		if hop.ID == "" {
+			fmt.Printf("%s %2d. %s %s\n", marker, i+1, gray("(not captured)"), output.SanitizeText(hop.URL))
+		} else {
+			status := "---"
+			if hop.Status > 0 {
+				status = colorStatus(hop.Status, fmt.Sprint(hop.Status))
+			}
+			fmt.Printf("%s %2d. %s %s %s %s\n", marker, i+1, status, hop.Method, output.SanitizeText(hop.URL), gray("["+hop.ID+"]"))
+		}
+		for _, t := range hop.Tokens {
+			fmt.Printf("        %s %s=%s\n", pterm.FgYellow.Sprint(t.In), t.Name, output.SanitizeText(truncateCell(t.Value, 60)))
+		}
+		if len(hop.CookiesSet) > 0 {
+			fmt.Printf("        Set-Cookie: %s\n", strings.Join(hop.CookiesSet, ", "))
+		}
+		var changes []string
+		for _, name := range hop.CookiesAdded {
+			changes = append(changes, "+"+name)
+		}
+		for _, name := range hop.CookiesChanged {
+			changes = append(changes, "~"+name)
+		}
+		for _, name := range hop.CookiesRemoved {
+			changes = append(changes, "-"+name)
+		}
+		if len(changes) > 0 {
+			fmt.Printf("        Cookies sent: %s\n", strings.Join(changes, " "))
+		}
+		if hop.AuthChanged {
+			fmt.Printf("        %s\n", pterm.FgYellow.Sprint("Authorization header changed"))
+		}
+		if hop.Location != "" {
+			fmt.Printf("        %s %s\n", gray("→"), gray(output.SanitizeText(hop.Location)))
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/replay"
+	"github.com/repplus/rep-cli/internal/similarity"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	replaySaved      string
+	replayUseVars    bool
+	replayInsecure   bool
+	replaySuites     string
+	replayCanary     string
+	replayDryRun     bool
+	replaySimilarity float64
+)
+
+var replaySuiteNames = []string{"host-header", "cors", "method-override"}
+
+// replayOverrideHeaders carry a tunneled HTTP method
+var replayOverrideHeaders = []string{"X-HTTP-Method-Override", "X-HTTP-Method", "X-Method-Override"}
+
+// replayOverrideMethods are the methods a suite tries to tunnel
+var replayOverrideMethods = []string{"PUT", "PATCH", "DELETE"}
+
+// ReplayProbe is one mutated request of a suite and, once sent, its outcome
+type ReplayProbe struct {
+	Suite      string                 `json:"suite"`
+	Label      string                 `json:"label"` // What changed
+	Status     int                    `json:"status,omitempty"`
+	Size       int64                  `json:"size,omitempty"`
+	Diff       string                 `json:"diff,omitempty"` // same, similar, status, body
+	Similarity *similarity.Comparison `json:"similarity,omitempty"`
+	Anomaly    string                 `json:"anomaly,omitempty"` // Why the response deserves a look
+	Error      string                 `json:"error,omitempty"`
+	origin     string                 // Origin sent by a cors probe
+	request    *store.Request
+}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <request-id>",
+	Short: "Re-send a request, alone or as a mutation suite",
+	Long: `Re-send a captured request and diff the response against a baseline.
+
+Without --suite, the request is sent once and compared with the captured
+response (status, size, body). With --suite, the request is sent as a
+baseline and then once per mutation, and every response is diffed against
+the baseline:
+
+  host-header      Host, X-Forwarded-Host, X-Host, X-Forwarded-Server and
+                   Forwarded pointing at a canary host, localhost and an
+                   unexpected port. The canary reflected in the response
+                   (Location, links, body) points to host header injection:
+                   cache poisoning, password reset poisoning, SSRF routing.
+  cors             Origin set to a foreign site, null, a suffix/prefix of the
+                   target domain, a subdomain and plain http. An Origin echoed
+                   in Access-Control-Allow-Origin is flagged, and rated high
+                   when Access-Control-Allow-Credentials is true.
+  method-override  X-HTTP-Method-Override, X-HTTP-Method, X-Method-Override
+                   and ?_method= tunneling PUT, PATCH and DELETE. A response
+                   that differs from the baseline means the override reached
+                   the application.
+
+Suites send one request per mutation, sequentially; --dry-run lists them
+without sending anything.
+
+Repeater mode changes the request before sending it, then shows the status
+and a line diff of the response body against the capture (JSON bodies are
+pretty-printed first):
+
+  --edit        Open the raw request in $VISUAL/$EDITOR; after each send,
+                Enter edits the last version again, q quits
+  --raw-file    Send a raw HTTP request from a file (- for stdin), as
+                'rep raw' writes it; scheme and host default to the capture
+  -X, -H, --body  Patch the method, a header ('Name:' removes it) or the body
+
+Content-Length is recomputed; the flags apply before --edit and --raw-file.
+
+Responses are compared by status, length, word overlap (Jaccard) and, for
+JSON, key structure, into a similarity score from 0 to 1. A body scoring
+under --similarity-threshold is "body" (different); a changed body above it
+is "similar", as when only a timestamp or a nonce changed.
+
+--use-vars resolves credentials from the environment and the domain auth
+env file ('rep auth --save'): auth headers, and the same token, session and
+CSRF values wherever they appear in the query string or body. The injected
+variables are listed with the result.
+
+Examples:
+  rep replay h_abc123                          Re-send and diff against the capture
+  rep replay h_abc123 --suite host-header      Host header injection matrix
+  rep replay h_abc123 --suite cors,method-override
+  rep replay h_abc123 --suite cors --dry-run   Show the probes only
+  rep replay h_abc123 --suite method-override --similarity-threshold 0.98
+  rep replay h_abc123 --suite cors --use-vars -o json
+  rep replay h_abc123 --edit --use-vars        Terminal repeater
+  rep replay h_abc123 -H 'Authorization:' -X DELETE
+  rep raw h_abc123 | sed 's/42/43/' | rep replay h_abc123 --raw-file - -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		requestID := args[0]
+
+		var suites []string
+		for _, s := range parseCommaSeparated(strings.ToLower(replaySuites)) {
+			if !containsString(replaySuiteNames, s) {
+				return fmt.Errorf("invalid suite: %s (use %s)", s, strings.Join(replaySuiteNames, ", "))
+			}
+			suites = append(suites, s)
+		}
+		if replayDryRun && len(suites) == 0 {
+			return fmt.Errorf("--dry-run requires --suite")
+		}
+		if replaySimilarity < 0 || replaySimilarity > 1 {
+			return usageError("--similarity-threshold must be between 0 and 1")
+		}
+
+		req, err := lookupRequest(requestID, replaySaved)
+		if err != nil {
+			return err
+		}
+		if req == nil {
+			return notFoundError("request not found: %s", requestID).withHint("Use 'rep list' to see available request IDs")
+		}
+
+		sendReq, missing := resolveSendRequest(req, replayUseVars)
+		if err := warnMissingVars(req, missing); err != nil {
+			return err
+		}
+
+		if replayEditing() {
+			if len(suites) > 0 {
+				return usageError("--suite can't be combined with --edit, --raw-file, --header, --method or --body")
+			}
+			return runReplayEdit(req, sendReq)
+		}
+
+		var probes []ReplayProbe
+		for _, suite := range suites {
+			probes = append(probes, replaySuiteProbes(suite, sendReq, replayCanary)...)
+		}
+		if replayDryRun {
+			printReplayPlan(req, probes)
+			return nil
+		}
+
+		opts := replay.Options{Insecure: replayInsecure}
+		baseline, err := replay.Send(context.Background(), sendReq, opts)
+		if err != nil {
+			return fmt.Errorf("baseline request failed: %w", err)
+		}
+		if len(suites) == 0 {
+			return printReplayComparison(req, baseline)
+		}
+		for i := range probes {
+			runReplayProbe(&probes[i], baseline, opts)
+		}
+
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"id":       req.ID,
+				"suites":   suites,
+				"baseline": map[string]interface{}{"status": baseline.Status, "size": baseline.Size},
+				"probes":   probes,
+				"injected": injectedVars(req, replayUseVars),
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		printReplaySuite(req, baseline, probes)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+	replayCmd.Flags().StringVar(&replaySaved, "saved", "", "Read from saved session (ID or 'latest')")
+	replayCmd.Flags().BoolVar(&replayUseVars, "use-vars", false, "Resolve auth from environment / ~/.rep/auth-<domain>.env")
+	replayCmd.Flags().BoolVarP(&replayInsecure, "insecure", "k", false, "Skip TLS certificate verification")
+	replayCmd.Flags().StringVar(&replaySuites, "suite", "", "Comma-separated mutation suites: host-header, cors, method-override")
+	replayCmd.Flags().StringVar(&replayCanary, "canary", "rep-canary.example", "Foreign host used by the host-header and cors suites")
+	replayCmd.Flags().BoolVar(&replayDryRun, "dry-run", false, "List the suite's probes without sending them")
+	replayCmd.Flags().BoolVar(&replayEdit, "edit", false, "Edit the raw request in $EDITOR, send it and diff the response; repeats until q")
+	replayCmd.Flags().StringVar(&replayRawFile, "raw-file", "", "Send this raw HTTP request instead (- for stdin)")
+	replayCmd.Flags().StringArrayVarP(&replayHeaderFlags, "header", "H", nil, "Set a header, 'Name: value' ('Name:' removes it); repeatable")
+	replayCmd.Flags().StringVarP(&replayMethod, "method", "X", "", "Send with this method")
+	replayCmd.Flags().StringVar(&replayBody, "body", "", "Send this request body")
+	replayCmd.Flags().Float64Var(&replaySimilarity, "similarity-threshold", similarity.DefaultThreshold, "Similarity score (0-1) under which a response body counts as different")
+	replayCmd.Flags().IntVar(&replayDiffMax, "diff-lines", 80, "Body diff lines shown (0 = all)")
+}
+
+// replaySuiteProbes builds the mutated requests of one suite
+func replaySuiteProbes(suite string, req *store.Request, canary string) []ReplayProbe {
+	parsed, err := url.Parse(req.URL)
+	if err != nil || parsed.Host == "" {
+		return nil
+	}
+	host := parsed.Host
+	var probes []ReplayProbe
+
+	withHeaders := func(label string, pairs ...string) *ReplayProbe {
+		v := *req
+		v.Headers = store.CloneHeaders(req.Headers)
+		if v.Headers == nil {
+			v.Headers = store.HeaderMap{}
+		}
+		for i := 0; i+1 < len(pairs); i += 2 {
+			store.SetHeader(v.Headers, pairs[i], pairs[i+1])
+		}
+		probes = append(probes, ReplayProbe{Suite: suite, Label: label, request: &v})
+		return &probes[len(probes)-1]
+	}
+
+	switch suite {
+	case "host-header":
+		withHeaders("Host: "+canary, "Host", canary)
+		withHeaders("Host: localhost", "Host", "localhost")
+		withHeaders("Host: "+parsed.Hostname()+":1337", "Host", parsed.Hostname()+":1337")
+		for _, name := range []string{"X-Forwarded-Host", "X-Host", "X-Forwarded-Server", "X-Original-Host"} {
+			withHeaders(name+": "+canary, "Host", host, name, canary)
+		}
+		withHeaders("Forwarded: host="+canary, "Host", host, "Forwarded", "host="+canary)
+
+	case "cors":
+		label, _, _ := strings.Cut(canary, ".")
+		origins := []string{
+			"https://" + canary,
+			"null",
+			"https://" + parsed.Hostname() + "." + canary,
+		}
+		// Prefix and subdomain origins only make sense for named hosts
+		if net.ParseIP(parsed.Hostname()) == nil {
+			base := store.GetBaseDomain(parsed.Hostname())
+			origins = append(origins, "https://"+label+base, "https://"+label+"."+base)
+		}
+		origins = append(origins, "http://"+host)
+		for _, origin := range origins {
+			probe := withHeaders("Origin: "+origin, "Origin", origin)
+			probe.origin = origin
+		}
+
+	case "method-override":
+		for _, method := range replayOverrideMethods {
+			if strings.EqualFold(method, req.Method) {
+				continue
+			}
+			for _, name := range replayOverrideHeaders {
+				withHeaders(name+": "+method, name, method)
+			}
+			u := *parsed
+			q := u.Query()
+			q.Set("_method", method)
+			u.RawQuery = q.Encode()
+			v := *req
+			v.URL = u.String()
+			probes = append(probes, ReplayProbe{Suite: suite, Label: "?_method=" + method, request: &v})
+		}
+	}
+	return probes
+}
+
+// runReplayProbe sends one probe and diffs it against the baseline
+func runReplayProbe(p *ReplayProbe, baseline *replay.Result, opts replay.Options) {
+	result, err := replay.Send(context.Background(), p.request, opts)
+	if err != nil {
+		p.Error = err.Error()
+		return
+	}
+	p.Status = result.Status
+	p.Size = result.Size
+	comparison := similarity.Compare(baseline.Status, baseline.Body, result.Status, result.Body)
+	p.Diff, p.Similarity = replayDiff(comparison, baseline.Body, result), &comparison
+
+	switch p.Suite {
+	case "host-header":
+		if where := canaryReflection(result, strings.ToLower(replayCanary)); where != "" {
+			p.Anomaly = "canary reflected in " + where
+		} else if p.Diff == "status" {
+			p.Anomaly = fmt.Sprintf("status %d → %d", baseline.Status, result.Status)
+		}
+	case "cors":
+		allowed := store.HeaderFirst(result.Headers, "access-control-allow-origin")
+		credentials := strings.EqualFold(store.HeaderFirst(result.Headers, "access-control-allow-credentials"), "true")
+		if allowed != "" && allowed == p.origin {
+			p.Anomaly = "origin reflected in Access-Control-Allow-Origin"
+			if credentials {
+				p.Anomaly += " with credentials (high)"
+			}
+		}
+	case "method-override":
+		if p.Diff == "status" || p.Diff == "body" {
+			p.Anomaly = "override changed the response"
+			if p.Diff == "status" {
+				p.Anomaly = fmt.Sprintf("status %d → %d", baseline.Status, result.Status)
+			}
+		}
+	}
+}
+
+// replayDiff classifies how a response differs from the baseline: a changed
+// body scoring at least --similarity-threshold is only "similar"
+func replayDiff(c similarity.Comparison, baseBody string, result *replay.Result) string {
+	switch {
+	case c.StatusChanged:
+		return "status"
+	case c.Different(replaySimilarity):
+		return "body"
+	case result.Body != baseBody:
+		return "similar"
+	default:
+		return "same"
+	}
+}
+
+// canaryReflection reports where the canary host appears in a response
+func canaryReflection(result *replay.Result, canary string) string {
+	for _, name := range []string{"Location", "Link", "Refresh", "Content-Location"} {
+		if strings.Contains(strings.ToLower(store.HeaderFirst(result.Headers, name)), canary) {
+			return name
+		}
+	}
+	if strings.Contains(strings.ToLower(result.Body), canary) {
+		return "body"
+	}
+	return ""
+}
+
+func printReplayPlan(req *store.Request, probes []ReplayProbe) {
+	pterm.DefaultSection.Printf("Replay suite for %s\n", req.ID)
+	fmt.Printf("  %s %s\n\n", req.Method, output.SanitizeText(req.URL))
+	for _, p := range probes {
+		fmt.Printf("  %-16s %s\n", p.Suite, output.SanitizeText(p.Label))
+	}
+	fmt.Println()
+	fmt.Printf("Run without --dry-run to send %d requests + baseline\n", len(probes))
+}
+
+func printReplaySuite(req *store.Request, baseline *replay.Result, probes []ReplayProbe) {
+	pterm.DefaultSection.Printf("Replay suite for %s\n", req.ID)
+	fmt.Printf("  %s %s\n\n", req.Method, output.SanitizeText(req.URL))
+	fmt.Printf("  %-16s %-48s %6s %9s  %s\n", "SUITE", "PROBE", "STATUS", "SIZE", "DIFF")
+	fmt.Printf("  %-16s %-48s %6d %9s\n", "baseline", "(original)", baseline.Status, output.FormatBodySize(int(baseline.Size)))
+	anomalies := 0
+	for _, p := range probes {
+		label := truncateCell(output.SanitizeText(p.Label), 48)
+		if p.Error != "" {
+			fmt.Printf("  %-16s %-48s %6s  %s\n", p.Suite, label, "ERR", p.Error)
+			continue
+		}
+		line := fmt.Sprintf("  %-16s %-48s %6d %9s  %s", p.Suite, label, p.Status, output.FormatBodySize(int(p.Size)), p.Diff)
+		if p.Anomaly != "" {
+			anomalies++
+			line = pterm.Yellow(line + "  ★ " + p.Anomaly)
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+	if anomalies > 0 {
+		pterm.Warning.Printf("%d probe(s) behaved differently from the baseline\n", anomalies)
+	} else {
+		pterm.Info.Println("No anomalies: every probe matched the baseline")
+	}
+}
+
+// printReplayComparison reports a single replay against the captured response
+func printReplayComparison(req *store.Request, result *replay.Result) error {
+	capturedStatus, capturedBody := 0, ""
+	if req.Response != nil {
+		capturedStatus = req.Response.Status
+		capturedBody = store.ResponseBodyText(req)
+	}
+	comparison := similarity.Compare(capturedStatus, capturedBody, result.Status, result.Body)
+	diff := replayDiff(comparison, capturedBody, result)
+
+	if getOutputMode() == "json" {
+		out, _ := sonic.MarshalIndent(map[string]interface{}{
+			"id":              req.ID,
+			"captured_status": capturedStatus,
+			"status":          result.Status,
+			"size":            result.Size,
+			"duration_ms":     result.DurationMs(),
+			"diff":            diff,
+			"similarity":      comparison,
+			"injected":        injectedVars(req, replayUseVars),
+		}, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Printf("[%s] %s %s\n", req.ID, req.Method, output.SanitizeText(req.URL))
+	fmt.Printf("  captured %s → replayed %s in %dms (%s), %s (similarity %.2f)\n",
+		colorStatus(capturedStatus, fmt.Sprint(capturedStatus)), colorStatus(result.Status, fmt.Sprint(result.Status)),
+		result.DurationMs(), output.FormatBodySize(int(result.Size)), diff, comparison.Score)
+	if injected := injectedVars(req, replayUseVars); len(injected) > 0 {
+		fmt.Printf("  Injected: %s\n", formatInjectedVars(injected))
+	}
+	fmt.Printf("  Next: rep curl %s --exec --record   (full response, saved for rep body)\n", req.ID)
+	return nil
+}
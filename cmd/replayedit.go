@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/replay"
+	"github.com/repplus/rep-cli/internal/similarity"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/repplus/rep-cli/internal/textdiff"
+)
+
+var (
+	replayEdit        bool
+	replayRawFile     string
+	replayHeaderFlags []string
+	replayMethod      string
+	replayBody        string
+	replayDiffMax     int
+)
+
+// replayDiffContext is the number of unchanged lines around body changes
+const replayDiffContext = 3
+
+// replayEditing reports whether the request is changed before sending
+func replayEditing() bool {
+	return replayEdit || replayRawFile != "" || len(replayHeaderFlags) > 0 || replayMethod != "" || replayBody != ""
+}
+
+// runReplayEdit sends the patched or edited request and diffs the response
+// against the capture; with --edit, repeats until the user quits
+func runReplayEdit(req, sendReq *store.Request) error {
+	current, err := patchReplayRequest(sendReq)
+	if err != nil {
+		return err
+	}
+	if replayRawFile != "" {
+		raw, err := readRawFile(replayRawFile)
+		if err != nil {
+			return err
+		}
+		if current, err = parseRawRequest(raw, current); err != nil {
+			return parseError("%s: %v", replayRawFile, err)
+		}
+	}
+	opts := replay.Options{Insecure: replayInsecure}
+
+	if !replayEdit {
+		result, err := replay.Send(context.Background(), current, opts)
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err)
+		}
+		return printReplayEdit(req, current, result)
+	}
+
+	if getOutputMode() == "json" {
+		return usageError("--edit is interactive; use --raw-file, --header, --method or --body with -o json")
+	}
+	if !stdinIsTerminal() || !stdoutIsTerminal() {
+		return usageError("--edit needs a terminal; pipe the edited request with --raw-file - instead")
+	}
+	prompt := bufio.NewReader(os.Stdin)
+	for {
+		raw, err := formatRawRequest(current.Method, current.URL, rawHeaders(current.Headers), current.Body)
+		if err != nil {
+			return err
+		}
+		edited, err := editText(raw, "rep-"+req.ID+"-*.http")
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(edited) == "" {
+			pterm.Info.Println("Empty request, nothing sent")
+			return nil
+		}
+		next, err := parseRawRequest(edited, current)
+		if err != nil {
+			pterm.Error.Println(err.Error())
+		} else {
+			current = next
+			result, err := replay.Send(context.Background(), current, opts)
+			if err != nil {
+				pterm.Error.Printf("Request failed: %v\n", err)
+			} else if err := printReplayEdit(req, current, result); err != nil {
+				return err
+			}
+		}
+
+		fmt.Print("\n[Enter] edit again, [q] quit: ")
+		answer, err := prompt.ReadString('\n')
+		if err != nil || strings.EqualFold(strings.TrimSpace(answer), "q") {
+			fmt.Println()
+			return nil
+		}
+	}
+}
+
+// patchReplayRequest applies --method, --header and --body to a copy of req
+func patchReplayRequest(req *store.Request) (*store.Request, error) {
+	patched := *req
+	patched.Headers = store.CloneHeaders(req.Headers)
+	if patched.Headers == nil {
+		patched.Headers = store.HeaderMap{}
+	}
+	if replayMethod != "" {
+		patched.Method = strings.ToUpper(replayMethod)
+	}
+	for _, h := range replayHeaderFlags {
+		name, value, ok := strings.Cut(h, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, usageError("bad --header %q (use 'Name: value', or 'Name:' to remove)", h)
+		}
+		if value = strings.TrimSpace(value); value == "" {
+			store.DelHeader(patched.Headers, name)
+		} else {
+			store.SetHeader(patched.Headers, name, value)
+		}
+	}
+	if replayBody != "" {
+		patched.Body = replayBody
+	}
+	return &patched, nil
+}
+
+// readRawFile reads a raw request from a file, or stdin for "-"
+func readRawFile(path string) (string, error) {
+	if path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		return string(data), err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", notFoundError("file not found: %s", path)
+		}
+		return "", err
+	}
+	return string(data), nil
+}
+
+// parseRawRequest reads an HTTP/1.1 request as 'rep raw' writes it. The
+// scheme comes from base, the host from the Host header (or base), and
+// Content-Length is recomputed. One trailing newline, as editors add, is
+// dropped from the body unless base's body had it.
+func parseRawRequest(raw string, base *store.Request) (*store.Request, error) {
+	// Line endings are normalized in the head only; the body is kept as is
+	var head []string
+	rest := raw
+	for {
+		line, tail, found := strings.Cut(rest, "\n")
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" && len(head) > 0 {
+			rest = tail
+			break
+		}
+		if line != "" {
+			head = append(head, line)
+		}
+		rest = tail
+		if !found {
+			break
+		}
+	}
+	if len(head) == 0 {
+		return nil, fmt.Errorf("no request line")
+	}
+
+	parts := strings.Fields(head[0])
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("bad request line %q (want 'METHOD /path HTTP/1.1')", head[0])
+	}
+	req := *base
+	req.Method, req.Headers, req.Body = strings.ToUpper(parts[0]), store.HeaderMap{}, rest
+	if !strings.HasSuffix(base.Body, "\n") {
+		req.Body = strings.TrimSuffix(strings.TrimSuffix(req.Body, "\n"), "\r")
+	}
+
+	host := ""
+	for i, line := range head[1:] {
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("line %d: bad header %q (want 'Name: value')", i+2, line)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(name) {
+		case "host":
+			host = value
+		case "content-length":
+		default:
+			req.Headers[name] = append(req.Headers[name], value)
+		}
+	}
+
+	target := parts[1]
+	if !strings.Contains(target, "://") {
+		baseURL, err := url.Parse(base.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL: %s", base.URL)
+		}
+		if host == "" {
+			host = baseURL.Host
+		}
+		if !strings.HasPrefix(target, "/") {
+			return nil, fmt.Errorf("bad request target %q", target)
+		}
+		target = baseURL.Scheme + "://" + host + target
+	}
+	if _, err := url.Parse(target); err != nil {
+		return nil, fmt.Errorf("bad request target %q: %v", parts[1], err)
+	}
+	req.URL = target
+	store.ComputeRequestFields(&req)
+	return &req, nil
+}
+
+// editText opens text in $VISUAL or $EDITOR and returns the saved result
+func editText(text, pattern string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return "", err
+	}
+	f.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	fields := strings.Fields(editor)
+	c := exec.Command(fields[0], append(fields[1:], f.Name())...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("editor %s failed: %w", fields[0], err)
+	}
+	data, err := os.ReadFile(f.Name())
+	return string(data), err
+}
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// replayBodyDiff diffs the captured and replayed bodies, JSON bodies
+// pretty-printed first so changes show per field
+func replayBodyDiff(captured, replayed string) ([]string, bool) {
+	ops, ok := textdiff.Lines(indentJSON(captured), indentJSON(replayed))
+	if !ok {
+		return nil, false
+	}
+	return textdiff.Unified(ops, replayDiffContext), true
+}
+
+func indentJSON(body string) string {
+	var b bytes.Buffer
+	if json.Indent(&b, []byte(strings.TrimSpace(body)), "", "  ") != nil {
+		return body
+	}
+	return b.String()
+}
+
+// printReplayEdit reports an edited replay against the captured response
+func printReplayEdit(req, sent *store.Request, result *replay.Result) error {
+	capturedStatus, capturedBody := 0, ""
+	if req.Response != nil {
+		capturedStatus = req.Response.Status
+		capturedBody = store.ResponseBodyText(req)
+	}
+	comparison := similarity.Compare(capturedStatus, capturedBody, result.Status, result.Body)
+	diff := replayDiff(comparison, capturedBody, result)
+	lines, diffed := replayBodyDiff(capturedBody, result.Body)
+
+	if getOutputMode() == "json" {
+		raw, _ := formatRawRequest(sent.Method, sent.URL, rawHeaders(sent.Headers), sent.Body)
+		payload := map[string]interface{}{
+			"id":              req.ID,
+			"request":         raw,
+			"captured_status": capturedStatus,
+			"status":          result.Status,
+			"size":            result.Size,
+			"duration_ms":     result.DurationMs(),
+			"diff":            diff,
+			"similarity":      comparison,
+			"injected":        injectedVars(req, replayUseVars),
+		}
+		if diffed {
+			if lines == nil {
+				lines = []string{}
+			}
+			payload["body_diff"] = lines
+		}
+		out, _ := sonic.MarshalIndent(payload, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Printf("[%s] %s %s\n", req.ID, sent.Method, output.SanitizeText(sent.URL))
+	fmt.Printf("  captured %s → replayed %s in %dms (%s), %s (similarity %.2f)\n",
+		colorStatus(capturedStatus, fmt.Sprint(capturedStatus)), colorStatus(result.Status, fmt.Sprint(result.Status)),
+		result.DurationMs(), output.FormatBodySize(int(result.Size)), diff, comparison.Score)
+	if injected := injectedVars(req, replayUseVars); len(injected) > 0 {
+		fmt.Printf("  Injected: %s\n", formatInjectedVars(injected))
+	}
+	switch {
+	case !diffed:
+		pterm.Info.Println("Bodies too large to diff line by line")
+	case len(lines) > 0:
+		fmt.Println()
+		shown := lines
+		if replayDiffMax > 0 && len(shown) > replayDiffMax {
+			shown = shown[:replayDiffMax]
+		}
+		for _, line := range shown {
+			text := output.SanitizeText(line)
+			switch line[0] {
+			case '@':
+				text = pterm.FgCyan.Sprint(text)
+			case '-':
+				text = pterm.FgRed.Sprint(text)
+			case '+':
+				text = pterm.FgGreen.Sprint(text)
+			}
+			fmt.Println("  " + text)
+		}
+		if len(shown) < len(lines) {
+			fmt.Printf("  ... %d more diff lines (--diff-lines 0 for all)\n", len(lines)-len(shown))
+		}
+	}
+	return nil
+}
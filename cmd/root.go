@@ -1,15 +1,26 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
+	"github.com/repplus/rep-cli/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// Global flags
-	outputMode string
-	jsonOutput bool
+	outputMode   string
+	jsonOutput   bool
+	truncateMode string
+	maxBodySize  int
+	tailSize     int
+	maxString    int
+	binaryLabel  string
+	liveSession  string
+	mappingFile  string
 )
 
 // rootCmd represents the base command
@@ -22,6 +33,7 @@ Designed for efficient analysis by AI agents like Claude Code.
 Works with rep+ Chrome extension for real-time traffic capture.
 
 AI Agent Workflow (token-optimized):
+  0. rep agent <target>                One call: JSON briefing + next commands
   1. rep summary                       First! Get landscape + ignore suggestions
   2. rep primary <target-domains>      Mark targets (enables --primary filter)
   3. rep ignore <suggested-domains>    Remove noise (from summary suggestions)
@@ -39,6 +51,14 @@ Token tips:
   - Use -o meta (headers only) for scanning, -o json for parsing
   - Use --limit N to cap results, output shows "[X of Y]" when truncated
   - Use rep auth --save + rep auth --vars to avoid copying huge cookies/tokens
+  - Use --max-tokens N (or REP_MAX_TOKENS) to fit list/body/summary into a
+    context budget; what was left out and how to fetch it is reported
+  - Use --envelope (or REP_ENVELOPE=1) for versioned JSON with warnings and
+    next steps; rep schema <command> prints its JSON Schema
+
+Exit codes (errors go to stderr, as JSON with -o json):
+  0 ok, 1 error, 2 usage, 3 not found, 4 empty result, 5 parse error,
+  6 warning. 4 and 6 only with --strict, which makes soft warnings fatal
 
 Real-time analysis (reads live.json, same as extension):
   rep summary                          Quick overview for first-pass analysis
@@ -52,33 +72,112 @@ Session management:
   rep sessions                         List saved sessions
   rep list --saved latest              View most recent saved session
   rep list --saved 20231227            View by session ID prefix
+  rep delete <id...> [--saved <id>]    Remove requests before sharing
+  rep sessions export <id> --out f     One session to a file (rep import f)
+  rep export --sanitized --mapping-out m   Share placeholders, keep the map
+  rep --mapping m replay <id>          Send with the original values
+  rep prune --older-than 30d --dry-run Retention: --keep-last, --max-size
+  rep archive --older-than 14d         Compress old sessions out of store.json
+  rep host-status                      Native host state, counters, settings
+
+Several browsers/profiles:
+  Each native host writes its own live-<id>.json; commands merge them all
+  rep sessions                         Lists live sessions (ID, profile)
+  rep list --session <id|profile>      Read (or save/delete/clear) just one
+
+Windows:
+  Data lives in %LOCALAPPDATA%\rep-cli (XDG_DATA_HOME still wins if set)
+  rep host-install --name <host> --extension-id <id>   Register rep-host
+  Generated commands use PowerShell quoting (curl.exe); --shell-syntax
+  (or $REP_SHELL) picks posix, powershell or cmd on any OS
 
 Configuration:
   rep ignore <domain>                  Ignore entire domain (broad filter)
   rep mute <domain/path>               Mute specific endpoint (fine filter)
   rep primary <domain>                 Mark domain as primary target
+  rep group create <name> <domain...>  Named domain group (filter with -d @name)
   rep clear                            Clear all data (live + saved + config)
 
+Shell completion (request/session IDs, domains and @groups from the store):
+  source <(rep completion bash)        Or: rep completion zsh|fish|powershell
+
+Try it offline:
+  rep demo --generate                  Synthetic dataset (no extension needed)
+  rep demo                             Walkthrough of commands to run on it
+
 Output modes (--output):
   compact   Truncated bodies, perfect for scanning (default)
   meta      Headers only, no bodies - ultra fast
   full      Complete bodies for deep analysis
-  json      Raw JSON for piping to other tools`,
+  json      Raw JSON for piping to other tools
+  ndjson    One JSON object per line (rep list, rep domains) - streams into jq
+  csv       Comma-separated metadata (rep list, rep domains) - for spreadsheets/awk
+
+Body truncation in compact mode (--truncate, or $REP_TRUNCATE):
+  head        First --max-body chars (default). JSON bodies stay valid JSON:
+              long strings, arrays and objects end with "…(+N chars|items|keys)"
+  head-tail   First chars ... last --tail chars (error details at the end)
+  strings     Like head, with JSON strings first cut to --max-string chars
+  keys        JSON structure only: {"id": number, "items": [...] (20 items)}
+  --binary-label off shows binary bodies instead of a [BINARY: ...] label
+  $REP_MAX_BODY, $REP_TAIL, $REP_MAX_STRING and $REP_BINARY_LABEL set defaults
+
+Truncation profiles (~/.rep/truncate.json, or $REP_TRUNCATE_CONFIG):
+  {"max_body": 800, "commands": {"list": {"max_body": 200},
+   "body": {"mode": "strings", "max_string": 200, "binary_label": false}}}
+  Keys: mode, max_body, tail, max_string, binary_label. The top level
+  applies to every command; environment variables and flags override both`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := parseTruncateMode(truncateMode); err != nil {
+			return err
+		}
+		if _, _, err := parseBinaryLabel(binaryLabel); err != nil {
+			return err
+		}
+		activeCommand = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+		profiles, err := loadTruncateProfiles()
+		if err != nil {
+			return err
+		}
+		loadedTruncateProfiles = profiles
+		if _, err := currentShellDialect(); err != nil {
+			return err
+		}
+		store.SelectLiveSession(liveSession)
+		return nil
+	},
 }
 
 // Execute adds all child commands to the root command
 func Execute() {
-	err := rootCmd.Execute()
-	if err != nil {
-		os.Exit(1)
+	registerCompletions(rootCmd)
+	classifyUsageErrors(rootCmd)
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+
+	if err := rootCmd.Execute(); err != nil {
+		code := exitCode(err)
+		printError(err, code)
+		os.Exit(code)
 	}
 }
 
 func init() {
 	rootCmd.Version = Version
 	rootCmd.SetVersionTemplate("{{.Version}}\n")
-	rootCmd.PersistentFlags().StringVarP(&outputMode, "output", "o", "compact", "Output mode: compact, meta, full, json")
+	rootCmd.PersistentFlags().StringVarP(&outputMode, "output", "o", "compact", "Output mode: compact, meta, full, json, ndjson, csv")
 	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output as JSON (shorthand for --output json)")
+	rootCmd.PersistentFlags().StringVar(&truncateMode, "truncate", "", "Body truncation: head, head-tail, strings, keys (default head)")
+	rootCmd.PersistentFlags().IntVar(&maxBodySize, "max-body", 0, "Max body chars in compact mode (default 500)")
+	rootCmd.PersistentFlags().IntVar(&tailSize, "tail", 0, "Chars kept from the end with --truncate head-tail (default 200)")
+	rootCmd.PersistentFlags().IntVar(&maxString, "max-string", 0, "Chars kept per JSON string with --truncate strings (default 80)")
+	rootCmd.PersistentFlags().StringVar(&binaryLabel, "binary-label", "", "Show binary bodies as a [BINARY: size type] label: on, off (default on)")
+	rootCmd.PersistentFlags().StringVar(&shellSyntax, "shell-syntax", "", "Shell for generated commands: posix, powershell, cmd (default posix; powershell on Windows)")
+	rootCmd.PersistentFlags().StringVar(&liveSession, "session", "", "Live session (browser/profile) to read, by ID or profile (default: merge all)")
+	rootCmd.PersistentFlags().BoolVar(&strictMode, "strict", false, "Fail on warnings and empty results too (exit 3 not found, 4 empty, 5 parse error, 6 other warning)")
+	rootCmd.PersistentFlags().IntVar(&maxTokens, "max-tokens", 0, "Approximate token budget for list, body and summary: trims bodies, headers and rows to fit (or $REP_MAX_TOKENS; 0 = unlimited)")
+	rootCmd.PersistentFlags().BoolVar(&envelopeOutput, "envelope", false, "Wrap -o json output in a versioned envelope with warnings and next steps (or $REP_ENVELOPE=1; see 'rep schema')")
+	rootCmd.PersistentFlags().StringVar(&mappingFile, "mapping", "", "Placeholder mapping from 'rep export --sanitized --mapping-out': requests rep sends get the original values back (or $REP_MAPPING)")
 }
 
 // getOutputMode returns the current output mode
@@ -88,3 +187,61 @@ func getOutputMode() string {
 	}
 	return outputMode
 }
+
+// truncateConfig returns the body truncation settings: defaults, overridden
+// by ~/.rep/truncate.json (its top level, then the running command's
+// profile), by $REP_TRUNCATE/$REP_MAX_BODY/$REP_TAIL/$REP_MAX_STRING/
+// $REP_BINARY_LABEL, and by flags.
+func truncateConfig() store.TruncateConfig {
+	cfg := store.DefaultTruncateConfig()
+	applyTruncateProfiles(&cfg)
+
+	if mode, err := parseTruncateMode(os.Getenv("REP_TRUNCATE")); err == nil && mode != "" {
+		cfg.Mode = mode
+	}
+	if n, err := strconv.Atoi(os.Getenv("REP_MAX_BODY")); err == nil && n > 0 {
+		cfg.MaxBodySize = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("REP_TAIL")); err == nil && n > 0 {
+		cfg.TailSize = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("REP_MAX_STRING")); err == nil && n > 0 {
+		cfg.MaxString = n
+	}
+	if set, label, err := parseBinaryLabel(os.Getenv("REP_BINARY_LABEL")); err == nil && set {
+		cfg.BinaryAsLabel = label
+	}
+
+	if mode, _ := parseTruncateMode(truncateMode); mode != "" {
+		cfg.Mode = mode
+	}
+	if maxBodySize > 0 {
+		cfg.MaxBodySize = maxBodySize
+	}
+	if tailSize > 0 {
+		cfg.TailSize = tailSize
+	}
+	if maxString > 0 {
+		cfg.MaxString = maxString
+	}
+	if set, label, _ := parseBinaryLabel(binaryLabel); set {
+		cfg.BinaryAsLabel = label
+	}
+	return cfg
+}
+
+// parseTruncateMode validates a --truncate value ("" means unset)
+func parseTruncateMode(value string) (store.TruncateMode, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return "", nil
+	}
+	names := make([]string, 0, len(store.TruncateModes))
+	for _, mode := range store.TruncateModes {
+		if string(mode) == value {
+			return mode, nil
+		}
+		names = append(names, string(mode))
+	}
+	return "", fmt.Errorf("invalid truncate mode: %s (use %s)", value, strings.Join(names, ", "))
+}
@@ -19,42 +19,41 @@ var saveCmd = &cobra.Command{
 	Short: "Save current live session to archive",
 	Long: `Save the current live.json session to store.json as a named session.
 
-The live session remains intact after saving.
+The live session remains intact after saving. When several browsers or
+profiles are capturing, their live sessions are saved together; use
+--session <id|profile> to save just one.
+
+The native host can also save automatically: set REP_AUTOSAVE_REQUESTS=<n>
+and/or REP_AUTOSAVE_AGE=<duration> (e.g. 5000, 2h) in its environment and it
+snapshots live.json into an "autosave" session, then starts it afresh,
+instead of dropping the oldest requests once 10,000 are captured.
 Use 'rep list --saved <id>' to view saved sessions.
 Use 'rep sessions' to list all saved sessions.
 
 Examples:
   rep save                    Save with auto-generated ID (timestamp)
   rep save --note "auth flow" Save with descriptive note in ID
+  rep save --session work     Save only the "work" profile's live session
   rep save -o json            JSON output for agents`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		livePath, err := store.GetLiveFilePath()
+		livePaths, err := store.GetLiveFilePaths()
 		if err != nil {
 			return err
 		}
 
 		// Check if file exists
-		if _, err := os.Stat(livePath); os.IsNotExist(err) {
-			pterm.Warning.Printf("Live file not found: %s\n", livePath)
-			pterm.Info.Println("Enable auto-export in rep+ extension first")
-			return nil
+		if _, err := os.Stat(livePaths[0]); len(livePaths) == 1 && os.IsNotExist(err) {
+			return softFail(notFoundError("Live file not found: %s", livePaths[0]).withHint("Enable auto-export in rep+ extension first"))
 		}
 
-		// Read file
-		data, err := os.ReadFile(livePath)
+		// Read all live sessions in scope (--session picks one browser)
+		export, err := loadLiveMerged()
 		if err != nil {
-			return fmt.Errorf("failed to read file: %w", err)
-		}
-
-		// Parse export
-		var export store.Export
-		if err := sonic.Unmarshal(data, &export); err != nil {
-			return fmt.Errorf("failed to parse JSON: %w", err)
+			return fmt.Errorf("failed to read live data: %w", err)
 		}
 
 		if len(export.Requests) == 0 {
-			pterm.Info.Println("No requests to save (live session is empty)")
-			return nil
+			return softFail(emptyError("No requests to save (live session is empty)"))
 		}
 
 		// Load store
@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/jsonpath"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/schema"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var schemaInferSaved string
+
+// InferredEndpoint is the schema inferred from one endpoint's bodies
+type InferredEndpoint struct {
+	Endpoint    string                   `json:"endpoint"` // "METHOD host/path/{id}"
+	Requests    int                      `json:"requests"`
+	RequestBody *InferredBody            `json:"request_body,omitempty"`
+	Responses   map[string]*InferredBody `json:"responses"` // By status code
+}
+
+// InferredBody is the schema merged from the JSON bodies of one kind
+type InferredBody struct {
+	Samples int            `json:"samples"`            // JSON bodies merged
+	NonJSON int            `json:"non_json,omitempty"` // Non-empty bodies that weren't JSON
+	Schema  schema.Schema  `json:"schema,omitempty"`
+	Fields  []schema.Field `json:"fields,omitempty"`
+
+	inferrer *schema.Inferrer
+}
+
+// endpointMatcher selects the requests of an endpoint; empty parts match
+// anything
+type endpointMatcher struct {
+	method   string
+	domain   string
+	template string
+}
+
+var schemaInferCmd = &cobra.Command{
+	Use:   "schema-infer <endpoint|request-id>",
+	Short: "Infer a JSON schema from every body an endpoint sent and received",
+	Long: `Merge all observed JSON request and response bodies of an endpoint into
+an inferred JSON Schema: field types, which fields are optional (missing
+from some bodies), nullable fields, and enums (strings seen repeatedly with
+few distinct values). Responses are inferred per status code.
+
+Use it as a starting point for an OpenAPI spec, or to spot fields the
+documentation or the UI doesn't show.
+
+The endpoint is a request ID (its method, host and path template), or
+[METHOD] [host]/path. Numeric, UUID and token path segments match {id}, so
+/v1/users/42 and /v1/users/{id} are the same endpoint. Without a method or
+host, every matching endpoint gets its own schema.
+
+Examples:
+  rep schema-infer req_42
+  rep schema-infer 'GET /v1/users/{id}'
+  rep schema-infer 'POST api.example.com/v1/orders'
+  rep schema-infer /v1/orders --saved latest
+  rep schema-infer req_42 -o json | jq '.[0].responses["200"].schema'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		matcher, err := endpointArg(strings.TrimSpace(args[0]), schemaInferSaved)
+		if err != nil {
+			return err
+		}
+
+		tempStore, err := loadSourceStore(schemaInferSaved)
+		if err != nil || tempStore == nil {
+			return err
+		}
+		endpoints := inferEndpoints(tempStore.Filter(store.FilterOptions{}), matcher)
+
+		if getOutputMode() == "json" {
+			if endpoints == nil {
+				endpoints = []*InferredEndpoint{}
+			}
+			printJSON("schema-infer", endpoints)
+			return nil
+		}
+		if len(endpoints) == 0 {
+			return softFail(emptyError("No requests to %s", args[0]))
+		}
+		for _, e := range endpoints {
+			printInferredEndpoint(e)
+		}
+		return nil
+	},
+}
+
+// endpointArg parses a request ID or "[METHOD] [host]/path"
+func endpointArg(arg, saved string) (endpointMatcher, error) {
+	if !strings.ContainsAny(arg, "/ ") {
+		req, err := lookupRequest(arg, saved)
+		if err != nil {
+			return endpointMatcher{}, err
+		}
+		if req == nil {
+			return endpointMatcher{}, notFoundError("request not found: %s", arg).
+				withHint("Give a request ID or an endpoint like 'GET /v1/users/{id}'")
+		}
+		return endpointMatcher{
+			method:   req.Method,
+			domain:   req.Domain,
+			template: store.EndpointTemplate(req.Path),
+		}, nil
+	}
+
+	var m endpointMatcher
+	target := arg
+	if method, rest, ok := strings.Cut(arg, " "); ok {
+		m.method = strings.ToUpper(method)
+		target = strings.TrimSpace(rest)
+	}
+	if strings.Contains(target, "://") {
+		u, err := url.Parse(target)
+		if err != nil {
+			return m, usageError("bad endpoint URL %q: %v", target, err)
+		}
+		m.domain, target = u.Hostname(), u.EscapedPath()
+	} else if !strings.HasPrefix(target, "/") {
+		host, path, _ := strings.Cut(target, "/")
+		m.domain, target = host, "/"+path
+	}
+	m.template = store.EndpointTemplate(target)
+	return m, nil
+}
+
+func (m endpointMatcher) matches(req *store.Request) bool {
+	return (m.method == "" || strings.EqualFold(req.Method, m.method)) &&
+		(m.domain == "" || strings.EqualFold(req.Domain, m.domain)) &&
+		store.EndpointTemplate(req.Path) == m.template
+}
+
+// inferEndpoints infers a schema per endpoint from the matching requests,
+// endpoints ordered by request count
+func inferEndpoints(requests []store.Request, m endpointMatcher) []*InferredEndpoint {
+	byKey := make(map[string]*InferredEndpoint)
+	var endpoints []*InferredEndpoint
+	for i := range requests {
+		req := &requests[i]
+		if !m.matches(req) {
+			continue
+		}
+		key := uniqueKey(req, true)
+		e, ok := byKey[key]
+		if !ok {
+			e = &InferredEndpoint{Endpoint: key, Responses: map[string]*InferredBody{}}
+			byKey[key] = e
+			endpoints = append(endpoints, e)
+		}
+		e.Requests++
+
+		if req.Body != "" {
+			if e.RequestBody == nil {
+				e.RequestBody = &InferredBody{}
+			}
+			e.RequestBody.add(req.Body)
+		}
+		if req.Response != nil {
+			status := strconv.Itoa(req.Response.Status)
+			body := e.Responses[status]
+			if body == nil {
+				body = &InferredBody{}
+				e.Responses[status] = body
+			}
+			body.add(store.ResponseBodyText(req))
+		}
+	}
+
+	for _, e := range endpoints {
+		e.RequestBody.finish()
+		for _, body := range e.Responses {
+			body.finish()
+		}
+	}
+	sort.SliceStable(endpoints, func(i, j int) bool { return endpoints[i].Requests > endpoints[j].Requests })
+	return endpoints
+}
+
+// add merges a body if it is JSON
+func (b *InferredBody) add(body string) {
+	if strings.TrimSpace(body) == "" {
+		return
+	}
+	doc, err := jsonpath.Decode(body)
+	if err != nil {
+		b.NonJSON++
+		return
+	}
+	if b.inferrer == nil {
+		b.inferrer = schema.NewInferrer()
+	}
+	b.inferrer.Add(doc)
+	b.Samples++
+}
+
+func (b *InferredBody) finish() {
+	if b == nil || b.inferrer == nil {
+		return
+	}
+	b.Schema = b.inferrer.Schema()
+	b.Fields = b.inferrer.Fields()
+}
+
+func printInferredEndpoint(e *InferredEndpoint) {
+	pterm.DefaultSection.Printf("%s (%d requests)\n", output.SanitizeText(e.Endpoint), e.Requests)
+	printInferredBody("Request body", e.RequestBody)
+
+	statuses := make([]string, 0, len(e.Responses))
+	for status := range e.Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		printInferredBody("Response "+status, e.Responses[status])
+	}
+	fmt.Println()
+}
+
+func printInferredBody(title string, b *InferredBody) {
+	switch {
+	case b == nil || b.Samples+b.NonJSON == 0:
+		fmt.Printf("  %s: none\n", title)
+		return
+	case b.Samples == 0:
+		fmt.Printf("  %s: %d non-JSON bodies\n", title, b.NonJSON)
+		return
+	}
+	note := ""
+	if b.NonJSON > 0 {
+		note = fmt.Sprintf(", %d non-JSON", b.NonJSON)
+	}
+	fmt.Printf("  %s (%d JSON bodies%s):\n", title, b.Samples, note)
+
+	width := 0
+	for _, f := range b.Fields {
+		if len(f.Path) > width && len(f.Path) <= 48 {
+			width = len(f.Path)
+		}
+	}
+	for _, f := range b.Fields {
+		line := strings.Join(f.Types, "|")
+		if f.Optional {
+			line += pterm.FgYellow.Sprint(" optional")
+		}
+		if len(f.Enum) > 0 {
+			line += pterm.FgCyan.Sprint(" enum: " + output.SanitizeText(strings.Join(f.Enum, ", ")))
+		}
+		fmt.Printf("    %-*s  %s\n", width, output.SanitizeText(f.Path), line)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(schemaInferCmd)
+	schemaInferCmd.Flags().StringVar(&schemaInferSaved, "saved", "", "Read from saved session (ID, prefix, or 'latest')")
+}
@@ -0,0 +1,492 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/repplus/rep-cli/internal/webui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveListen string
+	serveCORS   string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve [--listen 127.0.0.1:7777]",
+	Short: "Serve captures over a local read-only JSON API and web UI",
+	Long: `Expose the live capture and saved sessions over a small read-only JSON
+HTTP API, for web UIs, notebooks and other tools that would otherwise
+shell out to the CLI. Every call reads the current data, so new captures
+and sessions show up without restarting.
+
+The root URL serves a minimal capture browser built on the API: request
+table with filters, detail pane with pretty-printed bodies, and copy as
+curl. A lightweight alternative to opening Burp just to look at traffic.
+
+Endpoints (GET only):
+  /                          Capture browser
+  /api                       Index: version and endpoints
+  /api/requests              List/filter requests (like rep list -o json)
+  /api/requests/<id>         One request with bodies
+  /api/requests/<id>/body    Response body (?part=request for the request's)
+  /api/requests/<id>/curl    curl command (?shell=posix|powershell|cmd, ?use-vars)
+  /api/sessions              Saved, archived and live sessions
+  /api/domains               Domains with stats (?all, ?primary, ?ignored)
+  /api/summary               Traffic overview (like rep summary -o json)
+
+/api/requests takes the filter flags of 'rep list' as query parameters,
+with the same names and values (domain, method, status-range, pattern,
+api, errors, since, req-header, body-pattern, ...), plus limit, offset,
+sort, desc and bodies (include request/response bodies; off by default).
+primary (default true, like rep list) only applies once primary domains
+are set.
+Every endpoint takes saved=<id|latest> to read a saved session instead
+of the live capture.
+
+Captured traffic holds live credentials: keep the listener on loopback.
+Requests whose Host header isn't the listen address or localhost are
+refused (DNS rebinding), and no CORS header is sent unless --cors names
+the origin of a UI that should read the API.
+
+Examples:
+  rep serve                                  Then open http://127.0.0.1:7777/
+  rep serve --listen 127.0.0.1:8080 --cors http://localhost:5173
+  curl -s 'localhost:7777/api/requests?domain=api.acme.test&errors&limit=20'
+  curl -s 'localhost:7777/api/requests/h_abc123/body' | jq -r .body
+  curl -s 'localhost:7777/api/summary?saved=latest'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, _, err := net.SplitHostPort(serveListen)
+		if err != nil {
+			return fmt.Errorf("invalid --listen: %w", err)
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			pterm.Warning.Printf("Listening on %s: captured credentials are readable from the network\n", serveListen)
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api", serveIndex)
+		mux.HandleFunc("/api/requests", serveRequests)
+		mux.HandleFunc("/api/requests/", serveRequest)
+		mux.HandleFunc("/api/sessions", serveSessions)
+		mux.HandleFunc("/api/domains", serveDomains)
+		mux.HandleFunc("/api/summary", serveSummary)
+		mux.Handle("/", webui.Handler())
+
+		listener, err := net.Listen("tcp", serveListen)
+		if err != nil {
+			return err
+		}
+		server := &http.Server{
+			Handler:           serveGuard(mux),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		pterm.Success.Printf("Serving on http://%s/ (API under /api, Ctrl-C to stop)\n", listener.Addr())
+		err = server.Serve(listener)
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:7777", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveCORS, "cors", "", "Origin allowed to read the API from a browser (e.g. http://localhost:5173, * for any)")
+}
+
+// serveGuard rejects writes and foreign Host headers, and adds CORS headers
+func serveGuard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !serveHostAllowed(r.Host) {
+			writeServeError(w, http.StatusForbidden, "host not allowed: "+r.Host)
+			return
+		}
+		if serveCORS != "" {
+			w.Header().Set("Access-Control-Allow-Origin", serveCORS)
+			w.Header().Set("Vary", "Origin")
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			next.ServeHTTP(w, r)
+		case http.MethodOptions:
+			w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+			writeServeError(w, http.StatusMethodNotAllowed, "read-only API")
+		}
+	})
+}
+
+// serveHostAllowed reports whether a Host header names this server: the
+// listen host or a loopback name
+func serveHostAllowed(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+	listenHost, _, _ := net.SplitHostPort(serveListen)
+	return listenHost != "" && strings.EqualFold(host, listenHost)
+}
+
+func writeServeJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, err := sonic.MarshalIndent(v, "", "  ")
+	if err != nil {
+		status = http.StatusInternalServerError
+		data = []byte(`{"error": "failed to encode response"}`)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
+
+func writeServeError(w http.ResponseWriter, status int, msg string) {
+	writeServeJSON(w, status, map[string]string{"error": msg})
+}
+
+// serveStore loads the persistent store fresh for each call, so sessions
+// saved and lists changed while serving are picked up
+func serveStore() (*store.Store, error) {
+	s, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load store: %w", err)
+	}
+	return s, nil
+}
+
+// serveSavedStore returns a temp store over a saved session, with the
+// persistent lists applied
+func serveSavedStore(persistent *store.Store, saved string) (*store.Store, error) {
+	session := resolveSession(persistent, saved)
+	if session == nil {
+		return nil, fmt.Errorf("session not found: %s", saved)
+	}
+	tempStore := store.NewTempStore(session.Requests)
+	tempStore.PrimaryDomains = persistent.PrimaryDomains
+	tempStore.IgnoredDomains = persistent.IgnoredDomains
+	tempStore.MutedPaths = persistent.MutedPaths
+	return tempStore, nil
+}
+
+// serveLiveStore returns a temp store over the live capture without bodies
+// (empty when nothing was captured yet)
+func serveLiveStore(persistent *store.Store) (*store.Store, error) {
+	livePaths, err := store.GetLiveFilePaths()
+	if err != nil {
+		return nil, err
+	}
+	var requests []store.Request
+	if len(livePaths) > 1 || fileExists(livePaths[0]) {
+		if requests, err = store.LoadLiveWithoutBodiesAll(livePaths); err != nil {
+			return nil, fmt.Errorf("could not read live.json: %w", err)
+		}
+	}
+	tempStore := store.NewTempStore(requests)
+	tempStore.PrimaryDomains = persistent.PrimaryDomains
+	tempStore.IgnoredDomains = persistent.IgnoredDomains
+	tempStore.MutedPaths = persistent.MutedPaths
+	return tempStore, nil
+}
+
+func serveIndex(w http.ResponseWriter, r *http.Request) {
+	writeServeJSON(w, http.StatusOK, map[string]interface{}{
+		"name":    "rep",
+		"version": Version,
+		"endpoints": []string{
+			"/api/requests",
+			"/api/requests/{id}",
+			"/api/requests/{id}/body",
+			"/api/requests/{id}/curl",
+			"/api/sessions",
+			"/api/domains",
+			"/api/summary",
+		},
+	})
+}
+
+// requestQueryFlags parses /api/requests query parameters with the flags
+// of 'rep list', so both accept the same names and values
+func requestQueryFlags(r *http.Request) (store.FilterOptions, string, bool, error) {
+	var filter requestFilterFlags
+	var limit, offset int
+	var sortBy, saved string
+	var desc, bodies bool
+	c := &cobra.Command{}
+	filter.register(c)
+	c.Flags().IntVar(&limit, "limit", 0, "")
+	c.Flags().IntVar(&offset, "offset", 0, "")
+	c.Flags().StringVar(&sortBy, "sort", "", "")
+	c.Flags().BoolVar(&desc, "desc", false, "")
+	c.Flags().BoolVar(&bodies, "bodies", false, "")
+	c.Flags().StringVar(&saved, "saved", "", "")
+
+	for name, values := range r.URL.Query() {
+		flag := c.Flags().Lookup(name)
+		if flag == nil {
+			return store.FilterOptions{}, "", false, fmt.Errorf("unknown parameter: %s", name)
+		}
+		for _, value := range values {
+			if value == "" && flag.Value.Type() == "bool" {
+				value = "true"
+			}
+			if err := c.Flags().Set(name, value); err != nil {
+				return store.FilterOptions{}, "", false, fmt.Errorf("invalid %s: %w", name, err)
+			}
+		}
+	}
+
+	opts, err := filter.options()
+	if err != nil {
+		return opts, "", false, err
+	}
+	opts.Limit, opts.Offset = limit, offset
+	opts.SortBy, opts.SortDesc = strings.ToLower(sortBy), desc
+	if err := store.ValidateSortField(opts.SortBy); err != nil {
+		return opts, "", false, err
+	}
+	return opts, saved, bodies, nil
+}
+
+func serveRequests(w http.ResponseWriter, r *http.Request) {
+	opts, saved, bodies, err := requestQueryFlags(r)
+	if err != nil {
+		writeServeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	persistent, err := serveStore()
+	if err != nil {
+		writeServeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// primary means nothing until primary domains are set
+	if len(persistent.PrimaryDomains) == 0 {
+		opts.PrimaryOnly = false
+	}
+
+	var res store.StreamResult
+	if saved != "" {
+		tempStore, err := serveSavedStore(persistent, saved)
+		if err != nil {
+			writeServeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		unlimited := opts
+		unlimited.Limit, unlimited.Offset, unlimited.SortBy = 0, 0, ""
+		res.Matched = len(tempStore.Filter(unlimited))
+		res.Requests = tempStore.Filter(opts)
+	} else {
+		livePaths, err := store.GetLiveFilePaths()
+		if err != nil {
+			writeServeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if len(livePaths) > 1 || fileExists(livePaths[0]) {
+			sopts := store.StreamOptions{SkipBodies: !bodies && !opts.NeedsBodies(), CountAll: true}
+			if res, err = persistent.FilterStreamFiles(livePaths, opts, sopts); err != nil {
+				writeServeError(w, http.StatusInternalServerError, "could not read live.json: "+err.Error())
+				return
+			}
+		}
+	}
+
+	requests := res.Requests
+	if requests == nil {
+		requests = []store.Request{}
+	}
+	if !bodies {
+		for i := range requests {
+			requests[i].Body = ""
+			if requests[i].Response != nil {
+				resp := *requests[i].Response
+				resp.Body, resp.Events = "", nil
+				requests[i].Response = &resp
+			}
+		}
+	}
+	writeServeJSON(w, http.StatusOK, map[string]interface{}{
+		"total":    res.Matched,
+		"offset":   opts.Offset,
+		"count":    len(requests),
+		"requests": requests,
+	})
+}
+
+// serveRequest handles /api/requests/<id> and /api/requests/<id>/body
+func serveRequest(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/requests/")
+	id, sub, _ := strings.Cut(rest, "/")
+	if id == "" || (sub != "" && sub != "body" && sub != "curl") {
+		writeServeError(w, http.StatusNotFound, "not found: "+r.URL.Path)
+		return
+	}
+	req, err := serveLookupRequest(id, r.URL.Query().Get("saved"))
+	if err != nil {
+		writeServeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if req == nil {
+		writeServeError(w, http.StatusNotFound, "request not found: "+id)
+		return
+	}
+	switch sub {
+	case "":
+		writeServeJSON(w, http.StatusOK, req)
+		return
+	case "curl":
+		serveCurl(w, r, req)
+		return
+	}
+
+	out := map[string]interface{}{
+		"id":     req.ID,
+		"method": req.Method,
+		"url":    req.URL,
+	}
+	switch r.URL.Query().Get("part") {
+	case "request":
+		out["type"] = "request"
+		out["body"] = req.Body
+	case "", "response":
+		out["type"] = "response"
+		if req.Response != nil {
+			out["status"] = req.Response.Status
+			out["body"] = req.Response.Body
+			out["headers"] = req.Response.Headers
+		}
+	default:
+		writeServeError(w, http.StatusBadRequest, "part must be request or response")
+		return
+	}
+	writeServeJSON(w, http.StatusOK, out)
+}
+
+// serveCurl answers /api/requests/<id>/curl with the command 'rep curl'
+// would print
+func serveCurl(w http.ResponseWriter, r *http.Request, req *store.Request) {
+	query := r.URL.Query()
+	shell := query.Get("shell")
+	if shell == "" {
+		shell = "posix"
+	}
+	dialect, err := shellDialectFor(shell, false)
+	if err != nil {
+		writeServeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	useVars := query.Has("use-vars") && query.Get("use-vars") != "false"
+	writeServeJSON(w, http.StatusOK, map[string]string{
+		"id":    req.ID,
+		"shell": dialect.Name,
+		"curl":  generateCurl(req, useVars, dialect),
+	})
+}
+
+// serveLookupRequest is lookupRequest over a freshly loaded store
+func serveLookupRequest(id, saved string) (*store.Request, error) {
+	if saved == "" {
+		if export, err := loadLiveMerged(); err == nil {
+			for i := range export.Requests {
+				if export.Requests[i].ID == id {
+					store.ComputeRequestFields(&export.Requests[i])
+					return &export.Requests[i], nil
+				}
+			}
+		}
+	}
+	persistent, err := serveStore()
+	if err != nil {
+		return nil, err
+	}
+	if saved != "" {
+		session := resolveSession(persistent, saved)
+		if session == nil {
+			return nil, fmt.Errorf("session not found: %s", saved)
+		}
+		for i := range session.Requests {
+			if session.Requests[i].ID == id {
+				store.ComputeRequestFields(&session.Requests[i])
+				return &session.Requests[i], nil
+			}
+		}
+		return nil, nil
+	}
+	return persistent.GetRequestFromSessions(id), nil
+}
+
+func serveSessions(w http.ResponseWriter, r *http.Request) {
+	persistent, err := serveStore()
+	if err != nil {
+		writeServeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	live, _ := store.ListLiveSessions()
+	writeServeJSON(w, http.StatusOK, sessionsJSON(persistent.ListSessions(), persistent.ListArchivedSessions(), live))
+}
+
+// serveSourceStore returns the temp store for ?saved=, or the live capture
+func serveSourceStore(w http.ResponseWriter, r *http.Request) (*store.Store, *store.Store, bool) {
+	persistent, err := serveStore()
+	if err != nil {
+		writeServeError(w, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+	var tempStore *store.Store
+	if saved := r.URL.Query().Get("saved"); saved != "" {
+		tempStore, err = serveSavedStore(persistent, saved)
+		if err != nil {
+			writeServeError(w, http.StatusNotFound, err.Error())
+			return nil, nil, false
+		}
+	} else if tempStore, err = serveLiveStore(persistent); err != nil {
+		writeServeError(w, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+	return tempStore, persistent, true
+}
+
+func serveDomains(w http.ResponseWriter, r *http.Request) {
+	tempStore, _, ok := serveSourceStore(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	flag := func(name string) bool {
+		if !query.Has(name) {
+			return false
+		}
+		on, err := strconv.ParseBool(query.Get(name))
+		return err != nil || on
+	}
+	domains := filterDomains(tempStore.GetDomains(), flag("all"), flag("primary"), flag("ignored"))
+	if domains == nil {
+		domains = []store.DomainInfo{}
+	}
+	writeServeJSON(w, http.StatusOK, domains)
+}
+
+func serveSummary(w http.ResponseWriter, r *http.Request) {
+	tempStore, persistent, ok := serveSourceStore(w, r)
+	if !ok {
+		return
+	}
+	writeServeJSON(w, http.StatusOK, buildSummary(tempStore, tempStore.GetDomains(), persistent))
+}
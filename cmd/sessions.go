@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/bytedance/sonic"
@@ -10,7 +11,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var sessionsLimit int
+var (
+	sessionsLimit     int
+	sessionsExportOut string
+)
 
 var sessionsCmd = &cobra.Command{
 	Use:   "sessions",
@@ -19,10 +23,17 @@ var sessionsCmd = &cobra.Command{
 
 Use 'rep list --saved <id>' to view a specific session.
 Use 'rep save' to save the current live session.
+Sessions moved out by 'rep archive' are listed separately and can still be
+read with --saved <id>.
+
+Live sessions (one per browser/profile running the extension) are listed
+too. Commands merge them by default; pick one with --session <id|profile>.
 
 Examples:
   rep sessions              List all sessions
-  rep sessions -o json      JSON output for agents`,
+  rep list --session work   Read only the "work" profile's live session
+  rep sessions -o json      JSON output for agents
+  rep sessions export latest --out auth-flow.json   Share one session`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		s, err := store.Get()
 		if err != nil {
@@ -30,8 +41,10 @@ Examples:
 		}
 
 		sessions := s.ListSessions()
+		archived := s.ListArchivedSessions()
+		live, _ := store.ListLiveSessions()
 
-		if len(sessions) == 0 {
+		if len(sessions) == 0 && len(archived) == 0 && len(live) == 0 {
 			pterm.Info.Println("No saved sessions")
 			pterm.Info.Println("Use 'rep save' to save the current live session")
 			return nil
@@ -44,21 +57,32 @@ Examples:
 		}
 
 		if getOutputMode() == "json" {
-			out := make([]map[string]interface{}, len(sessions))
-			for i, sess := range sessions {
-				out[i] = map[string]interface{}{
-					"id":        sess.ID,
-					"requests":  len(sess.Requests),
-					"note":      sess.Note,
-					"timestamp": sess.Timestamp,
-					"time":      time.UnixMilli(sess.Timestamp).Format(time.RFC3339),
-				}
-			}
+			out := sessionsJSON(sessions, archived, live)
 			data, _ := sonic.MarshalIndent(out, "", "  ")
 			fmt.Println(string(data))
 			return nil
 		}
 
+		if len(live) > 0 {
+			pterm.DefaultSection.Println("Live Sessions")
+			liveData := pterm.TableData{{"ID", "Requests", "Started At", "Profile", "State"}}
+			for _, l := range live {
+				state := "stopped"
+				if l.Running {
+					state = "running"
+				}
+				liveData = append(liveData, []string{
+					l.ID,
+					fmt.Sprintf("%d", liveSessionRequests(l)),
+					time.UnixMilli(l.StartedAt).Format("2006-01-02 15:04:05"),
+					l.Profile,
+					state,
+				})
+			}
+			pterm.DefaultTable.WithHasHeader().WithData(liveData).Render()
+			fmt.Println()
+		}
+
 		pterm.DefaultSection.Println("Saved Sessions")
 
 		tableData := pterm.TableData{{"ID", "Requests", "Saved At", "Note"}}
@@ -71,13 +95,33 @@ Examples:
 			})
 		}
 
-		pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+		if len(sessions) > 0 {
+			pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+		} else {
+			pterm.Info.Println("None in store.json")
+		}
 
 		// Show truncation indicator
 		if sessionsLimit > 0 && len(sessions) < totalCount {
 			fmt.Printf("\n[Showing %d of %d sessions]\n", len(sessions), totalCount)
 		}
 
+		if len(archived) > 0 {
+			fmt.Println()
+			pterm.DefaultSection.Println("Archived Sessions")
+			archiveData := pterm.TableData{{"ID", "Requests", "Saved At", "Size", "Note"}}
+			for _, a := range archived {
+				archiveData = append(archiveData, []string{
+					a.ID,
+					fmt.Sprintf("%d", a.Requests),
+					time.UnixMilli(a.Timestamp).Format("2006-01-02 15:04:05"),
+					formatByteSize(a.Bytes),
+					a.Note,
+				})
+			}
+			pterm.DefaultTable.WithHasHeader().WithData(archiveData).Render()
+		}
+
 		fmt.Println()
 		pterm.Info.Println("To view a session: rep list --saved <id>")
 
@@ -85,7 +129,119 @@ Examples:
 	},
 }
 
+// sessionsJSON lists saved, archived and live sessions as JSON objects
+func sessionsJSON(sessions []store.Session, archived []store.ArchivedSession, live []store.LiveSession) []map[string]interface{} {
+	out := make([]map[string]interface{}, len(sessions))
+	for i, sess := range sessions {
+		out[i] = map[string]interface{}{
+			"id":        sess.ID,
+			"requests":  len(sess.Requests),
+			"note":      sess.Note,
+			"timestamp": sess.Timestamp,
+			"time":      time.UnixMilli(sess.Timestamp).Format(time.RFC3339),
+		}
+	}
+	for _, a := range archived {
+		out = append(out, map[string]interface{}{
+			"id":        a.ID,
+			"requests":  a.Requests,
+			"note":      a.Note,
+			"timestamp": a.Timestamp,
+			"time":      time.UnixMilli(a.Timestamp).Format(time.RFC3339),
+			"archived":  true,
+			"bytes":     a.Bytes,
+		})
+	}
+	for _, l := range live {
+		out = append(out, map[string]interface{}{
+			"id":        l.ID,
+			"requests":  liveSessionRequests(l),
+			"profile":   l.Profile,
+			"timestamp": l.StartedAt,
+			"time":      time.UnixMilli(l.StartedAt).Format(time.RFC3339),
+			"live":      true,
+			"running":   l.Running,
+		})
+	}
+	return out
+}
+
+// liveSessionRequests returns a live session's request count as last
+// reported by its host (0 if unknown)
+func liveSessionRequests(l store.LiveSession) int {
+	path, err := store.LiveSessionPath(l)
+	if err != nil {
+		return 0
+	}
+	if status, err := store.LoadHostStatus(path); err == nil {
+		return status.LiveRequests
+	}
+	return 0
+}
+
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export <session-id>",
+	Short: "Export one saved session to a file",
+	Long: `Export a single saved session (ID, prefix, or 'latest') so it can be
+moved to another machine or shared without the rest of the store.
+
+The file keeps the session ID, note and save time. Load it elsewhere with
+'rep import <file>'. Without --out the session is written to stdout.
+
+Examples:
+  rep sessions export latest --out session.json
+  rep sessions export 20240115 > auth-flow.json
+  rep import session.json                 On the other machine`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := store.Get()
+		if err != nil {
+			return fmt.Errorf("failed to load store: %w", err)
+		}
+
+		session := resolveSession(s, args[0])
+		if session == nil {
+			return notFoundError("session not found: %s", args[0]).withHint("Use 'rep sessions' to list available sessions")
+		}
+
+		export := store.SessionExport{
+			Format:     store.SessionExportFormat,
+			Version:    "1.0",
+			ExportedAt: time.Now().Format(time.RFC3339),
+			Session:    *session,
+		}
+		data, err := sonic.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode session: %w", err)
+		}
+
+		if sessionsExportOut == "" {
+			fmt.Println(string(data))
+			return nil
+		}
+		if err := os.WriteFile(sessionsExportOut, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", sessionsExportOut, err)
+		}
+
+		if getOutputMode() == "json" {
+			result := map[string]interface{}{
+				"session_id": session.ID,
+				"requests":   len(session.Requests),
+				"path":       sessionsExportOut,
+			}
+			out, _ := sonic.MarshalIndent(result, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+		pterm.Success.Printf("Exported session %s (%d requests) to %s\n", session.ID, len(session.Requests), sessionsExportOut)
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(sessionsCmd)
 	sessionsCmd.Flags().IntVarP(&sessionsLimit, "limit", "l", 0, "Limit number of sessions shown (0=unlimited)")
+
+	sessionsCmd.AddCommand(sessionsExportCmd)
+	sessionsExportCmd.Flags().StringVar(&sessionsExportOut, "out", "", "Output file (default: stdout)")
 }
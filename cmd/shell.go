@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// shellSyntax is the --shell-syntax flag (posix, powershell, cmd)
+var shellSyntax string
+
+// shellDialect describes how generated commands are quoted for one shell
+type shellDialect struct {
+	Name     string
+	Curl     string // curl program; PowerShell aliases plain "curl" to Invoke-WebRequest
+	Continue string // Line continuation, including the newline
+	Comment  string // Line comment prefix
+	Quote    func(string) string
+	Template func(h replayHeader) string // Quotes a templated value, expanding its $VARIABLE references
+	SetVar   func(name, value string) string
+	LoadAuth string // How to load 'rep auth --export' into the shell
+}
+
+// currentShellDialect resolves --shell-syntax, then REP_SHELL. The default
+// is posix, or powershell on Windows.
+func currentShellDialect() (shellDialect, error) {
+	name := shellSyntax
+	if name == "" {
+		name = os.Getenv("REP_SHELL")
+	}
+	if name == "" {
+		name = "posix"
+		if runtime.GOOS == "windows" {
+			name = "powershell"
+		}
+	}
+	return shellDialectFor(name, false)
+}
+
+// shellDialectFor returns the dialect for a shell name. With script, cmd
+// quoting targets .bat files, where a literal % must be doubled.
+func shellDialectFor(name string, script bool) (shellDialect, error) {
+	switch strings.ToLower(name) {
+	case "posix", "sh", "bash", "zsh":
+		return shellDialect{
+			Name:     "posix",
+			Curl:     "curl",
+			Continue: " \\\n",
+			Comment:  "#",
+			Quote:    shellQuote,
+			Template: func(h replayHeader) string {
+				// Double quotes so the shell expands $VARIABLES
+				escape := strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "$", "\\$", "`", "\\`").Replace
+				return "\"" + renderTemplated(h, escape, func(name string) string { return "$" + name }, "") + "\""
+			},
+			SetVar:   func(name, value string) string { return fmt.Sprintf("export %s=%s", name, shellQuote(value)) },
+			LoadAuth: `eval "$(rep auth --export)"`,
+		}, nil
+	case "powershell", "pwsh", "ps":
+		return shellDialect{
+			Name:     "powershell",
+			Curl:     "curl.exe",
+			Continue: " `\n",
+			Comment:  "#",
+			Quote:    powershellQuote,
+			Template: func(h replayHeader) string {
+				// One expandable string: "text ${env:NAME}"
+				escape := strings.NewReplacer("`", "``", "\"", "`\"", "$", "`$").Replace
+				return "\"" + renderTemplated(h, escape, func(name string) string { return "${env:" + name + "}" }, "") + "\""
+			},
+			SetVar:   func(name, value string) string { return fmt.Sprintf("$env:%s = %s", name, powershellQuote(value)) },
+			LoadAuth: "rep auth --export --shell-syntax powershell | Out-String | Invoke-Expression",
+		}, nil
+	case "cmd", "bat", "batch":
+		escape := cmdEscape
+		if script {
+			escape = func(s string) string { return cmdEscape(strings.ReplaceAll(s, "%", "%%")) }
+		}
+		return shellDialect{
+			Name:     "cmd",
+			Curl:     "curl",
+			Continue: " ^\n",
+			Comment:  "REM",
+			Quote:    func(s string) string { return "\"" + escape(s) + "\"" },
+			Template: func(h replayHeader) string {
+				// One quoted string: "text %NAME%"
+				return "\"" + renderTemplated(h, escape, func(name string) string { return "%" + name + "%" }, "") + "\""
+			},
+			SetVar: func(name, value string) string {
+				// set "NAME=value" keeps everything up to the last quote, so
+				// quotes in the value stay literal
+				return fmt.Sprintf("set \"%s=%s\"", name, cmdCaretOutside(strings.ReplaceAll(value, "%", "%%")))
+			},
+			LoadAuth: "rep auth --export --shell-syntax cmd > auth.cmd && call auth.cmd",
+		}, nil
+	}
+	return shellDialect{}, fmt.Errorf("unsupported shell syntax: %s (use posix, powershell, cmd)", name)
+}
+
+// powershellQuote single-quotes s for PowerShell, where ” is a literal quote
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// cmdEscape escapes s for a double-quoted cmd.exe argument. Embedded quotes
+// are doubled: cmd.exe reads "" as leaving and re-entering the quoted string,
+// so & | < > ^ never end up outside quotes, and programs splitting their
+// command line with the MSVC rules read "" inside quotes as a literal quote.
+// Backslashes before a quote, or before the closing quote, are doubled for
+// the same rules.
+func cmdEscape(s string) string {
+	var b strings.Builder
+	slashes := 0
+	for _, r := range s {
+		switch r {
+		case '\\':
+			slashes++
+		case '"':
+			b.WriteString(strings.Repeat("\\", slashes))
+			b.WriteByte('"')
+			slashes = 0
+		default:
+			slashes = 0
+		}
+		b.WriteRune(r)
+	}
+	b.WriteString(strings.Repeat("\\", slashes))
+	return b.String()
+}
+
+// cmdCaretOutside escapes s for a cmd.exe line where it follows an opening
+// quote and is not otherwise quoted: each quote in s flips cmd.exe's quote
+// state, and the metacharacters that end up outside quotes get a caret so
+// they are taken literally
+func cmdCaretOutside(s string) string {
+	var b strings.Builder
+	quoted := true
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case !quoted && strings.ContainsRune("&|<>^()", r):
+			b.WriteByte('^')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
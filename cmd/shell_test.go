@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// cmdRunsMeta reports whether cmd.exe would act on a & | < > in line: one
+// outside quotes that no caret escapes
+func cmdRunsMeta(line string) bool {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '^':
+			i++
+		case strings.IndexByte("&|<>", c) >= 0:
+			return true
+		}
+	}
+	return false
+}
+
+// msvcArgs splits a command line with the MSVC runtime rules
+func msvcArgs(line string) []string {
+	var args []string
+	var cur strings.Builder
+	inArg, quoted := false, false
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == '\\':
+			n := 0
+			for i < len(line) && line[i] == '\\' {
+				n++
+				i++
+			}
+			if i < len(line) && line[i] == '"' {
+				cur.WriteString(strings.Repeat(`\`, n/2))
+				if n%2 == 1 {
+					cur.WriteByte('"')
+					i++
+				}
+			} else {
+				cur.WriteString(strings.Repeat(`\`, n))
+			}
+			inArg = true
+			continue
+		case c == '"':
+			inArg = true
+			if quoted && i+1 < len(line) && line[i+1] == '"' {
+				cur.WriteByte('"')
+				i += 2
+				continue
+			}
+			quoted = !quoted
+		case (c == ' ' || c == '\t') && !quoted:
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteByte(c)
+			inArg = true
+		}
+		i++
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args
+}
+
+func TestCmdQuote(t *testing.T) {
+	shell, err := shellDialectFor("cmd", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{
+		`"a&b|c"`,
+		`{"q":"x > y","n":1}`,
+		`a\"b`,
+		`C:\dir\`,
+		`"&calc&"`,
+		`^<>|&`,
+	} {
+		line := "curl -d " + shell.Quote(body)
+		if cmdRunsMeta(line) {
+			t.Errorf("%q: cmd.exe runs a metacharacter in %s", body, line)
+		}
+		if args := msvcArgs(line); len(args) != 3 || args[2] != body {
+			t.Errorf("%q: %s parses as %q", body, line, args)
+		}
+	}
+}
+
+func TestCmdSetVar(t *testing.T) {
+	shell, err := shellDialectFor("cmd", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []string{`"a&b|c"`, `a"&calc&"`, `x" > out.txt`} {
+		if line := shell.SetVar("TOKEN", value); cmdRunsMeta(line) {
+			t.Errorf("%q: cmd.exe runs a metacharacter in %s", value, line)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/replay"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+var jsSourcemaps bool
+
+// sourceMappingURLPattern matches the trailing //# sourceMappingURL=
+// comment (and the legacy //@ form)
+var sourceMappingURLPattern = regexp.MustCompile(`(?m)(?://|/\*)[#@]\s*sourceMappingURL=([^\s*'"]+)`)
+
+// sourceMap is the part of a v3 source map needed to recover sources
+type sourceMap struct {
+	Version        int       `json:"version"`
+	SourceRoot     string    `json:"sourceRoot"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent"`
+}
+
+// sourceMapResult is the outcome for one script
+type sourceMapResult struct {
+	Script  string   `json:"script"`
+	MapURL  string   `json:"map_url,omitempty"`
+	Via     string   `json:"via,omitempty"`     // comment, header, inline, sibling
+	Result  string   `json:"result"`            // unpacked, no_content, not_found, failed
+	Sources int      `json:"sources"`           // Sources listed in the map
+	Written []string `json:"written,omitempty"` // Files written, relative to the output directory
+	Error   string   `json:"error,omitempty"`
+}
+
+// unpackSourceMaps looks for a source map for each captured script,
+// fetches it and writes the original sources under dir
+func unpackSourceMaps(requests []store.Request, dir string) error {
+	var scripts []store.Request
+	seen := make(map[string]bool)
+	for _, req := range requests {
+		if seen[req.URL] || strings.HasSuffix(strings.ToLower(strings.SplitN(req.URL, "?", 2)[0]), ".map") {
+			continue
+		}
+		seen[req.URL] = true
+		scripts = append(scripts, req)
+	}
+
+	workers := jsConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]sourceMapResult, len(scripts))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = unpackSourceMap(&scripts[i], dir)
+			}
+		}()
+	}
+	for i := range scripts {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	counts := make(map[string]int)
+	files := 0
+	for _, r := range results {
+		counts[r.Result]++
+		files += len(r.Written)
+	}
+
+	if getOutputMode() == "json" {
+		out, _ := sonic.MarshalIndent(map[string]interface{}{
+			"dir":      dir,
+			"scripts":  len(scripts),
+			"unpacked": counts["unpacked"],
+			"files":    files,
+			"results":  results,
+		}, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	for _, r := range results {
+		switch r.Result {
+		case "unpacked":
+			fmt.Printf("  %s %s  %s\n", pterm.FgGreen.Sprint("✓"), output.SanitizeText(r.Script),
+				pterm.FgGray.Sprintf("%d sources via %s", len(r.Written), r.Via))
+		case "no_content":
+			fmt.Printf("  %s %s  %s\n", pterm.FgYellow.Sprint("~"), output.SanitizeText(r.Script),
+				pterm.FgGray.Sprintf("map lists %d sources but has no sourcesContent (%s)", r.Sources, r.MapURL))
+		case "failed":
+			fmt.Printf("  %s %s  %s\n", pterm.FgRed.Sprint("✗"), output.SanitizeText(r.Script),
+				pterm.FgGray.Sprintf("%s: %s", r.MapURL, r.Error))
+		default:
+			fmt.Printf("  %s %s\n", pterm.FgGray.Sprint("·"), pterm.FgGray.Sprint(output.SanitizeText(r.Script)))
+		}
+	}
+	fmt.Println()
+	if counts["unpacked"] == 0 {
+		return softFail(emptyError("No source maps recovered from %d scripts", len(scripts)))
+	}
+	pterm.Success.Printf("%d of %d scripts had source maps: %d original files -> %s\n", counts["unpacked"], len(scripts), files, dir)
+	fmt.Println("  Next: grep the recovered sources for secrets, routes and API paths")
+	return nil
+}
+
+// unpackSourceMap finds, fetches and unpacks the map of one script
+func unpackSourceMap(req *store.Request, dir string) sourceMapResult {
+	result := sourceMapResult{Script: req.URL, Result: "not_found"}
+
+	mapRef, via := findSourceMapRef(req)
+	var data []byte
+	if mapRef != "" {
+		var err error
+		result.Via = via
+		data, result.MapURL, err = loadSourceMap(mapRef, req.URL)
+		if err != nil {
+			result.Result, result.Error = "failed", err.Error()
+			return result
+		}
+	} else {
+		// Many builds ship maps without referencing them
+		sibling := strings.SplitN(req.URL, "?", 2)[0] + ".map"
+		body, err := fetchSourceMap(sibling)
+		if err != nil || !looksLikeSourceMap(body) {
+			return result
+		}
+		data, result.MapURL, result.Via = body, sibling, "sibling"
+	}
+
+	var sm sourceMap
+	if err := sonic.Unmarshal(data, &sm); err != nil || len(sm.Sources) == 0 {
+		result.Result, result.Error = "failed", "not a source map"
+		return result
+	}
+	result.Sources = len(sm.Sources)
+
+	base := filepath.Join(dir, safePathSegment(hostFromURL(req.URL)))
+	written := make(map[string]bool)
+	for i, source := range sm.Sources {
+		if i >= len(sm.SourcesContent) || sm.SourcesContent[i] == nil {
+			continue
+		}
+		rel := sourceMapFilePath(sm.SourceRoot + source)
+		for n := 2; written[rel]; n++ {
+			rel = fmt.Sprintf("%s~%d", sourceMapFilePath(sm.SourceRoot+source), n)
+		}
+		target := filepath.Join(base, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		if err := os.WriteFile(target, []byte(*sm.SourcesContent[i]), 0644); err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		written[rel] = true
+		result.Written = append(result.Written, filepath.ToSlash(filepath.Join(filepath.Base(base), rel)))
+	}
+	if len(result.Written) == 0 {
+		result.Result = "no_content"
+		return result
+	}
+	result.Result = "unpacked"
+	return result
+}
+
+// findSourceMapRef returns the map reference of a script from its
+// SourceMap header or trailing comment
+func findSourceMapRef(req *store.Request) (ref, via string) {
+	if req.Response == nil {
+		return "", ""
+	}
+	for _, name := range []string{"sourcemap", "x-sourcemap"} {
+		if value := store.HeaderFirst(req.Response.Headers, name); value != "" {
+			return strings.TrimSpace(value), "header"
+		}
+	}
+	matches := sourceMappingURLPattern.FindAllStringSubmatch(store.ResponseBodyText(req), -1)
+	if len(matches) == 0 {
+		return "", ""
+	}
+	// The last one wins, as in browsers (bundles may embed others)
+	ref = matches[len(matches)-1][1]
+	if strings.HasPrefix(ref, "data:") {
+		return ref, "inline"
+	}
+	return ref, "comment"
+}
+
+// loadSourceMap decodes an inline data: map or fetches it relative to the
+// script
+func loadSourceMap(ref, scriptURL string) ([]byte, string, error) {
+	if strings.HasPrefix(ref, "data:") {
+		meta, payload, ok := strings.Cut(ref[len("data:"):], ",")
+		if !ok {
+			return nil, "data:", fmt.Errorf("malformed data URL")
+		}
+		if strings.HasSuffix(meta, ";base64") {
+			decoded, err := base64.StdEncoding.DecodeString(payload)
+			if err != nil {
+				return nil, "data:", fmt.Errorf("bad base64: %w", err)
+			}
+			return decoded, "data:", nil
+		}
+		decoded, err := url.PathUnescape(payload)
+		if err != nil {
+			return nil, "data:", err
+		}
+		return []byte(decoded), "data:", nil
+	}
+
+	base, err := url.Parse(scriptURL)
+	if err != nil {
+		return nil, ref, err
+	}
+	resolved, err := base.Parse(ref)
+	if err != nil {
+		return nil, ref, err
+	}
+	mapURL := resolved.String()
+	body, err := fetchSourceMap(mapURL)
+	return body, mapURL, err
+}
+
+func fetchSourceMap(mapURL string) ([]byte, error) {
+	req := &store.Request{Method: "GET", URL: mapURL, Headers: store.HeaderMap{"Accept": {"application/json, */*"}}}
+	res, err := replay.Send(context.Background(), req, replay.Options{FollowRedirects: true, Insecure: jsInsecure})
+	if err != nil {
+		return nil, err
+	}
+	if res.Status != 200 {
+		return nil, fmt.Errorf("HTTP %d", res.Status)
+	}
+	if res.Truncated {
+		return nil, fmt.Errorf("larger than %d MB", replay.MaxBodySize/(1024*1024))
+	}
+	return []byte(res.Body), nil
+}
+
+// looksLikeSourceMap rejects HTML error pages served with 200 for any path
+func looksLikeSourceMap(body []byte) bool {
+	s := strings.TrimSpace(string(body))
+	s = strings.TrimPrefix(s, ")]}'") // XSSI guard some servers prepend
+	s = strings.TrimSpace(s)
+	return strings.HasPrefix(s, "{") && strings.Contains(s, `"sources"`) && strings.Contains(s, `"mappings"`)
+}
+
+// sourceMapFilePath turns a source entry (webpack://app/./src/x.ts,
+// ../node_modules/y.js) into a safe relative path
+func sourceMapFilePath(source string) string {
+	if scheme, rest, ok := strings.Cut(source, "://"); ok && !strings.ContainsAny(scheme, "/.") {
+		source = rest // webpack://, ng://, file:// ...
+	}
+	source = strings.SplitN(source, "?", 2)[0]
+
+	var segments []string
+	for _, segment := range strings.Split(filepath.ToSlash(source), "/") {
+		switch segment {
+		case "", ".":
+			continue
+		case "..":
+			// Kept visible instead of escaping the output directory
+			segments = append(segments, "__")
+			continue
+		}
+		segments = append(segments, safePathSegment(segment))
+	}
+	if len(segments) == 0 {
+		return "unnamed"
+	}
+	return strings.Join(segments, "/")
+}
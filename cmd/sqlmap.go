@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sqlmapSaved   string
+	sqlmapUseVars bool
+	sqlmapLevel   int
+	sqlmapRisk    int
+	sqlmapParam   string
+	sqlmapOut     string
+)
+
+var sqlmapCmd = &cobra.Command{
+	Use:   "sqlmap <request-id>",
+	Short: "Write a request file and print a ready-to-run sqlmap command",
+	Long: `Write the raw request to a file and print the sqlmap command that tests it:
+sqlmap -r <file> --batch with --level/--risk, --force-ssl for https and -p
+for the parameter under test.
+
+Without --use-vars the request file holds every captured header, cookies and
+tokens included (the file is created with 0600 permissions). With --use-vars
+credentials stay out of the file: Cookie is passed as --cookie and other
+auth headers as -H, both referencing the same shell variables as
+'rep curl --use-vars', to be loaded with 'rep auth --export'.
+
+When the request has no query or body parameters, a variable path segment
+(/users/42) is marked with sqlmap's * injection marker.
+
+Examples:
+  rep sqlmap h_abc123                         Temp request file + command
+  rep sqlmap h_abc123 -p id --level 3 --risk 2
+  rep sqlmap h_abc123 --use-vars              Keep credentials out of the file
+  rep sqlmap h_abc123 --out login.req         Choose the request file`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if sqlmapLevel < 1 || sqlmapLevel > 5 {
+			return fmt.Errorf("--level must be between 1 and 5")
+		}
+		if sqlmapRisk < 1 || sqlmapRisk > 3 {
+			return fmt.Errorf("--risk must be between 1 and 3")
+		}
+		shell, err := currentShellDialect()
+		if err != nil {
+			return err
+		}
+
+		req, err := lookupRequest(args[0], sqlmapSaved)
+		if err != nil {
+			return err
+		}
+		if req == nil {
+			return notFoundError("request not found: %s", args[0]).withHint("Use 'rep list' to see available request IDs")
+		}
+
+		// Templated (credential) headers go on the command line, the rest in the file
+		var fileHeaders, authHeaders []replayHeader
+		for _, h := range mergedReplayHeaders(req, sqlmapUseVars) {
+			if h.Templated {
+				authHeaders = append(authHeaders, h)
+			} else {
+				fileHeaders = append(fileHeaders, h)
+			}
+		}
+
+		params := sqlmapParameters(req)
+		target, marked := req.URL, ""
+		if len(params) == 0 {
+			target, marked = markInjectionPoint(req.URL)
+		}
+		content, err := formatRawRequest(req.Method, target, fileHeaders, req.Body)
+		if err != nil {
+			return err
+		}
+
+		path, err := writeSqlmapRequest(req.ID, content)
+		if err != nil {
+			return err
+		}
+
+		parts := []string{"sqlmap", "-r", shell.Quote(path), "--batch",
+			fmt.Sprintf("--level=%d", sqlmapLevel), fmt.Sprintf("--risk=%d", sqlmapRisk)}
+		if strings.HasPrefix(req.URL, "https://") {
+			parts = append(parts, "--force-ssl")
+		}
+		if sqlmapParam != "" {
+			parts = append(parts, "-p", shell.Quote(sqlmapParam))
+		}
+		for _, h := range authHeaders {
+			if strings.EqualFold(h.Name, "cookie") {
+				parts = append(parts, "--cookie="+shell.Template(h))
+				continue
+			}
+			h.Value = h.Name + ": " + h.Value
+			parts = append(parts, "-H", shell.Template(h))
+		}
+		command := strings.Join(parts, " ")
+
+		if getOutputMode() == "json" {
+			payload := map[string]interface{}{
+				"id":         req.ID,
+				"file":       path,
+				"command":    command,
+				"parameters": params,
+			}
+			if marked != "" {
+				payload["injection_marker"] = marked
+			}
+			if len(authHeaders) > 0 {
+				payload["load_auth"] = shell.LoadAuth
+			}
+			out, _ := sonic.MarshalIndent(payload, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		pterm.Success.Printf("Request written to %s\n", path)
+		if len(params) > 0 {
+			fmt.Printf("  Parameters: %s\n", strings.Join(params, ", "))
+		} else if marked != "" {
+			fmt.Printf("  No parameters: marked path segment %q with *\n", marked)
+		} else {
+			fmt.Printf("  No parameters found: add a * injection marker to the file, or use --level=3+ for headers\n")
+		}
+		if sqlmapUseVars && len(authHeaders) > 0 {
+			fmt.Printf("  %s Run first: %s\n", shell.Comment, shell.LoadAuth)
+		} else if !sqlmapUseVars && sendsCredentials(req) {
+			fmt.Printf("  %s\n", pterm.FgGray.Sprint("The file holds captured credentials; --use-vars keeps them out"))
+		}
+		fmt.Println()
+		fmt.Println(command)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sqlmapCmd)
+	sqlmapCmd.Flags().StringVar(&sqlmapSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	sqlmapCmd.Flags().BoolVar(&sqlmapUseVars, "use-vars", false, "Pass credentials as shell variables instead of writing them to the file")
+	sqlmapCmd.Flags().IntVar(&sqlmapLevel, "level", 2, "sqlmap --level (1-5)")
+	sqlmapCmd.Flags().IntVar(&sqlmapRisk, "risk", 1, "sqlmap --risk (1-3)")
+	sqlmapCmd.Flags().StringVarP(&sqlmapParam, "param", "p", "", "Parameter to test (sqlmap -p)")
+	sqlmapCmd.Flags().StringVar(&sqlmapOut, "out", "", "Request file path (default: a temp file)")
+}
+
+// sqlmapParameters lists the query and body parameter names of a request
+func sqlmapParameters(req *store.Request) []string {
+	seen := make(map[string]bool)
+	params := []string{}
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			params = append(params, name)
+		}
+	}
+	if parsed, err := url.Parse(req.URL); err == nil {
+		for name := range parsed.Query() {
+			add(name)
+		}
+	}
+	for _, name := range bodyParamNames(req) {
+		add(name)
+	}
+	sort.Strings(params)
+	return params
+}
+
+// markInjectionPoint appends sqlmap's * marker to the last variable path
+// segment and returns the new URL and the marked segment
+func markInjectionPoint(rawURL string) (string, string) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL, ""
+	}
+	segments := strings.Split(parsed.Path, "/")
+	template := strings.Split(store.EndpointTemplate(parsed.Path), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if i < len(template) && template[i] == "{id}" {
+			marked := segments[i]
+			segments[i] += "*"
+			u := *parsed
+			u.Path, u.RawPath = strings.Join(segments, "/"), strings.Join(segments, "/")
+			return u.String(), marked
+		}
+	}
+	return rawURL, ""
+}
+
+// writeSqlmapRequest writes the request file with owner-only permissions
+func writeSqlmapRequest(id, content string) (string, error) {
+	if sqlmapOut != "" {
+		if err := os.WriteFile(sqlmapOut, []byte(content), 0600); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", sqlmapOut, err)
+		}
+		return sqlmapOut, nil
+	}
+	f, err := os.CreateTemp("", "rep-sqlmap-"+safePathSegment(id)+"-*.req")
+	if err != nil {
+		return "", fmt.Errorf("failed to create request file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", f.Name(), err)
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sseSaved string
+	sseRaw   bool
+	sseEvent string
+)
+
+// sseMessage is one event of a stream: a parsed SSE event, or a raw chunk
+// for other streaming responses
+type sseMessage struct {
+	OffsetMs  int64  `json:"offset_ms"` // Since the first chunk
+	Timestamp int64  `json:"timestamp,omitempty"`
+	Event     string `json:"event,omitempty"`
+	ID        string `json:"id,omitempty"`
+	Retry     string `json:"retry,omitempty"`
+	Data      string `json:"data"`
+}
+
+var sseCmd = &cobra.Command{
+	Use:   "sse <request-id>",
+	Short: "View the event stream of a streaming response",
+	Long: `Show the events of a Server-Sent Events or other streaming response
+(chunked long-poll, streamed JSON) in arrival order.
+
+The extension appends each chunk to the request as it arrives (the
+response "events" array), so streams that never finish still show up.
+text/event-stream responses are parsed into events (event, id, data);
+other streams are listed chunk by chunk, WebSocket messages (imported
+from mitmproxy) labeled client or server. A stream captured only as a body
+is parsed from the body, without timings.
+
+Each line starts with the time since the first chunk.
+
+Examples:
+  rep sse h_abc123                  Parsed events with timings
+  rep sse h_abc123 --event update   Only "update" events
+  rep sse h_abc123 --raw            Chunks exactly as received
+  rep sse h_abc123 -o json          Events as JSON (offset_ms, event, id, data)
+  rep list --resp-header "content-type:event-stream"   Find SSE requests`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		requestID := args[0]
+		req, err := lookupRequest(requestID, sseSaved)
+		if err != nil {
+			return err
+		}
+		if req == nil {
+			return notFoundError("request not found: %s", requestID).withHint("Use 'rep list' to see available request IDs")
+		}
+		if req.Response == nil {
+			return softFail(emptyError("No response captured"))
+		}
+
+		contentType := store.HeaderFirst(req.Response.Headers, "content-type")
+		events := req.Response.Events
+		if len(events) == 0 && req.Response.Body != "" {
+			events = []store.StreamEvent{{Data: store.ResponseBodyText(req)}}
+		}
+		if len(events) == 0 {
+			return softFail(emptyError("No streamed events captured for this request"))
+		}
+
+		format := "chunks"
+		var messages []sseMessage
+		if !sseRaw && isEventStream(contentType, events) {
+			format = "sse"
+			messages = parseEventStream(events)
+		} else {
+			messages = streamChunks(events)
+		}
+		if sseEvent != "" {
+			kept := messages[:0]
+			for _, m := range messages {
+				if strings.EqualFold(m.Event, sseEvent) || (sseEvent == "message" && m.Event == "") {
+					kept = append(kept, m)
+				}
+			}
+			messages = kept
+		}
+
+		if getOutputMode() == "json" {
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"id":       req.ID,
+				"method":   req.Method,
+				"url":      req.URL,
+				"status":   req.Response.Status,
+				"format":   format,
+				"chunks":   len(req.Response.Events),
+				"messages": messages,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		pterm.DefaultSection.Printf("Event Stream: %s\n", req.ID)
+		fmt.Printf("  %s %s\n", req.Method, output.SanitizeText(req.URL))
+		span := ""
+		if n := len(req.Response.Events); n > 1 {
+			span = fmt.Sprintf(" over %s", output.FormatDuration(float64(req.Response.Events[n-1].Timestamp-req.Response.Events[0].Timestamp)))
+		}
+		unit := "chunks"
+		if format == "sse" {
+			unit = "events"
+		}
+		fmt.Printf("  Status: %d  %d chunks%s  %d %s\n\n", req.Response.Status, len(events), span, len(messages), unit)
+
+		cfg := truncateConfig()
+		for _, m := range messages {
+			label := ""
+			if m.Event != "" {
+				label += "[" + output.SanitizeText(m.Event) + "] "
+			}
+			if m.ID != "" {
+				label += "id=" + output.SanitizeText(m.ID) + " "
+			}
+			data := m.Data
+			if getOutputMode() != "full" {
+				data, _ = output.TruncateBody(data, "", cfg)
+			}
+			data = output.SanitizeText(data)
+			offset := "+" + output.FormatDuration(float64(m.OffsetMs))
+			if m.Timestamp == 0 {
+				offset = "-"
+			}
+			lines := strings.Split(data, "\n")
+			if label != "" {
+				label = pterm.FgCyan.Sprint(label)
+			}
+			fmt.Printf("  %8s  %s%s\n", offset, label, lines[0])
+			for _, line := range lines[1:] {
+				fmt.Printf("  %8s  %s\n", "", line)
+			}
+		}
+		return nil
+	},
+}
+
+// isEventStream reports whether a stream uses the SSE wire format
+func isEventStream(contentType string, events []store.StreamEvent) bool {
+	if strings.Contains(strings.ToLower(contentType), "event-stream") {
+		return true
+	}
+	data := events[0].Data
+	return strings.HasPrefix(data, "data:") || strings.HasPrefix(data, "event:") || strings.HasPrefix(data, "id:")
+}
+
+// parseEventStream joins the chunks and splits them into SSE events. An
+// event is timed by the chunk that completed it; chunk boundaries may fall
+// anywhere inside an event.
+func parseEventStream(chunks []store.StreamEvent) []sseMessage {
+	var messages []sseMessage
+	var buf strings.Builder
+	first := chunks[0].Timestamp
+
+	flush := func(block string, ts int64) {
+		m := sseMessage{Timestamp: ts}
+		if ts != 0 {
+			m.OffsetMs = ts - first
+		}
+		var data []string
+		for _, line := range strings.Split(block, "\n") {
+			if line == "" || strings.HasPrefix(line, ":") {
+				continue // Comments are keep-alives
+			}
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				m.Event = value
+			case "id":
+				m.ID = value
+			case "retry":
+				m.Retry = value
+			case "data":
+				data = append(data, value)
+			}
+		}
+		if data == nil && m.Event == "" && m.ID == "" && m.Retry == "" {
+			return
+		}
+		m.Data = strings.Join(data, "\n")
+		messages = append(messages, m)
+	}
+
+	for _, chunk := range chunks {
+		buf.WriteString(strings.ReplaceAll(chunk.Data, "\r\n", "\n"))
+		pending := buf.String()
+		for {
+			end := strings.Index(pending, "\n\n")
+			if end < 0 {
+				break
+			}
+			flush(pending[:end], chunk.Timestamp)
+			pending = pending[end+2:]
+		}
+		buf.Reset()
+		buf.WriteString(pending)
+	}
+	if rest := strings.TrimSpace(buf.String()); rest != "" {
+		flush(rest, chunks[len(chunks)-1].Timestamp)
+	}
+	return messages
+}
+
+// streamChunks lists non-SSE chunks as received
+func streamChunks(chunks []store.StreamEvent) []sseMessage {
+	messages := make([]sseMessage, 0, len(chunks))
+	first := chunks[0].Timestamp
+	for _, chunk := range chunks {
+		// WebSocket messages are labeled with their sender
+		m := sseMessage{Timestamp: chunk.Timestamp, Event: chunk.From, Data: chunk.Data}
+		if chunk.Binary {
+			m.Event += " binary"
+		}
+		if chunk.Timestamp != 0 {
+			m.OffsetMs = chunk.Timestamp - first
+		}
+		messages = append(messages, m)
+	}
+	return messages
+}
+
+func init() {
+	rootCmd.AddCommand(sseCmd)
+	sseCmd.Flags().StringVar(&sseSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	sseCmd.Flags().BoolVar(&sseRaw, "raw", false, "List chunks as received instead of parsing SSE events")
+	sseCmd.Flags().StringVar(&sseEvent, "event", "", "Only events of this type (\"message\" matches unnamed events)")
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/extract"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	subdomainsSaved       string
+	subdomainsSources     bool
+	subdomainsUnrequested bool
+)
+
+// Where a hostname was seen, in display order
+var subdomainSourceOrder = []string{"request", "page", "initiator", "csp", "cors", "header", "body", "js"}
+
+// subdomainInfo is one hostname under the base domain
+type subdomainInfo struct {
+	Host      string   `json:"host"`
+	Requested bool     `json:"requested"` // Traffic went to this host
+	Sources   []string `json:"sources"`   // request, page, initiator, csp, cors, header, body, js
+	Requests  []string `json:"requests"`  // IDs of requests it was seen in (first 5)
+	Count     int      `json:"count"`     // Requests it was seen in
+}
+
+var subdomainsCmd = &cobra.Command{
+	Use:   "subdomains <base-domain>",
+	Short: "List hostnames under a domain seen anywhere in traffic",
+	Long: `Passive subdomain enumeration from captured traffic: every hostname that
+is the base domain or one of its subdomains, seen in
+  request     URLs of captured requests
+  page        page URLs requests were made from
+  initiator   request initiators
+  csp         Content-Security-Policy headers
+  cors        Access-Control-Allow-Origin headers
+  header      any other request or response header (Location, Referer, Set-Cookie, Link, ...)
+  body        request and response bodies (HTML, JSON, ...)
+  js          JavaScript response bodies
+
+All captured requests are scanned, including ignored domains: third-party
+scripts and headers often name hosts of the target. Output is one hostname
+per line, sorted, for piping into resolvers and scanners.
+
+Examples:
+  rep subdomains acme.com                      One hostname per line
+  rep subdomains acme.com --sources            With where each one was seen
+  rep subdomains acme.com --unrequested        Only hosts the browser never contacted
+  rep subdomains acme.com | dnsx -silent       Resolve them
+  rep subdomains acme.com --saved latest -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domain := strings.Trim(strings.ToLower(strings.TrimSpace(args[0])), ".")
+		if host := hostFromURL(domain); host != "" {
+			domain = host // Accept a URL
+		}
+		if domain == "" {
+			return fmt.Errorf("base domain required")
+		}
+
+		requests, err := filterSource(subdomainsSaved, store.FilterOptions{})
+		if err != nil || requests == nil {
+			return err
+		}
+
+		found := harvestSubdomains(requests, domain)
+		if subdomainsUnrequested {
+			kept := found[:0]
+			for _, s := range found {
+				if !s.Requested {
+					kept = append(kept, s)
+				}
+			}
+			found = kept
+		}
+
+		if getOutputMode() == "json" {
+			if found == nil {
+				found = []subdomainInfo{}
+			}
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"domain":     domain,
+				"total":      len(found),
+				"scanned":    len(requests),
+				"subdomains": found,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(found) == 0 {
+			return softFail(emptyError("No hostnames under %s found in %d requests", domain, len(requests)))
+		}
+		width := 0
+		for _, s := range found {
+			if len(s.Host) > width {
+				width = len(s.Host)
+			}
+		}
+		for _, s := range found {
+			if !subdomainsSources {
+				fmt.Println(s.Host)
+				continue
+			}
+			mark := pterm.FgGray.Sprint("·")
+			if s.Requested {
+				mark = pterm.FgGreen.Sprint("✓")
+			}
+			fmt.Printf("%s %-*s  %s\n", mark, width, s.Host, pterm.FgGray.Sprint(strings.Join(s.Sources, ",")))
+		}
+		return nil
+	},
+}
+
+// harvestSubdomains collects hostnames under domain from every part of the
+// requests, sorted by name
+func harvestSubdomains(requests []store.Request, domain string) []subdomainInfo {
+	pattern := extract.HostPattern(domain)
+	byHost := make(map[string]*subdomainInfo)
+	sources := make(map[string]map[string]bool)
+
+	for i := range requests {
+		req := &requests[i]
+
+		seen := make(map[string]bool)
+		note := func(text, source string) {
+			if text == "" {
+				return
+			}
+			for _, host := range extract.Hosts(text, pattern) {
+				info := byHost[host]
+				if info == nil {
+					info = &subdomainInfo{Host: host}
+					byHost[host] = info
+					sources[host] = make(map[string]bool)
+				}
+				sources[host][source] = true
+				if source == "request" {
+					info.Requested = true
+				}
+				if !seen[host] {
+					seen[host] = true
+					info.Count++
+					if len(info.Requests) < 5 {
+						info.Requests = append(info.Requests, req.ID)
+					}
+				}
+			}
+		}
+
+		note(hostFromURL(req.URL), "request")
+		note(hostFromURL(req.PageURL), "page")
+		note(hostFromURL(req.Initiator), "initiator")
+		noteHeaders := func(headers store.HeaderMap) {
+			for name, values := range headers {
+				source := "header"
+				switch lower := strings.ToLower(name); {
+				case strings.HasPrefix(lower, "content-security-policy"):
+					source = "csp"
+				case lower == "access-control-allow-origin":
+					source = "cors"
+				}
+				for _, value := range values {
+					note(value, source)
+				}
+			}
+		}
+		noteHeaders(req.Headers)
+		note(req.Body, "body")
+		if req.Response != nil {
+			noteHeaders(req.Response.Headers)
+			source := "body"
+			contentType := strings.ToLower(store.HeaderFirst(req.Response.Headers, "content-type"))
+			if req.ResourceType == "script" || strings.Contains(contentType, "javascript") || strings.Contains(contentType, "ecmascript") {
+				source = "js"
+			}
+			note(store.ResponseBodyText(req), source)
+		}
+	}
+
+	found := make([]subdomainInfo, 0, len(byHost))
+	for host, info := range byHost {
+		for _, source := range subdomainSourceOrder {
+			if sources[host][source] {
+				info.Sources = append(info.Sources, source)
+			}
+		}
+		found = append(found, *info)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Host < found[j].Host
+	})
+	return found
+}
+
+func init() {
+	rootCmd.AddCommand(subdomainsCmd)
+	subdomainsCmd.Flags().StringVar(&subdomainsSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	subdomainsCmd.Flags().BoolVar(&subdomainsSources, "sources", false, "Show where each hostname was seen (✓ = requested)")
+	subdomainsCmd.Flags().BoolVar(&subdomainsUnrequested, "unrequested", false, "Only hostnames no captured request went to")
+}
@@ -6,15 +6,17 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/bytedance/sonic"
 	"github.com/pterm/pterm"
 	"github.com/repplus/rep-cli/internal/noise"
+	"github.com/repplus/rep-cli/internal/output"
 	"github.com/repplus/rep-cli/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var (
-	summarySaved string
+	summarySaved    string
+	summarySnapshot string
+	summaryCompare  string
 )
 
 var summaryCmd = &cobra.Command{
@@ -30,7 +32,23 @@ Shows:
   - Total requests and unique domains
   - Domain breakdown with request counts
   - Method distribution
-  - Suggested domains to ignore (analytics, CDN, tracking)`,
+  - Timing: duration percentiles, slowest requests, bytes transferred and
+    protocols (when the extension reports timing metadata)
+  - Suggested domains to ignore (analytics, CDN, tracking)
+
+Snapshots:
+  --snapshot <name>   Save the current summary under a name
+  --compare <name>    Show what changed since that snapshot: request
+                      count, new / changed / gone domains, methods and
+                      status classes. Combine with --snapshot to move the
+                      snapshot forward in the same run.
+
+Examples:
+  rep summary                               Live capture
+  rep summary --saved latest -o json
+  rep summary --snapshot before-click       Before a click storm...
+  rep summary --compare before-click        ...and what it generated
+  rep summary --compare step --snapshot step  Changes since the last step`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var tempStore *store.Store
 		var persistentStore *store.Store
@@ -52,30 +70,26 @@ Shows:
 			}
 
 			if session == nil {
-				pterm.Warning.Printf("Session not found: %s\n", summarySaved)
-				pterm.Info.Println("Use 'rep sessions' to list available sessions")
-				return nil
+				return notFoundError("session not found: %s", summarySaved).withHint("Use 'rep sessions' to list available sessions")
 			}
 
 			tempStore = store.NewTempStore(session.Requests)
 		} else {
 			// Default: Load from live.json
-			livePath, err := store.GetLiveFilePath()
+			livePaths, err := store.GetLiveFilePaths()
 			if err != nil {
 				return fmt.Errorf("failed to get live path: %w", err)
 			}
-			export, err := loadLiveExport(livePath)
+			// Bodies are never needed here, so skip them while parsing
+			requests, err := store.LoadLiveWithoutBodiesAll(livePaths)
 			if err != nil {
-				pterm.Warning.Printf("Could not read live.json: %v\n", err)
-				pterm.Info.Println("Enable auto-export in rep+ extension first")
-				return nil
+				return softFail(liveReadError(err))
 			}
-			if len(export.Requests) == 0 {
-				pterm.Info.Println("No requests captured yet (live session empty)")
-				return nil
+			if len(requests) == 0 {
+				return softFail(emptyLiveError())
 			}
 
-			tempStore = store.NewTempStore(export.Requests)
+			tempStore = store.NewTempStore(requests)
 		}
 
 		// Apply ignore/primary lists
@@ -87,11 +101,24 @@ Shows:
 		// Build summary data
 		summary := buildSummary(tempStore, domains, persistentStore)
 
+		if summaryCompare != "" || summarySnapshot != "" {
+			return runSummarySnapshot(summary)
+		}
+
+		summary, report := fitSummary(summary)
 		if getOutputMode() == "json" {
-			out, _ := sonic.MarshalIndent(summary, "", "  ")
-			fmt.Println(string(out))
+			next := report.warn()
+			if len(summary.PrimaryDomains) == 0 && len(summary.TopDomains) > 0 {
+				next = append(next, "rep primary "+summary.TopDomains[0].Domain)
+			}
+			if len(summary.SuggestIgnore) > 0 {
+				next = append(next, "rep ignore "+strings.Join(summary.SuggestIgnore, " "))
+			}
+			next = append(next, "rep list --api -o json")
+			printJSON("summary", summary, next...)
 		} else {
 			printSummary(summary, domains, tempStore)
+			report.print()
 		}
 
 		return nil
@@ -108,6 +135,29 @@ type Summary struct {
 	PageBreakdown   []PageSummary   `json:"page_breakdown"`
 	TopDomains      []DomainSummary `json:"top_domains"`
 	SuggestIgnore   []string        `json:"suggest_ignore"`
+
+	// Only when the extension reports timing/size metadata
+	Timing *TimingSummary `json:"timing,omitempty"`
+}
+
+// TimingSummary aggregates duration, transfer size and protocol metadata
+type TimingSummary struct {
+	TimedRequests int            `json:"timed_requests"`
+	P50Ms         float64        `json:"p50_ms"`
+	P95Ms         float64        `json:"p95_ms"`
+	MaxMs         float64        `json:"max_ms"`
+	Slowest       []SlowRequest  `json:"slowest,omitempty"`
+	RequestBytes  int64          `json:"request_bytes"`
+	ResponseBytes int64          `json:"response_bytes"`
+	Protocols     map[string]int `json:"protocols,omitempty"`
+	RemoteIPs     int            `json:"remote_ips"`
+}
+
+type SlowRequest struct {
+	ID         string  `json:"id"`
+	Method     string  `json:"method"`
+	URL        string  `json:"url"`
+	DurationMs float64 `json:"duration_ms"`
 }
 
 type DomainSummary struct {
@@ -143,7 +193,9 @@ func buildSummary(tempStore *store.Store, domains []store.DomainInfo, persistent
 	// Build method and status breakdown from all requests
 	pageCounts := make(map[string]int)
 	pageOrder := make([]string, 0)
-	for _, req := range tempStore.Filter(store.FilterOptions{}) {
+	requests := tempStore.Filter(store.FilterOptions{})
+	summary.Timing = buildTimingSummary(requests)
+	for _, req := range requests {
 		summary.MethodBreakdown[req.Method]++
 		if req.Response != nil {
 			statusRange := fmt.Sprintf("%dxx", req.Response.Status/100)
@@ -199,6 +251,55 @@ func buildSummary(tempStore *store.Store, domains []store.DomainInfo, persistent
 	return summary
 }
 
+// buildTimingSummary returns nil when no request carries timing metadata
+func buildTimingSummary(requests []store.Request) *TimingSummary {
+	timing := &TimingSummary{Protocols: make(map[string]int)}
+	var durations []float64
+	var timed []store.Request
+	ips := make(map[string]bool)
+	for _, req := range requests {
+		if req.DurationMs > 0 {
+			durations = append(durations, req.DurationMs)
+			timed = append(timed, req)
+		}
+		timing.RequestBytes += req.RequestBytes
+		timing.ResponseBytes += req.ResponseBytes
+		if req.Protocol != "" {
+			timing.Protocols[strings.ToLower(req.Protocol)]++
+		}
+		if req.RemoteIP != "" {
+			ips[req.RemoteIP] = true
+		}
+	}
+	timing.RemoteIPs = len(ips)
+	if len(durations) == 0 && timing.RequestBytes == 0 && timing.ResponseBytes == 0 &&
+		len(timing.Protocols) == 0 && len(ips) == 0 {
+		return nil
+	}
+
+	if len(durations) > 0 {
+		sort.Float64s(durations)
+		percentile := func(p float64) float64 {
+			return durations[int(p*float64(len(durations)-1))]
+		}
+		timing.TimedRequests = len(durations)
+		timing.P50Ms = percentile(0.50)
+		timing.P95Ms = percentile(0.95)
+		timing.MaxMs = durations[len(durations)-1]
+
+		store.SortRequests(timed, "duration", true)
+		for i := 0; i < len(timed) && i < 5; i++ {
+			timing.Slowest = append(timing.Slowest, SlowRequest{
+				ID:         timed[i].ID,
+				Method:     timed[i].Method,
+				URL:        timed[i].URL,
+				DurationMs: timed[i].DurationMs,
+			})
+		}
+	}
+	return timing
+}
+
 func printSummary(summary Summary, domains []store.DomainInfo, s *store.Store) {
 	// Header box
 	pterm.DefaultBox.WithTitle("Traffic Summary").WithTitleTopCenter().Println(
@@ -219,6 +320,34 @@ func printSummary(summary Summary, domains []store.DomainInfo, s *store.Store) {
 		pterm.Printf("  %-8s %d\n", status, count)
 	}
 
+	// Timing (when the extension reports it)
+	if t := summary.Timing; t != nil {
+		fmt.Println()
+		pterm.DefaultSection.Println("Timing")
+		if t.TimedRequests > 0 {
+			pterm.Printf("  %-10s p50 %s  p95 %s  max %s  (%d requests)\n", "Duration",
+				output.FormatDuration(t.P50Ms), output.FormatDuration(t.P95Ms), output.FormatDuration(t.MaxMs), t.TimedRequests)
+		}
+		if t.RequestBytes > 0 || t.ResponseBytes > 0 {
+			pterm.Printf("  %-10s sent %s  received %s\n", "Transfer",
+				output.FormatBodySize(int(t.RequestBytes)), output.FormatBodySize(int(t.ResponseBytes)))
+		}
+		if len(t.Protocols) > 0 {
+			protocols := make([]string, 0, len(t.Protocols))
+			for proto, count := range t.Protocols {
+				protocols = append(protocols, fmt.Sprintf("%s %d", output.SanitizeText(proto), count))
+			}
+			sort.Strings(protocols)
+			pterm.Printf("  %-10s %s\n", "Protocols", strings.Join(protocols, ", "))
+		}
+		if t.RemoteIPs > 0 {
+			pterm.Printf("  %-10s %d\n", "Remote IPs", t.RemoteIPs)
+		}
+		for _, slow := range t.Slowest {
+			fmt.Printf("    %8s  [%s] %s %s\n", output.FormatDuration(slow.DurationMs), slow.ID, slow.Method, output.SanitizeText(slow.URL))
+		}
+	}
+
 	// Page breakdown (dev panel style)
 	if len(summary.PageBreakdown) > 0 {
 		fmt.Println()
@@ -337,4 +466,6 @@ func hostFromURL(raw string) string {
 func init() {
 	rootCmd.AddCommand(summaryCmd)
 	summaryCmd.Flags().StringVar(&summarySaved, "saved", "", "Read from saved session (ID, prefix, or 'latest')")
+	summaryCmd.Flags().StringVar(&summarySnapshot, "snapshot", "", "Save the summary as a named snapshot")
+	summaryCmd.Flags().StringVar(&summaryCompare, "compare", "", "Show changes since a named snapshot")
 }
@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// summarySnapshotDir is the directory (inside the store directory) holding
+// 'rep summary --snapshot' files, one JSON file per name
+const summarySnapshotDir = "snapshots"
+
+// SummarySnapshot is a saved summary to compare later traffic against
+type SummarySnapshot struct {
+	Name    string  `json:"name"`
+	TakenAt string  `json:"taken_at"` // RFC3339
+	Source  string  `json:"source"`   // "live" or the saved session ID
+	Summary Summary `json:"summary"`
+}
+
+// SummaryDelta is the change between a snapshot and the current summary
+type SummaryDelta struct {
+	Snapshot       string                `json:"snapshot"`
+	TakenAt        string                `json:"taken_at"`
+	Requests       CountDelta            `json:"requests"`
+	Domains        CountDelta            `json:"domains"`
+	NewDomains     []DomainDelta         `json:"new_domains"`
+	RemovedDomains []DomainDelta         `json:"removed_domains"`
+	ChangedDomains []DomainDelta         `json:"changed_domains"`
+	Methods        map[string]CountDelta `json:"methods"`
+	Status         map[string]CountDelta `json:"status"`
+}
+
+// CountDelta is a count before and after
+type CountDelta struct {
+	Before int `json:"before"`
+	After  int `json:"after"`
+	Delta  int `json:"delta"`
+}
+
+// DomainDelta is the change in one domain's request and endpoint counts
+type DomainDelta struct {
+	Domain    string     `json:"domain"`
+	Requests  CountDelta `json:"requests"`
+	Endpoints CountDelta `json:"endpoints"`
+}
+
+func newCountDelta(before, after int) CountDelta {
+	return CountDelta{Before: before, After: after, Delta: after - before}
+}
+
+// runSummarySnapshot handles --compare and --snapshot: the comparison is
+// made against the old snapshot before --snapshot replaces it
+func runSummarySnapshot(summary Summary) error {
+	var delta *SummaryDelta
+	if summaryCompare != "" {
+		snap, err := loadSummarySnapshot(summaryCompare)
+		if err != nil {
+			return err
+		}
+		d := diffSummaries(snap, summary)
+		delta = &d
+	}
+
+	var saved *SummarySnapshot
+	if summarySnapshot != "" {
+		source := "live"
+		if summarySaved != "" {
+			source = summarySaved
+		}
+		var err error
+		if saved, err = saveSummarySnapshot(summarySnapshot, source, summary); err != nil {
+			return err
+		}
+	}
+
+	if getOutputMode() == "json" {
+		payload := map[string]interface{}{}
+		if delta != nil {
+			payload["compare"] = delta
+		}
+		if saved != nil {
+			payload["snapshot"] = map[string]interface{}{
+				"name":     saved.Name,
+				"taken_at": saved.TakenAt,
+				"requests": summary.TotalRequests,
+				"domains":  summary.UniqueDomains,
+			}
+		}
+		out, _ := sonic.MarshalIndent(payload, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if delta != nil {
+		printSummaryDelta(*delta)
+	}
+	if saved != nil {
+		pterm.Success.Printf("Snapshot %q saved (%d requests, %d domains)\n", saved.Name, summary.TotalRequests, summary.UniqueDomains)
+		if delta == nil {
+			pterm.Info.Printf("Later: rep summary --compare %s\n", saved.Name)
+		}
+	}
+	return nil
+}
+
+// summarySnapshotPath returns the file of a named snapshot
+func summarySnapshotPath(name string) (string, error) {
+	storePath, err := store.GetStorePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(storePath, summarySnapshotDir, safePathSegment(name)+".json"), nil
+}
+
+// saveSummarySnapshot writes the summary under name, replacing an earlier
+// snapshot of the same name
+func saveSummarySnapshot(name, source string, summary Summary) (*SummarySnapshot, error) {
+	path, err := summarySnapshotPath(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	snap := &SummarySnapshot{
+		Name:    name,
+		TakenAt: time.Now().Format(time.RFC3339),
+		Source:  source,
+		Summary: summary,
+	}
+	data, err := sonic.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	if err := store.WriteFileAtomic(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+// loadSummarySnapshot reads a named snapshot; the error lists the existing
+// names when it is missing
+func loadSummarySnapshot(name string) (*SummarySnapshot, error) {
+	path, err := summarySnapshotPath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		names := listSummarySnapshots()
+		if len(names) == 0 {
+			return nil, notFoundError("no snapshot named %q (take one with 'rep summary --snapshot %s')", name, name)
+		}
+		return nil, notFoundError("no snapshot named %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var snap SummarySnapshot
+	if err := sonic.Unmarshal(data, &snap); err != nil {
+		return nil, parseError("failed to parse snapshot %s: %v", path, err)
+	}
+	return &snap, nil
+}
+
+// listSummarySnapshots returns the names of the saved snapshots
+func listSummarySnapshots() []string {
+	storePath, err := store.GetStorePath()
+	if err != nil {
+		return nil
+	}
+	entries, err := os.ReadDir(filepath.Join(storePath, summarySnapshotDir))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// diffSummaries compares the current summary with a snapshot
+func diffSummaries(snap *SummarySnapshot, current Summary) SummaryDelta {
+	before := snap.Summary
+	delta := SummaryDelta{
+		Snapshot:       snap.Name,
+		TakenAt:        snap.TakenAt,
+		Requests:       newCountDelta(before.TotalRequests, current.TotalRequests),
+		Domains:        newCountDelta(before.UniqueDomains, current.UniqueDomains),
+		NewDomains:     []DomainDelta{},
+		RemovedDomains: []DomainDelta{},
+		ChangedDomains: []DomainDelta{},
+		Methods:        countDeltas(before.MethodBreakdown, current.MethodBreakdown),
+		Status:         countDeltas(before.StatusBreakdown, current.StatusBreakdown),
+	}
+
+	old := make(map[string]DomainSummary)
+	for _, d := range before.TopDomains {
+		old[d.Domain] = d
+	}
+	for _, d := range current.TopDomains {
+		prev, existed := old[d.Domain]
+		delete(old, d.Domain)
+		dd := DomainDelta{
+			Domain:    d.Domain,
+			Requests:  newCountDelta(prev.Requests, d.Requests),
+			Endpoints: newCountDelta(prev.Endpoints, d.Endpoints),
+		}
+		switch {
+		case !existed:
+			delta.NewDomains = append(delta.NewDomains, dd)
+		case dd.Requests.Delta != 0 || dd.Endpoints.Delta != 0:
+			delta.ChangedDomains = append(delta.ChangedDomains, dd)
+		}
+	}
+	for _, d := range old {
+		delta.RemovedDomains = append(delta.RemovedDomains, DomainDelta{
+			Domain:    d.Domain,
+			Requests:  newCountDelta(d.Requests, 0),
+			Endpoints: newCountDelta(d.Endpoints, 0),
+		})
+	}
+
+	byGrowth := func(list []DomainDelta) {
+		sort.Slice(list, func(i, j int) bool {
+			a, b := list[i].Requests.Delta, list[j].Requests.Delta
+			if a < 0 {
+				a = -a
+			}
+			if b < 0 {
+				b = -b
+			}
+			if a != b {
+				return a > b
+			}
+			return list[i].Domain < list[j].Domain
+		})
+	}
+	byGrowth(delta.NewDomains)
+	byGrowth(delta.RemovedDomains)
+	byGrowth(delta.ChangedDomains)
+	return delta
+}
+
+// countDeltas compares two breakdowns key by key
+func countDeltas(before, after map[string]int) map[string]CountDelta {
+	deltas := make(map[string]CountDelta)
+	for key, n := range before {
+		deltas[key] = newCountDelta(n, after[key])
+	}
+	for key, n := range after {
+		if _, ok := before[key]; !ok {
+			deltas[key] = newCountDelta(0, n)
+		}
+	}
+	return deltas
+}
+
+func printSummaryDelta(delta SummaryDelta) {
+	pterm.DefaultSection.Printf("Changes since snapshot %q (%s)\n", delta.Snapshot, delta.TakenAt)
+	fmt.Printf("  %-10s %s\n", "Requests", formatCountDelta(delta.Requests))
+	fmt.Printf("  %-10s %s\n", "Domains", formatCountDelta(delta.Domains))
+
+	printBreakdownDelta := func(title string, deltas map[string]CountDelta) {
+		keys := make([]string, 0, len(deltas))
+		for key, d := range deltas {
+			if d.Delta != 0 {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			return
+		}
+		sort.Strings(keys)
+		fmt.Println()
+		pterm.DefaultSection.Println(title)
+		for _, key := range keys {
+			fmt.Printf("  %-8s %s\n", key, formatCountDelta(deltas[key]))
+		}
+	}
+	printBreakdownDelta("Methods", delta.Methods)
+	printBreakdownDelta("Response Status", delta.Status)
+
+	printDomains := func(title string, list []DomainDelta) {
+		if len(list) == 0 {
+			return
+		}
+		fmt.Println()
+		pterm.DefaultSection.Printf("%s (%d)\n", title, len(list))
+		for _, d := range list {
+			fmt.Printf("  %-40s requests %s  endpoints %s\n", output.SanitizeText(truncateCell(d.Domain, 40)),
+				formatCountDelta(d.Requests), formatCountDelta(d.Endpoints))
+		}
+	}
+	printDomains("New Domains", delta.NewDomains)
+	printDomains("Changed Domains", delta.ChangedDomains)
+	printDomains("Gone Domains", delta.RemovedDomains)
+
+	fmt.Println()
+	if delta.Requests.Delta == 0 && len(delta.NewDomains) == 0 && len(delta.ChangedDomains) == 0 && len(delta.RemovedDomains) == 0 {
+		pterm.Info.Println("No change since the snapshot")
+		return
+	}
+	if delta.Requests.Delta > 0 && delta.TakenAt != "" {
+		pterm.Info.Printf("New requests: rep list --since %s\n", delta.TakenAt)
+	}
+}
+
+// formatCountDelta renders "before → after (+delta)", colored by direction
+func formatCountDelta(d CountDelta) string {
+	change := fmt.Sprintf("%+d", d.Delta)
+	switch {
+	case d.Delta > 0:
+		change = pterm.FgGreen.Sprint(change)
+	case d.Delta < 0:
+		change = pterm.FgRed.Sprint(change)
+	default:
+		change = pterm.FgGray.Sprint(change)
+	}
+	return fmt.Sprintf("%d → %d (%s)", d.Before, d.After, change)
+}
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/noise"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	thirdpartySaved  string
+	thirdpartyDomain string
+)
+
+// thirdpartySensitiveParam matches parameter names carrying user data or
+// credentials
+var thirdpartySensitiveParam = regexp.MustCompile(`(?i)(e-?mail|user_?(id|name)?$|^uid$|phone|^name$|first_?name|last_?name|address|token|session|auth|password|secret|ssn|birth|dob)`)
+
+// thirdpartyEmail matches an email address in a parameter value
+var thirdpartyEmail = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// scriptTag matches a <script> tag with a src attribute
+var scriptTag = regexp.MustCompile(`(?is)<script\b[^>]*\bsrc\s*=\s*["']?([^"'\s>]+)[^>]*>`)
+
+// thirdpartyListed caps the pages and parameters printed per vendor
+const thirdpartyListed = 8
+
+// ThirdPartyReport ranks the third parties first-party pages depend on
+type ThirdPartyReport struct {
+	Domain  string             `json:"domain"` // First-party registrable domains, comma-separated
+	Pages   int                `json:"pages"`  // First-party pages seen loading resources
+	Vendors []ThirdPartyVendor `json:"vendors"`
+}
+
+// ThirdPartyVendor is a third-party registrable domain and what it gets
+// from the first-party pages
+type ThirdPartyVendor struct {
+	Vendor    string             `json:"vendor"`
+	Category  string             `json:"category,omitempty"` // analytics, tracking, ads, cdn, ... when known
+	Hosts     []string           `json:"hosts"`
+	Requests  int                `json:"requests"`
+	Scripts   []ThirdPartyScript `json:"scripts"`
+	Pages     []string           `json:"pages"`     // First-party pages calling it
+	Params    []string           `json:"params"`    // Query and body parameter names it receives
+	Sensitive []string           `json:"sensitive"` // User data and credentials it receives
+	Score     int                `json:"score"`
+	Exposure  string             `json:"exposure"` // high, medium, low
+}
+
+// ThirdPartyScript is a third-party script and the pages executing it
+type ThirdPartyScript struct {
+	URL   string   `json:"url"`
+	Pages []string `json:"pages"`
+	SRI   bool     `json:"sri"` // Every page loading it sets an integrity attribute
+}
+
+var thirdpartyCmd = &cobra.Command{
+	Use:   "thirdparty -d <domain>",
+	Short: "Rank third parties by the code they run and the data they receive",
+	Long: `List the third parties the target's pages depend on, from the page and
+initiator of every request: the scripts they run inside first-party pages,
+the pages loading them, and the data sent to them (query and body parameter
+names, user data, credentials in Referer URLs).
+
+Vendors are ranked by supply-chain exposure:
+
+  high    Their scripts run in first-party pages without Subresource
+          Integrity, or they receive credentials or user data
+  medium  Their scripts run with SRI, or they receive other parameters
+  low     Only passive resources (images, fonts, pixels without data)
+
+A compromised or malicious vendor script runs with the page's origin: it
+reads the DOM, cookies not marked HttpOnly and whatever the user types.
+SRI is read from the <script integrity> attributes of captured first-party
+HTML pages; scripts injected by other scripts never have it.
+
+Examples:
+  rep thirdparty -d target.com
+  rep thirdparty -d @acme                 First-party domains of a group
+  rep thirdparty -d target.com -o json | jq '.vendors[] | select(.exposure == "high") | .vendor'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if thirdpartyDomain == "" {
+			return usageError("-d is required").withHint("rep thirdparty -d target.com")
+		}
+		scope, err := baseDomainScope(thirdpartyDomain)
+		if err != nil {
+			return err
+		}
+		requests, err := filterSource(thirdpartySaved, store.FilterOptions{})
+		if err != nil || requests == nil {
+			return err
+		}
+		report := buildThirdParty(requests, scope)
+
+		if getOutputMode() == "json" {
+			printJSON("thirdparty", report)
+			return nil
+		}
+		if len(report.Vendors) == 0 {
+			return softFail(emptyError("No third-party requests from %s pages", report.Domain))
+		}
+		printThirdParty(report)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(thirdpartyCmd)
+	thirdpartyCmd.Flags().StringVar(&thirdpartySaved, "saved", "", "Read from saved session (ID or 'latest')")
+	thirdpartyCmd.Flags().StringVarP(&thirdpartyDomain, "domain", "d", "", "First-party domains (comma-separated or @group)")
+}
+
+func buildThirdParty(requests []store.Request, scope domainScope) *ThirdPartyReport {
+	report := &ThirdPartyReport{Domain: scope.String(), Vendors: []ThirdPartyVendor{}}
+	firstParty := func(rawURL string) bool {
+		host := hostFromURL(rawURL)
+		return host != "" && scope.covers(host)
+	}
+
+	// Scripts each first-party HTML page includes, and whether with SRI
+	integrity := make(map[string]map[string]bool) // page -> script URL -> SRI
+	for i := range requests {
+		req := &requests[i]
+		if req.ResourceType != "document" || req.Response == nil || !firstParty(req.URL) {
+			continue
+		}
+		page, err := url.Parse(req.URL)
+		if err != nil {
+			continue
+		}
+		scripts := make(map[string]bool)
+		for _, m := range scriptTag.FindAllStringSubmatch(store.ResponseBodyText(req), -1) {
+			src, err := page.Parse(m[1])
+			if err != nil {
+				continue
+			}
+			scripts[src.String()] = strings.Contains(strings.ToLower(m[0]), "integrity")
+		}
+		integrity[req.URL] = scripts
+	}
+
+	index := make(map[string]int)
+	pages := make(map[string]bool)
+	scriptIndex := make(map[string]map[string]int) // vendor -> URL -> index
+	for i := range requests {
+		req := &requests[i]
+		host := hostFromURL(req.URL)
+		if host == "" || firstParty(req.URL) {
+			continue
+		}
+		page := req.PageURL
+		if !firstParty(page) {
+			page = req.Initiator
+		}
+		if !firstParty(page) {
+			continue
+		}
+		pages[page] = true
+		vendor := store.GetBaseDomain(host)
+		k, ok := index[vendor]
+		if !ok {
+			k = len(report.Vendors)
+			index[vendor] = k
+			report.Vendors = append(report.Vendors, ThirdPartyVendor{
+				Vendor: vendor, Category: noise.DetectNoiseType(host), Hosts: []string{}, Scripts: []ThirdPartyScript{},
+				Pages: []string{}, Params: []string{}, Sensitive: []string{},
+			})
+			scriptIndex[vendor] = make(map[string]int)
+		}
+		v := &report.Vendors[k]
+		v.Requests++
+		if !containsString(v.Hosts, host) {
+			v.Hosts = append(v.Hosts, host)
+		}
+		if !containsString(v.Pages, page) {
+			v.Pages = append(v.Pages, page)
+		}
+
+		if req.ResourceType == "script" {
+			s, ok := scriptIndex[vendor][req.URL]
+			if !ok {
+				s = len(v.Scripts)
+				scriptIndex[vendor][req.URL] = s
+				v.Scripts = append(v.Scripts, ThirdPartyScript{URL: req.URL, Pages: []string{}, SRI: true})
+			}
+			script := &v.Scripts[s]
+			if !containsString(script.Pages, page) {
+				script.Pages = append(script.Pages, page)
+			}
+			if sri, ok := integrity[page][req.URL]; !ok || !sri {
+				script.SRI = false
+			}
+		}
+
+		addThirdPartyData(v, req)
+	}
+
+	report.Pages = len(pages)
+	for i := range report.Vendors {
+		scoreThirdParty(&report.Vendors[i])
+	}
+	sort.SliceStable(report.Vendors, func(i, j int) bool { return report.Vendors[i].Score > report.Vendors[j].Score })
+	return report
+}
+
+// addThirdPartyData records the parameters and user data a request sends to
+// its vendor
+func addThirdPartyData(v *ThirdPartyVendor, req *store.Request) {
+	sensitive := func(reason string) {
+		if !containsString(v.Sensitive, reason) {
+			v.Sensitive = append(v.Sensitive, reason)
+		}
+	}
+	param := func(name string, values []string) {
+		if !containsString(v.Params, name) {
+			v.Params = append(v.Params, name)
+		}
+		if thirdpartySensitiveParam.MatchString(name) {
+			sensitive("param " + name)
+		}
+		for _, value := range values {
+			if thirdpartyEmail.MatchString(value) {
+				sensitive("email address in " + name)
+			}
+		}
+	}
+	if u, err := url.Parse(req.URL); err == nil {
+		for name, values := range u.Query() {
+			param(name, values)
+		}
+	}
+	if req.Body != "" {
+		for _, name := range bodyParamNames(req) {
+			param(name, nil)
+		}
+		if thirdpartyEmail.MatchString(req.Body) {
+			sensitive("email in body")
+		}
+	}
+	for _, name := range refererTokenParams(store.HeaderFirst(req.Headers, "referer")) {
+		sensitive("Referer leaks " + name)
+	}
+	sort.Strings(v.Params)
+}
+
+// scoreThirdParty ranks a vendor: code running in first-party pages weighs
+// most, then the data it receives
+func scoreThirdParty(v *ThirdPartyVendor) {
+	unprotected, protected := 0, 0
+	for _, s := range v.Scripts {
+		if s.SRI {
+			protected += len(s.Pages)
+		} else {
+			unprotected += len(s.Pages)
+		}
+	}
+	v.Score = 10*unprotected + 3*protected + 5*len(v.Sensitive) + min(len(v.Params), 10)
+	switch {
+	case unprotected > 0 || len(v.Sensitive) > 0:
+		v.Exposure = "high"
+	case protected > 0 || len(v.Params) > 0:
+		v.Exposure = "medium"
+	default:
+		v.Exposure = "low"
+	}
+}
+
+func printThirdParty(r *ThirdPartyReport) {
+	pterm.DefaultSection.Printf("Third parties of %s (%d vendors, %d first-party pages)\n", r.Domain, len(r.Vendors), r.Pages)
+	for _, v := range r.Vendors {
+		exposure := pterm.FgGray.Sprint(v.Exposure)
+		switch v.Exposure {
+		case "high":
+			exposure = pterm.FgRed.Sprint(v.Exposure)
+		case "medium":
+			exposure = pterm.FgYellow.Sprint(v.Exposure)
+		}
+		label := pterm.FgCyan.Sprint(output.SanitizeText(v.Vendor))
+		if v.Category != "" {
+			label += pterm.FgGray.Sprint(" (" + v.Category + ")")
+		}
+		fmt.Printf("\n%s  %s  score %d  %d request(s) from %d page(s)\n", label, exposure, v.Score, v.Requests, len(v.Pages))
+		for _, s := range v.Scripts {
+			sri := pterm.FgYellow.Sprint("no SRI")
+			if s.SRI {
+				sri = pterm.FgGreen.Sprint("SRI")
+			}
+			fmt.Printf("  script %s  %s  on %d page(s)\n", output.SanitizeText(truncateCell(s.URL, 80)), sri, len(s.Pages))
+		}
+		if len(v.Sensitive) > 0 {
+			fmt.Printf("  %s %s\n", pterm.FgRed.Sprint("receives"), output.SanitizeText(strings.Join(v.Sensitive, ", ")))
+		}
+		if len(v.Params) > 0 {
+			params := v.Params
+			more := ""
+			if len(params) > thirdpartyListed {
+				params, more = params[:thirdpartyListed], fmt.Sprintf(", ... %d more", len(v.Params)-thirdpartyListed)
+			}
+			fmt.Printf("  params %s%s\n", output.SanitizeText(strings.Join(params, ", ")), more)
+		}
+		for i, page := range v.Pages {
+			if i == thirdpartyListed {
+				fmt.Printf("  ... %d more pages\n", len(v.Pages)-i)
+				break
+			}
+			fmt.Printf("  %s\n", pterm.FgGray.Sprint("from "+output.SanitizeText(truncateCell(page, 80))))
+		}
+	}
+}
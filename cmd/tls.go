@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tlsSaved  string
+	tlsDomain string
+)
+
+// internalName matches hostnames that look internal: private suffixes,
+// single labels and environment or tooling names
+var internalName = regexp.MustCompile(`(?i)(\.(local|internal|intranet|corp|lan|localdomain|home|private)$|^[^.]+$|(^|[.-])(dev|stag(e|ing)|test|qa|uat|int|preprod|sandbox|admin|vpn|jenkins|gitlab|jira|grafana|kibana)([.-]|\d))`)
+
+// TLSReport is the protocol and certificate metadata of the capture
+type TLSReport struct {
+	Hosts        []TLSHost      `json:"hosts"`
+	MixedContent []MixedRequest `json:"mixed_content"` // Plain http loaded by https pages
+	NewNames     []string       `json:"new_names"`     // Certificate SANs of hosts the capture never reached
+	WithTLS      int            `json:"with_tls"`      // Requests carrying TLS details
+}
+
+// TLSHost is the protocol and TLS metadata seen for one host
+type TLSHost struct {
+	Host         string           `json:"host"`
+	Requests     int              `json:"requests"`
+	Plaintext    int              `json:"plaintext"` // http:// and ws:// requests
+	Protocols    map[string]int   `json:"protocols"` // HTTP version -> requests
+	TLSVersions  map[string]int   `json:"tls_versions"`
+	ALPN         []string         `json:"alpn"`
+	Ciphers      []string         `json:"ciphers"`
+	Certificates []TLSCertificate `json:"certificates"`
+}
+
+// TLSCertificate is a server certificate seen for a host
+type TLSCertificate struct {
+	Subject   string   `json:"subject"`
+	Issuer    string   `json:"issuer"`
+	SANs      []string `json:"sans"`
+	ValidFrom string   `json:"valid_from,omitempty"` // YYYY-MM-DD
+	ValidTo   string   `json:"valid_to,omitempty"`
+	Expired   bool     `json:"expired"`
+	Internal  []string `json:"internal"` // SANs that look like internal hostnames
+	Requests  int      `json:"requests"`
+}
+
+// MixedRequest is a plain-text request made by an https page
+type MixedRequest struct {
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	PageURL      string `json:"page_url"`
+	ResourceType string `json:"resource_type,omitempty"`
+}
+
+var tlsCmd = &cobra.Command{
+	Use:   "tls [-d domain]",
+	Short: "Summarize HTTP versions, TLS and certificates per host",
+	Long: `Summarize the protocol metadata of every host in the capture: HTTP
+versions (http/1.1, h2, h3), and, when the capture provides them, the TLS
+version, ALPN, cipher and server certificate (subject, issuer, validity and
+subject alternative names).
+
+Certificate SANs regularly name hosts the application never calls:
+staging and internal environments, admin panels, other products sharing the
+certificate. Names the capture never reached are listed as new names, and
+those that look internal (.local, .corp, dev-, staging., vpn., ...) are
+marked.
+
+Mixed content lists plain http:// and ws:// requests made by https pages.
+
+TLS details come from the browser extension and from 'rep import' of
+mitmproxy flows; other captures only carry the HTTP version.
+
+Examples:
+  rep tls
+  rep tls -d target.com
+  rep tls -o json | jq -r '.new_names[]'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scope, err := baseDomainScope(tlsDomain)
+		if err != nil {
+			return err
+		}
+		requests, err := filterSource(tlsSaved, store.FilterOptions{})
+		if err != nil || requests == nil {
+			return err
+		}
+		report := buildTLSReport(requests, scope)
+
+		if getOutputMode() == "json" {
+			printJSON("tls", report)
+			return nil
+		}
+		if len(report.Hosts) == 0 {
+			return softFail(emptyError("No requests to summarize"))
+		}
+		printTLSReport(report)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tlsCmd)
+	tlsCmd.Flags().StringVar(&tlsSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	tlsCmd.Flags().StringVarP(&tlsDomain, "domain", "d", "", "Only hosts of these registrable domains (comma-separated or @group)")
+}
+
+func buildTLSReport(requests []store.Request, scope domainScope) *TLSReport {
+	report := &TLSReport{Hosts: []TLSHost{}, MixedContent: []MixedRequest{}, NewNames: []string{}}
+	index := make(map[string]int)
+	certIndex := make(map[string]int) // host + subject + issuer + validity
+	captured := make(map[string]bool)
+	for i := range requests {
+		req := &requests[i]
+		host := strings.ToLower(hostFromURL(req.URL))
+		if host == "" {
+			continue
+		}
+		captured[host] = true
+		if !scope.covers(host) {
+			continue
+		}
+		k, ok := index[host]
+		if !ok {
+			k = len(report.Hosts)
+			index[host] = k
+			report.Hosts = append(report.Hosts, TLSHost{
+				Host: host, Protocols: map[string]int{}, TLSVersions: map[string]int{},
+				ALPN: []string{}, Ciphers: []string{}, Certificates: []TLSCertificate{},
+			})
+		}
+		h := &report.Hosts[k]
+		h.Requests++
+		if req.Protocol != "" {
+			h.Protocols[strings.ToLower(req.Protocol)]++
+		}
+
+		scheme := ""
+		if u, err := url.Parse(req.URL); err == nil {
+			scheme = strings.ToLower(u.Scheme)
+		}
+		if scheme == "http" || scheme == "ws" {
+			h.Plaintext++
+			if strings.HasPrefix(strings.ToLower(req.PageURL), "https://") {
+				report.MixedContent = append(report.MixedContent, MixedRequest{
+					ID: req.ID, URL: req.URL, PageURL: req.PageURL, ResourceType: req.ResourceType,
+				})
+			}
+		}
+
+		t := req.TLS
+		if t == nil {
+			continue
+		}
+		report.WithTLS++
+		if t.Version != "" {
+			h.TLSVersions[t.Version]++
+		}
+		if t.ALPN != "" && !containsString(h.ALPN, t.ALPN) {
+			h.ALPN = append(h.ALPN, t.ALPN)
+		}
+		if t.Cipher != "" && !containsString(h.Ciphers, t.Cipher) {
+			h.Ciphers = append(h.Ciphers, t.Cipher)
+		}
+		if t.Subject == "" && len(t.SANs) == 0 {
+			continue
+		}
+		key := fmt.Sprintf("%s %s %s %d", host, t.Subject, t.Issuer, t.ValidTo)
+		c, ok := certIndex[key]
+		if !ok {
+			c = len(h.Certificates)
+			certIndex[key] = c
+			h.Certificates = append(h.Certificates, tlsCertificate(t))
+		}
+		h.Certificates[c].Requests++
+	}
+
+	seen := make(map[string]bool)
+	for _, h := range report.Hosts {
+		for _, c := range h.Certificates {
+			for _, san := range c.SANs {
+				name := strings.TrimPrefix(strings.ToLower(san), "*.")
+				if seen[name] || captured[name] {
+					continue
+				}
+				seen[name] = true
+				report.NewNames = append(report.NewNames, name)
+			}
+		}
+	}
+	sort.Strings(report.NewNames)
+	sort.SliceStable(report.Hosts, func(i, j int) bool { return report.Hosts[i].Requests > report.Hosts[j].Requests })
+	return report
+}
+
+// tlsCertificate summarizes the certificate of a TLS session
+func tlsCertificate(t *store.TLSInfo) TLSCertificate {
+	c := TLSCertificate{Subject: t.Subject, Issuer: t.Issuer, SANs: []string{}, Internal: []string{}}
+	for _, san := range t.SANs {
+		c.SANs = append(c.SANs, san)
+		if internalSAN(san) {
+			c.Internal = append(c.Internal, san)
+		}
+	}
+	if t.ValidFrom > 0 {
+		c.ValidFrom = time.Unix(t.ValidFrom, 0).UTC().Format("2006-01-02")
+	}
+	if t.ValidTo > 0 {
+		c.ValidTo = time.Unix(t.ValidTo, 0).UTC().Format("2006-01-02")
+		c.Expired = time.Now().Unix() > t.ValidTo
+	}
+	return c
+}
+
+// internalSAN reports whether a certificate name looks internal: a private
+// IP or an internal-looking hostname
+func internalSAN(san string) bool {
+	if ip := net.ParseIP(san); ip != nil {
+		return ip.IsPrivate() || ip.IsLoopback()
+	}
+	return internalName.MatchString(strings.TrimPrefix(san, "*."))
+}
+
+// tlsCounts formats a name -> requests map, most used first
+func tlsCounts(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s ×%d", output.SanitizeText(name), counts[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printTLSReport(r *TLSReport) {
+	pterm.DefaultSection.Printf("Protocols and TLS (%d hosts)\n", len(r.Hosts))
+	for _, h := range r.Hosts {
+		line := fmt.Sprintf("%s  %d request(s)", pterm.FgCyan.Sprint(output.SanitizeText(h.Host)), h.Requests)
+		if len(h.Protocols) > 0 {
+			line += "  " + tlsCounts(h.Protocols)
+		}
+		if h.Plaintext > 0 {
+			line += pterm.FgYellow.Sprintf("  plaintext ×%d", h.Plaintext)
+		}
+		fmt.Printf("\n%s\n", line)
+		if len(h.TLSVersions) > 0 {
+			details := "TLS: " + tlsCounts(h.TLSVersions)
+			if len(h.ALPN) > 0 {
+				details += "   ALPN: " + output.SanitizeText(strings.Join(h.ALPN, ", "))
+			}
+			if len(h.Ciphers) > 0 {
+				details += "   " + output.SanitizeText(truncateCell(strings.Join(h.Ciphers, ", "), 60))
+			}
+			fmt.Printf("  %s\n", details)
+		}
+		for _, c := range h.Certificates {
+			validity := ""
+			if c.ValidTo != "" {
+				validity = "  valid until " + c.ValidTo
+			}
+			if c.Expired {
+				validity = pterm.FgRed.Sprint("  expired " + c.ValidTo)
+			}
+			fmt.Printf("  cert %s  issuer %s%s\n", output.SanitizeText(c.Subject), output.SanitizeText(c.Issuer), validity)
+			if len(c.SANs) > 0 {
+				sans := make([]string, len(c.SANs))
+				for i, san := range c.SANs {
+					sans[i] = output.SanitizeText(san)
+					if containsString(c.Internal, san) {
+						sans[i] = pterm.FgYellow.Sprint(sans[i] + " ★")
+					}
+				}
+				fmt.Printf("    SANs: %s\n", strings.Join(sans, ", "))
+			}
+		}
+	}
+
+	if len(r.NewNames) > 0 {
+		fmt.Println()
+		pterm.DefaultSection.Printf("Certificate names never reached by the capture (%d)\n", len(r.NewNames))
+		for _, name := range r.NewNames {
+			label := output.SanitizeText(name)
+			if internalSAN(name) {
+				label = pterm.FgYellow.Sprint(label + " ★ looks internal")
+			}
+			fmt.Printf("  %s\n", label)
+		}
+	}
+	if len(r.MixedContent) > 0 {
+		fmt.Println()
+		pterm.DefaultSection.Printf("Mixed content (%d)\n", len(r.MixedContent))
+		for _, m := range r.MixedContent {
+			fmt.Printf("  %-14s %-10s %s  %s\n", m.ID, m.ResourceType, output.SanitizeText(truncateCell(m.URL, 70)),
+				pterm.FgGray.Sprint("from "+output.SanitizeText(truncateCell(m.PageURL, 50))))
+		}
+	}
+	if r.WithTLS == 0 {
+		fmt.Println()
+		pterm.Info.Println("No TLS details in this capture: they come from the extension and mitmproxy imports")
+	}
+}
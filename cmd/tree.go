@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	treeSaved     string
+	treeDepth     int
+	treeTemplates bool
+	treeParams    bool
+	treeFilter    requestFilterFlags
+)
+
+// treeNode is a host or path segment of the site map. Methods, statuses
+// and params cover requests ending exactly at this node.
+type treeNode struct {
+	Name     string      `json:"name"`
+	Path     string      `json:"path"`
+	Count    int         `json:"count"` // Requests at or below this node
+	Methods  []string    `json:"methods,omitempty"`
+	Statuses []int       `json:"statuses,omitempty"`
+	Params   []string    `json:"params,omitempty"` // Query parameter names
+	Children []*treeNode `json:"children,omitempty"`
+
+	children map[string]*treeNode
+	methods  map[string]bool
+	statuses map[int]bool
+	params   map[string]bool
+}
+
+var treeCmd = &cobra.Command{
+	Use:   "tree [filter flags]",
+	Short: "Show captured URLs as a site map tree",
+	Long: `Render the captured URL space as a directory-style tree: one root per
+host, path segments as nodes. Endpoints are annotated with the methods and
+status codes seen there, and each node with its request count.
+
+Examples:
+  rep tree -d target.com                  Site map of target.com
+  rep tree -d target.com --templates      Collapse IDs (/users/{id})
+  rep tree -d target.com --depth 2        Only the first two path levels
+  rep tree --api --params                 API endpoints with query parameter names
+  rep tree -d target.com -o json          Nested nodes (name, path, count, methods, statuses, children)`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := treeFilter.options()
+		if err != nil {
+			return err
+		}
+		requests, err := filterSource(treeSaved, opts)
+		if err != nil || requests == nil {
+			return err
+		}
+
+		roots := buildSiteTree(requests)
+
+		if getOutputMode() == "json" {
+			if roots == nil {
+				roots = []*treeNode{}
+			}
+			out, _ := sonic.MarshalIndent(roots, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(roots) == 0 {
+			return softFail(emptyError("No requests match"))
+		}
+		for i, root := range roots {
+			if i > 0 {
+				fmt.Println()
+			}
+			line := pterm.Bold.Sprint(output.SanitizeText(root.Name))
+			if len(root.Methods) > 0 {
+				// Requests for "/" itself
+				line += "  " + treeAnnotations(root)
+			}
+			fmt.Printf("%s %s\n", line, pterm.FgGray.Sprintf("(%d)", root.Count))
+			printTreeChildren(root, "", 1)
+		}
+		return nil
+	},
+}
+
+// buildSiteTree groups requests by host and path segment, sorted by name
+func buildSiteTree(requests []store.Request) []*treeNode {
+	hosts := make(map[string]*treeNode)
+	var roots []*treeNode
+
+	for _, req := range requests {
+		parsed, err := url.Parse(req.URL)
+		if err != nil || parsed.Host == "" {
+			continue
+		}
+		origin := parsed.Scheme + "://" + parsed.Host
+		root := hosts[origin]
+		if root == nil {
+			root = newTreeNode(origin, "/")
+			hosts[origin] = root
+			roots = append(roots, root)
+		}
+
+		path := parsed.EscapedPath()
+		if treeTemplates {
+			path = store.EndpointTemplate(path)
+		}
+		node := root
+		node.Count++
+		current := ""
+		for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+			if segment == "" {
+				continue
+			}
+			current += "/" + segment
+			child := node.children[segment]
+			if child == nil {
+				child = newTreeNode(segment, current)
+				node.children[segment] = child
+			}
+			node = child
+			node.Count++
+		}
+		if strings.HasSuffix(path, "/") && node != root {
+			// "/docs/" and "/docs" are separate endpoints
+			child := node.children[""]
+			if child == nil {
+				child = newTreeNode("/", current+"/")
+				node.children[""] = child
+			}
+			node = child
+			node.Count++
+		}
+
+		node.methods[req.Method] = true
+		if req.Response != nil && req.Response.Status > 0 {
+			node.statuses[req.Response.Status] = true
+		}
+		for name := range parsed.Query() {
+			node.params[name] = true
+		}
+	}
+
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].Name < roots[j].Name
+	})
+	for _, root := range roots {
+		finishTreeNode(root)
+	}
+	return roots
+}
+
+func newTreeNode(name, path string) *treeNode {
+	return &treeNode{
+		Name:     name,
+		Path:     path,
+		children: make(map[string]*treeNode),
+		methods:  make(map[string]bool),
+		statuses: make(map[int]bool),
+		params:   make(map[string]bool),
+	}
+}
+
+// finishTreeNode fills the exported slices from the build maps
+func finishTreeNode(node *treeNode) {
+	for method := range node.methods {
+		node.Methods = append(node.Methods, method)
+	}
+	sort.Strings(node.Methods)
+	for status := range node.statuses {
+		node.Statuses = append(node.Statuses, status)
+	}
+	sort.Ints(node.Statuses)
+	for param := range node.params {
+		node.Params = append(node.Params, param)
+	}
+	sort.Strings(node.Params)
+
+	for _, child := range node.children {
+		node.Children = append(node.Children, child)
+		finishTreeNode(child)
+	}
+	sort.Slice(node.Children, func(i, j int) bool {
+		return node.Children[i].Name < node.Children[j].Name
+	})
+}
+
+func printTreeChildren(node *treeNode, indent string, depth int) {
+	for i, child := range node.Children {
+		branch, next := "├── ", "│   "
+		if i == len(node.Children)-1 {
+			branch, next = "└── ", "    "
+		}
+
+		name := output.SanitizeText(child.Name)
+		if len(child.Children) > 0 {
+			name = pterm.FgCyan.Sprint(name)
+		}
+		line := indent + branch + name
+		if len(child.Methods) > 0 {
+			line += "  " + treeAnnotations(child)
+		}
+		if child.Count > 1 {
+			line += pterm.FgGray.Sprintf(" (%d)", child.Count)
+		}
+
+		if treeDepth > 0 && depth >= treeDepth && len(child.Children) > 0 {
+			line += pterm.FgGray.Sprintf(" … %d below", len(child.Children))
+			fmt.Println(line)
+			continue
+		}
+		fmt.Println(line)
+		printTreeChildren(child, indent+next, depth+1)
+	}
+}
+
+// treeAnnotations formats the methods, statuses and (with --params) query
+// parameters of an endpoint node
+func treeAnnotations(node *treeNode) string {
+	text := strings.Join(node.Methods, ",")
+	if len(node.Statuses) > 0 {
+		codes := make([]string, len(node.Statuses))
+		for i, status := range node.Statuses {
+			codes[i] = colorStatus(status, strconv.Itoa(status))
+		}
+		text += " [" + strings.Join(codes, ",") + "]"
+	}
+	if treeParams && len(node.Params) > 0 {
+		text += pterm.FgGray.Sprint(" ?" + strings.Join(node.Params, "&"))
+	}
+	return text
+}
+
+// colorStatus colors a status code label by class
+func colorStatus(status int, label string) string {
+	switch {
+	case status >= 500:
+		return pterm.FgRed.Sprint(label)
+	case status >= 400:
+		return pterm.FgYellow.Sprint(label)
+	case status >= 300:
+		return pterm.FgCyan.Sprint(label)
+	default:
+		return pterm.FgGreen.Sprint(label)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(treeCmd)
+	treeFilter.register(treeCmd)
+	treeCmd.Flags().StringVar(&treeSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	treeCmd.Flags().IntVar(&treeDepth, "depth", 0, "Only show this many path levels (0 = all)")
+	treeCmd.Flags().BoolVar(&treeTemplates, "templates", false, "Collapse ID-like segments into {id}")
+	treeCmd.Flags().BoolVar(&treeParams, "params", false, "Show query parameter names at endpoints")
+}
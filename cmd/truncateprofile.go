@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// truncateProfileFile is the truncation config file inside ~/.rep
+const truncateProfileFile = "truncate.json"
+
+// truncateProfile overrides body truncation settings; zero values keep the
+// setting below it
+type truncateProfile struct {
+	Mode        string `json:"mode,omitempty"`
+	MaxBody     int    `json:"max_body,omitempty"`
+	Tail        int    `json:"tail,omitempty"`
+	MaxString   int    `json:"max_string,omitempty"`
+	BinaryLabel *bool  `json:"binary_label,omitempty"`
+}
+
+// truncateProfiles is the truncation config file: top-level settings apply
+// to every command, and commands maps a command path ("list", "body",
+// "sessions export") to its own overrides:
+//
+//	{"max_body": 800, "commands": {"list": {"max_body": 200}, "body": {"mode": "strings"}}}
+type truncateProfiles struct {
+	truncateProfile
+	Commands map[string]truncateProfile `json:"commands,omitempty"`
+}
+
+var (
+	// loadedTruncateProfiles is the config file read before the command runs
+	loadedTruncateProfiles *truncateProfiles
+	// activeCommand is the running command path without "rep "
+	activeCommand string
+)
+
+// truncateProfilePath returns $REP_TRUNCATE_CONFIG or ~/.rep/truncate.json
+func truncateProfilePath() (string, error) {
+	if path := os.Getenv("REP_TRUNCATE_CONFIG"); path != "" {
+		return store.ExpandHomePath(path)
+	}
+	configDir, err := getRepConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, truncateProfileFile), nil
+}
+
+// loadTruncateProfiles reads and validates the truncation config file. A
+// missing file is no error.
+func loadTruncateProfiles() (*truncateProfiles, error) {
+	path, err := truncateProfilePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var profiles truncateProfiles
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("invalid truncation config %s: %w", path, err)
+	}
+	if _, err := parseTruncateMode(profiles.Mode); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for name, p := range profiles.Commands {
+		if _, err := parseTruncateMode(p.Mode); err != nil {
+			return nil, fmt.Errorf("%s, command %q: %w", path, name, err)
+		}
+	}
+	return &profiles, nil
+}
+
+// apply overrides cfg with the profile's settings
+func (p truncateProfile) apply(cfg *store.TruncateConfig) {
+	if mode, _ := parseTruncateMode(p.Mode); mode != "" {
+		cfg.Mode = mode
+	}
+	if p.MaxBody > 0 {
+		cfg.MaxBodySize = p.MaxBody
+	}
+	if p.Tail > 0 {
+		cfg.TailSize = p.Tail
+	}
+	if p.MaxString > 0 {
+		cfg.MaxString = p.MaxString
+	}
+	if p.BinaryLabel != nil {
+		cfg.BinaryAsLabel = *p.BinaryLabel
+	}
+}
+
+// applyTruncateProfiles overrides cfg with the config file: the top-level
+// settings, then those of the running command
+func applyTruncateProfiles(cfg *store.TruncateConfig) {
+	profiles := loadedTruncateProfiles
+	if profiles == nil {
+		return
+	}
+	profiles.truncateProfile.apply(cfg)
+	if p, ok := profiles.Commands[activeCommand]; ok {
+		p.apply(cfg)
+	}
+}
+
+// parseBinaryLabel validates a --binary-label value ("" means unset)
+func parseBinaryLabel(value string) (set bool, label bool, err error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "":
+		return false, false, nil
+	case "on", "true", "1":
+		return true, true, nil
+	case "off", "false", "0":
+		return true, false, nil
+	}
+	return false, false, fmt.Errorf("invalid binary label setting: %s (use on, off)", value)
+}
@@ -0,0 +1,722 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/repplus/rep-cli/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tuiSaved   string
+	tuiPrimary bool
+)
+
+// tuiReloadInterval is how often the live capture is reloaded
+const tuiReloadInterval = 2 * time.Second
+
+// Focus areas of the TUI, cycled with Tab
+const (
+	tuiFocusDomains = iota
+	tuiFocusList
+	tuiFocusPreview
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Full-screen terminal UI over the capture",
+	Long: `Browse the capture in a full-screen terminal UI instead of re-running
+list commands: a domain sidebar, the request list (refreshed every 2s
+while reading the live capture) and a preview of the selected request with
+pretty-printed bodies.
+
+Keys:
+  Up/Down, PgUp/PgDn, g/G   Move (in the focused pane)
+  Tab / Shift-Tab           Focus sidebar, list, preview
+  /                         Fuzzy filter (method, status, URL, ID); Esc clears
+  Enter                     Focus the preview
+  t                         Tag the request; tagged IDs are printed on exit
+  i                         Ignore the request's domain (rep ignore)
+  c                         Copy as curl to the clipboard (OSC 52 terminals)
+  r                         Reload now
+  q, Ctrl-C                 Quit
+
+Ignored domains are hidden, like in rep list; --primary shows only primary
+domains. Copying uses the terminal's clipboard sequence, which also works
+over SSH in most modern terminals (iTerm2, kitty, WezTerm, Windows
+Terminal, tmux with set-clipboard on).
+
+Examples:
+  rep tui
+  rep tui --primary
+  rep tui --saved latest
+  rep tui > tagged.txt                Keep the IDs tagged with t`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m := &tuiModel{saved: tuiSaved, primary: tuiPrimary, tagged: make(map[string]bool), focus: tuiFocusList}
+		if err := m.reload(); err != nil {
+			return err
+		}
+		m.cursor = len(m.view) - 1
+
+		// Draw on the terminal itself so stdout only gets the tagged IDs.
+		// bubbletea restores the terminal on quit, panic, SIGINT and SIGTERM.
+		out, err := tui.OpenOutput()
+		if errors.Is(err, tui.ErrNotTerminal) {
+			return fmt.Errorf("rep tui needs an interactive terminal (use rep list for pipes)")
+		}
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		m.out = out
+		_, err = tea.NewProgram(m, tea.WithAltScreen(), tea.WithOutput(out)).Run()
+
+		for _, id := range m.taggedIDs() {
+			fmt.Println(id)
+		}
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+	tuiCmd.Flags().StringVar(&tuiSaved, "saved", "", "Browse a saved session (ID or 'latest') instead of the live capture")
+	tuiCmd.Flags().BoolVar(&tuiPrimary, "primary", false, "Only primary domains")
+}
+
+// tuiDomain is one sidebar entry
+type tuiDomain struct {
+	Name  string
+	Count int
+}
+
+type tuiModel struct {
+	out     *tui.Output
+	saved   string
+	primary bool
+
+	width  int
+	height int
+
+	all     []store.Request // Source requests after the ignore/primary lists
+	domains []tuiDomain     // Sidebar, "" (all domains) first
+	view    []int           // Indexes into all after the domain and fuzzy filters
+
+	domain     string
+	query      string
+	filtering  bool
+	focus      int
+	cursor     int
+	top        int
+	sideCursor int
+	sideTop    int
+
+	previewID     string
+	preview       []string
+	previewWidth  int
+	previewScroll int
+
+	tagged map[string]bool
+	status string
+}
+
+// reload re-reads the source and re-applies the filters, keeping the
+// selection on the same request (or on the last row when it was there)
+func (m *tuiModel) reload() error {
+	selectedID := ""
+	atEnd := len(m.view) == 0 || m.cursor >= len(m.view)-1
+	if req := m.selected(); req != nil {
+		selectedID = req.ID
+	}
+
+	s, err := store.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load store: %w", err)
+	}
+	var requests []store.Request
+	if m.saved != "" {
+		session := resolveSession(s, m.saved)
+		if session == nil {
+			return notFoundError("session not found: %s", m.saved)
+		}
+		requests = append(requests, session.Requests...)
+	} else {
+		livePaths, err := store.GetLiveFilePaths()
+		if err != nil {
+			return fmt.Errorf("failed to get live path: %w", err)
+		}
+		if len(livePaths) > 1 || fileExists(livePaths[0]) {
+			// Bodies are loaded on demand for the preview
+			if requests, err = store.LoadLiveWithoutBodiesAll(livePaths); err != nil {
+				return fmt.Errorf("could not read live.json: %w", err)
+			}
+		}
+	}
+	tempStore := store.NewTempStore(requests)
+	tempStore.PrimaryDomains = s.PrimaryDomains
+	tempStore.IgnoredDomains = s.IgnoredDomains
+	tempStore.MutedPaths = s.MutedPaths
+	m.all = tempStore.Filter(store.FilterOptions{PrimaryOnly: m.primary, ExcludeIgnored: true})
+
+	counts := make(map[string]int)
+	for i := range m.all {
+		counts[m.all[i].Domain]++
+	}
+	m.domains = []tuiDomain{{Name: "", Count: len(m.all)}}
+	for name, n := range counts {
+		m.domains = append(m.domains, tuiDomain{Name: name, Count: n})
+	}
+	sort.SliceStable(m.domains[1:], func(i, j int) bool {
+		a, b := m.domains[1+i], m.domains[1+j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Name < b.Name
+	})
+	if _, ok := counts[m.domain]; m.domain != "" && !ok {
+		m.domain = ""
+	}
+	for i, d := range m.domains {
+		if d.Name == m.domain {
+			m.sideCursor = i
+		}
+	}
+
+	m.applyFilter()
+	switch {
+	case atEnd:
+		m.cursor = len(m.view) - 1
+	case selectedID != "":
+		for i, idx := range m.view {
+			if m.all[idx].ID == selectedID {
+				m.cursor = i
+			}
+		}
+	}
+	m.clamp()
+	return nil
+}
+
+// applyFilter rebuilds the view from the domain and fuzzy filters
+func (m *tuiModel) applyFilter() {
+	m.view = m.view[:0]
+	for i := range m.all {
+		req := &m.all[i]
+		if m.domain != "" && req.Domain != m.domain {
+			continue
+		}
+		if m.query != "" && !fuzzy.MatchFold(m.query, tuiSearchText(req)) {
+			continue
+		}
+		m.view = append(m.view, i)
+	}
+	m.clamp()
+}
+
+func tuiSearchText(req *store.Request) string {
+	return req.ID + " " + req.Method + " " + strconv.Itoa(responseStatus(req)) + " " + req.URL
+}
+
+func (m *tuiModel) clamp() {
+	if m.cursor >= len(m.view) {
+		m.cursor = len(m.view) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+func (m *tuiModel) selected() *store.Request {
+	if m.cursor < 0 || m.cursor >= len(m.view) {
+		return nil
+	}
+	return &m.all[m.view[m.cursor]]
+}
+
+func (m *tuiModel) taggedIDs() []string {
+	var ids []string
+	for id, tagged := range m.tagged {
+		if tagged {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// tuiReloadMsg triggers a reload of the live capture
+type tuiReloadMsg struct{}
+
+func tuiScheduleReload() tea.Cmd {
+	return tea.Tick(tuiReloadInterval, func(time.Time) tea.Msg { return tuiReloadMsg{} })
+}
+
+func (m *tuiModel) Init() tea.Cmd {
+	if m.saved == "" {
+		return tuiScheduleReload()
+	}
+	return nil
+}
+
+// Update handles key presses, resizes and live reloads
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		for _, key := range tui.Keys(msg) {
+			if !m.handleKey(key) {
+				return m, tea.Quit
+			}
+		}
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tuiReloadMsg:
+		if err := m.reload(); err != nil {
+			m.status = err.Error()
+		}
+		return m, tuiScheduleReload()
+	}
+	return m, nil
+}
+
+// handleKey applies one key press; false quits
+func (m *tuiModel) handleKey(key tui.Key) bool {
+	m.status = ""
+	if m.filtering {
+		switch key.Name {
+		case "enter":
+			m.filtering = false
+		case "esc":
+			m.filtering, m.query = false, ""
+		case "backspace":
+			if r := []rune(m.query); len(r) > 0 {
+				m.query = string(r[:len(r)-1])
+			}
+		case "ctrl-c":
+			return false
+		case "":
+			m.query += string(key.Rune)
+		}
+		m.applyFilter()
+		return true
+	}
+
+	switch key.Name {
+	case "ctrl-c":
+		return false
+	case "tab":
+		m.focus = (m.focus + 1) % 3
+		return true
+	case "backtab":
+		m.focus = (m.focus + 2) % 3
+		return true
+	case "enter":
+		m.focus = tuiFocusPreview
+		return true
+	case "esc":
+		if m.query != "" {
+			m.query = ""
+			m.applyFilter()
+		} else {
+			m.focus = tuiFocusList
+		}
+		return true
+	}
+	switch key.Rune {
+	case 'q':
+		return false
+	case '/':
+		m.filtering = true
+		return true
+	case 'r':
+		if err := m.reload(); err != nil {
+			m.status = err.Error()
+		}
+		return true
+	case 't':
+		if req := m.selected(); req != nil {
+			m.tagged[req.ID] = !m.tagged[req.ID]
+			m.status = fmt.Sprintf("%d tagged", len(m.taggedIDs()))
+			m.move(1)
+		}
+		return true
+	case 'i':
+		m.ignoreSelected()
+		return true
+	case 'c':
+		m.copyCurl()
+		return true
+	}
+	m.navigate(key)
+	return true
+}
+
+// pageSize is the number of rows of the list pane
+func (m *tuiModel) pageSize() int {
+	_, height := m.size()
+	listH, _ := tuiPaneHeights(height)
+	return listH - 1
+}
+
+// navigate moves within the focused pane
+func (m *tuiModel) navigate(key tui.Key) {
+	step := 0
+	switch {
+	case key.Name == "up" || key.Rune == 'k':
+		step = -1
+	case key.Name == "down" || key.Rune == 'j':
+		step = 1
+	case key.Name == "pgup":
+		step = -m.pageSize()
+	case key.Name == "pgdn":
+		step = m.pageSize()
+	case key.Name == "home" || key.Rune == 'g':
+		step = -1 << 30
+	case key.Name == "end" || key.Rune == 'G':
+		step = 1 << 30
+	case key.Name == "left":
+		m.focus = tuiFocusDomains
+		return
+	case key.Name == "right":
+		m.focus = tuiFocusList
+		return
+	default:
+		return
+	}
+	switch m.focus {
+	case tuiFocusDomains:
+		m.sideCursor = tuiClampIndex(m.sideCursor+step, len(m.domains))
+		m.domain = m.domains[m.sideCursor].Name
+		m.applyFilter()
+	case tuiFocusList:
+		m.move(step)
+	case tuiFocusPreview:
+		m.previewScroll += step
+		if m.previewScroll < 0 {
+			m.previewScroll = 0
+		}
+	}
+}
+
+func (m *tuiModel) move(step int) {
+	m.cursor = tuiClampIndex(m.cursor+step, len(m.view))
+}
+
+func tuiClampIndex(i, n int) int {
+	if i >= n {
+		i = n - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
+
+func (m *tuiModel) ignoreSelected() {
+	req := m.selected()
+	if req == nil || req.Domain == "" {
+		return
+	}
+	s, err := store.Get()
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+	s.Ignore(req.Domain)
+	if err := s.Save(); err != nil {
+		m.status = "failed to save: " + err.Error()
+		return
+	}
+	domain := req.Domain
+	if err := m.reload(); err != nil {
+		m.status = err.Error()
+		return
+	}
+	m.status = "Ignored " + domain + " (undo: rep ignore --remove " + domain + ")"
+}
+
+func (m *tuiModel) copyCurl() {
+	req := m.selected()
+	if req == nil {
+		return
+	}
+	full := *req
+	if err := store.LoadBodies(&full); err != nil {
+		m.status = err.Error()
+		return
+	}
+	dialect, err := currentShellDialect()
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+	m.out.Copy(generateCurl(&full, false, dialect))
+	m.status = "Copied curl for " + req.ID
+}
+
+// tuiPaneHeights splits the screen below the header between the list and
+// the preview (the footer takes the last line)
+func tuiPaneHeights(height int) (int, int) {
+	body := height - 2
+	if body < 4 {
+		body = 4
+	}
+	listH := body * 11 / 20
+	return listH, body - listH
+}
+
+// size returns the terminal width and height (80x24 until known)
+func (m *tuiModel) size() (int, int) {
+	if m.width <= 0 || m.height <= 0 {
+		return 80, 24
+	}
+	return m.width, m.height
+}
+
+func (m *tuiModel) View() string {
+	width, height := m.size()
+	listH, previewH := tuiPaneHeights(height)
+	sideW := 0
+	if width >= 60 {
+		sideW = width / 4
+		if sideW > 32 {
+			sideW = 32
+		}
+	}
+	mainW := width - sideW
+	if sideW > 0 {
+		mainW-- // Separator
+	}
+
+	source := "live"
+	if m.saved != "" {
+		source = "saved " + m.saved
+	}
+	header := fmt.Sprintf(" rep tui  %s  %d of %d requests", source, len(m.view), len(m.all))
+	if n := len(m.taggedIDs()); n > 0 {
+		header += fmt.Sprintf("  %d tagged", n)
+	}
+	if m.query != "" || m.filtering {
+		header += "  filter: " + m.query
+		if m.filtering {
+			header += "▏"
+		}
+	}
+	lines := []string{tui.Style("7", tui.Fit(header, width))}
+
+	side := m.renderDomains(sideW, listH+previewH)
+	list := m.renderList(mainW, listH)
+	preview := m.renderPreview(mainW, previewH)
+	right := append(list, preview...)
+	for i := range right {
+		line := right[i]
+		if sideW > 0 {
+			line = side[i] + tui.Style("2", "│") + line
+		}
+		lines = append(lines, line)
+	}
+
+	footer := " ↑↓ move  Tab focus  / filter  Enter preview  t tag  i ignore domain  c copy curl  r reload  q quit"
+	if m.status != "" {
+		footer = " " + m.status
+	}
+	lines = append(lines, tui.Style("7", tui.Fit(footer, width)))
+	return strings.Join(lines, "\n")
+}
+
+func (m *tuiModel) renderDomains(width, height int) []string {
+	lines := make([]string, height)
+	if width == 0 {
+		return lines
+	}
+	if m.sideCursor < m.sideTop {
+		m.sideTop = m.sideCursor
+	}
+	if m.sideCursor >= m.sideTop+height {
+		m.sideTop = m.sideCursor - height + 1
+	}
+	for i := range lines {
+		idx := m.sideTop + i
+		if idx >= len(m.domains) {
+			lines[i] = tui.Fit("", width)
+			continue
+		}
+		d := m.domains[idx]
+		name := d.Name
+		if name == "" {
+			name = "All domains"
+		}
+		count := strconv.Itoa(d.Count)
+		text := tui.Fit(" "+name, width-len(count)-1) + count + " "
+		switch {
+		case idx == m.sideCursor && m.focus == tuiFocusDomains:
+			text = tui.Style("7", text)
+		case idx == m.sideCursor:
+			text = tui.Style("1", text)
+		}
+		lines[i] = text
+	}
+	return lines
+}
+
+func (m *tuiModel) renderList(width, height int) []string {
+	lines := make([]string, 0, height)
+	title := " Requests"
+	if m.domain != "" {
+		title += " · " + m.domain
+	}
+	lines = append(lines, tui.Style(tuiPaneTitleStyle(m.focus == tuiFocusList), tui.Fit(title, width)))
+	rows := height - 1
+	if m.cursor < m.top {
+		m.top = m.cursor
+	}
+	if m.cursor >= m.top+rows {
+		m.top = m.cursor - rows + 1
+	}
+	if m.top < 0 {
+		m.top = 0
+	}
+	for i := 0; i < rows; i++ {
+		idx := m.top + i
+		if idx >= len(m.view) {
+			lines = append(lines, tui.Fit("", width))
+			continue
+		}
+		req := &m.all[m.view[idx]]
+		mark := " "
+		if m.tagged[req.ID] {
+			mark = "*"
+		}
+		status := responseStatus(req)
+		statusText := "-"
+		if status > 0 {
+			statusText = strconv.Itoa(status)
+		}
+		prefix := fmt.Sprintf("%s%-7s %-3s ", mark, truncateCell(req.Method, 7), statusText)
+		row := tui.Fit(prefix+output.SanitizeText(req.URL), width)
+		if idx == m.cursor {
+			sgr := "1"
+			if m.focus == tuiFocusList {
+				sgr = "7"
+			}
+			lines = append(lines, tui.Style(sgr, row))
+			continue
+		}
+		// Color the status column only, the rest of the row stays plain
+		statusStart := len(prefix) - 4
+		if len(row) < len(prefix) {
+			lines = append(lines, row)
+			continue
+		}
+		lines = append(lines, row[:statusStart]+tui.Style(tuiStatusStyle(status), row[statusStart:statusStart+3])+row[statusStart+3:])
+	}
+	return lines
+}
+
+func tuiPaneTitleStyle(focused bool) string {
+	if focused {
+		return "1;4"
+	}
+	return "2"
+}
+
+func tuiStatusStyle(status int) string {
+	switch {
+	case status >= 500:
+		return "31"
+	case status >= 400:
+		return "33"
+	case status >= 300:
+		return "36"
+	case status >= 200:
+		return "32"
+	}
+	return "2"
+}
+
+func (m *tuiModel) renderPreview(width, height int) []string {
+	req := m.selected()
+	lines := []string{tui.Style(tuiPaneTitleStyle(m.focus == tuiFocusPreview), tui.Fit(" Preview", width))}
+	if req == nil {
+		for len(lines) < height {
+			lines = append(lines, tui.Fit("", width))
+		}
+		return lines
+	}
+	if req.ID != m.previewID || width != m.previewWidth {
+		if req.ID != m.previewID {
+			m.previewScroll = 0
+		}
+		m.previewID, m.previewWidth = req.ID, width
+		m.preview = tuiPreviewLines(req, width-1)
+	}
+	if max := len(m.preview) - (height - 1); m.previewScroll > max {
+		m.previewScroll = max
+	}
+	if m.previewScroll < 0 {
+		m.previewScroll = 0
+	}
+	for i := 0; len(lines) < height; i++ {
+		idx := m.previewScroll + i
+		text := ""
+		if idx < len(m.preview) {
+			text = m.preview[idx]
+		}
+		lines = append(lines, tui.Fit(" "+text, width))
+	}
+	return lines
+}
+
+// tuiPreviewLines renders a request for the preview pane, bodies loaded on
+// demand and JSON bodies indented
+func tuiPreviewLines(req *store.Request, width int) []string {
+	full := *req
+	bodyErr := store.LoadBodies(&full)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", full.Method, full.URL)
+	if bodyErr != nil {
+		fmt.Fprintf(&b, "Bodies unavailable: %v\n", bodyErr)
+	}
+	if full.Response != nil {
+		fmt.Fprintf(&b, "→ %d  %s", full.Response.Status, output.FormatBodySize(len(full.Response.Body)))
+		if full.DurationMs > 0 {
+			fmt.Fprintf(&b, "  %.0fms", full.DurationMs)
+		}
+		b.WriteString("\n")
+		if ct := store.HeaderFirst(full.Response.Headers, "content-type"); ct != "" {
+			fmt.Fprintf(&b, "Content-Type: %s\n", ct)
+		}
+	}
+	if full.Body != "" {
+		b.WriteString("\n── Request body ──\n")
+		b.WriteString(tuiPrettyBody(full.Body))
+		b.WriteString("\n")
+	}
+	if full.Response != nil && full.Response.Body != "" {
+		b.WriteString("\n── Response body ──\n")
+		b.WriteString(tuiPrettyBody(full.Response.Body))
+	}
+	return tui.Wrap(output.SanitizeText(b.String()), width)
+}
+
+func tuiPrettyBody(body string) string {
+	trimmed := strings.TrimSpace(body)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var buf bytes.Buffer
+		if json.Indent(&buf, []byte(trimmed), "", "  ") == nil {
+			return buf.String()
+		}
+	}
+	return body
+}
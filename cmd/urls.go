@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/extract"
+	"github.com/repplus/rep-cli/internal/htmltext"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	urlsSaved       string
+	urlsHost        string
+	urlsUnrequested bool
+	urlsPaths       bool
+	urlsPlain       bool
+	urlsFilter      requestFilterFlags
+)
+
+// foundURL is a URL referenced by one or more response bodies
+type foundURL struct {
+	URL         string   `json:"url"`
+	Requested   bool     `json:"requested"`          // A captured request hit the same host and path
+	Sources     []string `json:"sources"`            // Request IDs whose bodies reference it (first 5)
+	SourceCount int      `json:"source_count"`       // All referencing requests
+	Relative    bool     `json:"relative,omitempty"` // Found as a path, resolved against its source
+}
+
+var urlsCmd = &cobra.Command{
+	Use:   "urls [filter flags]",
+	Short: "Extract URLs and paths referenced in response bodies",
+	Long: `Scan response bodies (HTML, JavaScript, JSON) for absolute URLs and
+quoted relative paths, dedupe them and mark which were actually requested
+and which are only referenced - unvisited pages and API routes worth
+feeding into ffuf or a crawler.
+
+Bodies to scan are picked with the usual filter flags (-d, -p, --type, ...).
+Relative paths resolve against the page that loaded the body (a script's
+"/api/..." belongs to the page origin, not the CDN serving the script).
+A URL counts as requested when a captured request has the same host and path.
+
+Output:
+  (default)        Requested (✓) / referenced-only (·) list with sources
+  --plain          One URL per line, nothing else
+  --paths          Unique paths only, one per line (ffuf wordlist)
+  -o json          url, requested, sources, source_count
+
+Examples:
+  rep urls -d target.com                          Everything target.com bodies reference
+  rep urls -d target.com --host target.com        Only URLs on target.com (and subdomains)
+  rep urls -d target.com --unrequested --plain    Referenced but never visited (crawl these)
+  rep urls --type script --paths > paths.txt      Paths from JS (ffuf -w paths.txt -u https://target.com/FUZZ)
+  rep urls --saved latest -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := urlsFilter.options()
+		if err != nil {
+			return err
+		}
+		requests, err := filterSource(urlsSaved, opts)
+		if err != nil || requests == nil {
+			return err
+		}
+
+		requested, err := capturedURLKeys(urlsSaved)
+		if err != nil {
+			return err
+		}
+
+		found := extractBodyURLs(requests, requested)
+		if urlsHost != "" {
+			kept := found[:0]
+			for _, f := range found {
+				if hostMatches(hostFromURL(f.URL), urlsHost) {
+					kept = append(kept, f)
+				}
+			}
+			found = kept
+		}
+		if urlsUnrequested {
+			kept := found[:0]
+			for _, f := range found {
+				if !f.Requested {
+					kept = append(kept, f)
+				}
+			}
+			found = kept
+		}
+
+		if urlsPaths {
+			seen := make(map[string]bool)
+			for _, f := range found {
+				parsed, err := url.Parse(f.URL)
+				if err != nil || parsed.Path == "" || parsed.Path == "/" || seen[parsed.Path] {
+					continue
+				}
+				seen[parsed.Path] = true
+				fmt.Println(strings.TrimPrefix(parsed.Path, "/"))
+			}
+			return nil
+		}
+		if urlsPlain {
+			for _, f := range found {
+				fmt.Println(f.URL)
+			}
+			return nil
+		}
+
+		requestedCount := 0
+		for _, f := range found {
+			if f.Requested {
+				requestedCount++
+			}
+		}
+
+		if getOutputMode() == "json" {
+			if found == nil {
+				found = []foundURL{}
+			}
+			out, _ := sonic.MarshalIndent(map[string]interface{}{
+				"total":      len(found),
+				"requested":  requestedCount,
+				"referenced": len(found) - requestedCount,
+				"scanned":    len(requests),
+				"urls":       found,
+			}, "", "  ")
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(found) == 0 {
+			return softFail(emptyError("No URLs found in %d response bodies", len(requests)))
+		}
+		pterm.DefaultSection.Printf("URLs (%d unique: %d requested, %d referenced only)\n",
+			len(found), requestedCount, len(found)-requestedCount)
+		for _, f := range found {
+			mark := pterm.FgGray.Sprint("·")
+			if f.Requested {
+				mark = pterm.FgGreen.Sprint("✓")
+			}
+			sources := strings.Join(f.Sources, ",")
+			if f.SourceCount > len(f.Sources) {
+				sources += fmt.Sprintf(" +%d", f.SourceCount-len(f.Sources))
+			}
+			fmt.Printf("  %s %s  %s\n", mark, output.SanitizeText(f.URL), pterm.FgGray.Sprint("["+sources+"]"))
+		}
+		fmt.Println()
+		pterm.Info.Printf("Scanned %d response bodies. Use --unrequested --plain to feed a crawler\n", len(requests))
+		return nil
+	},
+}
+
+// bodyURL is a resolved reference from one body
+type bodyURL struct {
+	url      string
+	relative bool
+}
+
+// extractBodyURLs collects URLs referenced by the response bodies of
+// requests, in order of first appearance
+func extractBodyURLs(requests []store.Request, requested map[string]bool) []foundURL {
+	var found []foundURL
+	index := make(map[string]int)
+
+	for i := range requests {
+		req := &requests[i]
+		if req.Response == nil || req.Response.Body == "" {
+			continue
+		}
+		body := store.ResponseBodyText(req)
+		source, _ := url.Parse(req.URL)
+		// Root-relative paths in scripts and API responses target the page
+		origin := source
+		if req.PageURL != "" {
+			if page, err := url.Parse(req.PageURL); err == nil && page.Host != "" {
+				origin = page
+			}
+		}
+
+		var refs []bodyURL
+		for _, ref := range extract.URLs(body) {
+			base := source
+			if strings.HasPrefix(ref.Value, "/") && !strings.HasPrefix(ref.Value, "//") {
+				base = origin
+			}
+			if resolved := extract.Resolve(ref, base); resolved != "" {
+				refs = append(refs, bodyURL{resolved, ref.Relative})
+			}
+		}
+		contentType := strings.ToLower(store.HeaderFirst(req.Response.Headers, "content-type"))
+		if strings.Contains(contentType, "html") {
+			for _, link := range htmltext.Links(body, req.URL) {
+				refs = append(refs, bodyURL{link.URL, link.Raw != ""})
+			}
+		}
+
+		for _, ref := range refs {
+			key := normalizeFoundURL(ref.url)
+			if key == "" {
+				continue
+			}
+			n, ok := index[key]
+			if !ok {
+				n = len(found)
+				index[key] = n
+				found = append(found, foundURL{
+					URL:       key,
+					Requested: requested[urlKey(key)],
+					Relative:  ref.relative,
+				})
+			}
+			f := &found[n]
+			if len(f.Sources) > 0 && f.Sources[len(f.Sources)-1] == req.ID {
+				continue
+			}
+			f.SourceCount++
+			if len(f.Sources) < 5 {
+				f.Sources = append(f.Sources, req.ID)
+			}
+		}
+	}
+	return found
+}
+
+// normalizeFoundURL drops fragments and rejects non-HTTP(S) URLs
+func normalizeFoundURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https", "ws", "wss":
+	default:
+		return ""
+	}
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	return parsed.String()
+}
+
+// urlKey identifies a URL by host and path, ignoring scheme and query
+func urlKey(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return strings.ToLower(parsed.Hostname()) + path
+}
+
+// capturedURLKeys returns the urlKey of every captured request in the
+// source, unfiltered, so references can be marked as requested
+func capturedURLKeys(saved string) (map[string]bool, error) {
+	var requests []store.Request
+	if saved != "" {
+		s, err := store.Get()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load store: %w", err)
+		}
+		if session := resolveSession(s, saved); session != nil {
+			requests = session.Requests
+		}
+	} else {
+		paths, err := store.GetLiveFilePaths()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get live path: %w", err)
+		}
+		requests, _ = store.LoadLiveWithoutBodiesAll(paths)
+	}
+
+	keys := make(map[string]bool, len(requests))
+	for _, req := range requests {
+		keys[urlKey(req.URL)] = true
+	}
+	return keys, nil
+}
+
+// hostMatches reports whether host is domain or one of its subdomains
+func hostMatches(host, domain string) bool {
+	host, domain = strings.ToLower(host), strings.ToLower(strings.TrimPrefix(domain, "."))
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+func init() {
+	rootCmd.AddCommand(urlsCmd)
+	urlsFilter.register(urlsCmd)
+	urlsCmd.Flags().StringVar(&urlsSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	urlsCmd.Flags().StringVar(&urlsHost, "host", "", "Only URLs on this host or its subdomains")
+	urlsCmd.Flags().BoolVar(&urlsUnrequested, "unrequested", false, "Only URLs referenced but never requested")
+	urlsCmd.Flags().BoolVar(&urlsPaths, "paths", false, "Print unique paths only (ffuf wordlist)")
+	urlsCmd.Flags().BoolVar(&urlsPlain, "plain", false, "Print URLs only, one per line")
+}
@@ -0,0 +1,407 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	versionsSaved  string
+	versionsDomain string
+	versionsAll    bool
+)
+
+// versionSegment matches a version path segment: v1, v2.1, v3beta, V10
+var versionSegment = regexp.MustCompile(`(?i)^v(\d+)(?:\.(\d+))?(?:[-_]?(alpha|beta)\d*)?$`)
+
+// versionDate matches date versions (2024-01-01), used in paths and
+// api-version parameters
+var versionDate = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})(?:-preview)?$`)
+
+// versionPlain matches bare numeric versions (2, 1.1), not cache busters
+var versionPlain = regexp.MustCompile(`^\d{1,2}(\.\d+){0,2}$`)
+
+// versionParams and versionHeaders carry a version outside the path
+var (
+	versionParams  = []string{"api-version", "api_version", "apiversion", "version", "v"}
+	versionHeaders = []string{"Api-Version", "X-Api-Version", "Accept-Version", "X-Version"}
+)
+
+// VersionSurface compares the endpoints each API version exposes
+type VersionSurface struct {
+	Versions  []APIVersion        `json:"versions"`  // Per host, oldest first
+	Endpoints []VersionedEndpoint `json:"endpoints"` // Dropped first, then added
+	Dropped   int                 `json:"dropped"`
+	Added     int                 `json:"added"`
+}
+
+// APIVersion is one version of a host's API
+type APIVersion struct {
+	Host      string `json:"host"`
+	Version   string `json:"version"`
+	Source    string `json:"source"` // path, query or header
+	Endpoints int    `json:"endpoints"`
+	Requests  int    `json:"requests"`
+}
+
+// VersionedEndpoint is an endpoint with its version replaced by {version},
+// and the versions it was captured in
+type VersionedEndpoint struct {
+	Endpoint string       `json:"endpoint"` // "GET api.target.com/{version}/users/{id}"
+	Host     string       `json:"host"`
+	Seen     []VersionHit `json:"seen"`          // Oldest first
+	Missing  []string     `json:"missing"`       // Versions of the host it wasn't captured in
+	Status   string       `json:"status"`        // dropped, added, gap, all
+	Try      []string     `json:"try,omitempty"` // URLs of the missing versions, from the newest capture
+}
+
+// VersionHit is the traffic of an endpoint in one version
+type VersionHit struct {
+	Version  string `json:"version"`
+	Requests int    `json:"requests"`
+	Statuses []int  `json:"statuses"`
+	ID       string `json:"id"` // Latest request
+}
+
+var versionsCmd = &cobra.Command{
+	Use:   "versions [-d host]",
+	Short: "Compare the endpoints of each API version to find forgotten ones",
+	Long: `Detect API versions in captured traffic (/v1/, /v2.1/, /2024-01-01/
+path segments, ?api-version= and similar parameters, Api-Version headers),
+group the endpoints of each host by version, and compare the versions'
+surfaces:
+
+  dropped  Captured in an older version but not in the newest one. Older
+           versions often stay deployed without the fixes and checks the
+           newest got: forgotten endpoints worth testing.
+  added    Only captured in newer versions. Try them on the older versions
+           too (the Try URLs): the old routes may serve them with weaker
+           authorization.
+  gap      Captured in the oldest and newest versions, not in one between.
+
+Endpoints are compared by method, host and path template, with the version
+replaced by {version}. Only hosts with two or more versions are compared.
+
+Examples:
+  rep versions -d api.target.com
+  rep versions --all                Endpoints present in every version too
+  rep versions -o json | jq '.endpoints[] | select(.status == "dropped")'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hosts, err := hostList(strings.ToLower(versionsDomain))
+		if err != nil {
+			return err
+		}
+		opts := store.FilterOptions{Domains: hosts}
+		requests, err := filterSource(versionsSaved, opts)
+		if err != nil || requests == nil {
+			return err
+		}
+		surface := buildVersionSurface(requests)
+		if !versionsAll {
+			kept := surface.Endpoints[:0]
+			for _, e := range surface.Endpoints {
+				if e.Status != "all" {
+					kept = append(kept, e)
+				}
+			}
+			surface.Endpoints = kept
+		}
+
+		if getOutputMode() == "json" {
+			printJSON("versions", surface)
+			return nil
+		}
+		if len(surface.Versions) == 0 {
+			return softFail(emptyError("No versioned API paths, parameters or headers found"))
+		}
+		printVersionSurface(surface)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionsCmd)
+	versionsCmd.Flags().StringVar(&versionsSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	versionsCmd.Flags().StringVarP(&versionsDomain, "domain", "d", "", "Only these hosts (comma-separated or @group)")
+	versionsCmd.Flags().BoolVar(&versionsAll, "all", false, "Also list endpoints captured in every version")
+}
+
+// requestVersion finds the API version of a request and the endpoint
+// template with the version replaced by {version}
+func requestVersion(req *store.Request) (version, source, template string) {
+	path, query, _ := strings.Cut(req.Path, "?")
+	template = store.EndpointTemplate(path)
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if versionSegment.MatchString(s) || versionDate.MatchString(s) {
+			segments[i] = "{version}"
+			return strings.ToLower(s), "path", store.EndpointTemplate(strings.Join(segments, "/"))
+		}
+	}
+	values, _ := url.ParseQuery(query)
+	for _, name := range versionParams {
+		if v := values.Get(name); isVersion(v) {
+			return strings.ToLower(v), "query", template
+		}
+	}
+	for _, name := range versionHeaders {
+		if v := store.HeaderFirst(req.Headers, name); isVersion(v) {
+			return strings.ToLower(v), "header", template
+		}
+	}
+	return "", "", template
+}
+
+// isVersion reports whether a parameter or header value is a version
+func isVersion(v string) bool {
+	return versionSegment.MatchString(v) || versionDate.MatchString(v) || versionPlain.MatchString(v)
+}
+
+// versionNumber is the numeric parts of a version (v2.1 -> 2 1), for
+// ordering; nil when v isn't a version
+func versionNumber(v string) []int {
+	if m := versionDate.FindStringSubmatch(v); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		d, _ := strconv.Atoi(m[3])
+		return []int{y, mo, d}
+	}
+	v = strings.TrimPrefix(strings.ToLower(v), "v")
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			if m := versionSegment.FindStringSubmatch("v" + p); m != nil {
+				n, _ = strconv.Atoi(m[1])
+				return append(parts, n)
+			}
+			return nil
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// versionLess orders versions oldest first
+func versionLess(a, b string) bool {
+	na, nb := versionNumber(a), versionNumber(b)
+	for i := 0; i < len(na) && i < len(nb); i++ {
+		if na[i] != nb[i] {
+			return na[i] < nb[i]
+		}
+	}
+	if len(na) != len(nb) {
+		return len(na) < len(nb)
+	}
+	return a < b
+}
+
+func buildVersionSurface(requests []store.Request) *VersionSurface {
+	surface := &VersionSurface{Versions: []APIVersion{}, Endpoints: []VersionedEndpoint{}}
+	versions := make(map[string]*APIVersion)             // host + version
+	endpointsOf := make(map[string]map[string]bool)      // host + version -> endpoint keys
+	endpoints := make(map[string]*VersionedEndpoint)     // endpoint key
+	hits := make(map[string]map[string]*VersionHit)      // endpoint key -> version
+	latest := make(map[string]map[string]*store.Request) // endpoint key -> version -> request
+	var order []string
+	for i := range requests {
+		req := &requests[i]
+		if req.Domain == "" {
+			store.ComputeRequestFields(req)
+		}
+		if containsString(staticResourceTypes, req.ResourceType) || req.Method == "OPTIONS" {
+			continue
+		}
+		version, source, template := requestVersion(req)
+		if version == "" {
+			continue
+		}
+		host := req.Domain
+		vkey := host + " " + version
+		v := versions[vkey]
+		if v == nil {
+			v = &APIVersion{Host: host, Version: version, Source: source}
+			versions[vkey] = v
+			endpointsOf[vkey] = make(map[string]bool)
+		}
+		v.Requests++
+
+		key := req.Method + " " + host + template
+		e := endpoints[key]
+		if e == nil {
+			e = &VersionedEndpoint{Endpoint: key, Host: host, Seen: []VersionHit{}, Missing: []string{}}
+			endpoints[key] = e
+			hits[key] = make(map[string]*VersionHit)
+			latest[key] = make(map[string]*store.Request)
+			order = append(order, key)
+		}
+		endpointsOf[vkey][key] = true
+		h := hits[key][version]
+		if h == nil {
+			h = &VersionHit{Version: version, Statuses: []int{}}
+			hits[key][version] = h
+		}
+		h.Requests++
+		h.ID = req.ID
+		addStatus(&h.Statuses, responseStatus(req))
+		latest[key][version] = req
+	}
+
+	hostVersions := make(map[string][]string)
+	for vkey, v := range versions {
+		v.Endpoints = len(endpointsOf[vkey])
+		hostVersions[v.Host] = append(hostVersions[v.Host], v.Version)
+	}
+	for host, list := range hostVersions {
+		sort.Slice(list, func(i, j int) bool { return versionLess(list[i], list[j]) })
+		if len(list) < 2 {
+			delete(hostVersions, host)
+			continue
+		}
+		for _, version := range list {
+			surface.Versions = append(surface.Versions, *versions[host+" "+version])
+		}
+	}
+	sort.SliceStable(surface.Versions, func(i, j int) bool {
+		a, b := surface.Versions[i], surface.Versions[j]
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		return versionLess(a.Version, b.Version)
+	})
+
+	for _, key := range order {
+		e := endpoints[key]
+		list, ok := hostVersions[e.Host]
+		if !ok {
+			continue
+		}
+		var newestSeen *store.Request
+		for _, version := range list {
+			if h := hits[key][version]; h != nil {
+				e.Seen = append(e.Seen, *h)
+				newestSeen = latest[key][version]
+			} else {
+				e.Missing = append(e.Missing, version)
+			}
+		}
+		newest, oldest := list[len(list)-1], list[0]
+		switch {
+		case len(e.Missing) == 0:
+			e.Status = "all"
+		case containsString(e.Missing, newest):
+			e.Status = "dropped"
+			surface.Dropped++
+		case containsString(e.Missing, oldest):
+			e.Status = "added"
+			surface.Added++
+		default:
+			e.Status = "gap" // Missing from a middle version only
+		}
+		for _, version := range e.Missing {
+			if u := versionURL(newestSeen, version); u != "" {
+				e.Try = append(e.Try, u)
+			}
+		}
+		surface.Endpoints = append(surface.Endpoints, *e)
+	}
+	rank := map[string]int{"dropped": 0, "added": 1, "gap": 2, "all": 3}
+	sort.SliceStable(surface.Endpoints, func(i, j int) bool {
+		return rank[surface.Endpoints[i].Status] < rank[surface.Endpoints[j].Status]
+	})
+	return surface
+}
+
+// versionURL rewrites a request's URL to another version of its API
+func versionURL(req *store.Request, version string) string {
+	current, source, _ := requestVersion(req)
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return ""
+	}
+	switch source {
+	case "path":
+		segments := strings.Split(u.Path, "/")
+		for i, s := range segments {
+			if strings.EqualFold(s, current) {
+				segments[i] = version
+				break
+			}
+		}
+		u.Path, u.RawPath = strings.Join(segments, "/"), ""
+	case "query":
+		q := u.Query()
+		for _, name := range versionParams {
+			if strings.EqualFold(q.Get(name), current) {
+				q.Set(name, version)
+				break
+			}
+		}
+		u.RawQuery = q.Encode()
+	default:
+		return "" // A header version: same URL
+	}
+	return u.String()
+}
+
+func printVersionSurface(s *VersionSurface) {
+	pterm.DefaultSection.Printf("API versions (%d dropped, %d added)\n", s.Dropped, s.Added)
+	host := ""
+	for _, v := range s.Versions {
+		if v.Host != host {
+			host = v.Host
+			fmt.Printf("  %s\n", pterm.FgCyan.Sprint(output.SanitizeText(host)))
+		}
+		fmt.Printf("    %-12s %-6s %3d endpoint(s) %5d request(s)\n", output.SanitizeText(v.Version), v.Source, v.Endpoints, v.Requests)
+	}
+	if len(s.Versions) == 0 {
+		fmt.Println("  No host has two or more versions to compare")
+		return
+	}
+
+	fmt.Println()
+	if len(s.Endpoints) == 0 {
+		pterm.Info.Println("Every endpoint was captured in every version")
+		return
+	}
+	for _, e := range s.Endpoints {
+		status := e.Status
+		switch status {
+		case "dropped":
+			status = pterm.FgRed.Sprintf("%-7s", status)
+		case "added":
+			status = pterm.FgYellow.Sprintf("%-7s", status)
+		default:
+			status = fmt.Sprintf("%-7s", status)
+		}
+		var seen []string
+		for _, h := range e.Seen {
+			statuses := make([]string, len(h.Statuses))
+			for i, code := range h.Statuses {
+				statuses[i] = colorStatus(code, strconv.Itoa(code))
+			}
+			seen = append(seen, fmt.Sprintf("%s %s", output.SanitizeText(h.Version), strings.Join(statuses, ",")))
+		}
+		fmt.Printf("  %s %-55s %s\n", status, output.SanitizeText(truncateCell(e.Endpoint, 55)), strings.Join(seen, "  "))
+		if len(e.Missing) > 0 {
+			fmt.Printf("          %s\n", pterm.FgGray.Sprint("not captured in "+strings.Join(e.Missing, ", ")))
+		}
+		for _, u := range e.Try {
+			fmt.Printf("          %s\n", pterm.FgGray.Sprint("try "+output.SanitizeText(u)))
+		}
+	}
+	if s.Dropped > 0 {
+		fmt.Println()
+		pterm.Info.Println("Dropped endpoints: replay them on the newest version, and test the old routes' authorization with 'rep replay <id> --edit'")
+	}
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/repplus/rep-cli/internal/output"
+	"github.com/repplus/rep-cli/internal/store"
+	"github.com/repplus/rep-cli/internal/waf"
+	"github.com/spf13/cobra"
+)
+
+var (
+	vhostsSaved  string
+	vhostsDomain string
+	vhostsShared bool
+)
+
+// VHostInventory groups the hostnames of the capture by the IP serving them
+type VHostInventory struct {
+	IPs        []VHostIP    `json:"ips"`        // Most hostnames first
+	Unresolved []VHostName  `json:"unresolved"` // Hosts captured without a remote IP
+	Overrides  []VHostAlias `json:"overrides"`  // Host headers naming another host than the URL
+}
+
+// VHostIP is one destination IP and the virtual hosts it served
+type VHostIP struct {
+	IP          string      `json:"ip"`
+	Shared      bool        `json:"shared"`       // Serves more than one hostname
+	BaseDomains []string    `json:"base_domains"` // Registrable domains of its hosts
+	Edge        []string    `json:"edge"`         // CDN / WAF fingerprinted in its responses
+	Servers     []string    `json:"servers"`      // Server header values
+	Requests    int         `json:"requests"`
+	Hosts       []VHostName `json:"hosts"`
+}
+
+// VHostName is a hostname (URL host, the TLS SNI) and its traffic
+type VHostName struct {
+	Host     string `json:"host"`
+	Requests int    `json:"requests"`
+	Example  string `json:"example"` // Request ID
+}
+
+// VHostAlias is a Host or :authority header that differs from the URL's
+// host, as proxies and edited replays send
+type VHostAlias struct {
+	URLHost string   `json:"url_host"`
+	Header  string   `json:"header"`
+	IDs     []string `json:"ids"`
+}
+
+var vhostsCmd = &cobra.Command{
+	Use:   "vhosts",
+	Short: "Inventory hostnames per destination IP to find shared infrastructure",
+	Long: `Group every hostname of the capture (the URL host, which is also the
+TLS SNI) by the IP that served it, when the capture recorded remote IPs.
+An IP serving several hostnames is shared infrastructure: other virtual
+hosts may answer on it, so it is worth vhost fuzzing (ffuf -H "Host: FUZZ"
+against the IP). IPs fronted by a CDN or WAF are marked, as fuzzing them
+reaches the edge rather than the origin.
+
+Also lists Host and :authority headers that name another host than the
+URL, as sent through proxies or by edited replays, and hosts captured
+without an IP.
+
+Examples:
+  rep vhosts
+  rep vhosts -d target.com --shared       IPs serving several hostnames
+  rep vhosts --saved latest -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scope, err := baseDomainScope(vhostsDomain)
+		if err != nil {
+			return err
+		}
+		requests, err := filterSource(vhostsSaved, store.FilterOptions{})
+		if err != nil || requests == nil {
+			return err
+		}
+		inv := buildVHosts(requests, scope)
+		if vhostsShared {
+			kept := inv.IPs[:0]
+			for _, ip := range inv.IPs {
+				if ip.Shared {
+					kept = append(kept, ip)
+				}
+			}
+			inv.IPs = kept
+		}
+
+		if getOutputMode() == "json" {
+			printJSON("vhosts", inv)
+			return nil
+		}
+		printVHosts(inv)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(vhostsCmd)
+	vhostsCmd.Flags().StringVar(&vhostsSaved, "saved", "", "Read from saved session (ID or 'latest')")
+	vhostsCmd.Flags().StringVarP(&vhostsDomain, "domain", "d", "", "Only IPs serving a host of these registrable domains (comma-separated or @group)")
+	vhostsCmd.Flags().BoolVar(&vhostsShared, "shared", false, "Only IPs serving more than one hostname")
+}
+
+func buildVHosts(requests []store.Request, scope domainScope) *VHostInventory {
+	inv := &VHostInventory{IPs: []VHostIP{}, Unresolved: []VHostName{}, Overrides: []VHostAlias{}}
+	byIP := make(map[string]*VHostIP)
+	hostIndex := make(map[string]int) // ip + host -> index in Hosts
+	unresolved := make(map[string]int)
+	aliases := make(map[string]int)
+	for i := range requests {
+		req := &requests[i]
+		host := hostFromURL(req.URL)
+		if host == "" {
+			continue
+		}
+		for _, name := range []string{"host", ":authority"} {
+			header := strings.ToLower(store.HeaderFirst(req.Headers, name))
+			if h := strings.Split(header, ":")[0]; h != "" && h != strings.ToLower(host) {
+				key := host + " " + header
+				k, ok := aliases[key]
+				if !ok {
+					k = len(inv.Overrides)
+					aliases[key] = k
+					inv.Overrides = append(inv.Overrides, VHostAlias{URLHost: host, Header: header})
+				}
+				inv.Overrides[k].IDs = append(inv.Overrides[k].IDs, req.ID)
+			}
+		}
+
+		if req.RemoteIP == "" {
+			k, ok := unresolved[host]
+			if !ok {
+				k = len(inv.Unresolved)
+				unresolved[host] = k
+				inv.Unresolved = append(inv.Unresolved, VHostName{Host: host, Example: req.ID})
+			}
+			inv.Unresolved[k].Requests++
+			continue
+		}
+		ip := byIP[req.RemoteIP]
+		if ip == nil {
+			ip = &VHostIP{IP: req.RemoteIP, BaseDomains: []string{}, Edge: []string{}, Servers: []string{}}
+			byIP[req.RemoteIP] = ip
+		}
+		ip.Requests++
+		k, ok := hostIndex[req.RemoteIP+" "+host]
+		if !ok {
+			k = len(ip.Hosts)
+			hostIndex[req.RemoteIP+" "+host] = k
+			ip.Hosts = append(ip.Hosts, VHostName{Host: host, Example: req.ID})
+		}
+		ip.Hosts[k].Requests++
+		if base := store.GetBaseDomain(host); !containsString(ip.BaseDomains, base) {
+			ip.BaseDomains = append(ip.BaseDomains, base)
+		}
+		if req.Response != nil {
+			if server := store.HeaderFirst(req.Response.Headers, "server"); server != "" && !containsString(ip.Servers, server) {
+				ip.Servers = append(ip.Servers, server)
+			}
+			for _, m := range waf.Detect(req) {
+				if !containsString(ip.Edge, m.Name) {
+					ip.Edge = append(ip.Edge, m.Name)
+				}
+			}
+		}
+	}
+
+	for _, ip := range byIP {
+		if len(scope) > 0 && !ip.inScope(scope) {
+			continue
+		}
+		ip.Shared = len(ip.Hosts) > 1
+		sort.Strings(ip.BaseDomains)
+		sort.Slice(ip.Hosts, func(i, j int) bool { return ip.Hosts[i].Requests > ip.Hosts[j].Requests })
+		inv.IPs = append(inv.IPs, *ip)
+	}
+	sort.Slice(inv.IPs, func(i, j int) bool {
+		a, b := inv.IPs[i], inv.IPs[j]
+		if len(a.Hosts) != len(b.Hosts) {
+			return len(a.Hosts) > len(b.Hosts)
+		}
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.IP < b.IP
+	})
+	if len(scope) > 0 {
+		kept := inv.Unresolved[:0]
+		for _, h := range inv.Unresolved {
+			if scope.covers(h.Host) {
+				kept = append(kept, h)
+			}
+		}
+		inv.Unresolved = kept
+		overrides := inv.Overrides[:0]
+		for _, a := range inv.Overrides {
+			if scope.covers(a.URLHost) {
+				overrides = append(overrides, a)
+			}
+		}
+		inv.Overrides = overrides
+	}
+	sort.SliceStable(inv.Unresolved, func(i, j int) bool { return inv.Unresolved[i].Requests > inv.Unresolved[j].Requests })
+	return inv
+}
+
+func printVHosts(inv *VHostInventory) {
+	shared := 0
+	for _, ip := range inv.IPs {
+		if ip.Shared {
+			shared++
+		}
+	}
+	pterm.DefaultSection.Printf("Virtual hosts by IP (%d IPs, %d shared)\n", len(inv.IPs), shared)
+	for _, ip := range inv.IPs {
+		label := pterm.FgCyan.Sprint(ip.IP)
+		if ip.Shared {
+			label += pterm.FgYellow.Sprintf("  shared: %d hosts", len(ip.Hosts))
+			if len(ip.BaseDomains) > 1 {
+				label += pterm.FgYellow.Sprintf(" across %d domains", len(ip.BaseDomains))
+			}
+		}
+		if len(ip.Edge) > 0 {
+			label += pterm.FgGray.Sprint("  edge: " + strings.Join(ip.Edge, ", "))
+		}
+		if len(ip.Servers) > 0 {
+			label += pterm.FgGray.Sprint("  server: " + output.SanitizeText(truncateCell(strings.Join(ip.Servers, ", "), 40)))
+		}
+		fmt.Printf("\n%s\n", label)
+		for _, h := range ip.Hosts {
+			fmt.Printf("  %-45s %5d request(s)  %s\n", output.SanitizeText(h.Host), h.Requests, h.Example)
+		}
+	}
+	if len(inv.IPs) == 0 {
+		fmt.Println("  none: the capture has no remote IPs")
+	}
+
+	if len(inv.Overrides) > 0 {
+		fmt.Println()
+		pterm.DefaultSection.Printf("Host headers differing from the URL (%d)\n", len(inv.Overrides))
+		for _, a := range inv.Overrides {
+			fmt.Printf("  %-35s Host: %-35s %s\n", output.SanitizeText(a.URLHost), output.SanitizeText(a.Header), strings.Join(a.IDs, ", "))
+		}
+	}
+	if len(inv.Unresolved) > 0 {
+		fmt.Println()
+		pterm.DefaultSection.Printf("Hosts without a remote IP (%d)\n", len(inv.Unresolved))
+		for _, h := range inv.Unresolved {
+			fmt.Printf("  %-45s %5d request(s)\n", output.SanitizeText(h.Host), h.Requests)
+		}
+	}
+
+	for _, ip := range inv.IPs {
+		if ip.Shared && len(ip.Edge) == 0 {
+			addr := ip.IP
+			if strings.Contains(addr, ":") {
+				addr = "[" + addr + "]"
+			}
+			fmt.Println()
+			pterm.Info.Printf("Fuzz other vhosts on %s: ffuf -u https://%s/ -H \"Host: FUZZ.%s\" -w subdomains.txt -fs <default size>\n",
+				ip.IP, addr, ip.BaseDomains[0])
+			break
+		}
+	}
+}
+
+// inScope reports whether the IP serves a host of the scope's domains
+func (ip *VHostIP) inScope(scope domainScope) bool {
+	for _, base := range ip.BaseDomains {
+		if scope[base] {
+			return true
+		}
+	}
+	return false
+}
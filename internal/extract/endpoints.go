@@ -0,0 +1,178 @@
+package extract
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Endpoint is a candidate API path found in JavaScript
+type Endpoint struct {
+	Path   string   `json:"path"`             // As written; template parts become {param}
+	Method string   `json:"method,omitempty"` // When the call site shows it
+	Via    []string `json:"via"`              // fetch, axios, xhr, jquery, client, url-field, string
+}
+
+var (
+	// LinkFinder's expression (https://github.com/GerbenJavado/LinkFinder):
+	// quoted absolute URLs, relative paths, paths with an extension or at
+	// least two segments, and bare file names with a server-side extension
+	linkFinderPattern = regexp.MustCompile(`(?:"|'|` + "`" + `)(` +
+		`(?:[a-zA-Z]{1,10}://|//)[^"'/` + "`" + `]{1,}\.[a-zA-Z]{2,}[^"'` + "`" + `]{0,}` +
+		`|(?:/|\.\./|\./)[^"'><,;| *()%$^/\\\[\]` + "`" + `][^"'><,;|()` + "`" + `]{1,}` +
+		`|[a-zA-Z0-9_\-/]{1,}/[a-zA-Z0-9_\-/]{1,}\.(?:[a-zA-Z]{1,4}|action)(?:[\?#][^"'` + "`" + `]{0,})?` +
+		`|[a-zA-Z0-9_\-/]{1,}/[a-zA-Z0-9_\-/]{3,}(?:[\?#][^"'` + "`" + `]{0,})?` +
+		`|[a-zA-Z0-9_\-]{1,}\.(?:php|asp|aspx|jsp|json|action|html|js|txt|xml)(?:[\?#][^"'` + "`" + `]{0,})?` +
+		`)(?:"|'|` + "`" + `)`)
+
+	quoted = `\s*["'` + "`" + `]([^"'` + "`" + `]+)["'` + "`" + `]`
+
+	// Call sites that reveal how a path is requested
+	callPatterns = []struct {
+		via     string
+		pattern *regexp.Regexp
+	}{
+		{"fetch", regexp.MustCompile(`\bfetch\(` + quoted)},
+		{"axios", regexp.MustCompile(`(?i)\baxios(?:\.(get|post|put|patch|delete|head|options|request))?\(` + quoted)},
+		{"xhr", regexp.MustCompile(`(?i)\.open\(\s*["'](GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS)["']\s*,` + quoted)},
+		{"jquery", regexp.MustCompile(`\$\.(ajax|get|post|getJSON|getScript)\(` + quoted)},
+		{"client", regexp.MustCompile(`\.(get|post|put|patch|delete)\(` + `\s*["'` + "`" + `](/[^"'` + "`" + `]*)["'` + "`" + `]`)},
+		{"url-field", regexp.MustCompile(`\burl\s*:` + quoted)},
+	}
+
+	// fetch(url, {method: "POST"}) and $.ajax({url, type: "POST"})
+	methodOptionPattern = regexp.MustCompile(`(?i)\b(?:method|type)\s*:\s*["'](GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS)["']`)
+	templatePartPattern = regexp.MustCompile(`\$\{[^}]*\}`)
+	mimePattern         = regexp.MustCompile(`^(?:application|text|image|audio|video|font|multipart|message|model)/[\w.+-]+$`)
+	dateFormatPattern   = regexp.MustCompile(`^(?i)[mdy]{1,4}/[mdy]{1,4}`)
+	staticExtPattern    = regexp.MustCompile(`(?i)\.(?:png|jpe?g|gif|svg|ico|webp|avif|bmp|css|woff2?|ttf|otf|eot|mp[34]|webm|ogg|wav|map)(?:[?#]|$)`)
+)
+
+// Endpoints returns candidate endpoints referenced by JavaScript source,
+// sorted by path. Call sites (fetch, axios, XHR, jQuery, HTTP clients) add
+// the method when it is visible.
+func Endpoints(js string) []Endpoint {
+	js = unescapeSlashes(js)
+	byKey := make(map[string]*Endpoint)
+	add := func(path, method, via string) {
+		path = cleanEndpoint(path)
+		if path == "" {
+			return
+		}
+		method = strings.ToUpper(method)
+		key := path + " " + method
+		ep := byKey[key]
+		if ep == nil {
+			// A method-less string match is subsumed by a call site
+			if method == "" {
+				for _, other := range byKey {
+					if other.Path == path {
+						addVia(other, via)
+						return
+					}
+				}
+			} else if plain := byKey[path+" "]; plain != nil {
+				delete(byKey, path+" ")
+				plain.Method = method
+				byKey[key] = plain
+				addVia(plain, via)
+				return
+			}
+			ep = &Endpoint{Path: path, Method: method}
+			byKey[key] = ep
+		}
+		addVia(ep, via)
+	}
+
+	for _, call := range callPatterns {
+		for _, m := range call.pattern.FindAllStringSubmatchIndex(js, -1) {
+			method, path := "", ""
+			switch call.via {
+			case "fetch", "url-field":
+				path = js[m[2]:m[3]]
+				method = methodNear(js[m[1]:])
+				if method == "" && call.via == "fetch" {
+					method = "GET"
+				}
+			case "jquery":
+				path = js[m[4]:m[5]]
+				switch verb := js[m[2]:m[3]]; verb {
+				case "post":
+					method = "POST"
+				case "ajax":
+					method = methodNear(js[m[1]:])
+				default:
+					method = "GET"
+				}
+			default:
+				if m[2] >= 0 {
+					method = js[m[2]:m[3]]
+				}
+				path = js[m[4]:m[5]]
+				if strings.EqualFold(method, "request") {
+					method = methodNear(js[m[1]:])
+				}
+			}
+			add(path, method, call.via)
+		}
+	}
+	for _, m := range linkFinderPattern.FindAllStringSubmatch(js, -1) {
+		add(m[1], "", "string")
+	}
+
+	endpoints := make([]Endpoint, 0, len(byKey))
+	for _, ep := range byKey {
+		endpoints = append(endpoints, *ep)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Path != endpoints[j].Path {
+			return endpoints[i].Path < endpoints[j].Path
+		}
+		return endpoints[i].Method < endpoints[j].Method
+	})
+	return endpoints
+}
+
+// methodNear finds a method option in the call's arguments
+func methodNear(rest string) string {
+	if len(rest) > 300 {
+		rest = rest[:300]
+	}
+	// Stop at the next statement so a later call's options don't leak in
+	if end := strings.IndexAny(rest, ";"); end >= 0 {
+		rest = rest[:end]
+	}
+	if m := methodOptionPattern.FindStringSubmatch(rest); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+func addVia(ep *Endpoint, via string) {
+	for _, v := range ep.Via {
+		if v == via {
+			return
+		}
+	}
+	ep.Via = append(ep.Via, via)
+}
+
+// cleanEndpoint normalizes a candidate and rejects what is clearly not an
+// endpoint (MIME types, date formats, static assets, prose)
+func cleanEndpoint(path string) string {
+	path = strings.TrimSpace(templatePartPattern.ReplaceAllString(path, "{param}"))
+	if len(path) < 2 || strings.ContainsAny(path, " \t\n\r") || !hasLetterPattern.MatchString(path) {
+		return ""
+	}
+	if mimePattern.MatchString(path) || dateFormatPattern.MatchString(path) || staticExtPattern.MatchString(path) {
+		return ""
+	}
+	if strings.HasPrefix(path, "/*") || strings.HasPrefix(path, "node_modules/") {
+		return ""
+	}
+	lower := strings.ToLower(path)
+	if strings.HasPrefix(lower, "data:") || strings.HasPrefix(lower, "javascript:") || strings.HasPrefix(lower, "http://www.w3.org/") {
+		return ""
+	}
+	return path
+}
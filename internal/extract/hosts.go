@@ -0,0 +1,63 @@
+package extract
+
+import (
+	"regexp"
+	"strings"
+)
+
+// hostEscapes undoes encodings that glue a hostname to what precedes it
+// ("%2F%2Fapi.example.com", "\nwww.example.com" in a JS string)
+var hostEscapes = strings.NewReplacer(
+	`%2F`, "/", `%2f`, "/", `%3A`, ":", `%3a`, ":",
+	`\n`, " ", `\r`, " ", `\t`, " ",
+	`\u002e`, ".", `\u002E`, ".", `\x2e`, ".", `\x2E`, ".",
+)
+
+// HostPattern compiles the matcher used by Hosts for domain, so callers
+// scanning many bodies compile it once
+func HostPattern(domain string) *regexp.Regexp {
+	domain = strings.Trim(strings.ToLower(domain), ".")
+	return regexp.MustCompile(`(?i)(?:[a-z0-9-]+\.)*` + regexp.QuoteMeta(domain))
+}
+
+// Hosts returns the hostnames in text that are domain or one of its
+// subdomains, lower-cased, in order of first appearance, without
+// duplicates. pattern comes from HostPattern(domain).
+func Hosts(text string, pattern *regexp.Regexp) []string {
+	text = hostEscapes.Replace(unescapeSlashes(text))
+
+	var hosts []string
+	seen := make(map[string]bool)
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		// A longer label on either side means another domain
+		// ("notexample.com", "example.com.evil.net")
+		if start > 0 && isHostChar(text[start-1]) {
+			continue
+		}
+		if end < len(text) && (isHostChar(text[end]) || (text[end] == '.' && end+1 < len(text) && isHostChar(text[end+1]))) {
+			continue
+		}
+		host := strings.ToLower(text[start:end])
+		if !validHost(host) || seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+func isHostChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+// validHost rejects labels no DNS name can have
+func validHost(host string) bool {
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+	}
+	return len(host) <= 253
+}
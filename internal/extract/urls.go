@@ -0,0 +1,92 @@
+// Package extract finds URLs, paths and hostnames referenced in captured
+// bodies (HTML, JavaScript, JSON). Matching is regex based: it favours
+// recall on minified and escaped content over strict parsing.
+package extract
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	absoluteURLPattern = regexp.MustCompile(`(?i)\b(?:https?|wss?)://[a-z0-9][a-z0-9.-]*(?::\d+)?(?:[/?#][^\s"'<>()\\{}|^` + "`" + `]*)?`)
+	// Protocol-relative and relative references only count inside quotes,
+	// which keeps prose, regexes and division out
+	quotedRefPattern = regexp.MustCompile(`["'` + "`" + `]((?://|\.\.?/|/)[^\s"'` + "`" + `<>\\{}|^]*)["'` + "`" + `]`)
+	hasLetterPattern = regexp.MustCompile(`[A-Za-z]`)
+)
+
+// Ref is a URL or path found in a body
+type Ref struct {
+	Value    string // As found (after unescaping)
+	Relative bool   // Path or protocol-relative; resolve before use
+}
+
+// URLs returns the absolute URLs and quoted relative paths in body, in
+// order of first appearance, without duplicates. JSON-escaped slashes
+// ("https:\/\/...") are unescaped first.
+func URLs(body string) []Ref {
+	body = unescapeSlashes(body)
+
+	var refs []Ref
+	seen := make(map[string]bool)
+	add := func(value string, relative bool) {
+		if value == "" || seen[value] {
+			return
+		}
+		seen[value] = true
+		refs = append(refs, Ref{Value: value, Relative: relative})
+	}
+
+	for _, m := range absoluteURLPattern.FindAllString(body, -1) {
+		add(strings.TrimRight(m, ".,;:!?)]}'\""), false)
+	}
+	for _, m := range quotedRefPattern.FindAllStringSubmatch(body, -1) {
+		if value := m[1]; isLikelyPath(value) {
+			add(value, true)
+		}
+	}
+	return refs
+}
+
+// Resolve turns a reference into an absolute URL against base. Returns ""
+// when it can't be resolved.
+func Resolve(ref Ref, base *url.URL) string {
+	if !ref.Relative {
+		return ref.Value
+	}
+	if base == nil {
+		return ""
+	}
+	resolved, err := base.Parse(ref.Value)
+	if err != nil || resolved.Host == "" {
+		return ""
+	}
+	return resolved.String()
+}
+
+// isLikelyPath filters quoted strings that start like a path but are not
+// one: lone slashes, comment markers, MIME-ish fragments, regexes
+func isLikelyPath(value string) bool {
+	if len(value) < 2 || !hasLetterPattern.MatchString(value) {
+		return false
+	}
+	if strings.HasPrefix(value, "//") {
+		// Protocol-relative: needs a dotted host
+		host := strings.SplitN(value[2:], "/", 2)[0]
+		return strings.Contains(host, ".") && !strings.ContainsAny(host, " *")
+	}
+	if strings.HasPrefix(value, "/*") || strings.ContainsAny(value, "*$") || (strings.Contains(value, "//") && !strings.Contains(value, "://")) {
+		return false
+	}
+	return true
+}
+
+// unescapeSlashes undoes JSON/JS escaping of "/" so escaped URLs match
+func unescapeSlashes(body string) string {
+	if !strings.Contains(body, `\/`) && !strings.Contains(body, `\u002f`) && !strings.Contains(body, `\u002F`) {
+		return body
+	}
+	return strings.NewReplacer(`\/`, "/", `\u002f`, "/", `\u002F`, "/").Replace(body)
+}
@@ -0,0 +1,233 @@
+// Package graphql recognises GraphQL operations in captured requests and
+// summarises introspection results. It reads the transport envelope
+// (operationName, query, variables) and the operation header; it does not
+// parse the query document itself.
+package graphql
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+)
+
+// Operation is one GraphQL operation sent in a request
+type Operation struct {
+	Name      string                 `json:"name,omitempty"` // "" for anonymous operations
+	Type      string                 `json:"type"`           // query, mutation, subscription
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// envelope is the JSON body of a GraphQL-over-HTTP request
+type envelope struct {
+	OperationName string                 `json:"operationName"`
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// operationHeader matches "query Name(", "mutation Name {" and the
+// anonymous "{ ... }" shorthand at the start of a document
+var operationHeader = regexp.MustCompile(`^\s*(?:#[^\n]*\n\s*)*(?:(query|mutation|subscription)\b\s*([A-Za-z_][A-Za-z0-9_]*)?|\{)`)
+
+// Parse returns the GraphQL operations in a request: a JSON envelope, a
+// batch (JSON array of envelopes), an application/graphql body, or GET query
+// parameters. It returns nil for anything else.
+func Parse(method, rawURL, contentType, body string) []Operation {
+	if strings.EqualFold(method, "GET") {
+		if op, ok := fromQueryParams(rawURL); ok {
+			return []Operation{op}
+		}
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(body)
+	if strings.Contains(strings.ToLower(contentType), "application/graphql") {
+		if op, ok := newOperation(envelope{Query: trimmed}); ok {
+			return []Operation{op}
+		}
+		return nil
+	}
+
+	var envelopes []envelope
+	switch {
+	case strings.HasPrefix(trimmed, "["):
+		if err := sonic.UnmarshalString(trimmed, &envelopes); err != nil {
+			return nil
+		}
+	case strings.HasPrefix(trimmed, "{"):
+		var e envelope
+		if err := sonic.UnmarshalString(trimmed, &e); err != nil {
+			return nil
+		}
+		envelopes = []envelope{e}
+	default:
+		return nil
+	}
+
+	var ops []Operation
+	for _, e := range envelopes {
+		if op, ok := newOperation(e); ok {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+func fromQueryParams(rawURL string) (Operation, bool) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return Operation{}, false
+	}
+	q := parsed.Query()
+	e := envelope{OperationName: q.Get("operationName"), Query: q.Get("query")}
+	if vars := q.Get("variables"); vars != "" {
+		_ = sonic.UnmarshalString(vars, &e.Variables)
+	}
+	return newOperation(e)
+}
+
+func newOperation(e envelope) (Operation, bool) {
+	m := operationHeader.FindStringSubmatch(e.Query)
+	if m == nil {
+		return Operation{}, false
+	}
+	op := Operation{Name: e.OperationName, Type: m[1], Query: e.Query, Variables: e.Variables}
+	if op.Type == "" {
+		op.Type = "query"
+	}
+	if op.Name == "" {
+		op.Name = m[2]
+	}
+	return op, true
+}
+
+// Body encodes an operation as a JSON request envelope
+func Body(op Operation) string {
+	e := map[string]interface{}{"query": op.Query}
+	if op.Name != "" {
+		e["operationName"] = op.Name
+	}
+	if len(op.Variables) > 0 {
+		e["variables"] = op.Variables
+	}
+	out, _ := sonic.MarshalString(e)
+	return out
+}
+
+// QueryParams encodes an operation as GET query parameters
+func QueryParams(op Operation) url.Values {
+	v := url.Values{}
+	v.Set("query", op.Query)
+	if op.Name != "" {
+		v.Set("operationName", op.Name)
+	}
+	if len(op.Variables) > 0 {
+		vars, _ := sonic.MarshalString(op.Variables)
+		v.Set("variables", vars)
+	}
+	return v
+}
+
+// IntrospectionQuery is the standard introspection query (as sent by
+// GraphiQL), trimmed of descriptions to keep responses small
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives { name locations args { ...InputValue } }
+  }
+}
+fragment FullType on __Type {
+  kind name
+  fields(includeDeprecated: true) { name args { ...InputValue } type { ...TypeRef } isDeprecated }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) { name isDeprecated }
+  possibleTypes { ...TypeRef }
+}
+fragment InputValue on __InputValue { name type { ...TypeRef } defaultValue }
+fragment TypeRef on __Type {
+  kind name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } }
+}`
+
+// Schema summarises an introspection result
+type Schema struct {
+	Types         int      `json:"types"` // User-defined types (no __ introspection types)
+	Queries       []string `json:"queries"`
+	Mutations     []string `json:"mutations"`
+	Subscriptions []string `json:"subscriptions"`
+}
+
+type introspection struct {
+	Data struct {
+		Schema *struct {
+			QueryType        *typeName `json:"queryType"`
+			MutationType     *typeName `json:"mutationType"`
+			SubscriptionType *typeName `json:"subscriptionType"`
+			Types            []struct {
+				Name   string `json:"name"`
+				Fields []struct {
+					Name string `json:"name"`
+				} `json:"fields"`
+			} `json:"types"`
+		} `json:"__schema"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type typeName struct {
+	Name string `json:"name"`
+}
+
+// ParseIntrospection summarises an introspection response. It returns nil
+// and the server's error messages when the schema is missing (introspection
+// disabled, auth required).
+func ParseIntrospection(body string) (*Schema, []string) {
+	var result introspection
+	if err := sonic.UnmarshalString(body, &result); err != nil {
+		return nil, []string{"response is not JSON"}
+	}
+	var errs []string
+	for _, e := range result.Errors {
+		errs = append(errs, e.Message)
+	}
+	s := result.Data.Schema
+	if s == nil {
+		if len(errs) == 0 {
+			errs = []string{"no __schema in response"}
+		}
+		return nil, errs
+	}
+
+	fields := make(map[string][]string)
+	schema := &Schema{Queries: []string{}, Mutations: []string{}, Subscriptions: []string{}}
+	for _, t := range s.Types {
+		if strings.HasPrefix(t.Name, "__") {
+			continue
+		}
+		schema.Types++
+		for _, f := range t.Fields {
+			fields[t.Name] = append(fields[t.Name], f.Name)
+		}
+	}
+	root := func(t *typeName) []string {
+		if t == nil {
+			return []string{}
+		}
+		names := append([]string{}, fields[t.Name]...)
+		sort.Strings(names)
+		return names
+	}
+	schema.Queries = root(s.QueryType)
+	schema.Mutations = root(s.MutationType)
+	schema.Subscriptions = root(s.SubscriptionType)
+	return schema, errs
+}
@@ -0,0 +1,310 @@
+// Package har reads and writes HTTP Archive 1.2 files, the format browsers,
+// OWASP ZAP and Burp exchange captures in.
+package har
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bytedance/sonic"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// HAR is the top-level document
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log holds the entries
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator names the tool that wrote the file
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is one request and its response. Fields starting with _ are
+// extensions: Chrome writes _resourceType, ZAP _zapMessageId, and rep _id
+// so a request keeps its ID through an export and import.
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"`
+	Time            float64  `json:"time"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Cache           struct{} `json:"cache"`
+	Timings         Timings  `json:"timings"`
+	ServerIPAddress string   `json:"serverIPAddress,omitempty"`
+	ID              string   `json:"_id,omitempty"`
+	ResourceType    string   `json:"_resourceType,omitempty"`
+	ZAPMessageID    int      `json:"_zapMessageId,omitempty"`
+}
+
+// Request is the request of an entry
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// Response is the response of an entry
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// NameValue is a header, cookie or query parameter
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PostData is a request body
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// Content is a response body; Encoding "base64" marks binary Text
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Timings splits Time; rep only knows the total, reported as wait
+type Timings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// Options shape a written HAR
+type Options struct {
+	Creator Creator
+	// ZAP writes what ZAP's HAR import understands: HTTP/1.1 versions
+	// (it can't parse h2) and no binary bodies (it reads text only)
+	ZAP bool
+}
+
+// Detect reports whether data looks like a HAR file
+func Detect(data []byte) bool {
+	head := string(data)
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	head = strings.TrimSpace(head)
+	return strings.HasPrefix(head, "{") && strings.Contains(head, `"log"`) && (strings.Contains(head, `"entries"`) || strings.Contains(head, `"creator"`))
+}
+
+// Parse reads a HAR file into requests. Entries without an _id get
+// zap_<message id> IDs when ZAP wrote them, har_<n> otherwise.
+func Parse(data []byte) ([]store.Request, error) {
+	var h HAR
+	if err := sonic.Unmarshal(data, &h); err != nil {
+		return nil, err
+	}
+	requests := make([]store.Request, 0, len(h.Log.Entries))
+	for i, e := range h.Log.Entries {
+		req := store.Request{
+			ID:           e.ID,
+			Method:       strings.ToUpper(e.Request.Method),
+			URL:          e.Request.URL,
+			Headers:      headerMap(e.Request.Headers),
+			ResourceType: e.ResourceType,
+			DurationMs:   e.Time,
+			RemoteIP:     strings.Trim(e.ServerIPAddress, "[]"),
+			Protocol:     protocolName(e.Request.HTTPVersion),
+		}
+		switch {
+		case req.ID != "":
+		case e.ZAPMessageID > 0:
+			req.ID = fmt.Sprintf("zap_%04d", e.ZAPMessageID)
+		default:
+			req.ID = fmt.Sprintf("har_%04d", i+1)
+		}
+		if t, err := time.Parse(time.RFC3339Nano, e.StartedDateTime); err == nil {
+			req.Timestamp = t.UnixMilli()
+		}
+		if e.Request.PostData != nil {
+			req.Body = e.Request.PostData.Text
+		}
+		// A response without a status was never received
+		if e.Response.Status > 0 {
+			req.Response = &store.Response{Status: e.Response.Status, Headers: headerMap(e.Response.Headers)}
+			body := e.Response.Content.Text
+			if strings.EqualFold(e.Response.Content.Encoding, "base64") {
+				if data, err := base64.StdEncoding.DecodeString(body); err == nil && utf8.Valid(data) {
+					body = string(data)
+				} else {
+					req.ResponseEncoding = "base64"
+				}
+			}
+			req.Response.Body = body
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+// Build writes requests as a HAR document
+func Build(requests []store.Request, opts Options) HAR {
+	h := HAR{Log: Log{Version: "1.2", Creator: opts.Creator, Entries: make([]Entry, 0, len(requests))}}
+	for i := range requests {
+		req := &requests[i]
+		version := httpVersion(req.Protocol, opts.ZAP)
+		e := Entry{
+			StartedDateTime: time.UnixMilli(req.Timestamp).UTC().Format("2006-01-02T15:04:05.000Z"),
+			Time:            req.DurationMs,
+			Timings:         Timings{Wait: req.DurationMs},
+			ServerIPAddress: req.RemoteIP,
+			ID:              req.ID,
+			ResourceType:    req.ResourceType,
+			Request: Request{
+				Method:      req.Method,
+				URL:         req.URL,
+				HTTPVersion: version,
+				Cookies:     []NameValue{},
+				Headers:     nameValues(req.Headers),
+				QueryString: queryString(req.URL),
+				HeadersSize: -1,
+				BodySize:    len(req.Body),
+			},
+			Response: Response{
+				HTTPVersion: version,
+				Cookies:     []NameValue{},
+				Headers:     []NameValue{},
+				HeadersSize: -1,
+				BodySize:    -1,
+			},
+		}
+		if req.Body != "" {
+			e.Request.PostData = &PostData{MimeType: store.HeaderFirst(req.Headers, "content-type"), Text: req.Body}
+		}
+		if resp := req.Response; resp != nil {
+			e.Response.Status = resp.Status
+			e.Response.StatusText = http.StatusText(resp.Status)
+			e.Response.Headers = nameValues(resp.Headers)
+			e.Response.RedirectURL = store.HeaderFirst(resp.Headers, "location")
+			e.Response.Content.MimeType = store.HeaderFirst(resp.Headers, "content-type")
+			body := store.ResponseBodyText(req)
+			e.Response.Content.Size = len(body)
+			e.Response.BodySize = len(body)
+			switch {
+			case utf8.ValidString(body):
+				e.Response.Content.Text = body
+			case !opts.ZAP:
+				e.Response.Content.Text = base64.StdEncoding.EncodeToString([]byte(body))
+				e.Response.Content.Encoding = "base64"
+			}
+		}
+		h.Log.Entries = append(h.Log.Entries, e)
+	}
+	return h
+}
+
+// headerMap reads HAR headers, dropping HTTP/2 pseudo-headers
+func headerMap(list []NameValue) store.HeaderMap {
+	headers := store.HeaderMap{}
+	for _, h := range list {
+		if h.Name == "" || strings.HasPrefix(h.Name, ":") {
+			continue
+		}
+		headers[h.Name] = append(headers[h.Name], h.Value)
+	}
+	return headers
+}
+
+// nameValues lists headers sorted by name
+func nameValues(headers store.HeaderMap) []NameValue {
+	list := []NameValue{}
+	for _, name := range sortedKeys(headers) {
+		if strings.HasPrefix(name, ":") {
+			continue
+		}
+		for _, v := range headers[name] {
+			list = append(list, NameValue{Name: name, Value: v})
+		}
+	}
+	return list
+}
+
+func sortedKeys(headers store.HeaderMap) []string {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func queryString(raw string) []NameValue {
+	list := []NameValue{}
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return list
+	}
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		name, value, _ := strings.Cut(pair, "=")
+		if n, err := url.QueryUnescape(name); err == nil {
+			name = n
+		}
+		if v, err := url.QueryUnescape(value); err == nil {
+			value = v
+		}
+		list = append(list, NameValue{Name: name, Value: value})
+	}
+	return list
+}
+
+// httpVersion is the HAR version string for a protocol (h2 -> HTTP/2.0)
+func httpVersion(protocol string, zap bool) string {
+	p := strings.ToLower(protocol)
+	switch {
+	case zap || p == "":
+		return "HTTP/1.1"
+	case p == "h2":
+		return "HTTP/2.0"
+	case p == "h3":
+		return "HTTP/3"
+	}
+	return strings.ToUpper(p)
+}
+
+// protocolName is the inverse of httpVersion
+func protocolName(version string) string {
+	v := strings.ToUpper(strings.TrimSpace(version))
+	switch {
+	case v == "", v == "UNKNOWN":
+		return ""
+	case strings.HasPrefix(v, "HTTP/2"), v == "H2":
+		return "h2"
+	case strings.HasPrefix(v, "HTTP/3"), v == "H3":
+		return "h3"
+	}
+	return strings.ToLower(v)
+}
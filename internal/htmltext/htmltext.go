@@ -0,0 +1,171 @@
+// Package htmltext turns captured HTML bodies into readable text and pulls
+// out the URLs they reference, without a full HTML parser. It is meant for
+// triage of real-world (often malformed) pages, not for exact rendering.
+package htmltext
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	// Elements whose content is never visible text (one pattern each, since
+	// RE2 has no backreferences to match the closing tag)
+	hiddenPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?is)<script\b[^>]*>.*?</script\s*>`),
+		regexp.MustCompile(`(?is)<style\b[^>]*>.*?</style\s*>`),
+		regexp.MustCompile(`(?is)<noscript\b[^>]*>.*?</noscript\s*>`),
+		regexp.MustCompile(`(?is)<template\b[^>]*>.*?</template\s*>`),
+		regexp.MustCompile(`(?is)<svg\b[^>]*>.*?</svg\s*>`),
+	}
+	commentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// Tags that start a new line in rendered text
+	blockPattern = regexp.MustCompile(`(?i)</?(p|div|br|li|ul|ol|h[1-6]|tr|table|section|article|header|footer|nav|main|aside|form|blockquote|pre|dt|dd|title|option|hr)\b[^>]*>`)
+	cellPattern  = regexp.MustCompile(`(?i)</?(td|th)\b[^>]*>`)
+	tagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+	spacePattern = regexp.MustCompile(`[ \t\f\v\r\x{00a0}]+`)
+
+	openTagPattern = regexp.MustCompile(`(?is)<([a-z][a-z0-9-]*)\b((?:[^>"']|"[^"]*"|'[^']*')*)>`)
+	attrPattern    = regexp.MustCompile(`(?is)([a-z_:][-a-z0-9_:.]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// Text returns the visible text of an HTML document: scripts, styles and
+// comments are dropped, block elements become line breaks, entities are
+// decoded and blank runs collapsed.
+func Text(doc string) string {
+	doc = commentPattern.ReplaceAllString(doc, "")
+	for _, hidden := range hiddenPatterns {
+		doc = hidden.ReplaceAllString(doc, "")
+	}
+	doc = blockPattern.ReplaceAllString(doc, "\n")
+	doc = cellPattern.ReplaceAllString(doc, " ")
+	doc = tagPattern.ReplaceAllString(doc, "")
+	doc = html.UnescapeString(doc)
+
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(spacePattern.ReplaceAllString(line, " "))
+		if line == "" {
+			// Keep at most one empty line between paragraphs
+			if !blank && len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// Link is a URL referenced by an HTML element
+type Link struct {
+	URL    string `json:"url"`              // Resolved against the page (and <base href>)
+	Raw    string `json:"raw,omitempty"`    // As written, when it differs from URL
+	Tag    string `json:"tag"`              // a, script, img, form, ...
+	Attr   string `json:"attr"`             // href, src, action, formaction, ...
+	Method string `json:"method,omitempty"` // Form method (forms only)
+}
+
+// linkAttrs are the attributes holding URLs, in output order
+var linkAttrs = []string{"href", "src", "action", "formaction", "srcset", "poster", "data-src", "data-href", "data-url"}
+
+// Links returns the href/src/action URLs of an HTML document in document
+// order, resolved against pageURL. Fragments, javascript:, data: and
+// duplicate URLs are skipped.
+func Links(doc, pageURL string) []Link {
+	doc = commentPattern.ReplaceAllString(doc, "")
+	base, _ := url.Parse(pageURL)
+
+	var links []Link
+	seen := make(map[string]bool)
+	for _, tag := range openTagPattern.FindAllStringSubmatch(doc, -1) {
+		name := strings.ToLower(tag[1])
+		attrs := parseAttrs(tag[2])
+
+		if name == "base" {
+			if href, ok := attrs["href"]; ok && base != nil {
+				if ref, err := base.Parse(href); err == nil {
+					base = ref
+				}
+			}
+			continue
+		}
+
+		for _, attr := range linkAttrs {
+			value, ok := attrs[attr]
+			if !ok {
+				continue
+			}
+			candidates := []string{value}
+			if attr == "srcset" {
+				candidates = srcsetURLs(value)
+			}
+			for _, raw := range candidates {
+				raw = strings.TrimSpace(raw)
+				if skipLink(raw) {
+					continue
+				}
+				resolved := raw
+				if base != nil {
+					if ref, err := base.Parse(raw); err == nil {
+						resolved = ref.String()
+					}
+				}
+				key := name + " " + attr + " " + resolved
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				link := Link{URL: resolved, Tag: name, Attr: attr}
+				if resolved != raw {
+					link.Raw = raw
+				}
+				if name == "form" && attr == "action" {
+					link.Method = strings.ToUpper(attrs["method"])
+					if link.Method == "" {
+						link.Method = "GET"
+					}
+				}
+				links = append(links, link)
+			}
+		}
+	}
+	return links
+}
+
+// parseAttrs returns lower-cased attribute names with decoded values
+func parseAttrs(s string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrPattern.FindAllStringSubmatch(s, -1) {
+		name := strings.ToLower(m[1])
+		if _, ok := attrs[name]; ok {
+			continue // First occurrence wins, as in browsers
+		}
+		attrs[name] = html.UnescapeString(m[2] + m[3] + m[4])
+	}
+	return attrs
+}
+
+// srcsetURLs splits "a.png 1x, b.png 2x" into its URLs
+func srcsetURLs(s string) []string {
+	var urls []string
+	for _, part := range strings.Split(s, ",") {
+		if fields := strings.Fields(part); len(fields) > 0 {
+			urls = append(urls, fields[0])
+		}
+	}
+	return urls
+}
+
+func skipLink(raw string) bool {
+	if raw == "" || strings.HasPrefix(raw, "#") {
+		return true
+	}
+	lower := strings.ToLower(raw)
+	return strings.HasPrefix(lower, "javascript:") || strings.HasPrefix(lower, "data:") || strings.HasPrefix(lower, "about:")
+}
@@ -0,0 +1,422 @@
+package importer
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// curlArgOptions are the curl options that take a value. The ones rep
+// doesn't use (output, proxies, timeouts) are skipped with their value.
+var curlArgOptions = map[string]bool{
+	"-X": true, "--request": true, "-H": true, "--header": true,
+	"-d": true, "--data": true, "--data-raw": true, "--data-ascii": true, "--data-binary": true, "--data-urlencode": true, "--json": true,
+	"-F": true, "--form": true, "--form-string": true,
+	"-b": true, "--cookie": true, "-u": true, "--user": true, "-A": true, "--user-agent": true, "-e": true, "--referer": true,
+	"--url": true, "-o": true, "--output": true, "-x": true, "--proxy": true, "-m": true, "--max-time": true,
+	"--connect-timeout": true, "-w": true, "--write-out": true, "-c": true, "--cookie-jar": true, "--resolve": true,
+	"--connect-to": true, "--retry": true, "--cacert": true, "-E": true, "--cert": true, "--key": true, "-T": true,
+	"--upload-file": true, "-r": true, "--range": true, "--oauth2-bearer": true, "-U": true, "--proxy-user": true,
+	"--max-redirs": true, "--limit-rate": true, "-K": true, "--config": true, "--interface": true, "--aws-sigv4": true,
+}
+
+// curlShortArgs are the single-letter options that take a value, which may
+// be attached ("-XPOST") or end a bundle ("-sX POST")
+const curlShortArgs = "XHdFbuAeoxmwcETrUK"
+
+func detectCurl(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return isCurlProgram(strings.Fields(line)[0])
+	}
+	return false
+}
+
+// ParseCurl reads curl commands in POSIX shell syntax, one per line (with
+// backslash continuations) or separated by ; and &&, as written by
+// "Copy as cURL" in browsers and pasted in docs. Lines that aren't curl
+// commands are skipped. Requests have no response until replayed.
+func ParseCurl(data []byte) ([]store.Request, error) {
+	commands, err := splitShellCommands(string(data))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UnixMilli()
+	var requests []store.Request
+	for _, args := range commands {
+		if len(args) == 0 || !isCurlProgram(args[0]) {
+			continue
+		}
+		req, err := parseCurlArgs(args[1:])
+		if err != nil {
+			return nil, fmt.Errorf("command %d: %w", len(requests)+1, err)
+		}
+		// IDs come from the command, so re-importing it doesn't collide
+		// with other commands' IDs
+		sum := sha1.Sum([]byte(strings.Join(args, "\x00")))
+		req.ID = "curl_" + hex.EncodeToString(sum[:6])
+		req.Timestamp = now + int64(len(requests))
+		requests = append(requests, req)
+	}
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("no curl command found")
+	}
+	return requests, nil
+}
+
+func isCurlProgram(arg string) bool {
+	name := strings.ToLower(arg[strings.LastIndexAny(arg, `/\`)+1:])
+	return name == "curl" || name == "curl.exe"
+}
+
+func parseCurlArgs(args []string) (store.Request, error) {
+	req := store.Request{Headers: store.HeaderMap{}}
+	var method, target string
+	var data []string
+	var form [][2]string
+	get, head := false, false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := arg, "", false
+		switch {
+		case arg == "--":
+			if i+1 < len(args) && target == "" {
+				target = args[i+1]
+			}
+			i = len(args)
+			continue
+		case strings.HasPrefix(arg, "--"):
+			if n, v, ok := strings.Cut(arg, "="); ok && curlArgOptions[n] {
+				name, value, hasValue = n, v, true
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 2:
+			// A bundle of flags, maybe ending in one that takes a value
+			for k := 1; k < len(arg); k++ {
+				if strings.IndexByte(curlShortArgs, arg[k]) >= 0 {
+					name = "-" + string(arg[k])
+					if k+1 < len(arg) {
+						value, hasValue = arg[k+1:], true
+					}
+					break
+				}
+				name = "-" + string(arg[k])
+				if arg[k] == 'G' {
+					get = true
+				} else if arg[k] == 'I' {
+					head = true
+				}
+			}
+		case !strings.HasPrefix(arg, "-") || arg == "-":
+			if target == "" {
+				target = arg
+			}
+			continue
+		}
+		if !curlArgOptions[name] {
+			switch name {
+			case "-G", "--get":
+				get = true
+			case "-I", "--head":
+				head = true
+			case "--http2", "--http2-prior-knowledge":
+				req.Protocol = "h2"
+			case "--http3", "--http3-only":
+				req.Protocol = "h3"
+			}
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return req, fmt.Errorf("%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch name {
+		case "-X", "--request":
+			method = strings.ToUpper(value)
+		case "--url":
+			target = value
+		case "-H", "--header":
+			if n, v, ok := strings.Cut(value, ":"); ok {
+				req.Headers[strings.TrimSpace(n)] = append(req.Headers[strings.TrimSpace(n)], strings.TrimSpace(v))
+			} else if n, ok := strings.CutSuffix(value, ";"); ok {
+				// "Name;" sends the header empty
+				req.Headers[strings.TrimSpace(n)] = append(req.Headers[strings.TrimSpace(n)], "")
+			}
+		case "-d", "--data", "--data-ascii":
+			// curl strips newlines from these, unlike --data-binary
+			data = append(data, strings.NewReplacer("\r", "", "\n", "").Replace(value))
+		case "--data-raw", "--data-binary":
+			data = append(data, value)
+		case "--data-urlencode":
+			data = append(data, curlURLEncode(value))
+		case "--json":
+			data = append(data, value)
+			setDefaultHeader(req.Headers, "Content-Type", "application/json")
+			setDefaultHeader(req.Headers, "Accept", "application/json")
+		case "-F", "--form", "--form-string":
+			n, v, _ := strings.Cut(value, "=")
+			form = append(form, [2]string{n, v})
+		case "-b", "--cookie":
+			// Without "=" the value is a cookie file
+			if strings.Contains(value, "=") {
+				req.Headers["Cookie"] = append(req.Headers["Cookie"], value)
+			}
+		case "-u", "--user":
+			setDefaultHeader(req.Headers, "Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(value)))
+		case "--oauth2-bearer":
+			setDefaultHeader(req.Headers, "Authorization", "Bearer "+value)
+		case "-A", "--user-agent":
+			setDefaultHeader(req.Headers, "User-Agent", value)
+		case "-e", "--referer":
+			setDefaultHeader(req.Headers, "Referer", strings.TrimSuffix(value, ";auto"))
+		}
+	}
+
+	if target == "" {
+		return req, fmt.Errorf("no URL")
+	}
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return req, fmt.Errorf("bad URL %q", target)
+	}
+
+	body := strings.Join(data, "&")
+	switch {
+	case get && body != "":
+		sep := "?"
+		if u.RawQuery != "" {
+			sep = "&"
+		}
+		target, body = target+sep+body, ""
+	case len(form) > 0:
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		for _, f := range form {
+			// Files aren't read: @file becomes an empty part with its name
+			if file, ok := strings.CutPrefix(f[1], "@"); ok {
+				file, _, _ = strings.Cut(file, ";")
+				h := textproto.MIMEHeader{}
+				h.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, f[0], file[strings.LastIndexAny(file, `/\`)+1:]))
+				h.Set("Content-Type", "application/octet-stream")
+				_, _ = w.CreatePart(h)
+				continue
+			}
+			_ = w.WriteField(f[0], f[1])
+		}
+		_ = w.Close()
+		body = buf.String()
+		store.SetHeader(req.Headers, "Content-Type", w.FormDataContentType())
+	case body != "":
+		setDefaultHeader(req.Headers, "Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	switch {
+	case method != "":
+	case head:
+		method = "HEAD"
+	case body != "":
+		method = "POST"
+	default:
+		method = "GET"
+	}
+	req.Method, req.URL, req.Body = method, target, body
+	return req, nil
+}
+
+// curlURLEncode applies --data-urlencode: "name=value" encodes the value,
+// a bare value is encoded whole
+func curlURLEncode(value string) string {
+	if name, v, ok := strings.Cut(value, "="); ok {
+		if name == "" {
+			return url.QueryEscape(v)
+		}
+		return name + "=" + url.QueryEscape(v)
+	}
+	return url.QueryEscape(value)
+}
+
+// setDefaultHeader sets a header unless the command already set it
+func setDefaultHeader(headers store.HeaderMap, name, value string) {
+	if store.HeaderFirst(headers, name) == "" {
+		store.SetHeader(headers, name, value)
+	}
+}
+
+// splitShellCommands splits POSIX shell text into commands and their
+// words. It handles quotes, $'...' escapes, backslash continuations and
+// # comments; unquoted newlines, ;, && and || end a command.
+func splitShellCommands(s string) ([][]string, error) {
+	var commands [][]string
+	var words []string
+	var word strings.Builder
+	inWord := false
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			commands = append(commands, words)
+			words = nil
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\':
+			if i+1 < len(s) && s[i+1] == '\r' {
+				i++
+			}
+			if i+1 < len(s) && s[i+1] == '\n' {
+				i++
+				continue
+			}
+			if i+1 < len(s) {
+				i++
+				word.WriteByte(s[i])
+				inWord = true
+			}
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated ' quote")
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			inWord = true
+			i += end + 1
+		case c == '$' && i+1 < len(s) && s[i+1] == '\'':
+			n, err := ansiCString(s[i+2:], &word)
+			if err != nil {
+				return nil, err
+			}
+			inWord = true
+			i += n + 2
+		case c == '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\\\"$`\n", s[i+1]) >= 0 {
+					i++
+					if s[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("unterminated \" quote")
+			}
+			inWord = true
+		case c == '#' && !inWord:
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+			endCommand()
+		case c == '\n' || c == ';':
+			endCommand()
+		case (c == '&' || c == '|') && i+1 < len(s) && s[i+1] == c:
+			endCommand()
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			endWord()
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	endCommand()
+	return commands, nil
+}
+
+// ansiCString decodes the body of a $'...' string into w and returns the
+// bytes consumed, including the closing quote
+func ansiCString(s string, w *strings.Builder) (int, error) {
+	escapes := map[byte]byte{'n': '\n', 't': '\t', 'r': '\r', '\\': '\\', '\'': '\'', '"': '"', 'a': '\a', 'b': '\b', 'e': 0x1b, 'f': '\f', 'v': '\v', '?': '?'}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\'' {
+			return i + 1, nil
+		}
+		if c != '\\' || i+1 >= len(s) {
+			w.WriteByte(c)
+			continue
+		}
+		i++
+		if e, ok := escapes[s[i]]; ok {
+			w.WriteByte(e)
+			continue
+		}
+		// \xHH, \uHHHH, \UHHHHHHHH and \NNN (octal)
+		base, max := 16, 0
+		switch s[i] {
+		case 'x':
+			max = 2
+		case 'u':
+			max = 4
+		case 'U':
+			max = 8
+		default:
+			if s[i] >= '0' && s[i] <= '7' {
+				base, max = 8, 3
+				i--
+			}
+		}
+		if max == 0 {
+			w.WriteByte('\\')
+			w.WriteByte(s[i])
+			continue
+		}
+		n, digits := 0, 0
+		for digits < max && i+1 < len(s) && isDigitIn(s[i+1], base) {
+			i++
+			n = n*base + hexValue(s[i])
+			digits++
+		}
+		if s[i-digits] == 'u' || s[i-digits] == 'U' {
+			w.WriteRune(rune(n))
+		} else {
+			w.WriteByte(byte(n))
+		}
+	}
+	return 0, fmt.Errorf("unterminated $' quote")
+}
+
+func isDigitIn(c byte, base int) bool {
+	if base == 8 {
+		return c >= '0' && c <= '7'
+	}
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func hexValue(c byte) int {
+	switch {
+	case c >= 'a':
+		return int(c-'a') + 10
+	case c >= 'A':
+		return int(c-'A') + 10
+	}
+	return int(c - '0')
+}
@@ -0,0 +1,141 @@
+// Package importer converts traffic captured by other tools into
+// store.Requests, so 'rep import' can save it as a session next to the
+// extension's captures.
+package importer
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/repplus/rep-cli/internal/har"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// Format is an importable file format
+type Format struct {
+	Name        string
+	Description string
+	Detect      func(data []byte) bool
+	Parse       func(data []byte) ([]store.Request, error)
+}
+
+// Formats are the formats 'rep import' reads besides its own JSON exports,
+// in detection order
+var Formats = []Format{
+	{Name: "mitmproxy", Description: "mitmproxy flow file (mitmdump -w)", Detect: detectMitmproxy, Parse: ParseMitmproxy},
+	{Name: "har", Description: "HTTP Archive (ZAP, Burp, browser devtools)", Detect: har.Detect, Parse: har.Parse},
+	{Name: "zap", Description: "ZAP message export (Export Messages to File)", Detect: detectZAP, Parse: ParseZAP},
+	{Name: "curl", Description: "curl commands, one per line", Detect: detectCurl, Parse: ParseCurl},
+}
+
+// Lookup returns the format named name
+func Lookup(name string) (Format, bool) {
+	for _, f := range Formats {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// Detect returns the format data looks like
+func Detect(data []byte) (Format, bool) {
+	for _, f := range Formats {
+		if f.Detect(data) {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// Names lists the format names
+func Names() []string {
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// maxDecodedBody bounds a decompressed body
+const maxDecodedBody = 64 << 20
+
+// decodeContent undoes a Content-Encoding, as the extension captures bodies
+// decoded. Unknown encodings and corrupt data are returned as is.
+func decodeContent(body []byte, encoding string) []byte {
+	var r io.Reader
+	var err error
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	case "deflate":
+		// Servers send zlib-wrapped or raw deflate under the same name
+		if r, err = zlib.NewReader(bytes.NewReader(body)); err != nil {
+			r, err = flate.NewReader(bytes.NewReader(body)), nil
+		}
+	case "zstd":
+		var d *zstd.Decoder
+		if d, err = zstd.NewReader(bytes.NewReader(body)); err == nil {
+			defer d.Close()
+			r = d
+		}
+	default:
+		return body
+	}
+	if err != nil {
+		return body
+	}
+	decoded, err := io.ReadAll(io.LimitReader(r, maxDecodedBody))
+	if err != nil {
+		return body
+	}
+	return decoded
+}
+
+// setResponseBody stores a response body as text, or base64 when it isn't
+// UTF-8
+func setResponseBody(req *store.Request, body []byte) {
+	if utf8.Valid(body) {
+		req.Response.Body = string(body)
+		return
+	}
+	req.Response.Body = base64.StdEncoding.EncodeToString(body)
+	req.ResponseEncoding = "base64"
+}
+
+// protocolName turns an HTTP version ("HTTP/2.0") into the extension's
+// protocol names (h2)
+func protocolName(version string) string {
+	switch v := strings.ToUpper(strings.TrimSpace(version)); {
+	case v == "":
+		return ""
+	case strings.HasPrefix(v, "HTTP/2"):
+		return "h2"
+	case strings.HasPrefix(v, "HTTP/3"):
+		return "h3"
+	default:
+		return strings.ToLower(v)
+	}
+}
+
+// requestURL builds an absolute URL, leaving out the default port
+func requestURL(scheme, host string, port int, path string) string {
+	if scheme == "" {
+		scheme = "http"
+	}
+	if port > 0 && !strings.Contains(host, ":") && !(scheme == "http" && port == 80) && !(scheme == "https" && port == 443) {
+		host = fmt.Sprintf("%s:%d", host, port)
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return scheme + "://" + host + path
+}
@@ -0,0 +1,288 @@
+package importer
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// maxTNetLength bounds a single tnetstring value
+const maxTNetLength = 1 << 30
+
+// detectMitmproxy recognizes a flow file: a stream of tnetstring dicts
+func detectMitmproxy(data []byte) bool {
+	colon := bytes.IndexByte(data, ':')
+	if colon <= 0 || colon > 12 {
+		return false
+	}
+	n, err := strconv.Atoi(string(data[:colon]))
+	end := colon + 1 + n
+	return err == nil && n >= 0 && end < len(data) && data[end] == '}'
+}
+
+// ParseMitmproxy reads a mitmproxy flow file (mitmdump -w, or File > Save
+// in mitmweb). HTTP flows become requests; WebSocket messages of a flow
+// become its response events, tagged with their sender. TCP, UDP and DNS
+// flows are skipped.
+func ParseMitmproxy(data []byte) ([]store.Request, error) {
+	var requests []store.Request
+	for n := 1; len(bytes.TrimSpace(data)) > 0; n++ {
+		value, rest, err := parseTNetString(data)
+		if err != nil {
+			return nil, fmt.Errorf("flow %d: %w", n, err)
+		}
+		data = rest
+		flow, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("flow %d: not a dict", n)
+		}
+		if t := tnetText(flow["type"]); t != "" && t != "http" {
+			continue
+		}
+		if req, ok := mitmproxyRequest(flow, n); ok {
+			requests = append(requests, req)
+		}
+	}
+	return requests, nil
+}
+
+func mitmproxyRequest(flow map[string]interface{}, n int) (store.Request, bool) {
+	r, ok := flow["request"].(map[string]interface{})
+	if !ok {
+		return store.Request{}, false
+	}
+	req := store.Request{
+		ID:      mitmproxyID(tnetText(flow["id"]), n),
+		Method:  strings.ToUpper(tnetText(r["method"])),
+		Headers: tnetHeaders(r["headers"]),
+	}
+	req.Body = string(decodeContent(tnetBytes(r["content"]), store.HeaderFirst(req.Headers, "content-encoding")))
+
+	host := tnetText(r["authority"])
+	if host == "" {
+		host = store.HeaderFirst(req.Headers, "host")
+	}
+	port := int(tnetInt(r["port"]))
+	if host == "" {
+		host = tnetText(r["host"])
+	} else {
+		port = 0
+	}
+	req.URL = requestURL(tnetText(r["scheme"]), host, port, tnetText(r["path"]))
+
+	start := tnetFloat(r["timestamp_start"])
+	req.Timestamp = int64(start * 1000)
+	req.Protocol = protocolName(tnetText(r["http_version"]))
+	if conn, ok := flow["server_conn"].(map[string]interface{}); ok {
+		for _, key := range []string{"peername", "ip_address", "address"} {
+			if addr, ok := conn[key].([]interface{}); ok && len(addr) > 0 {
+				req.RemoteIP = tnetText(addr[0])
+				break
+			}
+		}
+		req.TLS = mitmproxyTLS(conn)
+	}
+
+	if resp, ok := flow["response"].(map[string]interface{}); ok {
+		req.Response = &store.Response{Status: int(tnetInt(resp["status_code"])), Headers: tnetHeaders(resp["headers"])}
+		setResponseBody(&req, decodeContent(tnetBytes(resp["content"]), store.HeaderFirst(req.Response.Headers, "content-encoding")))
+		if end := tnetFloat(resp["timestamp_end"]); end > start && start > 0 {
+			req.DurationMs = (end - start) * 1000
+		}
+		if ws, ok := flow["websocket"].(map[string]interface{}); ok {
+			req.Response.Events = mitmproxyMessages(ws["messages"])
+		}
+	}
+	return req, true
+}
+
+// mitmproxyTLS reads the TLS session of a server connection and its leaf
+// certificate, stored as PEM; nil for plain HTTP
+func mitmproxyTLS(conn map[string]interface{}) *store.TLSInfo {
+	info := &store.TLSInfo{
+		Version: strings.Replace(tnetText(conn["tls_version"]), "TLSv", "TLS ", 1),
+		Cipher:  tnetText(conn["cipher"]),
+		ALPN:    tnetText(conn["alpn"]),
+	}
+	if certs, ok := conn["certificate_list"].([]interface{}); ok && len(certs) > 0 {
+		if block, _ := pem.Decode(tnetBytes(certs[0])); block != nil {
+			if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+				info.Subject = cert.Subject.CommonName
+				info.Issuer = cert.Issuer.CommonName
+				if info.Issuer == "" && len(cert.Issuer.Organization) > 0 {
+					info.Issuer = cert.Issuer.Organization[0]
+				}
+				info.SANs = append(info.SANs, cert.DNSNames...)
+				for _, ip := range cert.IPAddresses {
+					info.SANs = append(info.SANs, ip.String())
+				}
+				info.ValidFrom, info.ValidTo = cert.NotBefore.Unix(), cert.NotAfter.Unix()
+			}
+		}
+	}
+	if info.Version == "" && info.Subject == "" {
+		return nil
+	}
+	return info
+}
+
+// mitmproxyMessages converts WebSocket messages, stored as
+// [type, content, from_client, timestamp]
+func mitmproxyMessages(v interface{}) []store.StreamEvent {
+	list, _ := v.([]interface{})
+	var events []store.StreamEvent
+	for _, item := range list {
+		fields, ok := item.([]interface{})
+		if !ok || len(fields) < 4 {
+			continue
+		}
+		content := tnetBytes(fields[1])
+		e := store.StreamEvent{Timestamp: int64(tnetFloat(fields[3]) * 1000), From: "server"}
+		if fromClient, _ := fields[2].(bool); fromClient {
+			e.From = "client"
+		}
+		// Type 2 is a binary frame
+		if tnetInt(fields[0]) == 2 || !utf8.Valid(content) {
+			e.Data, e.Binary = base64.StdEncoding.EncodeToString(content), true
+		} else {
+			e.Data = string(content)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+// mitmproxyID derives a request ID from the flow's UUID
+func mitmproxyID(flowID string, n int) string {
+	hex := strings.ReplaceAll(flowID, "-", "")
+	if len(hex) >= 12 {
+		return "mitm_" + hex[:12]
+	}
+	return fmt.Sprintf("mitm_%04d", n)
+}
+
+// tnetHeaders reads headers stored as a list of [name, value] pairs
+func tnetHeaders(v interface{}) store.HeaderMap {
+	headers := store.HeaderMap{}
+	list, _ := v.([]interface{})
+	for _, item := range list {
+		pair, ok := item.([]interface{})
+		if !ok || len(pair) != 2 {
+			continue
+		}
+		name := tnetText(pair[0])
+		headers[name] = append(headers[name], tnetText(pair[1]))
+	}
+	return headers
+}
+
+func tnetText(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []byte:
+		return string(t)
+	}
+	return ""
+}
+
+func tnetBytes(v interface{}) []byte {
+	switch t := v.(type) {
+	case []byte:
+		return t
+	case string:
+		return []byte(t)
+	}
+	return nil
+}
+
+func tnetInt(v interface{}) int64 {
+	switch t := v.(type) {
+	case int64:
+		return t
+	case float64:
+		return int64(t)
+	}
+	return 0
+}
+
+func tnetFloat(v interface{}) float64 {
+	switch t := v.(type) {
+	case float64:
+		return t
+	case int64:
+		return float64(t)
+	}
+	return 0
+}
+
+// parseTNetString decodes one tnetstring, "<length>:<payload><tag>", and
+// returns the data after it. Byte strings decode to []byte, text to string,
+// dicts to map[string]interface{}.
+func parseTNetString(data []byte) (interface{}, []byte, error) {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	colon := bytes.IndexByte(data, ':')
+	if colon <= 0 || colon > 12 {
+		return nil, nil, fmt.Errorf("not a tnetstring")
+	}
+	n, err := strconv.Atoi(string(data[:colon]))
+	if err != nil || n < 0 || n > maxTNetLength {
+		return nil, nil, fmt.Errorf("bad tnetstring length %q", data[:colon])
+	}
+	end := colon + 1 + n
+	if end >= len(data) {
+		return nil, nil, fmt.Errorf("truncated tnetstring")
+	}
+	payload, tag, rest := data[colon+1:end], data[end], data[end+1:]
+
+	switch tag {
+	case ',':
+		return append([]byte(nil), payload...), rest, nil
+	case ';':
+		return string(payload), rest, nil
+	case '#':
+		i, err := strconv.ParseInt(string(payload), 10, 64)
+		return i, rest, err
+	case '^':
+		f, err := strconv.ParseFloat(string(payload), 64)
+		return f, rest, err
+	case '!':
+		return string(payload) == "true", rest, nil
+	case '~':
+		return nil, rest, nil
+	case ']':
+		list := []interface{}{}
+		for len(payload) > 0 {
+			item, more, err := parseTNetString(payload)
+			if err != nil {
+				return nil, nil, err
+			}
+			list = append(list, item)
+			payload = more
+		}
+		return list, rest, nil
+	case '}':
+		dict := map[string]interface{}{}
+		for len(payload) > 0 {
+			key, more, err := parseTNetString(payload)
+			if err != nil {
+				return nil, nil, err
+			}
+			value, more, err := parseTNetString(more)
+			if err != nil {
+				return nil, nil, err
+			}
+			dict[tnetText(key)] = value
+			payload = more
+		}
+		return dict, rest, nil
+	}
+	return nil, nil, fmt.Errorf("unknown tnetstring type %q", tag)
+}
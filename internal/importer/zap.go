@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// zapSeparator starts each message in ZAP's "Export Messages to File"
+// output, numbered with the message's history ID
+var zapSeparator = regexp.MustCompile(`(?m)^==== (\d+) ==========\r?\n`)
+
+// zapStatusLine starts the response of a message
+var zapStatusLine = regexp.MustCompile(`(?m)^HTTP/\d(?:\.\d)? \d{3}`)
+
+func detectZAP(data []byte) bool {
+	loc := zapSeparator.FindIndex(data)
+	return loc != nil && len(bytes.TrimSpace(data[:loc[0]])) == 0
+}
+
+// ParseZAP reads a ZAP message export (History > Export Messages to File):
+// raw requests and responses, one block per message, with absolute URLs
+// in the request lines
+func ParseZAP(data []byte) ([]store.Request, error) {
+	matches := zapSeparator.FindAllSubmatchIndex(data, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no ZAP messages found")
+	}
+	requests := make([]store.Request, 0, len(matches))
+	for i, m := range matches {
+		end := len(data)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		id := string(data[m[2]:m[3]])
+		req, err := parseZAPMessage(data[m[1]:end])
+		if err != nil {
+			return nil, fmt.Errorf("message %s: %w", id, err)
+		}
+		n, _ := strconv.Atoi(id)
+		req.ID = fmt.Sprintf("zap_%04d", n)
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+func parseZAPMessage(block []byte) (store.Request, error) {
+	var req store.Request
+	head, rest, ok := cutHead(block)
+	if !ok {
+		return req, fmt.Errorf("request headers not terminated")
+	}
+	line, headers := parseHead(head)
+	parts := strings.Fields(line)
+	if len(parts) < 2 {
+		return req, fmt.Errorf("bad request line %q", line)
+	}
+	req.Method, req.URL, req.Headers = strings.ToUpper(parts[0]), parts[1], headers
+	if len(parts) > 2 {
+		req.Protocol = protocolName(parts[2])
+	}
+	// Proxied requests carry absolute URLs; anything else needs the Host
+	if strings.HasPrefix(req.URL, "/") {
+		req.URL = requestURL("https", store.HeaderFirst(headers, "host"), 0, req.URL)
+	}
+
+	// The request body runs to Content-Length, or to the status line
+	body := rest
+	rest = nil
+	if n, err := strconv.Atoi(store.HeaderFirst(headers, "content-length")); err == nil && n >= 0 && n <= len(body) {
+		body, rest = body[:n], body[n:]
+	} else if loc := zapStatusLine.FindIndex(body); loc != nil {
+		body, rest = body[:loc[0]], body[loc[0]:]
+	}
+	req.Body = string(decodeContent(body, store.HeaderFirst(headers, "content-encoding")))
+
+	rest = bytes.TrimLeft(rest, "\r\n")
+	if !zapStatusLine.Match(rest) {
+		return req, nil
+	}
+	head, respBody, _ := cutHead(rest)
+	line, respHeaders := parseHead(head)
+	status, _ := strconv.Atoi(strings.Fields(line)[1])
+	req.Response = &store.Response{Status: status, Headers: respHeaders}
+	// ZAP ends each message with a newline of its own
+	respBody = bytes.TrimSuffix(bytes.TrimSuffix(respBody, []byte("\n")), []byte("\r"))
+	setResponseBody(&req, decodeContent(respBody, store.HeaderFirst(respHeaders, "content-encoding")))
+	return req, nil
+}
+
+// cutHead splits a raw message at the blank line ending its headers
+func cutHead(data []byte) (head, rest []byte, ok bool) {
+	if i := bytes.Index(data, []byte("\r\n\r\n")); i >= 0 {
+		if j := bytes.Index(data, []byte("\n\n")); j < 0 || i < j {
+			return data[:i], data[i+4:], true
+		}
+	}
+	if j := bytes.Index(data, []byte("\n\n")); j >= 0 {
+		return data[:j], data[j+2:], true
+	}
+	return data, nil, false
+}
+
+// parseHead splits a header block into its first line and headers
+func parseHead(head []byte) (string, store.HeaderMap) {
+	lines := strings.Split(strings.ReplaceAll(string(head), "\r\n", "\n"), "\n")
+	headers := store.HeaderMap{}
+	for _, l := range lines[1:] {
+		name, value, ok := strings.Cut(l, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		headers[name] = append(headers[name], strings.TrimSpace(value))
+	}
+	return strings.TrimSpace(lines[0]), headers
+}
@@ -0,0 +1,329 @@
+// Package jsbeautify re-indents minified JavaScript for reading. It is a
+// token-level formatter, not a parser: strings, template literals, regex
+// literals and comments are copied verbatim, and line breaks are added
+// around braces, after statements and between object members. The output
+// is meant for eyes and grep, not for execution.
+package jsbeautify
+
+import (
+	"strings"
+)
+
+// Indent is the indentation unit
+const Indent = "  "
+
+// keywords after which a '/' starts a regex literal
+var regexKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true,
+	"new": true, "delete": true, "void": true, "throw": true, "case": true, "do": true, "else": true,
+}
+
+// keywords that continue the statement closed by '}'
+var continuations = []string{"else", "catch", "finally", "while"}
+
+type formatter struct {
+	src    string
+	pos    int
+	out    strings.Builder
+	line   strings.Builder
+	depth  int
+	indent int    // Depth of the line being built
+	stack  []byte // Open brackets: '{', '(', '['
+	last   byte   // Last significant character emitted
+	lastID string // Last identifier emitted, for regex detection
+}
+
+// Beautify returns src with one statement per line and brace-based
+// indentation
+func Beautify(src string) string {
+	f := &formatter{src: src}
+	f.run()
+	f.newline()
+	return strings.TrimRight(f.out.String(), "\n") + "\n"
+}
+
+func (f *formatter) run() {
+	for f.pos < len(f.src) {
+		c := f.src[f.pos]
+		switch {
+		case c == '"' || c == '\'':
+			f.copyString(c)
+		case c == '`':
+			f.copyTemplate()
+		case c == '/' && f.peek(1) == '/':
+			f.copyLineComment()
+		case c == '/' && f.peek(1) == '*':
+			f.copyBlockComment()
+		case c == '/' && f.regexAllowed():
+			f.copyRegex()
+		case c == '\n' || c == '\r':
+			f.pos++
+			// Keep existing line breaks outside expressions (code that
+			// relies on ASI stays readable)
+			if f.parenDepth() == 0 {
+				f.newline()
+			} else {
+				f.space()
+			}
+		case c == ' ' || c == '\t':
+			f.pos++
+			f.space()
+		case c == '{':
+			f.pos++
+			f.emit("{")
+			f.stack = append(f.stack, '{')
+			f.depth++
+			f.newline()
+		case c == '}':
+			f.pos++
+			f.pop('{')
+			if f.depth > 0 {
+				f.depth--
+			}
+			f.newline()
+			f.emit("}")
+			f.afterCloseBrace()
+		case c == '(' || c == '[':
+			f.pos++
+			f.emit(string(c))
+			f.stack = append(f.stack, c)
+		case c == ')' || c == ']':
+			f.pos++
+			if c == ')' {
+				f.pop('(')
+			} else {
+				f.pop('[')
+			}
+			f.emit(string(c))
+		case c == ';':
+			f.pos++
+			f.emit(";")
+			if f.parenDepth() == 0 {
+				f.newline()
+			} else {
+				f.space()
+			}
+		case c == ',':
+			f.pos++
+			f.emit(",")
+			if len(f.stack) > 0 && f.stack[len(f.stack)-1] == '{' {
+				f.newline()
+			}
+		case isIdentChar(c):
+			start := f.pos
+			for f.pos < len(f.src) && isIdentChar(f.src[f.pos]) {
+				f.pos++
+			}
+			word := f.src[start:f.pos]
+			f.emit(word)
+			f.lastID = word
+		default:
+			f.pos++
+			f.emit(string(c))
+		}
+	}
+}
+
+// afterCloseBrace starts a new line unless the statement continues
+// ("} else {", "});", "},")
+func (f *formatter) afterCloseBrace() {
+	rest := strings.TrimLeft(f.src[f.pos:], " \t")
+	if rest == "" {
+		return
+	}
+	switch rest[0] {
+	case ';', ',', ')', ']', '.', '(', '?', ':':
+		return
+	}
+	for _, kw := range continuations {
+		if strings.HasPrefix(rest, kw) && (len(rest) == len(kw) || !isIdentChar(rest[len(kw)])) {
+			f.line.WriteByte(' ')
+			return
+		}
+	}
+	f.newline()
+}
+
+func (f *formatter) emit(s string) {
+	if s == "" {
+		return
+	}
+	f.write(s)
+	f.last = s[len(s)-1]
+	if !isIdentChar(f.last) {
+		f.lastID = ""
+	}
+}
+
+// write appends to the current line, fixing its indentation on the first
+// write
+func (f *formatter) write(s string) {
+	if f.line.Len() == 0 {
+		f.indent = f.depth
+	}
+	f.line.WriteString(s)
+}
+
+// space emits one separating space, only where one is needed to keep
+// tokens apart or was present in the source between words
+func (f *formatter) space() {
+	for f.pos < len(f.src) && (f.src[f.pos] == ' ' || f.src[f.pos] == '\t') {
+		f.pos++
+	}
+	if f.line.Len() == 0 {
+		return
+	}
+	s := f.line.String()
+	if s[len(s)-1] != ' ' {
+		f.line.WriteByte(' ')
+	}
+}
+
+func (f *formatter) newline() {
+	text := strings.TrimRight(f.line.String(), " ")
+	f.line.Reset()
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	f.out.WriteString(strings.Repeat(Indent, f.indent))
+	f.out.WriteString(strings.TrimLeft(text, " "))
+	f.out.WriteByte('\n')
+}
+
+func (f *formatter) pop(open byte) {
+	for i := len(f.stack) - 1; i >= 0; i-- {
+		if f.stack[i] == open {
+			f.stack = f.stack[:i]
+			return
+		}
+	}
+}
+
+// parenDepth counts the brackets opened inside the innermost block
+func (f *formatter) parenDepth() int {
+	n := 0
+	for i := len(f.stack) - 1; i >= 0 && f.stack[i] != '{'; i-- {
+		n++
+	}
+	return n
+}
+
+func (f *formatter) peek(offset int) byte {
+	if f.pos+offset < len(f.src) {
+		return f.src[f.pos+offset]
+	}
+	return 0
+}
+
+func (f *formatter) copyString(quote byte) {
+	start := f.pos
+	f.pos++
+	for f.pos < len(f.src) {
+		c := f.src[f.pos]
+		f.pos++
+		if c == '\\' {
+			f.pos++
+			continue
+		}
+		if c == quote || c == '\n' {
+			break
+		}
+	}
+	f.emitRaw(start)
+}
+
+func (f *formatter) copyTemplate() {
+	start := f.pos
+	f.pos++
+	depth := 0
+	for f.pos < len(f.src) {
+		c := f.src[f.pos]
+		f.pos++
+		switch {
+		case c == '\\':
+			f.pos++
+		case c == '$' && f.pos < len(f.src) && f.src[f.pos] == '{':
+			depth++
+			f.pos++
+		case c == '}' && depth > 0:
+			depth--
+		case c == '`' && depth == 0:
+			f.emitRaw(start)
+			return
+		}
+	}
+	f.emitRaw(start)
+}
+
+func (f *formatter) copyLineComment() {
+	start := f.pos
+	for f.pos < len(f.src) && f.src[f.pos] != '\n' {
+		f.pos++
+	}
+	f.write(f.src[start:f.pos])
+	f.newline()
+}
+
+func (f *formatter) copyBlockComment() {
+	start := f.pos
+	end := strings.Index(f.src[f.pos+2:], "*/")
+	if end < 0 {
+		f.pos = len(f.src)
+	} else {
+		f.pos += end + 4
+	}
+	f.write(f.src[start:f.pos])
+}
+
+func (f *formatter) copyRegex() {
+	start := f.pos
+	f.pos++
+	inClass := false
+	for f.pos < len(f.src) {
+		c := f.src[f.pos]
+		f.pos++
+		if c == '\\' {
+			f.pos++
+			continue
+		}
+		if c == '\n' {
+			break
+		}
+		if c == '[' {
+			inClass = true
+		} else if c == ']' {
+			inClass = false
+		} else if c == '/' && !inClass {
+			for f.pos < len(f.src) && isIdentChar(f.src[f.pos]) {
+				f.pos++ // Flags
+			}
+			break
+		}
+	}
+	f.emitRaw(start)
+}
+
+func (f *formatter) emitRaw(start int) {
+	if f.pos > len(f.src) {
+		f.pos = len(f.src)
+	}
+	f.emit(f.src[start:f.pos])
+	f.lastID = ""
+}
+
+// regexAllowed reports whether a '/' here starts a regex literal rather
+// than a division
+func (f *formatter) regexAllowed() bool {
+	if f.lastID != "" {
+		return regexKeywords[f.lastID]
+	}
+	switch f.last {
+	case 0, '(', ',', '=', ':', '[', '!', '&', '|', '?', '{', '}', ';', '+', '-', '*', '%', '<', '>', '~', '^':
+		return true
+	}
+	return false
+}
+
+func isIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '$' || c >= 0x80
+}
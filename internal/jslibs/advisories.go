@@ -0,0 +1,100 @@
+package jslibs
+
+// Advisory is a known vulnerability in a range of library versions
+type Advisory struct {
+	ID       string `json:"id"`       // CVE (or GHSA) identifier
+	Severity string `json:"severity"` // low, medium, high, critical
+	Summary  string `json:"summary"`
+	Fixed    string `json:"fixed,omitempty"` // First fixed version ("" = no fix in this line)
+
+	library    string
+	introduced string // First affected version ("" = all earlier versions)
+}
+
+// advisories is a small curated dataset of well-known client-side library
+// vulnerabilities. It is not exhaustive: treat a clean result as "nothing
+// obvious", not "not vulnerable".
+var advisories = []Advisory{
+	// jQuery
+	{library: "jquery", Fixed: "1.9.0", ID: "CVE-2012-6708", Severity: "medium", Summary: "XSS: $(html) treats strings containing '<' anywhere as HTML"},
+	{library: "jquery", Fixed: "3.0.0", introduced: "1.4.0", ID: "CVE-2015-9251", Severity: "medium", Summary: "XSS: cross-domain ajax responses with text/javascript are executed"},
+	{library: "jquery", Fixed: "3.4.0", ID: "CVE-2019-11358", Severity: "medium", Summary: "Prototype pollution in jQuery.extend(true, ...)"},
+	{library: "jquery", Fixed: "3.5.0", introduced: "1.2.0", ID: "CVE-2020-11022", Severity: "medium", Summary: "XSS: htmlPrefilter passes untrusted HTML to DOM manipulation methods"},
+	{library: "jquery", Fixed: "3.5.0", introduced: "1.0.3", ID: "CVE-2020-11023", Severity: "medium", Summary: "XSS: <option> elements in untrusted HTML passed to DOM manipulation methods"},
+
+	// jQuery UI
+	{library: "jquery-ui", Fixed: "1.12.0", ID: "CVE-2016-7103", Severity: "medium", Summary: "XSS in the dialog closeText option"},
+	{library: "jquery-ui", Fixed: "1.13.0", ID: "CVE-2021-41182", Severity: "medium", Summary: "XSS in the datepicker altField option"},
+	{library: "jquery-ui", Fixed: "1.13.0", ID: "CVE-2021-41183", Severity: "medium", Summary: "XSS in datepicker *Text options"},
+	{library: "jquery-ui", Fixed: "1.13.0", ID: "CVE-2021-41184", Severity: "medium", Summary: "XSS in the position() 'of' option"},
+	{library: "jquery-ui", Fixed: "1.13.2", ID: "CVE-2022-31160", Severity: "medium", Summary: "XSS when refreshing checkboxradio with HTML-like labels"},
+
+	// Lodash
+	{library: "lodash", Fixed: "4.17.5", ID: "CVE-2018-3721", Severity: "medium", Summary: "Prototype pollution via merge, mergeWith and defaultsDeep"},
+	{library: "lodash", Fixed: "4.17.11", ID: "CVE-2018-16487", Severity: "high", Summary: "Prototype pollution via merge, mergeWith and defaultsDeep"},
+	{library: "lodash", Fixed: "4.17.12", ID: "CVE-2019-10744", Severity: "critical", Summary: "Prototype pollution via defaultsDeep"},
+	{library: "lodash", Fixed: "4.17.19", ID: "CVE-2020-8203", Severity: "high", Summary: "Prototype pollution via zipObjectDeep"},
+	{library: "lodash", Fixed: "4.17.21", ID: "CVE-2021-23337", Severity: "high", Summary: "Command injection via template"},
+
+	// Underscore
+	{library: "underscore", Fixed: "1.12.1", introduced: "1.3.2", ID: "CVE-2021-23358", Severity: "high", Summary: "Arbitrary code execution via template variable option"},
+
+	// AngularJS (end of life since 2022: no further fixes)
+	{library: "angularjs", Fixed: "1.7.9", ID: "CVE-2019-10768", Severity: "high", Summary: "Prototype pollution via merge"},
+	{library: "angularjs", Fixed: "1.8.0", ID: "CVE-2020-7676", Severity: "medium", Summary: "XSS: <option> wrapped in <select> is not sanitized"},
+	{library: "angularjs", ID: "CVE-2022-25869", Severity: "medium", Summary: "XSS via <textarea> interpolation (Internet Explorer); AngularJS is end of life"},
+
+	// Vue 2 (end of life since 2023)
+	{library: "vue", Fixed: "3.0.0", introduced: "2.0.0", ID: "CVE-2024-6783", Severity: "medium", Summary: "XSS via prototype pollution in the template compiler; Vue 2 is end of life"},
+
+	// Bootstrap
+	{library: "bootstrap", Fixed: "3.4.0", ID: "CVE-2018-14040", Severity: "medium", Summary: "XSS in the collapse data-parent attribute"},
+	{library: "bootstrap", Fixed: "4.1.2", introduced: "4.0.0", ID: "CVE-2018-14040", Severity: "medium", Summary: "XSS in the collapse data-parent attribute"},
+	{library: "bootstrap", Fixed: "3.4.1", ID: "CVE-2019-8331", Severity: "medium", Summary: "XSS in tooltip/popover data-template"},
+	{library: "bootstrap", Fixed: "4.3.1", introduced: "4.0.0", ID: "CVE-2019-8331", Severity: "medium", Summary: "XSS in tooltip/popover data-template"},
+
+	// Moment.js
+	{library: "moment", Fixed: "2.19.3", ID: "CVE-2017-18214", Severity: "high", Summary: "ReDoS in date parsing"},
+	{library: "moment", Fixed: "2.29.2", introduced: "1.0.1", ID: "CVE-2022-24785", Severity: "high", Summary: "Path traversal in locale loading (user-controlled locale names)"},
+	{library: "moment", Fixed: "2.29.4", introduced: "2.18.0", ID: "CVE-2022-31129", Severity: "high", Summary: "ReDoS in RFC 2822 date parsing"},
+
+	// Handlebars
+	{library: "handlebars", Fixed: "4.3.0", ID: "CVE-2019-19919", Severity: "critical", Summary: "Prototype pollution leading to code execution in templates"},
+	{library: "handlebars", Fixed: "4.7.7", ID: "CVE-2021-23369", Severity: "critical", Summary: "Remote code execution when compiling untrusted templates"},
+	{library: "handlebars", Fixed: "4.7.7", ID: "CVE-2021-23383", Severity: "critical", Summary: "Prototype pollution when compiling untrusted templates"},
+
+	// DOMPurify
+	{library: "dompurify", Fixed: "2.0.17", ID: "CVE-2020-26870", Severity: "medium", Summary: "Mutation XSS bypass"},
+	{library: "dompurify", Fixed: "2.5.4", ID: "CVE-2024-45801", Severity: "high", Summary: "Sanitizer bypass via deep nesting and prototype pollution"},
+	{library: "dompurify", Fixed: "3.1.3", introduced: "3.0.0", ID: "CVE-2024-45801", Severity: "high", Summary: "Sanitizer bypass via deep nesting and prototype pollution"},
+
+	// Axios
+	{library: "axios", Fixed: "0.21.1", ID: "CVE-2020-28168", Severity: "medium", Summary: "SSRF: redirects to private addresses bypass proxy settings"},
+	{library: "axios", Fixed: "0.21.2", ID: "CVE-2021-3749", Severity: "high", Summary: "ReDoS in trim"},
+	{library: "axios", Fixed: "1.6.0", introduced: "0.8.1", ID: "CVE-2023-45857", Severity: "medium", Summary: "XSRF-TOKEN cookie sent to any host"},
+
+	// React
+	{library: "react", Fixed: "16.4.2", introduced: "16.0.0", ID: "CVE-2018-6341", Severity: "medium", Summary: "XSS in server-side rendered attribute names (react-dom/server)"},
+}
+
+// Advisories returns the known advisories affecting a library version.
+// Detections without a version match nothing.
+func Advisories(library, version string) []Advisory {
+	if version == "" {
+		return nil
+	}
+	var matched []Advisory
+	for _, a := range advisories {
+		if a.library != library {
+			continue
+		}
+		if a.introduced != "" && CompareVersions(version, a.introduced) < 0 {
+			continue
+		}
+		if a.Fixed != "" && CompareVersions(version, a.Fixed) >= 0 {
+			continue
+		}
+		matched = append(matched, a)
+	}
+	return matched
+}
@@ -0,0 +1,272 @@
+// Package jslibs fingerprints JavaScript libraries and their versions from
+// script URLs and contents, and matches them against an embedded list of
+// known advisories. Signatures follow the approach of retire.js: license
+// banners and version constants survive minification.
+package jslibs
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Detection is a library found in one script
+type Detection struct {
+	Library string `json:"library"`
+	Version string `json:"version,omitempty"` // Empty when only presence is known
+	Via     string `json:"via"`               // url, content
+}
+
+// signature identifies one library. Patterns capture the version in group 1;
+// presence patterns have no group and only prove the library is there.
+type signature struct {
+	library  string
+	url      []*regexp.Regexp
+	content  []*regexp.Regexp
+	presence []*regexp.Regexp
+}
+
+const version = `(\d+\.\d+(?:\.\d+)?)`
+
+var signatures = []signature{
+	{
+		library: "jquery",
+		url: []*regexp.Regexp{
+			regexp.MustCompile(`/jquery[.-]` + version + `(?:\.slim)?(?:\.min)?\.js`),
+			regexp.MustCompile(`/jquery/` + version + `/jquery(?:\.slim)?(?:\.min)?\.js`),
+			regexp.MustCompile(`/jquery@` + version + `/`),
+		},
+		content: []*regexp.Regexp{
+			regexp.MustCompile(`/\*!? jQuery v` + version),
+			regexp.MustCompile(`jQuery JavaScript Library v` + version),
+			regexp.MustCompile(`\.fn\.jquery\s*=\s*["']` + version),
+			regexp.MustCompile(`jquery:\s*["']` + version + `["']`),
+		},
+	},
+	{
+		library: "jquery-ui",
+		url: []*regexp.Regexp{
+			regexp.MustCompile(`/jquery-ui[.-]` + version + `(?:\.min)?\.js`),
+			regexp.MustCompile(`/jqueryui/` + version + `/`),
+		},
+		content: []*regexp.Regexp{
+			regexp.MustCompile(`jQuery UI - v` + version),
+			regexp.MustCompile(`\.ui\.version\s*=\s*["']` + version),
+		},
+	},
+	{
+		library: "lodash",
+		url: []*regexp.Regexp{
+			regexp.MustCompile(`/lodash[.-]` + version + `(?:\.min)?\.js`),
+			regexp.MustCompile(`/lodash(?:\.js)?/` + version + `/`),
+			regexp.MustCompile(`/lodash@` + version + `/`),
+		},
+		content: []*regexp.Regexp{
+			regexp.MustCompile(`(?s)@license\s+(?:Lo-Dash|lodash|Lodash)[^\n]*?` + version),
+			regexp.MustCompile(`(?s)lodash\.com.{0,1000}?VERSION\s*=\s*["']` + version),
+			regexp.MustCompile(`(?s)VERSION\s*=\s*["']` + version + `["'].{0,300}?lodash`),
+		},
+	},
+	{
+		library: "underscore",
+		url: []*regexp.Regexp{
+			regexp.MustCompile(`/underscore[.-]` + version + `(?:\.min)?\.js`),
+			regexp.MustCompile(`/underscore(?:\.js)?/` + version + `/`),
+			regexp.MustCompile(`/underscore@` + version + `/`),
+		},
+		content: []*regexp.Regexp{
+			regexp.MustCompile(`Underscore\.js ` + version),
+			regexp.MustCompile(`_\.VERSION\s*=\s*["']` + version),
+		},
+	},
+	{
+		library: "react",
+		url: []*regexp.Regexp{
+			regexp.MustCompile(`/react(?:-dom)?@` + version + `/`),
+			regexp.MustCompile(`/react(?:-dom)?/` + version + `/`),
+		},
+		content: []*regexp.Regexp{
+			regexp.MustCompile(`@license React v` + version),
+			regexp.MustCompile(`ReactVersion\s*=\s*["']` + version),
+			regexp.MustCompile(`(?s)react\.(?:production|development).{0,200}?version:\s*["']` + version),
+		},
+		presence: []*regexp.Regexp{
+			regexp.MustCompile(`__SECRET_INTERNALS_DO_NOT_USE_OR_YOU_WILL_BE_FIRED|__REACT_DEVTOOLS_GLOBAL_HOOK__`),
+		},
+	},
+	{
+		library: "angularjs",
+		url: []*regexp.Regexp{
+			regexp.MustCompile(`/angular(?:js)?/` + version + `/angular(?:\.min)?\.js`),
+			regexp.MustCompile(`/angular@` + version + `/`),
+			regexp.MustCompile(`/angular[.-]` + version + `(?:\.min)?\.js`),
+		},
+		content: []*regexp.Regexp{
+			regexp.MustCompile(`@license AngularJS v` + version),
+			regexp.MustCompile(`AngularJS v` + version),
+		},
+	},
+	{
+		library: "vue",
+		url: []*regexp.Regexp{
+			regexp.MustCompile(`/vue@` + version + `/`),
+			regexp.MustCompile(`/vue/` + version + `/vue`),
+		},
+		content: []*regexp.Regexp{
+			regexp.MustCompile(`Vue\.js v` + version),
+			regexp.MustCompile(`(?s)__VUE__.{0,500}?version\s*[:=]\s*["']` + version),
+		},
+		presence: []*regexp.Regexp{
+			regexp.MustCompile(`__VUE_DEVTOOLS_GLOBAL_HOOK__|__VUE_OPTIONS_API__`),
+		},
+	},
+	{
+		library: "bootstrap",
+		url: []*regexp.Regexp{
+			regexp.MustCompile(`/bootstrap@` + version + `/`),
+			regexp.MustCompile(`/bootstrap/` + version + `/`),
+			regexp.MustCompile(`/bootstrap[.-]` + version + `(?:\.bundle)?(?:\.min)?\.js`),
+		},
+		content: []*regexp.Regexp{
+			regexp.MustCompile(`Bootstrap v` + version),
+		},
+	},
+	{
+		library: "moment",
+		url: []*regexp.Regexp{
+			regexp.MustCompile(`/moment(?:\.js)?/` + version + `/`),
+			regexp.MustCompile(`/moment@` + version + `/`),
+			regexp.MustCompile(`/moment[.-]` + version + `(?:\.min)?\.js`),
+		},
+		content: []*regexp.Regexp{
+			regexp.MustCompile(`(?s)//! moment\.js\s*(?://! )?version\s*:\s*` + version),
+			regexp.MustCompile(`(?s)hooks\.version\s*=\s*["']` + version + `["'].{0,300}?moment`),
+		},
+	},
+	{
+		library: "handlebars",
+		url: []*regexp.Regexp{
+			regexp.MustCompile(`/handlebars(?:\.js)?/v?` + version + `/`),
+			regexp.MustCompile(`/handlebars@` + version + `/`),
+			regexp.MustCompile(`/handlebars[.-]v?` + version + `(?:\.min)?\.js`),
+		},
+		content: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)handlebars v` + version),
+			regexp.MustCompile(`(?s)HandlebarsEnvironment.{0,1000}?VERSION\s*=\s*["']` + version),
+		},
+	},
+	{
+		library: "dompurify",
+		url: []*regexp.Regexp{
+			regexp.MustCompile(`/dompurify@` + version + `/`),
+			regexp.MustCompile(`/dompurify/` + version + `/`),
+		},
+		content: []*regexp.Regexp{
+			regexp.MustCompile(`@license DOMPurify ` + version),
+			regexp.MustCompile(`DOMPurify\.version\s*=\s*["']` + version),
+		},
+	},
+	{
+		library: "axios",
+		url: []*regexp.Regexp{
+			regexp.MustCompile(`/axios@` + version + `/`),
+			regexp.MustCompile(`/axios/` + version + `/`),
+		},
+		content: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)/\*!? axios v` + version),
+			regexp.MustCompile(`(?s)axios.{0,200}?VERSION\s*=\s*["']` + version),
+		},
+	},
+	{
+		library: "webpack",
+		presence: []*regexp.Regexp{
+			regexp.MustCompile(`__webpack_require__|webpackChunk|webpackJsonp`),
+		},
+	},
+	{
+		library: "next.js",
+		url: []*regexp.Regexp{
+			regexp.MustCompile(`/_next/static/`),
+		},
+		content: []*regexp.Regexp{
+			regexp.MustCompile(`(?s)__NEXT_DATA__.{0,1000}?version\s*[:=]\s*["']` + version),
+		},
+		presence: []*regexp.Regexp{
+			regexp.MustCompile(`__NEXT_DATA__|__next_f`),
+		},
+	},
+}
+
+// Detect returns the libraries in a script, identified by its URL and
+// body. A versioned match wins over presence of the same library.
+func Detect(scriptURL, body string) []Detection {
+	var found []Detection
+	for _, sig := range signatures {
+		detection, ok := detectOne(sig, scriptURL, body)
+		if ok {
+			found = append(found, detection)
+		}
+	}
+	return found
+}
+
+func detectOne(sig signature, scriptURL, body string) (Detection, bool) {
+	d := Detection{Library: sig.library}
+	for _, pattern := range sig.content {
+		if m := pattern.FindStringSubmatch(body); m != nil {
+			d.Version, d.Via = groupOrEmpty(m), "content"
+			return d, true
+		}
+	}
+	for _, pattern := range sig.url {
+		if m := pattern.FindStringSubmatch(scriptURL); m != nil {
+			d.Version, d.Via = groupOrEmpty(m), "url"
+			return d, true
+		}
+	}
+	for _, pattern := range sig.presence {
+		if pattern.MatchString(body) {
+			d.Via = "content"
+			return d, true
+		}
+	}
+	return d, false
+}
+
+func groupOrEmpty(m []string) string {
+	if len(m) > 1 {
+		return m[1]
+	}
+	return ""
+}
+
+// CompareVersions compares dotted numeric versions ("1.12.4" < "3.5.0").
+// Missing parts count as 0; pre-release suffixes are ignored.
+func CompareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		na, nb := versionPart(pa, i), versionPart(pb, i)
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	digits := parts[i]
+	for j, r := range digits {
+		if r < '0' || r > '9' {
+			digits = digits[:j]
+			break
+		}
+	}
+	n, _ := strconv.Atoi(digits)
+	return n
+}
@@ -0,0 +1,390 @@
+// Package jsonpath pulls values out of JSON documents with a small jq-like
+// path language, and lists the leaf paths of a document, so one field can be
+// read from a large body without printing all of it.
+//
+// Paths are a chain of steps, with an optional leading "$":
+//
+//	.key  ["key"]  ['key']   object member
+//	[0]  [-1]                array element (negative counts from the end)
+//	[]  [*]  .*              every element or member
+//	..key                    key at any depth
+//
+// "." alone is the whole document.
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type stepKind int
+
+const (
+	stepKey stepKind = iota
+	stepIndex
+	stepAll
+	stepDescend // ..key
+)
+
+type step struct {
+	kind  stepKind
+	key   string
+	index int
+}
+
+// Path is a parsed path expression
+type Path struct {
+	expr  string
+	steps []step
+}
+
+func (p Path) String() string { return p.expr }
+
+// Parse parses a path expression
+func Parse(expr string) (Path, error) {
+	p := Path{expr: expr}
+	s := strings.TrimSpace(expr)
+	s = strings.TrimPrefix(s, "$")
+	if s == "" || s == "." {
+		return p, nil
+	}
+
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], ".."):
+			key, n := identifier(s[i+2:])
+			if key == "" {
+				return p, fmt.Errorf("expected a key after '..' at offset %d", i)
+			}
+			p.steps = append(p.steps, step{kind: stepDescend, key: key})
+			i += 2 + n
+		case s[i] == '.':
+			if i+1 < len(s) && s[i+1] == '*' {
+				p.steps = append(p.steps, step{kind: stepAll})
+				i += 2
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '[' {
+				i++
+				continue
+			}
+			key, n := identifier(s[i+1:])
+			if key == "" {
+				return p, fmt.Errorf("expected a key after '.' at offset %d", i)
+			}
+			p.steps = append(p.steps, step{kind: stepKey, key: key})
+			i += 1 + n
+		case s[i] == '[':
+			st, n, err := bracket(s[i:])
+			if err != nil {
+				return p, fmt.Errorf("%v at offset %d", err, i)
+			}
+			p.steps = append(p.steps, st)
+			i += n
+		default:
+			if i == 0 {
+				// "users[0]" reads as ".users[0]"
+				key, n := identifier(s)
+				if key != "" {
+					p.steps = append(p.steps, step{kind: stepKey, key: key})
+					i += n
+					continue
+				}
+			}
+			return p, fmt.Errorf("unexpected %q at offset %d", s[i], i)
+		}
+	}
+	return p, nil
+}
+
+// identifier reads a bare key: anything up to the next '.' or '['
+func identifier(s string) (string, int) {
+	n := strings.IndexAny(s, ".[")
+	if n < 0 {
+		n = len(s)
+	}
+	return s[:n], n
+}
+
+// bracket parses a [...] step at the start of s and returns its length
+func bracket(s string) (step, int, error) {
+	end := -1
+	if len(s) > 1 && (s[1] == '"' || s[1] == '\'') {
+		// Quoted key: find the closing quote, then ']'
+		quote := s[1]
+		for j := 2; j < len(s); j++ {
+			if s[j] == '\\' {
+				j++
+				continue
+			}
+			if s[j] == quote {
+				if j+1 < len(s) && s[j+1] == ']' {
+					end = j + 1
+				}
+				break
+			}
+		}
+		if end < 0 {
+			return step{}, 0, fmt.Errorf("unterminated quoted key")
+		}
+		key, err := unquote(s[1:end])
+		if err != nil {
+			return step{}, 0, err
+		}
+		return step{kind: stepKey, key: key}, end + 1, nil
+	}
+
+	end = strings.IndexByte(s, ']')
+	if end < 0 {
+		return step{}, 0, fmt.Errorf("missing ']'")
+	}
+	inner := strings.TrimSpace(s[1:end])
+	if inner == "" || inner == "*" {
+		return step{kind: stepAll}, end + 1, nil
+	}
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return step{}, 0, fmt.Errorf("bad index %q (quote keys: [\"%s\"])", inner, inner)
+	}
+	return step{kind: stepIndex, index: index}, end + 1, nil
+}
+
+// unquote decodes a '...' or "..." key
+func unquote(raw string) (string, error) {
+	if raw[0] == '\'' {
+		raw = `"` + strings.ReplaceAll(strings.ReplaceAll(raw[1:len(raw)-1], `\'`, `'`), `"`, `\"`) + `"`
+	}
+	key, err := strconv.Unquote(raw)
+	if err != nil {
+		return "", fmt.Errorf("bad quoted key %s", raw)
+	}
+	return key, nil
+}
+
+// Decode parses a JSON document, keeping numbers as written
+func Decode(doc string) (interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(doc))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("trailing data after JSON value")
+	}
+	return v, nil
+}
+
+// Get returns the values at p in doc (a decoded document), in document
+// order for arrays and key order for objects. Steps that don't apply (a key
+// on an array, an index out of range) yield nothing.
+func Get(doc interface{}, p Path) []interface{} {
+	nodes := []interface{}{doc}
+	for _, st := range p.steps {
+		var next []interface{}
+		for _, node := range nodes {
+			next = append(next, apply(node, st)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func apply(node interface{}, st step) []interface{} {
+	switch st.kind {
+	case stepKey:
+		if obj, ok := node.(map[string]interface{}); ok {
+			if v, ok := obj[st.key]; ok {
+				return []interface{}{v}
+			}
+		}
+	case stepIndex:
+		if arr, ok := node.([]interface{}); ok {
+			i := st.index
+			if i < 0 {
+				i += len(arr)
+			}
+			if i >= 0 && i < len(arr) {
+				return []interface{}{arr[i]}
+			}
+		}
+	case stepAll:
+		return children(node)
+	case stepDescend:
+		var out []interface{}
+		if obj, ok := node.(map[string]interface{}); ok {
+			if v, ok := obj[st.key]; ok {
+				out = append(out, v)
+			}
+		}
+		for _, child := range children(node) {
+			out = append(out, apply(child, st)...)
+		}
+		return out
+	}
+	return nil
+}
+
+// children returns array elements, or object members by key
+func children(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]interface{}, len(keys))
+		for i, k := range keys {
+			out[i] = v[k]
+		}
+		return out
+	}
+	return nil
+}
+
+// TypeOf names the JSON type of a decoded value, as in output.JSONSkeleton
+func TypeOf(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number, float64:
+		return "number"
+	case bool:
+		return "bool"
+	}
+	return "null"
+}
+
+// Leaf is a distinct leaf path of a document. Array indices are folded into
+// "[]", so the path selects every element's value with Get.
+type Leaf struct {
+	Path  string   `json:"path"`
+	Types []string `json:"types"` // JSON types seen at the path
+	Count int      `json:"count"` // Values at the path
+}
+
+// Leaves lists the leaf paths of a JSON document in document order.
+// Scalars and empty objects and arrays are leaves.
+func Leaves(doc string) ([]Leaf, error) {
+	dec := json.NewDecoder(strings.NewReader(doc))
+	dec.UseNumber()
+	l := &leafLister{index: map[string]int{}}
+	if err := l.walk(dec, ""); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("trailing data after JSON value")
+	}
+	return l.leaves, nil
+}
+
+type leafLister struct {
+	leaves []Leaf
+	index  map[string]int
+}
+
+func (l *leafLister) add(path, typ string) {
+	if path == "" {
+		path = "."
+	}
+	i, ok := l.index[path]
+	if !ok {
+		i = len(l.leaves)
+		l.index[path] = i
+		l.leaves = append(l.leaves, Leaf{Path: path})
+	}
+	leaf := &l.leaves[i]
+	leaf.Count++
+	for _, t := range leaf.Types {
+		if t == typ {
+			return
+		}
+	}
+	leaf.Types = append(leaf.Types, typ)
+}
+
+func (l *leafLister) walk(dec *json.Decoder, path string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		empty := true
+		if t == '{' {
+			for dec.More() {
+				empty = false
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if err := l.walk(dec, path+KeyStep(key.(string))); err != nil {
+					return err
+				}
+			}
+		} else {
+			for dec.More() {
+				empty = false
+				if err := l.walk(dec, path+"[]"); err != nil {
+					return err
+				}
+			}
+		}
+		if _, err := dec.Token(); err != nil { // closing delimiter
+			return err
+		}
+		if empty {
+			typ := "object"
+			if t == '[' {
+				typ = "array"
+			}
+			l.add(path, typ)
+		}
+	case string:
+		l.add(path, "string")
+	case json.Number:
+		l.add(path, "number")
+	case bool:
+		l.add(path, "bool")
+	case nil:
+		l.add(path, "null")
+	}
+	return nil
+}
+
+// KeyStep renders an object key as a path step: .key for plain keys,
+// ["key"] otherwise
+func KeyStep(key string) string {
+	plain := key != ""
+	for i, r := range key {
+		if !(r == '_' || r == '-' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' && i > 0) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return "." + key
+	}
+	return "[" + strconv.Quote(key) + "]"
+}
+
+// Text renders a decoded value as text: strings as they are, anything else
+// as compact JSON
+func Text(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	out, _ := json.Marshal(v)
+	return string(out)
+}
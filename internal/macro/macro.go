@@ -0,0 +1,215 @@
+// Package macro defines request chains stored as YAML: each step is a
+// request whose URL, headers and body may reference {{variables}}, and
+// whose response can define new variables for the steps after it (a CSRF
+// token, a session cookie, an access token).
+package macro
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/repplus/rep-cli/internal/jsonpath"
+	"github.com/repplus/rep-cli/internal/store"
+	"gopkg.in/yaml.v3"
+)
+
+// Macro is a chain of requests
+type Macro struct {
+	Name  string            `yaml:"name,omitempty"`
+	Vars  map[string]string `yaml:"vars,omitempty"` // Initial values, overridable at run time
+	Steps []Step            `yaml:"steps"`
+}
+
+// Step is one request of a macro
+type Step struct {
+	Name    string      `yaml:"name,omitempty"`
+	From    string      `yaml:"from,omitempty"` // Captured request the step was recorded from
+	Request Request     `yaml:"request"`
+	Extract []Extractor `yaml:"extract,omitempty"`
+	Expect  *Expect     `yaml:"expect,omitempty"`
+}
+
+// Request is a request template; {{name}} is replaced by a variable
+type Request struct {
+	Method  string            `yaml:"method"`
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Body    string            `yaml:"body,omitempty"`
+}
+
+// Extractor defines a variable from a step's response. JSON takes a
+// jsonpath expression over the body; Regex matches the body, or the values
+// of Header when set, and yields its first group (or the whole match);
+// Header alone yields the header value.
+type Extractor struct {
+	Var      string `yaml:"var"`
+	JSON     string `yaml:"json,omitempty"`
+	Header   string `yaml:"header,omitempty"`
+	Regex    string `yaml:"regex,omitempty"`
+	Optional bool   `yaml:"optional,omitempty"` // Don't fail the run when nothing matches
+}
+
+// Expect checks a step's response
+type Expect struct {
+	Status int `yaml:"status,omitempty"`
+}
+
+// varRef matches {{name}} references
+var varRef = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// Load reads and validates a macro file
+func Load(path string) (*Macro, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Macro
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// Validate checks that every step has a request and every extractor a
+// variable and a source
+func (m *Macro) Validate() error {
+	if len(m.Steps) == 0 {
+		return fmt.Errorf("no steps")
+	}
+	for i, step := range m.Steps {
+		if step.Request.Method == "" || step.Request.URL == "" {
+			return fmt.Errorf("step %d: request needs a method and a url", i+1)
+		}
+		for _, ex := range step.Extract {
+			if ex.Var == "" {
+				return fmt.Errorf("step %d: extractor without var", i+1)
+			}
+			if ex.JSON == "" && ex.Header == "" && ex.Regex == "" {
+				return fmt.Errorf("step %d: extractor %s needs json, header or regex", i+1, ex.Var)
+			}
+			if ex.JSON != "" {
+				if _, err := jsonpath.Parse(ex.JSON); err != nil {
+					return fmt.Errorf("step %d: extractor %s: %v", i+1, ex.Var, err)
+				}
+			}
+			if ex.Regex != "" {
+				if _, err := regexp.Compile(ex.Regex); err != nil {
+					return fmt.Errorf("step %d: extractor %s: %v", i+1, ex.Var, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Marshal renders a macro as YAML
+func Marshal(m *Macro) ([]byte, error) {
+	return yaml.Marshal(m)
+}
+
+// StepName is the step's name, or its position
+func (m *Macro) StepName(i int) string {
+	if m.Steps[i].Name != "" {
+		return m.Steps[i].Name
+	}
+	return fmt.Sprintf("step %d", i+1)
+}
+
+// Expand replaces {{name}} references in s with vars, falling back to the
+// environment. Unresolved references are kept and returned as missing.
+func Expand(s string, vars map[string]string) (string, []string) {
+	var missing []string
+	out := varRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := varRef.FindStringSubmatch(ref)[1]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		missing = append(missing, name)
+		return ref
+	})
+	return out, missing
+}
+
+// Build expands a step's request template into a request
+func (r Request) Build(vars map[string]string) (*store.Request, []string) {
+	var missing []string
+	expand := func(s string) string {
+		out, m := Expand(s, vars)
+		missing = append(missing, m...)
+		return out
+	}
+	req := &store.Request{
+		Method:  strings.ToUpper(expand(r.Method)),
+		URL:     expand(r.URL),
+		Headers: store.HeaderMap{},
+		Body:    expand(r.Body),
+	}
+	names := make([]string, 0, len(r.Headers))
+	for name := range r.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		req.Headers[name] = []string{expand(r.Headers[name])}
+	}
+	store.ComputeRequestFields(req)
+	return req, dedupe(missing)
+}
+
+// Run applies the extractor to a response
+func (ex Extractor) Run(headers store.HeaderMap, body string) (string, bool) {
+	if ex.JSON != "" {
+		doc, err := jsonpath.Decode(body)
+		if err != nil {
+			return "", false
+		}
+		path, _ := jsonpath.Parse(ex.JSON)
+		values := jsonpath.Get(doc, path)
+		if len(values) == 0 || values[0] == nil {
+			return "", false
+		}
+		return jsonpath.Text(values[0]), true
+	}
+
+	sources := []string{body}
+	if ex.Header != "" {
+		sources = store.HeaderValues(headers, ex.Header)
+	}
+	if ex.Regex == "" {
+		if len(sources) == 0 {
+			return "", false
+		}
+		return sources[0], true
+	}
+	pattern := regexp.MustCompile(ex.Regex)
+	for _, s := range sources {
+		if match := pattern.FindStringSubmatch(s); match != nil {
+			if len(match) > 1 {
+				return match[1], true
+			}
+			return match[0], true
+		}
+	}
+	return "", false
+}
+
+func dedupe(names []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, n := range names {
+		if !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	return out
+}
@@ -0,0 +1,377 @@
+package macro
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/repplus/rep-cli/internal/jsonpath"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// MinValueLength is the shortest request value traced back to a response;
+// shorter values (ids, flags) match responses by coincidence
+const MinValueLength = 8
+
+// skippedHeaders are set by the HTTP client, or describe the browser rather
+// than the request
+var skippedHeaders = map[string]bool{
+	"host":              true,
+	"content-length":    true,
+	"connection":        true,
+	"transfer-encoding": true,
+	"content-encoding":  true,
+	"accept-encoding":   true,
+}
+
+// contextLength bounds the literal text before a value in a regex extractor
+const contextLength = 24
+
+var plainValue = regexp.MustCompile(`^[A-Za-z0-9_.~+/=-]+$`)
+
+// candidate is a value a request sent, with a name hint for its variable
+type candidate struct {
+	value string
+	hint  string
+}
+
+// Record builds a macro from captured requests, in order. A value a request
+// sends that an earlier response produced (a Set-Cookie, a JSON field, a
+// token in a page) becomes a variable: the earlier step extracts it and the
+// later steps reference it.
+func Record(name string, requests []store.Request) *Macro {
+	m := &Macro{Name: name}
+	vars := map[string]string{} // value -> variable
+	used := map[string]bool{}   // variable names
+
+	for i := range requests {
+		req := &requests[i]
+		step := Step{
+			Name:    req.Method + " " + store.EndpointTemplate(req.Path),
+			From:    req.ID,
+			Request: recordRequest(req),
+		}
+		if req.Response != nil {
+			step.Expect = &Expect{Status: req.Response.Status}
+		}
+
+		for _, c := range candidates(req) {
+			if _, ok := vars[c.value]; ok {
+				continue
+			}
+			for j := i - 1; j >= 0; j-- {
+				ex, ok := extractorFor(&requests[j], c.value)
+				if !ok {
+					continue
+				}
+				ex.Var = varName(c.hint, used)
+				vars[c.value] = ex.Var
+				m.Steps[j].Extract = append(m.Steps[j].Extract, ex)
+				break
+			}
+		}
+		substitute(&step.Request, vars)
+		m.Steps = append(m.Steps, step)
+	}
+	return m
+}
+
+// recordRequest copies req as a template, without transport headers
+func recordRequest(req *store.Request) Request {
+	r := Request{Method: req.Method, URL: req.URL, Body: req.Body, Headers: map[string]string{}}
+	for name, values := range req.Headers {
+		if strings.HasPrefix(name, ":") || skippedHeaders[strings.ToLower(name)] || len(values) == 0 {
+			continue
+		}
+		r.Headers[name] = strings.Join(values, ", ")
+	}
+	return r
+}
+
+// candidates lists the values req sent that are long enough to trace:
+// cookies, bearer tokens, other header values, query and body parameters
+func candidates(req *store.Request) []candidate {
+	var out []candidate
+	add := func(value, hint string) {
+		if len(value) >= MinValueLength {
+			out = append(out, candidate{value: value, hint: hint})
+		}
+	}
+
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(name, ":") || skippedHeaders[lower] {
+			continue
+		}
+		for _, value := range req.Headers[name] {
+			switch lower {
+			case "cookie":
+				for _, pair := range strings.Split(value, ";") {
+					if k, v, ok := strings.Cut(strings.TrimSpace(pair), "="); ok {
+						add(v, k)
+					}
+				}
+			case "authorization":
+				if scheme, token, ok := strings.Cut(value, " "); ok {
+					add(strings.TrimSpace(token), strings.ToLower(scheme)+"_token")
+				} else {
+					add(value, "authorization")
+				}
+			case "user-agent", "accept", "accept-language", "referer", "origin", "content-type":
+			default:
+				add(value, name)
+			}
+		}
+	}
+
+	if u, err := url.Parse(req.URL); err == nil {
+		for k, values := range u.Query() {
+			for _, v := range values {
+				add(v, k)
+			}
+		}
+	}
+
+	contentType := strings.ToLower(store.HeaderFirst(req.Headers, "content-type"))
+	switch {
+	case strings.Contains(contentType, "x-www-form-urlencoded"):
+		if form, err := url.ParseQuery(req.Body); err == nil {
+			for k, values := range form {
+				for _, v := range values {
+					add(v, k)
+				}
+			}
+		}
+	case strings.Contains(contentType, "json"):
+		if doc, err := jsonpath.Decode(req.Body); err == nil {
+			jsonStrings(doc, "", add)
+		}
+	}
+
+	// Longest first, so a value containing another is replaced whole
+	sort.Slice(out, func(i, j int) bool {
+		if len(out[i].value) != len(out[j].value) {
+			return len(out[i].value) > len(out[j].value)
+		}
+		return out[i].value < out[j].value
+	})
+	return out
+}
+
+// jsonStrings calls add for every string in doc, hinted by its key
+func jsonStrings(v interface{}, key string, add func(value, hint string)) {
+	switch t := v.(type) {
+	case string:
+		add(t, key)
+	case map[string]interface{}:
+		for k, child := range t {
+			jsonStrings(child, k, add)
+		}
+	case []interface{}:
+		for _, child := range t {
+			jsonStrings(child, key, add)
+		}
+	}
+}
+
+// extractorFor finds where req's response produced value: a cookie, a JSON
+// field, another header or the body text. The extractor is checked to
+// yield exactly value.
+func extractorFor(req *store.Request, value string) (Extractor, bool) {
+	if req.Response == nil {
+		return Extractor{}, false
+	}
+	headers := req.Response.Headers
+	body := store.ResponseBodyText(req)
+	check := func(ex Extractor) bool {
+		got, ok := ex.Run(headers, body)
+		return ok && got == value
+	}
+
+	for _, cookie := range store.HeaderValues(headers, "set-cookie") {
+		name, rest, ok := strings.Cut(cookie, "=")
+		if !ok || !strings.HasPrefix(rest, value) {
+			continue
+		}
+		ex := Extractor{Header: "Set-Cookie", Regex: "^" + regexp.QuoteMeta(strings.TrimSpace(name)) + "=([^;]*)"}
+		if check(ex) {
+			return ex, true
+		}
+	}
+
+	if doc, err := jsonpath.Decode(body); err == nil {
+		if path := findString(doc, value, ""); path != "" {
+			ex := Extractor{JSON: path}
+			if check(ex) {
+				return ex, true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		if !strings.EqualFold(name, "set-cookie") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range headers[name] {
+			if regex, ok := contextRegex(v, value); ok {
+				ex := Extractor{Header: name, Regex: regex}
+				if check(ex) {
+					return ex, true
+				}
+			}
+		}
+	}
+
+	if regex, ok := contextRegex(body, value); ok {
+		ex := Extractor{Regex: regex}
+		if check(ex) {
+			return ex, true
+		}
+	}
+	return Extractor{}, false
+}
+
+// findString returns the path of the first string equal to want in doc
+func findString(v interface{}, want, path string) string {
+	switch t := v.(type) {
+	case string:
+		if t == want {
+			if path == "" {
+				return "."
+			}
+			return path
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if p := findString(t[k], want, path+jsonpath.KeyStep(k)); p != "" {
+				return p
+			}
+		}
+	case []interface{}:
+		for i, child := range t {
+			if p := findString(child, want, path+"["+strconv.Itoa(i)+"]"); p != "" {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// contextRegex matches value in text by the literal text just before its
+// first occurrence (on the same line)
+func contextRegex(text, value string) (string, bool) {
+	idx := strings.Index(text, value)
+	if idx < 0 {
+		return "", false
+	}
+	start := idx - contextLength
+	if start < 0 {
+		start = 0
+	}
+	prefix := text[start:idx]
+	if nl := strings.LastIndexByte(prefix, '\n'); nl >= 0 {
+		prefix = prefix[nl+1:]
+	}
+	// Don't start inside a word or a multi-byte character
+	if start > 0 {
+		if i := strings.IndexFunc(prefix, isNotWord); i >= 0 {
+			prefix = prefix[i:]
+		}
+	}
+	for len(prefix) > 0 && !utf8.RuneStart(prefix[0]) {
+		prefix = prefix[1:]
+	}
+	anchor := regexp.QuoteMeta(prefix)
+	if prefix == "" {
+		if idx != 0 && text[idx-1] != '\n' {
+			return "", false
+		}
+		anchor = "(?m)^"
+	}
+	class := `[^"'<>&\s;,]+`
+	if plainValue.MatchString(value) {
+		class = `[A-Za-z0-9_.~+/=-]+`
+	}
+	return anchor + "(" + class + ")", true
+}
+
+func isNotWord(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
+
+// varName turns a hint into an unused variable name
+func varName(hint string, used map[string]bool) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(hint) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	name := strings.Trim(b.String(), "_")
+	for strings.Contains(name, "__") {
+		name = strings.ReplaceAll(name, "__", "_")
+	}
+	switch {
+	case name == "":
+		name = "value"
+	case name[0] >= '0' && name[0] <= '9':
+		name = "v_" + name
+	}
+	base := name
+	for n := 2; used[name]; n++ {
+		name = base + "_" + strconv.Itoa(n)
+	}
+	used[name] = true
+	return name
+}
+
+// substitute replaces traced values with {{var}} references, longest
+// value first
+func substitute(r *Request, vars map[string]string) {
+	values := make([]string, 0, len(vars))
+	for v := range vars {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+	replace := func(s string) string {
+		for _, v := range values {
+			ref := "{{" + vars[v] + "}}"
+			s = strings.ReplaceAll(s, v, ref)
+			if escaped := url.QueryEscape(v); escaped != v {
+				s = strings.ReplaceAll(s, escaped, ref)
+			}
+		}
+		return s
+	}
+	r.URL = replace(r.URL)
+	r.Body = replace(r.Body)
+	for name, value := range r.Headers {
+		r.Headers[name] = replace(value)
+	}
+}
@@ -0,0 +1,212 @@
+// Package openapi reads the operations of an OpenAPI 3 or Swagger 2 spec and
+// matches observed requests against them.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// methods are the operation keys of a path item
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Spec is the part of a spec coverage needs
+type Spec struct {
+	Title      string
+	Version    string   // info.version
+	Format     string   // "openapi 3.0.3" or "swagger 2.0"
+	Hosts      []string // Hostnames of the servers (or Swagger host)
+	BasePaths  []string // Path prefixes of the servers, longest first
+	Operations []Operation
+}
+
+// Operation is one documented method + path
+type Operation struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"` // As documented: /users/{userId}
+	OperationID string `json:"operation_id,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+	Deprecated  bool   `json:"deprecated,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+type document struct {
+	OpenAPI  string `yaml:"openapi"`
+	Swagger  string `yaml:"swagger"`
+	Host     string `yaml:"host"`
+	BasePath string `yaml:"basePath"`
+	Info     struct {
+		Title   string `yaml:"title"`
+		Version string `yaml:"version"`
+	} `yaml:"info"`
+	Servers []struct {
+		URL       string `yaml:"url"`
+		Variables map[string]struct {
+			Default string `yaml:"default"`
+		} `yaml:"variables"`
+	} `yaml:"servers"`
+	Paths map[string]yaml.Node `yaml:"paths"`
+}
+
+type operationFields struct {
+	OperationID string `yaml:"operationId"`
+	Summary     string `yaml:"summary"`
+	Deprecated  bool   `yaml:"deprecated"`
+}
+
+// Parse reads a spec in YAML or JSON
+func Parse(data []byte) (*Spec, error) {
+	var doc document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		// JSON indented with tabs isn't valid YAML
+		var v interface{}
+		if json.Unmarshal(data, &v) != nil {
+			return nil, err
+		}
+		data, _ = yaml.Marshal(v)
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+	}
+	spec := &Spec{Title: doc.Info.Title, Version: doc.Info.Version}
+	switch {
+	case doc.OpenAPI != "":
+		spec.Format = "openapi " + doc.OpenAPI
+		for _, s := range doc.Servers {
+			raw := s.URL
+			for name, v := range s.Variables {
+				raw = strings.ReplaceAll(raw, "{"+name+"}", v.Default)
+			}
+			u, err := url.Parse(raw)
+			if err != nil {
+				continue
+			}
+			if h := u.Hostname(); h != "" && !containsString(spec.Hosts, h) {
+				spec.Hosts = append(spec.Hosts, h)
+			}
+			spec.addBasePath(u.Path)
+		}
+	case doc.Swagger != "":
+		spec.Format = "swagger " + doc.Swagger
+		if doc.Host != "" {
+			spec.Hosts = []string{strings.Split(doc.Host, ":")[0]}
+		}
+		spec.addBasePath(doc.BasePath)
+	default:
+		return nil, fmt.Errorf("not an OpenAPI or Swagger document (no openapi or swagger field)")
+	}
+	sort.Slice(spec.BasePaths, func(i, j int) bool { return len(spec.BasePaths[i]) > len(spec.BasePaths[j]) })
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		// Skips x- extensions
+		if strings.HasPrefix(path, "/") {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		pattern, err := pathPattern(path)
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %w", path, err)
+		}
+		node := doc.Paths[path]
+		var item map[string]yaml.Node
+		_ = node.Decode(&item)
+		for _, m := range methods {
+			opNode, ok := item[m]
+			if !ok {
+				continue
+			}
+			var fields operationFields
+			_ = opNode.Decode(&fields)
+			spec.Operations = append(spec.Operations, Operation{
+				Method:      strings.ToUpper(m),
+				Path:        path,
+				OperationID: fields.OperationID,
+				Summary:     fields.Summary,
+				Deprecated:  fields.Deprecated,
+				pattern:     pattern,
+			})
+		}
+	}
+	return spec, nil
+}
+
+func (s *Spec) addBasePath(p string) {
+	p = strings.TrimSuffix(p, "/")
+	if p != "" && !containsString(s.BasePaths, p) {
+		s.BasePaths = append(s.BasePaths, p)
+	}
+}
+
+// Match finds the operation documenting method and path (no query string).
+// Without one, pathOnly reports whether the path is documented for another
+// method.
+func (s *Spec) Match(method, path string) (op *Operation, pathOnly bool) {
+	candidates := []string{path}
+	for _, base := range s.BasePaths {
+		if rest, ok := strings.CutPrefix(path, base); ok && (rest == "" || strings.HasPrefix(rest, "/")) {
+			if rest == "" {
+				rest = "/"
+			}
+			candidates = append([]string{rest}, candidates...)
+		}
+	}
+	for _, p := range candidates {
+		p = strings.TrimSuffix(p, "/")
+		if p == "" {
+			p = "/"
+		}
+		for i := range s.Operations {
+			o := &s.Operations[i]
+			if !o.pattern.MatchString(p) {
+				continue
+			}
+			if o.Method == strings.ToUpper(method) {
+				return o, false
+			}
+			pathOnly = true
+		}
+	}
+	return nil, pathOnly
+}
+
+// paramPattern is a path parameter
+var paramPattern = regexp.MustCompile(`\{[^}/]+\}`)
+
+// pathPattern turns a templated path into a regexp: {param} matches one
+// non-empty segment, or part of one ("/files/{name}.json")
+func pathPattern(path string) (*regexp.Regexp, error) {
+	path = strings.TrimSuffix(path, "/")
+	if path == "" {
+		path = "/"
+	}
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range paramPattern.FindAllStringIndex(path, -1) {
+		b.WriteString(regexp.QuoteMeta(path[last:loc[0]]))
+		b.WriteString(`[^/]+`)
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(path[last:]))
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonMarker is the key and prefix of the elision markers TruncateJSON adds
+const jsonMarker = "…"
+
+// jsonLimits are the successive (string chars, items, depth) limits
+// TruncateJSON tries until the document fits; 0 is unlimited
+var jsonLimits = []struct{ str, items, depth int }{
+	{0, 0, 0},
+	{200, 50, 0},
+	{120, 30, 0},
+	{80, 20, 0},
+	{60, 15, 0},
+	{40, 10, 0},
+	{32, 7, 0},
+	{24, 5, 0},
+	{16, 3, 0},
+	{16, 2, 0},
+	{16, 1, 0},
+	{16, 1, 8},
+	{16, 1, 5},
+	{16, 1, 3},
+	{16, 1, 2},
+	{16, 1, 1},
+}
+
+// jsonNode is a parsed JSON value that keeps object key order
+type jsonNode struct {
+	kind     byte   // '{', '[', 's' string, 'v' number, bool or null
+	value    string // Strings, and the literal of other scalars
+	keys     []string
+	children []jsonNode
+}
+
+// TruncateJSON shortens a JSON document to about maxSize bytes while keeping
+// it valid JSON: long strings end with "…(+N chars)", long arrays with a
+// "…(+N items)" element, large objects with a "…": "+N keys" member, and
+// values nested too deep are collapsed the same way. Strings are first cut to
+// maxString chars (0 leaves them whole until the size requires it). Returns
+// false if body is not a single JSON object or array. The result may exceed
+// maxSize when even the tightest limits don't fit.
+func TruncateJSON(body string, maxSize, maxString int) (string, bool) {
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return "", false
+	}
+	dec := json.NewDecoder(strings.NewReader(trimmed))
+	dec.UseNumber()
+	root, err := parseJSONNode(dec)
+	if err != nil {
+		return "", false
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return "", false
+	}
+
+	for _, l := range jsonLimits {
+		str := l.str
+		if maxString > 0 && (str == 0 || str > maxString) {
+			str = maxString
+		}
+		w := &jsonWriter{maxString: str, maxItems: l.items, maxDepth: l.depth, maxSize: maxSize}
+		w.write(&root, 0)
+		if !w.over && w.b.Len() <= maxSize {
+			return w.b.String(), true
+		}
+	}
+	// Nothing fits: keep the tightest rendering, complete
+	last := jsonLimits[len(jsonLimits)-1]
+	w := &jsonWriter{maxString: last.str, maxItems: last.items, maxDepth: last.depth}
+	w.write(&root, 0)
+	return w.b.String(), true
+}
+
+func parseJSONNode(dec *json.Decoder) (jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return jsonNode{}, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		n := jsonNode{kind: byte(t)}
+		for dec.More() {
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return n, err
+				}
+				n.keys = append(n.keys, key.(string))
+			}
+			child, err := parseJSONNode(dec)
+			if err != nil {
+				return n, err
+			}
+			n.children = append(n.children, child)
+		}
+		_, err := dec.Token() // closing '}' or ']'
+		return n, err
+	case string:
+		return jsonNode{kind: 's', value: t}, nil
+	case json.Number:
+		return jsonNode{kind: 'v', value: t.String()}, nil
+	case bool:
+		return jsonNode{kind: 'v', value: fmt.Sprint(t)}, nil
+	default:
+		return jsonNode{kind: 'v', value: "null"}, nil
+	}
+}
+
+// jsonWriter renders a jsonNode compactly within its limits
+type jsonWriter struct {
+	b         strings.Builder
+	maxString int
+	maxItems  int
+	maxDepth  int
+	maxSize   int  // Stop rendering past this size; 0 renders everything
+	over      bool // maxSize was exceeded
+}
+
+func (w *jsonWriter) write(n *jsonNode, depth int) {
+	if w.maxSize > 0 && w.b.Len() > w.maxSize {
+		w.over = true
+		return
+	}
+	switch n.kind {
+	case 's':
+		s := n.value
+		if count := utf8.RuneCountInString(s); w.maxString > 0 && count > w.maxString {
+			s = fmt.Sprintf("%s%s(+%d chars)", string([]rune(s)[:w.maxString]), jsonMarker, count-w.maxString)
+		}
+		writeJSONString(&w.b, s)
+	case 'v':
+		w.b.WriteString(n.value)
+	case '{':
+		shown := w.shown(len(n.children), depth)
+		w.b.WriteByte('{')
+		for i := 0; i < shown; i++ {
+			if i > 0 {
+				w.b.WriteByte(',')
+			}
+			writeJSONString(&w.b, n.keys[i])
+			w.b.WriteByte(':')
+			w.write(&n.children[i], depth+1)
+		}
+		if rest := len(n.children) - shown; rest > 0 {
+			if shown > 0 {
+				w.b.WriteByte(',')
+			}
+			writeJSONString(&w.b, jsonMarker)
+			w.b.WriteByte(':')
+			writeJSONString(&w.b, fmt.Sprintf("+%d keys", rest))
+		}
+		w.b.WriteByte('}')
+	case '[':
+		shown := w.shown(len(n.children), depth)
+		w.b.WriteByte('[')
+		for i := 0; i < shown; i++ {
+			if i > 0 {
+				w.b.WriteByte(',')
+			}
+			w.write(&n.children[i], depth+1)
+		}
+		if rest := len(n.children) - shown; rest > 0 {
+			if shown > 0 {
+				w.b.WriteByte(',')
+			}
+			writeJSONString(&w.b, fmt.Sprintf("%s(+%d items)", jsonMarker, rest))
+		}
+		w.b.WriteByte(']')
+	}
+}
+
+// shown returns how many members of an object or array at depth are
+// rendered
+func (w *jsonWriter) shown(count, depth int) int {
+	if w.maxDepth > 0 && depth >= w.maxDepth {
+		return 0
+	}
+	if w.maxItems > 0 && count > w.maxItems {
+		return w.maxItems
+	}
+	return count
+}
+
+// writeJSONString writes s as a JSON string literal without HTML escaping
+func writeJSONString(b *strings.Builder, s string) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	b.Write(bytes.TrimRight(buf.Bytes(), "\n"))
+}
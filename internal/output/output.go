@@ -1,8 +1,11 @@
 package output
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/bytedance/sonic"
 	"github.com/repplus/rep-cli/internal/store"
@@ -50,6 +53,36 @@ func FormatBodySize(size int) string {
 	}
 }
 
+// FormatDuration formats milliseconds as "850ms" or "1.24s"
+func FormatDuration(ms float64) string {
+	if ms < 1000 {
+		return fmt.Sprintf("%.0fms", ms)
+	}
+	return fmt.Sprintf("%.2fs", ms/1000)
+}
+
+// TimingText summarizes a request's timing and transport details
+// ("182ms  sent 1.2KB  received 34.5KB  h2  203.0.113.7"), or "" if none
+func TimingText(req *store.Request) string {
+	var parts []string
+	if req.DurationMs > 0 {
+		parts = append(parts, FormatDuration(req.DurationMs))
+	}
+	if req.RequestBytes > 0 {
+		parts = append(parts, "sent "+FormatBodySize(int(req.RequestBytes)))
+	}
+	if req.ResponseBytes > 0 {
+		parts = append(parts, "received "+FormatBodySize(int(req.ResponseBytes)))
+	}
+	if req.Protocol != "" {
+		parts = append(parts, SanitizeText(req.Protocol))
+	}
+	if req.RemoteIP != "" {
+		parts = append(parts, SanitizeText(req.RemoteIP))
+	}
+	return strings.Join(parts, "  ")
+}
+
 // TruncateBody truncates response body for compact output
 // Returns the truncated body and whether it was truncated
 func TruncateBody(body string, contentType string, cfg store.TruncateConfig) (string, bool) {
@@ -65,14 +98,187 @@ func TruncateBody(body string, contentType string, cfg store.TruncateConfig) (st
 		return body, false
 	}
 
+	switch cfg.Mode {
+	case store.TruncateHeadTail:
+		return truncateHeadTail(body, cfg), true
+	case "", store.TruncateHead, store.TruncateStrings:
+		// JSON stays valid JSON, with elision markers instead of a cut
+		maxString := 0
+		if cfg.Mode == store.TruncateStrings {
+			maxString = cfg.MaxString
+		}
+		if truncated, ok := TruncateJSON(body, cfg.MaxBodySize, maxString); ok {
+			return truncated, true
+		}
+		// Not JSON: fall back to head truncation
+	case store.TruncateKeys:
+		if skeleton, ok := JSONSkeleton(body); ok {
+			if len(skeleton) > cfg.MaxBodySize {
+				skeleton = skeleton[:runeCut(skeleton, cfg.MaxBodySize)] + "…"
+			}
+			return skeleton + fmt.Sprintf("\n[keys only, %s total]", FormatBodySize(bodyLen)), true
+		}
+		// Not JSON: fall back to head truncation
+	}
+
 	// Truncate with size info
-	truncated := body[:cfg.MaxBodySize]
+	truncated := body[:runeCut(body, cfg.MaxBodySize)]
 	if cfg.ShowFullSize {
 		return truncated + fmt.Sprintf("\n[...truncated, %s total]", FormatBodySize(bodyLen)), true
 	}
 	return truncated + "\n[...truncated]", true
 }
 
+// truncateHeadTail keeps the start and the end of body within MaxBodySize.
+// Error details in JSON bodies (and stack trace causes) are often at the end.
+func truncateHeadTail(body string, cfg store.TruncateConfig) string {
+	tail := cfg.TailSize
+	if tail <= 0 || tail >= cfg.MaxBodySize {
+		tail = cfg.MaxBodySize / 2
+	}
+	headEnd := runeCut(body, cfg.MaxBodySize-tail)
+	tailStart := runeCut(body, len(body)-tail)
+	omitted := tailStart - headEnd
+
+	marker := fmt.Sprintf("\n[...%s omitted...]\n", FormatBodySize(omitted))
+	if cfg.ShowFullSize {
+		marker = fmt.Sprintf("\n[...%s omitted, %s total...]\n", FormatBodySize(omitted), FormatBodySize(len(body)))
+	}
+	return body[:headEnd] + marker + body[tailStart:]
+}
+
+// runeCut returns the largest index <= n that does not split a UTF-8 sequence
+func runeCut(s string, n int) int {
+	if n >= len(s) {
+		return len(s)
+	}
+	if n <= 0 {
+		return 0
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return n
+}
+
+// skeletonMaxDepth limits how deep JSONSkeleton descends before eliding
+const skeletonMaxDepth = 4
+
+// JSONSkeleton renders the structure of a JSON document with values replaced
+// by their type, keeping key order. Arrays show their first element and a
+// count: {"users": [{"id": number, "email": string}] (20 items)}.
+// Returns false if body is not a single JSON object or array.
+func JSONSkeleton(body string) (string, bool) {
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return "", false
+	}
+	dec := json.NewDecoder(strings.NewReader(trimmed))
+	dec.UseNumber()
+	var b strings.Builder
+	if err := writeSkeleton(dec, &b, 0); err != nil {
+		return "", false
+	}
+	if dec.More() {
+		return "", false
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return "", false
+	}
+	return b.String(), true
+}
+
+func writeSkeleton(dec *json.Decoder, b *strings.Builder, depth int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			return writeObjectSkeleton(dec, b, depth)
+		}
+		return writeArraySkeleton(dec, b, depth)
+	case string:
+		b.WriteString("string")
+	case json.Number:
+		b.WriteString("number")
+	case bool:
+		b.WriteString("bool")
+	case nil:
+		b.WriteString("null")
+	}
+	return nil
+}
+
+func writeObjectSkeleton(dec *json.Decoder, b *strings.Builder, depth int) error {
+	if depth >= skeletonMaxDepth {
+		b.WriteString("{…}")
+		return skipRest(dec, b)
+	}
+	b.WriteString("{")
+	for i := 0; dec.More(); i++ {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(b, "%q: ", key)
+		if err := writeSkeleton(dec, b, depth+1); err != nil {
+			return err
+		}
+	}
+	b.WriteString("}")
+	_, err := dec.Token() // closing '}'
+	return err
+}
+
+func writeArraySkeleton(dec *json.Decoder, b *strings.Builder, depth int) error {
+	if depth >= skeletonMaxDepth {
+		b.WriteString("[…]")
+		return skipRest(dec, b)
+	}
+	count := 0
+	var first strings.Builder
+	for dec.More() {
+		// Only the first element is shown; the rest are parsed and dropped
+		target := &first
+		if count > 0 {
+			target = &strings.Builder{}
+		}
+		if err := writeSkeleton(dec, target, depth+1); err != nil {
+			return err
+		}
+		count++
+	}
+	if _, err := dec.Token(); err != nil { // closing ']'
+		return err
+	}
+	switch count {
+	case 0:
+		b.WriteString("[]")
+	case 1:
+		fmt.Fprintf(b, "[%s]", first.String())
+	default:
+		fmt.Fprintf(b, "[%s] (%d items)", first.String(), count)
+	}
+	return nil
+}
+
+// skipRest consumes the remainder of an already-opened object or array
+func skipRest(dec *json.Decoder, b *strings.Builder) error {
+	discard := &strings.Builder{}
+	for dec.More() {
+		if err := writeSkeleton(dec, discard, skeletonMaxDepth); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
+
 // RequestOutput represents a request formatted for output
 type RequestOutput struct {
 	ID               string          `json:"id"`
@@ -88,6 +294,13 @@ type RequestOutput struct {
 	Headers          store.HeaderMap `json:"headers,omitempty"`
 	Body             string          `json:"body,omitempty"`
 	Response         *ResponseOutput `json:"response,omitempty"`
+
+	DurationMs    float64        `json:"duration_ms,omitempty"`
+	RequestBytes  int64          `json:"request_bytes,omitempty"`
+	ResponseBytes int64          `json:"response_bytes,omitempty"`
+	RemoteIP      string         `json:"remote_ip,omitempty"`
+	Protocol      string         `json:"protocol,omitempty"`
+	TLS           *store.TLSInfo `json:"tls,omitempty"`
 }
 
 // ResponseOutput represents a response formatted for output
@@ -95,10 +308,15 @@ type ResponseOutput struct {
 	Status  int             `json:"status"`
 	Headers store.HeaderMap `json:"headers,omitempty"`
 	Body    string          `json:"body,omitempty"`
+
+	// Streaming chunks; compact and meta output only carry the count
+	Events     []store.StreamEvent `json:"events,omitempty"`
+	EventCount int                 `json:"event_count,omitempty"`
 }
 
-// FormatRequest formats a request for the specified output mode
-func FormatRequest(req *store.Request, mode store.OutputMode) RequestOutput {
+// FormatRequest formats a request for the specified output mode.
+// cfg controls how compact mode shortens response bodies.
+func FormatRequest(req *store.Request, mode store.OutputMode, cfg store.TruncateConfig) RequestOutput {
 	out := RequestOutput{
 		ID:               req.ID,
 		OriginalID:       req.OriginalID,
@@ -112,12 +330,19 @@ func FormatRequest(req *store.Request, mode store.OutputMode) RequestOutput {
 		Path:             req.Path,
 		Headers:          req.Headers,
 		Body:             req.Body,
+		DurationMs:       req.DurationMs,
+		RequestBytes:     req.RequestBytes,
+		ResponseBytes:    req.ResponseBytes,
+		RemoteIP:         req.RemoteIP,
+		Protocol:         req.Protocol,
+		TLS:              req.TLS,
 	}
 
 	if req.Response != nil {
 		respOut := &ResponseOutput{
-			Status:  req.Response.Status,
-			Headers: req.Response.Headers,
+			Status:     req.Response.Status,
+			Headers:    req.Response.Headers,
+			EventCount: len(req.Response.Events),
 		}
 
 		switch mode {
@@ -128,14 +353,16 @@ func FormatRequest(req *store.Request, mode store.OutputMode) RequestOutput {
 		case store.OutputFull:
 			// Full body
 			respOut.Body = req.Response.Body
+			respOut.Events = req.Response.Events
 
 		case store.OutputCompact:
 			// Truncated body
 			contentType := store.HeaderFirst(req.Response.Headers, "content-type")
-			respOut.Body, _ = TruncateBody(req.Response.Body, contentType, store.DefaultTruncateConfig())
+			respOut.Body, _ = TruncateBody(req.Response.Body, contentType, cfg)
 
 		default:
 			respOut.Body = req.Response.Body
+			respOut.Events = req.Response.Events
 		}
 
 		out.Response = respOut
@@ -145,10 +372,10 @@ func FormatRequest(req *store.Request, mode store.OutputMode) RequestOutput {
 }
 
 // FormatRequests formats multiple requests
-func FormatRequests(reqs []store.Request, mode store.OutputMode) []RequestOutput {
+func FormatRequests(reqs []store.Request, mode store.OutputMode, cfg store.TruncateConfig) []RequestOutput {
 	result := make([]RequestOutput, len(reqs))
 	for i, req := range reqs {
-		result[i] = FormatRequest(&req, mode)
+		result[i] = FormatRequest(&req, mode, cfg)
 	}
 	return result
 }
@@ -0,0 +1,66 @@
+package output
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+
+	"github.com/bytedance/sonic"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// NDJSONWriter writes one compact JSON document per line
+type NDJSONWriter struct {
+	w *bufio.Writer
+}
+
+// NewNDJSONWriter returns a buffered NDJSON writer; call Flush when done
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{w: bufio.NewWriter(w)}
+}
+
+// Write encodes v on a single line
+func (n *NDJSONWriter) Write(v interface{}) error {
+	data, err := sonic.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := n.w.Write(data); err != nil {
+		return err
+	}
+	return n.w.WriteByte('\n')
+}
+
+// Flush writes any buffered lines
+func (n *NDJSONWriter) Flush() error {
+	return n.w.Flush()
+}
+
+// RequestCSVHeader is the column row for RequestCSVRow
+var RequestCSVHeader = []string{
+	"id", "method", "url", "domain", "path", "status",
+	"resource_type", "content_type", "size", "timestamp", "page_url",
+}
+
+// RequestCSVRow flattens a request into RequestCSVHeader columns (no bodies)
+func RequestCSVRow(req *store.Request) []string {
+	status, contentType, size := "", "", "0"
+	if req.Response != nil {
+		status = strconv.Itoa(req.Response.Status)
+		contentType = store.HeaderFirst(req.Response.Headers, "content-type")
+		size = strconv.Itoa(len(req.Response.Body))
+	}
+	return []string{
+		req.ID,
+		req.Method,
+		SanitizeText(req.URL),
+		req.Domain,
+		SanitizeText(req.Path),
+		status,
+		req.ResourceType,
+		contentType,
+		size,
+		strconv.FormatInt(req.Timestamp, 10),
+		SanitizeText(req.PageURL),
+	}
+}
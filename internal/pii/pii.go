@@ -0,0 +1,137 @@
+// Package pii finds personal data in text: email addresses, phone numbers,
+// national ID numbers and geographic coordinates. Patterns favour
+// precision: phone numbers need an international prefix or a formatted
+// national layout, coordinates need a lat/lng key or a decimal pair in
+// range.
+package pii
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kinds of personal data
+const (
+	KindEmail       = "email"
+	KindPhone       = "phone"
+	KindSSN         = "ssn"     // US Social Security number
+	KindNINO        = "nino"    // UK National Insurance number
+	KindCPF         = "cpf"     // Brazilian CPF
+	KindAadhaar     = "aadhaar" // Indian Aadhaar number
+	KindCoordinates = "coordinates"
+)
+
+// Kinds lists every kind in report order
+var Kinds = []string{KindEmail, KindPhone, KindSSN, KindNINO, KindCPF, KindAadhaar, KindCoordinates}
+
+// Match is one piece of personal data
+type Match struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"` // Normalized: lowercase email, phone digits with +, "lat,lng"
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]{1,64}@[A-Za-z0-9.-]+\.[A-Za-z]{2,24}`)
+	// Asset names like logo@2x.png look like addresses
+	emailAssetSuffix = regexp.MustCompile(`(?i)\.(png|jpe?g|gif|svg|webp|css|js)$`)
+
+	phonePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\+\d{1,3}[\s.-]?\(?\d{1,4}\)?(?:[\s.-]?\d{2,4}){2,4}`),
+		regexp.MustCompile(`\(\d{3}\)\s?\d{3}[\s.-]\d{4}`),
+	}
+
+	ssnPattern     = regexp.MustCompile(`\b(\d{3})-(\d{2})-(\d{4})\b`)
+	ninoPattern    = regexp.MustCompile(`\b[A-CEGHJ-PR-TW-Z][A-CEGHJ-NPR-TW-Z] ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`)
+	cpfPattern     = regexp.MustCompile(`\b\d{3}\.\d{3}\.\d{3}-\d{2}\b`)
+	aadhaarPattern = regexp.MustCompile(`\b[2-9]\d{3} \d{4} \d{4}\b`)
+
+	latLngKeys = regexp.MustCompile(`(?i)"(?:lat|latitude)"\s*:\s*"?(-?\d{1,2}\.\d{3,})"?\s*,\s*"(?:lng|lon|long|longitude)"\s*:\s*"?(-?\d{1,3}\.\d{3,})`)
+	latLngPair = regexp.MustCompile(`(-?\d{1,2}\.\d{4,}),\s?(-?\d{1,3}\.\d{4,})`)
+)
+
+// Scan returns the distinct personal data found in text, in order of
+// appearance within each kind
+func Scan(text string) []Match {
+	if text == "" {
+		return nil
+	}
+	var matches []Match
+	seen := make(map[string]bool)
+	add := func(kind, value string) {
+		if !seen[kind+"\x00"+value] {
+			seen[kind+"\x00"+value] = true
+			matches = append(matches, Match{Kind: kind, Value: value})
+		}
+	}
+
+	if strings.Contains(text, "@") {
+		for _, m := range emailPattern.FindAllString(text, -1) {
+			if !emailAssetSuffix.MatchString(m) {
+				add(KindEmail, strings.ToLower(m))
+			}
+		}
+	}
+	for _, p := range phonePatterns {
+		for _, m := range p.FindAllString(text, -1) {
+			if digits := phoneDigits(m); digits != "" {
+				add(KindPhone, digits)
+			}
+		}
+	}
+	for _, m := range ssnPattern.FindAllStringSubmatch(text, -1) {
+		if m[1] != "000" && m[1] != "666" && m[1][0] != '9' && m[2] != "00" && m[3] != "0000" {
+			add(KindSSN, m[0])
+		}
+	}
+	for _, m := range ninoPattern.FindAllString(text, -1) {
+		add(KindNINO, strings.ReplaceAll(m, " ", ""))
+	}
+	for _, m := range cpfPattern.FindAllString(text, -1) {
+		add(KindCPF, m)
+	}
+	for _, m := range aadhaarPattern.FindAllString(text, -1) {
+		add(KindAadhaar, m)
+	}
+	for _, m := range latLngKeys.FindAllStringSubmatch(text, -1) {
+		if coords, ok := coordinates(m[1], m[2]); ok {
+			add(KindCoordinates, coords)
+		}
+	}
+	for _, m := range latLngPair.FindAllStringSubmatch(text, -1) {
+		if coords, ok := coordinates(m[1], m[2]); ok {
+			add(KindCoordinates, coords)
+		}
+	}
+	return matches
+}
+
+// phoneDigits normalizes a phone match to its digits (with the leading +),
+// rejecting runs too short or long for a phone number
+func phoneDigits(s string) string {
+	var b strings.Builder
+	if strings.HasPrefix(s, "+") {
+		b.WriteByte('+')
+	}
+	n := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			n++
+		}
+	}
+	if n < 8 || n > 15 {
+		return ""
+	}
+	return b.String()
+}
+
+// coordinates validates a latitude/longitude pair; 0,0 is a placeholder
+func coordinates(lat, lng string) (string, bool) {
+	la, err1 := strconv.ParseFloat(lat, 64)
+	lo, err2 := strconv.ParseFloat(lng, 64)
+	if err1 != nil || err2 != nil || la < -90 || la > 90 || lo < -180 || lo > 180 || (la == 0 && lo == 0) {
+		return "", false
+	}
+	return lat + "," + lng, true
+}
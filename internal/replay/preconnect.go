@@ -0,0 +1,134 @@
+package replay
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrProxied is returned by Preconnect when requests go through a proxy,
+// whose connections can't be prepared ahead
+var ErrProxied = errors.New("requests go through a proxy")
+
+// preconnectTimeout bounds opening one connection ahead
+const preconnectTimeout = 10 * time.Second
+
+// Preconnect opens n connections to rawURL's host on transport, through DNS,
+// TCP and TLS, so the next n requests only have to write themselves. Each
+// request takes its own connection: transport is switched to HTTP/1.1 and
+// keeps up to n idle connections. On error, no connection is kept and
+// transport dials as usual.
+func Preconnect(ctx context.Context, transport *http.Transport, rawURL string, n int) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme: %s", u.Scheme)
+	}
+	if transport.Proxy != nil {
+		if proxy, err := transport.Proxy(&http.Request{URL: u}); err != nil || proxy != nil {
+			return ErrProxied
+		}
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	addr := net.JoinHostPort(u.Hostname(), port)
+
+	var tlsConfig *tls.Config
+	if u.Scheme == "https" {
+		tlsConfig = &tls.Config{}
+		if transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig.Clone()
+		}
+		tlsConfig.ServerName = u.Hostname()
+		tlsConfig.NextProtos = []string{"http/1.1"}
+	}
+
+	conns := make([]net.Conn, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			conns[i], errs[i] = preconnect(ctx, addr, tlsConfig)
+		}(i)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		for _, conn := range conns {
+			if conn != nil {
+				conn.Close()
+			}
+		}
+		return err
+	}
+
+	pool := make(chan net.Conn, n)
+	for _, conn := range conns {
+		pool <- conn
+	}
+	take := func(target string) net.Conn {
+		if target != addr {
+			return nil
+		}
+		select {
+		case conn := <-pool:
+			return conn
+		default:
+			return nil
+		}
+	}
+	if tlsConfig == nil {
+		dialer := &net.Dialer{Timeout: preconnectTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = func(ctx context.Context, network, target string) (net.Conn, error) {
+			if conn := take(target); conn != nil {
+				return conn, nil
+			}
+			return dialer.DialContext(ctx, network, target)
+		}
+	} else {
+		transport.DialTLSContext = func(ctx context.Context, network, target string) (net.Conn, error) {
+			if conn := take(target); conn != nil {
+				return conn, nil
+			}
+			return preconnect(ctx, target, tlsConfig)
+		}
+	}
+	transport.ForceAttemptHTTP2 = false
+	transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	transport.MaxIdleConnsPerHost = n
+	if transport.MaxIdleConns < n {
+		transport.MaxIdleConns = n
+	}
+	return nil
+}
+
+// preconnect dials addr, completing the TLS handshake when tlsConfig is set
+func preconnect(ctx context.Context, addr string, tlsConfig *tls.Config) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, preconnectTimeout)
+	defer cancel()
+	dialer := &net.Dialer{KeepAlive: 30 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil || tlsConfig == nil {
+		return conn, err
+	}
+	tlsConn := tls.Client(conn, tlsConfig.Clone())
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
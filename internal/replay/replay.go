@@ -0,0 +1,194 @@
+// Package replay re-sends captured requests over HTTP.
+// It is the shared engine behind commands that execute requests
+// (rep curl --exec and friends) instead of only printing them.
+package replay
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"time"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// DefaultTimeout bounds a single replayed request
+const DefaultTimeout = 30 * time.Second
+
+// MaxBodySize caps how much of a response body is kept in memory
+const MaxBodySize = 10 * 1024 * 1024
+
+// transportHeaders are set by the HTTP client and must not be copied verbatim
+var transportHeaders = map[string]bool{
+	"host":              true,
+	"content-length":    true,
+	"connection":        true,
+	"transfer-encoding": true,
+}
+
+// Options controls how a request is sent
+type Options struct {
+	Timeout         time.Duration // 0 = DefaultTimeout
+	FollowRedirects bool          // Follow 3xx responses (default: return them as-is)
+	Insecure        bool          // Skip TLS certificate verification
+	BodyWriter      io.Writer     // Optional: receives the response body as it streams in
+	// OnHeaders is called once the status line and headers arrive, before the
+	// body is read. Useful for printing headers ahead of a streamed body.
+	OnHeaders func(status int, proto string, headers store.HeaderMap)
+	// Transport is shared between requests (see Preconnect); nil builds a
+	// new one for each request
+	Transport *http.Transport
+	// Trace receives the connection and write events of the request
+	Trace *httptrace.ClientTrace
+}
+
+// Result is the outcome of a replayed request
+type Result struct {
+	Status    int             `json:"status"`
+	Proto     string          `json:"proto"`
+	Headers   store.HeaderMap `json:"headers,omitempty"`
+	Body      string          `json:"body,omitempty"`
+	Size      int64           `json:"size"`
+	Truncated bool            `json:"truncated,omitempty"` // Body exceeded MaxBodySize
+	Duration  time.Duration   `json:"-"`
+	Timestamp int64           `json:"timestamp"` // Unix millis when the request was sent
+}
+
+// DurationMs returns the round-trip time in milliseconds
+func (r *Result) DurationMs() int64 {
+	return r.Duration.Milliseconds()
+}
+
+// Response converts the result to a store.Response
+func (r *Result) Response() *store.Response {
+	return &store.Response{
+		Status:  r.Status,
+		Headers: r.Headers,
+		Body:    r.Body,
+	}
+}
+
+// Send executes req and returns the response. Header values are sent as-is;
+// callers are responsible for any variable substitution.
+func Send(ctx context.Context, req *store.Request, opts Options) (*Result, error) {
+	if req == nil {
+		return nil, errors.New("nil request")
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if opts.Trace != nil {
+		ctx = httptrace.WithClientTrace(ctx, opts.Trace)
+	}
+
+	var body io.Reader
+	if req.Body != "" {
+		body = strings.NewReader(req.Body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	for key, values := range req.Headers {
+		if transportHeaders[strings.ToLower(key)] {
+			continue
+		}
+		for _, v := range values {
+			httpReq.Header.Add(key, v)
+		}
+	}
+	// Preserve a captured Host header (virtual host testing)
+	if host := store.HeaderFirst(req.Headers, "host"); host != "" {
+		httpReq.Host = host
+	}
+
+	client := newClient(opts)
+	start := time.Now()
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if opts.OnHeaders != nil {
+		opts.OnHeaders(resp.StatusCode, resp.Proto, store.HeaderMap(resp.Header))
+	}
+
+	var buf strings.Builder
+	var dst io.Writer = &limitedWriter{w: &buf, remaining: MaxBodySize}
+	if opts.BodyWriter != nil {
+		dst = io.MultiWriter(dst, opts.BodyWriter)
+	}
+	size, err := io.Copy(dst, resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return &Result{
+		Status:    resp.StatusCode,
+		Proto:     resp.Proto,
+		Headers:   store.HeaderMap(resp.Header),
+		Body:      buf.String(),
+		Size:      size,
+		Truncated: size > MaxBodySize,
+		Duration:  time.Since(start),
+		Timestamp: start.UnixMilli(),
+	}, nil
+}
+
+// NewTransport returns the transport Send uses for opts
+func NewTransport(opts Options) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Keep bodies byte-identical to what the server sent
+	transport.DisableCompression = true
+	if opts.Insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return transport
+}
+
+func newClient(opts Options) *http.Client {
+	transport := opts.Transport
+	if transport == nil {
+		transport = NewTransport(opts)
+	}
+
+	client := &http.Client{Transport: transport}
+	if !opts.FollowRedirects {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	return client
+}
+
+// limitedWriter keeps at most remaining bytes and silently drops the rest
+type limitedWriter struct {
+	w         io.Writer
+	remaining int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if l.remaining <= 0 {
+		return n, nil
+	}
+	if len(p) > l.remaining {
+		p = p[:l.remaining]
+	}
+	written, err := l.w.Write(p)
+	l.remaining -= written
+	if err != nil {
+		return written, err
+	}
+	return n, nil
+}
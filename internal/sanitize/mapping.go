@@ -0,0 +1,94 @@
+package sanitize
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// MappingFormat identifies a placeholder mapping file
+const MappingFormat = "rep-mapping"
+
+// placeholderPattern matches the placeholders a Sanitizer issues
+var placeholderPattern = regexp.MustCompile(`\{\{(?:TOKEN|COOKIE|SECRET|EMAIL|USER)_\d+\}\}`)
+
+// Mapping is the placeholder -> original value table of one sanitized
+// export. It holds live secrets: it is written 0600 and never leaves the
+// machine that made the export.
+type Mapping struct {
+	Format       string            `json:"format"` // Always MappingFormat
+	SessionID    string            `json:"session_id,omitempty"`
+	CreatedAt    string            `json:"created_at"`
+	Placeholders map[string]string `json:"placeholders"`
+}
+
+// Mapping returns the placeholder table of everything s replaced
+func (s *Sanitizer) Mapping(sessionID string) *Mapping {
+	m := &Mapping{
+		Format:       MappingFormat,
+		SessionID:    sessionID,
+		CreatedAt:    time.Now().Format(time.RFC3339),
+		Placeholders: make(map[string]string, len(s.placeholders)),
+	}
+	for value, p := range s.placeholders {
+		m.Placeholders[p] = value
+	}
+	return m
+}
+
+// WriteMapping writes m to path, readable by the owner only
+func WriteMapping(path string, m *Mapping) error {
+	data, err := sonic.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of a file it overwrites
+	return os.Chmod(path, 0600)
+}
+
+// LoadMapping reads a mapping file
+func LoadMapping(path string) (*Mapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Mapping
+	if err := sonic.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse mapping %s: %w", path, err)
+	}
+	if m.Format != MappingFormat {
+		return nil, fmt.Errorf("%s is not a rep mapping file", path)
+	}
+	return &m, nil
+}
+
+// HasPlaceholders reports whether text contains sanitizer placeholders
+func HasPlaceholders(text string) bool {
+	return placeholderPattern.MatchString(text)
+}
+
+// Restore substitutes the original values back into text. With escape,
+// values are query-escaped (URLs and form bodies). It returns the
+// placeholders the mapping does not know.
+func (m *Mapping) Restore(text string, escape bool) (string, []string) {
+	var unknown []string
+	restored := placeholderPattern.ReplaceAllStringFunc(text, func(p string) string {
+		value, ok := m.Placeholders[p]
+		if !ok {
+			unknown = append(unknown, p)
+			return p
+		}
+		if escape {
+			return url.QueryEscape(value)
+		}
+		return value
+	})
+	return restored, unknown
+}
@@ -0,0 +1,368 @@
+// Package sanitize replaces credentials and personal data in captured
+// requests with stable placeholders ({{TOKEN_1}}, {{EMAIL_2}}, ...), so a
+// capture can be shared or handed to a model without live secrets. The same
+// value always gets the same placeholder across every request, header, URL
+// and body, which keeps the traffic analyzable: a token issued by one
+// response is still visibly the one sent by later requests.
+package sanitize
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/repplus/rep-cli/internal/pii"
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// Placeholder categories
+const (
+	Token  = "TOKEN"  // Authorization values, API keys, JWTs, OAuth codes and tokens
+	Cookie = "COOKIE" // Cookie values
+	Secret = "SECRET" // Passwords and client secrets
+	Email  = "EMAIL"
+	User   = "USER" // Usernames and non-numeric user identifiers
+)
+
+// minGlobalLength is the shortest value replaced everywhere it appears;
+// shorter ones are only replaced where they were found
+const minGlobalLength = 6
+
+var (
+	secretHeader = regexp.MustCompile(`(?i)(authorization|token|api-?key|secret|session|csrf|xsrf|signature|credential)`)
+	// Headers that match secretHeader but carry no secret
+	publicHeaders = []string{"access-control-allow-credentials", "access-control-allow-headers", "access-control-expose-headers"}
+
+	tokenField  = regexp.MustCompile(`(?i)^(access_?token|id_?token|refresh_?token|token|auth_?token|session_?token|jwt|code|api_?key|apikey|access_?key|key|signature|sig|session|sessionid|session_id|sid|csrf_?token|_csrf|xsrf_?token|authenticity_token|state|nonce|code_verifier|assertion|samlresponse)$`)
+	secretField = regexp.MustCompile(`(?i)^(password|passwd|pass|pwd|new_?password|old_?password|current_?password|secret|client_?secret|otp|pin|totp)$`)
+	userField   = regexp.MustCompile(`(?i)^(user_?name|login|user_?id|uid|account_?id|customer_?id|member_?id|owner_?id|sub|handle)$`)
+
+	jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]*`)
+	numeric    = regexp.MustCompile(`^\d+$`)
+)
+
+// Sanitizer assigns placeholders to sensitive values. Collect every
+// request first, then Apply to each: values found in one request are
+// replaced in all of them.
+type Sanitizer struct {
+	placeholders map[string]string // value -> placeholder
+	counts       map[string]int    // category -> placeholders issued
+	global       []string          // Values replaced wherever they appear
+}
+
+// New returns an empty Sanitizer
+func New() *Sanitizer {
+	return &Sanitizer{placeholders: make(map[string]string), counts: make(map[string]int)}
+}
+
+// Counts returns how many distinct values of each category were replaced
+func (s *Sanitizer) Counts() map[string]int {
+	counts := make(map[string]int, len(s.counts))
+	for category, n := range s.counts {
+		counts[category] = n
+	}
+	return counts
+}
+
+// placeholder returns the stable placeholder for value, issuing one on first use
+func (s *Sanitizer) placeholder(category, value string) string {
+	if p, ok := s.placeholders[value]; ok {
+		return p
+	}
+	s.counts[category]++
+	p := fmt.Sprintf("{{%s_%d}}", category, s.counts[category])
+	s.placeholders[value] = p
+	if len(value) >= minGlobalLength || category == Email {
+		s.global = append(s.global, value)
+	}
+	return p
+}
+
+// add registers a sensitive value; numeric user identifiers are kept so
+// object references stay comparable
+func (s *Sanitizer) add(category, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.HasPrefix(value, "{{") || (category == User && (numeric.MatchString(value) || len(value) < 3)) {
+		return
+	}
+	s.placeholder(category, value)
+}
+
+// Collect registers the sensitive values of one request
+func (s *Sanitizer) Collect(req *store.Request) {
+	s.collectHeaders(req.Headers)
+	for _, c := range requestCookies(req.Headers) {
+		s.add(Cookie, c.Value)
+	}
+	s.collectURL(req.URL)
+	s.collectURL(req.PageURL)
+	s.collectURL(req.Initiator)
+	s.collectBody(req.Body)
+	if req.Response != nil {
+		s.collectHeaders(req.Response.Headers)
+		for _, c := range responseCookies(req.Response.Headers) {
+			s.add(Cookie, c.Value)
+		}
+		if location := store.HeaderFirst(req.Response.Headers, "location"); location != "" {
+			s.collectURL(location)
+		}
+		s.collectBody(req.Response.Body)
+		for _, e := range req.Response.Events {
+			s.collectBody(e.Data)
+		}
+	}
+}
+
+func (s *Sanitizer) collectHeaders(headers store.HeaderMap) {
+	for _, name := range sortedKeys(headers) {
+		values := headers[name]
+		lower := strings.ToLower(name)
+		if !secretHeader.MatchString(name) || lower == "set-cookie" || lower == "cookie" {
+			continue
+		}
+		skip := false
+		for _, public := range publicHeaders {
+			skip = skip || lower == public
+		}
+		if skip {
+			continue
+		}
+		for _, v := range values {
+			if scheme, cred, ok := strings.Cut(v, " "); ok && lower == "authorization" && !strings.Contains(scheme, "=") {
+				s.add(Token, cred)
+				continue
+			}
+			s.add(Token, v)
+		}
+	}
+}
+
+func (s *Sanitizer) collectURL(rawURL string) {
+	if rawURL == "" {
+		return
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return
+	}
+	if parsed.User != nil {
+		if password, ok := parsed.User.Password(); ok {
+			s.add(Secret, password)
+		}
+	}
+	s.collectForm(parsed.RawQuery)
+	if strings.Contains(parsed.Fragment, "=") {
+		s.collectForm(parsed.Fragment)
+	}
+	s.collectText(rawURL)
+}
+
+func (s *Sanitizer) collectForm(raw string) {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return
+	}
+	for _, name := range sortedKeys(values) {
+		for _, v := range values[name] {
+			s.collectField(name, v)
+		}
+	}
+}
+
+func (s *Sanitizer) collectField(name, value string) {
+	switch {
+	case secretField.MatchString(name):
+		s.add(Secret, value)
+	case tokenField.MatchString(name) && tokenLike(value):
+		s.add(Token, value)
+	case userField.MatchString(name):
+		s.add(User, value)
+	}
+}
+
+func (s *Sanitizer) collectBody(body string) {
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" {
+		return
+	}
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var v interface{}
+		if sonic.UnmarshalString(trimmed, &v) == nil {
+			s.collectJSON("", v)
+		}
+	} else if !strings.ContainsAny(trimmed, " \n<") && strings.Contains(trimmed, "=") {
+		s.collectForm(trimmed)
+	}
+	s.collectText(body)
+}
+
+func (s *Sanitizer) collectJSON(key string, v interface{}) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for _, k := range sortedKeys(val) {
+			s.collectJSON(k, val[k])
+		}
+	case []interface{}:
+		for _, child := range val {
+			s.collectJSON(key, child)
+		}
+	case string:
+		if key != "" {
+			s.collectField(key, val)
+		}
+	}
+}
+
+// collectText finds JWTs and email addresses in free text
+func (s *Sanitizer) collectText(text string) {
+	for _, m := range jwtPattern.FindAllString(text, -1) {
+		s.add(Token, m)
+	}
+	decoded := text
+	if unescaped, err := url.QueryUnescape(text); err == nil {
+		decoded = unescaped
+	}
+	for _, m := range pii.Scan(decoded) {
+		if m.Kind == pii.KindEmail {
+			s.add(Email, m.Value)
+		}
+	}
+}
+
+// tokenLike tells credentials from the ordinary values generic field
+// names (code, key, state) also carry, such as error codes
+func tokenLike(value string) bool {
+	return !strings.ContainsAny(value, " \t\n") && (len(value) >= 16 || strings.ContainsAny(value, "0123456789"))
+}
+
+// Apply returns a copy of req with every registered value replaced
+func (s *Sanitizer) Apply(req *store.Request) store.Request {
+	out := *req
+	replace := s.replacer()
+	out.URL = replace.Replace(req.URL)
+	out.PageURL = replace.Replace(req.PageURL)
+	out.Initiator = replace.Replace(req.Initiator)
+	out.Body = replace.Replace(req.Body)
+	out.Headers = s.applyHeaders(req.Headers, replace)
+	if req.Response != nil {
+		resp := *req.Response
+		resp.Headers = s.applyHeaders(req.Response.Headers, replace)
+		resp.Body = replace.Replace(req.Response.Body)
+		if len(req.Response.Events) > 0 {
+			resp.Events = make([]store.StreamEvent, len(req.Response.Events))
+			for i, e := range req.Response.Events {
+				resp.Events[i] = e
+				resp.Events[i].Data = replace.Replace(e.Data)
+			}
+		}
+		out.Response = &resp
+	}
+	return out
+}
+
+// replacer substitutes registered values, longest first so a token
+// containing another value is replaced whole; emails are also matched
+// percent-encoded, as form bodies and query strings carry them
+func (s *Sanitizer) replacer() *strings.Replacer {
+	values := append([]string(nil), s.global...)
+	sort.SliceStable(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	var pairs []string
+	for _, v := range values {
+		p := s.placeholders[v]
+		pairs = append(pairs, v, p)
+		if escaped := url.QueryEscape(v); escaped != v {
+			pairs = append(pairs, escaped, p)
+		}
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// applyHeaders replaces header values: cookies value by value, secret
+// headers whole (keeping the Authorization scheme), others by substitution
+func (s *Sanitizer) applyHeaders(headers store.HeaderMap, replace *strings.Replacer) store.HeaderMap {
+	if headers == nil {
+		return nil
+	}
+	out := make(store.HeaderMap, len(headers))
+	for name, values := range headers {
+		lower := strings.ToLower(name)
+		sanitized := make([]string, len(values))
+		for i, v := range values {
+			switch {
+			case lower == "cookie":
+				sanitized[i] = s.applyCookieHeader(v)
+			case lower == "set-cookie":
+				sanitized[i] = s.applySetCookie(v)
+			default:
+				if p, ok := s.placeholders[strings.TrimSpace(v)]; ok {
+					sanitized[i] = p
+				} else if scheme, cred, ok := strings.Cut(v, " "); ok && lower == "authorization" {
+					if p, ok := s.placeholders[strings.TrimSpace(cred)]; ok {
+						sanitized[i] = scheme + " " + p
+						continue
+					}
+					sanitized[i] = replace.Replace(v)
+				} else {
+					sanitized[i] = replace.Replace(v)
+				}
+			}
+		}
+		out[name] = sanitized
+	}
+	return out
+}
+
+func (s *Sanitizer) applyCookieHeader(header string) string {
+	parts := strings.Split(header, ";")
+	for i, part := range parts {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		if p, found := s.placeholders[strings.TrimSpace(value)]; found {
+			value = p
+		}
+		parts[i] = name + "=" + value
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (s *Sanitizer) applySetCookie(header string) string {
+	first, attrs, _ := strings.Cut(header, ";")
+	name, value, ok := strings.Cut(first, "=")
+	if !ok {
+		return header
+	}
+	if p, found := s.placeholders[strings.TrimSpace(value)]; found {
+		value = p
+	}
+	if attrs != "" {
+		return name + "=" + value + ";" + attrs
+	}
+	return name + "=" + value
+}
+
+func requestCookies(headers store.HeaderMap) []*http.Cookie {
+	r := http.Request{Header: http.Header{"Cookie": store.HeaderValues(headers, "cookie")}}
+	return r.Cookies()
+}
+
+func responseCookies(headers store.HeaderMap) []*http.Cookie {
+	r := http.Response{Header: http.Header{"Set-Cookie": store.HeaderValues(headers, "set-cookie")}}
+	return r.Cookies()
+}
+
+// sortedKeys returns map keys in order, so placeholders are numbered the
+// same way on every run
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
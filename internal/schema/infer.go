@@ -0,0 +1,220 @@
+package schema
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/repplus/rep-cli/internal/jsonpath"
+)
+
+const (
+	enumMaxValues = 10 // Distinct strings an enum may have
+	enumMaxLength = 40 // Longer strings are free text, not enum members
+)
+
+// Inferrer merges observed JSON documents into one schema: a field is
+// required when every object at its place had it, strings seen repeatedly
+// with few distinct values become enums.
+type Inferrer struct {
+	root    *node
+	samples int
+}
+
+// node is what was observed at one place in the documents
+type node struct {
+	count   int            // Values seen
+	types   map[string]int // JSON Schema type -> values
+	objects int            // Object values
+	props   map[string]*node
+	order   []string // Property names by first appearance
+	items   *node    // Array elements
+	strs    map[string]int
+	freeStr bool // A string too long or too varied for an enum
+}
+
+func newNode() *node {
+	return &node{types: map[string]int{}}
+}
+
+// NewInferrer returns an empty Inferrer
+func NewInferrer() *Inferrer {
+	return &Inferrer{root: newNode()}
+}
+
+// Add merges a document decoded with json.Decoder.UseNumber
+func (in *Inferrer) Add(doc interface{}) {
+	in.samples++
+	in.root.add(doc)
+}
+
+// Samples is the number of documents added
+func (in *Inferrer) Samples() int { return in.samples }
+
+func (n *node) add(v interface{}) {
+	n.count++
+	switch t := v.(type) {
+	case map[string]interface{}:
+		n.types["object"]++
+		n.objects++
+		if n.props == nil {
+			n.props = map[string]*node{}
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child, ok := n.props[k]
+			if !ok {
+				child = newNode()
+				n.props[k] = child
+				n.order = append(n.order, k)
+			}
+			child.add(t[k])
+		}
+	case []interface{}:
+		n.types["array"]++
+		for _, e := range t {
+			if n.items == nil {
+				n.items = newNode()
+			}
+			n.items.add(e)
+		}
+	case string:
+		n.types["string"]++
+		if n.strs == nil {
+			n.strs = map[string]int{}
+		}
+		if len(t) > enumMaxLength {
+			n.freeStr = true
+		} else if !n.freeStr {
+			n.strs[t]++
+			if len(n.strs) > enumMaxValues {
+				n.freeStr = true
+			}
+		}
+	case json.Number:
+		if strings.ContainsAny(string(t), ".eE") {
+			n.types["number"]++
+		} else {
+			n.types["integer"]++
+		}
+	case float64:
+		n.types["number"]++
+	case bool:
+		n.types["boolean"]++
+	default:
+		n.types["null"]++
+	}
+}
+
+// typeNames returns the observed types in a stable order; integer folds
+// into number when both were seen
+func (n *node) typeNames() []string {
+	var names []string
+	for _, t := range []string{"object", "array", "string", "integer", "number", "boolean", "null"} {
+		if n.types[t] == 0 || (t == "integer" && n.types["number"] > 0) {
+			continue
+		}
+		names = append(names, t)
+	}
+	return names
+}
+
+// enum returns the string values when they look like an enum: few distinct
+// values, each seen on average at least twice
+func (n *node) enum() []string {
+	if n.freeStr || len(n.strs) == 0 || n.types["string"] < 2*len(n.strs) {
+		return nil
+	}
+	values := make([]string, 0, len(n.strs))
+	for s := range n.strs {
+		values = append(values, s)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// required lists the properties every observed object had
+func (n *node) required() []string {
+	var names []string
+	for _, k := range n.order {
+		if n.props[k].count == n.objects {
+			names = append(names, k)
+		}
+	}
+	return names
+}
+
+// Schema returns the inferred schema of the added documents
+func (in *Inferrer) Schema() Schema {
+	s := in.root.schema()
+	s["$schema"] = Draft
+	return s
+}
+
+func (n *node) schema() Schema {
+	s := Schema{}
+	types := n.typeNames()
+	switch len(types) {
+	case 0:
+		return s
+	case 1:
+		s["type"] = types[0]
+	default:
+		s["type"] = types
+	}
+	if n.props != nil {
+		properties := Schema{}
+		for _, k := range n.order {
+			properties[k] = n.props[k].schema()
+		}
+		s["properties"] = properties
+		if req := n.required(); len(req) > 0 {
+			s["required"] = req
+		}
+	}
+	if n.items != nil {
+		s["items"] = n.items.schema()
+	}
+	if values := n.enum(); values != nil {
+		enum := make([]interface{}, 0, len(values)+1)
+		for _, v := range values {
+			enum = append(enum, v)
+		}
+		if n.types["null"] > 0 {
+			enum = append(enum, nil)
+		}
+		s["enum"] = enum
+	}
+	return s
+}
+
+// Field is one place in the inferred documents, for display
+type Field struct {
+	Path     string   `json:"path"` // jq-like, arrays as []
+	Types    []string `json:"types"`
+	Optional bool     `json:"optional,omitempty"` // Missing from some objects
+	Enum     []string `json:"enum,omitempty"`
+}
+
+// Fields lists every place in the inferred documents, parents first
+func (in *Inferrer) Fields() []Field {
+	var fields []Field
+	in.root.fields(".", false, &fields)
+	return fields
+}
+
+func (n *node) fields(path string, optional bool, out *[]Field) {
+	*out = append(*out, Field{Path: path, Types: n.typeNames(), Optional: optional, Enum: n.enum()})
+	prefix := strings.TrimSuffix(path, ".")
+	for _, k := range n.order {
+		child := n.props[k]
+		child.fields(prefix+jsonpath.KeyStep(k), child.count < n.objects, out)
+	}
+	if n.items != nil {
+		n.items.fields(prefix+"[]", false, out)
+	}
+}
@@ -0,0 +1,101 @@
+// Package schema derives JSON Schemas (draft 2020-12) from the Go types
+// commands encode, so the published schema of an output can't drift from
+// the code that writes it. Inferrer goes the other way, inferring a schema
+// from observed JSON bodies.
+package schema
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Draft is the JSON Schema dialect of generated schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document or subschema
+type Schema = map[string]interface{}
+
+// For returns the schema of values of type t as encoding/json writes them.
+// Fields without omitempty are required; pointers and slices may be null.
+func For(t reflect.Type) Schema {
+	return forType(t, map[reflect.Type]bool{})
+}
+
+func forType(t reflect.Type, seen map[reflect.Type]bool) Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(forType(t.Elem(), seen))
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return nullable(Schema{"type": "array", "items": forType(t.Elem(), seen)})
+	case reflect.Map:
+		return nullable(Schema{"type": "object", "additionalProperties": forType(t.Elem(), seen)})
+	case reflect.Struct:
+		if seen[t] {
+			// Recursive type: stop at an unconstrained object
+			return Schema{"type": "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		return structSchema(t, seen)
+	}
+	// interface{} and anything else: any value
+	return Schema{}
+}
+
+func structSchema(t reflect.Type, seen map[reflect.Type]bool) Schema {
+	properties := Schema{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			// Embedded struct fields are promoted
+			embedded := structSchema(field.Type, seen)
+			for k, v := range embedded["properties"].(Schema) {
+				properties[k] = v
+			}
+			if req, ok := embedded["required"].([]string); ok {
+				required = append(required, req...)
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = forType(field.Type, seen)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	s := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// nullable lets a schema also accept null (nil slices, maps and pointers)
+func nullable(s Schema) Schema {
+	if typ, ok := s["type"].(string); ok {
+		s["type"] = []string{typ, "null"}
+	}
+	return s
+}
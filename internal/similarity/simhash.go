@@ -0,0 +1,59 @@
+// Package similarity measures how alike two HTTP responses are, so commands
+// can tell a response that changed behavior from one that only changed a
+// timestamp or a nonce.
+package similarity
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// Simhash is a 64-bit fingerprint of a text's word shingles: near-identical
+// texts get fingerprints a few bits apart
+func Simhash(text string) uint64 {
+	tokens := Tokens(text)
+	if len(tokens) == 0 {
+		return 0
+	}
+	var weights [64]int
+	add := func(feature string) {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(feature))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	// Pairs of words keep some order; single words carry short texts
+	for i, t := range tokens {
+		add(t)
+		if i > 0 {
+			add(tokens[i-1] + " " + t)
+		}
+	}
+	var fingerprint uint64
+	for bit, w := range weights {
+		if w > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fingerprint
+}
+
+// Distance is the number of bits two fingerprints differ in (0 to 64)
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Tokens splits text into lowercase words of letters and digits
+func Tokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,170 @@
+package similarity
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+)
+
+// DefaultThreshold is the score under which a response counts as different
+// from its baseline
+const DefaultThreshold = 0.9
+
+// structureListed caps the paths a StructureDiff lists per kind
+const structureListed = 20
+
+// Comparison measures how a response differs from a baseline
+type Comparison struct {
+	StatusChanged bool           `json:"status_changed"`
+	LengthDelta   int            `json:"length_delta"` // Bytes, response minus baseline
+	Jaccard       float64        `json:"jaccard"`      // Word sets, 0 to 1
+	Structure     *StructureDiff `json:"structure,omitempty"`
+	Score         float64        `json:"score"` // Body likeness, 0 (unrelated) to 1 (same)
+}
+
+// StructureDiff is the difference of two JSON bodies' shapes: key paths
+// ($.data.items[].id) present in one only, or holding another type
+type StructureDiff struct {
+	Similarity float64  `json:"similarity"` // Shared paths over all paths
+	Added      []string `json:"added,omitempty"`
+	Removed    []string `json:"removed,omitempty"`
+	Retyped    []string `json:"retyped,omitempty"`
+}
+
+// Compare scores body against baseBody. The score weighs the word Jaccard
+// twice and the length ratio once; when both bodies are JSON, the
+// structural similarity counts like the Jaccard.
+func Compare(baseStatus int, baseBody string, status int, body string) Comparison {
+	c := Comparison{
+		StatusChanged: status != baseStatus,
+		LengthDelta:   len(body) - len(baseBody),
+		Jaccard:       Jaccard(baseBody, body),
+	}
+	lengths := 1.0
+	if longest := max(len(body), len(baseBody)); longest > 0 {
+		lengths = float64(min(len(body), len(baseBody))) / float64(longest)
+	}
+	score := (2*c.Jaccard + lengths) / 3
+	if c.Structure = Structure(baseBody, body); c.Structure != nil {
+		score = (2*c.Jaccard + 2*c.Structure.Similarity + lengths) / 5
+	}
+	c.Score = round(score)
+	return c
+}
+
+// Different reports whether the response changed behavior: another status,
+// or a body scoring under threshold
+func (c Comparison) Different(threshold float64) bool {
+	return c.StatusChanged || c.Score < threshold
+}
+
+// Jaccard is the overlap of the word sets of two texts, 1 when both are
+// empty
+func Jaccard(a, b string) float64 {
+	setA, setB := tokenSet(a), tokenSet(b)
+	if len(setA) == 0 && len(setB) == 0 {
+		return 1
+	}
+	shared := 0
+	for t := range setA {
+		if setB[t] {
+			shared++
+		}
+	}
+	return round(float64(shared) / float64(len(setA)+len(setB)-shared))
+}
+
+func tokenSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, t := range Tokens(text) {
+		set[t] = true
+	}
+	return set
+}
+
+// Structure compares the key paths and value types of two JSON documents,
+// nil when either isn't JSON
+func Structure(a, b string) *StructureDiff {
+	shapeA, okA := jsonShape(a)
+	shapeB, okB := jsonShape(b)
+	if !okA || !okB {
+		return nil
+	}
+	d := &StructureDiff{}
+	shared := 0
+	for path, kind := range shapeA {
+		other, ok := shapeB[path]
+		switch {
+		case !ok:
+			d.Removed = append(d.Removed, path)
+		case other != kind:
+			d.Retyped = append(d.Retyped, path)
+		default:
+			shared++
+		}
+	}
+	for path := range shapeB {
+		if _, ok := shapeA[path]; !ok {
+			d.Added = append(d.Added, path)
+		}
+	}
+	total := shared + len(d.Added) + len(d.Removed) + len(d.Retyped)
+	d.Similarity = 1
+	if total > 0 {
+		d.Similarity = round(float64(shared) / float64(total))
+	}
+	d.Added, d.Removed, d.Retyped = listed(d.Added), listed(d.Removed), listed(d.Retyped)
+	return d
+}
+
+func listed(paths []string) []string {
+	sort.Strings(paths)
+	if len(paths) > structureListed {
+		paths = paths[:structureListed]
+	}
+	return paths
+}
+
+// jsonShape maps every key path of a JSON document to its value type;
+// array elements share the path "[]"
+func jsonShape(text string) (map[string]string, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" || (text[0] != '{' && text[0] != '[') {
+		return nil, false
+	}
+	var v interface{}
+	if json.Unmarshal([]byte(text), &v) != nil {
+		return nil, false
+	}
+	shape := make(map[string]string)
+	var walk func(path string, v interface{})
+	walk = func(path string, v interface{}) {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			shape[path] = "object"
+			for k, child := range t {
+				walk(path+"."+k, child)
+			}
+		case []interface{}:
+			shape[path] = "array"
+			for _, child := range t {
+				walk(path+"[]", child)
+			}
+		case string:
+			shape[path] = "string"
+		case float64:
+			shape[path] = "number"
+		case bool:
+			shape[path] = "boolean"
+		default:
+			shape[path] = "null"
+		}
+	}
+	walk("$", v)
+	return shape, true
+}
+
+func round(f float64) float64 {
+	return math.Round(f*1000) / 1000
+}
@@ -0,0 +1,228 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ArchiveDirName is the directory (inside the store directory) holding
+// archived sessions, one zstd-compressed JSON file each
+const ArchiveDirName = "archive"
+
+// GetArchivePath returns the archive directory
+func GetArchivePath() (string, error) {
+	storePath, err := GetStorePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(storePath, ArchiveDirName), nil
+}
+
+// ArchiveSession compresses a session (exact ID) into the archive directory
+// and replaces it in store.json with an index entry. The caller saves the store.
+func (s *Store) ArchiveSession(id string) (*ArchivedSession, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	idx := -1
+	for i := range s.Sessions {
+		if s.Sessions[i].ID == id {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, fmt.Errorf("session not found: %s", id)
+	}
+	session := s.Sessions[idx]
+
+	dir, err := GetArchivePath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	data, err := sonic.Marshal(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal session: %w", err)
+	}
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	if err != nil {
+		return nil, err
+	}
+	compressed := enc.EncodeAll(data, nil)
+	enc.Close()
+
+	file := archiveFileName(session.ID)
+	if err := os.WriteFile(filepath.Join(dir, file), compressed, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	entry := ArchivedSession{
+		ID:        session.ID,
+		Timestamp: session.Timestamp,
+		Note:      session.Note,
+		Requests:  len(session.Requests),
+		File:      file,
+		Bytes:     int64(len(compressed)),
+	}
+	s.Sessions = append(s.Sessions[:idx], s.Sessions[idx+1:]...)
+	s.Archived = append(s.Archived, entry)
+	return &entry, nil
+}
+
+// RestoreSession moves an archived session (exact ID) back into store.json
+// and deletes its archive file. The caller saves the store.
+func (s *Store) RestoreSession(id string) (*Session, error) {
+	session, err := s.LoadArchivedSession(id)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, a := range s.Archived {
+		if a.ID != session.ID {
+			continue
+		}
+		s.Sessions = append(s.Sessions, *session)
+		s.Archived = append(s.Archived[:i], s.Archived[i+1:]...)
+		delete(s.archiveCache, a.ID)
+		if dir, err := GetArchivePath(); err == nil {
+			os.Remove(filepath.Join(dir, a.File))
+		}
+		return &s.Sessions[len(s.Sessions)-1], nil
+	}
+	return nil, fmt.Errorf("archived session not found: %s", id)
+}
+
+// DeleteArchivedSessions removes archived sessions (exact IDs) and their
+// files. Returns the number removed.
+func (s *Store) DeleteArchivedSessions(ids ...string) int {
+	mu.Lock()
+	defer mu.Unlock()
+
+	drop := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		drop[id] = true
+	}
+	dir, _ := GetArchivePath()
+	kept := s.Archived[:0]
+	for _, a := range s.Archived {
+		if !drop[a.ID] {
+			kept = append(kept, a)
+			continue
+		}
+		delete(s.archiveCache, a.ID)
+		if dir != "" {
+			os.Remove(filepath.Join(dir, a.File))
+		}
+	}
+	removed := len(s.Archived) - len(kept)
+	s.Archived = kept
+	return removed
+}
+
+// ListArchivedSessions returns the archive index (newest first)
+func (s *Store) ListArchivedSessions() []ArchivedSession {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	result := make([]ArchivedSession, len(s.Archived))
+	for i, a := range s.Archived {
+		result[len(result)-1-i] = a
+	}
+	return result
+}
+
+// LoadArchivedSession decompresses an archived session by ID (exact or
+// prefix match). Loaded sessions are cached for the rest of the run.
+func (s *Store) LoadArchivedSession(id string) (*Session, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var entry *ArchivedSession
+	for i := range s.Archived {
+		if s.Archived[i].ID == id {
+			entry = &s.Archived[i]
+			break
+		}
+	}
+	if entry == nil {
+		for i := range s.Archived {
+			if strings.HasPrefix(s.Archived[i].ID, id) {
+				entry = &s.Archived[i]
+				break
+			}
+		}
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("archived session not found: %s", id)
+	}
+	if cached := s.archiveCache[entry.ID]; cached != nil {
+		return cached, nil
+	}
+
+	dir, err := GetArchivePath()
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := os.ReadFile(filepath.Join(dir, entry.File))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dec.Close()
+	data, err := dec.DecodeAll(compressed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %w", entry.File, err)
+	}
+
+	var session Session
+	if err := sonic.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", entry.File, err)
+	}
+	for i := range session.Requests {
+		ComputeRequestFields(&session.Requests[i])
+	}
+
+	if s.archiveCache == nil {
+		s.archiveCache = make(map[string]*Session)
+	}
+	s.archiveCache[entry.ID] = &session
+	return &session, nil
+}
+
+// archiveFileName returns a filesystem-safe file name for a session ID. IDs
+// that had to be sanitized get a short hash of the raw ID, so "a/b" and
+// "a_b" don't share a file.
+func archiveFileName(id string) string {
+	name := safeFileName(id)
+	if name != id {
+		sum := sha256.Sum256([]byte(id))
+		name += "-" + hex.EncodeToString(sum[:4])
+	}
+	return name + ".json.zst"
+}
+
+// safeFileName maps an ID to letters, digits, '-' and '_'
+func safeFileName(id string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, id)
+}
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AutoSaveNote is the note given to sessions created by auto-save
+const AutoSaveNote = "autosave"
+
+// AutoSaveConfig decides when the live session is snapshotted into a saved
+// session and truncated. Zero values disable the corresponding trigger.
+type AutoSaveConfig struct {
+	MaxRequests int           // Snapshot once live.json holds this many requests
+	MaxAge      time.Duration // Snapshot once the oldest live request is this old
+}
+
+// AutoSaveConfigFromEnv reads REP_AUTOSAVE_REQUESTS (count) and
+// REP_AUTOSAVE_AGE (Go duration or "1d"). Unset variables leave the trigger off.
+func AutoSaveConfigFromEnv() (AutoSaveConfig, error) {
+	var cfg AutoSaveConfig
+	if v := strings.TrimSpace(os.Getenv("REP_AUTOSAVE_REQUESTS")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return cfg, fmt.Errorf("invalid REP_AUTOSAVE_REQUESTS: %s", v)
+		}
+		cfg.MaxRequests = n
+	}
+	if v := strings.TrimSpace(os.Getenv("REP_AUTOSAVE_AGE")); v != "" {
+		d, err := ParseAge(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid REP_AUTOSAVE_AGE: %s", v)
+		}
+		cfg.MaxAge = d
+	}
+	return cfg, nil
+}
+
+// ParseAge parses a Go duration, also accepting a "d" (day) suffix
+func ParseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration: %s", value)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration: %s", value)
+	}
+	return d, nil
+}
+
+// Enabled reports whether any trigger is set
+func (c AutoSaveConfig) Enabled() bool {
+	return c.MaxRequests > 0 || c.MaxAge > 0
+}
+
+// Due reports whether a live session with count requests, the oldest captured
+// at oldestMillis, should be snapshotted now
+func (c AutoSaveConfig) Due(count int, oldestMillis int64, now time.Time) bool {
+	if count == 0 {
+		return false
+	}
+	if c.MaxRequests > 0 && count >= c.MaxRequests {
+		return true
+	}
+	if c.MaxAge > 0 && oldestMillis > 0 && now.Sub(time.UnixMilli(oldestMillis)) >= c.MaxAge {
+		return true
+	}
+	return false
+}
+
+// SnapshotSession adds requests to store.json as a new saved session. The
+// store is re-read from disk first, so long-running callers (the native host)
+// don't overwrite changes made by the CLI in the meantime.
+func SnapshotSession(note string, requests []Request) (*Session, error) {
+	s, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	session := s.AddSession(GenerateSessionID(note), note, requests)
+	if err := s.Save(); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
@@ -48,3 +48,32 @@ func isStableID(req *Request) bool {
 	}
 	return strings.HasPrefix(req.ID, "h_")
 }
+
+// DedupIndex finds requests already present in a list by their index keys
+// (content hash, plus the ID when it is stable), so a request re-sent after
+// a reconnect can update its earlier copy instead of being added twice.
+type DedupIndex struct {
+	positions map[string]int
+}
+
+// NewDedupIndex returns an empty index.
+func NewDedupIndex() *DedupIndex {
+	return &DedupIndex{positions: make(map[string]int)}
+}
+
+// Find returns the position of an earlier copy of req.
+func (d *DedupIndex) Find(req *Request) (int, bool) {
+	for _, key := range requestIndexKeys(req) {
+		if pos, ok := d.positions[key]; ok {
+			return pos, true
+		}
+	}
+	return 0, false
+}
+
+// Add records req at position.
+func (d *DedupIndex) Add(req *Request, position int) {
+	for _, key := range requestIndexKeys(req) {
+		d.positions[key] = position
+	}
+}
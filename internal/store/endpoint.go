@@ -0,0 +1,43 @@
+package store
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	uuidSegment = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hexSegment  = regexp.MustCompile(`^[0-9a-fA-F]{16,}$`)
+	numSegment  = regexp.MustCompile(`^\d+$`)
+	// Opaque tokens: long mixed letters+digits (base64url ids, hashes)
+	tokenSegment = regexp.MustCompile(`^[A-Za-z0-9_-]{20,}$`)
+)
+
+// EndpointTemplate collapses variable path segments (numbers, UUIDs, hashes,
+// opaque tokens) into {id}, so /v1/users/42 and /v1/users/43 share
+// /v1/users/{id}. The query string is dropped.
+func EndpointTemplate(path string) string {
+	if idx := strings.IndexAny(path, "?#"); idx >= 0 {
+		path = path[:idx]
+	}
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if isVariableSegment(seg) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isVariableSegment(seg string) bool {
+	if seg == "" {
+		return false
+	}
+	if numSegment.MatchString(seg) || uuidSegment.MatchString(seg) || hexSegment.MatchString(seg) {
+		return true
+	}
+	return tokenSegment.MatchString(seg) && strings.ContainsAny(seg, "0123456789")
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// LockFile takes an advisory lock guarding path (on path + ".lock"), shared
+// for readers or exclusive for writers. The native host and the CLI both use
+// it around live.json. Call the returned function to release.
+func LockFile(path string, exclusive bool) (func(), error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, exclusive); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// ReadFileLocked reads path under a shared lock. When the lock can't be
+// taken (e.g. read-only directory) it falls back to a plain read, which is
+// still safe against torn reads because writers replace the file atomically.
+func ReadFileLocked(path string) ([]byte, error) {
+	if unlock, err := LockFile(path, false); err == nil {
+		defer unlock()
+	}
+	return os.ReadFile(path)
+}
+
+// UpdateFile replaces the content of path with fn's result, holding the
+// exclusive lock from the read to the rename so a concurrent writer's change
+// can't land in between and be lost. data is nil when path doesn't exist;
+// fn returning nil data leaves the file untouched.
+func UpdateFile(path string, perm os.FileMode, fn func(data []byte) ([]byte, error)) error {
+	unlock, err := LockFile(path, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	data, err = fn(data)
+	if err != nil || data == nil {
+		return err
+	}
+	return writeFileAtomic(path, data, perm, false)
+}
+
+// WriteFileAtomic writes data to a temp file in the same directory and
+// renames it over path, so readers see either the old or the new content,
+// never a partial write. Takes the exclusive lock while replacing.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return writeFileAtomic(path, data, perm, true)
+}
+
+// writeFileAtomic is WriteFileAtomic; lock=false is for callers that already
+// hold the exclusive lock on path
+func writeFileAtomic(path string, data []byte, perm os.FileMode, lock bool) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // No-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+
+	if lock {
+		if unlock, err := LockFile(path, true); err == nil {
+			defer unlock()
+		}
+	}
+	return os.Rename(tmpPath, path)
+}
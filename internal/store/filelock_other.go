@@ -0,0 +1,12 @@
+//go:build !unix && !windows
+
+package store
+
+import "os"
+
+// No advisory locking here; WriteFileAtomic's rename still keeps readers
+// from seeing partial writes.
+
+func lockFile(f *os.File, exclusive bool) error { return nil }
+
+func unlockFile(f *os.File) error { return nil }
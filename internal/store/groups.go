@@ -0,0 +1,116 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NormalizeGroupName strips a leading "@" and validates the remaining name
+// (letters, digits, '-', '_' and '.').
+func NormalizeGroupName(name string) (string, error) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+	if name == "" {
+		return "", fmt.Errorf("empty group name")
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return "", fmt.Errorf("invalid group name: %s (use letters, digits, '-', '_', '.')", name)
+		}
+	}
+	return name, nil
+}
+
+// SetDomainGroup creates or replaces a named group of domains
+func (s *Store) SetDomainGroup(name string, domains []string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if s.DomainGroups == nil {
+		s.DomainGroups = make(map[string][]string)
+	}
+	s.DomainGroups[name] = uniqueSorted(domains)
+}
+
+// AddToDomainGroup adds domains to a group (creating it if needed)
+func (s *Store) AddToDomainGroup(name string, domains ...string) int {
+	mu.Lock()
+	defer mu.Unlock()
+	if s.DomainGroups == nil {
+		s.DomainGroups = make(map[string][]string)
+	}
+	before := len(s.DomainGroups[name])
+	s.DomainGroups[name] = uniqueSorted(append(s.DomainGroups[name], domains...))
+	return len(s.DomainGroups[name]) - before
+}
+
+// RemoveFromDomainGroup removes domains from a group
+func (s *Store) RemoveFromDomainGroup(name string, domains ...string) int {
+	mu.Lock()
+	defer mu.Unlock()
+	members, ok := s.DomainGroups[name]
+	if !ok {
+		return 0
+	}
+	drop := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		drop[strings.ToLower(d)] = true
+	}
+	kept := members[:0]
+	for _, d := range members {
+		if !drop[d] {
+			kept = append(kept, d)
+		}
+	}
+	s.DomainGroups[name] = kept
+	return len(members) - len(kept)
+}
+
+// DeleteDomainGroup removes a group entirely
+func (s *Store) DeleteDomainGroup(name string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := s.DomainGroups[name]; !ok {
+		return false
+	}
+	delete(s.DomainGroups, name)
+	return true
+}
+
+// GetDomainGroup returns the domains in a group (nil, false if it doesn't exist)
+func (s *Store) GetDomainGroup(name string) ([]string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	members, ok := s.DomainGroups[name]
+	if !ok {
+		return nil, false
+	}
+	return append([]string(nil), members...), true
+}
+
+// GetDomainGroupNames returns all group names, sorted
+func (s *Store) GetDomainGroupNames() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(s.DomainGroups))
+	for name := range s.DomainGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// uniqueSorted lowercases, dedupes and sorts domains
+func uniqueSorted(domains []string) []string {
+	seen := make(map[string]bool, len(domains))
+	result := make([]string, 0, len(domains))
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" || seen[d] {
+			continue
+		}
+		seen[d] = true
+		result = append(result, d)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -82,3 +83,76 @@ func HeaderFirst(headers HeaderMap, name string) string {
 	}
 	return values[0]
 }
+
+// CloneHeaders returns a deep copy of headers.
+func CloneHeaders(headers HeaderMap) HeaderMap {
+	if headers == nil {
+		return nil
+	}
+	clone := make(HeaderMap, len(headers))
+	for key, values := range headers {
+		clone[key] = append([]string(nil), values...)
+	}
+	return clone
+}
+
+// SetHeader replaces all values of a header (case-insensitive), keeping the
+// existing key casing when present.
+func SetHeader(headers HeaderMap, name, value string) {
+	if key, _ := HeaderValuesWithKey(headers, name); key != "" {
+		headers[key] = []string{value}
+		return
+	}
+	headers[name] = []string{value}
+}
+
+// DelHeader removes a header (case-insensitive).
+func DelHeader(headers HeaderMap, name string) {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			delete(headers, key)
+		}
+	}
+}
+
+// HeaderMatch matches requests carrying a header, optionally with a value
+// matching a (case-insensitive) regex.
+type HeaderMatch struct {
+	Name  string
+	Value *regexp.Regexp // nil = header present with any value
+}
+
+// ParseHeaderMatch parses "name" or "name:value-regex".
+func ParseHeaderMatch(spec string) (HeaderMatch, error) {
+	name, pattern, hasValue := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return HeaderMatch{}, fmt.Errorf("empty header name in %q", spec)
+	}
+	match := HeaderMatch{Name: name}
+	if hasValue {
+		re, err := regexp.Compile("(?i)" + strings.TrimSpace(pattern))
+		if err != nil {
+			return HeaderMatch{}, fmt.Errorf("invalid header value regex in %q: %w", spec, err)
+		}
+		match.Value = re
+	}
+	return match, nil
+}
+
+// Matches reports whether headers satisfy the match.
+func (m HeaderMatch) Matches(headers HeaderMap) bool {
+	values := HeaderValues(headers, m.Name)
+	if len(values) == 0 {
+		return false
+	}
+	if m.Value == nil {
+		return true
+	}
+	for _, v := range values {
+		if m.Value.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
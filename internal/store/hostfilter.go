@@ -0,0 +1,91 @@
+package store
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/bytedance/sonic"
+)
+
+// HostFilter is the set of domains and domain/path patterns the native host
+// drops before writing to live.json. Paths use the 'rep mute' syntax.
+type HostFilter struct {
+	Domains map[string]bool `json:"domains,omitempty"`
+	Paths   []MutedPath     `json:"paths,omitempty"`
+}
+
+// ParseHostFilter builds a filter from domain names and mute-style patterns
+// ("domain/path", "*/path", "domain/^regex")
+func ParseHostFilter(domains, paths []string) (HostFilter, error) {
+	f := HostFilter{Domains: make(map[string]bool)}
+	for _, d := range domains {
+		if d != "" {
+			f.Domains[d] = true
+		}
+	}
+	for _, p := range paths {
+		domain, path := parseMutePattern(p)
+		if domain == "" || path == "" {
+			return f, fmt.Errorf("invalid path pattern: %s (use domain/path or */path)", p)
+		}
+		f.Paths = append(f.Paths, MutedPath{Domain: domain, Pattern: path})
+	}
+	return f, nil
+}
+
+// LoadHostFilter reads the ignore and mute lists from store.json. Sessions
+// are not decoded, so this stays cheap when the store is large.
+func LoadHostFilter() (HostFilter, error) {
+	var lists struct {
+		IgnoredDomains map[string]bool `json:"ignored_domains"`
+		MutedPaths     []MutedPath     `json:"muted_paths"`
+	}
+	filePath, err := GetStoreFilePath()
+	if err != nil {
+		return HostFilter{}, err
+	}
+	data, err := ReadFileLocked(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return HostFilter{}, nil
+		}
+		return HostFilter{}, err
+	}
+	if err := sonic.Unmarshal(data, &lists); err != nil {
+		return HostFilter{}, fmt.Errorf("failed to parse store: %w", err)
+	}
+
+	f := HostFilter{Domains: make(map[string]bool), Paths: lists.MutedPaths}
+	for d, ignored := range lists.IgnoredDomains {
+		if ignored {
+			f.Domains[d] = true
+		}
+	}
+	return f, nil
+}
+
+// Rules returns the number of domain and path rules
+func (f HostFilter) Rules() int {
+	return len(f.Domains) + len(f.Paths)
+}
+
+// Drops reports whether a request to rawURL should be discarded. Matching is
+// the same as --exclude-ignored and mute: exact host, path without query.
+func (f HostFilter) Drops(rawURL string) bool {
+	if f.Rules() == 0 {
+		return false
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if f.Domains[parsed.Host] {
+		return true
+	}
+	if len(f.Paths) == 0 {
+		return false
+	}
+	muted := Store{MutedPaths: f.Paths}
+	return muted.isMutedInternal(parsed.Host, parsed.Path)
+}
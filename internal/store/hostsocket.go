@@ -0,0 +1,80 @@
+package store
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// The native host can serve captured requests over a local socket (Unix
+// domain socket; AF_UNIX also works on Windows 10+), so followers get pushed
+// updates instead of re-reading live.json. Frames are a 4-byte little-endian
+// length followed by JSON, like native messaging.
+//
+// A client sends one SocketRequest, then receives SocketEvents:
+//   - "snapshot": requests already captured (Timestamp > Since)
+//   - "add":      one new request
+//   - "update":   a re-sent request merged into its earlier copy (the result)
+//   - "sync":     the extension replaced the whole live set
+//   - "clear":    the live set was emptied (clear or auto-save)
+// "snapshot" requests get their snapshot and the connection is closed;
+// "subscribe" requests stay open until either side disconnects.
+
+// HostSocketFileName is the socket next to live.json (host-<id>.sock for a
+// namespaced live-<id>.json)
+const HostSocketFileName = "host.sock"
+
+// MaxSocketFrame bounds a single frame read from the socket
+const MaxSocketFrame = 256 << 20
+
+// SocketRequest is the first (and only) frame a client sends
+type SocketRequest struct {
+	Action string `json:"action"`          // "subscribe" or "snapshot"
+	Since  int64  `json:"since,omitempty"` // Only requests captured after this (Unix millis)
+}
+
+// SocketEvent is a frame pushed by the host
+type SocketEvent struct {
+	Type     string    `json:"type"`
+	Request  *Request  `json:"request,omitempty"`
+	Requests []Request `json:"requests,omitempty"`
+}
+
+// GetHostSocketPath returns the socket path for the live file at livePath
+func GetHostSocketPath(livePath string) string {
+	name := HostSocketFileName
+	if id, ok := liveSessionID(filepath.Base(livePath)); ok {
+		name = strings.TrimSuffix(HostSocketFileName, ".sock") + "-" + id + ".sock"
+	}
+	return filepath.Join(filepath.Dir(livePath), name)
+}
+
+// WriteFrame writes one length-prefixed frame
+func WriteFrame(w io.Writer, data []byte) error {
+	var header [4]byte
+	binary.LittleEndian.PutUint32(header[:], uint32(len(data)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// ReadFrame reads one length-prefixed frame of at most max bytes
+func ReadFrame(r io.Reader, max int) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	n := binary.LittleEndian.Uint32(header[:])
+	if int64(n) > int64(max) {
+		return nil, fmt.Errorf("frame too large: %d bytes", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
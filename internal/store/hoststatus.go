@@ -0,0 +1,78 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"github.com/bytedance/sonic"
+)
+
+// HostStatusFileName is the sidecar file the native host keeps next to live.json
+const HostStatusFileName = "host-status.json"
+
+// HostStatus is the native host's self-reported state ('rep host-status')
+type HostStatus struct {
+	Running          bool   `json:"running"`
+	PID              int    `json:"pid"`
+	StartedAt        int64  `json:"started_at"` // Unix millis
+	UpdatedAt        int64  `json:"updated_at"` // Unix millis
+	LivePath         string `json:"live_path"`
+	LiveRequests     int    `json:"live_requests"`
+	MaxRequests      int    `json:"max_requests"`
+	KeepOnDisconnect bool   `json:"keep_on_disconnect"`
+	Messages         int64  `json:"messages"`      // Messages processed
+	BytesWritten     int64  `json:"bytes_written"` // live.json bytes written
+	Rotations        int64  `json:"rotations"`     // Times the request cap dropped requests
+	Dropped          int64  `json:"dropped"`       // Requests dropped by rotation
+	AutoSaves        int64  `json:"autosaves"`     // Auto-save snapshots written
+	Duplicates       int64  `json:"duplicates"`    // Re-sent requests merged into an existing entry
+	Filtered         int64  `json:"filtered"`      // Requests dropped by host-side filtering
+	FilterStore      bool   `json:"filter_store"`  // Ignore/mute lists applied by the host
+	FilterRules      int    `json:"filter_rules"`  // Active host-side filter rules
+	LastError        string `json:"last_error,omitempty"`
+
+	Socket string `json:"socket,omitempty"` // Push socket path, when the host serves one (-socket)
+}
+
+// GetHostStatusPath returns the sidecar path for the live file at livePath:
+// host-status.json, or host-status-<id>.json for a namespaced live-<id>.json
+func GetHostStatusPath(livePath string) string {
+	name := HostStatusFileName
+	if id, ok := liveSessionID(filepath.Base(livePath)); ok {
+		name = strings.TrimSuffix(HostStatusFileName, ".json") + "-" + id + ".json"
+	}
+	return filepath.Join(filepath.Dir(livePath), name)
+}
+
+// LoadHostStatus reads the sidecar next to livePath
+func LoadHostStatus(livePath string) (*HostStatus, error) {
+	data, err := os.ReadFile(GetHostStatusPath(livePath))
+	if err != nil {
+		return nil, err
+	}
+	var status HostStatus
+	if err := sonic.Unmarshal(data, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// ProcessAlive reports whether pid is a running process. Where that can't
+// be checked cheaply (Windows), it assumes the process is alive.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = proc.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// BodyRef locates a request's JSON object inside an export file so its
+// bodies can be read on demand instead of held in memory
+type BodyRef struct {
+	Path   string
+	Offset int64
+	Length int64
+	ID     string // ID in the file, when the request was renamed on merge
+}
+
+// LoadLiveWithoutBodies reads an export file leaving Body, Response.Body and
+// Response.Events empty, even where the file has them. Each request keeps a
+// BodyRef (BodiesDeferred reports true); call LoadBodies before reading a
+// body, since an unloaded body is indistinguishable from an empty one.
+// Commands that only look at URLs, methods, statuses and headers (summary,
+// domains, recon) use this to avoid paying for bodies.
+func LoadLiveWithoutBodies(path string) ([]Request, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	res, err := NewStore().FilterStream(bufio.NewReaderSize(f, 1<<20), FilterOptions{}, StreamOptions{SkipBodies: true, Path: path})
+	if err != nil {
+		return nil, err
+	}
+	return res.Requests, nil
+}
+
+// LoadLiveWithoutBodiesAll is LoadLiveWithoutBodies over several live files,
+// merged with MergeLiveRequests. Unreadable files are skipped unless all are.
+func LoadLiveWithoutBodiesAll(paths []string) ([]Request, error) {
+	res, err := NewStore().FilterStreamFiles(paths, FilterOptions{}, StreamOptions{SkipBodies: true})
+	if err != nil {
+		return nil, err
+	}
+	return res.Requests, nil
+}
+
+// BodiesDeferred reports whether the request was read without its bodies
+// (LoadLiveWithoutBodies), so an empty Body or Response.Body means "not
+// loaded" rather than "none"
+func (r *Request) BodiesDeferred() bool {
+	return r.BodyRef != nil
+}
+
+// LoadBodies fills Body, Response.Body and Response.Events of a request read
+// without bodies.
+// It is a no-op when the bodies are already loaded. Fails if the file has
+// been rewritten since (the request at the recorded offset has another ID).
+func LoadBodies(req *Request) error {
+	ref := req.BodyRef
+	if ref == nil {
+		return nil
+	}
+
+	f, err := os.Open(ref.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buf := make([]byte, ref.Length)
+	if _, err := f.ReadAt(buf, ref.Offset); err != nil {
+		return fmt.Errorf("failed to read body of %s: %w", req.ID, err)
+	}
+	// The range can start with the separator between array elements
+	buf = bytes.TrimLeft(buf, ", \t\r\n")
+
+	wantID := req.ID
+	if ref.ID != "" {
+		wantID = ref.ID
+	}
+	var full Request
+	if err := json.Unmarshal(buf, &full); err != nil || full.ID != wantID {
+		return fmt.Errorf("%s changed since it was read; reload to get the body of %s", ref.Path, req.ID)
+	}
+
+	req.Body = full.Body
+	if req.Response != nil && full.Response != nil {
+		req.Response.Body = full.Response.Body
+		req.Response.Events = full.Response.Events
+	}
+	req.BodyRef = nil
+	return nil
+}
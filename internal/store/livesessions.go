@@ -0,0 +1,341 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// LiveIndexFileName lists the per-host live files (live-<id>.json) written
+// next to live.json, so several browsers/profiles can capture at once
+const LiveIndexFileName = "live-index.json"
+
+// LiveSession is one native host's entry in the live index
+type LiveSession struct {
+	ID        string `json:"id"`                // Namespace key; file is live-<id>.json
+	Profile   string `json:"profile,omitempty"` // Browser profile label, if the extension sent one
+	File      string `json:"file"`              // Base name, in the live.json directory
+	PID       int    `json:"pid"`
+	StartedAt int64  `json:"started_at"` // Unix millis
+	Running   bool   `json:"running"`
+}
+
+// LiveIndex is the live-index.json format
+type LiveIndex struct {
+	Sessions []LiveSession `json:"sessions"`
+}
+
+// liveSelection restricts live reads and writes to one live session (--session)
+var liveSelection string
+
+// SelectLiveSession makes GetLiveFilePath and GetLiveFilePaths use one live
+// session, matched by ID, profile or ID prefix. "" restores the default.
+func SelectLiveSession(id string) {
+	liveSelection = id
+}
+
+// GetLiveIndexPath returns the live index path (next to the default live.json)
+func GetLiveIndexPath() (string, error) {
+	livePath, err := defaultLiveFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(livePath), LiveIndexFileName), nil
+}
+
+// LiveSessionFileName returns the live file name for a namespace key
+func LiveSessionFileName(id string) string {
+	return "live-" + safeFileName(id) + ".json"
+}
+
+// liveSessionID extracts the key from a live-<id>.json file name
+func liveSessionID(name string) (string, bool) {
+	if !strings.HasPrefix(name, "live-") || !strings.HasSuffix(name, ".json") || name == LiveIndexFileName {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(name, "live-"), ".json"), true
+}
+
+// LoadLiveIndex reads the live index. A missing index is empty.
+func LoadLiveIndex() (LiveIndex, error) {
+	var index LiveIndex
+	path, err := GetLiveIndexPath()
+	if err != nil {
+		return index, err
+	}
+	data, err := ReadFileLocked(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+		return index, err
+	}
+	if err := sonic.Unmarshal(data, &index); err != nil {
+		return index, fmt.Errorf("failed to parse %s: %w", LiveIndexFileName, err)
+	}
+	return index, nil
+}
+
+// UpdateLiveIndex applies fn to the live index under an exclusive lock, so
+// concurrent hosts don't lose each other's entries
+func UpdateLiveIndex(fn func(*LiveIndex)) error {
+	path, err := GetLiveIndexPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return UpdateFile(path, 0644, func(data []byte) ([]byte, error) {
+		var index LiveIndex
+		if data != nil {
+			if err := sonic.Unmarshal(data, &index); err != nil {
+				index = LiveIndex{} // Rebuilt by the hosts that are still running
+			}
+		}
+		fn(&index)
+		return sonic.MarshalIndent(index, "", "  ")
+	})
+}
+
+// RegisterLiveSession adds a native host to the live index and returns its
+// entry. A stopped session with the same profile whose file still exists
+// (kept on disconnect) is taken over, so reconnecting resumes it; otherwise
+// a new key is derived from profile or fallbackID.
+func RegisterLiveSession(profile, fallbackID string, pid int) (LiveSession, error) {
+	var entry LiveSession
+	err := UpdateLiveIndex(func(index *LiveIndex) {
+		dir := ""
+		if path, err := GetLiveIndexPath(); err == nil {
+			dir = filepath.Dir(path)
+		}
+
+		// Drop entries whose file is gone and whose host has exited
+		kept := index.Sessions[:0]
+		for _, s := range index.Sessions {
+			alive := s.Running && ProcessAlive(s.PID)
+			if _, err := os.Stat(filepath.Join(dir, s.File)); err != nil && !alive {
+				continue
+			}
+			s.Running = alive
+			kept = append(kept, s)
+		}
+		index.Sessions = kept
+
+		for i := range index.Sessions {
+			s := &index.Sessions[i]
+			if !s.Running && s.Profile == profile {
+				s.PID = pid
+				s.Running = true
+				entry = *s
+				return
+			}
+		}
+
+		key := profile
+		if key == "" {
+			key = fallbackID
+		}
+		id := key
+		for n := 2; liveIndexHas(index, id); n++ {
+			id = key + "-" + strconv.Itoa(n)
+		}
+		entry = LiveSession{
+			ID:        id,
+			Profile:   profile,
+			File:      LiveSessionFileName(id),
+			PID:       pid,
+			StartedAt: time.Now().UnixMilli(),
+			Running:   true,
+		}
+		index.Sessions = append(index.Sessions, entry)
+	})
+	return entry, err
+}
+
+func liveIndexHas(index *LiveIndex, id string) bool {
+	for _, s := range index.Sessions {
+		if s.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateLiveSession changes the entry with the given ID, if present
+func UpdateLiveSession(id string, fn func(*LiveSession)) error {
+	return UpdateLiveIndex(func(index *LiveIndex) {
+		for i := range index.Sessions {
+			if index.Sessions[i].ID == id {
+				fn(&index.Sessions[i])
+				return
+			}
+		}
+	})
+}
+
+// RemoveLiveSession deletes the entry with the given ID
+func RemoveLiveSession(id string) error {
+	return UpdateLiveIndex(func(index *LiveIndex) {
+		kept := index.Sessions[:0]
+		for _, s := range index.Sessions {
+			if s.ID != id {
+				kept = append(kept, s)
+			}
+		}
+		index.Sessions = kept
+	})
+}
+
+// ListLiveSessions returns indexed live sessions whose files exist, oldest
+// first. Running is re-checked against the host process.
+func ListLiveSessions() ([]LiveSession, error) {
+	index, err := LoadLiveIndex()
+	if err != nil {
+		return nil, err
+	}
+	path, err := GetLiveIndexPath()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(path)
+
+	var result []LiveSession
+	for _, s := range index.Sessions {
+		if _, err := os.Stat(filepath.Join(dir, s.File)); err != nil {
+			continue
+		}
+		s.Running = s.Running && ProcessAlive(s.PID)
+		result = append(result, s)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].StartedAt < result[j].StartedAt
+	})
+	return result, nil
+}
+
+// FindLiveSession matches a live session by exact ID, profile, then ID prefix
+func FindLiveSession(id string) (*LiveSession, error) {
+	sessions, err := ListLiveSessions()
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range []func(LiveSession) bool{
+		func(s LiveSession) bool { return s.ID == id },
+		func(s LiveSession) bool { return s.Profile != "" && s.Profile == id },
+		func(s LiveSession) bool { return strings.HasPrefix(s.ID, id) },
+	} {
+		for i := range sessions {
+			if match(sessions[i]) {
+				return &sessions[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("live session not found: %s (see 'rep sessions')", id)
+}
+
+// LiveSessionPath returns the file of a live session
+func LiveSessionPath(s LiveSession) (string, error) {
+	path, err := GetLiveIndexPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), s.File), nil
+}
+
+// selectedLiveFilePath resolves the SelectLiveSession selection
+func selectedLiveFilePath() (string, error) {
+	session, err := FindLiveSession(liveSelection)
+	if err != nil {
+		return "", err
+	}
+	return LiveSessionPath(*session)
+}
+
+// GetLiveFilePaths returns every live file to read: REPLIVE_PATH or the
+// selected session alone, otherwise live.json (if present) plus each
+// indexed live session. When none exist it returns the default live.json,
+// so callers report the usual "not found".
+func GetLiveFilePaths() ([]string, error) {
+	if os.Getenv("REPLIVE_PATH") != "" || liveSelection != "" {
+		path, err := GetLiveFilePath()
+		if err != nil {
+			return nil, err
+		}
+		return []string{path}, nil
+	}
+
+	defaultPath, err := defaultLiveFilePath()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	if _, err := os.Stat(defaultPath); err == nil {
+		paths = append(paths, defaultPath)
+	}
+	if sessions, err := ListLiveSessions(); err == nil {
+		for _, s := range sessions {
+			if path, err := LiveSessionPath(s); err == nil {
+				paths = append(paths, path)
+			}
+		}
+	}
+	if len(paths) == 0 {
+		paths = append(paths, defaultPath)
+	}
+	return paths, nil
+}
+
+// LiveSourceName returns the session key of a live-<id>.json file, or ""
+// for any other file (live.json, REPLIVE_PATH)
+func LiveSourceName(path string) string {
+	id, _ := liveSessionID(filepath.Base(path))
+	return id
+}
+
+// MergeLiveRequests combines requests read from several live files in
+// capture order. IDs from namespaced sessions always become
+// "<session>:<id>", whether or not other sources are read, so requests from
+// different browsers never collide and a request keeps the same ID when
+// another browser connects. A single set keeps its order.
+func MergeLiveRequests(sources []string, sets [][]Request) []Request {
+	if len(sets) == 1 && sources[0] == "" {
+		return sets[0]
+	}
+	total := 0
+	for _, set := range sets {
+		total += len(set)
+	}
+	merged := make([]Request, 0, total)
+	for i, set := range sets {
+		for _, req := range set {
+			if sources[i] != "" {
+				prefixLiveID(sources[i], &req)
+			}
+			merged = append(merged, req)
+		}
+	}
+	if len(sets) > 1 {
+		sort.SliceStable(merged, func(i, j int) bool {
+			return merged[i].Timestamp < merged[j].Timestamp
+		})
+	}
+	return merged
+}
+
+// prefixLiveID gives a request read from a namespaced session its
+// "<session>:<id>" ID, keeping the file's ID for LoadBodies
+func prefixLiveID(source string, req *Request) {
+	if req.BodyRef != nil {
+		ref := *req.BodyRef
+		ref.ID = req.ID
+		req.BodyRef = &ref
+	}
+	req.ID = source + ":" + req.ID
+}
@@ -0,0 +1,401 @@
+package store
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+)
+
+// SearchIndexFileName is the cross-session search index, kept next to
+// store.json and updated on every Save
+const SearchIndexFileName = "search-index.json"
+
+// searchIndexVersion is bumped when the tokenization changes, which forces
+// a rebuild of existing index files
+const searchIndexVersion = 1
+
+// Term kinds held by the search index
+const (
+	TermURL    = "url"    // Host, path and query tokens
+	TermHeader = "header" // Request and response header names
+	TermDomain = "domain" // Request hosts
+	TermParam  = "param"  // Query and body parameter names
+)
+
+// SearchTermKinds lists the term kinds in display order
+var SearchTermKinds = []string{TermURL, TermHeader, TermDomain, TermParam}
+
+// SearchIndex maps URL tokens, header names, domains and parameter names to
+// the requests of every saved and archived session, so cross-session
+// lookups read one small file instead of store.json and the archives
+type SearchIndex struct {
+	Version   int                      `json:"version"`
+	StoreSize int64                    `json:"store_size"`  // store.json size when indexed
+	StoreTime int64                    `json:"store_mtime"` // store.json mtime (unix nanos) when indexed
+	Sessions  map[string]*SessionIndex `json:"sessions"`
+}
+
+// SessionIndex is the part of the index built from one session. It is
+// rebuilt only when the session's timestamp or request count changes.
+type SessionIndex struct {
+	Timestamp int64                       `json:"timestamp"`
+	Count     int                         `json:"count"`
+	Archived  bool                        `json:"archived,omitempty"`
+	Requests  []IndexedRequest            `json:"requests"`
+	Terms     map[string]map[string][]int `json:"terms"` // kind -> term -> positions in Requests
+}
+
+// IndexedRequest is the part of a request kept in the index for display
+type IndexedRequest struct {
+	ID     string `json:"id"`
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Status int    `json:"status,omitempty"`
+}
+
+// SearchHit is one request matching a search, with the terms that matched
+// (as "kind:term")
+type SearchHit struct {
+	Session string
+	Request IndexedRequest
+	Matched []string
+}
+
+// TermUsage is how often one term appears across the index
+type TermUsage struct {
+	Term      string   `json:"term"`
+	Requests  int      `json:"requests"`
+	Endpoints int      `json:"endpoints"`
+	Sessions  []string `json:"sessions"`
+}
+
+// GetSearchIndexPath returns the path of the search index file
+func GetSearchIndexPath() (string, error) {
+	storePath, err := GetStorePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(storePath, SearchIndexFileName), nil
+}
+
+// NewSearchIndex returns an empty index
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{Version: searchIndexVersion, Sessions: make(map[string]*SessionIndex)}
+}
+
+// LoadSearchIndex reads the index file. A missing, unreadable or outdated
+// file yields an empty index, which UpdateSearchIndex fills in.
+func LoadSearchIndex() *SearchIndex {
+	path, err := GetSearchIndexPath()
+	if err != nil {
+		return NewSearchIndex()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return NewSearchIndex()
+	}
+	ix := NewSearchIndex()
+	if err := sonic.Unmarshal(data, ix); err != nil || ix.Version != searchIndexVersion || ix.Sessions == nil {
+		return NewSearchIndex()
+	}
+	return ix
+}
+
+// IsCurrent reports whether the index was built from the store.json on disk
+func (ix *SearchIndex) IsCurrent() bool {
+	size, mtime := storeFileStamp()
+	return size == ix.StoreSize && mtime == ix.StoreTime
+}
+
+// LoadCurrentSearchIndex returns the index, updating it first when
+// store.json changed since it was built. Only then is the store loaded.
+func LoadCurrentSearchIndex() (*SearchIndex, error) {
+	ix := LoadSearchIndex()
+	if ix.IsCurrent() {
+		return ix, nil
+	}
+	s, err := Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load store: %w", err)
+	}
+	return s.UpdateSearchIndex()
+}
+
+// storeFileStamp returns the size and mtime of store.json (zero when missing)
+func storeFileStamp() (int64, int64) {
+	path, err := GetStoreFilePath()
+	if err != nil {
+		return 0, 0
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0
+	}
+	return info.Size(), info.ModTime().UnixNano()
+}
+
+// UpdateSearchIndex brings the index file in line with the store: new or
+// changed sessions are indexed, removed ones dropped, and sessions moved to
+// the archive keep their entries. Unchanged sessions are not re-read, and
+// archives are only decompressed when missing from the index.
+func (s *Store) UpdateSearchIndex() (*SearchIndex, error) {
+	ix := LoadSearchIndex()
+	keep := make(map[string]bool)
+
+	for i := range s.Sessions {
+		session := &s.Sessions[i]
+		keep[session.ID] = true
+		entry := ix.Sessions[session.ID]
+		if entry != nil && entry.Timestamp == session.Timestamp && entry.Count == len(session.Requests) {
+			entry.Archived = false
+			continue
+		}
+		ix.Sessions[session.ID] = IndexSession(session.Timestamp, session.Requests)
+	}
+
+	for _, archived := range s.ListArchivedSessions() {
+		keep[archived.ID] = true
+		if entry := ix.Sessions[archived.ID]; entry != nil && entry.Timestamp == archived.Timestamp {
+			entry.Archived = true
+			continue
+		}
+		session, err := s.LoadArchivedSession(archived.ID)
+		if err != nil {
+			continue
+		}
+		entry := IndexSession(session.Timestamp, session.Requests)
+		entry.Archived = true
+		ix.Sessions[archived.ID] = entry
+	}
+
+	for id := range ix.Sessions {
+		if !keep[id] {
+			delete(ix.Sessions, id)
+		}
+	}
+
+	ix.StoreSize, ix.StoreTime = storeFileStamp()
+	path, err := GetSearchIndexPath()
+	if err != nil {
+		return ix, err
+	}
+	if err := EnsureStoreDir(); err != nil {
+		return ix, err
+	}
+	data, err := sonic.Marshal(ix)
+	if err != nil {
+		return ix, fmt.Errorf("failed to marshal search index: %w", err)
+	}
+	if err := WriteFileAtomic(path, data, 0644); err != nil {
+		return ix, fmt.Errorf("failed to write search index: %w", err)
+	}
+	return ix, nil
+}
+
+// IndexSession builds the index entry for a list of requests
+func IndexSession(timestamp int64, requests []Request) *SessionIndex {
+	entry := &SessionIndex{
+		Timestamp: timestamp,
+		Count:     len(requests),
+		Requests:  make([]IndexedRequest, 0, len(requests)),
+		Terms:     make(map[string]map[string][]int),
+	}
+	for i := range requests {
+		req := &requests[i]
+		pos := len(entry.Requests)
+		indexed := IndexedRequest{ID: req.ID, Method: req.Method, URL: req.URL}
+		if req.Response != nil {
+			indexed.Status = req.Response.Status
+		}
+		entry.Requests = append(entry.Requests, indexed)
+
+		for kind, terms := range requestTerms(req) {
+			postings := entry.Terms[kind]
+			if postings == nil {
+				postings = make(map[string][]int)
+				entry.Terms[kind] = postings
+			}
+			for _, term := range terms {
+				postings[term] = append(postings[term], pos)
+			}
+		}
+	}
+	return entry
+}
+
+// requestTerms returns the distinct terms of a request by kind
+func requestTerms(req *Request) map[string][]string {
+	terms := make(map[string][]string)
+	seen := make(map[string]bool)
+	add := func(kind, term string) {
+		// Parameter names are case-sensitive; the rest is not
+		term = strings.TrimSpace(term)
+		if kind != TermParam {
+			term = strings.ToLower(term)
+		}
+		if term == "" || seen[kind+":"+term] {
+			return
+		}
+		seen[kind+":"+term] = true
+		terms[kind] = append(terms[kind], term)
+	}
+
+	parsed, err := url.Parse(req.URL)
+	if err == nil {
+		add(TermDomain, parsed.Hostname())
+		for _, token := range urlTokens(parsed.Host + parsed.Path + "?" + parsed.RawQuery) {
+			add(TermURL, token)
+		}
+		for name := range parsed.Query() {
+			add(TermParam, name)
+		}
+	}
+	for name := range req.Headers {
+		if !strings.HasPrefix(name, ":") {
+			add(TermHeader, name)
+		}
+	}
+	if req.Response != nil {
+		for name := range req.Response.Headers {
+			if !strings.HasPrefix(name, ":") {
+				add(TermHeader, name)
+			}
+		}
+	}
+	for _, name := range bodyFieldNames(req) {
+		add(TermParam, name)
+	}
+	return terms
+}
+
+// urlTokens splits a URL on everything but letters and digits
+func urlTokens(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+}
+
+// bodyFieldNames returns the top-level field names of a JSON object body or
+// the names of a form-encoded body
+func bodyFieldNames(req *Request) []string {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil
+	}
+	var names []string
+	if strings.HasPrefix(body, "{") {
+		var fields map[string]interface{}
+		if sonic.UnmarshalString(body, &fields) == nil {
+			for name := range fields {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	if strings.Contains(strings.ToLower(HeaderFirst(req.Headers, "content-type")), "form-urlencoded") {
+		if values, err := url.ParseQuery(body); err == nil {
+			for name := range values {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// sessionIDs returns the indexed session IDs, newest first
+func (ix *SearchIndex) sessionIDs() []string {
+	ids := make([]string, 0, len(ix.Sessions))
+	for id := range ix.Sessions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := ix.Sessions[ids[i]], ix.Sessions[ids[j]]
+		if a.Timestamp != b.Timestamp {
+			return a.Timestamp > b.Timestamp
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// Search returns the requests with a term of one of kinds (all when empty)
+// containing pattern, case-insensitively. Sessions are newest first and
+// requests keep their captured order.
+func (ix *SearchIndex) Search(pattern string, kinds []string) []SearchHit {
+	pattern = strings.ToLower(pattern)
+	if len(kinds) == 0 {
+		kinds = SearchTermKinds
+	}
+	var hits []SearchHit
+	for _, id := range ix.sessionIDs() {
+		entry := ix.Sessions[id]
+		matched := make(map[int][]string)
+		for _, kind := range kinds {
+			for term, positions := range entry.Terms[kind] {
+				if !strings.Contains(strings.ToLower(term), pattern) {
+					continue
+				}
+				for _, pos := range positions {
+					matched[pos] = append(matched[pos], kind+":"+term)
+				}
+			}
+		}
+		positions := make([]int, 0, len(matched))
+		for pos := range matched {
+			positions = append(positions, pos)
+		}
+		sort.Ints(positions)
+		for _, pos := range positions {
+			terms := matched[pos]
+			sort.Strings(terms)
+			hits = append(hits, SearchHit{Session: id, Request: entry.Requests[pos], Matched: terms})
+		}
+	}
+	return hits
+}
+
+// TermUsage counts the requests, endpoints and sessions of every term of a
+// kind, most used first
+func (ix *SearchIndex) TermUsage(kind string) []TermUsage {
+	type usage struct {
+		requests  int
+		endpoints map[string]bool
+		sessions  []string
+	}
+	byTerm := make(map[string]*usage)
+	for _, id := range ix.sessionIDs() {
+		entry := ix.Sessions[id]
+		for term, positions := range entry.Terms[kind] {
+			u := byTerm[term]
+			if u == nil {
+				u = &usage{endpoints: make(map[string]bool)}
+				byTerm[term] = u
+			}
+			u.requests += len(positions)
+			u.sessions = append(u.sessions, id)
+			for _, pos := range positions {
+				req := entry.Requests[pos]
+				if parsed, err := url.Parse(req.URL); err == nil {
+					u.endpoints[req.Method+" "+parsed.Host+EndpointTemplate(parsed.Path)] = true
+				}
+			}
+		}
+	}
+
+	result := make([]TermUsage, 0, len(byTerm))
+	for term, u := range byTerm {
+		result = append(result, TermUsage{Term: term, Requests: u.requests, Endpoints: len(u.endpoints), Sessions: u.sessions})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Requests != result[j].Requests {
+			return result[i].Requests > result[j].Requests
+		}
+		return result[i].Term < result[j].Term
+	})
+	return result
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortFields lists the valid FilterOptions.SortBy values
+var SortFields = []string{"time", "size", "status", "url", "domain", "duration"}
+
+// ValidateSortField checks a sort field name ("" means capture order)
+func ValidateSortField(field string) error {
+	if field == "" {
+		return nil
+	}
+	for _, f := range SortFields {
+		if f == field {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid sort field: %s (use %s)", field, strings.Join(SortFields, ", "))
+}
+
+// SortRequests sorts requests in place by field (ties keep capture order)
+func SortRequests(reqs []Request, field string, desc bool) {
+	var less func(a, b *Request) bool
+	switch field {
+	case "time":
+		less = func(a, b *Request) bool { return a.Timestamp < b.Timestamp }
+	case "size":
+		less = func(a, b *Request) bool { return ResponseSize(a) < ResponseSize(b) }
+	case "status":
+		less = func(a, b *Request) bool { return responseStatus(a) < responseStatus(b) }
+	case "url":
+		less = func(a, b *Request) bool { return a.URL < b.URL }
+	case "domain":
+		less = func(a, b *Request) bool { return a.Domain < b.Domain }
+	case "duration":
+		less = func(a, b *Request) bool { return a.DurationMs < b.DurationMs }
+	default:
+		return
+	}
+	sort.SliceStable(reqs, func(i, j int) bool {
+		if desc {
+			return less(&reqs[j], &reqs[i])
+		}
+		return less(&reqs[i], &reqs[j])
+	})
+}
+
+// ResponseSize returns the response body length in bytes, accounting for
+// base64-encoded (binary) bodies
+func ResponseSize(req *Request) int {
+	if req.Response == nil {
+		return 0
+	}
+	if strings.EqualFold(req.ResponseEncoding, "base64") {
+		body := strings.TrimRight(req.Response.Body, "=")
+		return len(body) * 3 / 4
+	}
+	return len(req.Response.Body)
+}
+
+// ResponseBodyText returns the response body as text, decoding base64 bodies
+func ResponseBodyText(req *Request) string {
+	if req.Response == nil {
+		return ""
+	}
+	if strings.EqualFold(req.ResponseEncoding, "base64") {
+		if data, err := base64.StdEncoding.DecodeString(req.Response.Body); err == nil {
+			return string(data)
+		}
+	}
+	return req.Response.Body
+}
+
+func responseStatus(req *Request) int {
+	if req.Response == nil {
+		return 0
+	}
+	return req.Response.Status
+}
@@ -6,7 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,12 +22,17 @@ const (
 )
 
 // GetStorePath returns the path to the store directory following XDG spec
-// Uses ~/.local/share/rep-cli/
+// Uses ~/.local/share/rep-cli/ (%LOCALAPPDATA%\rep-cli on Windows)
 func GetStorePath() (string, error) {
 	// Check XDG_DATA_HOME first
 	if xdgData := os.Getenv("XDG_DATA_HOME"); xdgData != "" {
 		return filepath.Join(xdgData, "rep-cli"), nil
 	}
+	if runtime.GOOS == "windows" {
+		if localAppData := os.Getenv("LOCALAPPDATA"); localAppData != "" {
+			return filepath.Join(localAppData, "rep-cli"), nil
+		}
+	}
 	// Default to ~/.local/share/rep-cli
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -41,11 +48,23 @@ var (
 )
 
 // GetLiveFilePath returns the path where live data is exported.
-// REPLIVE_PATH overrides the default XDG/rep-cli location.
+// REPLIVE_PATH overrides the default XDG/rep-cli location. With a live
+// session selected (SelectLiveSession), it is that session's file.
 func GetLiveFilePath() (string, error) {
 	if override := os.Getenv("REPLIVE_PATH"); override != "" {
-		return expandHomePath(override)
+		if liveSelection != "" {
+			return "", fmt.Errorf("live session selection can't be combined with REPLIVE_PATH")
+		}
+		return ExpandHomePath(override)
 	}
+	if liveSelection != "" {
+		return selectedLiveFilePath()
+	}
+	return defaultLiveFilePath()
+}
+
+// defaultLiveFilePath is live.json in the store directory
+func defaultLiveFilePath() (string, error) {
 	storePath, err := GetStorePath()
 	if err != nil {
 		return "", err
@@ -53,8 +72,9 @@ func GetLiveFilePath() (string, error) {
 	return filepath.Join(storePath, LiveFileName), nil
 }
 
-func expandHomePath(path string) (string, error) {
-	if path == "~" || strings.HasPrefix(path, "~/") {
+// ExpandHomePath expands a leading ~ (~/ or ~\ on Windows) to the home directory
+func ExpandHomePath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") || (runtime.GOOS == "windows" && strings.HasPrefix(path, `~\`)) {
 		home, err := os.UserHomeDir()
 		if err != nil {
 			return "", fmt.Errorf("failed to get home directory: %w", err)
@@ -193,8 +213,17 @@ func ComputeRequestFields(req *Request) {
 }
 
 
-// Save saves the store to disk
+// Save saves the store to disk and updates the search index. The index is
+// a cache: when updating it fails, the next cross-session query rebuilds it.
 func (s *Store) Save() error {
+	if err := s.save(); err != nil {
+		return err
+	}
+	_, _ = s.UpdateSearchIndex()
+	return nil
+}
+
+func (s *Store) save() error {
 	mu.Lock()
 	defer mu.Unlock()
 
@@ -212,7 +241,7 @@ func (s *Store) Save() error {
 		return fmt.Errorf("failed to marshal store: %w", err)
 	}
 
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	if err := WriteFileAtomic(filePath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write store: %w", err)
 	}
 
@@ -234,6 +263,7 @@ func (s *Store) ClearAll() {
 	s.IgnoredDomains = make(map[string]bool)
 	s.MutedPaths = nil
 	s.PrimaryDomains = make(map[string]bool)
+	s.DomainGroups = nil
 }
 
 // GenerateSessionID creates an agent-friendly session ID
@@ -284,8 +314,70 @@ func (s *Store) AddSession(id string, note string, requests []Request) *Session
 	return &s.Sessions[len(s.Sessions)-1]
 }
 
-// GetSession returns a session by ID (exact or prefix match)
+// DeleteSessions removes sessions by exact ID. Returns the number removed.
+func (s *Store) DeleteSessions(ids ...string) int {
+	mu.Lock()
+	defer mu.Unlock()
+
+	drop := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		drop[id] = true
+	}
+	kept := s.Sessions[:0]
+	for _, sess := range s.Sessions {
+		if !drop[sess.ID] {
+			kept = append(kept, sess)
+		}
+	}
+	removed := len(s.Sessions) - len(kept)
+	s.Sessions = kept
+	return removed
+}
+
+// RemoveSessionRequests drops requests whose ID is in ids from a session
+// (exact ID). Returns the number removed.
+func (s *Store) RemoveSessionRequests(sessionID string, ids map[string]bool) int {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for i := range s.Sessions {
+		if s.Sessions[i].ID != sessionID {
+			continue
+		}
+		var removed int
+		s.Sessions[i].Requests, removed = RemoveRequests(s.Sessions[i].Requests, ids)
+		return removed
+	}
+	return 0
+}
+
+// RemoveRequests returns requests without those whose ID is in ids, and the
+// number removed
+func RemoveRequests(requests []Request, ids map[string]bool) ([]Request, int) {
+	kept := make([]Request, 0, len(requests))
+	for _, req := range requests {
+		if !ids[req.ID] {
+			kept = append(kept, req)
+		}
+	}
+	return kept, len(requests) - len(kept)
+}
+
+// GetSession returns a session by ID (exact or prefix match). Archived
+// sessions are loaded from disk on demand when no live session matches.
 func (s *Store) GetSession(id string) *Session {
+	if session := s.getStoredSession(id); session != nil {
+		return session
+	}
+	session, err := s.LoadArchivedSession(id)
+	if err != nil {
+		return nil
+	}
+	return session
+}
+
+// getStoredSession finds a session held in store.json (exact, then prefix)
+func (s *Store) getStoredSession(id string) *Session {
 	mu.RLock()
 	defer mu.RUnlock()
 
@@ -447,160 +539,266 @@ func (s *Store) GetRequestFromSessions(id string) *Request {
 	return nil
 }
 
-// Filter returns requests matching the filter options
-func (s *Store) Filter(opts FilterOptions) []Request {
-	mu.RLock()
-	defer mu.RUnlock()
+// requestMatcher holds FilterOptions with their patterns compiled once
+type requestMatcher struct {
+	opts           FilterOptions
+	pattern        string
+	patternRE      *regexp.Regexp
+	patternLower   string
+	excludePattern string
+	excludeRE      *regexp.Regexp
+	bodyMatch      func(string) bool
+	respBodyMatch  func(string) bool
+}
 
-	var result []Request
-	pattern := strings.TrimSpace(opts.Pattern)
-	var patternRE *regexp.Regexp
-	var patternLower string
-	if pattern != "" {
-		if re, err := regexp.Compile(pattern); err == nil {
-			patternRE = re
+func newRequestMatcher(opts FilterOptions) *requestMatcher {
+	m := &requestMatcher{opts: opts}
+	m.pattern = strings.TrimSpace(opts.Pattern)
+	if m.pattern != "" {
+		if re, err := regexp.Compile(m.pattern); err == nil {
+			m.patternRE = re
 		} else {
-			patternLower = strings.ToLower(pattern)
+			m.patternLower = strings.ToLower(m.pattern)
 		}
 	}
 
-	for _, req := range s.Requests {
-		// Skip ignored domains
-		if opts.ExcludeIgnored && s.IgnoredDomains[req.Domain] {
-			continue
-		}
+	m.excludePattern = strings.TrimSpace(opts.ExcludePattern)
+	if m.excludePattern != "" {
+		m.excludeRE, _ = regexp.Compile(m.excludePattern)
+	}
 
-		// Skip muted paths (check without lock since we already have RLock)
-		if opts.ExcludeIgnored && s.isMutedInternal(req.Domain, req.Path) {
-			continue
-		}
+	m.bodyMatch = newTextMatcher(opts.BodyPattern)
+	m.respBodyMatch = newTextMatcher(opts.ResponseBodyPattern)
+	return m
+}
 
-		// Primary only filter
-		if opts.PrimaryOnly && !s.PrimaryDomains[req.Domain] {
-			continue
-		}
+// matchRequest reports whether req passes every filter in m (offset, limit
+// and sorting are applied by the caller). Caller holds mu.
+func (s *Store) matchRequest(m *requestMatcher, req *Request) bool {
+	opts := m.opts
+	pattern, patternRE, patternLower := m.pattern, m.patternRE, m.patternLower
+	excludePattern, excludeRE := m.excludePattern, m.excludeRE
+	bodyMatch, respBodyMatch := m.bodyMatch, m.respBodyMatch
 
-		// Filter by domain
-		if opts.Domain != "" && !strings.EqualFold(req.Domain, opts.Domain) {
-			continue
-		}
+	// Skip ignored domains
+	if opts.ExcludeIgnored && s.IgnoredDomains[req.Domain] {
+		return false
+	}
 
-		// Filter by domains list
-		if len(opts.Domains) > 0 {
-			found := false
-			for _, d := range opts.Domains {
-				if strings.EqualFold(req.Domain, d) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
+	// Skip muted paths (check without lock since we already have RLock)
+	if opts.ExcludeIgnored && s.isMutedInternal(req.Domain, req.Path) {
+		return false
+	}
+
+	// Primary only filter
+	if opts.PrimaryOnly && !s.PrimaryDomains[req.Domain] {
+		return false
+	}
+
+	// Filter by domain
+	if opts.Domain != "" && !strings.EqualFold(req.Domain, opts.Domain) {
+		return false
+	}
+
+	// Filter by domains list
+	if len(opts.Domains) > 0 {
+		found := false
+		for _, d := range opts.Domains {
+			if strings.EqualFold(req.Domain, d) {
+				found = true
+				break
 			}
 		}
-
-		// Filter by method
-		if opts.Method != "" && !strings.EqualFold(req.Method, opts.Method) {
-			continue
+		if !found {
+			return false
 		}
+	}
 
-		// Filter by methods list
-		if len(opts.Methods) > 0 {
-			found := false
-			for _, m := range opts.Methods {
-				if strings.EqualFold(req.Method, m) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
+	// Filter by method
+	if opts.Method != "" && !strings.EqualFold(req.Method, opts.Method) {
+		return false
+	}
+
+	// Filter by methods list
+	if len(opts.Methods) > 0 {
+		found := false
+		for _, m := range opts.Methods {
+			if strings.EqualFold(req.Method, m) {
+				found = true
+				break
 			}
 		}
+		if !found {
+			return false
+		}
+	}
 
-		// Filter by status
-		if opts.Status != 0 && (req.Response == nil || req.Response.Status != opts.Status) {
-			continue
+	// Filter by status
+	if opts.Status != 0 && (req.Response == nil || req.Response.Status != opts.Status) {
+		return false
+	}
+
+	// Filter by status range (e.g., "4xx", "5xx")
+	if opts.StatusRange != "" && req.Response != nil {
+		status := req.Response.Status
+		switch opts.StatusRange {
+		case "2xx":
+			if status < 200 || status >= 300 {
+				return false
+			}
+		case "3xx":
+			if status < 300 || status >= 400 {
+				return false
+			}
+		case "4xx":
+			if status < 400 || status >= 500 {
+				return false
+			}
+		case "5xx":
+			if status < 500 || status >= 600 {
+				return false
+			}
 		}
+	}
 
-		// Filter by status range (e.g., "4xx", "5xx")
-		if opts.StatusRange != "" && req.Response != nil {
-			status := req.Response.Status
-			switch opts.StatusRange {
+	// Filter by multiple status ranges (e.g., ["4xx", "5xx"])
+	if len(opts.StatusRanges) > 0 && req.Response != nil {
+		status := req.Response.Status
+		matched := false
+		for _, sr := range opts.StatusRanges {
+			switch sr {
 			case "2xx":
-				if status < 200 || status >= 300 {
-					continue
+				if status >= 200 && status < 300 {
+					matched = true
 				}
 			case "3xx":
-				if status < 300 || status >= 400 {
-					continue
+				if status >= 300 && status < 400 {
+					matched = true
 				}
 			case "4xx":
-				if status < 400 || status >= 500 {
-					continue
+				if status >= 400 && status < 500 {
+					matched = true
 				}
 			case "5xx":
-				if status < 500 || status >= 600 {
-					continue
+				if status >= 500 && status < 600 {
+					matched = true
 				}
 			}
+			if matched {
+				break
+			}
+		}
+		if !matched {
+			return false
 		}
+	}
 
-		// Filter by multiple status ranges (e.g., ["4xx", "5xx"])
-		if len(opts.StatusRanges) > 0 && req.Response != nil {
-			status := req.Response.Status
-			matched := false
-			for _, sr := range opts.StatusRanges {
-				switch sr {
-				case "2xx":
-					if status >= 200 && status < 300 {
-						matched = true
-					}
-				case "3xx":
-					if status >= 300 && status < 400 {
-						matched = true
-					}
-				case "4xx":
-					if status >= 400 && status < 500 {
-						matched = true
-					}
-				case "5xx":
-					if status >= 500 && status < 600 {
-						matched = true
-					}
-				}
-				if matched {
-					break
-				}
+	// Filter by resource types (e.g., ["script", "xhr", "fetch"])
+	if len(opts.ResourceTypes) > 0 {
+		found := false
+		for _, rt := range opts.ResourceTypes {
+			if strings.EqualFold(req.ResourceType, rt) {
+				found = true
+				break
 			}
-			if !matched {
-				continue
+		}
+		if !found {
+			return false
+		}
+	}
+
+	// URL pattern filter (regex, fallback to substring)
+	if pattern != "" {
+		if patternRE != nil {
+			if !patternRE.MatchString(req.URL) {
+				return false
 			}
+		} else if !strings.Contains(strings.ToLower(req.URL), patternLower) {
+			return false
 		}
+	}
 
-		// Filter by resource types (e.g., ["script", "xhr", "fetch"])
-		if len(opts.ResourceTypes) > 0 {
-			found := false
-			for _, rt := range opts.ResourceTypes {
-				if strings.EqualFold(req.ResourceType, rt) {
-					found = true
-					break
-				}
+	// Exclusions
+	if containsFold(opts.ExcludeDomains, req.Domain) || containsFold(opts.ExcludeMethods, req.Method) {
+		return false
+	}
+	if len(opts.ExcludeResourceTypes) > 0 && containsFold(opts.ExcludeResourceTypes, req.ResourceType) {
+		return false
+	}
+	if len(opts.ExcludeStatusRanges) > 0 && req.Response != nil {
+		excluded := false
+		for _, sr := range opts.ExcludeStatusRanges {
+			if MatchStatusRange(req.Response.Status, sr) {
+				excluded = true
+				break
 			}
-			if !found {
-				continue
+		}
+		if excluded {
+			return false
+		}
+	}
+	if excludePattern != "" {
+		if excludeRE != nil {
+			if excludeRE.MatchString(req.URL) {
+				return false
 			}
+		} else if strings.Contains(strings.ToLower(req.URL), strings.ToLower(excludePattern)) {
+			return false
 		}
+	}
 
-		// URL pattern filter (regex, fallback to substring)
-		if pattern != "" {
-			if patternRE != nil {
-				if !patternRE.MatchString(req.URL) {
-					continue
-				}
-			} else if !strings.Contains(strings.ToLower(req.URL), patternLower) {
-				continue
-			}
+	// Header matches
+	if !matchAllHeaders(opts.RequestHeaders, req.Headers) {
+		return false
+	}
+	if len(opts.ResponseHeaders) > 0 && (req.Response == nil || !matchAllHeaders(opts.ResponseHeaders, req.Response.Headers)) {
+		return false
+	}
+
+	// Body content
+	if bodyMatch != nil && !bodyMatch(req.Body) {
+		return false
+	}
+	if respBodyMatch != nil && (req.Response == nil || !respBodyMatch(ResponseBodyText(req))) {
+		return false
+	}
+
+	// Response size
+	if opts.MinSize > 0 || opts.MaxSizeSet {
+		size := ResponseSize(req)
+		if size < opts.MinSize || (opts.MaxSizeSet && size > opts.MaxSize) {
+			return false
+		}
+	}
+
+	// Time window
+	if opts.Since > 0 && req.Timestamp < opts.Since {
+		return false
+	}
+	if opts.Until > 0 && req.Timestamp > opts.Until {
+		return false
+	}
+
+	return true
+}
+
+// Filter returns requests matching the filter options
+func (s *Store) Filter(opts FilterOptions) []Request {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	var result []Request
+	m := newRequestMatcher(opts)
+
+	for _, req := range s.Requests {
+		if !s.matchRequest(m, &req) {
+			continue
+		}
+
+		// Sorting needs every match before offset/limit apply
+		if opts.SortBy != "" {
+			result = append(result, req)
+			continue
 		}
 
 		// Apply offset
@@ -617,9 +815,70 @@ func (s *Store) Filter(opts FilterOptions) []Request {
 		}
 	}
 
+	if opts.SortBy != "" {
+		SortRequests(result, opts.SortBy, opts.SortDesc)
+		if opts.Offset > 0 {
+			if opts.Offset >= len(result) {
+				return nil
+			}
+			result = result[opts.Offset:]
+		}
+		if opts.Limit > 0 && len(result) > opts.Limit {
+			result = result[:opts.Limit]
+		}
+	}
+
 	return result
 }
 
+// MatchStatusRange reports whether status falls in a range like "4xx"
+// (an exact code like "404" also works)
+func MatchStatusRange(status int, statusRange string) bool {
+	statusRange = strings.ToLower(strings.TrimSpace(statusRange))
+	if len(statusRange) == 3 && strings.HasSuffix(statusRange, "xx") {
+		base := int(statusRange[0]-'0') * 100
+		return status >= base && status < base+100
+	}
+	return statusRange == strconv.Itoa(status)
+}
+
+// newTextMatcher returns a regex matcher for pattern, falling back to a
+// case-insensitive substring match when it is not a valid regex. Returns nil
+// for an empty pattern.
+func newTextMatcher(pattern string) func(string) bool {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil
+	}
+	if re, err := regexp.Compile(pattern); err == nil {
+		return re.MatchString
+	}
+	lower := strings.ToLower(pattern)
+	return func(text string) bool {
+		return strings.Contains(strings.ToLower(text), lower)
+	}
+}
+
+// matchAllHeaders reports whether headers satisfy every match
+func matchAllHeaders(matches []HeaderMatch, headers HeaderMap) bool {
+	for _, m := range matches {
+		if !m.Matches(headers) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold reports whether list contains value (case-insensitive)
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetDomains returns all unique domains with their info
 func (s *Store) GetDomains() []DomainInfo {
 	mu.RLock()
@@ -0,0 +1,335 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+)
+
+// StreamOptions controls FilterStream
+type StreamOptions struct {
+	SkipBodies bool   // Don't keep request/response bodies (meta output)
+	CountAll   bool   // Keep scanning after Limit to count every match
+	Path       string // File being read; lets skipped bodies be loaded later
+	// Emit, when set, receives each match (after offset/limit and sorting)
+	// instead of StreamResult.Requests, so callers can write matches out
+	// while the file is still being read
+	Emit func(req *Request) error
+}
+
+// StreamResult is what FilterStream found
+type StreamResult struct {
+	Requests []Request // Matches after offset/limit (and sorting)
+	Matched  int       // Matches seen; all of them when CountAll or no limit
+	Scanned  int       // Requests decoded
+	Emitted  int       // Matches passed to StreamOptions.Emit
+}
+
+// NeedsBodies reports whether opts inspect bodies (so they can't be skipped)
+func (opts FilterOptions) NeedsBodies() bool {
+	return opts.MinSize > 0 || opts.MaxSizeSet || opts.BodyPattern != "" ||
+		opts.ResponseBodyPattern != "" || opts.SortBy == "size"
+}
+
+// FilterStream decodes an export (live.json format) from r one request at a
+// time and applies opts while parsing, so memory holds only the matches.
+// Without sorting or CountAll, reading stops once Limit matches are found.
+// The store supplies the ignore/primary/mute lists; its Requests are unused.
+func (s *Store) FilterStream(r io.Reader, opts FilterOptions, sopts StreamOptions) (StreamResult, error) {
+	var res StreamResult
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return res, err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return res, err
+		}
+		if key, _ := tok.(string); key != "requests" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return res, err
+			}
+			continue
+		}
+
+		if tok, err := dec.Token(); err != nil {
+			return res, err
+		} else if tok == nil {
+			continue // "requests": null
+		} else if d, ok := tok.(json.Delim); !ok || d != '[' {
+			return res, fmt.Errorf("requests: expected array")
+		}
+
+		done, err := s.filterStreamRequests(dec, opts, sopts, &res)
+		if err != nil || done {
+			return res, err
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return res, err
+		}
+	}
+
+	if opts.SortBy != "" {
+		SortRequests(res.Requests, opts.SortBy, opts.SortDesc)
+		if opts.Offset > 0 {
+			if opts.Offset >= len(res.Requests) {
+				res.Requests = nil
+			} else {
+				res.Requests = res.Requests[opts.Offset:]
+			}
+		}
+		if opts.Limit > 0 && len(res.Requests) > opts.Limit {
+			res.Requests = res.Requests[:opts.Limit]
+		}
+		if sopts.Emit != nil {
+			return res, emitRequests(&res, sopts.Emit)
+		}
+	}
+	return res, nil
+}
+
+// emitRequests passes the collected matches to emit and drops them
+func emitRequests(res *StreamResult, emit func(req *Request) error) error {
+	requests := res.Requests
+	res.Requests = nil
+	for i := range requests {
+		if err := emit(&requests[i]); err != nil {
+			return err
+		}
+		res.Emitted++
+	}
+	return nil
+}
+
+// FilterStreamFiles runs FilterStream over one or more export files (one per
+// live session) and merges the matches in capture order (MergeLiveRequests)
+// as if they were a single file. Unreadable files are skipped unless all are.
+func (s *Store) FilterStreamFiles(paths []string, opts FilterOptions, sopts StreamOptions) (StreamResult, error) {
+	if len(paths) == 1 {
+		source := LiveSourceName(paths[0])
+		if emit := sopts.Emit; emit != nil && source != "" {
+			sopts.Emit = func(req *Request) error {
+				prefixLiveID(source, req)
+				return emit(req)
+			}
+		}
+		res, err := s.filterStreamFile(paths[0], opts, sopts)
+		if err == nil && source != "" {
+			// Already sorted and paged by filterStreamFile
+			for i := range res.Requests {
+				prefixLiveID(source, &res.Requests[i])
+			}
+		}
+		return res, err
+	}
+
+	// Matches are merged in capture order before they can be emitted
+	emit := sopts.Emit
+	sopts.Emit = nil
+
+	// Each file yields enough matches for the merged page; sorting and
+	// paging happen after the merge
+	fileOpts := opts
+	fileOpts.Offset = 0
+	fileOpts.Limit = 0
+	fileOpts.SortBy = ""
+	if opts.SortBy == "" && opts.Limit > 0 {
+		fileOpts.Limit = opts.Offset + opts.Limit
+	}
+
+	var res StreamResult
+	var sources []string
+	var sets [][]Request
+	var firstErr error
+	for _, path := range paths {
+		r, err := s.filterStreamFile(path, fileOpts, sopts)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		res.Matched += r.Matched
+		res.Scanned += r.Scanned
+		sources = append(sources, LiveSourceName(path))
+		sets = append(sets, r.Requests)
+	}
+	if len(sets) == 0 {
+		return res, firstErr
+	}
+
+	merged := MergeLiveRequests(sources, sets)
+	if opts.SortBy != "" {
+		SortRequests(merged, opts.SortBy, opts.SortDesc)
+	}
+	if opts.Offset > 0 {
+		if opts.Offset >= len(merged) {
+			merged = nil
+		} else {
+			merged = merged[opts.Offset:]
+		}
+	}
+	if opts.Limit > 0 && len(merged) > opts.Limit {
+		merged = merged[:opts.Limit]
+	}
+	res.Requests = merged
+	if emit != nil {
+		return res, emitRequests(&res, emit)
+	}
+	return res, nil
+}
+
+// filterStreamFile is FilterStream over a file, recording it for BodyRefs
+func (s *Store) filterStreamFile(path string, opts FilterOptions, sopts StreamOptions) (StreamResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return StreamResult{}, err
+	}
+	defer f.Close()
+	if sopts.SkipBodies {
+		sopts.Path = path
+	}
+	return s.FilterStream(bufio.NewReaderSize(f, 1<<20), opts, sopts)
+}
+
+// filterStreamRequests consumes array elements up to (not including) the
+// closing bracket. Returns done=true when it stopped early at the limit.
+func (s *Store) filterStreamRequests(dec *json.Decoder, opts FilterOptions, sopts StreamOptions, res *StreamResult) (bool, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	m := newRequestMatcher(opts)
+	offset := opts.Offset
+	kept := len(res.Requests) + res.Emitted
+	for dec.More() {
+		var req Request
+		if sopts.SkipBodies {
+			var meta streamRequestMeta
+			start := dec.InputOffset()
+			if err := dec.Decode(&meta); err != nil {
+				return false, err
+			}
+			req = meta.request()
+			if sopts.Path != "" {
+				req.BodyRef = &BodyRef{Path: sopts.Path, Offset: start, Length: dec.InputOffset() - start}
+			}
+		} else if err := dec.Decode(&req); err != nil {
+			return false, err
+		}
+		res.Scanned++
+		// Same Domain/Path as NewTempStore (path without query)
+		if parsed, err := url.Parse(req.URL); err == nil {
+			req.Domain = parsed.Host
+			req.Path = parsed.Path
+		}
+
+		if !s.matchRequest(m, &req) {
+			continue
+		}
+		res.Matched++
+
+		if opts.SortBy != "" {
+			res.Requests = append(res.Requests, req)
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		if opts.Limit > 0 && kept >= opts.Limit {
+			if !sopts.CountAll {
+				return true, nil
+			}
+			continue
+		}
+		if sopts.Emit != nil {
+			if err := sopts.Emit(&req); err != nil {
+				return false, err
+			}
+			res.Emitted++
+		} else {
+			res.Requests = append(res.Requests, req)
+		}
+		kept++
+		if opts.Limit > 0 && kept >= opts.Limit && !sopts.CountAll {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("invalid export: expected %q", want)
+	}
+	return nil
+}
+
+// skippedBody discards a JSON value without copying it
+type skippedBody struct{}
+
+func (*skippedBody) UnmarshalJSON([]byte) error { return nil }
+
+// streamRequestMeta mirrors Request without bodies
+type streamRequestMeta struct {
+	ID               string              `json:"id"`
+	OriginalID       string              `json:"original_id"`
+	Method           string              `json:"method"`
+	URL              string              `json:"url"`
+	PageURL          string              `json:"page_url"`
+	ResourceType     string              `json:"resource_type"`
+	Initiator        string              `json:"initiator"`
+	Headers          HeaderMap           `json:"headers"`
+	Body             skippedBody         `json:"body"`
+	Response         *streamResponseMeta `json:"response"`
+	ResponseEncoding string              `json:"response_encoding"`
+	Timestamp        int64               `json:"timestamp"`
+	DurationMs       float64             `json:"duration_ms"`
+	RequestBytes     int64               `json:"request_bytes"`
+	ResponseBytes    int64               `json:"response_bytes"`
+	RemoteIP         string              `json:"remote_ip"`
+	Protocol         string              `json:"protocol"`
+	TLS              *TLSInfo            `json:"tls"`
+}
+
+type streamResponseMeta struct {
+	Status  int         `json:"status"`
+	Headers HeaderMap   `json:"headers"`
+	Body    skippedBody `json:"body"`
+	Events  skippedBody `json:"events"`
+}
+
+func (m *streamRequestMeta) request() Request {
+	req := Request{
+		ID:               m.ID,
+		OriginalID:       m.OriginalID,
+		Method:           m.Method,
+		URL:              m.URL,
+		PageURL:          m.PageURL,
+		ResourceType:     m.ResourceType,
+		Initiator:        m.Initiator,
+		Headers:          m.Headers,
+		ResponseEncoding: m.ResponseEncoding,
+		Timestamp:        m.Timestamp,
+		DurationMs:       m.DurationMs,
+		RequestBytes:     m.RequestBytes,
+		ResponseBytes:    m.ResponseBytes,
+		RemoteIP:         m.RemoteIP,
+		Protocol:         m.Protocol,
+		TLS:              m.TLS,
+	}
+	if m.Response != nil {
+		req.Response = &Response{Status: m.Response.Status, Headers: m.Response.Headers}
+	}
+	return req
+}
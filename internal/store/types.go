@@ -15,9 +15,35 @@ type Request struct {
 	Response         *Response `json:"response,omitempty"`
 	ResponseEncoding string    `json:"response_encoding,omitempty"`
 	Timestamp        int64     `json:"timestamp"`
+
+	// Timing and transport details, when the extension provides them
+	DurationMs    float64  `json:"duration_ms,omitempty"`    // Request start to response end
+	RequestBytes  int64    `json:"request_bytes,omitempty"`  // Bytes sent, headers included
+	ResponseBytes int64    `json:"response_bytes,omitempty"` // Bytes received on the wire (compressed)
+	RemoteIP      string   `json:"remote_ip,omitempty"`
+	Protocol      string   `json:"protocol,omitempty"` // e.g. http/1.1, h2, h3
+	TLS           *TLSInfo `json:"tls,omitempty"`
+
 	// Computed fields (not from export)
 	Domain string `json:"-"`
 	Path   string `json:"-"`
+
+	// Set when the request was read without bodies, which are then empty
+	// until LoadBodies; see BodiesDeferred
+	BodyRef *BodyRef `json:"-"`
+}
+
+// TLSInfo describes the connection's TLS session and the server
+// certificate, when the extension or an import provides them
+type TLSInfo struct {
+	Version   string   `json:"version,omitempty"` // e.g. TLS 1.3
+	Cipher    string   `json:"cipher,omitempty"`
+	ALPN      string   `json:"alpn,omitempty"`    // Negotiated protocol: h2, http/1.1
+	Subject   string   `json:"subject,omitempty"` // Certificate subject common name
+	Issuer    string   `json:"issuer,omitempty"`
+	SANs      []string `json:"sans,omitempty"`       // Subject alternative names
+	ValidFrom int64    `json:"valid_from,omitempty"` // Unix seconds
+	ValidTo   int64    `json:"valid_to,omitempty"`
 }
 
 // Response represents an HTTP response
@@ -25,6 +51,17 @@ type Response struct {
 	Status  int       `json:"status"`
 	Headers HeaderMap `json:"headers,omitempty"`
 	Body    string    `json:"body,omitempty"`
+
+	// Chunks of a streaming response (SSE, chunked long-poll) in arrival order
+	Events []StreamEvent `json:"events,omitempty"`
+}
+
+// StreamEvent is one chunk of a streaming response as it arrived
+type StreamEvent struct {
+	Timestamp int64  `json:"timestamp"` // Unix millis
+	Data      string `json:"data"`
+	From      string `json:"from,omitempty"`   // WebSocket messages: "client" or "server"
+	Binary    bool   `json:"binary,omitempty"` // Data is base64 (binary WebSocket frame)
 }
 
 // Export represents the JSON export format from rep+ extension
@@ -42,6 +79,18 @@ type Session struct {
 	Requests  []Request `json:"requests"`
 }
 
+// SessionExportFormat identifies a single-session file
+const SessionExportFormat = "rep-session"
+
+// SessionExport is the file written by 'rep sessions export': one saved
+// session, importable with 'rep import'
+type SessionExport struct {
+	Format     string  `json:"format"` // Always SessionExportFormat
+	Version    string  `json:"version"`
+	ExportedAt string  `json:"exported_at"`
+	Session    Session `json:"session"`
+}
+
 // MutedPath represents a path pattern to mute (fine-grained noise filtering)
 type MutedPath struct {
 	Domain  string `json:"domain"`  // Domain to match, or "*" for all domains
@@ -50,13 +99,28 @@ type MutedPath struct {
 
 // Store holds saved sessions and configuration
 type Store struct {
-	Sessions       []Session       `json:"sessions"`
-	IgnoredDomains map[string]bool `json:"ignored_domains"`
-	PrimaryDomains map[string]bool `json:"primary_domains"`
-	MutedPaths     []MutedPath     `json:"muted_paths,omitempty"`
+	Sessions       []Session           `json:"sessions"`
+	IgnoredDomains map[string]bool     `json:"ignored_domains"`
+	PrimaryDomains map[string]bool     `json:"primary_domains"`
+	MutedPaths     []MutedPath         `json:"muted_paths,omitempty"`
+	DomainGroups   map[string][]string `json:"domain_groups,omitempty"` // Named domain lists, used as -d @name
+	Archived       []ArchivedSession   `json:"archived,omitempty"`      // Sessions moved to archive/ by 'rep archive'
 	// Legacy fields for migration (will be removed after migration)
 	Requests   []Request `json:"requests,omitempty"`
 	LastImport int64     `json:"last_import,omitempty"`
+
+	archiveCache map[string]*Session // Archived sessions loaded this run
+}
+
+// ArchivedSession is the store.json index entry for a session whose requests
+// live in a compressed file under the archive directory
+type ArchivedSession struct {
+	ID        string `json:"id"`
+	Timestamp int64  `json:"timestamp"`
+	Note      string `json:"note,omitempty"`
+	Requests  int    `json:"requests"` // Request count
+	File      string `json:"file"`     // File name inside the archive directory
+	Bytes     int64  `json:"bytes"`    // Compressed size
 }
 
 // OutputMode controls how much detail to show
@@ -80,10 +144,34 @@ type FilterOptions struct {
 	StatusRanges   []string // Multiple ranges like ["4xx", "5xx"]
 	ResourceTypes  []string // Filter by resource type (script, xhr, fetch, etc.)
 	Pattern        string   // regex pattern for URL
+	Since          int64    // Unix millis, only requests at or after (0 = no bound)
+	Until          int64    // Unix millis, only requests at or before (0 = no bound)
 	ExcludeIgnored bool
 	PrimaryOnly    bool
+	SortBy         string // time, size, status, url, domain ("" = capture order)
+	SortDesc       bool
 	Limit          int
 	Offset         int
+
+	// Exclusions (applied after the positive filters)
+	ExcludeDomains       []string
+	ExcludeMethods       []string
+	ExcludeResourceTypes []string
+	ExcludeStatusRanges  []string
+	ExcludePattern       string // regex (substring fallback) matched against the URL
+
+	// Response body size bounds in bytes (inclusive)
+	MinSize    int
+	MaxSize    int
+	MaxSizeSet bool // MaxSize applies (so --max-size 0 finds empty bodies)
+
+	// Header matches; all must match
+	RequestHeaders  []HeaderMatch
+	ResponseHeaders []HeaderMatch
+
+	// Body content regexes (substring fallback)
+	BodyPattern         string // Request body
+	ResponseBodyPattern string // Response body (base64 bodies are decoded)
 }
 
 // PageFlowInfo represents requests grouped by PageURL for cross-domain analysis
@@ -104,17 +192,36 @@ type DomainInfo struct {
 	IsPrimary    bool
 }
 
+// TruncateMode selects how oversized bodies are shortened
+type TruncateMode string
+
+const (
+	TruncateHead     TruncateMode = "head"      // First N chars; JSON shortened as valid JSON (default)
+	TruncateHeadTail TruncateMode = "head-tail" // First N chars … last M chars
+	TruncateStrings  TruncateMode = "strings"   // JSON with long string values elided
+	TruncateKeys     TruncateMode = "keys"      // JSON structure with values replaced by types
+)
+
+// TruncateModes lists the valid truncation modes in help order
+var TruncateModes = []TruncateMode{TruncateHead, TruncateHeadTail, TruncateStrings, TruncateKeys}
+
 // TruncateConfig controls body truncation
 type TruncateConfig struct {
-	MaxBodySize   int  // Max chars to show (default 500)
-	ShowFullSize  bool // Show total size in truncation message
-	BinaryAsLabel bool // Show "[BINARY: 12KB image/png]" for binary
+	MaxBodySize   int          // Max chars to show (default 500)
+	TailSize      int          // Chars kept from the end in head-tail mode (default 200)
+	MaxString     int          // Chars kept per JSON string in strings mode (default 80)
+	Mode          TruncateMode // head, head-tail, strings or keys (default head)
+	ShowFullSize  bool         // Show total size in truncation message
+	BinaryAsLabel bool         // Show "[BINARY: 12KB image/png]" for binary
 }
 
 // DefaultTruncateConfig returns sensible defaults for agent consumption
 func DefaultTruncateConfig() TruncateConfig {
 	return TruncateConfig{
 		MaxBodySize:   500,
+		TailSize:      200,
+		MaxString:     80,
+		Mode:          TruncateHead,
 		ShowFullSize:  true,
 		BinaryAsLabel: true,
 	}
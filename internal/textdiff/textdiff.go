@@ -0,0 +1,159 @@
+// Package textdiff computes line diffs of response bodies for display, in
+// the unified format of diff -u without file headers.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxCells bounds the lines(a) × lines(b) table of a diff; larger inputs
+// are reported as differing without a line diff
+const MaxCells = 4_000_000
+
+// Op is one line of an edit script
+type Op struct {
+	Kind byte // ' ' kept, '-' removed from a, '+' added in b
+	Line string
+}
+
+// Lines returns the edit script turning a into b, or false when the inputs
+// are too large to diff
+func Lines(a, b string) ([]Op, bool) {
+	al, bl := split(a), split(b)
+
+	// Common prefix and suffix need no table
+	prefix := 0
+	for prefix < len(al) && prefix < len(bl) && al[prefix] == bl[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(al)-prefix && suffix < len(bl)-prefix && al[len(al)-1-suffix] == bl[len(bl)-1-suffix] {
+		suffix++
+	}
+	am, bm := al[prefix:len(al)-suffix], bl[prefix:len(bl)-suffix]
+	if len(am)*len(bm) > MaxCells {
+		return nil, false
+	}
+
+	var ops []Op
+	for _, line := range al[:prefix] {
+		ops = append(ops, Op{' ', line})
+	}
+	ops = append(ops, lcsOps(am, bm)...)
+	for _, line := range al[len(al)-suffix:] {
+		ops = append(ops, Op{' ', line})
+	}
+	return ops, true
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lcsOps diffs a and b through their longest common subsequence
+func lcsOps(a, b []string) []Op {
+	n, m := len(a), len(b)
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []Op
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, Op{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, Op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, Op{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, Op{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, Op{'+', b[j]})
+	}
+	return ops
+}
+
+// Unified renders ops as hunks with context unchanged lines around each
+// change. No changes render as no lines.
+func Unified(ops []Op, context int) []string {
+	var out []string
+	aLine, bLine := 1, 1 // Line numbers of ops[i] in a and b
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == ' ' {
+			aLine++
+			bLine++
+			i++
+			continue
+		}
+		// A hunk starts context lines before the change and runs until
+		// more than 2*context unchanged lines follow a change
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end, kept := i, 0
+		for end < len(ops) && kept <= 2*context {
+			if ops[end].Kind == ' ' {
+				kept++
+			} else {
+				kept = 0
+			}
+			end++
+		}
+		if kept > context {
+			end -= kept - context
+		}
+
+		aStart, bStart := aLine-(i-start), bLine-(i-start)
+		aCount, bCount := 0, 0
+		var body []string
+		for _, op := range ops[start:end] {
+			if op.Kind != '+' {
+				aCount++
+			}
+			if op.Kind != '-' {
+				bCount++
+			}
+			body = append(body, string(op.Kind)+op.Line)
+		}
+		out = append(out, fmt.Sprintf("@@ -%d,%d +%d,%d @@", aStart, aCount, bStart, bCount))
+		out = append(out, body...)
+
+		for _, op := range ops[i:end] {
+			if op.Kind != '+' {
+				aLine++
+			}
+			if op.Kind != '-' {
+				bLine++
+			}
+		}
+		i = end
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+package triage
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// Error finding kinds, most revealing first
+const (
+	KindStackTrace    = "stack_trace"
+	KindSQLError      = "sql_error"
+	KindDebugOutput   = "debug_output"
+	KindFrameworkPage = "framework_page"
+	KindPathLeak      = "path_leak"
+	KindMessage       = "message"
+)
+
+// kindWeight ranks how much a finding reveals
+var kindWeight = map[string]int{
+	KindStackTrace:    5,
+	KindSQLError:      5,
+	KindDebugOutput:   4,
+	KindFrameworkPage: 2,
+	KindPathLeak:      2,
+	KindMessage:       1,
+}
+
+// ErrorInfo is what an error response gives away
+type ErrorInfo struct {
+	Technologies []string `json:"technologies"`    // Django, Rails, Spring, PHP, PostgreSQL, ...
+	Kinds        []string `json:"kinds"`           // stack_trace, sql_error, ...
+	Message      string   `json:"message"`         // Most telling line of the body
+	Paths        []string `json:"paths,omitempty"` // Server file paths in the body
+	Revealing    int      `json:"revealing"`       // Sum of finding weights; higher reveals more
+}
+
+// technology is one fingerprint: any body pattern (or header value match)
+// identifies it
+type technology struct {
+	name    string
+	body    *regexp.Regexp
+	headers map[string]*regexp.Regexp // Header name -> value pattern
+}
+
+var technologies = []technology{
+	{name: "Django", body: regexp.MustCompile(`(?i)django|DisallowedHost|you have DEBUG = True|Request Method:[\s\S]{0,200}?Django Version`)},
+	{name: "Flask", body: regexp.MustCompile(`Werkzeug Debugger|werkzeug\.exceptions|flask/app\.py`), headers: map[string]*regexp.Regexp{"server": regexp.MustCompile(`(?i)werkzeug`)}},
+	{name: "Python", body: regexp.MustCompile(`Traceback \(most recent call last\)|File "[^"]+\.py", line \d+`), headers: map[string]*regexp.Regexp{"server": regexp.MustCompile(`(?i)gunicorn|uvicorn|python`)}},
+	{name: "Rails", body: regexp.MustCompile("ActionController::|ActiveRecord::|ActionView::|Action Controller: Exception caught|\\.rb:\\d+:in `"), headers: map[string]*regexp.Regexp{"x-runtime": regexp.MustCompile(`.`), "server": regexp.MustCompile(`(?i)\b(?:puma|passenger|unicorn)\b`)}},
+	{name: "Spring", body: regexp.MustCompile(`Whitelabel Error Page|org\.springframework|"trace"\s*:\s*"(?:java|org)\.`)},
+	{name: "Java", body: regexp.MustCompile(`java\.lang\.|javax?\.servlet|Exception in thread|\bat [\w$.]+\([\w$]+\.java:\d+\)|Apache Tomcat/`)},
+	{name: "Laravel", body: regexp.MustCompile(`Illuminate\\|laravel|Whoops, looks like something went wrong`), headers: map[string]*regexp.Regexp{"set-cookie": regexp.MustCompile(`laravel_session`)}},
+	{name: "Symfony", body: regexp.MustCompile(`Symfony\\Component|symfony/`)},
+	{name: "PHP", body: regexp.MustCompile(`(?i)<b>(?:fatal error|parse error|warning|notice)</b>:|(?:Fatal|Parse) error: |\.php on line \d+|\.php:\d+|Stack trace:\s*#0|PHP (?:Fatal|Warning|Notice)`), headers: map[string]*regexp.Regexp{"x-powered-by": regexp.MustCompile(`(?i)php`)}},
+	{name: "ASP.NET", body: regexp.MustCompile(`Server Error in '[^']*' Application|System\.[A-Z]\w+Exception|at System\.|ASP\.NET is configured|__VIEWSTATE`), headers: map[string]*regexp.Regexp{"x-powered-by": regexp.MustCompile(`(?i)asp\.net`), "x-aspnet-version": regexp.MustCompile(`.`)}},
+	{name: "Node.js", body: regexp.MustCompile(`at Object\.<anonymous>|node_modules/|at Module\._compile|Cannot (?:GET|POST|PUT|DELETE) /`), headers: map[string]*regexp.Regexp{"x-powered-by": regexp.MustCompile(`(?i)express|next\.js`)}},
+	{name: "Go", body: regexp.MustCompile(`goroutine \d+ \[|panic: runtime error|\.go:\d+ \+0x`)},
+	{name: "PostgreSQL", body: regexp.MustCompile(`pq: |PG::\w+|psycopg2|syntax error at or near|PSQLException|postgres`)},
+	{name: "MySQL", body: regexp.MustCompile(`(?i)You have an error in your SQL syntax|mysql_|MySQLSyntaxErrorException|com\.mysql|SQLSTATE\[\w+\]`)},
+	{name: "MSSQL", body: regexp.MustCompile(`Unclosed quotation mark|Microsoft OLE DB|SqlException|\[SQL Server\]`)},
+	{name: "Oracle", body: regexp.MustCompile(`ORA-\d{5}|oracle\.jdbc`)},
+	{name: "SQLite", body: regexp.MustCompile(`sqlite3?\.\w+Error|SQLITE_ERROR|SQLiteException`)},
+}
+
+var (
+	sqlErrorPattern    = regexp.MustCompile(`(?i)SQL syntax|SQLSTATE|syntax error at or near|pq: |PG::\w+Error|ORA-\d{5}|Unclosed quotation mark|SQLITE_ERROR|sqlite3?\.\w+Error|SqlException|psycopg2\.\w+`)
+	debugOutputPattern = regexp.MustCompile(`(?i)you have DEBUG = True|Werkzeug Debugger|Whoops, looks like|var_dump|<pre class=["']?xdebug|Request Method:[\s\S]{0,200}?Request URL:|"(?:debug|exception|trace|stacktrace|stack)"\s*:\s*["\[{]`)
+	frameworkPage      = regexp.MustCompile(`Whitelabel Error Page|Server Error in '[^']*' Application|Action Controller: Exception caught|<title>[^<]*(?:Error|Exception)[^<]*</title>|Apache Tomcat/\d`)
+	serverPathPattern  = regexp.MustCompile(`(?:/(?:srv|var/www|home|usr/(?:local|share|lib)|opt|app|Users)/[\w.@-]+(?:/[\w.@-]+)+|[A-Z]:\\(?:[\w. -]+\\)+[\w. -]+\.\w+)`)
+	messageLine        = regexp.MustCompile(`(?i)\b(?:\w+(?:Exception|Error)\b[:\s]|error|exception|failed|invalid|denied|not found|unsupported)`)
+)
+
+// ClassifyError reports what an error response reveals: the technologies it
+// fingerprints, stack traces, SQL errors, debug pages and server paths
+func ClassifyError(req *store.Request) ErrorInfo {
+	info := ErrorInfo{Technologies: []string{}, Kinds: []string{}}
+	if req.Response == nil {
+		return info
+	}
+	body := store.ResponseBodyText(req)
+
+	for _, t := range technologies {
+		if body != "" && t.body != nil && t.body.MatchString(body) {
+			info.Technologies = append(info.Technologies, t.name)
+			continue
+		}
+		for name, pattern := range t.headers {
+			if value := store.HeaderFirst(req.Response.Headers, name); value != "" && pattern.MatchString(value) {
+				info.Technologies = append(info.Technologies, t.name)
+				break
+			}
+		}
+	}
+
+	if body == "" {
+		return info
+	}
+	addKind := func(kind string) {
+		info.Kinds = append(info.Kinds, kind)
+		info.Revealing += kindWeight[kind]
+	}
+	if firstMatch(stackTracePatterns, body) != "" {
+		addKind(KindStackTrace)
+	}
+	if sqlErrorPattern.MatchString(body) {
+		addKind(KindSQLError)
+	}
+	if debugOutputPattern.MatchString(body) {
+		addKind(KindDebugOutput)
+	}
+	if frameworkPage.MatchString(body) {
+		addKind(KindFrameworkPage)
+	}
+	seen := make(map[string]bool)
+	for _, p := range serverPathPattern.FindAllString(body, 20) {
+		if !seen[p] {
+			seen[p] = true
+			info.Paths = append(info.Paths, p)
+		}
+	}
+	if len(info.Paths) > 0 {
+		sort.Strings(info.Paths)
+		addKind(KindPathLeak)
+	}
+
+	info.Message = errorMessage(body)
+	if len(info.Kinds) == 0 && info.Message != "" {
+		addKind(KindMessage)
+	}
+	return info
+}
+
+// errorMessage picks the most telling line: the last line naming an
+// exception or error (tracebacks end with the exception), else the first
+// non-empty line
+func errorMessage(body string) string {
+	lines := strings.Split(body, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line != "" && messageLine.MatchString(line) && !strings.HasPrefix(line, "at ") {
+			return shortLine(line)
+		}
+	}
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			return shortLine(line)
+		}
+	}
+	return ""
+}
+
+func shortLine(line string) string {
+	line = strings.Join(strings.Fields(line), " ")
+	if len(line) > 200 {
+		line = line[:197] + "..."
+	}
+	return line
+}
@@ -0,0 +1,216 @@
+// Package triage ranks captured requests by how worth a look they are.
+// Each request collects weighted signals (credentials sent, admin paths,
+// verbose errors, stack traces, large or rare responses, ...); the score is
+// their sum. Signals carry a short explanation so an agent can tell why a
+// request ranked high without opening it.
+package triage
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// Signal weights
+const (
+	WeightAuth         = 10
+	WeightMutation     = 10
+	WeightAdminPath    = 20
+	WeightServerError  = 15
+	WeightAccessDenied = 10
+	WeightClientError  = 5
+	WeightVerboseError = 20
+	WeightStackTrace   = 25
+	WeightLarge        = 10
+	WeightHuge         = 15
+	WeightRare         = 5
+	WeightObjectID     = 5
+)
+
+// Response size thresholds in bytes
+const (
+	LargeResponse = 100 * 1024
+	HugeResponse  = 1024 * 1024
+)
+
+// rareMinRequests is how many requests a capture needs before a one-off
+// endpoint counts as rare
+const rareMinRequests = 10
+
+// Signal is one reason a request scored
+type Signal struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+	Detail string `json:"detail"`
+}
+
+// Score is the ranking of one request
+type Score struct {
+	Request *store.Request `json:"-"`
+	Score   int            `json:"score"`
+	Signals []Signal       `json:"signals"`
+}
+
+var (
+	adminPathPattern = regexp.MustCompile(`(?i)(?:^|[/_.-])(admin|administrator|internal|debug|manage(?:ment)?|config|private|staff|superuser|actuator|console|backoffice|swagger|metrics|env)(?:$|[/_.?-])`)
+
+	stackTracePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`Traceback \(most recent call last\)`),
+		regexp.MustCompile(`\n\s+at [\w$.<>]+\([\w$]+\.java:\d+\)`),
+		regexp.MustCompile(`\n\s+at .+ \(.+\.(?:js|ts|mjs):\d+:\d+\)`),
+		regexp.MustCompile(`\.(?:php|rb|py|go|cs):\d+`),
+		regexp.MustCompile(`(?i)stack ?trace:`),
+		regexp.MustCompile(`goroutine \d+ \[`),
+	}
+
+	verboseErrorPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)SQL syntax|SQLSTATE|syntax error at or near|pq: |ORA-\d{5}|sqlite3?\.|mysql_|unclosed quotation mark`),
+		regexp.MustCompile(`(?i)\b\w+(?:Exception|Error): `),
+		regexp.MustCompile(`(?i)undefined (?:index|variable|method)|null ?pointer|cannot read propert`),
+		regexp.MustCompile(`(?i)/(?:srv|var/www|home|usr|opt|app)/[\w./-]+`),
+	}
+
+	numericID = regexp.MustCompile(`^\d+$`)
+)
+
+// Scorer scores requests against the capture they came from (rarity is
+// relative to the other requests)
+type Scorer struct {
+	endpoints map[string]int
+	total     int
+}
+
+// NewScorer prepares endpoint counts for rarity over requests
+func NewScorer(requests []store.Request) *Scorer {
+	s := &Scorer{endpoints: make(map[string]int), total: len(requests)}
+	for i := range requests {
+		s.endpoints[endpointKey(&requests[i])]++
+	}
+	return s
+}
+
+// Rank scores requests and returns them highest first (capture order on
+// ties). Bodies must be loaded for the body signals.
+func Rank(requests []store.Request) []Score {
+	s := NewScorer(requests)
+	scores := make([]Score, len(requests))
+	for i := range requests {
+		scores[i] = s.Score(&requests[i])
+	}
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+	return scores
+}
+
+// Score computes the signals of one request
+func (s *Scorer) Score(req *store.Request) Score {
+	result := Score{Request: req, Signals: []Signal{}}
+	add := func(name string, weight int, detail string) {
+		result.Signals = append(result.Signals, Signal{Name: name, Weight: weight, Detail: detail})
+		result.Score += weight
+	}
+
+	if mechanism := credentials(req); mechanism != "" {
+		add("auth", WeightAuth, "sends "+mechanism)
+	}
+	switch strings.ToUpper(req.Method) {
+	case "POST", "PUT", "PATCH", "DELETE":
+		add("mutation", WeightMutation, req.Method+" changes state")
+	}
+
+	path := req.URL
+	if parsed, err := url.Parse(req.URL); err == nil {
+		path = parsed.Path
+	}
+	if m := adminPathPattern.FindStringSubmatch(path); m != nil {
+		add("admin_path", WeightAdminPath, fmt.Sprintf("path contains %q", strings.ToLower(m[1])))
+	}
+	for _, seg := range strings.Split(path, "/") {
+		if numericID.MatchString(seg) {
+			add("object_id", WeightObjectID, fmt.Sprintf("numeric id %s in path (IDOR candidate)", seg))
+			break
+		}
+	}
+
+	if req.Response != nil {
+		status := req.Response.Status
+		switch {
+		case status >= 500:
+			add("server_error", WeightServerError, fmt.Sprintf("%d response", status))
+		case status == 401 || status == 403:
+			add("access_denied", WeightAccessDenied, fmt.Sprintf("%d response (access control boundary)", status))
+		case status >= 400:
+			add("client_error", WeightClientError, fmt.Sprintf("%d response", status))
+		}
+
+		body := store.ResponseBodyText(req)
+		if m := firstMatch(stackTracePatterns, body); m != "" {
+			add("stack_trace", WeightStackTrace, "stack trace in body: "+excerpt(m))
+		} else if status >= 400 {
+			// Verbose errors only count on error responses; success bodies
+			// mention "Error" in too many harmless ways
+			if m := firstMatch(verboseErrorPatterns, body); m != "" {
+				add("verbose_error", WeightVerboseError, "error detail in body: "+excerpt(m))
+			}
+		}
+
+		size := store.ResponseSize(req)
+		switch {
+		case size >= HugeResponse:
+			add("large_response", WeightHuge, fmt.Sprintf("%d KB response", size/1024))
+		case size >= LargeResponse:
+			add("large_response", WeightLarge, fmt.Sprintf("%d KB response", size/1024))
+		}
+	}
+
+	if s.total >= rareMinRequests && s.endpoints[endpointKey(req)] == 1 {
+		add("rare_endpoint", WeightRare, fmt.Sprintf("only request to this endpoint (of %d)", s.total))
+	}
+	return result
+}
+
+// credentials names the credential a request sends ("" when none)
+func credentials(req *store.Request) string {
+	if value := store.HeaderFirst(req.Headers, "authorization"); value != "" {
+		scheme, _, _ := strings.Cut(value, " ")
+		return "Authorization: " + scheme
+	}
+	for _, name := range []string{"X-Api-Key", "X-Auth-Token", "X-Access-Token", "Cookie"} {
+		if store.HeaderFirst(req.Headers, name) != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func endpointKey(req *store.Request) string {
+	host, path := "", req.URL
+	if parsed, err := url.Parse(req.URL); err == nil {
+		host, path = parsed.Host, parsed.Path
+	}
+	return req.Method + " " + host + store.EndpointTemplate(path)
+}
+
+func firstMatch(patterns []*regexp.Regexp, text string) string {
+	if text == "" {
+		return ""
+	}
+	for _, p := range patterns {
+		if m := p.FindString(text); m != "" {
+			return m
+		}
+	}
+	return ""
+}
+
+// excerpt shortens a match to one line for a signal explanation
+func excerpt(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > 60 {
+		s = s[:57] + "..."
+	}
+	return s
+}
@@ -0,0 +1,134 @@
+// Package tui holds the terminal pieces behind 'rep tui', which runs on
+// bubbletea: the terminal device the UI draws on (so stdout stays free for
+// the tagged IDs), key names, the clipboard sequence and width-aware text
+// fitting.
+package tui
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// Key is one key press. Name is set for special keys (up, down, left, right,
+// pgup, pgdn, home, end, enter, esc, tab, backtab, backspace, ctrl-c);
+// otherwise Rune holds the typed character.
+type Key struct {
+	Name string
+	Rune rune
+}
+
+// keyNames maps the bubbletea key types the TUI binds to Key names
+var keyNames = map[tea.KeyType]string{
+	tea.KeyUp: "up", tea.KeyDown: "down", tea.KeyLeft: "left", tea.KeyRight: "right",
+	tea.KeyPgUp: "pgup", tea.KeyPgDown: "pgdn", tea.KeyHome: "home", tea.KeyEnd: "end",
+	tea.KeyEnter: "enter", tea.KeyEsc: "esc", tea.KeyTab: "tab", tea.KeyShiftTab: "backtab",
+	tea.KeyBackspace: "backspace", tea.KeyCtrlH: "backspace", tea.KeyCtrlC: "ctrl-c",
+}
+
+// Keys converts a bubbletea key message, one Key per typed or pasted
+// character. Alt combinations and other control keys are not bound.
+func Keys(msg tea.KeyMsg) []Key {
+	if msg.Alt {
+		return nil
+	}
+	switch msg.Type {
+	case tea.KeyRunes:
+		keys := make([]Key, len(msg.Runes))
+		for i, r := range msg.Runes {
+			keys[i] = Key{Rune: r}
+		}
+		return keys
+	case tea.KeySpace:
+		return []Key{{Rune: ' '}}
+	}
+	if name, ok := keyNames[msg.Type]; ok {
+		return []Key{{Name: name}}
+	}
+	return nil
+}
+
+// ErrNotTerminal is returned by OpenOutput when the process has no
+// controlling terminal
+var ErrNotTerminal = errors.New("not an interactive terminal")
+
+// Output is the terminal device the UI draws on. Writes are serialized, so
+// Copy never lands inside a frame.
+type Output struct {
+	*os.File
+	mu sync.Mutex
+}
+
+// OpenOutput opens the terminal device (/dev/tty, CONOUT$ on Windows), which
+// stays the terminal when stdout is redirected
+func OpenOutput() (*Output, error) {
+	f, err := openTTY()
+	if err != nil {
+		return nil, ErrNotTerminal
+	}
+	if !term.IsTerminal(int(f.Fd())) {
+		f.Close()
+		return nil, ErrNotTerminal
+	}
+	return &Output{File: f}, nil
+}
+
+func (o *Output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.File.Write(p)
+}
+
+// Copy puts text on the clipboard of the terminal (OSC 52), which works
+// over SSH too. Terminals that don't support it ignore the sequence.
+func (o *Output) Copy(text string) {
+	o.Write([]byte("\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"))
+}
+
+// Fit truncates or pads plain text to exactly width columns. Control
+// characters are replaced, since they would break the layout.
+func Fit(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	text = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, text)
+	return runewidth.FillRight(runewidth.Truncate(text, width, "…"), width)
+}
+
+// Wrap splits text into lines of at most width columns
+func Wrap(text string, width int) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		for runewidth.StringWidth(line) > width && width > 0 {
+			cut := runewidth.Truncate(line, width, "")
+			if cut == "" {
+				break
+			}
+			lines = append(lines, cut)
+			line = line[len(cut):]
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Style wraps text in an SGR sequence (e.g. "1" bold, "7" reverse, "32"
+// green); the caller fits the text first
+func Style(sgr, text string) string {
+	if sgr == "" {
+		return text
+	}
+	return "\x1b[" + sgr + "m" + text + "\x1b[0m"
+}
@@ -0,0 +1,199 @@
+// Package waf fingerprints web application firewalls and bot managers from
+// captured responses. A fingerprint in the headers proves the WAF sits in
+// front of the host; a block page or challenge proves it stopped a request.
+package waf
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/repplus/rep-cli/internal/store"
+)
+
+// Match is a WAF seen in one response
+type Match struct {
+	Name     string `json:"name"`
+	Blocked  bool   `json:"blocked"`  // The response is a block page or challenge
+	Evidence string `json:"evidence"` // What matched (header or body marker)
+}
+
+// headerSign matches a header (by name, optionally by value)
+type headerSign struct {
+	name  string
+	value *regexp.Regexp // nil = presence is enough
+}
+
+type fingerprint struct {
+	name    string
+	headers []headerSign
+	cookies []string       // Set-Cookie name prefixes
+	block   *regexp.Regexp // Block page / challenge body
+	// blockHeaders mark the response itself as a block or challenge
+	blockHeaders []headerSign
+}
+
+var fingerprints = []fingerprint{
+	{
+		name: "Cloudflare",
+		headers: []headerSign{
+			{name: "cf-ray"},
+			{name: "server", value: regexp.MustCompile(`(?i)^cloudflare`)},
+		},
+		cookies:      []string{"__cf_bm", "cf_clearance", "__cfduid"},
+		block:        regexp.MustCompile(`(?i)Attention Required! \| Cloudflare|Just a moment\.\.\.|cf-error-details|Sorry, you have been blocked|cf_chl_opt|challenge-platform`),
+		blockHeaders: []headerSign{{name: "cf-mitigated"}},
+	},
+	{
+		name: "Akamai",
+		headers: []headerSign{
+			{name: "server", value: regexp.MustCompile(`(?i)AkamaiGHost|AkamaiNetStorage`)},
+			{name: "x-akamai-transformed"},
+			{name: "akamai-grn"},
+		},
+		cookies: []string{"ak_bmsc", "bm_sz", "_abck"},
+		block:   regexp.MustCompile(`(?i)Reference&#32;&#35;\d+\.|Reference #\d+\.[0-9a-f]+\.\d+|errors\.edgesuite\.net`),
+	},
+	{
+		name: "Imperva",
+		headers: []headerSign{
+			{name: "x-iinfo"},
+			{name: "x-cdn", value: regexp.MustCompile(`(?i)incapsula|imperva`)},
+		},
+		cookies: []string{"incap_ses_", "visid_incap_", "nlbi_"},
+		block:   regexp.MustCompile(`(?i)Incapsula incident ID|_Incapsula_Resource|Request unsuccessful\. Incapsula`),
+	},
+	{
+		name: "AWS WAF",
+		headers: []headerSign{
+			{name: "x-amzn-waf-action"},
+		},
+		cookies:      []string{"aws-waf-token"},
+		block:        regexp.MustCompile(`(?i)<h1>403 Forbidden</h1>\s*<ul>\s*<li>Code: AccessDenied|Request blocked\.[\s\S]{0,300}cloudfront`),
+		blockHeaders: []headerSign{{name: "x-amzn-waf-action", value: regexp.MustCompile(`(?i)block|captcha|challenge`)}},
+	},
+	{
+		name: "Sucuri",
+		headers: []headerSign{
+			{name: "x-sucuri-id"},
+			{name: "server", value: regexp.MustCompile(`(?i)Sucuri`)},
+		},
+		block:        regexp.MustCompile(`(?i)Sucuri WebSite Firewall - Access Denied|sucuri\.net/privacy-policy`),
+		blockHeaders: []headerSign{{name: "x-sucuri-block"}},
+	},
+	{
+		name:  "F5 BIG-IP ASM",
+		block: regexp.MustCompile(`The requested URL was rejected\. Please consult with your administrator\.`),
+		headers: []headerSign{
+			{name: "x-wa-info"},
+		},
+		cookies: []string{"TS01", "BIGipServer"},
+	},
+	{
+		name:  "ModSecurity",
+		block: regexp.MustCompile(`(?i)Mod_Security|This error was generated by Mod_Security|ModSecurity Action`),
+	},
+	{
+		name: "DataDome",
+		headers: []headerSign{
+			{name: "x-datadome"},
+			{name: "x-dd-b"},
+		},
+		cookies: []string{"datadome"},
+		block:   regexp.MustCompile(`(?i)geo\.captcha-delivery\.com|dd=\{`),
+	},
+	{
+		name:    "PerimeterX",
+		cookies: []string{"_px3", "_pxhd", "_pxvid"},
+		block:   regexp.MustCompile(`(?i)px-captcha|Press & Hold|perimeterx`),
+	},
+	{
+		name: "Azure Front Door",
+		headers: []headerSign{
+			{name: "x-azure-ref"},
+		},
+		block: regexp.MustCompile(`(?i)The request is blocked\.[\s\S]{0,200}?(?:azure|Front Door)`),
+	},
+}
+
+// Detect returns the WAFs fingerprinted in a response. Bodies are only
+// checked for block pages on 4xx/5xx responses and challenges.
+func Detect(req *store.Request) []Match {
+	if req.Response == nil {
+		return nil
+	}
+	headers := req.Response.Headers
+	status := req.Response.Status
+	body := ""
+	if status >= 400 || status == 202 {
+		body = store.ResponseBodyText(req)
+	}
+
+	var matches []Match
+	for _, fp := range fingerprints {
+		m := Match{Name: fp.name}
+		for _, h := range fp.blockHeaders {
+			if value, ok := headerMatches(headers, h); ok {
+				m.Blocked, m.Evidence = true, h.name+": "+value
+				break
+			}
+		}
+		if !m.Blocked && body != "" && fp.block != nil {
+			if found := fp.block.FindString(body); found != "" {
+				m.Blocked, m.Evidence = true, "body: "+excerpt(found)
+			}
+		}
+		if m.Evidence == "" {
+			for _, h := range fp.headers {
+				if value, ok := headerMatches(headers, h); ok {
+					m.Evidence = h.name + ": " + excerpt(value)
+					break
+				}
+			}
+		}
+		if m.Evidence == "" {
+			if name := cookieMatch(headers, fp.cookies); name != "" {
+				m.Evidence = "cookie: " + name
+			}
+		}
+		if m.Evidence != "" {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+func headerMatches(headers store.HeaderMap, sign headerSign) (string, bool) {
+	values := store.HeaderValues(headers, sign.name)
+	if len(values) == 0 {
+		return "", false
+	}
+	value := values[0]
+	if sign.value != nil && !sign.value.MatchString(value) {
+		return "", false
+	}
+	return value, true
+}
+
+func cookieMatch(headers store.HeaderMap, prefixes []string) string {
+	if len(prefixes) == 0 {
+		return ""
+	}
+	for _, c := range store.HeaderValues(headers, "set-cookie") {
+		name, _, _ := strings.Cut(c, "=")
+		name = strings.TrimSpace(name)
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func excerpt(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > 60 {
+		s = s[:57] + "..."
+	}
+	return s
+}
@@ -0,0 +1,176 @@
+// rep capture browser: talks only to the /api endpoints of 'rep serve'.
+// Captured data is always inserted with textContent, never as HTML.
+"use strict";
+
+const pageSize = 200;
+const form = document.getElementById("filters");
+const rows = document.getElementById("rows");
+const more = document.getElementById("more");
+let offset = 0;
+let selected = null;
+
+function $(id) {
+  return document.getElementById(id);
+}
+
+function showError(msg) {
+  const el = $("error");
+  el.textContent = msg;
+  el.hidden = !msg;
+}
+
+async function api(path) {
+  const resp = await fetch(path);
+  const data = await resp.json();
+  if (!resp.ok) {
+    throw new Error(data.error || resp.statusText);
+  }
+  return data;
+}
+
+function filterQuery() {
+  const params = new URLSearchParams();
+  for (const el of form.elements) {
+    if (!el.name) continue;
+    if (el.type === "checkbox") {
+      params.set(el.name, el.checked ? "true" : "false");
+    } else if (el.value.trim()) {
+      params.set(el.name, el.value.trim());
+    }
+  }
+  return params;
+}
+
+function cell(text, className) {
+  const td = document.createElement("td");
+  td.textContent = text;
+  if (className) td.className = className;
+  return td;
+}
+
+function statusClass(status) {
+  return status ? "s" + String(status)[0] : "";
+}
+
+async function loadRequests(reset) {
+  if (reset) {
+    offset = 0;
+    rows.replaceChildren();
+  }
+  const params = filterQuery();
+  params.set("limit", pageSize);
+  params.set("offset", offset);
+  try {
+    const data = await api("/api/requests?" + params);
+    for (const req of data.requests) {
+      const tr = document.createElement("tr");
+      const status = req.response ? req.response.status : 0;
+      tr.append(
+        cell(req.id),
+        cell(req.method),
+        cell(status || "-", statusClass(status)),
+        cell(req.url, "url"),
+        cell(req.resource_type || ""),
+        cell(req.duration_ms ? Math.round(req.duration_ms) + "ms" : "")
+      );
+      tr.title = req.url;
+      tr.addEventListener("click", () => showRequest(req.id, tr));
+      rows.append(tr);
+    }
+    offset += data.count;
+    $("count").textContent = offset + " of " + data.total;
+    more.hidden = offset >= data.total;
+    showError("");
+  } catch (err) {
+    showError(err.message);
+  }
+}
+
+function formatHeaders(headers) {
+  if (!headers) return "";
+  return Object.keys(headers).sort()
+    .flatMap((name) => headers[name].map((value) => name + ": " + value))
+    .join("\n");
+}
+
+// prettyBody indents JSON bodies and leaves everything else as captured
+function prettyBody(body) {
+  if (!body) return "";
+  const text = body.trim();
+  if (text.startsWith("{") || text.startsWith("[")) {
+    try {
+      return JSON.stringify(JSON.parse(text), null, 2);
+    } catch (e) {
+      // Not JSON after all
+    }
+  }
+  return body;
+}
+
+function savedParam() {
+  const saved = form.elements.saved.value;
+  return saved ? "?saved=" + encodeURIComponent(saved) : "";
+}
+
+async function showRequest(id, tr) {
+  if (selected) selected.classList.remove("selected");
+  selected = tr;
+  tr.classList.add("selected");
+  try {
+    const req = await api("/api/requests/" + encodeURIComponent(id) + savedParam());
+    $("detail").hidden = false;
+    $("detail").dataset.id = req.id;
+    $("detail-title").textContent = req.method + " " + req.url;
+    $("req-headers").textContent = formatHeaders(req.headers);
+    $("req-body").textContent = prettyBody(req.body);
+    const resp = req.response || {};
+    $("resp-status").textContent = resp.status ? String(resp.status) : "(none)";
+    $("resp-status").className = statusClass(resp.status);
+    $("resp-headers").textContent = formatHeaders(resp.headers);
+    $("resp-body").textContent = prettyBody(resp.body);
+    $("copy-status").textContent = "";
+    showError("");
+  } catch (err) {
+    showError(err.message);
+  }
+}
+
+async function copyCurl() {
+  const id = $("detail").dataset.id;
+  const saved = form.elements.saved.value;
+  const params = new URLSearchParams({ shell: $("shell").value });
+  if (saved) params.set("saved", saved);
+  try {
+    const data = await api("/api/requests/" + encodeURIComponent(id) + "/curl?" + params);
+    await navigator.clipboard.writeText(data.curl);
+    $("copy-status").textContent = "Copied";
+  } catch (err) {
+    showError(err.message);
+  }
+}
+
+async function loadSessions() {
+  try {
+    const sessions = await api("/api/sessions");
+    for (const s of sessions) {
+      if (s.live) continue;
+      const opt = document.createElement("option");
+      opt.value = s.id;
+      opt.textContent = s.id + " (" + s.requests + ")" + (s.note ? " " + s.note : "");
+      form.elements.saved.append(opt);
+    }
+  } catch (err) {
+    showError(err.message);
+  }
+}
+
+form.addEventListener("submit", (e) => {
+  e.preventDefault();
+  loadRequests(true);
+});
+form.elements.saved.addEventListener("change", () => loadRequests(true));
+more.addEventListener("click", () => loadRequests(false));
+$("copy-curl").addEventListener("click", copyCurl);
+
+loadSessions();
+loadRequests(true);
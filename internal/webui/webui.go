@@ -0,0 +1,30 @@
+// Package webui is the single-page capture browser served by 'rep serve'.
+// The page only talks to the /api endpoints of the same server; captured
+// content is rendered as text, never as markup.
+package webui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the UI files
+func Handler() http.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // The embedded tree always has static/
+	}
+	fileServer := http.FileServer(http.FS(files))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Captured traffic is attacker-controlled: nothing but our own
+		// script may run, and nothing may be framed or loaded from elsewhere
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; img-src 'self' data:; frame-ancestors 'none'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		fileServer.ServeHTTP(w, r)
+	})
+}